@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"calendar-wbf/pkg/cache"
+	"calendar-wbf/pkg/cache/sim"
+)
+
+func main() {
+	var (
+		capacity  = flag.Int("capacity", 1000, "cache capacity")
+		traceFile = flag.String("trace", "", "file with one key per line; overrides -pattern")
+		pattern   = flag.String("pattern", "monthview", "synthetic trace: zipf or monthview")
+		requests  = flag.Int("requests", 200_000, "synthetic trace length")
+		keys      = flag.Int("keys", 10_000, "distinct keys in the zipf trace, hot keys in the monthview trace")
+		policies  = flag.String("policies", "lru,lfu,arc,tinylfu", "comma-separated policies to compare")
+		seed      = flag.Uint64("seed", 1, "random seed for synthetic traces")
+	)
+	flag.Parse()
+
+	var selected []cache.Policy
+	for name := range strings.SplitSeq(*policies, ",") {
+		selected = append(selected, cache.Policy(strings.TrimSpace(name)))
+	}
+
+	var (
+		results []sim.Result
+		err     error
+	)
+
+	switch {
+	case *traceFile != "":
+		results, err = compareFile(*traceFile, selected, *capacity)
+	case *pattern == "zipf":
+		results, err = sim.Compare(selected, *capacity, sim.Zipf(*requests, *keys, *seed))
+	case *pattern == "monthview":
+		trace := sim.MonthView(*requests, *keys, *capacity, 2*(*capacity), *seed)
+		results, err = sim.Compare(selected, *capacity, trace)
+	default:
+		err = fmt.Errorf("unknown pattern %q", *pattern)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cache_sim: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err = sim.WriteTable(os.Stdout, results); err != nil {
+		fmt.Fprintf(os.Stderr, "cache_sim: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func compareFile(path string, policies []cache.Policy, capacity int) ([]sim.Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	trace, err := sim.ReadTrace(f)
+	if err != nil {
+		return nil, err
+	}
+	return sim.Compare(policies, capacity, trace)
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	httpt "calendar-wbf/internal/transport/http"
+
+	"github.com/google/uuid"
+)
+
+const (
+	_idempotencyKeyHeader = "Idempotency-Key"
+	_retryBackoff         = 200 * time.Millisecond
+	_maxErrorBody         = 4 << 10
+)
+
+type (
+	// client calls the JSON API with the request types of the server.
+	client struct {
+		baseURL      string
+		http         *http.Client
+		retries      int
+		tenant       string
+		tenantHeader string
+		token        string
+	}
+
+	// apiError is a non-2xx response.
+	apiError struct {
+		Status  int
+		Message string
+	}
+)
+
+func newClient(cfg *cliConfig) *client {
+	return &client{
+		baseURL:      strings.TrimRight(cfg.URL, "/"),
+		http:         &http.Client{Timeout: cfg.Timeout},
+		retries:      cfg.Retries,
+		tenant:       cfg.Tenant,
+		tenantHeader: cfg.TenantHeader,
+		token:        cfg.Token,
+	}
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("server returned %d: %s", e.Status, e.Message)
+}
+
+func (c *client) CreateEvent(ctx context.Context, req httpt.CreateEventRequest, key string) (string, error) {
+	var resp struct {
+		Result string `json:"result"`
+	}
+	err := c.post(ctx, "/create_event", key, req, &resp)
+	return resp.Result, err
+}
+
+func (c *client) UpdateEvent(ctx context.Context, id uint64, req httpt.UpdateEventRequest, key string) (string, error) {
+	var resp struct {
+		Result string `json:"result"`
+	}
+	err := c.post(ctx, "/update_event/"+strconv.FormatUint(id, 10), key, req, &resp)
+	return resp.Result, err
+}
+
+func (c *client) DeleteEvent(ctx context.Context, id, userID uint64, key string) (string, error) {
+	var resp struct {
+		Message string `json:"message"`
+	}
+	req := httpt.DeleteEventRequest{UserID: userID}
+	err := c.post(ctx, "/delete_event/"+strconv.FormatUint(id, 10), key, req, &resp)
+	return resp.Message, err
+}
+
+func (c *client) GetEvent(ctx context.Context, id, userID uint64) (*entity.Event, error) {
+	var event entity.Event
+	req := httpt.GetEventRequest{UserID: userID}
+	if err := c.post(ctx, "/get_event/"+strconv.FormatUint(id, 10), "", req, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (c *client) EventsForDay(ctx context.Context, req httpt.GetEventForDayRequest) ([]*entity.Event, error) {
+	var events []*entity.Event
+	err := c.post(ctx, "/events_for_day", "", req, &events)
+	return events, err
+}
+
+func (c *client) EventsForWeek(ctx context.Context, req httpt.GetEventForWeekRequest) ([]*entity.Event, error) {
+	var events []*entity.Event
+	err := c.post(ctx, "/events_for_week", "", req, &events)
+	return events, err
+}
+
+func (c *client) EventsForMonth(ctx context.Context, req httpt.GetEventForMonthRequest) ([]*entity.Event, error) {
+	var events []*entity.Event
+	err := c.post(ctx, "/events_for_month", "", req, &events)
+	return events, err
+}
+
+// post sends body as JSON and decodes the response into out. Network errors
+// and 5xx responses are retried; mutating requests carry an Idempotency-Key,
+// so a retry of a request the server already applied is replayed rather
+// than applied twice, and a 409 for a retry racing the original is retried
+// as well.
+func (c *client) post(ctx context.Context, path, key string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err = c.do(ctx, path, key, payload, out)
+		if !c.retryable(ctx, err, key) || attempt >= c.retries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(_retryBackoff << attempt):
+		}
+	}
+}
+
+func (c *client) retryable(ctx context.Context, err error, key string) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+	return apiErr.Status >= http.StatusInternalServerError ||
+		(apiErr.Status == http.StatusConflict && key != "")
+}
+
+func (c *client) do(ctx context.Context, path, key string, payload []byte, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(_idempotencyKeyHeader, key)
+	}
+	if c.tenant != "" {
+		req.Header.Set(c.tenantHeader, c.tenant)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("POST %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return decodeAPIError(resp)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response of %s: %w", path, err)
+	}
+	return nil
+}
+
+func decodeAPIError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, _maxErrorBody))
+
+	var errResp httpt.ErrorResponse
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error != "" {
+		return &apiError{Status: resp.StatusCode, Message: errResp.Error}
+	}
+
+	message := strings.TrimSpace(string(body))
+	if message == "" {
+		message = http.StatusText(resp.StatusCode)
+	}
+	return &apiError{Status: resp.StatusCode, Message: message}
+}
+
+// newIdempotencyKey returns the key for one invocation of a mutating
+// command, shared by all of its retries.
+func newIdempotencyKey() string {
+	return uuid.NewString()
+}
@@ -0,0 +1,553 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	httpt "calendar-wbf/internal/transport/http"
+	"calendar-wbf/pkg/ical"
+)
+
+const (
+	_exportProdID = "-//calendar-wbf//calendar_cli//EN"
+	_formatICS    = "ics"
+	_daysPerWeek  = 7
+)
+
+var errUsage = errors.New("invalid usage")
+
+type (
+	command struct {
+		name    string
+		summary string
+		run     func(ctx context.Context, env *environment, args []string) error
+	}
+
+	// environment is what commands share: resolved config, API client and
+	// output.
+	environment struct {
+		cfg    *cliConfig
+		client *client
+		out    *printer
+		stdout io.Writer
+		stderr io.Writer
+		now    func() time.Time
+	}
+
+	// tagFlags are the tag filters of the list commands.
+	tagFlags struct {
+		include string
+		exclude string
+	}
+)
+
+func commands() []command {
+	return []command{
+		{"add", "create an event", runAdd},
+		{"edit", "change fields of an event", runEdit},
+		{"rm", "delete events by ID", runRemove},
+		{"day", "list events of a day", runDay},
+		{"week", "list events of a week", runWeek},
+		{"month", "list events of a month", runMonth},
+		{"export", "export events of a date range as iCalendar or JSON", runExport},
+	}
+}
+
+func runAdd(ctx context.Context, env *environment, args []string) error {
+	fs := newFlagSet("add", env, "-title TITLE -date DATE [flags]")
+	var (
+		title    = fs.String("title", "", "event title (required)")
+		text     = fs.String("text", "", "event description; defaults to the title")
+		date     = fs.String("date", "", "start: RFC 3339, YYYY-MM-DD HH:MM, YYYY-MM-DD, today or tomorrow (required)")
+		duration = fs.Duration("duration", time.Hour, "event duration")
+		tags     = fs.String("tags", "", "comma-separated tags")
+		color    = fs.String("color", "", "hex color, e.g. #ff8800")
+		key      = fs.String("key", "", "Idempotency-Key; generated when empty")
+	)
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	if *title == "" || *date == "" {
+		return usageError(fs, "-title and -date are required")
+	}
+
+	userID, err := env.userID()
+	if err != nil {
+		return err
+	}
+
+	start, err := parseDate(*date, env.cfg.location, env.now())
+	if err != nil {
+		return usageError(fs, err.Error())
+	}
+
+	if *text == "" {
+		*text = *title
+	}
+
+	req := httpt.CreateEventRequest{
+		UserID:   userID,
+		Date:     start,
+		Duration: entity.Duration(*duration),
+		Title:    *title,
+		Text:     *text,
+		Tags:     splitList(*tags),
+		Color:    *color,
+	}
+
+	message, err := env.client.CreateEvent(ctx, req, orNewKey(*key))
+	if err != nil {
+		return err
+	}
+	return env.out.message(message)
+}
+
+// runEdit fetches the event and sends it back with the given flags applied,
+// since the update endpoint replaces every field.
+func runEdit(ctx context.Context, env *environment, args []string) error {
+	fs := newFlagSet("edit", env, "[flags] ID")
+	var (
+		title    = fs.String("title", "", "new title")
+		text     = fs.String("text", "", "new description")
+		date     = fs.String("date", "", "new start, in the formats of add")
+		duration = fs.Duration("duration", 0, "new duration")
+		tags     = fs.String("tags", "", `new comma-separated tags; "" removes all`)
+		color    = fs.String("color", "", `new hex color; "" removes it`)
+		key      = fs.String("key", "", "Idempotency-Key; generated when empty")
+	)
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+
+	id, err := parseID(fs.Arg(0))
+	if err != nil {
+		return usageError(fs, err.Error())
+	}
+	userID, err := env.userID()
+	if err != nil {
+		return err
+	}
+
+	event, err := env.client.GetEvent(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+
+	req := httpt.UpdateEventRequest{
+		UserID:   userID,
+		Date:     event.Date,
+		Duration: entity.Duration(event.Duration),
+		Title:    event.Title,
+		Text:     event.Text,
+		Tags:     event.Tags,
+		Color:    event.Color,
+	}
+
+	var parseErr error
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "title":
+			req.Title = *title
+		case "text":
+			req.Text = *text
+		case "date":
+			req.Date, parseErr = parseDate(*date, env.cfg.location, env.now())
+		case "duration":
+			req.Duration = entity.Duration(*duration)
+		case "tags":
+			req.Tags = splitList(*tags)
+		case "color":
+			req.Color = *color
+		}
+	})
+	if parseErr != nil {
+		return usageError(fs, parseErr.Error())
+	}
+
+	message, err := env.client.UpdateEvent(ctx, id, req, orNewKey(*key))
+	if err != nil {
+		return err
+	}
+	return env.out.message(message)
+}
+
+func runRemove(ctx context.Context, env *environment, args []string) error {
+	fs := newFlagSet("rm", env, "ID...")
+	if err := parseFlags(fs, args, -1); err != nil {
+		return err
+	}
+
+	userID, err := env.userID()
+	if err != nil {
+		return err
+	}
+
+	ids := make([]uint64, 0, fs.NArg())
+	for _, arg := range fs.Args() {
+		id, parseErr := parseID(arg)
+		if parseErr != nil {
+			return usageError(fs, parseErr.Error())
+		}
+		ids = append(ids, id)
+	}
+
+	for _, id := range ids {
+		message, deleteErr := env.client.DeleteEvent(ctx, id, userID, newIdempotencyKey())
+		if deleteErr != nil {
+			return fmt.Errorf("delete event %d: %w", id, deleteErr)
+		}
+		if err = env.out.message(fmt.Sprintf("%d: %s", id, message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runDay(ctx context.Context, env *environment, args []string) error {
+	fs := newFlagSet("day", env, "[flags]")
+	date := fs.String("date", "today", "day to list")
+	var tags tagFlags
+	tags.register(fs)
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	userID, err := env.userID()
+	if err != nil {
+		return err
+	}
+	day, err := parseDate(*date, env.cfg.location, env.now())
+	if err != nil {
+		return usageError(fs, err.Error())
+	}
+
+	events, err := env.client.EventsForDay(ctx, httpt.GetEventForDayRequest{
+		TagFilterRequest: tags.request(),
+		UserID:           userID,
+		Date:             day,
+	})
+	if err != nil {
+		return err
+	}
+	return env.out.events(events)
+}
+
+func runWeek(ctx context.Context, env *environment, args []string) error {
+	fs := newFlagSet("week", env, "[flags]")
+	start := fs.String("start", "", "first day of the week; defaults to this Monday")
+	var tags tagFlags
+	tags.register(fs)
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	userID, err := env.userID()
+	if err != nil {
+		return err
+	}
+
+	var from time.Time
+	if *start == "" {
+		from = startOfWeek(env.now().In(env.cfg.location))
+	} else if from, err = parseDate(*start, env.cfg.location, env.now()); err != nil {
+		return usageError(fs, err.Error())
+	}
+
+	events, err := env.client.EventsForWeek(ctx, httpt.GetEventForWeekRequest{
+		TagFilterRequest: tags.request(),
+		UserID:           userID,
+		StartDate:        from,
+	})
+	if err != nil {
+		return err
+	}
+	return env.out.events(events)
+}
+
+func runMonth(ctx context.Context, env *environment, args []string) error {
+	fs := newFlagSet("month", env, "[flags]")
+	month := fs.String("month", "", "month as YYYY-MM; defaults to the current one")
+	var tags tagFlags
+	tags.register(fs)
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	userID, err := env.userID()
+	if err != nil {
+		return err
+	}
+	first, err := parseMonth(*month, env.cfg.location, env.now())
+	if err != nil {
+		return usageError(fs, err.Error())
+	}
+
+	events, err := env.client.EventsForMonth(ctx, httpt.GetEventForMonthRequest{
+		TagFilterRequest: tags.request(),
+		UserID:           userID,
+		Year:             first.Year(),
+		Month:            int(first.Month()),
+	})
+	if err != nil {
+		return err
+	}
+	return env.out.events(events)
+}
+
+// runExport collects the events starting in [from, to) month by month, as
+// the API has no range query.
+func runExport(ctx context.Context, env *environment, args []string) error {
+	fs := newFlagSet("export", env, "[flags]")
+	var (
+		fromFlag = fs.String("from", "", "first day; defaults to the start of the current month")
+		toFlag   = fs.String("to", "", "day after the last one; defaults to one month after -from")
+		format   = fs.String("format", _formatICS, "ics or json")
+		output   = fs.String("o", "", "output file; stdout when empty")
+	)
+	var tags tagFlags
+	tags.register(fs)
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	if *format != _formatICS && *format != _outputJSON {
+		return usageError(fs, "-format must be ics or json")
+	}
+
+	userID, err := env.userID()
+	if err != nil {
+		return err
+	}
+
+	from, to, err := exportRange(*fromFlag, *toFlag, env.cfg.location, env.now())
+	if err != nil {
+		return usageError(fs, err.Error())
+	}
+
+	var events []*entity.Event
+	first := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location())
+	for month := first; month.Before(to); month = month.AddDate(0, 1, 0) {
+		monthEvents, listErr := env.client.EventsForMonth(ctx, httpt.GetEventForMonthRequest{
+			TagFilterRequest: tags.request(),
+			UserID:           userID,
+			Year:             month.Year(),
+			Month:            int(month.Month()),
+		})
+		if listErr != nil {
+			return listErr
+		}
+		for _, event := range monthEvents {
+			if !event.Date.Before(from) && event.Date.Before(to) {
+				events = append(events, event)
+			}
+		}
+	}
+
+	slices.SortFunc(events, func(a, b *entity.Event) int {
+		if c := a.Date.Compare(b.Date); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	events = slices.CompactFunc(events, func(a, b *entity.Event) bool { return a.ID == b.ID })
+
+	var buf bytes.Buffer
+	if *format == _formatICS {
+		err = ical.Encode(&buf, _exportProdID, icalEvents(events)...)
+	} else {
+		err = (&printer{w: &buf, format: _outputJSON}).events(events)
+	}
+	if err != nil {
+		return fmt.Errorf("encode export: %w", err)
+	}
+
+	if *output == "" {
+		_, err = env.stdout.Write(buf.Bytes())
+		return err
+	}
+	if err = os.WriteFile(*output, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("write export: %w", err)
+	}
+	fmt.Fprintf(env.stderr, "exported %d events to %s\n", len(events), *output)
+	return nil
+}
+
+func (t *tagFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&t.include, "tags", "", "comma-separated tags; events need at least one")
+	fs.StringVar(&t.exclude, "exclude", "", "comma-separated tags to leave out")
+}
+
+func (t *tagFlags) request() httpt.TagFilterRequest {
+	return httpt.TagFilterRequest{
+		IncludeTags: splitList(t.include),
+		ExcludeTags: splitList(t.exclude),
+	}
+}
+
+func (env *environment) userID() (uint64, error) {
+	if env.cfg.UserID == 0 {
+		return 0, errors.New("user ID is not set; use -user, CALENDAR_USER_ID or user_id in the config file")
+	}
+	return env.cfg.UserID, nil
+}
+
+func newFlagSet(name string, env *environment, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(env.stderr, "usage: calendar_cli [global flags] %s %s\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args and checks the number of positional arguments:
+// exactly n, or at least one when n is negative.
+func parseFlags(fs *flag.FlagSet, args []string, n int) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	switch {
+	case n < 0 && fs.NArg() == 0:
+		return usageError(fs, "at least one argument is required")
+	case n >= 0 && fs.NArg() != n:
+		return usageError(fs, fmt.Sprintf("expected %d arguments, got %d", n, fs.NArg()))
+	}
+	return nil
+}
+
+func usageError(fs *flag.FlagSet, message string) error {
+	fmt.Fprintf(fs.Output(), "%s: %s\n", fs.Name(), message)
+	fs.Usage()
+	return errUsage
+}
+
+func parseID(s string) (uint64, error) {
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("invalid event ID %q", s)
+	}
+	return id, nil
+}
+
+// parseDate accepts RFC 3339 timestamps and, in loc, "YYYY-MM-DD HH:MM",
+// "YYYY-MM-DDTHH:MM", "YYYY-MM-DD", "today" and "tomorrow".
+func parseDate(s string, loc *time.Location, now time.Time) (time.Time, error) {
+	today := startOfDay(now.In(loc))
+
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+func parseMonth(s string, loc *time.Location, now time.Time) (time.Time, error) {
+	if s == "" {
+		now = now.In(loc)
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc), nil
+	}
+
+	t, err := time.ParseInLocation("2006-01", s, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid month %q, want YYYY-MM", s)
+	}
+	return t, nil
+}
+
+func exportRange(fromFlag, toFlag string, loc *time.Location, now time.Time) (time.Time, time.Time, error) {
+	from, err := parseMonth("", loc, now)
+	if fromFlag != "" {
+		from, err = parseDate(fromFlag, loc, now)
+	}
+	if err != nil {
+		return from, from, err
+	}
+
+	to := from.AddDate(0, 1, 0)
+	if toFlag != "" {
+		if to, err = parseDate(toFlag, loc, now); err != nil {
+			return from, to, err
+		}
+	}
+
+	if !to.After(from) {
+		return from, to, errors.New("-to must be after -from")
+	}
+	if to.After(from.AddDate(1, 0, 0)) {
+		return from, to, errors.New("range is limited to a year")
+	}
+	return from, to, nil
+}
+
+func icalEvents(events []*entity.Event) []ical.Event {
+	result := make([]ical.Event, 0, len(events))
+	for _, event := range events {
+		uid := event.UID
+		if uid == "" {
+			uid = strconv.FormatUint(event.ID, 10)
+		}
+
+		result = append(result, ical.Event{
+			UID:          uid,
+			Start:        event.Date,
+			End:          event.End(),
+			Summary:      event.Title,
+			Description:  event.Text,
+			Categories:   event.Tags,
+			Color:        event.Color,
+			Attendees:    entity.ICalAttendees(event.Attendees),
+			Created:      event.CreatedAt,
+			LastModified: event.UpdatedAt,
+		})
+	}
+	return result
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + _daysPerWeek - 1) % _daysPerWeek
+	return startOfDay(t).AddDate(0, 0, -offset)
+}
+
+func splitList(s string) []string {
+	var values []string
+	for value := range strings.SplitSeq(s, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func orNewKey(key string) string {
+	if key == "" {
+		return newIdempotencyKey()
+	}
+	return key
+}
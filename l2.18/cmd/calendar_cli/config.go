@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+const (
+	_configEnv   = "CALENDAR_CONFIG"
+	_configDir   = "calendar"
+	_configName  = "cli.yaml"
+	_outputTable = "table"
+	_outputJSON  = "json"
+)
+
+// cliConfig holds the defaults of every command. Values come from the config
+// file, then CALENDAR_* environment variables, then global flags.
+type cliConfig struct {
+	URL          string        `yaml:"url"           env:"CALENDAR_URL"           validate:"required,url"     env-default:"http://localhost:8080"`
+	UserID       uint64        `yaml:"user_id"       env:"CALENDAR_USER_ID"`
+	Timezone     string        `yaml:"timezone"      env:"CALENDAR_TIMEZONE"      validate:"required"         env-default:"Local"`
+	Output       string        `yaml:"output"        env:"CALENDAR_OUTPUT"        validate:"oneof=table json" env-default:"table"`
+	Timeout      time.Duration `yaml:"timeout"       env:"CALENDAR_TIMEOUT"       validate:"gt=0s,lte=5m"     env-default:"10s"`
+	Retries      int           `yaml:"retries"       env:"CALENDAR_RETRIES"       validate:"gte=0,lte=10"     env-default:"2"`
+	Tenant       string        `yaml:"tenant"        env:"CALENDAR_TENANT"`
+	TenantHeader string        `yaml:"tenant_header" env:"CALENDAR_TENANT_HEADER" validate:"required"         env-default:"X-Tenant-ID"`
+	Token        string        `yaml:"token"         env:"CALENDAR_TOKEN"`
+
+	location *time.Location
+}
+
+// defaultConfigPath is $XDG_CONFIG_HOME/calendar/cli.yaml or its platform
+// equivalent.
+func defaultConfigPath() string {
+	if path := os.Getenv(_configEnv); path != "" {
+		return path
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, _configDir, _configName)
+}
+
+// loadConfig reads path when it exists; a missing file at the default path
+// is not an error, so the CLI works with flags and environment alone.
+func loadConfig(path string, explicit bool) (*cliConfig, error) {
+	var cfg cliConfig
+
+	err := cleanenv.ReadConfig(path, &cfg)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		err = cleanenv.ReadEnv(&cfg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read config %s: %w", path, err)
+	}
+
+	return &cfg, nil
+}
+
+func (cfg *cliConfig) validate() error {
+	if err := validator.New().Struct(cfg); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", cfg.Timezone, err)
+	}
+	cfg.location = loc
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata"
+)
+
+const (
+	_exitError = 1
+	_exitUsage = 2
+)
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	err := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	if err == nil {
+		return
+	}
+
+	code := _exitUsage
+	if !errors.Is(err, errUsage) {
+		fmt.Fprintf(os.Stderr, "calendar_cli: %v\n", err)
+		code = _exitError
+	}
+	cancel()
+	os.Exit(code)
+}
+
+// run parses the global flags, resolves the config and dispatches to the
+// subcommand.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("calendar_cli", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		configPath = fs.String("config", defaultConfigPath(), "config file (YAML); $"+_configEnv+" overrides the default")
+		url        = fs.String("url", "", "service URL")
+		userID     = fs.Uint64("user", 0, "user ID")
+		timezone   = fs.String("tz", "", "IANA timezone for dates without an offset and for tables")
+		output     = fs.String("output", "", "output format: table or json")
+		timeout    = fs.Duration("timeout", 0, "timeout of each request")
+		retries    = fs.Int("retries", 0, "retries of failed requests (2 unless configured)")
+		tenant     = fs.String("tenant", "", "tenant to send requests as; empty sends no tenant header")
+		tenantHdr  = fs.String("tenant-header", "", "header carrying the tenant (X-Tenant-ID unless configured)")
+		token      = fs.String("token", "", "bearer token; prefer $CALENDAR_TOKEN, flags are visible to other users")
+	)
+	fs.Usage = func() { usage(fs, stderr) }
+
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	explicit := false
+	fs.Visit(func(f *flag.Flag) { explicit = explicit || f.Name == "config" })
+
+	cfg, err := loadConfig(*configPath, explicit)
+	if err != nil {
+		return err
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "url":
+			cfg.URL = *url
+		case "user":
+			cfg.UserID = *userID
+		case "tz":
+			cfg.Timezone = *timezone
+		case "output":
+			cfg.Output = *output
+		case "timeout":
+			cfg.Timeout = *timeout
+		case "retries":
+			cfg.Retries = *retries
+		case "tenant":
+			cfg.Tenant = *tenant
+		case "tenant-header":
+			cfg.TenantHeader = *tenantHdr
+		case "token":
+			cfg.Token = *token
+		}
+	})
+	if err = cfg.validate(); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	for _, cmd := range commands() {
+		if cmd.name != fs.Arg(0) {
+			continue
+		}
+
+		env := &environment{
+			cfg:    cfg,
+			client: newClient(cfg),
+			out:    &printer{w: stdout, format: cfg.Output, location: cfg.location},
+			stdout: stdout,
+			stderr: stderr,
+			now:    time.Now,
+		}
+		return cmd.run(ctx, env, fs.Args()[1:])
+	}
+
+	fmt.Fprintf(stderr, "calendar_cli: unknown command %q\n", fs.Arg(0))
+	fs.Usage()
+	return errUsage
+}
+
+func usage(fs *flag.FlagSet, w io.Writer) {
+	fmt.Fprintln(w, "usage: calendar_cli [global flags] <command> [flags]")
+	fmt.Fprintln(w, "\ncommands:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "\nglobal flags:")
+	fs.PrintDefaults()
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	httpt "calendar-wbf/internal/transport/http"
+)
+
+type recordedRequest struct {
+	path   string
+	key    string
+	header http.Header
+	body   []byte
+}
+
+// fakeAPI answers like the service and records every request. The first
+// failures requests get a 503.
+type fakeAPI struct {
+	mu       sync.Mutex
+	requests []recordedRequest
+	failures int
+	event    entity.Event
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var body bytes.Buffer
+	_, _ = body.ReadFrom(r.Body)
+	f.requests = append(f.requests, recordedRequest{
+		path:   r.URL.Path,
+		key:    r.Header.Get("Idempotency-Key"),
+		header: r.Header.Clone(),
+		body:   body.Bytes(),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	if f.failures > 0 {
+		f.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(httpt.ErrorResponse{Error: "shutting down"})
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/create_event":
+		_ = json.NewEncoder(w).Encode(map[string]string{"result": "Event create successfully"})
+	case r.URL.Path == "/update_event/5":
+		_ = json.NewEncoder(w).Encode(map[string]string{"result": "Event update successfully"})
+	case r.URL.Path == "/get_event/5":
+		_ = json.NewEncoder(w).Encode(f.event)
+	case strings.HasPrefix(r.URL.Path, "/events_for_"):
+		_ = json.NewEncoder(w).Encode([]entity.Event{f.event})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(httpt.ErrorResponse{Error: "event not found"})
+	}
+}
+
+func runCLI(t *testing.T, api *fakeAPI, args ...string) (string, error) {
+	t.Helper()
+
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	t.Setenv(_configEnv, filepath.Join(t.TempDir(), "missing.yaml"))
+	global := []string{"-url", server.URL, "-user", "3", "-tz", "Europe/Moscow", "-retries", "2"}
+
+	var stdout, stderr bytes.Buffer
+	err := run(context.Background(), append(global, args...), &stdout, &stderr)
+	return stdout.String(), err
+}
+
+func TestAdd(t *testing.T) {
+	api := &fakeAPI{failures: 1}
+
+	out, err := runCLI(t, api, "add", "-title", "Standup", "-date", "2026-10-20 10:00", "-duration", "15m",
+		"-tags", "work, daily")
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if out != "Event create successfully\n" {
+		t.Errorf("output = %q", out)
+	}
+
+	if len(api.requests) != 2 {
+		t.Fatalf("requests = %d, want a retry after the 503", len(api.requests))
+	}
+	if api.requests[0].key == "" || api.requests[0].key != api.requests[1].key {
+		t.Errorf("Idempotency-Key %q and %q, want the same key on retry", api.requests[0].key, api.requests[1].key)
+	}
+
+	var req httpt.CreateEventRequest
+	if err = json.Unmarshal(api.requests[1].body, &req); err != nil {
+		t.Fatalf("decode request: %v", err)
+	}
+	want := time.Date(2026, time.October, 20, 7, 0, 0, 0, time.UTC)
+	if req.UserID != 3 || !req.Date.Equal(want) || time.Duration(req.Duration) != 15*time.Minute ||
+		req.Text != "Standup" || !slices.Equal(req.Tags, []string{"work", "daily"}) {
+		t.Errorf("request = %+v", req)
+	}
+}
+
+func TestTenantAndToken(t *testing.T) {
+	t.Setenv("CALENDAR_TOKEN", "s3cr3t")
+	api := &fakeAPI{}
+
+	_, err := runCLI(t, api, "-tenant", "acme", "-tenant-header", "X-Org", "day", "-date", "2026-10-20")
+	if err != nil {
+		t.Fatalf("day: %v", err)
+	}
+
+	header := api.requests[0].header
+	if header.Get("X-Org") != "acme" || header.Get("X-Tenant-ID") != "" {
+		t.Errorf("tenant headers = %v, want acme in X-Org only", header)
+	}
+	if header.Get("Authorization") != "Bearer s3cr3t" {
+		t.Errorf("Authorization = %q, want the token from CALENDAR_TOKEN", header.Get("Authorization"))
+	}
+
+	api = &fakeAPI{}
+	if _, err = runCLI(t, api, "-token", "", "day", "-date", "2026-10-20"); err != nil {
+		t.Fatalf("day: %v", err)
+	}
+	if header = api.requests[0].header; header.Get("Authorization") != "" || header.Get("X-Tenant-ID") != "" {
+		t.Errorf("headers = %v, want no tenant and no token", header)
+	}
+}
+
+func TestEditKeepsUnsetFields(t *testing.T) {
+	api := &fakeAPI{event: entity.Event{
+		ID:       5,
+		UserID:   3,
+		Date:     time.Date(2026, time.October, 20, 7, 0, 0, 0, time.UTC),
+		Duration: time.Hour,
+		Title:    "Review",
+		Text:     "Quarterly review",
+		Tags:     []string{"work"},
+		Color:    "#ff8800",
+	}}
+
+	if _, err := runCLI(t, api, "edit", "-title", "Retro", "-tags", "", "5"); err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+
+	var req httpt.UpdateEventRequest
+	if err := json.Unmarshal(api.requests[len(api.requests)-1].body, &req); err != nil {
+		t.Fatalf("decode request: %v", err)
+	}
+	if req.Title != "Retro" || req.Text != "Quarterly review" || req.Tags != nil || req.Color != "#ff8800" ||
+		time.Duration(req.Duration) != time.Hour {
+		t.Errorf("request = %+v", req)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	testCases := []struct {
+		desc string
+		args []string
+		want string
+	}{
+		{"NotFound", []string{"rm", "7"}, "server returned 404: event not found"},
+		{"MissingTitle", []string{"add", "-date", "today"}, errUsage.Error()},
+		{"BadID", []string{"edit", "abc"}, errUsage.Error()},
+		{"UnknownCommand", []string{"frobnicate"}, errUsage.Error()},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := runCLI(t, &fakeAPI{}, tc.args...)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestExportICS(t *testing.T) {
+	api := &fakeAPI{event: entity.Event{
+		ID:       5,
+		Date:     time.Date(2026, time.October, 20, 7, 0, 0, 0, time.UTC),
+		Duration: time.Hour,
+		Title:    "Review",
+		Text:     "Quarterly review",
+	}}
+
+	out, err := runCLI(t, api, "export", "-from", "2026-10-01", "-to", "2026-12-01")
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	if len(api.requests) != 2 {
+		t.Errorf("requests = %d, want one per month", len(api.requests))
+	}
+	if strings.Count(out, "BEGIN:VEVENT") != 1 || !strings.Contains(out, "DTSTART:20261020T070000Z") {
+		t.Errorf("export =\n%s", out)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	t.Parallel()
+
+	loc, _ := time.LoadLocation("Europe/Moscow")
+	now := time.Date(2026, time.October, 18, 23, 30, 0, 0, time.UTC) // 02:30 on the 19th in Moscow
+
+	testCases := []struct {
+		desc  string
+		input string
+		want  time.Time
+	}{
+		{"RFC3339", "2026-10-20T10:00:00Z", time.Date(2026, time.October, 20, 10, 0, 0, 0, time.UTC)},
+		{"LocalTime", "2026-10-20 10:00", time.Date(2026, time.October, 20, 10, 0, 0, 0, loc)},
+		{"DateOnly", "2026-10-20", time.Date(2026, time.October, 20, 0, 0, 0, 0, loc)},
+		{"Today", "today", time.Date(2026, time.October, 19, 0, 0, 0, 0, loc)},
+		{"Tomorrow", "Tomorrow", time.Date(2026, time.October, 20, 0, 0, 0, 0, loc)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseDate(tc.input, loc, now)
+			if err != nil || !got.Equal(tc.want) {
+				t.Errorf("parseDate(%q) = %v, %v; want %v", tc.input, got, err, tc.want)
+			}
+		})
+	}
+
+	if _, err := parseDate("next week", loc, now); err == nil {
+		t.Error("parseDate accepted an unknown format")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cli.yaml")
+	data := "url: http://calendar:8080\nuser_id: 9\ntimezone: Asia/Tokyo\noutput: json\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CALENDAR_OUTPUT", "table")
+
+	cfg, err := loadConfig(path, true)
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if err = cfg.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	if cfg.URL != "http://calendar:8080" || cfg.UserID != 9 || cfg.location.String() != "Asia/Tokyo" {
+		t.Errorf("config = %+v", cfg)
+	}
+	if cfg.Output != _outputTable || cfg.Timeout != 10*time.Second || cfg.Retries != 2 {
+		t.Errorf("env override or defaults not applied: %+v", cfg)
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+	if _, err = loadConfig(missing, true); err == nil {
+		t.Error("explicit missing config accepted")
+	}
+
+	cfg, err = loadConfig(missing, false)
+	if err != nil {
+		t.Fatalf("loadConfig without a file: %v", err)
+	}
+	if err = cfg.validate(); err != nil || cfg.location != time.Local {
+		t.Errorf("defaults: location %v, error %v; want Local", cfg.location, err)
+	}
+}
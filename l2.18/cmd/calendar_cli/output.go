@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"calendar-wbf/internal/entity"
+)
+
+const _tableTimeLayout = "2006-01-02 15:04"
+
+// printer renders command results in the configured output format. Times in
+// tables are shown in the configured timezone.
+type printer struct {
+	w        io.Writer
+	format   string
+	location *time.Location
+}
+
+func (p *printer) events(events []*entity.Event) error {
+	if p.format == _outputJSON {
+		if events == nil {
+			events = []*entity.Event{}
+		}
+		return p.json(events)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tDATE\tDURATION\tTITLE\tTAGS")
+	for _, event := range events {
+		// Holidays and days off are read-only overlay entries without an ID.
+		id := strconv.FormatUint(event.ID, 10)
+		if event.ReadOnly {
+			id = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			id,
+			event.Date.In(p.location).Format(_tableTimeLayout),
+			formatDuration(event.Duration),
+			event.Title,
+			strings.Join(event.Tags, ","),
+		)
+	}
+	return tw.Flush()
+}
+
+func (p *printer) event(event *entity.Event) error {
+	if p.format == _outputJSON {
+		return p.json(event)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fields := [][2]string{
+		{"ID", strconv.FormatUint(event.ID, 10)},
+		{"Date", event.Date.In(p.location).Format(_tableTimeLayout)},
+		{"Duration", formatDuration(event.Duration)},
+		{"Title", event.Title},
+		{"Text", event.Text},
+		{"Tags", strings.Join(event.Tags, ",")},
+		{"Color", event.Color},
+	}
+	for _, field := range fields {
+		fmt.Fprintf(tw, "%s:\t%s\n", field[0], field[1])
+	}
+	return tw.Flush()
+}
+
+// message prints the confirmation returned by a mutating endpoint.
+func (p *printer) message(message string) error {
+	if p.format == _outputJSON {
+		return p.json(map[string]string{"result": message})
+	}
+
+	_, err := fmt.Fprintln(p.w, message)
+	return err
+}
+
+func (p *printer) json(v any) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return entity.FormatDuration(d)
+}
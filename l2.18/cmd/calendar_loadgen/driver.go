@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	httpt "calendar-wbf/internal/transport/http"
+)
+
+const _maxErrorBody = 4 << 10
+
+var (
+	errDropped     = errors.New("dropped: in-flight limit reached")
+	errBadResponse = errors.New("bad response")
+)
+
+type (
+	// driver sends generated requests to the service and records them.
+	driver struct {
+		cfg   *loadConfig
+		http  *http.Client
+		pool  *idPool
+		stats *recorder
+	}
+
+	// statusError is a non-2xx response.
+	statusError struct {
+		Status  int
+		Message string
+	}
+)
+
+func newDriver(cfg *loadConfig) *driver {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = max(cfg.workers, cfg.maxInFlight)
+
+	return &driver{
+		cfg:   cfg,
+		http:  &http.Client{Timeout: cfg.timeout, Transport: transport},
+		pool:  newIDPool(),
+		stats: newRecorder(),
+	}
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("server returned %d: %s", e.Status, e.Message)
+}
+
+// seed creates events per user for every user and learns their IDs from
+// month reads, so the run starts against a populated calendar. It returns
+// how many creates failed.
+func (d *driver) seed(ctx context.Context) (int, error) {
+	users := make(chan uint64)
+	var failed atomic.Int64
+	var wg sync.WaitGroup
+
+	for worker := range d.cfg.workers {
+		gen := newGenerator(d.cfg, d.pool, uint64(worker))
+		wg.Go(func() {
+			for userID := range users {
+				for range d.cfg.events {
+					if err := d.execute(ctx, gen.create(userID)); err != nil {
+						failed.Add(1)
+					}
+				}
+				for _, month := range d.cfg.months() {
+					_ = d.execute(ctx, gen.read(opMonth, "/events_for_month", userID, httpt.GetEventForMonthRequest{
+						UserID: userID,
+						Year:   month.Year(),
+						Month:  int(month.Month()),
+					}))
+				}
+			}
+		})
+	}
+
+	for userID := uint64(1); userID <= uint64(d.cfg.users) && ctx.Err() == nil; userID++ {
+		users <- userID
+	}
+	close(users)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return int(failed.Load()), ctx.Err()
+	}
+	if total := d.cfg.users * d.cfg.events; total > 0 && int(failed.Load()) == total {
+		return total, errors.New("seed: every create failed; is the service up?")
+	}
+	return int(failed.Load()), nil
+}
+
+// runClosed keeps the given number of workers busy: each sends its next
+// request once the previous one completed and the think time passed, so
+// the load drops as the service slows down.
+func (d *driver) runClosed(ctx context.Context) {
+	var wg sync.WaitGroup
+	for worker := range d.cfg.workers {
+		gen := newGenerator(d.cfg, d.pool, uint64(d.cfg.workers+worker))
+		wg.Go(func() {
+			for ctx.Err() == nil {
+				req := gen.next()
+				start := time.Now()
+				err := d.execute(ctx, req)
+				if err != nil && ctx.Err() != nil {
+					return
+				}
+				d.stats.record(req.op, time.Since(start), err)
+
+				if d.cfg.think > 0 {
+					select {
+					case <-ctx.Done():
+					case <-time.After(d.cfg.think):
+					}
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// runOpen sends requests as a Poisson process at the configured rate
+// whatever the service does. Latency is measured from when a request was
+// due, so a stalled service is not hidden by requests it delayed; requests
+// due while maxInFlight are outstanding are dropped and reported.
+func (d *driver) runOpen(ctx context.Context) {
+	gen := newGenerator(d.cfg, d.pool, uint64(d.cfg.workers))
+	inFlight := make(chan struct{}, d.cfg.maxInFlight)
+	var wg sync.WaitGroup
+
+	due := time.Now()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		due = due.Add(gen.interarrival(d.cfg.rate))
+		timer.Reset(time.Until(due))
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-timer.C:
+		}
+
+		req := gen.next()
+		select {
+		case inFlight <- struct{}{}:
+		default:
+			d.stats.record(req.op, 0, errDropped)
+			continue
+		}
+
+		sentDue := due
+		wg.Go(func() {
+			defer func() { <-inFlight }()
+
+			err := d.execute(ctx, req)
+			if err != nil && ctx.Err() != nil {
+				return
+			}
+			d.stats.record(req.op, time.Since(sentDue), err)
+		})
+	}
+}
+
+// execute sends the request and keeps the ID pool in step with what the
+// service returned.
+func (d *driver) execute(ctx context.Context, req request) error {
+	switch req.op {
+	case opDay, opWeek, opMonth:
+		var events []*entity.Event
+		if err := d.post(ctx, req, &events); err != nil {
+			return err
+		}
+		// Holiday overlay entries have no ID and cannot be fetched, updated
+		// or deleted, so they stay out of the pool.
+		ids := make([]uint64, 0, len(events))
+		for _, event := range events {
+			if event.ReadOnly || event.ID == 0 {
+				continue
+			}
+			ids = append(ids, event.ID)
+		}
+		d.pool.add(req.userID, ids...)
+		return nil
+	default:
+		err := d.post(ctx, req, nil)
+		var statusErr *statusError
+		if req.op == opDelete && err == nil ||
+			errors.As(err, &statusErr) && statusErr.Status == http.StatusNotFound {
+			d.pool.remove(req.userID, req.eventID)
+		}
+		return err
+	}
+}
+
+func (d *driver) post(ctx context.Context, req request, out any) error {
+	payload, err := json.Marshal(req.body)
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, d.cfg.url+req.path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if d.cfg.tenant != "" {
+		httpReq.Header.Set(d.cfg.tenantHeader, d.cfg.tenant)
+	}
+
+	resp, err := d.http.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, _maxErrorBody))
+		var errResp httpt.ErrorResponse
+		if json.Unmarshal(body, &errResp) != nil || errResp.Error == "" {
+			errResp.Error = strings.TrimSpace(string(body))
+		}
+		return &statusError{Status: resp.StatusCode, Message: errResp.Error}
+	}
+
+	if out == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%w: decode %s: %w", errBadResponse, req.path, err)
+	}
+	return nil
+}
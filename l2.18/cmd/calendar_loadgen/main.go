@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	_exitError = 1
+	_exitUsage = 2
+
+	_modelClosed = "closed"
+	_modelOpen   = "open"
+
+	_dateLayout = "2006-01-02"
+)
+
+var errUsage = errors.New("invalid usage")
+
+// loadConfig describes the simulated users and the load they put on the
+// service.
+type loadConfig struct {
+	url          string
+	tenant       string
+	tenantHeader string
+	users        int
+	events       int
+	mix          mix
+	model        string
+	workers      int
+	think        time.Duration
+	rate         float64
+	maxInFlight  int
+	duration     time.Duration
+	timeout      time.Duration
+	budget       time.Duration
+	seed         uint64
+	start        time.Time
+	days         int
+}
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	err := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	if err == nil {
+		return
+	}
+
+	code := _exitUsage
+	if !errors.Is(err, errUsage) {
+		fmt.Fprintf(os.Stderr, "calendar_loadgen: %v\n", err)
+		code = _exitError
+	}
+	cancel()
+	os.Exit(code)
+}
+
+// run seeds the calendars, drives the load for the configured duration or
+// until interrupted, and writes the report to stdout. Progress goes to
+// stderr.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	cfg, err := parseFlags(args, stderr)
+	if err != nil {
+		return err
+	}
+
+	d := newDriver(cfg)
+	if cfg.events > 0 {
+		fmt.Fprintf(stderr, "seeding %d events for each of %d users\n", cfg.events, cfg.users)
+		start := time.Now()
+		failed, seedErr := d.seed(ctx)
+		if seedErr != nil {
+			return seedErr
+		}
+		fmt.Fprintf(stderr, "seeded in %s: %d creates failed, %d event IDs known\n",
+			time.Since(start).Round(time.Millisecond), failed, d.pool.len())
+	}
+
+	fmt.Fprintf(stderr, "running the %s model for %s\n", cfg.model, cfg.duration)
+	runCtx, cancel := context.WithTimeout(ctx, cfg.duration)
+	defer cancel()
+
+	start := time.Now()
+	if cfg.model == _modelOpen {
+		d.runOpen(runCtx)
+	} else {
+		d.runClosed(runCtx)
+	}
+
+	return writeReport(stdout, d.stats.report(cfg.model, time.Since(start), cfg.budget))
+}
+
+func parseFlags(args []string, stderr io.Writer) (*loadConfig, error) {
+	fs := flag.NewFlagSet("calendar_loadgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		cfg   loadConfig
+		mix   string
+		start string
+	)
+	fs.StringVar(&cfg.url, "url", "http://localhost:8080", "service URL")
+	fs.StringVar(&cfg.tenant, "tenant", "", "tenant to send requests as; empty sends no tenant header")
+	fs.StringVar(&cfg.tenantHeader, "tenant-header", "X-Tenant-ID", "header carrying the tenant")
+	fs.IntVar(&cfg.users, "users", 100, "simulated users; activity follows a Zipf distribution")
+	fs.IntVar(&cfg.events, "events", 20, "events created for each user before the run")
+	fs.StringVar(&mix, "mix", _defaultMix, "request mix as op=weight pairs; ops: "+strings.Join(_ops, ", "))
+	fs.StringVar(&cfg.model, "model", _modelClosed, "load model: closed (fixed workers) or open (fixed arrival rate)")
+	fs.IntVar(&cfg.workers, "workers", 16, "concurrent workers of the closed model and the seeding")
+	fs.DurationVar(&cfg.think, "think", 0, "pause of a closed-model worker between requests")
+	fs.Float64Var(&cfg.rate, "rate", 200, "requests per second of the open model")
+	fs.IntVar(&cfg.maxInFlight, "max-inflight", 1000, "outstanding requests of the open model before dropping")
+	fs.DurationVar(&cfg.duration, "duration", 30*time.Second, "length of the run")
+	fs.DurationVar(&cfg.timeout, "timeout", 5*time.Second, "client timeout of each request")
+	fs.DurationVar(&cfg.budget, "budget", 500*time.Millisecond, "latency reported as over budget, the service deadline")
+	fs.Uint64Var(&cfg.seed, "seed", 1, "random seed")
+	fs.StringVar(&start, "start", "", "first day of the event window (YYYY-MM-DD); this week's Monday by default")
+	fs.IntVar(&cfg.days, "days", 90, "length of the event window in days")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, errUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "calendar_loadgen: unexpected arguments %q\n", fs.Args())
+		fs.Usage()
+		return nil, errUsage
+	}
+
+	var err error
+	if cfg.mix, err = parseMix(mix); err != nil {
+		return nil, err
+	}
+	if cfg.start, err = parseStart(start, time.Now()); err != nil {
+		return nil, err
+	}
+	cfg.url = strings.TrimRight(cfg.url, "/")
+
+	if err = cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// parseStart returns the day at midnight UTC, or the Monday of the week of
+// now when value is empty.
+func parseStart(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		return today.AddDate(0, 0, -(int(today.Weekday())+6)%7), nil
+	}
+
+	day, err := time.Parse(_dateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("start: %q is not a YYYY-MM-DD date", value)
+	}
+	return day, nil
+}
+
+func (c *loadConfig) validate() error {
+	if u, err := url.Parse(c.url); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("url: %q is not an absolute URL", c.url)
+	}
+
+	checks := []struct {
+		ok   bool
+		what string
+	}{
+		{c.users >= 1, "users must be at least 1"},
+		{c.events >= 0, "events must not be negative"},
+		{c.model == _modelClosed || c.model == _modelOpen, "model must be closed or open"},
+		{c.workers >= 1, "workers must be at least 1"},
+		{c.think >= 0, "think must not be negative"},
+		{c.rate > 0, "rate must be positive"},
+		{c.maxInFlight >= 1, "max-inflight must be at least 1"},
+		{c.duration > 0, "duration must be positive"},
+		{c.timeout > 0, "timeout must be positive"},
+		{c.budget > 0, "budget must be positive"},
+		{c.days >= 1, "days must be at least 1"},
+		{c.tenant == "" || c.tenantHeader != "", "tenant-header must be set with tenant"},
+	}
+	for _, check := range checks {
+		if !check.ok {
+			return errors.New(check.what)
+		}
+	}
+	return nil
+}
+
+// months returns the first day of every month overlapping the window.
+func (c *loadConfig) months() []time.Time {
+	end := c.start.AddDate(0, 0, c.days)
+	var months []time.Time
+	for month := time.Date(c.start.Year(), c.start.Month(), 1, 0, 0, 0, 0, time.UTC); month.Before(end); {
+		months = append(months, month)
+		month = month.AddDate(0, 1, 0)
+	}
+	return months
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	httpt "calendar-wbf/internal/transport/http"
+)
+
+// fakeAPI answers reads with a fixed set of events per user and a holiday
+// overlay entry, times out updates like a service past its deadline and
+// counts requests by path prefix.
+type fakeAPI struct {
+	mu       sync.Mutex
+	requests map[string]int
+	tenants  map[string]int
+	// overlayIDs counts requests for the ID of the overlay entry.
+	overlayIDs int
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	if i := strings.LastIndex(path[1:], "/"); i >= 0 {
+		path = path[:i+1]
+	}
+
+	f.mu.Lock()
+	f.requests[path]++
+	f.tenants[r.Header.Get("X-Tenant-ID")]++
+	if strings.HasSuffix(r.URL.Path, "/0") {
+		f.overlayIDs++
+	}
+	f.mu.Unlock()
+
+	var body struct {
+		UserID uint64 `json:"user_id"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	w.Header().Set("Content-Type", "application/json")
+	switch path {
+	case "/events_for_day", "/events_for_week", "/events_for_month":
+		events := []entity.Event{
+			{ID: body.UserID * 10}, {ID: body.UserID*10 + 1},
+			{Title: "Public holiday", Kind: entity.EventKindHoliday, ReadOnly: true},
+		}
+		_ = json.NewEncoder(w).Encode(events)
+	case "/update_event":
+		w.WriteHeader(http.StatusGatewayTimeout)
+		_ = json.NewEncoder(w).Encode(httpt.ErrorResponse{Error: "Request timed out"})
+	default:
+		_ = json.NewEncoder(w).Encode(map[string]string{"result": "ok"})
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc  string
+		model []string
+	}{
+		{"Closed", []string{"-model", "closed", "-workers", "4"}},
+		{"Open", []string{"-model", "open", "-rate", "500"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			api := &fakeAPI{requests: make(map[string]int), tenants: make(map[string]int)}
+			srv := httptest.NewServer(api)
+			t.Cleanup(srv.Close)
+
+			args := append([]string{
+				"-url", srv.URL, "-tenant", "acme", "-users", "3", "-events", "2", "-days", "31",
+				"-start", "2026-10-01", "-duration", "300ms", "-mix", "day=1,update=1",
+			}, tc.model...)
+			var stdout, stderr bytes.Buffer
+			if err := run(context.Background(), args, &stdout, &stderr); err != nil {
+				t.Fatalf("run() error = %v; stderr:\n%s", err, stderr.String())
+			}
+
+			api.mu.Lock()
+			defer api.mu.Unlock()
+			if got := api.requests["/create_event"]; got < 6 {
+				t.Errorf("create requests = %d; want at least the 6 seeded", got)
+			}
+			// October 2026 is one month, read once per user while seeding.
+			if got := api.requests["/events_for_month"]; got != 3 {
+				t.Errorf("month requests = %d; want 3", got)
+			}
+			if api.requests["/update_event"] == 0 || api.requests["/events_for_day"] == 0 {
+				t.Errorf("requests = %v; want updates and day reads", api.requests)
+			}
+			if api.overlayIDs != 0 {
+				t.Errorf("requests for the holiday overlay entry = %d; want none", api.overlayIDs)
+			}
+			if len(api.tenants) != 1 || api.tenants["acme"] == 0 {
+				t.Errorf("tenants = %v; want only acme", api.tenants)
+			}
+
+			out := stdout.String()
+			for _, want := range []string{
+				"p99.9", "\n     day ", "\n  update ", "\n   total ", "update  504 Gateway Timeout",
+			} {
+				if !strings.Contains(out, want) {
+					t.Errorf("report lacks %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestRun_InvalidFlags(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc  string
+		args  []string
+		usage bool
+	}{
+		{"UnknownFlag", []string{"-nope"}, true},
+		{"Arguments", []string{"extra"}, true},
+		{"UnknownOp", []string{"-mix", "day=1,list=2"}, false},
+		{"EmptyMix", []string{"-mix", "day=0"}, false},
+		{"Model", []string{"-model", "burst"}, false},
+		{"Start", []string{"-start", "01.10.2026"}, false},
+		{"URL", []string{"-url", "localhost"}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout, stderr bytes.Buffer
+			err := run(context.Background(), tc.args, &stdout, &stderr)
+			if err == nil {
+				t.Fatal("run() error = nil")
+			}
+			if errors.Is(err, errUsage) != tc.usage {
+				t.Errorf("run() error = %v; usage error %v", err, tc.usage)
+			}
+		})
+	}
+}
+
+func TestParseMix(t *testing.T) {
+	t.Parallel()
+
+	m, err := parseMix(" day=3, delete=0 ,create=1")
+	if err != nil {
+		t.Fatalf("parseMix() error = %v", err)
+	}
+	if !slices.Equal(m.ops, []string{opDay, opCreate}) || m.total != 4 {
+		t.Errorf("parseMix() = %+v; want day and create with total 4", m)
+	}
+
+	if _, err = parseMix("day=1,day=2"); err == nil {
+		t.Error("parseMix() with a repeated op error = nil")
+	}
+	if _, err = parseMix("day=-1"); err == nil {
+		t.Error("parseMix() with a negative weight error = nil")
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	t.Parallel()
+
+	m, _ := parseMix("create=1")
+	start := time.Date(2026, time.October, 5, 0, 0, 0, 0, time.UTC)
+	cfg := &loadConfig{users: 10, mix: m, seed: 7, days: 28, start: start}
+	gen := newGenerator(cfg, newIDPool(), 0)
+
+	end := cfg.start.AddDate(0, 0, cfg.days)
+	users := make(map[uint64]int)
+	for range 1000 {
+		req := gen.next()
+		event, ok := req.body.(httpt.CreateEventRequest)
+		if !ok {
+			t.Fatalf("body = %T; want a create request", req.body)
+		}
+		users[req.userID]++
+
+		if event.Date.Before(cfg.start) || !event.Date.Before(end) {
+			t.Errorf("date %s outside the window", event.Date)
+		}
+		if hour := event.Date.Hour(); hour < _workdayStart || hour >= _workdayStart+_workdayHours {
+			t.Errorf("start %s outside working hours", event.Date)
+		}
+		if event.Title == "" || event.Text == "" || event.Duration <= 0 || len(event.Tags) > 2 {
+			t.Errorf("invalid event %+v", event)
+		}
+	}
+
+	if len(users) > cfg.users || users[1] <= users[uint64(cfg.users)] {
+		t.Errorf("users = %v; want at most %d with the first the busiest", users, cfg.users)
+	}
+}
+
+func TestGenerator_FallsBackToCreate(t *testing.T) {
+	t.Parallel()
+
+	m, _ := parseMix("delete=1")
+	pool := newIDPool()
+	gen := newGenerator(&loadConfig{users: 1, mix: m, seed: 1, days: 7, start: time.Now()}, pool, 0)
+
+	if req := gen.next(); req.op != opCreate {
+		t.Errorf("op without known events = %s; want create", req.op)
+	}
+
+	pool.add(1, 42)
+	if req := gen.next(); req.op != opDelete || req.path != "/delete_event/42" {
+		t.Errorf("request = %s %s; want delete of event 42", req.op, req.path)
+	}
+	pool.remove(1, 42)
+	if pool.len() != 0 {
+		t.Errorf("pool len = %d; want 0", pool.len())
+	}
+}
+
+func TestRecorder_Report(t *testing.T) {
+	t.Parallel()
+
+	r := newRecorder()
+	for i := range 100 {
+		r.record(opDay, time.Duration(i+1)*10*time.Millisecond, nil)
+	}
+	r.record(opCreate, time.Millisecond, &statusError{Status: http.StatusGatewayTimeout})
+	r.record(opCreate, 0, errDropped)
+	r.record(opCreate, 0, errDropped)
+
+	rep := r.report(_modelOpen, time.Second, 500*time.Millisecond)
+	if len(rep.rows) != 3 {
+		t.Fatalf("rows = %d; want day, create and total", len(rep.rows))
+	}
+
+	testCases := []struct {
+		desc string
+		got  time.Duration
+		want time.Duration
+	}{
+		{"P50", rep.rows[0].percentiles[0], 500 * time.Millisecond},
+		{"P90", rep.rows[0].percentiles[1], 900 * time.Millisecond},
+		{"P99", rep.rows[0].percentiles[2], 990 * time.Millisecond},
+		{"P999", rep.rows[0].percentiles[3], time.Second},
+		{"Max", rep.rows[0].max, time.Second},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if tc.got != tc.want {
+				t.Errorf("got %s; want %s", tc.got, tc.want)
+			}
+		})
+	}
+
+	if day := rep.rows[0]; day.overBudget != 50 {
+		t.Errorf("day over budget = %d; want 50", day.overBudget)
+	}
+	if create := rep.rows[1]; create.requests != 3 || create.errors != 3 {
+		t.Errorf("create = %d requests, %d errors; want 3 and 3", create.requests, create.errors)
+	}
+	if total := rep.rows[2]; total.requests != 103 || total.errors != 3 {
+		t.Errorf("total = %d requests, %d errors; want 103 and 3", total.requests, total.errors)
+	}
+
+	want := []failure{
+		{opCreate, errDropped.Error(), 2},
+		{opCreate, "504 Gateway Timeout", 1},
+	}
+	if !slices.Equal(rep.failures, want) {
+		t.Errorf("failures = %+v; want %+v", rep.failures, want)
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+)
+
+var _percentiles = []float64{0.5, 0.9, 0.99, 0.999}
+
+type (
+	// recorder collects the latency and outcome of every request by
+	// operation.
+	recorder struct {
+		mu  sync.Mutex
+		ops map[string]*opRecord
+	}
+
+	opRecord struct {
+		latencies []time.Duration
+		dropped   int
+		errors    map[string]int
+	}
+
+	// report summarizes a run.
+	report struct {
+		model    string
+		elapsed  time.Duration
+		budget   time.Duration
+		rows     []row
+		failures []failure
+	}
+
+	// row is the summary of one operation, or of all of them.
+	row struct {
+		op          string
+		requests    int
+		errors      int
+		percentiles []time.Duration
+		max         time.Duration
+		overBudget  int
+	}
+
+	failure struct {
+		op     string
+		reason string
+		count  int
+	}
+)
+
+func newRecorder() *recorder {
+	return &recorder{ops: make(map[string]*opRecord)}
+}
+
+// record adds a completed request. Latencies of requests that were never
+// sent are not kept.
+func (r *recorder) record(op string, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec, ok := r.ops[op]
+	if !ok {
+		rec = &opRecord{errors: make(map[string]int)}
+		r.ops[op] = rec
+	}
+	if err != nil {
+		rec.errors[classify(err)]++
+	}
+	if errors.Is(err, errDropped) {
+		rec.dropped++
+		return
+	}
+	rec.latencies = append(rec.latencies, latency)
+}
+
+// report summarizes the recorded requests, operations in the order of
+// _ops and failures by count.
+func (r *recorder) report(model string, elapsed, budget time.Duration) report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rep := report{model: model, elapsed: elapsed, budget: budget}
+	total := &opRecord{}
+	var totalErrs int
+	for _, op := range _ops {
+		rec, ok := r.ops[op]
+		if !ok {
+			continue
+		}
+
+		var errs int
+		for reason, n := range rec.errors {
+			errs += n
+			rep.failures = append(rep.failures, failure{op: op, reason: reason, count: n})
+		}
+		rep.rows = append(rep.rows, summarize(op, rec, errs, budget))
+
+		total.latencies = append(total.latencies, rec.latencies...)
+		total.dropped += rec.dropped
+		totalErrs += errs
+	}
+	if len(rep.rows) > 1 {
+		rep.rows = append(rep.rows, summarize("total", total, totalErrs, budget))
+	}
+
+	slices.SortFunc(rep.failures, func(a, b failure) int {
+		if c := cmp.Compare(b.count, a.count); c != 0 {
+			return c
+		}
+		return cmp.Or(cmp.Compare(a.op, b.op), cmp.Compare(a.reason, b.reason))
+	})
+	return rep
+}
+
+// summarize computes the row of one operation. Dropped requests count as
+// requests but have no latency.
+func summarize(op string, rec *opRecord, errs int, budget time.Duration) row {
+	sorted := slices.Clone(rec.latencies)
+	slices.Sort(sorted)
+
+	res := row{op: op, errors: errs, requests: len(sorted) + rec.dropped}
+	for _, p := range _percentiles {
+		res.percentiles = append(res.percentiles, percentile(sorted, p))
+	}
+	if len(sorted) > 0 {
+		res.max = sorted[len(sorted)-1]
+	}
+	for _, latency := range sorted {
+		if latency > budget {
+			res.overBudget++
+		}
+	}
+	return res
+}
+
+// percentile returns the nearest-rank percentile p of sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
+// classify names the cause of a failed request for the error breakdown.
+// A 504 is the service giving up on its own request deadline.
+func classify(err error) string {
+	var (
+		statusErr *statusError
+		netErr    net.Error
+	)
+	switch {
+	case errors.As(err, &statusErr):
+		return fmt.Sprintf("%d %s", statusErr.Status, http.StatusText(statusErr.Status))
+	case errors.Is(err, errDropped):
+		return errDropped.Error()
+	case errors.Is(err, errBadResponse):
+		return errBadResponse.Error()
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "client timeout"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection refused"
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return "connection reset"
+	default:
+		return "network error"
+	}
+}
+
+func writeReport(w io.Writer, rep report) error {
+	var requests int
+	if len(rep.rows) > 0 {
+		requests = rep.rows[len(rep.rows)-1].requests
+	}
+	fmt.Fprintf(w, "%s model, %s, %.1f req/s, latency budget %s\n\n",
+		rep.model, rep.elapsed.Round(time.Millisecond), rep.rate(requests), rep.budget)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "op\trequests\terrors\treq/s\tp50\tp90\tp99\tp99.9\tmax\tover budget\t")
+	for _, r := range rep.rows {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t", r.op, r.requests, r.errors, rep.rate(r.requests))
+		for _, latency := range r.percentiles {
+			fmt.Fprintf(tw, "%s\t", formatLatency(latency))
+		}
+		fmt.Fprintf(tw, "%s\t%d\t\n", formatLatency(r.max), r.overBudget)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(rep.failures) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w, "\nerrors:")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, f := range rep.failures {
+		fmt.Fprintf(tw, "  %s\t%s\t%d\n", f.op, f.reason, f.count)
+	}
+	return tw.Flush()
+}
+
+func (rep report) rate(requests int) float64 {
+	if rep.elapsed <= 0 {
+		return 0
+	}
+	return float64(requests) / rep.elapsed.Seconds()
+}
+
+func formatLatency(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	default:
+		return d.Round(time.Microsecond).String()
+	}
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	httpt "calendar-wbf/internal/transport/http"
+)
+
+const (
+	opDay    = "day"
+	opWeek   = "week"
+	opMonth  = "month"
+	opGet    = "get"
+	opCreate = "create"
+	opUpdate = "update"
+	opDelete = "delete"
+
+	_defaultMix = "day=35,week=25,month=15,get=10,create=8,update=5,delete=2"
+
+	// A few users own most of the events and traffic.
+	_userZipfS = 1.2
+	_userZipfV = 2
+
+	_weekendShare = 0.15
+	_workdayStart = 9
+	_workdayHours = 9
+	_timeSlot     = 15 * time.Minute
+)
+
+var (
+	_ops = []string{opDay, opWeek, opMonth, opGet, opCreate, opUpdate, opDelete}
+
+	_titles = []string{
+		"Standup", "Planning", "1:1", "Design review", "Lunch", "Focus time",
+		"Interview", "Retro", "Dentist", "Gym", "Demo", "Customer call",
+	}
+	_tags      = []string{"work", "meeting", "focus", "personal", "travel"}
+	_durations = []time.Duration{
+		15 * time.Minute, 30 * time.Minute, 30 * time.Minute, 45 * time.Minute,
+		time.Hour, time.Hour, 90 * time.Minute, 2 * time.Hour,
+	}
+)
+
+type (
+	// mix is the share of each operation in the generated traffic.
+	mix struct {
+		ops     []string
+		weights []int
+		total   int
+	}
+
+	// request is one API call and the operation it is reported under.
+	request struct {
+		op      string
+		path    string
+		body    any
+		userID  uint64
+		eventID uint64
+	}
+
+	// generator draws users, events and requests. It is not safe for
+	// concurrent use; each worker has its own, sharing the pool.
+	generator struct {
+		rng   *rand.Rand
+		users *rand.Zipf
+		mix   mix
+		start time.Time
+		days  int
+		pool  *idPool
+	}
+
+	// idPool holds the IDs of events known to exist, per user. The API does
+	// not return the ID of a created event, so IDs are learned from reads.
+	idPool struct {
+		mu     sync.Mutex
+		byUser map[uint64][]uint64
+		pos    map[uint64]int
+	}
+)
+
+// parseMix parses op=weight pairs such as "day=60,create=40".
+func parseMix(s string) (mix, error) {
+	var m mix
+	for pair := range strings.SplitSeq(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		op, value, found := strings.Cut(pair, "=")
+		op = strings.TrimSpace(op)
+		if !found || !slices.Contains(_ops, op) {
+			return mix{}, fmt.Errorf("mix: %q: want op=weight with op one of %s", pair, strings.Join(_ops, ", "))
+		}
+		if slices.Contains(m.ops, op) {
+			return mix{}, fmt.Errorf("mix: %s given twice", op)
+		}
+
+		weight, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || weight < 0 {
+			return mix{}, fmt.Errorf("mix: %q: weight must be a non-negative integer", pair)
+		}
+		if weight == 0 {
+			continue
+		}
+		m.ops = append(m.ops, op)
+		m.weights = append(m.weights, weight)
+		m.total += weight
+	}
+	if m.total == 0 {
+		return mix{}, fmt.Errorf("mix: %q has no operations", s)
+	}
+	return m, nil
+}
+
+func (m mix) pick(rng *rand.Rand) string {
+	n := rng.IntN(m.total)
+	for i, weight := range m.weights {
+		if n < weight {
+			return m.ops[i]
+		}
+		n -= weight
+	}
+	return m.ops[len(m.ops)-1]
+}
+
+func newGenerator(cfg *loadConfig, pool *idPool, stream uint64) *generator {
+	rng := rand.New(rand.NewPCG(cfg.seed, stream))
+	return &generator{
+		rng:   rng,
+		users: rand.NewZipf(rng, _userZipfS, _userZipfV, uint64(cfg.users-1)),
+		mix:   cfg.mix,
+		start: cfg.start,
+		days:  cfg.days,
+		pool:  pool,
+	}
+}
+
+// next returns the next request of the mix. Operations on existing events
+// fall back to creating one while the user has none known.
+func (g *generator) next() request {
+	userID := g.users.Uint64() + 1
+
+	switch op := g.mix.pick(g.rng); op {
+	case opDay:
+		return g.read(op, "/events_for_day", userID, httpt.GetEventForDayRequest{
+			UserID: userID,
+			Date:   g.day(),
+		})
+	case opWeek:
+		day := g.day()
+		monday := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		return g.read(op, "/events_for_week", userID, httpt.GetEventForWeekRequest{
+			UserID:    userID,
+			StartDate: monday,
+		})
+	case opMonth:
+		day := g.day()
+		return g.read(op, "/events_for_month", userID, httpt.GetEventForMonthRequest{
+			UserID: userID,
+			Year:   day.Year(),
+			Month:  int(day.Month()),
+		})
+	case opGet, opUpdate, opDelete:
+		id, ok := g.pool.pick(g.rng, userID)
+		if !ok {
+			return g.create(userID)
+		}
+		return g.change(op, userID, id)
+	default:
+		return g.create(userID)
+	}
+}
+
+func (g *generator) read(op, path string, userID uint64, body any) request {
+	return request{op: op, path: path, body: body, userID: userID}
+}
+
+func (g *generator) create(userID uint64) request {
+	return request{
+		op:     opCreate,
+		path:   "/create_event",
+		body:   httpt.CreateEventRequest(g.event(userID)),
+		userID: userID,
+	}
+}
+
+func (g *generator) change(op string, userID, eventID uint64) request {
+	req := request{op: op, userID: userID, eventID: eventID}
+	id := strconv.FormatUint(eventID, 10)
+
+	switch op {
+	case opGet:
+		req.path = "/get_event/" + id
+		req.body = httpt.GetEventRequest{UserID: userID}
+	case opUpdate:
+		req.path = "/update_event/" + id
+		req.body = g.event(userID)
+	default:
+		req.path = "/delete_event/" + id
+		req.body = httpt.DeleteEventRequest{UserID: userID}
+	}
+	return req
+}
+
+// event draws an event in working hours, mostly on weekdays, with a
+// typical meeting length and up to two tags.
+func (g *generator) event(userID uint64) httpt.UpdateEventRequest {
+	slots := int(time.Hour/_timeSlot) * _workdayHours
+	start := g.day().
+		Add(_workdayStart * time.Hour).
+		Add(time.Duration(g.rng.IntN(slots)) * _timeSlot)
+
+	var tags []string
+	for _, i := range g.rng.Perm(len(_tags))[:g.rng.IntN(3)] {
+		tags = append(tags, _tags[i])
+	}
+
+	title := _titles[g.rng.IntN(len(_titles))]
+	return httpt.UpdateEventRequest{
+		UserID:   userID,
+		Date:     start,
+		Duration: entity.Duration(_durations[g.rng.IntN(len(_durations))]),
+		Title:    title,
+		Text:     title + " generated by calendar_loadgen",
+		Tags:     tags,
+	}
+}
+
+// day returns a day of the window, skipping most weekends.
+func (g *generator) day() time.Time {
+	for {
+		day := g.start.AddDate(0, 0, g.rng.IntN(g.days))
+		if weekday := day.Weekday(); weekday != time.Saturday && weekday != time.Sunday ||
+			g.rng.Float64() < _weekendShare {
+			return day
+		}
+	}
+}
+
+// interarrival returns the wait before the next arrival of a Poisson
+// process with the given rate per second.
+func (g *generator) interarrival(rate float64) time.Duration {
+	return time.Duration(g.rng.ExpFloat64() / rate * float64(time.Second))
+}
+
+func newIDPool() *idPool {
+	return &idPool{
+		byUser: make(map[uint64][]uint64),
+		pos:    make(map[uint64]int),
+	}
+}
+
+func (p *idPool) add(userID uint64, ids ...uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, id := range ids {
+		if _, ok := p.pos[id]; ok {
+			continue
+		}
+		p.pos[id] = len(p.byUser[userID])
+		p.byUser[userID] = append(p.byUser[userID], id)
+	}
+}
+
+func (p *idPool) remove(userID, id uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	i, ok := p.pos[id]
+	if !ok {
+		return
+	}
+	ids := p.byUser[userID]
+	last := len(ids) - 1
+	ids[i] = ids[last]
+	p.pos[ids[i]] = i
+	p.byUser[userID] = ids[:last]
+	delete(p.pos, id)
+}
+
+func (p *idPool) pick(rng *rand.Rand, userID uint64) (uint64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ids := p.byUser[userID]
+	if len(ids) == 0 {
+		return 0, false
+	}
+	return ids[rng.IntN(len(ids))], true
+}
+
+func (p *idPool) len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.pos)
+}
@@ -3,9 +3,11 @@ package main
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	_ "time/tzdata"
 
 	"calendar-wbf/internal/app"
 	"calendar-wbf/internal/config"
@@ -25,6 +27,9 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Route log/slog and the standard log package through our sinks.
+	slog.SetDefault(slog.New(log.Handler()))
+
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
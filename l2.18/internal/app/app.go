@@ -5,66 +5,369 @@ import (
 	"fmt"
 
 	"calendar-wbf/internal/config"
+	"calendar-wbf/internal/digest"
 	"calendar-wbf/internal/entity"
+	"calendar-wbf/internal/outbox"
 	"calendar-wbf/internal/repository"
 	"calendar-wbf/internal/service"
 	httpt "calendar-wbf/internal/transport/http"
+	"calendar-wbf/pkg/blob"
 	"calendar-wbf/pkg/cache"
+	"calendar-wbf/pkg/holiday"
+	"calendar-wbf/pkg/kafka"
 	"calendar-wbf/pkg/logger"
+	"calendar-wbf/pkg/resp"
 
 	"golang.org/x/sync/errgroup"
 )
 
+const _memoryBrokerCapacity = 10000
+
 func Run(ctx context.Context, cfg *config.Config, log logger.Logger) error {
 	eg, ctx := errgroup.WithContext(ctx)
 
-	calendarCache, err := initCache(&cfg.Cache, log)
+	calendarCache, remote, err := initCache(ctx, eg, &cfg.Cache, log)
 	if err != nil {
 		return err
 	}
-	defer stopCache(calendarCache)
+	defer stopCache(calendarCache, remote, &cfg.Cache, log)
+
+	var repoOpts []repository.EventRepositoryOption
+	if cfg.Outbox.Enabled {
+		repoOpts = append(repoOpts, repository.WithOutbox())
+	}
+	calendarRepo := repository.NewEventRepository(repoOpts...)
 
-	calendarService := initEventService(
+	calendarService, err := initEventService(
 		cfg,
+		calendarRepo,
 		calendarCache,
 		log,
 	)
+	if err != nil {
+		return err
+	}
+
+	tagRepo := repository.NewTagRepository()
+	tagService := service.NewTagService(
+		tagRepo,
+		calendarRepo,
+		calendarService,
+		log.With("component", "tag service"),
+		calendarCache,
+	)
 
-	if serverErr := initHTTPServer(ctx, eg, &cfg.HTTP, calendarService, log); serverErr != nil {
-		return serverErr
+	digestRepo := repository.NewDigestSubscriptionRepository()
+	digestGenerator, err := initDigestGenerator(calendarService)
+	if err != nil {
+		return err
+	}
+
+	digestService := service.NewDigestService(
+		digestRepo,
+		digestGenerator,
+		calendarService,
+		log.With("component", "digest service"),
+	)
+
+	holidays, err := holiday.Load(cfg.Holiday.Dir)
+	if err != nil {
+		return fmt.Errorf("app.Run: load holidays: %w", err)
+	}
+
+	scheduleRepo := repository.NewWorkScheduleRepository()
+	scheduleService := service.NewScheduleService(
+		scheduleRepo,
+		holidays,
+		cfg.Holiday.DefaultCountry,
+		calendarService,
+		log.With("component", "schedule service"),
+	)
+
+	templateRepo := repository.NewTemplateRepository()
+	templateService := service.NewTemplateService(
+		templateRepo,
+		calendarService,
+		log.With("component", "template service"),
+	)
+
+	attachmentService, err := initAttachmentService(&cfg.Attachment, calendarService, log)
+	if err != nil {
+		return err
+	}
+
+	privacyService, err := initPrivacyService(
+		&cfg.Privacy,
+		service.PrivacyStores{
+			Events:    calendarRepo,
+			Tags:      tagRepo,
+			Digests:   digestRepo,
+			Schedules: scheduleRepo,
+			Templates: templateRepo,
+			Exports:   repository.NewExportJobRepository(),
+		},
+		calendarService,
+		attachmentService,
+		log,
+	)
+	if err != nil {
+		return err
+	}
+	defer privacyService.Wait()
+
+	configStore := config.NewStore(cfg)
+
+	idempotency, err := httpt.NewIdempotencyStore(
+		cfg.Idempotency.Capacity,
+		cfg.Idempotency.TTL,
+		log.With("component", "idempotency"),
+	)
+	if err != nil {
+		return err
+	}
+
+	handler := httpt.NewCalendarHandler(calendarService, tagService, log,
+		httpt.WithAttachments(attachmentService),
+		httpt.WithBuildInfo(cfg.App.Name, cfg.App.Version),
+		httpt.WithCache(calendarCache),
+		httpt.WithCalDAV(httpt.NewCalDAV(calendarService, log.With("component", "caldav"))),
+		httpt.WithConfigStore(configStore),
+		httpt.WithDigest(digestService),
+		httpt.WithLogRing(logger.RingOf(log)),
+		httpt.WithPrivacy(privacyService),
+		httpt.WithSchedules(scheduleService),
+		httpt.WithTemplates(templateService),
+		httpt.WithIdempotency(idempotency),
+	)
+
+	httpServer, err := initHTTPServer(ctx, eg, &cfg.HTTP, handler, log)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Digest.Enabled {
+		if digestErr := initDigestScheduler(ctx, eg, &cfg.Digest, digestRepo, digestGenerator, log); digestErr != nil {
+			return digestErr
+		}
+	}
+
+	if cfg.Outbox.Enabled {
+		broker, outboxErr := initOutboxRelay(ctx, eg, &cfg.Outbox, cfg.App.Name, calendarRepo, log)
+		if outboxErr != nil {
+			return outboxErr
+		}
+		defer broker.Close()
+	}
+
+	if cfg.Reload.Enabled {
+		reloader := &reloader{
+			log:        log.With("component", "config reloader"),
+			root:       log,
+			store:      configStore,
+			cache:      calendarCache,
+			service:    calendarService,
+			httpServer: httpServer,
+		}
+		if watchErr := initConfigWatcher(ctx, eg, cfg, reloader); watchErr != nil {
+			return watchErr
+		}
 	}
 
 	return waitForShutdown(eg)
 }
 
+// initCache builds the cache for CACHE_POLICY and wraps it for read-through
+// loading. When CACHE_REMOTE_ADDR is set the local cache becomes the near
+// tier in front of the shared RESP server, and the returned client must be
+// closed on shutdown.
 func initCache(
+	ctx context.Context,
+	eg *errgroup.Group,
 	cfg *config.Cache,
 	log logger.Logger,
-) (cache.Cache[uint64, *entity.Event], error) {
-	calendarCache, err := cache.NewLRUCache[uint64, *entity.Event](
-		cfg.Capacity,
+) (*cache.LoadingCache[string, *entity.Event], *resp.Client, error) {
+	items, err := initLocalCache(cfg, log)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var remote *resp.Client
+	if cfg.RemoteAddr != "" {
+		remote = resp.NewClient(cfg.RemoteAddr, resp.WithIOTimeout(cfg.RemoteTimeout))
+		if items, err = initTieredCache(ctx, eg, cfg, items, remote, log); err != nil {
+			remote.Close()
+			return nil, nil, err
+		}
+	}
+
+	calendarCache, err := cache.NewLoadingCache[string, *entity.Event](
+		items,
 		log.With("component", "cache"),
+		cache.WithLoadTTL(cfg.TTL),
+		cache.WithRefreshAhead(cfg.RefreshAhead),
+		cache.WithStaleWhileRevalidate(cfg.StaleTTL),
+		cache.WithSharedNegativeCaching(cfg.NegativeTTL, entity.ErrEventNotFound),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("app.initCache: %w", err)
+		if remote != nil {
+			remote.Close()
+		}
+		return nil, nil, fmt.Errorf("app.initCache: %w", err)
 	}
+
+	restoreCache(calendarCache, cfg, log)
+
 	calendarCache.StartCleanup(cfg.CleanupInterval)
-	return calendarCache, nil
+	return calendarCache, remote, nil
+}
+
+// initLocalCache builds the in-process cache. LRU is sharded unless
+// CACHE_SHARDS=1 asks for the single-lock implementation.
+func initLocalCache(
+	cfg *config.Cache,
+	log logger.Logger,
+) (cache.Cache[string, *cache.Loaded[*entity.Event]], error) {
+	var (
+		items cache.Cache[string, *cache.Loaded[*entity.Event]]
+		err   error
+	)
+
+	if cfg.Policy == string(cache.PolicyLRU) && cfg.Shards == 1 {
+		items, err = cache.NewLRUCache[string, *cache.Loaded[*entity.Event]](
+			cfg.Capacity,
+			log.With("component", "cache"),
+		)
+	} else {
+		items, err = cache.New[string, *cache.Loaded[*entity.Event]](
+			cache.Policy(cfg.Policy),
+			cfg.Capacity,
+			log.With("component", "cache"),
+			cache.WithShards(cfg.Shards),
+		)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("app.initLocalCache: %w", err)
+	}
+	return items, nil
 }
 
-func stopCache(calendarCache cache.Cache[uint64, *entity.Event]) {
-	if calendarCache != nil {
-		calendarCache.StopCleanup()
+// initTieredCache puts near in front of the RESP tier and listens for
+// invalidations broadcast by other replicas.
+func initTieredCache(
+	ctx context.Context,
+	eg *errgroup.Group,
+	cfg *config.Cache,
+	near cache.Cache[string, *cache.Loaded[*entity.Event]],
+	remote *resp.Client,
+	log logger.Logger,
+) (*cache.TieredCache[string, *cache.Loaded[*entity.Event]], error) {
+	far, err := cache.NewRemoteCache[string, *cache.Loaded[*entity.Event]](
+		remote,
+		log.With("component", "remote cache"),
+		cache.WithKeyPrefix(cfg.RemotePrefix),
+		cache.WithRemoteTimeout(cfg.RemoteTimeout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("app.initTieredCache: %w", err)
+	}
+
+	tiered, err := cache.NewTieredCache(near, far,
+		log.With("component", "cache invalidation"),
+		cache.WithNearTTL(cfg.NearTTL),
+		cache.WithInvalidation(remote, cfg.RemoteChannel),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("app.initTieredCache: %w", err)
 	}
+
+	eg.Go(func() error {
+		return tiered.Run(ctx)
+	})
+	return tiered, nil
+}
+
+// restoreCache warms the cache from CACHE_SNAPSHOT_PATH. A missing or
+// unreadable snapshot only means a cold start. With a remote tier snapshots
+// are skipped: the shared cache outlives restarts, and restoring could
+// overwrite values other replicas wrote since.
+func restoreCache(
+	calendarCache cache.Cache[string, *entity.Event],
+	cfg *config.Cache,
+	log logger.Logger,
+) {
+	if cfg.SnapshotPath == "" || cfg.RemoteAddr != "" {
+		return
+	}
+
+	codec, err := cache.NewCodec[string, *entity.Event](cfg.SnapshotCodec)
+	if err != nil {
+		log.Warnw("cache snapshot skipped", "error", err)
+		return
+	}
+
+	restored, err := cache.LoadSnapshot(cfg.SnapshotPath, calendarCache, codec)
+	if err != nil {
+		log.Warnw("cache snapshot restore failed, starting cold",
+			"path", cfg.SnapshotPath,
+			"error", err,
+		)
+		return
+	}
+
+	log.Infow("cache snapshot restored",
+		"path", cfg.SnapshotPath,
+		"entries", restored,
+	)
+}
+
+func stopCache(
+	calendarCache cache.Cache[string, *entity.Event],
+	remote *resp.Client,
+	cfg *config.Cache,
+	log logger.Logger,
+) {
+	if calendarCache == nil {
+		return
+	}
+
+	calendarCache.StopCleanup()
+
+	if remote != nil {
+		remote.Close()
+		return
+	}
+
+	if cfg.SnapshotPath == "" {
+		return
+	}
+
+	codec, err := cache.NewCodec[string, *entity.Event](cfg.SnapshotCodec)
+	if err != nil {
+		log.Warnw("cache snapshot skipped", "error", err)
+		return
+	}
+
+	saved, err := cache.SaveSnapshot(cfg.SnapshotPath, calendarCache, codec)
+	if err != nil {
+		log.Errorw("cache snapshot save failed",
+			"path", cfg.SnapshotPath,
+			"error", err,
+		)
+		return
+	}
+
+	log.Infow("cache snapshot saved",
+		"path", cfg.SnapshotPath,
+		"entries", saved,
+	)
 }
 
 func initEventService(
 	cfg *config.Config,
-	calendarCache cache.Cache[uint64, *entity.Event],
+	calendarRepo service.EventRepo,
+	calendarCache *cache.LoadingCache[string, *entity.Event],
 	log logger.Logger,
-) *service.EventService {
-	calendarRepo := repository.NewEventRepository()
-
+) (*service.EventService, error) {
 	calendarService := service.NewEventService(
 		calendarRepo,
 		log.With("component", "calendar service"),
@@ -72,28 +375,190 @@ func initEventService(
 		cfg.Cache.TTL,
 	)
 
-	return calendarService
+	quotas, err := tenantQuotas(&cfg.Tenant)
+	if err != nil {
+		return nil, fmt.Errorf("app.initEventService: %w", err)
+	}
+	calendarService.SetQuotas(quotas)
+
+	return calendarService, nil
+}
+
+func tenantQuotas(cfg *config.Tenant) (service.TenantQuotas, error) {
+	perTenant, err := cfg.ParseQuotas()
+	if err != nil {
+		return service.TenantQuotas{}, err
+	}
+	return service.TenantQuotas{Default: cfg.MaxEvents, PerTenant: perTenant}, nil
+}
+
+func initAttachmentService(
+	cfg *config.Attachment,
+	events *service.EventService,
+	log logger.Logger,
+) (*service.AttachmentService, error) {
+	const op = "app.initAttachmentService"
+
+	contentTypes, err := cfg.ParseContentTypes()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	blobs, err := blob.NewFSStore(cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return service.NewAttachmentService(
+		repository.NewAttachmentRepository(),
+		blobs,
+		events,
+		service.AttachmentLimits{
+			MaxSize:      cfg.MaxSize,
+			MaxPerEvent:  cfg.MaxPerEvent,
+			ContentTypes: contentTypes,
+		},
+		log.With("component", "attachment service"),
+	), nil
+}
+
+// initPrivacyService sets up user data export and erasure. Export archives
+// are kept as files under PRIVACY_EXPORT_DIR.
+func initPrivacyService(
+	cfg *config.Privacy,
+	stores service.PrivacyStores,
+	events *service.EventService,
+	attachments *service.AttachmentService,
+	log logger.Logger,
+) (*service.PrivacyService, error) {
+	archives, err := blob.NewFSStore(cfg.ExportDir)
+	if err != nil {
+		return nil, fmt.Errorf("app.initPrivacyService: %w", err)
+	}
+
+	return service.NewPrivacyService(
+		stores,
+		events,
+		attachments,
+		archives,
+		logger.RingOf(log),
+		cfg.ExportTTL,
+		log.With("component", "privacy service"),
+	), nil
+}
+
+func initDigestGenerator(events digest.EventSource) (*digest.Generator, error) {
+	renderer, err := digest.NewRenderer()
+	if err != nil {
+		return nil, fmt.Errorf("app.initDigestGenerator: %w", err)
+	}
+	return digest.NewGenerator(events, renderer), nil
+}
+
+func initDigestScheduler(
+	ctx context.Context,
+	eg *errgroup.Group,
+	cfg *config.Digest,
+	subs digest.SubscriptionRepo,
+	generator *digest.Generator,
+	log logger.Logger,
+) error {
+	sender, err := digest.NewFileSender(cfg.Dir, cfg.From)
+	if err != nil {
+		return fmt.Errorf("app.initDigestScheduler: %w", err)
+	}
+
+	scheduler := digest.NewScheduler(
+		subs,
+		generator,
+		sender,
+		log.With("component", "digest scheduler"),
+		cfg.TickInterval,
+	)
+
+	eg.Go(func() error {
+		return scheduler.Run(ctx)
+	})
+	return nil
+}
+
+// initOutboxRelay starts publishing the outbox of the event repository. The
+// returned broker must be closed once the relay has stopped.
+func initOutboxRelay(
+	ctx context.Context,
+	eg *errgroup.Group,
+	cfg *config.Outbox,
+	clientID string,
+	store outbox.Store,
+	log logger.Logger,
+) (outbox.Broker, error) {
+	var broker outbox.Broker
+	switch cfg.Broker {
+	case "kafka":
+		addrs, err := cfg.ParseKafkaBrokers()
+		if err != nil {
+			return nil, fmt.Errorf("app.initOutboxRelay: %w", err)
+		}
+		producer, err := kafka.NewProducer(addrs, kafka.WithClientID(clientID))
+		if err != nil {
+			return nil, fmt.Errorf("app.initOutboxRelay: %w", err)
+		}
+		broker = outbox.NewKafkaBroker(producer)
+	default:
+		broker = outbox.NewMemoryBroker(_memoryBrokerCapacity)
+	}
+
+	relay := outbox.NewRelay(
+		store,
+		broker,
+		cfg.Topic,
+		log.With("component", "outbox relay"),
+		cfg.PollInterval,
+		cfg.BatchSize,
+	)
+
+	eg.Go(func() error {
+		return relay.Run(ctx)
+	})
+	return broker, nil
 }
 
 func initHTTPServer(
 	ctx context.Context,
 	eg *errgroup.Group,
 	cfg *config.HTTP,
-	calendarService *service.EventService,
+	handler *httpt.CalendarHandler,
 	log logger.Logger,
-) error {
+) (*httpt.HTTPServer, error) {
 	httpServer, err := httpt.NewHTTPServer(
-		httpt.NewCalendarHandler(calendarService, log),
+		handler,
 		cfg,
 		log.With("component", "http server"),
 	)
 	if err != nil {
-		return fmt.Errorf("app.initHTTPServer: %w", err)
+		return nil, fmt.Errorf("app.initHTTPServer: %w", err)
 	}
 
 	eg.Go(func() error {
 		return httpServer.Start(ctx)
 	})
+	return httpServer, nil
+}
+
+func initConfigWatcher(
+	ctx context.Context,
+	eg *errgroup.Group,
+	cfg *config.Config,
+	r *reloader,
+) error {
+	watcher, err := config.NewWatcher(cfg, r.apply, r.reject)
+	if err != nil {
+		return fmt.Errorf("app.initConfigWatcher: %w", err)
+	}
+
+	eg.Go(func() error {
+		return watcher.Run(ctx)
+	})
 	return nil
 }
 
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"fmt"
+
+	"calendar-wbf/internal/config"
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/internal/service"
+	httpt "calendar-wbf/internal/transport/http"
+	"calendar-wbf/pkg/cache"
+	"calendar-wbf/pkg/logger"
+)
+
+type reloader struct {
+	log        logger.Logger
+	root       logger.Logger
+	store      *config.Store
+	cache      cache.Cache[string, *entity.Event]
+	service    *service.EventService
+	httpServer *httpt.HTTPServer
+}
+
+// apply applies the changes that take effect at runtime and stores the
+// resulting configuration. A change that fails to apply keeps its previous
+// value, so it is retried on the next reload.
+func (r *reloader) apply(prev, next *config.Config, changes []config.Change) *config.Config {
+	var (
+		failed  []string
+		restart bool
+	)
+
+	for _, change := range changes {
+		if change.RequiresRestart {
+			restart = true
+			continue
+		}
+
+		if err := r.applyChange(next, change.Key); err != nil {
+			r.log.Errorw("failed to apply config change, keeping previous value",
+				"key", change.Key,
+				"error", err,
+			)
+			failed = append(failed, change.Key)
+			continue
+		}
+
+		r.log.Infow("config change applied",
+			"key", change.Key,
+			"old", fmt.Sprint(change.Old),
+			"new", fmt.Sprint(change.New),
+		)
+	}
+
+	applied := next.Keep(prev, failed)
+	r.store.Update(applied)
+
+	if restart && len(r.store.PendingRestart()) > 0 {
+		r.log.Warnw("config changes require restart to take effect",
+			"keys", r.store.PendingRestart(),
+		)
+	}
+	return applied
+}
+
+func (r *reloader) applyChange(cfg *config.Config, key string) error {
+	switch key {
+	case "LOGGER_LEVEL":
+		level, err := logger.ParseLevel(cfg.Logger.Level)
+		if err != nil {
+			return err
+		}
+		r.root.SetLevel(level)
+	case "LOGGER_LEVELS":
+		overrides, err := logger.ParseLevels(cfg.Logger.Levels)
+		if err != nil {
+			return err
+		}
+		r.root.SetComponentLevels(overrides)
+	case "CACHE_CAPACITY":
+		if err := r.cache.Resize(cfg.Cache.Capacity); err != nil {
+			return err
+		}
+	case "CACHE_TTL":
+		r.service.SetCacheTTL(cfg.Cache.TTL)
+	case "CACHE_CLEANUP_INTERVAL":
+		r.cache.StartCleanup(cfg.Cache.CleanupInterval)
+	case "TENANT_MAX_EVENTS", "TENANT_QUOTAS":
+		quotas, err := tenantQuotas(&cfg.Tenant)
+		if err != nil {
+			return err
+		}
+		r.service.SetQuotas(quotas)
+	case "HTTP_SHUTDOWN_TIMEOUT":
+		r.httpServer.SetShutdownTimeout(cfg.HTTP.ShutdownTimeout)
+	case "HTTP_DRAIN_DELAY":
+		r.httpServer.SetDrainDelay(cfg.HTTP.DrainDelay)
+	}
+	return nil
+}
+
+func (r *reloader) reject(err error) {
+	r.log.Errorw("config reload failed", "error", err)
+}
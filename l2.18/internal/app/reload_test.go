@@ -0,0 +1,96 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"calendar-wbf/internal/config"
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/cache"
+	"calendar-wbf/pkg/logger"
+	mock_logger "calendar-wbf/pkg/logger/mock"
+
+	"go.uber.org/mock/gomock"
+)
+
+func loadConfig(t *testing.T, overrides ...string) *config.Config {
+	t.Helper()
+
+	data, err := os.ReadFile("../../.env.example")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	for _, override := range overrides {
+		key, _, _ := strings.Cut(override, "=")
+		for i, line := range lines {
+			if strings.HasPrefix(line, key+"=") {
+				lines[i] = override
+			}
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "config.env")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	cfg, err := config.LoadPath(path)
+	if err != nil {
+		t.Fatalf("LoadPath() error = %v", err)
+	}
+	return cfg
+}
+
+func TestReloader_Apply(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	log, root := mock_logger.NewMockLogger(ctrl), mock_logger.NewMockLogger(ctrl)
+	c, err := cache.NewLRUCache[string, *entity.Event](1000, mock_logger.NewMockLogger(ctrl))
+	if err != nil {
+		t.Fatalf("NewLRUCache() error = %v", err)
+	}
+	prev := loadConfig(t, "LOGGER_LEVEL=info")
+	store := config.NewStore(prev)
+	r := &reloader{log: log, root: root, store: store, cache: c}
+
+	next := loadConfig(t, "LOGGER_LEVEL=warn", "HTTP_PORT=9090")
+	// Validation rejects such a file, but a cache can refuse any capacity.
+	next.Cache.Capacity = 0
+
+	root.EXPECT().SetLevel(logger.WarnLevel)
+	log.EXPECT().Infow("config change applied",
+		"key", "LOGGER_LEVEL", "old", gomock.Any(), "new", gomock.Any())
+	log.EXPECT().Errorw("failed to apply config change, keeping previous value",
+		"key", "CACHE_CAPACITY", "error", gomock.Any())
+	log.EXPECT().Warnw("config changes require restart to take effect", "keys", []string{"HTTP_PORT"})
+
+	applied := r.apply(prev, next, config.Diff(prev, next))
+	if applied != store.Load() {
+		t.Error("apply() returned a config other than the stored one")
+	}
+	if applied.Cache.Capacity != 1000 || c.Capacity() != 1000 {
+		t.Errorf("capacity = %d, cache capacity = %d; want 1000 kept", applied.Cache.Capacity, c.Capacity())
+	}
+	if applied.Logger.Level != "warn" {
+		t.Errorf("logger level = %s; want warn", applied.Logger.Level)
+	}
+
+	// The capacity is retried on the next reload, and the port changed back
+	// no longer waits for a restart.
+	next = loadConfig(t, "LOGGER_LEVEL=warn", "CACHE_CAPACITY=64")
+	log.EXPECT().Infow("config change applied",
+		"key", "CACHE_CAPACITY", "old", gomock.Any(), "new", gomock.Any())
+
+	applied = r.apply(applied, next, config.Diff(applied, next))
+	if applied.Cache.Capacity != 64 || c.Capacity() != 64 {
+		t.Errorf("capacity = %d, cache capacity = %d; want 64", applied.Cache.Capacity, c.Capacity())
+	}
+	if pending := store.PendingRestart(); len(pending) != 0 {
+		t.Errorf("PendingRestart() after changing back = %v; want none", pending)
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"mime"
+	"net"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,11 +19,23 @@ import (
 
 type (
 	Config struct {
-		App    App    `env-prefix:"APP_"`
-		Logger Logger `env-prefix:"LOGGER_"`
-		HTTP   HTTP   `env-prefix:"HTTP_"`
-		Cache  Cache  `env-prefix:"CACHE_"`
-		Env    string `                     env:"ENV" env-default:"local" validate:"oneof=local dev staging prod"`
+		App         App         `env-prefix:"APP_"`
+		Logger      Logger      `env-prefix:"LOGGER_"`
+		HTTP        HTTP        `env-prefix:"HTTP_"`
+		Cache       Cache       `env-prefix:"CACHE_"`
+		Attachment  Attachment  `env-prefix:"ATTACHMENT_"`
+		Digest      Digest      `env-prefix:"DIGEST_"`
+		GraphQL     GraphQL     `env-prefix:"GRAPHQL_"`
+		Holiday     Holiday     `env-prefix:"HOLIDAY_"`
+		Idempotency Idempotency `env-prefix:"IDEMPOTENCY_"`
+		Outbox      Outbox      `env-prefix:"OUTBOX_"`
+		Privacy     Privacy     `env-prefix:"PRIVACY_"`
+		Reload      Reload      `env-prefix:"RELOAD_"`
+		Tenant      Tenant      `env-prefix:"TENANT_"`
+		Admin       Admin       `env-prefix:"ADMIN_"`
+		Env         string      `                     env:"ENV" env-default:"local" validate:"oneof=local dev staging prod"`
+
+		path string
 	}
 
 	App struct {
@@ -36,6 +51,7 @@ type (
 		WriteTimeout      time.Duration `env:"WRITE_TIMEOUT"       validate:"gte=10ms,lte=30s"         env-default:"5s"`
 		IdleTimeout       time.Duration `env:"IDLE_TIMEOUT"        validate:"gte=10ms,lte=30s"         env-default:"60s"`
 		ShutdownTimeout   time.Duration `env:"SHUTDOWN_TIMEOUT"    validate:"gte=10ms,lte=30s"         env-default:"10s"`
+		DrainDelay        time.Duration `env:"DRAIN_DELAY"         validate:"gte=0s,lte=30s"           env-default:"0s"`
 		ReadHeaderTimeout time.Duration `env:"READ_HEADER_TIMEOUT" validate:"gte=10ms,lte=30s"         env-default:"5s"`
 	}
 
@@ -43,14 +59,114 @@ type (
 		Capacity        int           `env:"CAPACITY"         validate:"required,min=1,max=1000000"`
 		TTL             time.Duration `env:"TTL"              validate:"required,gt=0s,lte=24h"     env-default:"5m"`
 		CleanupInterval time.Duration `env:"CLEANUP_INTERVAL" validate:"gt=0s,lte=24h"              env-default:"10s"`
+		Shards          int           `env:"SHARDS"           validate:"min=0,max=256"              env-default:"0"`
+		Policy          string        `env:"POLICY"           validate:"oneof=lru lfu arc tinylfu"  env-default:"lru"`
+		RefreshAhead    float64       `env:"REFRESH_AHEAD"    validate:"gte=0,lt=1"                 env-default:"0.8"`
+		StaleTTL        time.Duration `env:"STALE_TTL"        validate:"gte=0s,lte=24h"             env-default:"1m"`
+		NegativeTTL     time.Duration `env:"NEGATIVE_TTL"     validate:"gte=0s,lte=1h"              env-default:"5s"`
+		SnapshotPath    string        `env:"SNAPSHOT_PATH"`
+		SnapshotCodec   string        `env:"SNAPSHOT_CODEC"   validate:"oneof=gob json"             env-default:"gob"`
+		RemoteAddr      string        `env:"REMOTE_ADDR"      validate:"omitempty,hostname_port"`
+		RemotePrefix    string        `env:"REMOTE_PREFIX"    validate:"required"                   env-default:"calendar:event:"`
+		RemoteChannel   string        `env:"REMOTE_CHANNEL"   validate:"required"                   env-default:"calendar:invalidate"`
+		RemoteTimeout   time.Duration `env:"REMOTE_TIMEOUT"   validate:"gte=1ms,lte=10s"            env-default:"200ms"`
+		NearTTL         time.Duration `env:"NEAR_TTL"         validate:"gt=0s,lte=24h"              env-default:"30s"`
+	}
+
+	// Attachment limits event attachments, stored as files under Dir.
+	// ContentTypes is a comma-separated list of allowed media types.
+	Attachment struct {
+		Dir          string `env:"DIR"           validate:"required"             env-default:"./attachments"`
+		MaxSize      int64  `env:"MAX_SIZE"      validate:"min=1,max=1073741824" env-default:"10485760"`
+		MaxPerEvent  int    `env:"MAX_PER_EVENT" validate:"min=0,max=1000"       env-default:"20"`
+		ContentTypes string `env:"CONTENT_TYPES" validate:"required"             env-default:"image/png,image/jpeg,image/gif,application/pdf,text/plain"`
+	}
+
+	Digest struct {
+		Enabled      bool          `env:"ENABLED"       env-default:"false"`
+		Dir          string        `env:"DIR"           env-default:"./digests"          validate:"required"`
+		From         string        `env:"FROM"          env-default:"calendar@localhost" validate:"required"`
+		TickInterval time.Duration `env:"TICK_INTERVAL" env-default:"1m"                 validate:"gte=1s,lte=1h"`
+	}
+
+	// GraphQL limits requests to the /graphql endpoint. Depth counts nested
+	// selections; complexity sums field costs, with list fields counted ten
+	// times.
+	GraphQL struct {
+		Enabled       bool `env:"ENABLED"        env-default:"true"`
+		MaxDepth      int  `env:"MAX_DEPTH"      env-default:"10"   validate:"min=1,max=100"`
+		MaxComplexity int  `env:"MAX_COMPLEXITY" env-default:"1000" validate:"min=1,max=1000000"`
+	}
+
+	// Holiday selects the public holiday calendars. Files in Dir are loaded
+	// on top of the bundled ones; DefaultCountry applies to users without a
+	// saved work schedule.
+	Holiday struct {
+		Dir            string `env:"DIR"`
+		DefaultCountry string `env:"DEFAULT_COUNTRY" validate:"omitempty,len=2,alpha"`
+	}
+
+	Idempotency struct {
+		TTL      time.Duration `env:"TTL"      env-default:"24h"   validate:"gte=1m,lte=168h"`
+		Capacity int           `env:"CAPACITY" env-default:"10000" validate:"min=1,max=1000000"`
+	}
+
+	// Outbox publishes domain events of event changes to Topic. Broker is
+	// either an in-process memory broker or a Kafka cluster reached through
+	// the comma-separated KafkaBrokers.
+	Outbox struct {
+		Enabled      bool          `env:"ENABLED"       env-default:"false"`
+		Broker       string        `env:"BROKER"        env-default:"memory"          validate:"oneof=memory kafka"`
+		KafkaBrokers string        `env:"KAFKA_BROKERS" env-default:"localhost:9092"`
+		Topic        string        `env:"TOPIC"         env-default:"calendar.events" validate:"required"`
+		PollInterval time.Duration `env:"POLL_INTERVAL" env-default:"1s"              validate:"gte=10ms,lte=1m"`
+		BatchSize    int           `env:"BATCH_SIZE"    env-default:"100"             validate:"min=1,max=10000"`
+	}
+
+	// Privacy keeps user data export archives under ExportDir for ExportTTL
+	// after they are built.
+	Privacy struct {
+		ExportDir string        `env:"EXPORT_DIR" validate:"required"       env-default:"./exports"`
+		ExportTTL time.Duration `env:"EXPORT_TTL" validate:"gte=1m,lte=720h" env-default:"24h"`
+	}
+
+	Reload struct {
+		Enabled      bool          `env:"ENABLED"       env-default:"true"`
+		PollInterval time.Duration `env:"POLL_INTERVAL" env-default:"5s"   validate:"gte=100ms,lte=1h"`
+	}
+
+	// Tenant resolves the tenant of a request. Without TokenSecret the Header
+	// is trusted, which suits a gateway that sets it; with TokenSecret the
+	// tenant is taken from the TokenClaim of a verified HS256 bearer token.
+	Tenant struct {
+		Header      string `env:"HEADER"       env-default:"X-Tenant-ID" validate:"required"`
+		Required    bool   `env:"REQUIRED"     env-default:"false"`
+		TokenSecret string `env:"TOKEN_SECRET"                           json:"-"`
+		TokenClaim  string `env:"TOKEN_CLAIM"  env-default:"tenant"      validate:"required"`
+		MaxEvents   int    `env:"MAX_EVENTS"   env-default:"0"           validate:"min=0"`
+		Quotas      string `env:"QUOTAS"`
+	}
+
+	Admin struct {
+		Token string `env:"TOKEN" json:"-"`
 	}
 
 	Logger struct {
-		Level      string `env:"LEVEL"       env-default:"info"                     validate:"oneof=debug info warn error"`
-		Filename   string `env:"FILENAME"    env-default:"./logs/order-service.log"`
-		MaxSize    int    `env:"MAX_SIZE"    env-default:"100"                      validate:"min=1,max=1000"`
-		MaxBackups int    `env:"MAX_BACKUPS" env-default:"3"                        validate:"min=0,max=20"`
-		MaxAge     int    `env:"MAX_AGE"     env-default:"28"                       validate:"min=1,max=365"`
+		Level              string        `env:"LEVEL"               env-default:"info"                     validate:"oneof=debug info warn error"`
+		Levels             string        `env:"LEVELS"`
+		RedactDetectors    string        `env:"REDACT_DETECTORS"    env-default:"email,phone"`
+		RedactKeys         string        `env:"REDACT_KEYS"         env-default:"client_ip=mask,email=mask,text=hash,title=hash,user_agent=drop"`
+		Sinks              string        `env:"SINKS"               env-default:"file,stdout"`
+		RingSize           int           `env:"RING_SIZE"           env-default:"1000"                     validate:"min=1,max=100000"`
+		SyslogNetwork      string        `env:"SYSLOG_NETWORK"                                             validate:"omitempty,oneof=udp tcp unix unixgram"`
+		SyslogAddr         string        `env:"SYSLOG_ADDR"`
+		Filename           string        `env:"FILENAME"            env-default:"./logs/order-service.log"`
+		MaxSize            int           `env:"MAX_SIZE"            env-default:"100"                      validate:"min=1,max=1000"`
+		MaxBackups         int           `env:"MAX_BACKUPS"         env-default:"3"                        validate:"min=0,max=20"`
+		MaxAge             int           `env:"MAX_AGE"             env-default:"28"                       validate:"min=1,max=365"`
+		SamplingInitial    int           `env:"SAMPLING_INITIAL"    env-default:"100"                      validate:"min=0"`
+		SamplingThereafter int           `env:"SAMPLING_THEREAFTER" env-default:"100"                      validate:"min=1"`
+		SamplingTick       time.Duration `env:"SAMPLING_TICK"       env-default:"1s"                       validate:"gte=10ms,lte=1m"`
 	}
 )
 
@@ -94,9 +210,94 @@ func LoadPath(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("%s: config validation: %w", op, err)
 	}
 
+	if _, err := cfg.Tenant.ParseQuotas(); err != nil {
+		return nil, fmt.Errorf("%s: config validation: %w", op, err)
+	}
+
+	if _, err := cfg.Attachment.ParseContentTypes(); err != nil {
+		return nil, fmt.Errorf("%s: config validation: %w", op, err)
+	}
+
+	if cfg.Outbox.Broker == "kafka" {
+		if _, err := cfg.Outbox.ParseKafkaBrokers(); err != nil {
+			return nil, fmt.Errorf("%s: config validation: %w", op, err)
+		}
+	}
+
+	cfg.path = configPath
+
 	return &cfg, nil
 }
 
+// ParseQuotas parses TENANT_QUOTAS, a comma-separated list of tenant=limit
+// pairs overriding TENANT_MAX_EVENTS. A limit of 0 means unlimited.
+func (t Tenant) ParseQuotas() (map[string]int, error) {
+	quotas := make(map[string]int)
+	for pair := range strings.SplitSeq(t.Quotas, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		tenantID, value, found := strings.Cut(pair, "=")
+		tenantID = strings.TrimSpace(tenantID)
+		if !found || !entity.ValidTenantID(tenantID) {
+			return nil, fmt.Errorf("TENANT_QUOTAS: %q: %w", pair, entity.ErrInvalidTenant)
+		}
+
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("TENANT_QUOTAS: %q: limit must be a non-negative integer", pair)
+		}
+		quotas[tenantID] = limit
+	}
+	return quotas, nil
+}
+
+// ParseContentTypes parses ATTACHMENT_CONTENT_TYPES into media types such
+// as image/png, without parameters.
+func (a Attachment) ParseContentTypes() ([]string, error) {
+	var types []string
+	for value := range strings.SplitSeq(a.ContentTypes, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		mediaType, _, err := mime.ParseMediaType(value)
+		if err != nil || !strings.Contains(mediaType, "/") {
+			return nil, fmt.Errorf("ATTACHMENT_CONTENT_TYPES: %q is not a media type", value)
+		}
+		types = append(types, mediaType)
+	}
+	return types, nil
+}
+
+// ParseKafkaBrokers parses OUTBOX_KAFKA_BROKERS into host:port addresses.
+func (o Outbox) ParseKafkaBrokers() ([]string, error) {
+	var addrs []string
+	for addr := range strings.SplitSeq(o.KafkaBrokers, ",") {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+
+		if _, port, err := net.SplitHostPort(addr); err != nil || port == "" {
+			return nil, fmt.Errorf("OUTBOX_KAFKA_BROKERS: %q is not a host:port address", addr)
+		}
+		addrs = append(addrs, addr)
+	}
+	if len(addrs) == 0 {
+		return nil, errors.New("OUTBOX_KAFKA_BROKERS: no broker addresses")
+	}
+	return addrs, nil
+}
+
+// Path returns the file the configuration was loaded from.
+func (c *Config) Path() string {
+	return c.path
+}
+
 func fetchConfigPath() string {
 	var path string
 	flag.StringVar(&path, "config", "", "Path to config file")
@@ -0,0 +1,261 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"syscall"
+	"time"
+
+	"calendar-wbf/internal/entity"
+)
+
+const (
+	_maskedValue = "***"
+)
+
+type (
+	Change struct {
+		Key             string
+		Old             any
+		New             any
+		RequiresRestart bool
+	}
+
+	// ReloadFunc applies the changes from prev to next and returns the
+	// configuration now in effect, which keeps the previous value of every
+	// change that failed to apply.
+	ReloadFunc func(prev, next *Config, changes []Change) *Config
+
+	Watcher struct {
+		path     string
+		interval time.Duration
+		current  *Config
+		modTime  time.Time
+		size     int64
+		onReload ReloadFunc
+		onError  func(err error)
+	}
+)
+
+func NewWatcher(current *Config, onReload ReloadFunc, onError func(err error)) (*Watcher, error) {
+	const op = "config.NewWatcher"
+
+	if current.Path() == "" {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrConfigPathNotSet)
+	}
+
+	w := &Watcher{
+		path:     current.Path(),
+		interval: current.Reload.PollInterval,
+		current:  current,
+		onReload: onReload,
+		onError:  onError,
+	}
+
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: stat config file: %w", op, err)
+	}
+	w.modTime, w.size = info.ModTime(), info.Size()
+
+	return w, nil
+}
+
+// Run blocks until ctx is done, reloading the configuration on SIGHUP and
+// whenever the polled file changes its modification time or size.
+func (w *Watcher) Run(ctx context.Context) error {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-hup:
+			w.reload()
+		case <-ticker.C:
+			if w.fileChanged() {
+				w.reload()
+			}
+		}
+	}
+}
+
+func (w *Watcher) fileChanged() bool {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		w.onError(fmt.Errorf("config.Watcher: stat config file: %w", err))
+		return false
+	}
+
+	if info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return false
+	}
+
+	w.modTime, w.size = info.ModTime(), info.Size()
+	return true
+}
+
+func (w *Watcher) reload() {
+	next, err := LoadPath(w.path)
+	if err != nil {
+		w.onError(fmt.Errorf("config.Watcher: reload rejected, keeping previous config: %w", err))
+		return
+	}
+
+	changes := Diff(w.current, next)
+	if len(changes) == 0 {
+		return
+	}
+
+	w.current = w.onReload(w.current, next, changes)
+}
+
+// Keep returns a copy of c with the settings named by keys, as Diff reports
+// them, taken from prev.
+func (c *Config) Keep(prev *Config, keys []string) *Config {
+	kept := *c
+	dst, src := reflect.ValueOf(&kept).Elem(), reflect.ValueOf(prev).Elem()
+	for _, key := range keys {
+		if index, ok := fieldByKey(dst.Type(), key); ok {
+			dst.FieldByIndex(index).Set(src.FieldByIndex(index))
+		}
+	}
+	return &kept
+}
+
+// fieldByKey finds the field a setting is read into by its env and
+// env-prefix tags.
+func fieldByKey(t reflect.Type, key string) ([]int, bool) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if env, ok := field.Tag.Lookup("env"); ok && env == key {
+			return []int{i}, true
+		}
+
+		prefix, ok := field.Tag.Lookup("env-prefix")
+		if !ok || field.Type.Kind() != reflect.Struct || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if index, found := fieldByKey(field.Type, strings.TrimPrefix(key, prefix)); found {
+			return append([]int{i}, index...), true
+		}
+	}
+	return nil, false
+}
+
+// Diff lists the settings that differ between two configurations and marks
+// the ones the running service cannot apply without a restart.
+func Diff(prev, next *Config) []Change {
+	var changes []Change
+
+	add := func(key string, prevValue, nextValue any, requiresRestart bool) {
+		if prevValue != nextValue {
+			changes = append(changes, Change{
+				Key:             key,
+				Old:             prevValue,
+				New:             nextValue,
+				RequiresRestart: requiresRestart,
+			})
+		}
+	}
+
+	addSecret := func(key string, prevValue, nextValue string) {
+		if prevValue != nextValue {
+			changes = append(changes, Change{Key: key, Old: _maskedValue, New: _maskedValue})
+		}
+	}
+
+	add("ENV", prev.Env, next.Env, true)
+
+	add("APP_NAME", prev.App.Name, next.App.Name, true)
+	add("APP_PORT", prev.App.Port, next.App.Port, true)
+	add("APP_VERSION", prev.App.Version, next.App.Version, true)
+
+	add("HTTP_HOST", prev.HTTP.Host, next.HTTP.Host, true)
+	add("HTTP_PORT", prev.HTTP.Port, next.HTTP.Port, true)
+	add("HTTP_READ_TIMEOUT", prev.HTTP.ReadTimeout, next.HTTP.ReadTimeout, true)
+	add("HTTP_WRITE_TIMEOUT", prev.HTTP.WriteTimeout, next.HTTP.WriteTimeout, true)
+	add("HTTP_IDLE_TIMEOUT", prev.HTTP.IdleTimeout, next.HTTP.IdleTimeout, true)
+	add("HTTP_READ_HEADER_TIMEOUT", prev.HTTP.ReadHeaderTimeout, next.HTTP.ReadHeaderTimeout, true)
+	add("HTTP_SHUTDOWN_TIMEOUT", prev.HTTP.ShutdownTimeout, next.HTTP.ShutdownTimeout, false)
+	add("HTTP_DRAIN_DELAY", prev.HTTP.DrainDelay, next.HTTP.DrainDelay, false)
+
+	add("CACHE_CAPACITY", prev.Cache.Capacity, next.Cache.Capacity, false)
+	add("CACHE_TTL", prev.Cache.TTL, next.Cache.TTL, false)
+	add("CACHE_CLEANUP_INTERVAL", prev.Cache.CleanupInterval, next.Cache.CleanupInterval, false)
+	add("CACHE_SHARDS", prev.Cache.Shards, next.Cache.Shards, true)
+	add("CACHE_POLICY", prev.Cache.Policy, next.Cache.Policy, true)
+	add("CACHE_REFRESH_AHEAD", prev.Cache.RefreshAhead, next.Cache.RefreshAhead, true)
+	add("CACHE_STALE_TTL", prev.Cache.StaleTTL, next.Cache.StaleTTL, true)
+	add("CACHE_NEGATIVE_TTL", prev.Cache.NegativeTTL, next.Cache.NegativeTTL, true)
+	add("CACHE_SNAPSHOT_PATH", prev.Cache.SnapshotPath, next.Cache.SnapshotPath, true)
+	add("CACHE_SNAPSHOT_CODEC", prev.Cache.SnapshotCodec, next.Cache.SnapshotCodec, true)
+	add("CACHE_REMOTE_ADDR", prev.Cache.RemoteAddr, next.Cache.RemoteAddr, true)
+	add("CACHE_REMOTE_PREFIX", prev.Cache.RemotePrefix, next.Cache.RemotePrefix, true)
+	add("CACHE_REMOTE_CHANNEL", prev.Cache.RemoteChannel, next.Cache.RemoteChannel, true)
+	add("CACHE_REMOTE_TIMEOUT", prev.Cache.RemoteTimeout, next.Cache.RemoteTimeout, true)
+	add("CACHE_NEAR_TTL", prev.Cache.NearTTL, next.Cache.NearTTL, true)
+
+	add("ATTACHMENT_DIR", prev.Attachment.Dir, next.Attachment.Dir, true)
+	add("ATTACHMENT_MAX_SIZE", prev.Attachment.MaxSize, next.Attachment.MaxSize, true)
+	add("ATTACHMENT_MAX_PER_EVENT", prev.Attachment.MaxPerEvent, next.Attachment.MaxPerEvent, true)
+	add("ATTACHMENT_CONTENT_TYPES", prev.Attachment.ContentTypes, next.Attachment.ContentTypes, true)
+	add("DIGEST_ENABLED", prev.Digest.Enabled, next.Digest.Enabled, true)
+	add("DIGEST_DIR", prev.Digest.Dir, next.Digest.Dir, true)
+	add("DIGEST_FROM", prev.Digest.From, next.Digest.From, true)
+	add("DIGEST_TICK_INTERVAL", prev.Digest.TickInterval, next.Digest.TickInterval, true)
+	add("GRAPHQL_ENABLED", prev.GraphQL.Enabled, next.GraphQL.Enabled, false)
+	add("GRAPHQL_MAX_DEPTH", prev.GraphQL.MaxDepth, next.GraphQL.MaxDepth, false)
+	add("GRAPHQL_MAX_COMPLEXITY", prev.GraphQL.MaxComplexity, next.GraphQL.MaxComplexity, false)
+	add("HOLIDAY_DIR", prev.Holiday.Dir, next.Holiday.Dir, true)
+	add("HOLIDAY_DEFAULT_COUNTRY", prev.Holiday.DefaultCountry, next.Holiday.DefaultCountry, true)
+	add("IDEMPOTENCY_TTL", prev.Idempotency.TTL, next.Idempotency.TTL, true)
+	add("IDEMPOTENCY_CAPACITY", prev.Idempotency.Capacity, next.Idempotency.Capacity, true)
+	add("OUTBOX_ENABLED", prev.Outbox.Enabled, next.Outbox.Enabled, true)
+	add("OUTBOX_BROKER", prev.Outbox.Broker, next.Outbox.Broker, true)
+	add("OUTBOX_KAFKA_BROKERS", prev.Outbox.KafkaBrokers, next.Outbox.KafkaBrokers, true)
+	add("OUTBOX_TOPIC", prev.Outbox.Topic, next.Outbox.Topic, true)
+	add("OUTBOX_POLL_INTERVAL", prev.Outbox.PollInterval, next.Outbox.PollInterval, true)
+	add("OUTBOX_BATCH_SIZE", prev.Outbox.BatchSize, next.Outbox.BatchSize, true)
+	add("PRIVACY_EXPORT_DIR", prev.Privacy.ExportDir, next.Privacy.ExportDir, true)
+	add("PRIVACY_EXPORT_TTL", prev.Privacy.ExportTTL, next.Privacy.ExportTTL, true)
+
+	add("RELOAD_ENABLED", prev.Reload.Enabled, next.Reload.Enabled, true)
+	add("RELOAD_POLL_INTERVAL", prev.Reload.PollInterval, next.Reload.PollInterval, true)
+
+	add("TENANT_HEADER", prev.Tenant.Header, next.Tenant.Header, false)
+	add("TENANT_REQUIRED", prev.Tenant.Required, next.Tenant.Required, false)
+	addSecret("TENANT_TOKEN_SECRET", prev.Tenant.TokenSecret, next.Tenant.TokenSecret)
+	add("TENANT_TOKEN_CLAIM", prev.Tenant.TokenClaim, next.Tenant.TokenClaim, false)
+	add("TENANT_MAX_EVENTS", prev.Tenant.MaxEvents, next.Tenant.MaxEvents, false)
+	add("TENANT_QUOTAS", prev.Tenant.Quotas, next.Tenant.Quotas, false)
+
+	addSecret("ADMIN_TOKEN", prev.Admin.Token, next.Admin.Token)
+
+	add("LOGGER_LEVEL", prev.Logger.Level, next.Logger.Level, false)
+	add("LOGGER_FILENAME", prev.Logger.Filename, next.Logger.Filename, true)
+	add("LOGGER_MAX_SIZE", prev.Logger.MaxSize, next.Logger.MaxSize, true)
+	add("LOGGER_MAX_BACKUPS", prev.Logger.MaxBackups, next.Logger.MaxBackups, true)
+	add("LOGGER_MAX_AGE", prev.Logger.MaxAge, next.Logger.MaxAge, true)
+	add("LOGGER_LEVELS", prev.Logger.Levels, next.Logger.Levels, false)
+	add("LOGGER_SAMPLING_INITIAL", prev.Logger.SamplingInitial, next.Logger.SamplingInitial, true)
+	add("LOGGER_SAMPLING_THEREAFTER", prev.Logger.SamplingThereafter, next.Logger.SamplingThereafter, true)
+	add("LOGGER_SAMPLING_TICK", prev.Logger.SamplingTick, next.Logger.SamplingTick, true)
+	add("LOGGER_SINKS", prev.Logger.Sinks, next.Logger.Sinks, true)
+	add("LOGGER_REDACT_KEYS", prev.Logger.RedactKeys, next.Logger.RedactKeys, true)
+	add("LOGGER_REDACT_DETECTORS", prev.Logger.RedactDetectors, next.Logger.RedactDetectors, true)
+	add("LOGGER_RING_SIZE", prev.Logger.RingSize, next.Logger.RingSize, true)
+	add("LOGGER_SYSLOG_NETWORK", prev.Logger.SyslogNetwork, next.Logger.SyslogNetwork, true)
+	add("LOGGER_SYSLOG_ADDR", prev.Logger.SyslogAddr, next.Logger.SyslogAddr, true)
+
+	return changes
+}
@@ -0,0 +1,189 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"calendar-wbf/internal/config"
+)
+
+// writeConfig writes .env.example with the lines of the overridden keys
+// replaced to path, or to a temporary file if path is empty, and returns
+// the path written.
+func writeConfig(t *testing.T, path string, overrides ...string) string {
+	t.Helper()
+
+	data, err := os.ReadFile("../../.env.example")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	for _, override := range overrides {
+		key, _, _ := strings.Cut(override, "=")
+		for i, line := range lines {
+			if strings.HasPrefix(line, key+"=") {
+				lines[i] = override
+			}
+		}
+	}
+
+	if path == "" {
+		path = filepath.Join(t.TempDir(), "config.env")
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func loadConfig(t *testing.T, overrides ...string) *config.Config {
+	t.Helper()
+
+	cfg, err := config.LoadPath(writeConfig(t, "", overrides...))
+	if err != nil {
+		t.Fatalf("LoadPath() error = %v", err)
+	}
+	return cfg
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc     string
+		override string
+		want     config.Change
+	}{
+		{
+			desc:     "RuntimeSetting",
+			override: "CACHE_CAPACITY=50",
+			want:     config.Change{Key: "CACHE_CAPACITY", Old: 1000, New: 50},
+		},
+		{
+			desc:     "RestartSetting",
+			override: "HTTP_PORT=9090",
+			want:     config.Change{Key: "HTTP_PORT", Old: "8080", New: "9090", RequiresRestart: true},
+		},
+		{
+			desc:     "SecretMasked",
+			override: "ADMIN_TOKEN=s3cret",
+			want:     config.Change{Key: "ADMIN_TOKEN", Old: "***", New: "***"},
+		},
+	}
+
+	prev := loadConfig(t)
+	if changes := config.Diff(prev, prev); len(changes) != 0 {
+		t.Errorf("Diff() of the same config = %+v; want none", changes)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			changes := config.Diff(prev, loadConfig(t, tc.override))
+			if len(changes) != 1 || changes[0] != tc.want {
+				t.Errorf("Diff() = %+v; want [%+v]", changes, tc.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Keep(t *testing.T) {
+	t.Parallel()
+
+	prev := loadConfig(t)
+	next := loadConfig(t, "CACHE_CAPACITY=50", "LOGGER_LEVEL=warn", "ENV=staging")
+
+	kept := next.Keep(prev, []string{"CACHE_CAPACITY", "ENV"})
+	if kept.Cache.Capacity != prev.Cache.Capacity || kept.Env != prev.Env {
+		t.Errorf("kept capacity, env = %d, %s; want %d, %s",
+			kept.Cache.Capacity, kept.Env, prev.Cache.Capacity, prev.Env)
+	}
+	if kept.Logger.Level != "warn" {
+		t.Errorf("kept logger level = %s; want warn", kept.Logger.Level)
+	}
+	if next.Cache.Capacity != 50 {
+		t.Errorf("Keep() modified the receiver: capacity = %d", next.Cache.Capacity)
+	}
+}
+
+func TestStore_PendingRestart(t *testing.T) {
+	t.Parallel()
+
+	started := loadConfig(t)
+	store := config.NewStore(started)
+
+	store.Update(loadConfig(t, "HTTP_PORT=9090", "CACHE_CAPACITY=50"))
+	if got := store.PendingRestart(); !slices.Equal(got, []string{"HTTP_PORT"}) {
+		t.Errorf("PendingRestart() = %v; want [HTTP_PORT]", got)
+	}
+
+	store.Update(loadConfig(t, "CACHE_CAPACITY=50"))
+	if got := store.PendingRestart(); len(got) != 0 {
+		t.Errorf("PendingRestart() after changing back = %v; want none", got)
+	}
+	if store.Load().Cache.Capacity != 50 {
+		t.Errorf("Load() capacity = %d; want 50", store.Load().Cache.Capacity)
+	}
+}
+
+func TestWatcher_Run(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfig(t, "", "RELOAD_POLL_INTERVAL=100ms")
+	cfg, err := config.LoadPath(path)
+	if err != nil {
+		t.Fatalf("LoadPath() error = %v", err)
+	}
+
+	reloads := make(chan []config.Change, 2)
+	onReload := func(prev, next *config.Config, changes []config.Change) *config.Config {
+		reloads <- changes
+		// Refuse the capacity change, so the next reload reports it again.
+		return next.Keep(prev, []string{"CACHE_CAPACITY"})
+	}
+	onError := func(err error) { t.Errorf("onError(%v)", err) }
+
+	w, err := config.NewWatcher(cfg, onReload, onError)
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	}()
+
+	wantKeys := func(want ...string) {
+		t.Helper()
+		select {
+		case changes := <-reloads:
+			var keys []string
+			for _, change := range changes {
+				keys = append(keys, change.Key)
+			}
+			if !slices.Equal(keys, want) {
+				t.Errorf("reloaded keys = %v; want %v", keys, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no reload; want keys %v", want)
+		}
+	}
+
+	writeConfig(t, path, "RELOAD_POLL_INTERVAL=100ms", "CACHE_CAPACITY=50")
+	wantKeys("CACHE_CAPACITY")
+
+	writeConfig(t, path, "RELOAD_POLL_INTERVAL=100ms", "CACHE_CAPACITY=50", "LOGGER_LEVEL=warn")
+	wantKeys("CACHE_CAPACITY", "LOGGER_LEVEL")
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"sync"
+)
+
+// Store holds the configuration in effect. Settings that need a restart
+// are pending while they differ from the ones the process started with.
+type Store struct {
+	mu             sync.RWMutex
+	started        *Config
+	current        *Config
+	pendingRestart []string
+}
+
+func NewStore(cfg *Config) *Store {
+	return &Store{started: cfg, current: cfg}
+}
+
+func (s *Store) Load() *Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current
+}
+
+// PendingRestart lists the keys changed on disk that the running process
+// has not applied yet.
+func (s *Store) PendingRestart() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string{}, s.pendingRestart...)
+}
+
+func (s *Store) Update(cfg *Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.current = cfg
+	s.pendingRestart = nil
+	for _, change := range Diff(s.started, cfg) {
+		if change.RequiresRestart {
+			s.pendingRestart = append(s.pendingRestart, change.Key)
+		}
+	}
+}
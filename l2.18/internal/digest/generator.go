@@ -0,0 +1,104 @@
+package digest
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"calendar-wbf/internal/entity"
+)
+
+const _daysPerWeek = 7
+
+type (
+	EventSource interface {
+		GetEventsForDay(
+			ctx context.Context,
+			userID uint64,
+			date time.Time,
+			filter entity.TagFilter,
+		) ([]*entity.Event, error)
+		GetEventsForWeek(
+			ctx context.Context,
+			userID uint64,
+			startDate time.Time,
+			filter entity.TagFilter,
+		) ([]*entity.Event, error)
+	}
+
+	Generator struct {
+		events   EventSource
+		renderer *Renderer
+	}
+)
+
+func NewGenerator(events EventSource, renderer *Renderer) *Generator {
+	return &Generator{
+		events:   events,
+		renderer: renderer,
+	}
+}
+
+// Generate builds the agenda that starts on the local day of now in loc:
+// that single day for daily digests, the next seven days for weekly ones.
+func (g *Generator) Generate(
+	ctx context.Context,
+	userID uint64,
+	period entity.DigestPeriod,
+	format entity.DigestFormat,
+	now time.Time,
+	loc *time.Location,
+) (*entity.Digest, error) {
+	const op = "digest.Generate"
+
+	local := now.In(loc)
+	from := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+	var (
+		events  []*entity.Event
+		days    int
+		subject string
+		err     error
+	)
+
+	switch period {
+	case entity.DigestDaily:
+		days = 1
+		subject = "Повестка на " + dateLabel(from)
+		events, err = g.events.GetEventsForDay(ctx, userID, from, entity.TagFilter{})
+	case entity.DigestWeekly:
+		days = _daysPerWeek
+		subject = fmt.Sprintf("Повестка на неделю: %s – %s", dateLabel(from), dateLabel(from.AddDate(0, 0, days-1)))
+		events, err = g.events.GetEventsForWeek(ctx, userID, from, entity.TagFilter{})
+	default:
+		return nil, fmt.Errorf("%s: unknown period %q: %w", op, period, entity.ErrInvalidDigest)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: load events: %w", op, err)
+	}
+
+	events = slices.Clone(events)
+	slices.SortStableFunc(events, func(a, b *entity.Event) int {
+		return cmp.Or(a.Date.Compare(b.Date), cmp.Compare(a.ID, b.ID))
+	})
+
+	body, contentType, err := g.renderer.Render(format, buildTemplateData(subject, from, days, events))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &entity.Digest{
+		TenantID:    entity.TenantFromContext(ctx),
+		UserID:      userID,
+		Period:      period,
+		Format:      format,
+		From:        from,
+		Until:       from.AddDate(0, 0, days),
+		Subject:     subject,
+		ContentType: contentType,
+		Body:        body,
+		Events:      len(events),
+	}, nil
+}
@@ -0,0 +1,121 @@
+package digest_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"calendar-wbf/internal/digest"
+	"calendar-wbf/internal/entity"
+)
+
+type stubEvents struct {
+	events []*entity.Event
+}
+
+func (s stubEvents) GetEventsForDay(
+	_ context.Context,
+	_ uint64,
+	date time.Time,
+	_ entity.TagFilter,
+) ([]*entity.Event, error) {
+	var result []*entity.Event
+	for _, event := range s.events {
+		if entity.DayKey(event.Date.In(date.Location())) == entity.DayKey(date) {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+func (s stubEvents) GetEventsForWeek(
+	_ context.Context,
+	_ uint64,
+	_ time.Time,
+	_ entity.TagFilter,
+) ([]*entity.Event, error) {
+	return s.events, nil
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	t.Parallel()
+
+	loc, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+
+	now := time.Date(2026, time.October, 19, 8, 0, 0, 0, loc)
+	events := stubEvents{events: []*entity.Event{
+		{ID: 2, Date: now.Add(5 * time.Hour), Duration: time.Hour, Title: "Lunch <with> *Anna*", Tags: []string{"personal"}},
+		{ID: 1, Date: now.Add(2 * time.Hour), Duration: 30 * time.Minute, Title: "Standup", Color: "#ff0000"},
+		{ID: 3, Date: now.AddDate(0, 0, 2), Duration: time.Hour, Title: "Review"},
+	}}
+
+	renderer, err := digest.NewRenderer()
+	if err != nil {
+		t.Fatalf("NewRenderer() error = %v", err)
+	}
+	generator := digest.NewGenerator(events, renderer)
+
+	testCases := []struct {
+		desc        string
+		period      entity.DigestPeriod
+		format      entity.DigestFormat
+		contentType string
+		contains    []string
+		excludes    []string
+	}{
+		{
+			desc:        "DailyText",
+			period:      entity.DigestDaily,
+			format:      entity.DigestText,
+			contentType: "text/plain; charset=utf-8",
+			contains:    []string{"Повестка на 19 октября", "Понедельник, 19 октября", "10:00–10:30  Standup", "[personal]"},
+			excludes:    []string{"Review"},
+		},
+		{
+			desc:        "DailyMarkdownEscapes",
+			period:      entity.DigestDaily,
+			format:      entity.DigestMarkdown,
+			contentType: "text/markdown; charset=utf-8",
+			contains:    []string{"# Повестка на 19 октября", `Lunch \<with> \*Anna\*`, "`#personal`"},
+		},
+		{
+			desc:        "WeeklyHTMLEscapes",
+			period:      entity.DigestWeekly,
+			format:      entity.DigestHTML,
+			contentType: "text/html; charset=utf-8",
+			contains:    []string{"19 октября – 25 октября", "Lunch &lt;with&gt; *Anna*", "Среда, 21 октября", "#ff0000"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := generator.Generate(context.Background(), 1, tc.period, tc.format, now, loc)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			if got.ContentType != tc.contentType {
+				t.Errorf("ContentType = %q; want %q", got.ContentType, tc.contentType)
+			}
+			for _, want := range tc.contains {
+				if !strings.Contains(got.Body, want) {
+					t.Errorf("Body does not contain %q:\n%s", want, got.Body)
+				}
+			}
+			for _, unwanted := range tc.excludes {
+				if strings.Contains(got.Body, unwanted) {
+					t.Errorf("Body unexpectedly contains %q:\n%s", unwanted, got.Body)
+				}
+			}
+			if strings.Index(got.Body, "Standup") > strings.Index(got.Body, "Lunch") {
+				t.Errorf("events are not sorted by start time:\n%s", got.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package digest
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"calendar-wbf/internal/entity"
+)
+
+//go:embed templates/*.tmpl
+var templatesFS embed.FS
+
+const (
+	_contentTypeText     = "text/plain; charset=utf-8"
+	_contentTypeHTML     = "text/html; charset=utf-8"
+	_contentTypeMarkdown = "text/markdown; charset=utf-8"
+
+	_clockLayout = "15:04"
+)
+
+type (
+	templateData struct {
+		Title string
+		Days  []dayData
+		Total int
+	}
+
+	dayData struct {
+		Label  string
+		Events []eventData
+	}
+
+	eventData struct {
+		Start  string
+		End    string
+		AllDay bool
+		Title  string
+		Text   string
+		Tags   []string
+		Color  string
+	}
+
+	Renderer struct {
+		text     *texttemplate.Template
+		markdown *texttemplate.Template
+		html     *htmltemplate.Template
+	}
+)
+
+func NewRenderer() (*Renderer, error) {
+	const op = "digest.NewRenderer"
+
+	funcs := texttemplate.FuncMap{
+		"join": strings.Join,
+		"md":   escapeMarkdown,
+	}
+
+	text, err := texttemplate.New("digest.txt.tmpl").Funcs(funcs).ParseFS(templatesFS, "templates/digest.txt.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("%s: parse text template: %w", op, err)
+	}
+
+	markdown, err := texttemplate.New("digest.md.tmpl").Funcs(funcs).ParseFS(templatesFS, "templates/digest.md.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("%s: parse markdown template: %w", op, err)
+	}
+
+	html, err := htmltemplate.New("digest.html.tmpl").ParseFS(templatesFS, "templates/digest.html.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("%s: parse html template: %w", op, err)
+	}
+
+	return &Renderer{text: text, markdown: markdown, html: html}, nil
+}
+
+// Render executes the template for format and returns the body together with
+// its MIME content type.
+func (r *Renderer) Render(format entity.DigestFormat, data templateData) (string, string, error) {
+	const op = "digest.Render"
+
+	var (
+		tmpl interface {
+			Execute(w io.Writer, data any) error
+		}
+		contentType string
+	)
+
+	switch format {
+	case entity.DigestText:
+		tmpl, contentType = r.text, _contentTypeText
+	case entity.DigestMarkdown:
+		tmpl, contentType = r.markdown, _contentTypeMarkdown
+	case entity.DigestHTML:
+		tmpl, contentType = r.html, _contentTypeHTML
+	default:
+		return "", "", fmt.Errorf("%s: unknown format %q: %w", op, format, entity.ErrInvalidDigest)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", "", fmt.Errorf("%s: execute %s template: %w", op, format, err)
+	}
+
+	return buf.String(), contentType, nil
+}
+
+func buildTemplateData(title string, from time.Time, days int, events []*entity.Event) templateData {
+	loc := from.Location()
+	data := templateData{Title: title, Total: len(events)}
+
+	byDay := make(map[string][]eventData, days)
+	for _, event := range events {
+		start := event.Date.In(loc)
+		view := eventData{
+			Start: start.Format(_clockLayout),
+			End:   event.End().In(loc).Format(_clockLayout),
+			Title: event.Title,
+			Text:  event.Text,
+			Tags:  event.Tags,
+			Color: event.Color,
+		}
+		view.AllDay = event.Duration == 0 && start.Hour() == 0 && start.Minute() == 0
+		if event.Duration == 0 {
+			view.End = ""
+		}
+
+		key := entity.DayKey(start)
+		byDay[key] = append(byDay[key], view)
+	}
+
+	for i := range days {
+		day := from.AddDate(0, 0, i)
+		if dayEvents, ok := byDay[entity.DayKey(day)]; ok {
+			data.Days = append(data.Days, dayData{Label: dayLabel(day), Events: dayEvents})
+		}
+	}
+
+	return data
+}
+
+func dayLabel(day time.Time) string {
+	weekdays := [...]string{"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"}
+	return fmt.Sprintf("%s, %s", capitalize(weekdays[day.Weekday()]), dateLabel(day))
+}
+
+func dateLabel(day time.Time) string {
+	months := [...]string{
+		"января", "февраля", "марта", "апреля", "мая", "июня",
+		"июля", "августа", "сентября", "октября", "ноября", "декабря",
+	}
+	return fmt.Sprintf("%d %s", day.Day(), months[day.Month()-1])
+}
+
+func capitalize(s string) string {
+	for i := range s {
+		if i > 0 {
+			return strings.ToUpper(s[:i]) + s[i:]
+		}
+	}
+	return strings.ToUpper(s)
+}
+
+func escapeMarkdown(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "#", `\#`, "<", `\<`,
+	)
+	return replacer.Replace(s)
+}
@@ -0,0 +1,165 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/logger"
+)
+
+type (
+	SubscriptionRepo interface {
+		List(ctx context.Context) ([]*entity.DigestSubscription, error)
+		MarkSent(ctx context.Context, userID uint64, at time.Time) error
+	}
+
+	Scheduler struct {
+		subs      SubscriptionRepo
+		generator *Generator
+		sender    Sender
+		log       logger.Logger
+		interval  time.Duration
+		now       func() time.Time
+	}
+)
+
+func NewScheduler(
+	subs SubscriptionRepo,
+	generator *Generator,
+	sender Sender,
+	log logger.Logger,
+	interval time.Duration,
+) *Scheduler {
+	return &Scheduler{
+		subs:      subs,
+		generator: generator,
+		sender:    sender,
+		log:       log,
+		interval:  interval,
+		now:       time.Now,
+	}
+}
+
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.log.Infow("digest scheduler started", "interval", s.interval.String())
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.Tick(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Infow("digest scheduler stopped")
+			return nil
+		case <-ticker.C:
+			s.Tick(ctx)
+		}
+	}
+}
+
+// Tick delivers every digest that is due at the current moment. Failures are
+// logged and retried on the next tick since the subscription is not marked sent.
+func (s *Scheduler) Tick(ctx context.Context) {
+	const op = "digest.Tick"
+
+	subs, err := s.subs.List(ctx)
+	if err != nil {
+		s.log.LogAttrs(ctx, logger.ErrorLevel, "failed to list digest subscriptions",
+			logger.String("op", op),
+			logger.Any("error", err),
+		)
+		return
+	}
+
+	now := s.now()
+	for _, sub := range subs {
+		if ctx.Err() != nil {
+			return
+		}
+
+		due, dueErr := IsDue(sub, now)
+		if dueErr != nil {
+			s.log.LogAttrs(ctx, logger.WarnLevel, "invalid digest subscription",
+				logger.String("op", op),
+				logger.Uint64("user_id", sub.UserID),
+				logger.Any("error", dueErr),
+			)
+			continue
+		}
+		if !due {
+			continue
+		}
+
+		// Subscriptions of all tenants are listed together; each one is
+		// delivered in its own tenant.
+		subCtx := entity.WithTenant(ctx, sub.TenantID)
+		subCtx = s.log.WithTenantID(subCtx, entity.TenantFromContext(subCtx))
+
+		if deliverErr := s.deliver(subCtx, sub, now); deliverErr != nil {
+			s.log.LogAttrs(subCtx, logger.ErrorLevel, "digest delivery failed",
+				logger.String("op", op),
+				logger.Uint64("user_id", sub.UserID),
+				logger.Any("error", deliverErr),
+			)
+		}
+	}
+}
+
+func (s *Scheduler) deliver(ctx context.Context, sub *entity.DigestSubscription, now time.Time) error {
+	loc, err := time.LoadLocation(sub.Timezone)
+	if err != nil {
+		return fmt.Errorf("load location: %w", err)
+	}
+
+	digest, err := s.generator.Generate(ctx, sub.UserID, sub.Period, sub.Format, now, loc)
+	if err != nil {
+		return err
+	}
+	digest.To = sub.Email
+
+	if err = s.sender.Send(ctx, digest); err != nil {
+		return fmt.Errorf("send: %w", err)
+	}
+
+	if err = s.subs.MarkSent(ctx, sub.UserID, now); err != nil {
+		return fmt.Errorf("mark sent: %w", err)
+	}
+
+	s.log.LogAttrs(ctx, logger.InfoLevel, "digest delivered",
+		logger.Uint64("user_id", sub.UserID),
+		logger.String("period", string(sub.Period)),
+		logger.Int("events", digest.Events),
+	)
+
+	return nil
+}
+
+// IsDue reports whether the subscription's local send time has passed today
+// and nothing has been sent since. Weekly digests only go out on their weekday.
+func IsDue(sub *entity.DigestSubscription, now time.Time) (bool, error) {
+	loc, err := time.LoadLocation(sub.Timezone)
+	if err != nil {
+		return false, fmt.Errorf("digest.IsDue: %w", entity.ErrInvalidDigest)
+	}
+
+	sendAt, err := time.Parse(_clockLayout, sub.SendAt)
+	if err != nil {
+		return false, fmt.Errorf("digest.IsDue: send_at %q: %w", sub.SendAt, entity.ErrInvalidDigest)
+	}
+
+	local := now.In(loc)
+	scheduled := time.Date(local.Year(), local.Month(), local.Day(), sendAt.Hour(), sendAt.Minute(), 0, 0, loc)
+
+	if local.Before(scheduled) {
+		return false, nil
+	}
+	if sub.Period == entity.DigestWeekly && local.Weekday() != sub.Weekday {
+		return false, nil
+	}
+
+	return sub.LastSent.Before(scheduled), nil
+}
@@ -0,0 +1,98 @@
+package digest_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"calendar-wbf/internal/digest"
+	"calendar-wbf/internal/entity"
+)
+
+func TestIsDue(t *testing.T) {
+	t.Parallel()
+
+	// 05:30 UTC is 08:30 in Moscow, on a Monday.
+	now := time.Date(2026, time.October, 19, 5, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc string
+		sub  entity.DigestSubscription
+		want bool
+	}{
+		{
+			desc: "DailyAfterSendTime",
+			sub:  entity.DigestSubscription{Timezone: "Europe/Moscow", SendAt: "08:00", Period: entity.DigestDaily},
+			want: true,
+		},
+		{
+			desc: "DailyBeforeSendTime",
+			sub:  entity.DigestSubscription{Timezone: "Europe/Moscow", SendAt: "09:00", Period: entity.DigestDaily},
+			want: false,
+		},
+		{
+			desc: "LocalTimeZoneMatters",
+			sub:  entity.DigestSubscription{Timezone: "America/New_York", SendAt: "08:00", Period: entity.DigestDaily},
+			want: false,
+		},
+		{
+			desc: "AlreadySentToday",
+			sub: entity.DigestSubscription{
+				Timezone: "Europe/Moscow", SendAt: "08:00", Period: entity.DigestDaily,
+				LastSent: now.Add(-10 * time.Minute),
+			},
+			want: false,
+		},
+		{
+			desc: "SentYesterday",
+			sub: entity.DigestSubscription{
+				Timezone: "Europe/Moscow", SendAt: "08:00", Period: entity.DigestDaily,
+				LastSent: now.Add(-24 * time.Hour),
+			},
+			want: true,
+		},
+		{
+			desc: "WeeklyOnItsWeekday",
+			sub: entity.DigestSubscription{
+				Timezone: "Europe/Moscow", SendAt: "08:00", Period: entity.DigestWeekly, Weekday: time.Monday,
+			},
+			want: true,
+		},
+		{
+			desc: "WeeklyOnOtherWeekday",
+			sub: entity.DigestSubscription{
+				Timezone: "Europe/Moscow", SendAt: "08:00", Period: entity.DigestWeekly, Weekday: time.Friday,
+			},
+			want: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := digest.IsDue(&tc.sub, now)
+			if err != nil {
+				t.Fatalf("IsDue() error = %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("IsDue() = %v; want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsDue_InvalidSubscription(t *testing.T) {
+	t.Parallel()
+
+	subs := []entity.DigestSubscription{
+		{Timezone: "Mars/Olympus", SendAt: "08:00"},
+		{Timezone: "UTC", SendAt: "8 am"},
+	}
+
+	for _, sub := range subs {
+		if _, err := digest.IsDue(&sub, time.Now()); !errors.Is(err, entity.ErrInvalidDigest) {
+			t.Errorf("IsDue(%+v) error = %v; want %v", sub, err, entity.ErrInvalidDigest)
+		}
+	}
+}
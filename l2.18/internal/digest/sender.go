@@ -0,0 +1,76 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"time"
+
+	"calendar-wbf/internal/entity"
+)
+
+const (
+	_dirPerm  = 0o750
+	_filePerm = 0o640
+)
+
+type (
+	Sender interface {
+		Send(ctx context.Context, digest *entity.Digest) error
+	}
+
+	// FileSender stands in for an SMTP relay: every digest is written to dir
+	// as an RFC 5322 message that any mail client can open or re-send.
+	FileSender struct {
+		dir  string
+		from string
+		now  func() time.Time
+	}
+)
+
+func NewFileSender(dir, from string) (*FileSender, error) {
+	if err := os.MkdirAll(dir, _dirPerm); err != nil {
+		return nil, fmt.Errorf("digest.NewFileSender: create %s: %w", dir, err)
+	}
+
+	return &FileSender{
+		dir:  dir,
+		from: from,
+		now:  time.Now,
+	}, nil
+}
+
+func (s *FileSender) Send(ctx context.Context, digest *entity.Digest) error {
+	const op = "digest.FileSender.Send"
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	now := s.now()
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.from)
+	if digest.To != "" {
+		fmt.Fprintf(&msg, "To: %s\r\n", digest.To)
+	}
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", digest.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "X-Calendar-Tenant-ID: %s\r\n", digest.TenantID)
+	fmt.Fprintf(&msg, "X-Calendar-User-ID: %d\r\n", digest.UserID)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: %s\r\n", digest.ContentType)
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(digest.Body)
+
+	name := fmt.Sprintf("%s-%d-%s-%s.eml",
+		digest.TenantID, digest.UserID, digest.Period, now.UTC().Format("20060102T150405.000000000"))
+	if err := os.WriteFile(filepath.Join(s.dir, name), msg.Bytes(), _filePerm); err != nil {
+		return fmt.Errorf("%s: write %s: %w", op, name, err)
+	}
+
+	return nil
+}
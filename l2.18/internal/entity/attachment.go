@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"strconv"
+	"time"
+)
+
+// Attachment is the metadata of a file attached to an event. The content
+// lives in the blob store under BlobKey.
+type Attachment struct {
+	ID          string    `json:"id"`
+	TenantID    string    `json:"tenant_id,omitempty"`
+	EventID     uint64    `json:"event_id"`
+	UserID      uint64    `json:"user_id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// BlobKey is where the content of the attachment is stored.
+func (a *Attachment) BlobKey() string {
+	return a.TenantID + "/" + strconv.FormatUint(a.EventID, 10) + "/" + a.ID
+}
@@ -0,0 +1,43 @@
+package entity
+
+import "time"
+
+type (
+	DigestPeriod string
+	DigestFormat string
+)
+
+const (
+	DigestDaily  DigestPeriod = "daily"
+	DigestWeekly DigestPeriod = "weekly"
+
+	DigestText     DigestFormat = "text"
+	DigestHTML     DigestFormat = "html"
+	DigestMarkdown DigestFormat = "markdown"
+)
+
+type DigestSubscription struct {
+	TenantID string       `json:"tenant_id,omitempty"`
+	UserID   uint64       `json:"user_id"`
+	Email    string       `json:"email"`
+	Timezone string       `json:"timezone"`
+	SendAt   string       `json:"send_at"`
+	Weekday  time.Weekday `json:"weekday"`
+	Period   DigestPeriod `json:"period"`
+	Format   DigestFormat `json:"format"`
+	LastSent time.Time    `json:"last_sent,omitzero"`
+}
+
+type Digest struct {
+	TenantID    string       `json:"tenant_id,omitempty"`
+	UserID      uint64       `json:"user_id"`
+	To          string       `json:"to,omitempty"`
+	Period      DigestPeriod `json:"period"`
+	Format      DigestFormat `json:"format"`
+	From        time.Time    `json:"from"`
+	Until       time.Time    `json:"until"`
+	Subject     string       `json:"subject"`
+	ContentType string       `json:"content_type"`
+	Body        string       `json:"body"`
+	Events      int          `json:"events"`
+}
@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DomainEventType names a change to an event as published to the broker.
+type DomainEventType string
+
+const (
+	EventCreated DomainEventType = "event.created"
+	EventUpdated DomainEventType = "event.updated"
+	EventDeleted DomainEventType = "event.deleted"
+)
+
+// DomainEvent records one change to a calendar event. Event is the state
+// after the change and is left out of deletions. ID is unique per change,
+// so consumers can drop redeliveries.
+type DomainEvent struct {
+	ID         string          `json:"id"`
+	Type       DomainEventType `json:"type"`
+	TenantID   string          `json:"tenant_id,omitempty"`
+	UserID     uint64          `json:"user_id"`
+	EventID    uint64          `json:"event_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Event      *Event          `json:"event,omitempty"`
+}
+
+// OutboxMessage is a domain event stored with the change that raised it
+// and kept until the relay has published it. Seq follows the order of the
+// changes.
+type OutboxMessage struct {
+	Seq       uint64
+	Event     DomainEvent
+	CreatedAt time.Time
+}
+
+// NewDomainEvent describes a change to the stored event, copying it so
+// later changes do not alter what is published.
+func NewDomainEvent(eventType DomainEventType, event *Event, at time.Time) DomainEvent {
+	domainEvent := DomainEvent{
+		ID:         uuid.NewString(),
+		Type:       eventType,
+		TenantID:   event.TenantID,
+		UserID:     event.UserID,
+		EventID:    event.ID,
+		OccurredAt: at,
+	}
+	if eventType != EventDeleted {
+		snapshot := *event
+		domainEvent.Event = &snapshot
+	}
+	return domainEvent
+}
+
+// Key groups the changes of one event, so a partitioned broker keeps them
+// in order.
+func (e DomainEvent) Key() string {
+	return e.TenantID + "/" + strconv.FormatUint(e.EventID, 10)
+}
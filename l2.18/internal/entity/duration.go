@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Duration is how event durations travel as JSON: a Go duration string such
+// as "1h30m" is written, and either such a string or a whole number of
+// minutes is read.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(FormatDuration(time.Duration(d)))
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: want a duration such as \"30m\"", s)
+		}
+		*d = Duration(parsed)
+		return nil
+	}
+
+	var minutes int64
+	if err := json.Unmarshal(data, &minutes); err != nil || minutes > math.MaxInt64/int64(time.Minute) ||
+		minutes < math.MinInt64/int64(time.Minute) {
+		return fmt.Errorf("invalid duration %s: want a duration such as \"30m\" or whole minutes", data)
+	}
+	*d = Duration(time.Duration(minutes) * time.Minute)
+	return nil
+}
+
+// FormatDuration writes d without trailing zero units: 1h30m, 2h, 45s.
+func FormatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,70 @@
+package entity_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"calendar-wbf/internal/entity"
+)
+
+func TestDuration_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc      string
+		input     string
+		want      time.Duration
+		wantError bool
+	}{
+		{"String", `"30m"`, 30 * time.Minute, false},
+		{"CompoundString", `"1h30m"`, 90 * time.Minute, false},
+		{"Minutes", `45`, 45 * time.Minute, false},
+		{"Null", `null`, 0, false},
+		{"InvalidString", `"half an hour"`, 0, true},
+		{"FractionalMinutes", `1.5`, 0, true},
+		{"Overflow", `9223372036854775807`, 0, true},
+		{"Object", `{}`, 0, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var got entity.Duration
+			err := json.Unmarshal([]byte(tc.input), &got)
+			if (err != nil) != tc.wantError {
+				t.Fatalf("Unmarshal(%s) error = %v; wantError %v", tc.input, err, tc.wantError)
+			}
+			if time.Duration(got) != tc.want {
+				t.Errorf("Unmarshal(%s) = %v; want %v", tc.input, time.Duration(got), tc.want)
+			}
+		})
+	}
+}
+
+func TestEvent_JSONDuration(t *testing.T) {
+	t.Parallel()
+
+	event := entity.Event{ID: 1, UserID: 7, Title: "Standup", Duration: 90 * time.Minute}
+	data, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"duration":"1h30m"`) || strings.Count(string(data), `"duration"`) != 1 {
+		t.Errorf("Marshal() = %s; want a single duration of \"1h30m\"", data)
+	}
+
+	var decoded entity.Event
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if decoded.Duration != event.Duration || decoded.Title != event.Title || decoded.UserID != event.UserID {
+		t.Errorf("Unmarshal() = %+v; want %+v", decoded, event)
+	}
+
+	if data, _ = json.Marshal(entity.Event{ID: 2}); strings.Contains(string(data), "duration") {
+		t.Errorf("Marshal() = %s; want no duration for an event without one", data)
+	}
+}
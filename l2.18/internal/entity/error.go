@@ -5,10 +5,32 @@ import (
 )
 
 var (
-	ErrEventNotFound    = errors.New("event not found")
-	ErrInvalidData      = errors.New("invalid data")
-	ErrInvalidDate      = errors.New("invalid date")
-	ErrInvalidUserID    = errors.New("invalid user_id")
-	ErrDuplicateEvent   = errors.New("event already exists")
-	ErrConfigPathNotSet = errors.New("CONFIG_PATH not set and -config flag not provided")
+	ErrEventNotFound        = errors.New("event not found")
+	ErrInvalidData          = errors.New("invalid data")
+	ErrInvalidDate          = errors.New("invalid date")
+	ErrInvalidUserID        = errors.New("invalid user_id")
+	ErrDuplicateEvent       = errors.New("event already exists")
+	ErrPreconditionFailed   = errors.New("event does not match the precondition")
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different request")
+	ErrTagNotFound          = errors.New("tag not found")
+	ErrInvalidTag           = errors.New("invalid tag")
+	ErrInvalidColor         = errors.New("invalid color")
+	ErrInvalidTimezone      = errors.New("invalid timezone")
+	ErrUnparsableText       = errors.New("could not parse event text")
+	ErrDigestNotFound       = errors.New("digest subscription not found")
+	ErrInvalidDigest        = errors.New("invalid digest settings")
+	ErrInvalidTenant        = errors.New("invalid tenant")
+	ErrInvalidSchedule      = errors.New("invalid work schedule")
+	ErrScheduleNotFound     = errors.New("work schedule not found")
+	ErrHolidaysNotCovered   = errors.New("no holiday data for the year")
+	ErrQuotaExceeded        = errors.New("tenant event quota exceeded")
+	ErrAttachmentNotFound   = errors.New("attachment not found")
+	ErrAttachmentTooLarge   = errors.New("attachment too large")
+	ErrTooManyAttachments   = errors.New("too many attachments")
+	ErrUnsupportedMediaType = errors.New("unsupported attachment content type")
+	ErrTemplateNotFound     = errors.New("template not found")
+	ErrInvalidTemplate      = errors.New("invalid template")
+	ErrExportNotFound       = errors.New("export not found")
+	ErrExportNotReady       = errors.New("export is not ready")
+	ErrConfigPathNotSet     = errors.New("CONFIG_PATH not set and -config flag not provided")
 )
@@ -1,13 +1,186 @@
 package entity
 
-import "time"
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"calendar-wbf/pkg/ical"
+)
 
 type Event struct {
-	ID        uint64    `json:"id"         validate:"required,gte=1"`
-	UserID    uint64    `json:"user_id"    validate:"required,gte=1"`
-	Date      time.Time `json:"date"       validate:"required"`
-	Title     string    `json:"title"      validate:"required,max=50"`
-	Text      string    `json:"text"       validate:"required,max=255"`
-	CreatedAt time.Time `json:"created_at" validate:"required"`
-	UpdatedAt time.Time `json:"updated_at" validate:"required"`
+	ID        uint64        `json:"id"                  validate:"required,gte=1"`
+	TenantID  string        `json:"tenant_id,omitempty"`
+	UserID    uint64        `json:"user_id"             validate:"required,gte=1"`
+	UID       string        `json:"uid,omitempty"       validate:"max=255"`
+	Date      time.Time     `json:"date"                validate:"required"`
+	Duration  time.Duration `json:"duration,omitempty"  validate:"gte=0"`
+	Title     string        `json:"title"               validate:"required,max=50"`
+	Text      string        `json:"text"                validate:"required,max=10000"`
+	TextHTML  string        `json:"text_html,omitempty"`
+	Tags      []string      `json:"tags,omitempty"      validate:"max=10,dive,max=30"`
+	Color     string        `json:"color,omitempty"     validate:"omitempty,hexcolor"`
+	Attendees []Attendee    `json:"attendees,omitempty" validate:"max=100,dive"`
+	Kind      EventKind     `json:"kind,omitempty"`
+	ReadOnly  bool          `json:"read_only,omitempty"`
+	CreatedAt time.Time     `json:"created_at"          validate:"required"`
+	UpdatedAt time.Time     `json:"updated_at"          validate:"required"`
+}
+
+// Attendee is a participant of an event. Calendar clients set them over
+// CalDAV; Status is the participation status in lower case, e.g. "accepted",
+// or empty when the client gave none.
+type Attendee struct {
+	Email  string `json:"email"            validate:"required,max=254"`
+	Name   string `json:"name,omitempty"   validate:"max=255"`
+	Status string `json:"status,omitempty" validate:"max=30"`
+}
+
+// EventMatch reports whether a conditional change applies to the stored
+// version of an event, e.g. whether its ETag is one the client named.
+type EventMatch func(stored *Event) bool
+
+type TagFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// MarshalJSON writes Duration as a duration string, see Duration.
+func (e Event) MarshalJSON() ([]byte, error) {
+	type event Event
+	var duration *Duration
+	if e.Duration != 0 {
+		duration = (*Duration)(&e.Duration)
+	}
+
+	return json.Marshal(struct {
+		event
+		Duration *Duration `json:"duration,omitempty"`
+	}{event(e), duration})
+}
+
+func (e *Event) UnmarshalJSON(data []byte) error {
+	type event Event
+	aux := struct {
+		*event
+		Duration *Duration `json:"duration"`
+	}{event: (*event)(e)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.Duration != nil {
+		e.Duration = time.Duration(*aux.Duration)
+	}
+	return nil
+}
+
+func (e *Event) End() time.Time {
+	return e.Date.Add(e.Duration)
+}
+
+// IsAllDay reports whether the event covers whole days in its own location,
+// as DATE events imported from clients and all-day quick adds do.
+func (e *Event) IsAllDay() bool {
+	const day = 24 * time.Hour
+	h, m, s := e.Date.Clock()
+
+	return h == 0 && m == 0 && s == 0 && e.Date.Nanosecond() == 0 &&
+		e.Duration > 0 && e.Duration%day == 0
+}
+
+// ICal maps the event to a VEVENT. Events created through the API have no
+// UID and use their ID instead.
+func (e *Event) ICal() ical.Event {
+	uid := e.UID
+	if uid == "" {
+		uid = strconv.FormatUint(e.ID, 10)
+	}
+
+	return ical.Event{
+		UID:          uid,
+		Start:        e.Date,
+		End:          e.End(),
+		AllDay:       e.IsAllDay(),
+		Summary:      e.Title,
+		Description:  e.Text,
+		Categories:   e.Tags,
+		Color:        e.Color,
+		Attendees:    ICalAttendees(e.Attendees),
+		Created:      e.CreatedAt,
+		LastModified: e.UpdatedAt,
+	}
+}
+
+// ICalAttendees maps attendees to ATTENDEE properties.
+func ICalAttendees(attendees []Attendee) []ical.Attendee {
+	if len(attendees) == 0 {
+		return nil
+	}
+
+	result := make([]ical.Attendee, len(attendees))
+	for i, a := range attendees {
+		result[i] = ical.Attendee{Email: a.Email, Name: a.Name, Status: strings.ToUpper(a.Status)}
+	}
+	return result
+}
+
+// DayKey buckets a timestamp by its calendar day in its own location, which
+// is what day, week and month views are indexed by.
+func DayKey(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
+
+func (e *Event) HasTag(tag string) bool {
+	return slices.Contains(e.Tags, tag)
+}
+
+// Match reports whether an event with the given tags passes the filter: it
+// must carry at least one included tag (if any are set) and none of the
+// excluded ones.
+func (f TagFilter) Match(tags []string) bool {
+	for _, tag := range f.Exclude {
+		if slices.Contains(tags, tag) {
+			return false
+		}
+	}
+
+	if len(f.Include) == 0 {
+		return true
+	}
+
+	for _, tag := range f.Include {
+		if slices.Contains(tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f TagFilter) IsEmpty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// NormalizeTags lowercases and trims tags, dropping empty values and
+// duplicates while keeping the original order.
+func NormalizeTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || slices.Contains(result, tag) {
+			continue
+		}
+		result = append(result, tag)
+	}
+	return result
 }
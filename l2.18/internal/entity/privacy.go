@@ -0,0 +1,43 @@
+package entity
+
+import "time"
+
+type ExportStatus string
+
+const (
+	ExportPending ExportStatus = "pending"
+	ExportRunning ExportStatus = "running"
+	ExportDone    ExportStatus = "done"
+	ExportFailed  ExportStatus = "failed"
+)
+
+// ExportJob builds the archive of everything stored about a user. The
+// archive can be downloaded until ExpiresAt.
+type ExportJob struct {
+	ID          string       `json:"id"`
+	TenantID    string       `json:"tenant_id,omitempty"`
+	UserID      uint64       `json:"user_id"`
+	Status      ExportStatus `json:"status"`
+	Size        int64        `json:"size,omitempty"`
+	Error       string       `json:"error,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	CompletedAt time.Time    `json:"completed_at,omitzero"`
+	ExpiresAt   time.Time    `json:"expires_at"`
+}
+
+// EraseReport lists what was removed for a user per store and what a
+// second pass over the same stores still found. Verified is set when
+// nothing remained.
+type EraseReport struct {
+	TenantID  string         `json:"tenant_id,omitempty"`
+	UserID    uint64         `json:"user_id"`
+	Removed   map[string]int `json:"removed"`
+	Remaining map[string]int `json:"remaining,omitempty"`
+	Verified  bool           `json:"verified"`
+	ErasedAt  time.Time      `json:"erased_at"`
+}
+
+// BlobKey is where the archive of the job is stored.
+func (j *ExportJob) BlobKey() string {
+	return j.TenantID + "/" + j.ID + ".zip"
+}
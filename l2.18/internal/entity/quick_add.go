@@ -0,0 +1,11 @@
+package entity
+
+type QuickAdd struct {
+	Event   *Event `json:"event"`
+	AllDay  bool   `json:"all_day"`
+	Lang    string `json:"lang"`
+	Created bool   `json:"created"`
+	// Warnings explain why the event falls outside the user's working
+	// calendar, e.g. on a public holiday.
+	Warnings []string `json:"warnings,omitempty"`
+}
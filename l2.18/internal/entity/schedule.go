@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+type EventKind string
+
+// Read-only entries merged into day, week and month views from the working
+// calendar of the user; they have no ID and cannot be edited.
+const (
+	EventKindHoliday EventKind = "holiday"
+	EventKindDayOff  EventKind = "day_off"
+)
+
+// WorkSchedule is the working calendar of a user: the public holidays of
+// Country, the working days and hours in Timezone, and personal days off.
+type WorkSchedule struct {
+	TenantID  string         `json:"tenant_id,omitempty"`
+	UserID    uint64         `json:"user_id"`
+	Country   string         `json:"country,omitempty"`
+	Timezone  string         `json:"timezone"`
+	WorkDays  []time.Weekday `json:"work_days"`
+	WorkStart string         `json:"work_start"`
+	WorkEnd   string         `json:"work_end"`
+	DaysOff   []string       `json:"days_off,omitempty"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+type Tag struct {
+	UserID    uint64    `json:"user_id"         validate:"required,gte=1"`
+	Name      string    `json:"name"            validate:"required,max=30"`
+	Color     string    `json:"color,omitempty" validate:"omitempty,hexcolor"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,71 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Template is a reusable event body. Title and Text may contain
+// {{placeholders}} filled in when an event is created from it. Shared
+// templates are visible to every user of the tenant, but only the author
+// may change them.
+type Template struct {
+	ID        uint64        `json:"id"`
+	TenantID  string        `json:"tenant_id,omitempty"`
+	UserID    uint64        `json:"user_id"`
+	Name      string        `json:"name"`
+	Shared    bool          `json:"shared"`
+	Title     string        `json:"title"`
+	Text      string        `json:"text"`
+	Duration  time.Duration `json:"duration,omitempty"`
+	Tags      []string      `json:"tags,omitempty"`
+	Color     string        `json:"color,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// TemplateOverrides replace template fields for one event; nil fields keep
+// the template value. Vars fill the placeholders of the template.
+type TemplateOverrides struct {
+	Duration *time.Duration
+	Title    *string
+	Text     *string
+	Tags     []string
+	Color    *string
+	Vars     map[string]string
+}
+
+// MarshalJSON writes Duration as a duration string, see Duration.
+func (t Template) MarshalJSON() ([]byte, error) {
+	type template Template
+	var duration *Duration
+	if t.Duration != 0 {
+		duration = (*Duration)(&t.Duration)
+	}
+
+	return json.Marshal(struct {
+		template
+		Duration *Duration `json:"duration,omitempty"`
+	}{template(t), duration})
+}
+
+func (t *Template) UnmarshalJSON(data []byte) error {
+	type template Template
+	aux := struct {
+		*template
+		Duration *Duration `json:"duration"`
+	}{template: (*template)(t)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.Duration != nil {
+		t.Duration = time.Duration(*aux.Duration)
+	}
+	return nil
+}
+
+// VisibleTo reports whether userID may use the template.
+func (t *Template) VisibleTo(userID uint64) bool {
+	return t.Shared || t.UserID == userID
+}
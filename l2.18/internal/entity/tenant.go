@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+)
+
+// DefaultTenant owns requests that do not name a tenant, so a single-tenant
+// deployment keeps working without any configuration.
+const DefaultTenant = "default"
+
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+type tenantKey struct{}
+
+// Owner identifies whose data a record is: user IDs are only unique within
+// a tenant.
+type Owner struct {
+	TenantID string
+	UserID   uint64
+}
+
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant of the request, DefaultTenant when
+// none was resolved.
+func TenantFromContext(ctx context.Context) string {
+	if tenantID, ok := ctx.Value(tenantKey{}).(string); ok && tenantID != "" {
+		return tenantID
+	}
+	return DefaultTenant
+}
+
+func OwnerOf(ctx context.Context, userID uint64) Owner {
+	return Owner{TenantID: TenantFromContext(ctx), UserID: userID}
+}
+
+func ValidTenantID(tenantID string) bool {
+	return tenantIDPattern.MatchString(tenantID)
+}
+
+// EventCacheKey keys cached events by tenant as well as ID, so a shared
+// cache never hands one tenant's event to another.
+func EventCacheKey(tenantID string, id uint64) string {
+	return tenantID + "/" + strconv.FormatUint(id, 10)
+}
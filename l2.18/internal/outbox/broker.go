@@ -0,0 +1,101 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"calendar-wbf/pkg/kafka"
+)
+
+type (
+	// Broker publishes messages to a topic. Publish returns once all of
+	// them are stored; a failed call may have stored some, so the relay
+	// delivers at least once.
+	Broker interface {
+		Publish(ctx context.Context, topic string, msgs []Message) error
+		Close() error
+	}
+
+	// Message is a domain event as published. Messages with the same Key
+	// keep their order.
+	Message struct {
+		Key     string
+		Value   []byte
+		Headers map[string]string
+	}
+
+	// MemoryBroker keeps the last capacity messages of each topic in
+	// process. It serves local runs without a broker and tests.
+	MemoryBroker struct {
+		capacity int
+
+		mu     sync.Mutex
+		topics map[string][]Message
+	}
+
+	// KafkaBroker publishes over the Kafka protocol.
+	KafkaBroker struct {
+		producer *kafka.Producer
+	}
+)
+
+func NewMemoryBroker(capacity int) *MemoryBroker {
+	return &MemoryBroker{
+		capacity: max(capacity, 1),
+		topics:   make(map[string][]Message),
+	}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, topic string, msgs []Message) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("outbox.MemoryBroker.Publish: %w", err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	kept := append(b.topics[topic], msgs...)
+	if over := len(kept) - b.capacity; over > 0 {
+		kept = slices.Delete(kept, 0, over)
+	}
+	b.topics[topic] = kept
+	return nil
+}
+
+// Messages returns the kept messages of the topic, oldest first.
+func (b *MemoryBroker) Messages(topic string) []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return slices.Clone(b.topics[topic])
+}
+
+func (b *MemoryBroker) Close() error {
+	return nil
+}
+
+func NewKafkaBroker(producer *kafka.Producer) *KafkaBroker {
+	return &KafkaBroker{producer: producer}
+}
+
+func (b *KafkaBroker) Publish(ctx context.Context, topic string, msgs []Message) error {
+	records := make([]kafka.Message, len(msgs))
+	for i, msg := range msgs {
+		records[i] = kafka.Message{Key: []byte(msg.Key), Value: msg.Value}
+		for _, key := range slices.Sorted(maps.Keys(msg.Headers)) {
+			records[i].Headers = append(records[i].Headers, kafka.Header{Key: key, Value: []byte(msg.Headers[key])})
+		}
+	}
+
+	if err := b.producer.Produce(ctx, topic, records...); err != nil {
+		return fmt.Errorf("outbox.KafkaBroker.Publish: %w", err)
+	}
+	return nil
+}
+
+func (b *KafkaBroker) Close() error {
+	return b.producer.Close()
+}
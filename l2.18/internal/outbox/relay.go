@@ -0,0 +1,160 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/logger"
+)
+
+const (
+	_maxBackoff   = time.Minute
+	_finalTimeout = 5 * time.Second
+)
+
+type (
+	Store interface {
+		PendingOutbox(ctx context.Context, limit int) ([]*entity.OutboxMessage, error)
+		AckOutbox(ctx context.Context, seq uint64) error
+	}
+
+	// Relay moves domain events from the outbox to the broker in the order
+	// they were recorded. A message is acknowledged only after the broker
+	// has stored it, so a crash or failure in between publishes it again.
+	Relay struct {
+		store     Store
+		broker    Broker
+		topic     string
+		log       logger.Logger
+		interval  time.Duration
+		batchSize int
+	}
+)
+
+func NewRelay(
+	store Store,
+	broker Broker,
+	topic string,
+	log logger.Logger,
+	interval time.Duration,
+	batchSize int,
+) *Relay {
+	return &Relay{
+		store:     store,
+		broker:    broker,
+		topic:     topic,
+		log:       log,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Run flushes the outbox every interval. After a failure it waits twice as
+// long each time, up to a minute, so an unreachable broker is not hammered.
+// On shutdown it flushes once more, since the outbox does not outlive the
+// process.
+func (r *Relay) Run(ctx context.Context) error {
+	r.log.Infow("outbox relay started",
+		"interval", r.interval.String(),
+		"topic", r.topic,
+	)
+
+	wait := r.interval
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.stop(ctx)
+			return nil
+		case <-timer.C:
+		}
+
+		if _, err := r.Flush(ctx); err != nil && ctx.Err() == nil {
+			wait = min(wait*2, max(_maxBackoff, r.interval))
+			r.log.LogAttrs(ctx, logger.WarnLevel, "outbox relay failed",
+				logger.String("op", "outbox.Run"),
+				logger.String("retry_in", wait.String()),
+				logger.Any("error", err),
+			)
+		} else {
+			wait = r.interval
+		}
+		timer.Reset(wait)
+	}
+}
+
+func (r *Relay) stop(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), _finalTimeout)
+	defer cancel()
+
+	published, err := r.Flush(ctx)
+	if err != nil {
+		r.log.LogAttrs(ctx, logger.WarnLevel, "outbox final flush failed",
+			logger.String("op", "outbox.stop"),
+			logger.Any("error", err),
+		)
+	}
+	r.log.Infow("outbox relay stopped", "published", published)
+}
+
+// Flush publishes pending messages batch by batch until the outbox is
+// empty, and returns how many were published.
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	const op = "outbox.Flush"
+
+	var published int
+	for {
+		pending, err := r.store.PendingOutbox(ctx, r.batchSize)
+		if err != nil {
+			return published, fmt.Errorf("%s: list pending: %w", op, err)
+		}
+		if len(pending) == 0 {
+			return published, nil
+		}
+
+		msgs := make([]Message, len(pending))
+		for i, msg := range pending {
+			if msgs[i], err = encode(msg.Event); err != nil {
+				return published, fmt.Errorf("%s: encode %d: %w", op, msg.Seq, err)
+			}
+		}
+
+		if err = r.broker.Publish(ctx, r.topic, msgs); err != nil {
+			return published, fmt.Errorf("%s: publish: %w", op, err)
+		}
+		if err = r.store.AckOutbox(ctx, pending[len(pending)-1].Seq); err != nil {
+			return published, fmt.Errorf("%s: ack: %w", op, err)
+		}
+
+		published += len(pending)
+		r.log.LogAttrs(ctx, logger.DebugLevel, "outbox messages published",
+			logger.String("op", op),
+			logger.Int("count", len(pending)),
+			logger.Uint64("last_seq", pending[len(pending)-1].Seq),
+		)
+	}
+}
+
+// encode renders a domain event as JSON keyed by the changed event. The
+// headers let consumers route without decoding the value.
+func encode(event entity.DomainEvent) (Message, error) {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		Key:   event.Key(),
+		Value: value,
+		Headers: map[string]string{
+			"id":        event.ID,
+			"type":      string(event.Type),
+			"tenant_id": event.TenantID,
+		},
+	}, nil
+}
@@ -0,0 +1,175 @@
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/internal/outbox"
+	"calendar-wbf/internal/repository"
+	"calendar-wbf/pkg/kafka"
+	"calendar-wbf/pkg/logger"
+)
+
+const _topic = "calendar.events"
+
+type failingBroker struct {
+	outbox.Broker
+
+	failures int
+}
+
+func (b *failingBroker) Publish(ctx context.Context, topic string, msgs []outbox.Message) error {
+	if b.failures > 0 {
+		b.failures--
+		return errors.New("broker unavailable")
+	}
+	return b.Broker.Publish(ctx, topic, msgs)
+}
+
+func TestRelay_Flush(t *testing.T) {
+	t.Parallel()
+
+	ctx, repo := seedOutbox(t)
+	broker := outbox.NewMemoryBroker(10)
+	relay := outbox.NewRelay(repo, broker, _topic, logger.NewNop(), time.Second, 2)
+
+	published, err := relay.Flush(ctx)
+	if err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if published != 3 {
+		t.Errorf("Flush() = %d; want 3", published)
+	}
+	if n, _ := repo.OutboxLen(ctx); n != 0 {
+		t.Errorf("OutboxLen() = %d; want 0", n)
+	}
+
+	msgs := broker.Messages(_topic)
+	var types []string
+	for _, msg := range msgs {
+		types = append(types, msg.Headers["type"])
+		if msg.Key != "acme/1" || msg.Headers["tenant_id"] != "acme" {
+			t.Errorf("message key %q, tenant %q; want acme/1, acme", msg.Key, msg.Headers["tenant_id"])
+		}
+
+		var event entity.DomainEvent
+		if err = json.Unmarshal(msg.Value, &event); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		if event.ID != msg.Headers["id"] || string(event.Type) != msg.Headers["type"] {
+			t.Errorf("value %+v does not match headers %v", event, msg.Headers)
+		}
+	}
+	if want := []string{"event.created", "event.updated", "event.deleted"}; !slices.Equal(types, want) {
+		t.Errorf("published types = %v; want %v", types, want)
+	}
+}
+
+func TestRelay_FlushKeepsUnpublished(t *testing.T) {
+	t.Parallel()
+
+	ctx, repo := seedOutbox(t)
+	memory := outbox.NewMemoryBroker(10)
+	broker := &failingBroker{Broker: memory, failures: 1}
+	relay := outbox.NewRelay(repo, broker, _topic, logger.NewNop(), time.Second, 10)
+
+	if _, err := relay.Flush(ctx); err == nil {
+		t.Fatal("Flush() error = nil; want the broker error")
+	}
+	if n, _ := repo.OutboxLen(ctx); n != 3 {
+		t.Errorf("OutboxLen() after failure = %d; want 3", n)
+	}
+
+	if published, err := relay.Flush(ctx); err != nil || published != 3 {
+		t.Fatalf("Flush() = %d, %v; want 3, nil", published, err)
+	}
+	if got := len(memory.Messages(_topic)); got != 3 {
+		t.Errorf("Messages() = %d; want 3", got)
+	}
+}
+
+func TestRelay_RunFlushesOnShutdown(t *testing.T) {
+	t.Parallel()
+
+	ctx, repo := seedOutbox(t)
+	broker := outbox.NewMemoryBroker(10)
+	relay := outbox.NewRelay(repo, broker, _topic, logger.NewNop(), time.Hour, 10)
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := relay.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got := len(broker.Messages(_topic)); got != 3 {
+		t.Errorf("Messages() = %d; want 3", got)
+	}
+}
+
+func TestRelay_FlushToKafka(t *testing.T) {
+	t.Parallel()
+
+	srv, err := kafka.NewServer("127.0.0.1:0", 3)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+
+	producer, err := kafka.NewProducer([]string{srv.Addr()})
+	if err != nil {
+		t.Fatalf("NewProducer() error = %v", err)
+	}
+	broker := outbox.NewKafkaBroker(producer)
+	t.Cleanup(func() { broker.Close() })
+
+	ctx, repo := seedOutbox(t)
+	relay := outbox.NewRelay(repo, broker, _topic, logger.NewNop(), time.Second, 10)
+	if _, err = relay.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	records := srv.Records(_topic)
+	if len(records) != 3 {
+		t.Fatalf("Records() = %d records; want 3", len(records))
+	}
+	for i, want := range []string{"event.created", "event.updated", "event.deleted"} {
+		record := records[i]
+		if string(record.Key) != "acme/1" {
+			t.Errorf("record %d key = %q; want acme/1", i, record.Key)
+		}
+		// Headers are sent sorted by key: id, tenant_id, type.
+		if headers := record.Headers; len(headers) != 3 || headers[2].Key != "type" ||
+			string(headers[2].Value) != want {
+			t.Errorf("record %d headers = %+v; want type %s", i, headers, want)
+		}
+	}
+}
+
+func seedOutbox(t *testing.T) (context.Context, *repository.EventRepository) {
+	t.Helper()
+
+	ctx := entity.WithTenant(context.Background(), "acme")
+	repo := repository.NewEventRepository(repository.WithOutbox())
+
+	event, err := repo.Create(ctx, &entity.Event{
+		UserID: 1,
+		Date:   time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+		Title:  "standup",
+	}, entity.EventCreated)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	updated := *event
+	updated.Title = "retro"
+	if _, err = repo.Update(ctx, &updated, entity.EventUpdated); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err = repo.Delete(ctx, event.ID, entity.EventDeleted); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	return ctx, repo
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"calendar-wbf/internal/entity"
+)
+
+type (
+	eventKey struct {
+		TenantID string
+		EventID  uint64
+	}
+
+	AttachmentRepository struct {
+		mu      sync.RWMutex
+		byEvent map[eventKey][]*entity.Attachment
+	}
+)
+
+func NewAttachmentRepository() *AttachmentRepository {
+	return &AttachmentRepository{
+		byEvent: make(map[eventKey][]*entity.Attachment),
+	}
+}
+
+func eventKeyOf(ctx context.Context, eventID uint64) eventKey {
+	return eventKey{TenantID: entity.TenantFromContext(ctx), EventID: eventID}
+}
+
+// Add stores the attachment in the tenant of the context unless the event
+// already has limit attachments; zero means no limit.
+func (r *AttachmentRepository) Add(
+	ctx context.Context,
+	attachment *entity.Attachment,
+	limit int,
+) (*entity.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := eventKeyOf(ctx, attachment.EventID)
+	if limit > 0 && len(r.byEvent[key]) >= limit {
+		return nil, fmt.Errorf("%d of %d: %w", len(r.byEvent[key]), limit, entity.ErrTooManyAttachments)
+	}
+
+	saved := *attachment
+	saved.TenantID = key.TenantID
+	r.byEvent[key] = append(r.byEvent[key], &saved)
+
+	copied := saved
+	return &copied, nil
+}
+
+func (r *AttachmentRepository) Get(ctx context.Context, eventID uint64, id string) (*entity.Attachment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, attachment := range r.byEvent[eventKeyOf(ctx, eventID)] {
+		if attachment.ID == id {
+			copied := *attachment
+			return &copied, nil
+		}
+	}
+	return nil, entity.ErrAttachmentNotFound
+}
+
+func (r *AttachmentRepository) ListByEvent(ctx context.Context, eventID uint64) ([]*entity.Attachment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	attachments := r.byEvent[eventKeyOf(ctx, eventID)]
+	result := make([]*entity.Attachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		copied := *attachment
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+
+func (r *AttachmentRepository) Delete(ctx context.Context, eventID uint64, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := eventKeyOf(ctx, eventID)
+	attachments := r.byEvent[key]
+	for i, attachment := range attachments {
+		if attachment.ID != id {
+			continue
+		}
+
+		if len(attachments) == 1 {
+			delete(r.byEvent, key)
+		} else {
+			r.byEvent[key] = append(attachments[:i:i], attachments[i+1:]...)
+		}
+		return nil
+	}
+	return entity.ErrAttachmentNotFound
+}
+
+// DeleteByEvent drops all attachments of an event and returns them, so the
+// caller can remove their content.
+func (r *AttachmentRepository) DeleteByEvent(ctx context.Context, eventID uint64) ([]*entity.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := eventKeyOf(ctx, eventID)
+	attachments := r.byEvent[key]
+	delete(r.byEvent, key)
+	return attachments, nil
+}
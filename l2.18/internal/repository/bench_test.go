@@ -0,0 +1,127 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/internal/repository"
+)
+
+const (
+	_benchTenant     = "acme"
+	_benchUsers      = 100
+	_benchWindowDays = 365
+)
+
+var _benchStart = time.Date(2026, time.January, 5, 0, 0, 0, 0, time.UTC)
+
+// newBenchRepository fills a repository with perUser events for each of
+// _benchUsers users, spread over a year in working hours, a third of them
+// tagged "work".
+func newBenchRepository(b *testing.B, perUser int) *repository.EventRepository {
+	b.Helper()
+
+	ctx := entity.WithTenant(context.Background(), _benchTenant)
+	repo := repository.NewEventRepository()
+	rng := rand.New(rand.NewPCG(1, 1))
+	for userID := uint64(1); userID <= _benchUsers; userID++ {
+		for i := range perUser {
+			day := _benchStart.AddDate(0, 0, rng.IntN(_benchWindowDays))
+			event := &entity.Event{
+				UserID:   userID,
+				Date:     day.Add(time.Duration(9+rng.IntN(9)) * time.Hour),
+				Duration: time.Hour,
+				Title:    "bench",
+				Text:     "bench",
+			}
+			if i%3 == 0 {
+				event.Tags = []string{"work"}
+			}
+			if _, err := repo.Create(ctx, event); err != nil {
+				b.Fatalf("Create() error = %v", err)
+			}
+		}
+	}
+	return repo
+}
+
+// BenchmarkEventRepository_GetByUserAndDateRange measures the range queries
+// behind the day, week and month views by calendar size:
+// go test -bench=EventRepository -benchmem ./internal/repository.
+func BenchmarkEventRepository_GetByUserAndDateRange(b *testing.B) {
+	ranges := []struct {
+		name string
+		days int
+	}{
+		{"Day", 1},
+		{"Week", 7},
+		{"Month", 31},
+		{"Year", _benchWindowDays},
+	}
+	filters := []struct {
+		name   string
+		filter entity.TagFilter
+	}{
+		{"All", entity.TagFilter{}},
+		{"IncludeTag", entity.TagFilter{Include: []string{"work"}}},
+		{"ExcludeTag", entity.TagFilter{Exclude: []string{"work"}}},
+	}
+
+	ctx := entity.WithTenant(context.Background(), _benchTenant)
+	for _, perUser := range []int{100, 1_000, 10_000} {
+		repo := newBenchRepository(b, perUser)
+
+		for _, r := range ranges {
+			for _, f := range filters {
+				b.Run(fmt.Sprintf("Events%d/%s/%s", perUser, r.name, f.name), func(b *testing.B) {
+					rng := rand.New(rand.NewPCG(2, 2))
+					var found int
+					for b.Loop() {
+						start := _benchStart.AddDate(0, 0, rng.IntN(_benchWindowDays-r.days+1))
+						end := start.AddDate(0, 0, r.days-1)
+						events, _ := repo.GetByUserAndDateRange(ctx, rng.Uint64N(_benchUsers)+1, start, end, f.filter)
+						found += len(events)
+					}
+					b.ReportMetric(float64(found)/float64(b.N), "events/op")
+				})
+			}
+		}
+	}
+}
+
+// BenchmarkEventRepository_ParallelReadWrite runs month queries alongside
+// creates and deletes, the mix the read lock is contended by:
+// go test -bench=ParallelReadWrite -cpu=1,4,16 ./internal/repository.
+func BenchmarkEventRepository_ParallelReadWrite(b *testing.B) {
+	ctx := entity.WithTenant(context.Background(), _benchTenant)
+	var all entity.TagFilter
+
+	for _, readPercent := range []int{99, 90, 50} {
+		b.Run(fmt.Sprintf("Read%d", readPercent), func(b *testing.B) {
+			repo := newBenchRepository(b, 1_000)
+			b.ResetTimer()
+
+			b.RunParallel(func(pb *testing.PB) {
+				rng := rand.New(rand.NewPCG(rand.Uint64(), 3))
+				for pb.Next() {
+					userID := rng.Uint64N(_benchUsers) + 1
+					start := _benchStart.AddDate(0, 0, rng.IntN(_benchWindowDays-31))
+
+					if rng.IntN(100) < readPercent {
+						_, _ = repo.GetByUserAndDateRange(ctx, userID, start, start.AddDate(0, 0, 30), all)
+						continue
+					}
+
+					event := &entity.Event{UserID: userID, Date: start, Title: "bench", Text: "bench"}
+					if _, err := repo.Create(ctx, event); err == nil {
+						_ = repo.Delete(ctx, event.ID)
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"calendar-wbf/internal/entity"
+)
+
+type DigestSubscriptionRepository struct {
+	mu   sync.RWMutex
+	subs map[entity.Owner]*entity.DigestSubscription
+}
+
+func NewDigestSubscriptionRepository() *DigestSubscriptionRepository {
+	return &DigestSubscriptionRepository{
+		subs: make(map[entity.Owner]*entity.DigestSubscription),
+	}
+}
+
+func (r *DigestSubscriptionRepository) Upsert(
+	ctx context.Context,
+	sub *entity.DigestSubscription,
+) (*entity.DigestSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	owner := entity.OwnerOf(ctx, sub.UserID)
+	saved := *sub
+	saved.TenantID = owner.TenantID
+	if existing, exists := r.subs[owner]; exists {
+		saved.LastSent = existing.LastSent
+	}
+	r.subs[owner] = &saved
+
+	result := saved
+	return &result, nil
+}
+
+func (r *DigestSubscriptionRepository) Get(ctx context.Context, userID uint64) (*entity.DigestSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sub, exists := r.subs[entity.OwnerOf(ctx, userID)]
+	if !exists {
+		return nil, entity.ErrDigestNotFound
+	}
+
+	result := *sub
+	return &result, nil
+}
+
+// List returns the subscriptions of every tenant for the scheduler, ordered
+// by tenant and user.
+func (r *DigestSubscriptionRepository) List(_ context.Context) ([]*entity.DigestSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*entity.DigestSubscription, 0, len(r.subs))
+	for _, sub := range r.subs {
+		copied := *sub
+		result = append(result, &copied)
+	}
+
+	slices.SortFunc(result, func(a, b *entity.DigestSubscription) int {
+		if c := strings.Compare(a.TenantID, b.TenantID); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.UserID, b.UserID)
+	})
+
+	return result, nil
+}
+
+func (r *DigestSubscriptionRepository) MarkSent(ctx context.Context, userID uint64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sub, exists := r.subs[entity.OwnerOf(ctx, userID)]
+	if !exists {
+		return entity.ErrDigestNotFound
+	}
+	sub.LastSent = at
+
+	return nil
+}
+
+func (r *DigestSubscriptionRepository) Delete(ctx context.Context, userID uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	owner := entity.OwnerOf(ctx, userID)
+	if _, exists := r.subs[owner]; !exists {
+		return entity.ErrDigestNotFound
+	}
+	delete(r.subs, owner)
+
+	return nil
+}
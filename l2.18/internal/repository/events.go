@@ -1,148 +1,428 @@
 package repository
 
 import (
+	"cmp"
 	"context"
+	"slices"
 	"sync"
 	"time"
 
 	"calendar-wbf/internal/entity"
 )
 
-type EventRepository struct {
-	mu        sync.RWMutex
-	events    map[uint64]*entity.Event
-	nextID    uint64
-	userIndex map[uint64]map[string][]uint64
+type (
+	// EventRepository keeps events of all tenants. IDs are global, but every
+	// lookup is scoped to the tenant of the context and the indexes are keyed
+	// by owner, so one tenant never sees another's events.
+	//
+	// Changes take the domain events they raise. With an outbox those are
+	// stored under the same lock as the change, so a change is never kept
+	// without its events or the other way round; without one they are
+	// dropped.
+	EventRepository struct {
+		mu           sync.RWMutex
+		events       map[uint64]*entity.Event
+		nextID       uint64
+		userIndex    map[entity.Owner]map[string][]uint64
+		tagIndex     map[entity.Owner]map[string]map[uint64]struct{}
+		tenantCounts map[string]int
+
+		outboxEnabled bool
+		outbox        []*entity.OutboxMessage
+		nextSeq       uint64
+	}
+
+	EventRepositoryOption func(*EventRepository)
+)
+
+// WithOutbox keeps the raised domain events until they are acknowledged.
+func WithOutbox() EventRepositoryOption {
+	return func(r *EventRepository) {
+		r.outboxEnabled = true
+	}
 }
 
-func NewEventRepository() *EventRepository {
-	return &EventRepository{
-		events:    make(map[uint64]*entity.Event),
-		nextID:    1,
-		userIndex: make(map[uint64]map[string][]uint64),
+func NewEventRepository(opts ...EventRepositoryOption) *EventRepository {
+	r := &EventRepository{
+		events:       make(map[uint64]*entity.Event),
+		nextID:       1,
+		userIndex:    make(map[entity.Owner]map[string][]uint64),
+		tagIndex:     make(map[entity.Owner]map[string]map[uint64]struct{}),
+		tenantCounts: make(map[string]int),
+		nextSeq:      1,
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
 }
 
-func (r *EventRepository) Create(_ context.Context, event *entity.Event) (*entity.Event, error) {
+func (r *EventRepository) Create(
+	ctx context.Context,
+	event *entity.Event,
+	raise ...entity.DomainEventType,
+) (*entity.Event, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	now := time.Now()
 	event.ID = r.nextID
+	event.TenantID = entity.TenantFromContext(ctx)
 	event.CreatedAt = now
 	event.UpdatedAt = now
 	r.nextID++
 
 	r.events[event.ID] = event
 	r.addToIndex(event)
+	r.tenantCounts[event.TenantID]++
+	r.record(event, now, raise)
 
 	return event, nil
 }
 
-func (r *EventRepository) GetByID(_ context.Context, id uint64) (*entity.Event, error) {
+func (r *EventRepository) GetByID(ctx context.Context, id uint64) (*entity.Event, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	event, exists := r.events[id]
-	if !exists {
-		return nil, entity.ErrEventNotFound
-	}
+	return r.get(ctx, id)
+}
 
-	return event, nil
+func (r *EventRepository) Update(
+	ctx context.Context,
+	event *entity.Event,
+	raise ...entity.DomainEventType,
+) (*entity.Event, error) {
+	return r.UpdateIf(ctx, event, nil, raise...)
 }
 
-func (r *EventRepository) Update(_ context.Context, event *entity.Event) (*entity.Event, error) {
+// UpdateIf replaces the event if match accepts the stored version, checked
+// under the same lock as the write, and fails with ErrPreconditionFailed
+// otherwise. A nil match accepts any version.
+func (r *EventRepository) UpdateIf(
+	ctx context.Context,
+	event *entity.Event,
+	match entity.EventMatch,
+	raise ...entity.DomainEventType,
+) (*entity.Event, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	existing, exists := r.events[event.ID]
-	if !exists {
-		return nil, entity.ErrEventNotFound
+	existing, err := r.get(ctx, event.ID)
+	if err != nil {
+		return nil, err
+	}
+	if match != nil && !match(existing) {
+		return nil, entity.ErrPreconditionFailed
 	}
 
 	r.removeFromIndex(existing)
 
+	event.TenantID = existing.TenantID
 	event.CreatedAt = existing.CreatedAt
 	event.UpdatedAt = time.Now()
 	r.events[event.ID] = event
 
 	r.addToIndex(event)
+	r.record(event, event.UpdatedAt, raise)
 
 	return event, nil
 }
 
-func (r *EventRepository) Delete(_ context.Context, id uint64) error {
+func (r *EventRepository) Delete(ctx context.Context, id uint64, raise ...entity.DomainEventType) error {
+	return r.DeleteIf(ctx, id, nil, raise...)
+}
+
+// DeleteIf removes the event if match accepts the stored version, like
+// UpdateIf.
+func (r *EventRepository) DeleteIf(
+	ctx context.Context,
+	id uint64,
+	match entity.EventMatch,
+	raise ...entity.DomainEventType,
+) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	event, exists := r.events[id]
-	if !exists {
-		return entity.ErrEventNotFound
+	event, err := r.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if match != nil && !match(event) {
+		return entity.ErrPreconditionFailed
 	}
 
 	r.removeFromIndex(event)
 	delete(r.events, id)
+	if r.tenantCounts[event.TenantID]--; r.tenantCounts[event.TenantID] == 0 {
+		delete(r.tenantCounts, event.TenantID)
+	}
+	r.record(event, time.Now(), raise)
 
 	return nil
 }
 
+// CountByTenant returns how many events the tenant of the context has,
+// which per-tenant quotas are checked against.
+func (r *EventRepository) CountByTenant(ctx context.Context) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.tenantCounts[entity.TenantFromContext(ctx)], nil
+}
+
 func (r *EventRepository) GetByUserAndDate(
-	_ context.Context,
+	ctx context.Context,
 	userID uint64,
 	date time.Time,
+	filter entity.TagFilter,
 ) ([]*entity.Event, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	dateKey := date.String()
-	return r.getEventsByDateKey(userID, dateKey), nil
+	owner := entity.OwnerOf(ctx, userID)
+	dateKey := entity.DayKey(date)
+	if len(filter.Include) > 0 {
+		return r.getEventsByTags(owner, map[string]struct{}{dateKey: {}}, filter), nil
+	}
+	return filterEvents(r.getEventsByDateKey(owner, dateKey), filter), nil
 }
 
 func (r *EventRepository) GetByUserAndDateRange(
-	_ context.Context,
+	ctx context.Context,
 	userID uint64,
 	startDate, endDate time.Time,
+	filter entity.TagFilter,
 ) ([]*entity.Event, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	owner := entity.OwnerOf(ctx, userID)
+	if len(filter.Include) > 0 {
+		dateKeys := make(map[string]struct{})
+		for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
+			dateKeys[entity.DayKey(d)] = struct{}{}
+		}
+		return r.getEventsByTags(owner, dateKeys, filter), nil
+	}
+
 	var result []*entity.Event
 	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
-		dateKey := d.String()
-		events := r.getEventsByDateKey(userID, dateKey)
-		result = append(result, events...)
+		dateKey := entity.DayKey(d)
+		events := r.getEventsByDateKey(owner, dateKey)
+		result = append(result, filterEvents(events, filter)...)
+	}
+
+	return result, nil
+}
+
+// GetByUser returns every event of the user ordered by date.
+func (r *EventRepository) GetByUser(ctx context.Context, userID uint64) ([]*entity.Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*entity.Event
+	for _, ids := range r.userIndex[entity.OwnerOf(ctx, userID)] {
+		for _, id := range ids {
+			if event, exists := r.events[id]; exists {
+				result = append(result, event)
+			}
+		}
 	}
 
+	slices.SortFunc(result, func(a, b *entity.Event) int {
+		if c := a.Date.Compare(b.Date); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
 	return result, nil
 }
 
+// DeleteByUser removes every event of the user along with the index entries
+// of the owner, and returns the removed events. Outbox messages still
+// holding copies of them are dropped before the deletions are recorded.
+func (r *EventRepository) DeleteByUser(
+	ctx context.Context,
+	userID uint64,
+	raise ...entity.DomainEventType,
+) ([]*entity.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	owner := entity.OwnerOf(ctx, userID)
+	var removed []*entity.Event
+	for _, ids := range r.userIndex[owner] {
+		for _, id := range ids {
+			if event, exists := r.events[id]; exists {
+				removed = append(removed, event)
+				delete(r.events, id)
+			}
+		}
+	}
+
+	delete(r.userIndex, owner)
+	delete(r.tagIndex, owner)
+	if r.tenantCounts[owner.TenantID] -= len(removed); r.tenantCounts[owner.TenantID] <= 0 {
+		delete(r.tenantCounts, owner.TenantID)
+	}
+
+	slices.SortFunc(removed, func(a, b *entity.Event) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	r.dropOutboxCopies(owner)
+	now := time.Now()
+	for _, event := range removed {
+		r.record(event, now, raise)
+	}
+	return removed, nil
+}
+
+// IndexedByUser counts the index entries kept for the user, including
+// empty date and tag buckets, and the outbox messages holding copies of
+// the events of the user, so an erase can be verified.
+func (r *EventRepository) IndexedByUser(ctx context.Context, userID uint64) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	owner := entity.OwnerOf(ctx, userID)
+	var n int
+	for _, ids := range r.userIndex[owner] {
+		n += max(len(ids), 1)
+	}
+	for _, ids := range r.tagIndex[owner] {
+		n += max(len(ids), 1)
+	}
+	for _, msg := range r.outbox {
+		if msg.Event.Event != nil && ownerOfEvent(msg.Event.Event) == owner {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// RemoveTag strips the tag from every event of the user and returns the
+// updated copies so callers can refresh anything holding the old ones.
+func (r *EventRepository) RemoveTag(
+	ctx context.Context,
+	userID uint64,
+	tag string,
+	raise ...entity.DomainEventType,
+) ([]*entity.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := r.tagIndex[entity.OwnerOf(ctx, userID)][tag]
+	updated := make([]*entity.Event, 0, len(ids))
+
+	for id := range ids {
+		existing, exists := r.events[id]
+		if !exists {
+			continue
+		}
+
+		r.removeFromIndex(existing)
+
+		event := *existing
+		event.Tags = slices.DeleteFunc(slices.Clone(existing.Tags), func(t string) bool {
+			return t == tag
+		})
+		event.UpdatedAt = time.Now()
+		r.events[id] = &event
+
+		r.addToIndex(&event)
+		r.record(&event, event.UpdatedAt, raise)
+		updated = append(updated, &event)
+	}
+
+	return updated, nil
+}
+
+func (r *EventRepository) Ping(ctx context.Context) error {
+	locked := make(chan struct{})
+	go func() {
+		r.mu.RLock()
+		r.mu.RUnlock()
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// get returns the event if it belongs to the tenant of the context. Events
+// of other tenants are reported as missing rather than forbidden, so IDs do
+// not leak across tenants.
+func (r *EventRepository) get(ctx context.Context, id uint64) (*entity.Event, error) {
+	event, exists := r.events[id]
+	if !exists || event.TenantID != entity.TenantFromContext(ctx) {
+		return nil, entity.ErrEventNotFound
+	}
+
+	return event, nil
+}
+
+func ownerOfEvent(event *entity.Event) entity.Owner {
+	return entity.Owner{TenantID: event.TenantID, UserID: event.UserID}
+}
+
 func (r *EventRepository) addToIndex(event *entity.Event) {
-	if r.userIndex[event.UserID] == nil {
-		r.userIndex[event.UserID] = make(map[string][]uint64)
+	owner := ownerOfEvent(event)
+	if r.userIndex[owner] == nil {
+		r.userIndex[owner] = make(map[string][]uint64)
 	}
 
-	dateKey := event.Date.String()
-	r.userIndex[event.UserID][dateKey] = append(r.userIndex[event.UserID][dateKey], event.ID)
+	dateKey := entity.DayKey(event.Date)
+	r.userIndex[owner][dateKey] = append(r.userIndex[owner][dateKey], event.ID)
+
+	if len(event.Tags) == 0 {
+		return
+	}
+
+	if r.tagIndex[owner] == nil {
+		r.tagIndex[owner] = make(map[string]map[uint64]struct{})
+	}
+	for _, tag := range event.Tags {
+		if r.tagIndex[owner][tag] == nil {
+			r.tagIndex[owner][tag] = make(map[uint64]struct{})
+		}
+		r.tagIndex[owner][tag][event.ID] = struct{}{}
+	}
 }
 
 func (r *EventRepository) removeFromIndex(event *entity.Event) {
-	dateKey := event.Date.String()
-	if userDates, exists := r.userIndex[event.UserID]; exists {
+	owner := ownerOfEvent(event)
+	dateKey := entity.DayKey(event.Date)
+	if userDates, exists := r.userIndex[owner]; exists {
 		if ids, idsExists := userDates[dateKey]; idsExists {
 			for i, id := range ids {
 				if id == event.ID {
-					r.userIndex[event.UserID][dateKey] = append(ids[:i], ids[i+1:]...)
+					r.userIndex[owner][dateKey] = append(ids[:i], ids[i+1:]...)
 					break
 				}
 			}
 		}
 	}
+
+	if userTags, exists := r.tagIndex[owner]; exists {
+		for _, tag := range event.Tags {
+			delete(userTags[tag], event.ID)
+			if len(userTags[tag]) == 0 {
+				delete(userTags, tag)
+			}
+		}
+	}
 }
 
-func (r *EventRepository) getEventsByDateKey(userID uint64, dateKey string) []*entity.Event {
+func (r *EventRepository) getEventsByDateKey(owner entity.Owner, dateKey string) []*entity.Event {
 	var result []*entity.Event
 
-	if userDates, exists := r.userIndex[userID]; exists {
+	if userDates, exists := r.userIndex[owner]; exists {
 		if ids, idsExists := userDates[dateKey]; idsExists {
 			for _, id := range ids {
 				if event, eventExists := r.events[id]; eventExists {
@@ -154,3 +434,58 @@ func (r *EventRepository) getEventsByDateKey(userID uint64, dateKey string) []*e
 
 	return result
 }
+
+// getEventsByTags walks only the events carrying one of the included tags,
+// which is cheaper than a date scan when a user has many untagged events.
+func (r *EventRepository) getEventsByTags(
+	owner entity.Owner,
+	dateKeys map[string]struct{},
+	filter entity.TagFilter,
+) []*entity.Event {
+	var result []*entity.Event
+	seen := make(map[uint64]struct{})
+
+	userTags := r.tagIndex[owner]
+	for _, tag := range filter.Include {
+		for id := range userTags[tag] {
+			if _, dup := seen[id]; dup {
+				continue
+			}
+			seen[id] = struct{}{}
+
+			event, exists := r.events[id]
+			if !exists {
+				continue
+			}
+			if _, inRange := dateKeys[entity.DayKey(event.Date)]; !inRange {
+				continue
+			}
+			if filter.Match(event.Tags) {
+				result = append(result, event)
+			}
+		}
+	}
+
+	slices.SortFunc(result, func(a, b *entity.Event) int {
+		if c := a.Date.Compare(b.Date); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return result
+}
+
+func filterEvents(events []*entity.Event, filter entity.TagFilter) []*entity.Event {
+	if filter.IsEmpty() {
+		return events
+	}
+
+	result := make([]*entity.Event, 0, len(events))
+	for _, event := range events {
+		if filter.Match(event.Tags) {
+			result = append(result, event)
+		}
+	}
+	return result
+}
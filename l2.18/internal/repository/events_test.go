@@ -0,0 +1,363 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/internal/repository"
+)
+
+func seedTaggedEvents(t *testing.T, repo *repository.EventRepository, day time.Time) {
+	t.Helper()
+
+	events := []*entity.Event{
+		{UserID: 1, Date: day, Title: "standup", Tags: []string{"meetings", "work"}},
+		{UserID: 1, Date: day, Title: "pager", Tags: []string{"on-call"}},
+		{UserID: 1, Date: day.AddDate(0, 0, 1), Title: "gym", Tags: []string{"personal"}},
+		{UserID: 1, Date: day.AddDate(0, 0, 2), Title: "review", Tags: []string{"work"}},
+		{UserID: 2, Date: day, Title: "other user", Tags: []string{"work"}},
+	}
+
+	for _, event := range events {
+		if _, err := repo.Create(context.Background(), event); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+}
+
+func titles(events []*entity.Event) []string {
+	result := make([]string, 0, len(events))
+	for _, event := range events {
+		result = append(result, event.Title)
+	}
+	return result
+}
+
+func TestEventRepository_TagFilter(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc   string
+		filter entity.TagFilter
+		want   []string
+	}{
+		{"NoFilter", entity.TagFilter{}, []string{"standup", "pager", "gym", "review"}},
+		{"IncludeOne", entity.TagFilter{Include: []string{"work"}}, []string{"standup", "review"}},
+		{"IncludeMany", entity.TagFilter{Include: []string{"on-call", "personal"}}, []string{"pager", "gym"}},
+		{"Exclude", entity.TagFilter{Exclude: []string{"work"}}, []string{"pager", "gym"}},
+		{
+			"IncludeAndExclude",
+			entity.TagFilter{Include: []string{"work"}, Exclude: []string{"meetings"}},
+			[]string{"review"},
+		},
+		{"UnknownTag", entity.TagFilter{Include: []string{"missing"}}, []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			repo := repository.NewEventRepository()
+			seedTaggedEvents(t, repo, day)
+
+			got, err := repo.GetByUserAndDateRange(context.Background(), 1, day, day.AddDate(0, 0, 6), tc.filter)
+			if err != nil {
+				t.Fatalf("GetByUserAndDateRange() error = %v", err)
+			}
+
+			if !slices.Equal(titles(got), tc.want) {
+				t.Errorf("GetByUserAndDateRange() = %v; want %v", titles(got), tc.want)
+			}
+		})
+	}
+}
+
+func TestEventRepository_RemoveTag(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+	repo := repository.NewEventRepository()
+	seedTaggedEvents(t, repo, day)
+
+	updated, err := repo.RemoveTag(context.Background(), 1, "work")
+	if err != nil {
+		t.Fatalf("RemoveTag() error = %v", err)
+	}
+	if len(updated) != 2 {
+		t.Fatalf("RemoveTag() updated %d events; want 2", len(updated))
+	}
+
+	got, _ := repo.GetByUserAndDateRange(context.Background(), 1, day, day.AddDate(0, 0, 6),
+		entity.TagFilter{Include: []string{"work"}})
+	if len(got) != 0 {
+		t.Errorf("events still tagged after RemoveTag: %v", titles(got))
+	}
+
+	got, _ = repo.GetByUserAndDate(context.Background(), 2, day, entity.TagFilter{Include: []string{"work"}})
+	if len(got) != 1 {
+		t.Errorf("RemoveTag() touched another user's events: got %d; want 1", len(got))
+	}
+
+	standup, _ := repo.GetByID(context.Background(), 1)
+	if !slices.Equal(standup.Tags, []string{"meetings"}) {
+		t.Errorf("Tags = %v; want [meetings]", standup.Tags)
+	}
+}
+
+func TestEventRepository_IndexesByCalendarDay(t *testing.T) {
+	t.Parallel()
+
+	moscow := time.FixedZone("MSK", 3*60*60)
+	repo := repository.NewEventRepository()
+	events := []*entity.Event{
+		{UserID: 1, Date: time.Date(2026, time.May, 8, 10, 30, 0, 0, moscow), Title: "dentist"},
+		{UserID: 1, Date: time.Date(2026, time.May, 8, 1, 0, 0, 0, moscow), Title: "night train"},
+		{UserID: 1, Date: time.Date(2026, time.May, 9, 0, 0, 0, 0, time.UTC), Title: "next day"},
+	}
+	for _, event := range events {
+		if _, err := repo.Create(context.Background(), event); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	testCases := []struct {
+		desc string
+		date time.Time
+		want []string
+	}{
+		{"Midnight", time.Date(2026, time.May, 8, 0, 0, 0, 0, time.UTC), []string{"dentist", "night train"}},
+		{"TimeOfDayIgnored", time.Date(2026, time.May, 8, 18, 0, 0, 0, moscow), []string{"dentist", "night train"}},
+		{"OtherDay", time.Date(2026, time.May, 9, 0, 0, 0, 0, moscow), []string{"next day"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := repo.GetByUserAndDate(context.Background(), 1, tc.date, entity.TagFilter{})
+			if err != nil {
+				t.Fatalf("GetByUserAndDate() error = %v", err)
+			}
+			if !slices.Equal(titles(got), tc.want) {
+				t.Errorf("GetByUserAndDate() = %v; want %v", titles(got), tc.want)
+			}
+		})
+	}
+
+	week, _ := repo.GetByUserAndDateRange(context.Background(), 1,
+		time.Date(2026, time.May, 4, 0, 0, 0, 0, time.UTC), time.Date(2026, time.May, 10, 0, 0, 0, 0, time.UTC),
+		entity.TagFilter{})
+	if len(week) != len(events) {
+		t.Errorf("GetByUserAndDateRange() = %v; want all %d events", titles(week), len(events))
+	}
+}
+
+func TestEventRepository_TenantIsolation(t *testing.T) {
+	t.Parallel()
+
+	repo := repository.NewEventRepository()
+	day := time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	hr := entity.WithTenant(context.Background(), "hr")
+	eng := entity.WithTenant(context.Background(), "eng")
+
+	hrEvent, err := repo.Create(hr, &entity.Event{UserID: 1, Date: day, Title: "interview", Tags: []string{"work"}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	_, err = repo.Create(eng, &entity.Event{UserID: 1, Date: day, Title: "deploy", Tags: []string{"work"}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if hrEvent.TenantID != "hr" {
+		t.Errorf("TenantID = %q, want hr", hrEvent.TenantID)
+	}
+
+	got, _ := repo.GetByUserAndDate(eng, 1, day, entity.TagFilter{Include: []string{"work"}})
+	if !slices.Equal(titles(got), []string{"deploy"}) {
+		t.Errorf("eng events = %v, want only its own", titles(got))
+	}
+	got, _ = repo.GetByUser(context.Background(), 1)
+	if len(got) != 0 {
+		t.Errorf("default tenant events = %v, want none", titles(got))
+	}
+
+	if _, err = repo.GetByID(eng, hrEvent.ID); !errors.Is(err, entity.ErrEventNotFound) {
+		t.Errorf("GetByID() from another tenant error = %v, want ErrEventNotFound", err)
+	}
+	if err = repo.Delete(eng, hrEvent.ID); !errors.Is(err, entity.ErrEventNotFound) {
+		t.Errorf("Delete() from another tenant error = %v, want ErrEventNotFound", err)
+	}
+	if _, err = repo.Update(eng, &entity.Event{ID: hrEvent.ID, UserID: 1, Date: day}); !errors.Is(
+		err, entity.ErrEventNotFound,
+	) {
+		t.Errorf("Update() from another tenant error = %v, want ErrEventNotFound", err)
+	}
+
+	if n, _ := repo.CountByTenant(hr); n != 1 {
+		t.Errorf("CountByTenant(hr) = %d, want 1", n)
+	}
+	if err = repo.Delete(hr, hrEvent.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if n, _ := repo.CountByTenant(hr); n != 0 {
+		t.Errorf("CountByTenant(hr) after delete = %d, want 0", n)
+	}
+}
+
+func TestEventRepository_ConditionalChanges(t *testing.T) {
+	t.Parallel()
+
+	repo := repository.NewEventRepository()
+	day := time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	event, err := repo.Create(context.Background(), &entity.Event{UserID: 1, Date: day, Title: "standup"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	version := event.UpdatedAt
+	readVersion := func(stored *entity.Event) bool { return stored.UpdatedAt.Equal(version) }
+
+	updated, err := repo.UpdateIf(context.Background(),
+		&entity.Event{ID: event.ID, UserID: 1, Date: day, Title: "retro"}, readVersion)
+	if err != nil {
+		t.Fatalf("UpdateIf() with the current version error = %v", err)
+	}
+
+	_, err = repo.UpdateIf(context.Background(),
+		&entity.Event{ID: event.ID, UserID: 1, Date: day, Title: "lost update"}, readVersion)
+	if !errors.Is(err, entity.ErrPreconditionFailed) {
+		t.Errorf("UpdateIf() with a stale version error = %v; want ErrPreconditionFailed", err)
+	}
+	if err = repo.DeleteIf(context.Background(), event.ID, readVersion); !errors.Is(err, entity.ErrPreconditionFailed) {
+		t.Errorf("DeleteIf() with a stale version error = %v; want ErrPreconditionFailed", err)
+	}
+
+	got, _ := repo.GetByID(context.Background(), event.ID)
+	if got == nil || got.Title != "retro" {
+		t.Fatalf("GetByID() = %+v; want the first update kept", got)
+	}
+
+	version = updated.UpdatedAt
+	if err = repo.DeleteIf(context.Background(), event.ID, readVersion); err != nil {
+		t.Errorf("DeleteIf() with the current version error = %v", err)
+	}
+}
+
+func TestEventRepository_DeleteByUser(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	repo := repository.NewEventRepository()
+	seedTaggedEvents(t, repo, day)
+
+	// A single delete leaves an empty date bucket behind.
+	if err := repo.Delete(ctx, 2); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	removed, err := repo.DeleteByUser(ctx, 1)
+	if err != nil {
+		t.Fatalf("DeleteByUser() error = %v", err)
+	}
+	if !slices.Equal(titles(removed), []string{"standup", "gym", "review"}) {
+		t.Errorf("DeleteByUser() removed %v; want standup, gym, review", titles(removed))
+	}
+
+	if got, _ := repo.GetByUser(ctx, 1); len(got) != 0 {
+		t.Errorf("events left after DeleteByUser: %v", titles(got))
+	}
+	if n, _ := repo.IndexedByUser(ctx, 1); n != 0 {
+		t.Errorf("IndexedByUser() = %d; want 0", n)
+	}
+	if n, _ := repo.CountByTenant(ctx); n != 1 {
+		t.Errorf("CountByTenant() = %d; want 1", n)
+	}
+	if got, _ := repo.GetByUser(ctx, 2); len(got) != 1 {
+		t.Errorf("DeleteByUser() touched another user's events: got %d; want 1", len(got))
+	}
+}
+
+func TestEventRepository_Outbox(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	ctx := entity.WithTenant(context.Background(), "acme")
+
+	repo := repository.NewEventRepository(repository.WithOutbox())
+	standup, _ := repo.Create(ctx, &entity.Event{UserID: 1, Date: day, Title: "standup"}, entity.EventCreated)
+	gym, _ := repo.Create(ctx, &entity.Event{UserID: 1, Date: day, Title: "gym"}, entity.EventCreated)
+	other, _ := repo.Create(ctx, &entity.Event{UserID: 2, Date: day, Title: "other"}, entity.EventCreated)
+	updated := *standup
+	updated.Title = "retro"
+	if _, err := repo.Update(ctx, &updated, entity.EventUpdated); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := repo.Delete(ctx, gym.ID, entity.EventDeleted); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	pending, _ := repo.PendingOutbox(ctx, 10)
+	var got []string
+	for _, msg := range pending {
+		got = append(got, string(msg.Event.Type)+":"+msg.Event.Key())
+	}
+	want := []string{
+		"event.created:acme/1", "event.created:acme/2", "event.created:acme/3",
+		"event.updated:acme/1", "event.deleted:acme/2",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("PendingOutbox() = %v; want %v", got, want)
+	}
+	if title := pending[3].Event.Event.Title; title != "retro" {
+		t.Errorf("updated snapshot title = %q; want retro", title)
+	}
+	if pending[4].Event.Event != nil {
+		t.Errorf("deletion carries a snapshot: %+v", pending[4].Event.Event)
+	}
+
+	if err := repo.AckOutbox(ctx, pending[1].Seq); err != nil {
+		t.Fatalf("AckOutbox() error = %v", err)
+	}
+	if n, _ := repo.OutboxLen(ctx); n != 3 {
+		t.Errorf("OutboxLen() after ack = %d; want 3", n)
+	}
+
+	// Erasing a user drops the copies of their events still waiting and
+	// records the deletions instead.
+	if _, err := repo.DeleteByUser(ctx, 1, entity.EventDeleted); err != nil {
+		t.Fatalf("DeleteByUser() error = %v", err)
+	}
+	if n, _ := repo.IndexedByUser(ctx, 1); n != 0 {
+		t.Errorf("IndexedByUser() after erase = %d; want 0", n)
+	}
+	pending, _ = repo.PendingOutbox(ctx, 10)
+	got = got[:0]
+	for _, msg := range pending {
+		got = append(got, string(msg.Event.Type)+":"+msg.Event.Key())
+	}
+	want = []string{
+		"event.created:acme/" + strconv.FormatUint(other.ID, 10),
+		"event.deleted:acme/2", "event.deleted:acme/1",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("PendingOutbox() after erase = %v; want %v", got, want)
+	}
+
+	plain := repository.NewEventRepository()
+	if _, err := plain.Create(ctx, &entity.Event{UserID: 1, Date: day}, entity.EventCreated); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if n, _ := plain.OutboxLen(ctx); n != 0 {
+		t.Errorf("OutboxLen() without outbox = %d; want 0", n)
+	}
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"calendar-wbf/internal/entity"
+)
+
+type ExportJobRepository struct {
+	mu   sync.RWMutex
+	jobs map[string]*entity.ExportJob
+}
+
+func NewExportJobRepository() *ExportJobRepository {
+	return &ExportJobRepository{
+		jobs: make(map[string]*entity.ExportJob),
+	}
+}
+
+// Save stores the job in the tenant of the context, replacing an earlier
+// version of it.
+func (r *ExportJobRepository) Save(ctx context.Context, job *entity.ExportJob) (*entity.ExportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saved := *job
+	saved.TenantID = entity.TenantFromContext(ctx)
+	r.jobs[saved.ID] = &saved
+
+	copied := saved
+	return &copied, nil
+}
+
+func (r *ExportJobRepository) Get(ctx context.Context, id string) (*entity.ExportJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, exists := r.jobs[id]
+	if !exists || job.TenantID != entity.TenantFromContext(ctx) {
+		return nil, entity.ErrExportNotFound
+	}
+
+	copied := *job
+	return &copied, nil
+}
+
+// ListByUser returns the jobs of the user, oldest first.
+func (r *ExportJobRepository) ListByUser(ctx context.Context, userID uint64) ([]*entity.ExportJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tenantID := entity.TenantFromContext(ctx)
+	var result []*entity.ExportJob
+	for _, job := range r.jobs {
+		if job.TenantID == tenantID && job.UserID == userID {
+			copied := *job
+			result = append(result, &copied)
+		}
+	}
+
+	slices.SortFunc(result, func(a, b *entity.ExportJob) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return result, nil
+}
+
+func (r *ExportJobRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, exists := r.jobs[id]
+	if !exists || job.TenantID != entity.TenantFromContext(ctx) {
+		return entity.ErrExportNotFound
+	}
+	delete(r.jobs, id)
+	return nil
+}
+
+// DeleteExpired removes the jobs of every tenant that expired before now and
+// returns them, so their archives can be removed too.
+func (r *ExportJobRepository) DeleteExpired(_ context.Context, now time.Time) ([]*entity.ExportJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var expired []*entity.ExportJob
+	for id, job := range r.jobs {
+		if job.ExpiresAt.Before(now) {
+			expired = append(expired, job)
+			delete(r.jobs, id)
+		}
+	}
+	return expired, nil
+}
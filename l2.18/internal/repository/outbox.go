@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"calendar-wbf/internal/entity"
+)
+
+// PendingOutbox returns up to limit unacknowledged messages of all tenants
+// in the order they were recorded.
+func (r *EventRepository) PendingOutbox(_ context.Context, limit int) ([]*entity.OutboxMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Clone(r.outbox[:min(limit, len(r.outbox))]), nil
+}
+
+// AckOutbox drops the messages up to and including seq once the relay has
+// published them.
+func (r *EventRepository) AckOutbox(_ context.Context, seq uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n, _ := slices.BinarySearchFunc(r.outbox, seq+1, func(msg *entity.OutboxMessage, target uint64) int {
+		return cmp.Compare(msg.Seq, target)
+	})
+	r.outbox = slices.Delete(r.outbox, 0, n)
+	return nil
+}
+
+// OutboxLen reports how many messages wait to be published.
+func (r *EventRepository) OutboxLen(_ context.Context) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.outbox), nil
+}
+
+// record stores the domain events raised by a change. Callers hold the
+// write lock.
+func (r *EventRepository) record(event *entity.Event, at time.Time, raise []entity.DomainEventType) {
+	if !r.outboxEnabled {
+		return
+	}
+
+	for _, eventType := range raise {
+		r.outbox = append(r.outbox, &entity.OutboxMessage{
+			Seq:       r.nextSeq,
+			Event:     entity.NewDomainEvent(eventType, event, at),
+			CreatedAt: at,
+		})
+		r.nextSeq++
+	}
+}
+
+// dropOutboxCopies removes the pending messages carrying events of the
+// owner. Callers hold the write lock.
+func (r *EventRepository) dropOutboxCopies(owner entity.Owner) {
+	r.outbox = slices.DeleteFunc(r.outbox, func(msg *entity.OutboxMessage) bool {
+		return msg.Event.Event != nil && ownerOfEvent(msg.Event.Event) == owner
+	})
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"calendar-wbf/internal/entity"
+)
+
+type TagRepository struct {
+	mu   sync.RWMutex
+	tags map[entity.Owner]map[string]*entity.Tag
+}
+
+func NewTagRepository() *TagRepository {
+	return &TagRepository{
+		tags: make(map[entity.Owner]map[string]*entity.Tag),
+	}
+}
+
+func (r *TagRepository) Upsert(ctx context.Context, tag *entity.Tag) (*entity.Tag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	owner := entity.OwnerOf(ctx, tag.UserID)
+	if r.tags[owner] == nil {
+		r.tags[owner] = make(map[string]*entity.Tag)
+	}
+
+	now := time.Now()
+	tag.CreatedAt = now
+	tag.UpdatedAt = now
+	if existing, exists := r.tags[owner][tag.Name]; exists {
+		tag.CreatedAt = existing.CreatedAt
+	}
+
+	r.tags[owner][tag.Name] = tag
+
+	return tag, nil
+}
+
+func (r *TagRepository) ListByUser(ctx context.Context, userID uint64) ([]*entity.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	owner := entity.OwnerOf(ctx, userID)
+	result := make([]*entity.Tag, 0, len(r.tags[owner]))
+	for _, tag := range r.tags[owner] {
+		result = append(result, tag)
+	}
+
+	slices.SortFunc(result, func(a, b *entity.Tag) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return result, nil
+}
+
+func (r *TagRepository) Delete(ctx context.Context, userID uint64, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	owner := entity.OwnerOf(ctx, userID)
+	if _, exists := r.tags[owner][name]; !exists {
+		return entity.ErrTagNotFound
+	}
+
+	delete(r.tags[owner], name)
+
+	return nil
+}
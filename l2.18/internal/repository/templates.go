@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"calendar-wbf/internal/entity"
+)
+
+type TemplateRepository struct {
+	mu        sync.RWMutex
+	templates map[uint64]*entity.Template
+	nextID    uint64
+}
+
+func NewTemplateRepository() *TemplateRepository {
+	return &TemplateRepository{
+		templates: make(map[uint64]*entity.Template),
+		nextID:    1,
+	}
+}
+
+func (r *TemplateRepository) Create(ctx context.Context, template *entity.Template) (*entity.Template, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saved := copyTemplate(template)
+	saved.ID = r.nextID
+	saved.TenantID = entity.TenantFromContext(ctx)
+	saved.CreatedAt = time.Now()
+	saved.UpdatedAt = saved.CreatedAt
+
+	r.templates[saved.ID] = saved
+	r.nextID++
+
+	return copyTemplate(saved), nil
+}
+
+// Update replaces a template of the tenant of the context, keeping its
+// author and creation time.
+func (r *TemplateRepository) Update(ctx context.Context, template *entity.Template) (*entity.Template, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, err := r.get(ctx, template.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	saved := copyTemplate(template)
+	saved.TenantID = existing.TenantID
+	saved.UserID = existing.UserID
+	saved.CreatedAt = existing.CreatedAt
+	saved.UpdatedAt = time.Now()
+	r.templates[saved.ID] = saved
+
+	return copyTemplate(saved), nil
+}
+
+func (r *TemplateRepository) GetByID(ctx context.Context, id uint64) (*entity.Template, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	template, err := r.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return copyTemplate(template), nil
+}
+
+// ListForUser returns the templates of the user and the shared ones of the
+// tenant, ordered by name.
+func (r *TemplateRepository) ListForUser(ctx context.Context, userID uint64) ([]*entity.Template, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tenantID := entity.TenantFromContext(ctx)
+	var result []*entity.Template
+	for _, template := range r.templates {
+		if template.TenantID == tenantID && template.VisibleTo(userID) {
+			result = append(result, copyTemplate(template))
+		}
+	}
+
+	slices.SortFunc(result, func(a, b *entity.Template) int {
+		return cmp.Or(strings.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+	return result, nil
+}
+
+func (r *TemplateRepository) Delete(ctx context.Context, id uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.get(ctx, id); err != nil {
+		return err
+	}
+	delete(r.templates, id)
+	return nil
+}
+
+// get reads a template of the tenant of the context; templates of other
+// tenants are reported as missing. Callers hold the lock.
+func (r *TemplateRepository) get(ctx context.Context, id uint64) (*entity.Template, error) {
+	template, exists := r.templates[id]
+	if !exists || template.TenantID != entity.TenantFromContext(ctx) {
+		return nil, entity.ErrTemplateNotFound
+	}
+	return template, nil
+}
+
+func copyTemplate(template *entity.Template) *entity.Template {
+	copied := *template
+	copied.Tags = slices.Clone(template.Tags)
+	return &copied
+}
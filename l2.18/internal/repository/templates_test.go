@@ -0,0 +1,58 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/internal/repository"
+)
+
+func TestTemplateRepository_Visibility(t *testing.T) {
+	t.Parallel()
+
+	repo := repository.NewTemplateRepository()
+	hr := entity.WithTenant(context.Background(), "hr")
+
+	templates := []*entity.Template{
+		{UserID: 1, Name: "1:1", Title: "1:1 with {{name}}"},
+		{UserID: 1, Name: "Sprint review", Title: "Sprint review", Shared: true},
+		{UserID: 2, Name: "On-call handover", Title: "Handover", Shared: true},
+		{UserID: 2, Name: "Private", Title: "Private"},
+	}
+	for _, template := range templates {
+		if _, err := repo.Create(hr, template); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	other := &entity.Template{UserID: 1, Name: "Other tenant", Shared: true}
+	if _, err := repo.Create(context.Background(), other); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	got, _ := repo.ListForUser(hr, 1)
+	names := make([]string, 0, len(got))
+	for _, template := range got {
+		names = append(names, template.Name)
+	}
+	if want := []string{"1:1", "On-call handover", "Sprint review"}; !slices.Equal(names, want) {
+		t.Errorf("ListForUser() = %v, want %v", names, want)
+	}
+
+	if _, err := repo.GetByID(context.Background(), got[0].ID); !errors.Is(err, entity.ErrTemplateNotFound) {
+		t.Errorf("GetByID() from another tenant error = %v, want ErrTemplateNotFound", err)
+	}
+
+	update := *got[0]
+	update.UserID = 99
+	update.Title = "1:1 / {{name}}"
+	updated, err := repo.Update(hr, &update)
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if updated.UserID != 1 || updated.Title != "1:1 / {{name}}" || !updated.CreatedAt.Equal(got[0].CreatedAt) {
+		t.Errorf("Update() = %+v, want the author and creation time kept", updated)
+	}
+}
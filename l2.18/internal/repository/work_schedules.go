@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"calendar-wbf/internal/entity"
+)
+
+type WorkScheduleRepository struct {
+	mu        sync.RWMutex
+	schedules map[entity.Owner]*entity.WorkSchedule
+}
+
+func NewWorkScheduleRepository() *WorkScheduleRepository {
+	return &WorkScheduleRepository{
+		schedules: make(map[entity.Owner]*entity.WorkSchedule),
+	}
+}
+
+func (r *WorkScheduleRepository) Upsert(
+	ctx context.Context,
+	schedule *entity.WorkSchedule,
+) (*entity.WorkSchedule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	owner := entity.OwnerOf(ctx, schedule.UserID)
+	saved := copySchedule(schedule)
+	saved.TenantID = owner.TenantID
+	saved.UpdatedAt = time.Now()
+	r.schedules[owner] = saved
+
+	return copySchedule(saved), nil
+}
+
+func (r *WorkScheduleRepository) Get(ctx context.Context, userID uint64) (*entity.WorkSchedule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schedule, exists := r.schedules[entity.OwnerOf(ctx, userID)]
+	if !exists {
+		return nil, entity.ErrScheduleNotFound
+	}
+
+	return copySchedule(schedule), nil
+}
+
+func (r *WorkScheduleRepository) Delete(ctx context.Context, userID uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	owner := entity.OwnerOf(ctx, userID)
+	if _, exists := r.schedules[owner]; !exists {
+		return entity.ErrScheduleNotFound
+	}
+	delete(r.schedules, owner)
+
+	return nil
+}
+
+func copySchedule(schedule *entity.WorkSchedule) *entity.WorkSchedule {
+	copied := *schedule
+	copied.WorkDays = slices.Clone(schedule.WorkDays)
+	copied.DaysOff = slices.Clone(schedule.DaysOff)
+	return &copied
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/blob"
+	"calendar-wbf/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+const (
+	_sniffLen              = 512
+	_maxAttachmentNameLen  = 255
+	_defaultAttachmentName = "attachment"
+)
+
+type (
+	AttachmentRepo interface {
+		Add(ctx context.Context, attachment *entity.Attachment, limit int) (*entity.Attachment, error)
+		Get(ctx context.Context, eventID uint64, id string) (*entity.Attachment, error)
+		ListByEvent(ctx context.Context, eventID uint64) ([]*entity.Attachment, error)
+		Delete(ctx context.Context, eventID uint64, id string) error
+		DeleteByEvent(ctx context.Context, eventID uint64) ([]*entity.Attachment, error)
+	}
+
+	// AttachmentLimits bound uploads. ContentTypes lists the allowed media
+	// types, which are detected from the content rather than trusted from
+	// the client.
+	AttachmentLimits struct {
+		MaxSize      int64
+		MaxPerEvent  int
+		ContentTypes []string
+	}
+
+	// AttachmentService keeps files attached to events. Only the owner of
+	// the event may upload, list, download or delete them, and they are
+	// removed along with the event.
+	AttachmentService struct {
+		repo   AttachmentRepo
+		blobs  blob.Store
+		events *EventService
+		limits AttachmentLimits
+		logger logger.Logger
+	}
+)
+
+func NewAttachmentService(
+	repo AttachmentRepo,
+	blobs blob.Store,
+	events *EventService,
+	limits AttachmentLimits,
+	logger logger.Logger,
+) *AttachmentService {
+	s := &AttachmentService{
+		repo:   repo,
+		blobs:  blobs,
+		events: events,
+		limits: limits,
+		logger: logger,
+	}
+	events.OnEventDeleted(s.deleteForEvent)
+
+	return s
+}
+
+func (s *AttachmentService) MaxSize() int64 {
+	return s.limits.MaxSize
+}
+
+// Upload stores r as an attachment of the event. The content type is sniffed
+// from the first bytes and must be allowed; content over MaxSize is rejected
+// and not kept.
+func (s *AttachmentService) Upload(
+	ctx context.Context,
+	eventID, userID uint64,
+	name string,
+	r io.Reader,
+) (*entity.Attachment, error) {
+	const op = "service.UploadAttachment"
+	log := s.logger.Ctx(ctx)
+
+	if _, err := s.events.GetEvent(ctx, eventID, userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	existing, err := s.repo.ListByEvent(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: list attachments: %w", op, err)
+	}
+	if s.limits.MaxPerEvent > 0 && len(existing) >= s.limits.MaxPerEvent {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrTooManyAttachments)
+	}
+
+	content := bufio.NewReaderSize(r, _sniffLen)
+	head, err := content.Peek(_sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: read content: %w", op, err)
+	}
+	if len(head) == 0 {
+		return nil, fmt.Errorf("%s: empty file: %w", op, entity.ErrInvalidData)
+	}
+
+	contentType := http.DetectContentType(head)
+	if !s.allowed(contentType) {
+		return nil, fmt.Errorf("%s: %s: %w", op, contentType, entity.ErrUnsupportedMediaType)
+	}
+
+	attachment := &entity.Attachment{
+		ID:          uuid.NewString(),
+		TenantID:    entity.TenantFromContext(ctx),
+		EventID:     eventID,
+		UserID:      userID,
+		Name:        sanitizeFileName(name),
+		ContentType: contentType,
+		CreatedAt:   time.Now(),
+	}
+
+	hash := sha256.New()
+	limited := &limitedReader{r: content, n: s.limits.MaxSize}
+	size, err := s.blobs.Put(ctx, attachment.BlobKey(), io.TeeReader(limited, hash))
+	if err != nil {
+		return nil, fmt.Errorf("%s: store content: %w", op, err)
+	}
+	attachment.Size = size
+	attachment.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	saved, err := s.repo.Add(ctx, attachment, s.limits.MaxPerEvent)
+	if err != nil {
+		s.deleteBlob(ctx, attachment)
+		return nil, fmt.Errorf("%s: add attachment: %w", op, err)
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "attachment uploaded",
+		logger.String("op", op),
+		logger.Uint64("event_id", eventID),
+		logger.String("attachment_id", saved.ID),
+		logger.String("content_type", saved.ContentType),
+		logger.Int64("size", saved.Size),
+	)
+
+	return saved, nil
+}
+
+func (s *AttachmentService) List(ctx context.Context, eventID, userID uint64) ([]*entity.Attachment, error) {
+	const op = "service.ListAttachments"
+
+	if _, err := s.events.GetEvent(ctx, eventID, userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	attachments, err := s.repo.ListByEvent(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return attachments, nil
+}
+
+// Open returns the attachment with its content, which the caller must close.
+func (s *AttachmentService) Open(
+	ctx context.Context,
+	eventID, userID uint64,
+	id string,
+) (*entity.Attachment, io.ReadCloser, error) {
+	const op = "service.OpenAttachment"
+
+	if _, err := s.events.GetEvent(ctx, eventID, userID); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	attachment, err := s.repo.Get(ctx, eventID, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	content, err := s.blobs.Open(ctx, attachment.BlobKey())
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil, nil, fmt.Errorf("%s: %w: %w", op, entity.ErrAttachmentNotFound, err)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: open content: %w", op, err)
+	}
+	return attachment, content, nil
+}
+
+func (s *AttachmentService) Delete(ctx context.Context, eventID, userID uint64, id string) error {
+	const op = "service.DeleteAttachment"
+	log := s.logger.Ctx(ctx)
+
+	if _, err := s.events.GetEvent(ctx, eventID, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	attachment, err := s.repo.Get(ctx, eventID, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = s.repo.Delete(ctx, eventID, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	s.deleteBlob(ctx, attachment)
+
+	log.LogAttrs(ctx, logger.InfoLevel, "attachment deleted",
+		logger.String("op", op),
+		logger.Uint64("event_id", eventID),
+		logger.String("attachment_id", id),
+	)
+	return nil
+}
+
+// deleteForEvent removes the attachments of a deleted event. The event is
+// gone either way, so a failure is only logged.
+func (s *AttachmentService) deleteForEvent(ctx context.Context, eventID uint64) {
+	const op = "service.DeleteEventAttachments"
+
+	attachments, err := s.repo.DeleteByEvent(ctx, eventID)
+	if err != nil {
+		s.logger.Ctx(ctx).LogAttrs(ctx, logger.WarnLevel, "failed to delete event attachments",
+			logger.String("op", op),
+			logger.Uint64("event_id", eventID),
+			logger.Any("error", err),
+		)
+		return
+	}
+	for _, attachment := range attachments {
+		s.deleteBlob(ctx, attachment)
+	}
+}
+
+// deleteBlob removes stored content. A leftover file only wastes space, so
+// failures are logged rather than returned.
+func (s *AttachmentService) deleteBlob(ctx context.Context, attachment *entity.Attachment) {
+	if err := s.blobs.Delete(ctx, attachment.BlobKey()); err != nil {
+		s.logger.Ctx(ctx).LogAttrs(ctx, logger.WarnLevel, "failed to delete attachment content",
+			logger.String("attachment_id", attachment.ID),
+			logger.Any("error", err),
+		)
+	}
+}
+
+func (s *AttachmentService) allowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return slices.Contains(s.limits.ContentTypes, mediaType)
+}
+
+// sanitizeFileName keeps the base name of a client path without control
+// characters, as it is echoed back in Content-Disposition.
+func sanitizeFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, name))
+
+	if name == "" || name == "." || name == "/" {
+		return _defaultAttachmentName
+	}
+	if utf8.RuneCountInString(name) > _maxAttachmentNameLen {
+		name = string([]rune(name)[:_maxAttachmentNameLen])
+	}
+	return name
+}
+
+// limitedReader fails with ErrAttachmentTooLarge once more than n bytes
+// were read, so the blob store discards the partial upload.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n, entity.ErrAttachmentTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,165 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/internal/repository"
+	"calendar-wbf/internal/service"
+	"calendar-wbf/pkg/blob"
+	"calendar-wbf/pkg/logger"
+)
+
+const _pngHeader = "\x89PNG\r\n\x1a\n"
+
+// newAttachmentService returns a service keeping files under a temporary
+// directory, which it also returns, and an event of user 1.
+func newAttachmentService(
+	t *testing.T,
+	limits service.AttachmentLimits,
+) (*service.AttachmentService, *service.EventService, *entity.Event, string, context.Context) {
+	t.Helper()
+
+	f := newEventService(t)
+	events, ctx := f.svc, f.ctx
+	dir := t.TempDir()
+	blobs, err := blob.NewFSStore(dir)
+	if err != nil {
+		t.Fatalf("NewFSStore() error = %v", err)
+	}
+
+	svc := service.NewAttachmentService(repository.NewAttachmentRepository(), blobs, events, limits, logger.NewNop())
+	event, err := events.CreateEvent(ctx, 1, time.Now().Add(time.Hour), time.Hour, "Review", "slides", nil, "")
+	if err != nil {
+		t.Fatalf("CreateEvent() error = %v", err)
+	}
+	return svc, events, event, dir, ctx
+}
+
+// countFiles returns how many files are stored under dir.
+func countFiles(t *testing.T, dir string) int {
+	t.Helper()
+
+	var n int
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			n++
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WalkDir() error = %v", err)
+	}
+	return n
+}
+
+func TestAttachmentService_Upload(t *testing.T) {
+	t.Parallel()
+
+	limits := service.AttachmentLimits{MaxSize: 1024, MaxPerEvent: 2, ContentTypes: []string{"image/png", "text/plain"}}
+
+	testCases := []struct {
+		desc        string
+		userID      uint64
+		content     string
+		wantErr     error
+		wantType    string
+		wantStored  int
+		uploadsDone int
+	}{
+		{desc: "PNG", userID: 1, content: _pngHeader + "data", wantType: "image/png", wantStored: 1},
+		{desc: "Text", userID: 1, content: "notes", wantType: "text/plain; charset=utf-8", wantStored: 1},
+		{desc: "TypeSniffedNotTrusted", userID: 1, content: "%PDF-1.7", wantErr: entity.ErrUnsupportedMediaType},
+		{desc: "Empty", userID: 1, content: "", wantErr: entity.ErrInvalidData},
+		{desc: "TooLarge", userID: 1, content: strings.Repeat("a", 1025), wantErr: entity.ErrAttachmentTooLarge},
+		{desc: "AtSizeLimit", userID: 1, content: strings.Repeat("a", 1024), wantType: "text/plain; charset=utf-8",
+			wantStored: 1},
+		{desc: "NotOwner", userID: 2, content: "notes", wantErr: entity.ErrEventNotFound},
+		{desc: "PerEventLimit", userID: 1, content: "notes", wantErr: entity.ErrTooManyAttachments,
+			wantStored: 2, uploadsDone: 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			svc, _, event, dir, ctx := newAttachmentService(t, limits)
+			for range tc.uploadsDone {
+				if _, err := svc.Upload(ctx, event.ID, 1, "a.txt", strings.NewReader("earlier")); err != nil {
+					t.Fatalf("Upload() error = %v", err)
+				}
+			}
+
+			got, err := svc.Upload(ctx, event.ID, tc.userID, "report.pdf", strings.NewReader(tc.content))
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Upload() error = %v; want %v", err, tc.wantErr)
+			}
+			if err == nil && (got.ContentType != tc.wantType || got.Size != int64(len(tc.content))) {
+				t.Errorf("attachment = %s, %d bytes; want %s, %d bytes",
+					got.ContentType, got.Size, tc.wantType, len(tc.content))
+			}
+			if n := countFiles(t, dir); n != tc.wantStored {
+				t.Errorf("stored files = %d; want %d", n, tc.wantStored)
+			}
+		})
+	}
+}
+
+func TestAttachmentService_OwnerOnly(t *testing.T) {
+	t.Parallel()
+
+	svc, _, event, _, ctx := newAttachmentService(t, service.AttachmentLimits{
+		MaxSize: 1024, ContentTypes: []string{"text/plain"},
+	})
+	attachment, err := svc.Upload(ctx, event.ID, 1, "notes.txt", strings.NewReader("notes"))
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	if _, err = svc.List(ctx, event.ID, 2); !errors.Is(err, entity.ErrEventNotFound) {
+		t.Errorf("List() by another user error = %v; want %v", err, entity.ErrEventNotFound)
+	}
+	if _, _, err = svc.Open(ctx, event.ID, 2, attachment.ID); !errors.Is(err, entity.ErrEventNotFound) {
+		t.Errorf("Open() by another user error = %v; want %v", err, entity.ErrEventNotFound)
+	}
+	if err = svc.Delete(ctx, event.ID, 2, attachment.ID); !errors.Is(err, entity.ErrEventNotFound) {
+		t.Errorf("Delete() by another user error = %v; want %v", err, entity.ErrEventNotFound)
+	}
+
+	_, content, err := svc.Open(ctx, event.ID, 1, attachment.ID)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer content.Close()
+	if data, _ := io.ReadAll(content); !bytes.Equal(data, []byte("notes")) {
+		t.Errorf("content = %q; want %q", data, "notes")
+	}
+}
+
+func TestAttachmentService_DeletedWithEvent(t *testing.T) {
+	t.Parallel()
+
+	svc, events, event, dir, ctx := newAttachmentService(t, service.AttachmentLimits{
+		MaxSize: 1024, ContentTypes: []string{"text/plain"},
+	})
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if _, err := svc.Upload(ctx, event.ID, 1, name, strings.NewReader("notes")); err != nil {
+			t.Fatalf("Upload() error = %v", err)
+		}
+	}
+
+	if err := events.DeleteEvent(ctx, event.ID, 1); err != nil {
+		t.Fatalf("DeleteEvent() error = %v", err)
+	}
+	if n := countFiles(t, dir); n != 0 {
+		t.Errorf("stored files after event deletion = %d; want 0", n)
+	}
+}
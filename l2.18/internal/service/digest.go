@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"time"
+
+	"calendar-wbf/internal/digest"
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/logger"
+)
+
+const (
+	_defaultDigestSendAt = "08:00"
+	_digestClockLayout   = "15:04"
+)
+
+type (
+	DigestRepo interface {
+		Upsert(ctx context.Context, sub *entity.DigestSubscription) (*entity.DigestSubscription, error)
+		Get(ctx context.Context, userID uint64) (*entity.DigestSubscription, error)
+		Delete(ctx context.Context, userID uint64) error
+	}
+
+	DigestService struct {
+		repo      DigestRepo
+		generator *digest.Generator
+		events    *EventService
+		logger    logger.Logger
+	}
+)
+
+func NewDigestService(
+	repo DigestRepo,
+	generator *digest.Generator,
+	events *EventService,
+	logger logger.Logger,
+) *DigestService {
+	return &DigestService{
+		repo:      repo,
+		generator: generator,
+		events:    events,
+		logger:    logger,
+	}
+}
+
+func (s *DigestService) SaveSubscription(
+	ctx context.Context,
+	sub *entity.DigestSubscription,
+) (*entity.DigestSubscription, error) {
+	const op = "service.SaveSubscription"
+	log := s.logger.Ctx(ctx)
+
+	if err := s.events.validateUserID(sub.UserID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	applyDigestDefaults(sub)
+	if err := validateDigestSubscription(sub); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, _defaultContextTimeout)
+	defer cancel()
+
+	saved, err := s.repo.Upsert(ctx, sub)
+	if err != nil {
+		return nil, fmt.Errorf("%s: upsert subscription: %w", op, err)
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "digest subscription saved",
+		logger.String("op", op),
+		logger.Uint64("user_id", saved.UserID),
+		logger.String("period", string(saved.Period)),
+		logger.String("send_at", saved.SendAt),
+		logger.String("timezone", saved.Timezone),
+	)
+
+	return saved, nil
+}
+
+func (s *DigestService) DeleteSubscription(ctx context.Context, userID uint64) error {
+	const op = "service.DeleteSubscription"
+	log := s.logger.Ctx(ctx)
+
+	if err := s.events.validateUserID(userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, _defaultContextTimeout)
+	defer cancel()
+
+	if err := s.repo.Delete(ctx, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "digest subscription deleted",
+		logger.String("op", op),
+		logger.Uint64("user_id", userID),
+	)
+
+	return nil
+}
+
+// PreviewDigest renders the digest the user would receive right now. Empty
+// period, format or timezone fall back to the saved subscription, if any.
+func (s *DigestService) PreviewDigest(
+	ctx context.Context,
+	userID uint64,
+	period entity.DigestPeriod,
+	format entity.DigestFormat,
+	timezone string,
+) (*entity.Digest, error) {
+	const op = "service.PreviewDigest"
+
+	if err := s.events.validateUserID(userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sub := &entity.DigestSubscription{UserID: userID}
+	if saved, err := s.repo.Get(ctx, userID); err == nil {
+		sub = saved
+	}
+	if period != "" {
+		sub.Period = period
+	}
+	if format != "" {
+		sub.Format = format
+	}
+	if timezone != "" {
+		sub.Timezone = timezone
+	}
+
+	applyDigestDefaults(sub)
+	if err := validateDigestSubscription(sub); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	loc, err := time.LoadLocation(sub.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrInvalidTimezone)
+	}
+
+	result, err := s.generator.Generate(ctx, userID, sub.Period, sub.Format, time.Now(), loc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	result.To = sub.Email
+
+	return result, nil
+}
+
+func applyDigestDefaults(sub *entity.DigestSubscription) {
+	if sub.Timezone == "" {
+		sub.Timezone = time.UTC.String()
+	}
+	if sub.SendAt == "" {
+		sub.SendAt = _defaultDigestSendAt
+	}
+	if sub.Period == "" {
+		sub.Period = entity.DigestDaily
+	}
+	if sub.Format == "" {
+		sub.Format = entity.DigestText
+	}
+}
+
+func validateDigestSubscription(sub *entity.DigestSubscription) error {
+	if _, err := time.LoadLocation(sub.Timezone); err != nil {
+		return entity.ErrInvalidTimezone
+	}
+	if _, err := time.Parse(_digestClockLayout, sub.SendAt); err != nil {
+		return entity.ErrInvalidDigest
+	}
+	if sub.Period != entity.DigestDaily && sub.Period != entity.DigestWeekly {
+		return entity.ErrInvalidDigest
+	}
+	if sub.Format != entity.DigestText && sub.Format != entity.DigestHTML && sub.Format != entity.DigestMarkdown {
+		return entity.ErrInvalidDigest
+	}
+	if sub.Weekday < time.Sunday || sub.Weekday > time.Saturday {
+		return entity.ErrInvalidDigest
+	}
+	if sub.Email != "" {
+		if _, err := mail.ParseAddress(sub.Email); err != nil {
+			return entity.ErrInvalidDigest
+		}
+	}
+	return nil
+}
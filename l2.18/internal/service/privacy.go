@@ -0,0 +1,664 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/blob"
+	"calendar-wbf/pkg/ical"
+	"calendar-wbf/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+const (
+	_exportTimeout       = 5 * time.Minute
+	_exportFormatVersion = 1
+	_exportProdID        = "-//calendar-wbf//Export//EN"
+)
+
+type (
+	ExportJobRepo interface {
+		Save(ctx context.Context, job *entity.ExportJob) (*entity.ExportJob, error)
+		Get(ctx context.Context, id string) (*entity.ExportJob, error)
+		ListByUser(ctx context.Context, userID uint64) ([]*entity.ExportJob, error)
+		Delete(ctx context.Context, id string) error
+		DeleteExpired(ctx context.Context, now time.Time) ([]*entity.ExportJob, error)
+	}
+
+	UserEventRepo interface {
+		GetByUser(ctx context.Context, userID uint64) ([]*entity.Event, error)
+		DeleteByUser(ctx context.Context, userID uint64, raise ...entity.DomainEventType) ([]*entity.Event, error)
+		IndexedByUser(ctx context.Context, userID uint64) (int, error)
+	}
+
+	// PrivacyStores are the stores holding data of a user, which exports
+	// read and erasure clears.
+	PrivacyStores struct {
+		Events    UserEventRepo
+		Tags      TagRepo
+		Digests   DigestRepo
+		Schedules WorkScheduleRepo
+		Templates TemplateRepo
+		Exports   ExportJobRepo
+	}
+
+	// PrivacyService exports everything stored about a user into a
+	// downloadable archive and erases it on request. Audit records are the
+	// log entries of the user's tenant kept by the ring sink: only the most
+	// recent ones, held in memory and lost on restart, so older records
+	// written to files or syslog are neither exported nor erased.
+	PrivacyService struct {
+		stores      PrivacyStores
+		events      *EventService
+		attachments *AttachmentService
+		archives    blob.Store
+		audit       *logger.Ring
+		exportTTL   time.Duration
+		logger      logger.Logger
+		running     sync.WaitGroup
+	}
+
+	exportData struct {
+		Version      int                        `json:"version"`
+		TenantID     string                     `json:"tenant_id"`
+		UserID       uint64                     `json:"user_id"`
+		ExportedAt   time.Time                  `json:"exported_at"`
+		Events       []*entity.Event            `json:"events"`
+		Tags         []*entity.Tag              `json:"tags"`
+		Digest       *entity.DigestSubscription `json:"digest,omitempty"`
+		WorkSchedule *entity.WorkSchedule       `json:"work_schedule,omitempty"`
+		Templates    []*entity.Template         `json:"templates"`
+		Attachments  []*entity.Attachment       `json:"attachments"`
+		Audit        []logger.RingEntry         `json:"audit"`
+	}
+)
+
+func NewPrivacyService(
+	stores PrivacyStores,
+	events *EventService,
+	attachments *AttachmentService,
+	archives blob.Store,
+	audit *logger.Ring,
+	exportTTL time.Duration,
+	logger logger.Logger,
+) *PrivacyService {
+	return &PrivacyService{
+		stores:      stores,
+		events:      events,
+		attachments: attachments,
+		archives:    archives,
+		audit:       audit,
+		exportTTL:   exportTTL,
+		logger:      logger,
+	}
+}
+
+// StartExport queues an export of the user and returns the pending job. The
+// archive is built in the background and outlives the request.
+func (s *PrivacyService) StartExport(ctx context.Context, userID uint64) (*entity.ExportJob, error) {
+	const op = "service.StartExport"
+	log := s.logger.Ctx(ctx)
+
+	if err := s.events.validateUserID(userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.purgeExpired(ctx)
+
+	now := time.Now()
+	job, err := s.stores.Exports.Save(ctx, &entity.ExportJob{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Status:    entity.ExportPending,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.exportTTL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: save job: %w", op, err)
+	}
+
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		s.runExport(context.WithoutCancel(ctx), *job)
+	}()
+
+	log.LogAttrs(ctx, logger.InfoLevel, "export started",
+		logger.String("op", op),
+		logger.String("export_id", job.ID),
+		logger.Uint64("user_id", userID),
+	)
+
+	return job, nil
+}
+
+func (s *PrivacyService) GetExport(ctx context.Context, id string) (*entity.ExportJob, error) {
+	const op = "service.GetExport"
+
+	s.purgeExpired(ctx)
+
+	job, err := s.stores.Exports.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return job, nil
+}
+
+// OpenExport returns a finished job with its archive, which the caller must
+// close.
+func (s *PrivacyService) OpenExport(ctx context.Context, id string) (*entity.ExportJob, io.ReadCloser, error) {
+	const op = "service.OpenExport"
+
+	job, err := s.GetExport(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if job.Status != entity.ExportDone {
+		return nil, nil, fmt.Errorf("%s: %s: %w", op, job.Status, entity.ErrExportNotReady)
+	}
+
+	archive, err := s.archives.Open(ctx, job.BlobKey())
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil, nil, fmt.Errorf("%s: %w: %w", op, entity.ErrExportNotFound, err)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: open archive: %w", op, err)
+	}
+	return job, archive, nil
+}
+
+// Wait blocks until the running exports finish, so shutdown does not cut
+// an archive short.
+func (s *PrivacyService) Wait() {
+	s.running.Wait()
+}
+
+func (s *PrivacyService) runExport(ctx context.Context, job entity.ExportJob) {
+	const op = "service.runExport"
+	log := s.logger.Ctx(ctx)
+
+	ctx, cancel := context.WithTimeout(ctx, _exportTimeout)
+	defer cancel()
+
+	job.Status = entity.ExportRunning
+	if _, err := s.stores.Exports.Save(ctx, &job); err != nil {
+		log.LogAttrs(ctx, logger.ErrorLevel, "failed to save export job",
+			logger.String("op", op),
+			logger.Any("error", err),
+			logger.String("export_id", job.ID),
+		)
+		return
+	}
+
+	size, err := s.writeArchive(ctx, &job)
+
+	// The user may have been erased while the archive was written; the job
+	// is gone then and the archive must not stay behind.
+	if _, getErr := s.stores.Exports.Get(ctx, job.ID); errors.Is(getErr, entity.ErrExportNotFound) {
+		s.deleteArchive(ctx, &job)
+		return
+	}
+
+	job.CompletedAt = time.Now()
+	job.ExpiresAt = job.CompletedAt.Add(s.exportTTL)
+	if err != nil {
+		job.Status = entity.ExportFailed
+		job.Error = err.Error()
+		s.deleteArchive(ctx, &job)
+
+		log.LogAttrs(ctx, logger.ErrorLevel, "export failed",
+			logger.String("op", op),
+			logger.Any("error", err),
+			logger.String("export_id", job.ID),
+		)
+	} else {
+		job.Status = entity.ExportDone
+		job.Size = size
+
+		log.LogAttrs(ctx, logger.InfoLevel, "export finished",
+			logger.String("op", op),
+			logger.String("export_id", job.ID),
+			logger.Int64("size", size),
+		)
+	}
+
+	if _, err = s.stores.Exports.Save(ctx, &job); err != nil {
+		log.LogAttrs(ctx, logger.ErrorLevel, "failed to save export job",
+			logger.String("op", op),
+			logger.Any("error", err),
+			logger.String("export_id", job.ID),
+		)
+	}
+}
+
+// writeArchive streams the zip archive of the user into the archive store.
+func (s *PrivacyService) writeArchive(ctx context.Context, job *entity.ExportJob) (int64, error) {
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(s.buildArchive(ctx, job, w))
+	}()
+
+	size, err := s.archives.Put(ctx, job.BlobKey(), r)
+	// Unblocks the writer if the store gave up before reading everything.
+	r.Close()
+	return size, err
+}
+
+// buildArchive writes data.json with every record of the user, events.ics
+// with their events and the attachment files under attachments/.
+func (s *PrivacyService) buildArchive(ctx context.Context, job *entity.ExportJob, w io.Writer) error {
+	data, err := s.collect(ctx, job.UserID)
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+
+	f, err := createEntry(archive, "data.json", data.ExportedAt)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err = enc.Encode(data); err != nil {
+		return fmt.Errorf("write data.json: %w", err)
+	}
+
+	if f, err = createEntry(archive, "events.ics", data.ExportedAt); err != nil {
+		return err
+	}
+	vevents := make([]ical.Event, 0, len(data.Events))
+	for _, event := range data.Events {
+		vevents = append(vevents, event.ICal())
+	}
+	if err = ical.Encode(f, _exportProdID, vevents...); err != nil {
+		return fmt.Errorf("write events.ics: %w", err)
+	}
+
+	for _, attachment := range data.Attachments {
+		if err = s.addAttachment(ctx, archive, attachment); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+func (s *PrivacyService) addAttachment(ctx context.Context, archive *zip.Writer, attachment *entity.Attachment) error {
+	content, err := s.attachments.blobs.Open(ctx, attachment.BlobKey())
+	if errors.Is(err, blob.ErrNotFound) {
+		// Listed in data.json all the same; the file was lost, not withheld.
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open attachment %s: %w", attachment.ID, err)
+	}
+	defer content.Close()
+
+	name := path.Join("attachments", strconv.FormatUint(attachment.EventID, 10), attachment.ID+"-"+attachment.Name)
+	f, err := createEntry(archive, name, attachment.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, content); err != nil {
+		return fmt.Errorf("write attachment %s: %w", attachment.ID, err)
+	}
+	return nil
+}
+
+func (s *PrivacyService) collect(ctx context.Context, userID uint64) (*exportData, error) {
+	data := &exportData{
+		Version:    _exportFormatVersion,
+		TenantID:   entity.TenantFromContext(ctx),
+		UserID:     userID,
+		ExportedAt: time.Now(),
+		Audit:      s.auditEntries(ctx, userID),
+	}
+
+	var err error
+	if data.Events, err = s.stores.Events.GetByUser(ctx, userID); err != nil {
+		return nil, fmt.Errorf("list events: %w", err)
+	}
+	if data.Tags, err = s.stores.Tags.ListByUser(ctx, userID); err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+	if data.Templates, err = s.authoredTemplates(ctx, userID); err != nil {
+		return nil, fmt.Errorf("list templates: %w", err)
+	}
+
+	data.Digest, err = s.stores.Digests.Get(ctx, userID)
+	if err != nil && !errors.Is(err, entity.ErrDigestNotFound) {
+		return nil, fmt.Errorf("get digest: %w", err)
+	}
+	data.WorkSchedule, err = s.stores.Schedules.Get(ctx, userID)
+	if err != nil && !errors.Is(err, entity.ErrScheduleNotFound) {
+		return nil, fmt.Errorf("get work schedule: %w", err)
+	}
+
+	for _, event := range data.Events {
+		attachments, listErr := s.attachments.repo.ListByEvent(ctx, event.ID)
+		if listErr != nil {
+			return nil, fmt.Errorf("list attachments: %w", listErr)
+		}
+		data.Attachments = append(data.Attachments, attachments...)
+	}
+
+	return data, nil
+}
+
+// auditEntries returns the log entries of the ring that name the user in
+// the tenant of the context.
+func (s *PrivacyService) auditEntries(ctx context.Context, userID uint64) []logger.RingEntry {
+	if s.audit == nil {
+		return nil
+	}
+
+	var entries []logger.RingEntry
+	match := auditMatch(ctx, userID)
+	for _, e := range s.audit.Entries(0, logger.DebugLevel) {
+		if match(e) {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// auditMatch reports whether a log entry names the user. Entries without the
+// tenant of the context, including those logged outside of a request, may
+// be about a namesake in another tenant and are left alone.
+func auditMatch(ctx context.Context, userID uint64) func(logger.RingEntry) bool {
+	tenantID := entity.TenantFromContext(ctx)
+	user := strconv.FormatUint(userID, 10)
+
+	return func(e logger.RingEntry) bool {
+		tenant, ok := e.Fields["tenant_id"]
+		return ok && fmt.Sprint(tenant) == tenantID && fmt.Sprint(e.Fields["user_id"]) == user
+	}
+}
+
+// Erase removes the user from every store, the event cache, the event
+// indexes and the audit ring, then checks each of them again. Templates the user shared go
+// too. Erasing an unknown or already erased user succeeds with nothing
+// removed, so a failed erase can simply be repeated.
+func (s *PrivacyService) Erase(ctx context.Context, userID uint64) (*entity.EraseReport, error) {
+	const op = "service.Erase"
+	log := s.logger.Ctx(ctx)
+
+	if err := s.events.validateUserID(userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	report := &entity.EraseReport{
+		TenantID: entity.TenantFromContext(ctx),
+		UserID:   userID,
+		Removed:  make(map[string]int),
+	}
+
+	events, err := s.stores.Events.DeleteByUser(ctx, userID, entity.EventDeleted)
+	if err != nil {
+		return nil, fmt.Errorf("%s: delete events: %w", op, err)
+	}
+	report.Removed["events"] = len(events)
+	report.Removed["cache"] = s.events.evictEvents(events)
+
+	report.Removed["attachments"] = 0
+	for _, event := range events {
+		attachments, deleteErr := s.attachments.repo.DeleteByEvent(ctx, event.ID)
+		if deleteErr != nil {
+			return nil, fmt.Errorf("%s: delete attachments: %w", op, deleteErr)
+		}
+		for _, attachment := range attachments {
+			s.attachments.deleteBlob(ctx, attachment)
+		}
+		report.Removed["attachments"] += len(attachments)
+	}
+
+	if report.Removed["tags"], err = s.eraseTags(ctx, userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if report.Removed["templates"], err = s.eraseTemplates(ctx, userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if report.Removed["exports"], err = s.eraseExports(ctx, userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	report.Removed["digest"], err = eraseSetting(s.stores.Digests.Delete(ctx, userID), entity.ErrDigestNotFound)
+	if err != nil {
+		return nil, fmt.Errorf("%s: delete digest: %w", op, err)
+	}
+	report.Removed["work_schedule"], err = eraseSetting(
+		s.stores.Schedules.Delete(ctx, userID),
+		entity.ErrScheduleNotFound,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: delete work schedule: %w", op, err)
+	}
+
+	// Last, as the steps above log about the user too.
+	if s.audit != nil {
+		report.Removed["audit"] = s.audit.DeleteFunc(auditMatch(ctx, userID))
+	}
+
+	remaining, err := s.remaining(ctx, userID, events)
+	if err != nil {
+		return nil, fmt.Errorf("%s: verify: %w", op, err)
+	}
+	maps.DeleteFunc(remaining, func(_ string, n int) bool { return n == 0 })
+	if len(remaining) > 0 {
+		report.Remaining = remaining
+	}
+	report.Verified = len(remaining) == 0
+	report.ErasedAt = time.Now()
+
+	level := logger.InfoLevel
+	if !report.Verified {
+		level = logger.ErrorLevel
+	}
+	log.LogAttrs(ctx, level, "user data erased",
+		logger.String("op", op),
+		logger.Uint64("user_id", userID),
+		logger.Int("events", report.Removed["events"]),
+		logger.Bool("verified", report.Verified),
+	)
+
+	return report, nil
+}
+
+// remaining counts what the stores still hold for the user after an erase.
+// removed are the erased events, whose cache entries and attachments are
+// looked up.
+func (s *PrivacyService) remaining(
+	ctx context.Context,
+	userID uint64,
+	removed []*entity.Event,
+) (map[string]int, error) {
+	remaining := make(map[string]int)
+
+	events, err := s.stores.Events.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	remaining["events"] = len(events)
+
+	if remaining["event_index"], err = s.stores.Events.IndexedByUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	remaining["cache"] = s.events.cachedEvents(removed)
+
+	for _, event := range removed {
+		attachments, listErr := s.attachments.repo.ListByEvent(ctx, event.ID)
+		if listErr != nil {
+			return nil, listErr
+		}
+		remaining["attachments"] += len(attachments)
+	}
+
+	tags, err := s.stores.Tags.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	remaining["tags"] = len(tags)
+
+	templates, err := s.authoredTemplates(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	remaining["templates"] = len(templates)
+
+	jobs, err := s.stores.Exports.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	remaining["exports"] = len(jobs)
+
+	if _, err = s.stores.Digests.Get(ctx, userID); !errors.Is(err, entity.ErrDigestNotFound) {
+		remaining["digest"] = 1
+	}
+	if _, err = s.stores.Schedules.Get(ctx, userID); !errors.Is(err, entity.ErrScheduleNotFound) {
+		remaining["work_schedule"] = 1
+	}
+
+	remaining["audit"] = len(s.auditEntries(ctx, userID))
+
+	return remaining, nil
+}
+
+func (s *PrivacyService) eraseTags(ctx context.Context, userID uint64) (int, error) {
+	tags, err := s.stores.Tags.ListByUser(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("list tags: %w", err)
+	}
+	for _, tag := range tags {
+		if err = s.stores.Tags.Delete(ctx, userID, tag.Name); err != nil && !errors.Is(err, entity.ErrTagNotFound) {
+			return 0, fmt.Errorf("delete tag: %w", err)
+		}
+	}
+	return len(tags), nil
+}
+
+func (s *PrivacyService) eraseTemplates(ctx context.Context, userID uint64) (int, error) {
+	templates, err := s.authoredTemplates(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("list templates: %w", err)
+	}
+	for _, template := range templates {
+		if err = s.stores.Templates.Delete(ctx, template.ID); err != nil &&
+			!errors.Is(err, entity.ErrTemplateNotFound) {
+			return 0, fmt.Errorf("delete template: %w", err)
+		}
+	}
+	return len(templates), nil
+}
+
+func (s *PrivacyService) eraseExports(ctx context.Context, userID uint64) (int, error) {
+	jobs, err := s.stores.Exports.ListByUser(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("list exports: %w", err)
+	}
+	for _, job := range jobs {
+		if err = s.stores.Exports.Delete(ctx, job.ID); err != nil && !errors.Is(err, entity.ErrExportNotFound) {
+			return 0, fmt.Errorf("delete export: %w", err)
+		}
+		s.deleteArchive(ctx, job)
+	}
+	return len(jobs), nil
+}
+
+// authoredTemplates returns the templates the user wrote, leaving out the
+// shared templates of others.
+func (s *PrivacyService) authoredTemplates(ctx context.Context, userID uint64) ([]*entity.Template, error) {
+	templates, err := s.stores.Templates.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	authored := templates[:0]
+	for _, template := range templates {
+		if template.UserID == userID {
+			authored = append(authored, template)
+		}
+	}
+	return authored, nil
+}
+
+// purgeExpired drops expired jobs of every tenant with their archives.
+func (s *PrivacyService) purgeExpired(ctx context.Context) {
+	expired, err := s.stores.Exports.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		s.logger.Ctx(ctx).LogAttrs(ctx, logger.WarnLevel, "failed to purge expired exports",
+			logger.Any("error", err),
+		)
+		return
+	}
+	for _, job := range expired {
+		s.deleteArchive(ctx, job)
+	}
+}
+
+// deleteArchive removes the archive of a job. A leftover file is logged
+// rather than returned, like leftover attachment content.
+func (s *PrivacyService) deleteArchive(ctx context.Context, job *entity.ExportJob) {
+	if err := s.archives.Delete(ctx, job.BlobKey()); err != nil {
+		s.logger.Ctx(ctx).LogAttrs(ctx, logger.WarnLevel, "failed to delete export archive",
+			logger.String("export_id", job.ID),
+			logger.Any("error", err),
+		)
+	}
+}
+
+func createEntry(archive *zip.Writer, name string, modified time.Time) (io.Writer, error) {
+	return archive.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+}
+
+// eraseSetting turns the result of deleting a per-user setting into the
+// number removed; a missing setting is not an error.
+func eraseSetting(err, notFound error) (int, error) {
+	switch {
+	case err == nil:
+		return 1, nil
+	case errors.Is(err, notFound):
+		return 0, nil
+	default:
+		return 0, err
+	}
+}
+
+// evictEvents drops the cached copies of events from every cache tier and
+// returns how many entries were removed. Keys are derived from the events
+// rather than listed, since a remote tier cannot be listed.
+func (s *EventService) evictEvents(events []*entity.Event) int {
+	var n int
+	for _, event := range events {
+		if s.cache.Delete(eventCacheKey(event)) {
+			n++
+		}
+	}
+	return n
+}
+
+// cachedEvents counts the events still cached in any tier.
+func (s *EventService) cachedEvents(events []*entity.Event) int {
+	var n int
+	for _, event := range events {
+		if _, ok := s.cache.Peek(eventCacheKey(event)); ok {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,65 @@
+package service_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"calendar-wbf/internal/config"
+	"calendar-wbf/internal/repository"
+	"calendar-wbf/internal/service"
+	"calendar-wbf/pkg/blob"
+	"calendar-wbf/pkg/logger"
+)
+
+func TestPrivacyService_EraseAudit(t *testing.T) {
+	t.Parallel()
+
+	audit, err := logger.NewAdapter(&config.Config{Logger: config.Logger{Level: "info"}},
+		logger.Sinks(logger.SinkRing),
+	)
+	if err != nil {
+		t.Fatalf("NewAdapter() error = %v", err)
+	}
+	ring := logger.RingOf(audit)
+
+	f := newEventService(t)
+	blobs, err := blob.NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFSStore() error = %v", err)
+	}
+	attachments := service.NewAttachmentService(
+		repository.NewAttachmentRepository(), blobs, f.svc, service.AttachmentLimits{}, logger.NewNop(),
+	)
+	svc := service.NewPrivacyService(service.PrivacyStores{
+		Events:    f.repo,
+		Tags:      repository.NewTagRepository(),
+		Digests:   repository.NewDigestSubscriptionRepository(),
+		Schedules: repository.NewWorkScheduleRepository(),
+		Templates: repository.NewTemplateRepository(),
+		Exports:   repository.NewExportJobRepository(),
+	}, f.svc, attachments, blobs, ring, time.Hour, logger.NewNop())
+
+	acme := audit.WithTenantID(f.ctx, "acme")
+	audit.Ctx(acme).Infow("erased user", "user_id", 1)
+	audit.Ctx(acme).Infow("other user", "user_id", 2)
+	audit.Ctx(audit.WithTenantID(context.Background(), "globex")).Infow("other tenant", "user_id", 1)
+	audit.Infow("no tenant", "user_id", 1)
+
+	report, err := svc.Erase(f.ctx, 1)
+	if err != nil {
+		t.Fatalf("Erase() error = %v", err)
+	}
+	if report.Removed["audit"] != 1 || !report.Verified {
+		t.Errorf("report = %+v; want one audit entry removed and verified", report)
+	}
+
+	var kept []string
+	for _, e := range ring.Entries(0, logger.DebugLevel) {
+		kept = append(kept, e.Message)
+	}
+	if want := []string{"other user", "other tenant", "no tenant"}; !slices.Equal(kept, want) {
+		t.Errorf("ring = %q; want %q", kept, want)
+	}
+}
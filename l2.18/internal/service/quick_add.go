@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/logger"
+	"calendar-wbf/pkg/markdown"
+	"calendar-wbf/pkg/quickadd"
+)
+
+// QuickAdd turns a free-form phrase such as "Lunch with Anna tomorrow at 13:00
+// for 1h" into an event in the user's timezone. Without create the event is
+// only validated and returned as a draft for confirmation.
+func (s *EventService) QuickAdd(
+	ctx context.Context,
+	userID uint64,
+	input, timezone string,
+	create bool,
+) (*entity.QuickAdd, error) {
+	const op = "service.QuickAdd"
+	log := s.logger.Ctx(ctx)
+
+	if err := s.validateUserID(userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	loc := time.UTC
+	if timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("%s: load location %q: %w", op, timezone, entity.ErrInvalidTimezone)
+		}
+	}
+
+	parsed, err := s.parser.Parse(input, loc)
+	if err != nil {
+		if errors.Is(err, quickadd.ErrEmptyInput) {
+			return nil, fmt.Errorf("%s: %w", op, entity.ErrInvalidData)
+		}
+		return nil, fmt.Errorf("%s: %w: %w", op, entity.ErrUnparsableText, err)
+	}
+
+	result := &entity.QuickAdd{
+		AllDay: parsed.AllDay,
+		Lang:   string(parsed.Lang),
+	}
+
+	if !create {
+		result.Event = &entity.Event{
+			UserID:   userID,
+			Date:     parsed.Start,
+			Duration: parsed.Duration,
+			Title:    parsed.Title,
+			Text:     input,
+		}
+		if err = s.validateEvent(result.Event); err != nil {
+			return nil, fmt.Errorf("%s: validate event: %w", op, err)
+		}
+		result.Event.TextHTML = markdown.Render(input)
+		return result, nil
+	}
+
+	result.Event, err = s.CreateEvent(ctx, userID, parsed.Start, parsed.Duration, parsed.Title, input, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	result.Created = true
+
+	log.LogAttrs(ctx, logger.InfoLevel, "event created via quick add",
+		logger.String("op", op),
+		logger.Uint64("event_id", result.Event.ID),
+		logger.String("lang", result.Lang),
+	)
+
+	return result, nil
+}
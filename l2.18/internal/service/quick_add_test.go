@@ -0,0 +1,91 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/internal/repository"
+	"calendar-wbf/internal/service"
+	"calendar-wbf/pkg/cache"
+	"calendar-wbf/pkg/logger"
+)
+
+// eventFixture is an event service over an in-memory repository and cache,
+// with a context of tenant acme.
+type eventFixture struct {
+	svc   *service.EventService
+	repo  *repository.EventRepository
+	cache *cache.LoadingCache[string, *entity.Event]
+	ctx   context.Context
+}
+
+func newEventService(t *testing.T) eventFixture {
+	t.Helper()
+
+	items, err := cache.NewLRUCache[string, *cache.Loaded[*entity.Event]](100, logger.NewNop())
+	if err != nil {
+		t.Fatalf("NewLRUCache() error = %v", err)
+	}
+	eventCache, err := cache.NewLoadingCache[string, *entity.Event](items, logger.NewNop())
+	if err != nil {
+		t.Fatalf("NewLoadingCache() error = %v", err)
+	}
+
+	repo := repository.NewEventRepository()
+	return eventFixture{
+		svc:   service.NewEventService(repo, logger.NewNop(), eventCache, time.Minute),
+		repo:  repo,
+		cache: eventCache,
+		ctx:   entity.WithTenant(context.Background(), "acme"),
+	}
+}
+
+func TestEventService_QuickAdd(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc       string
+		input      string
+		wantAllDay bool
+	}{
+		{"AllDay", "Vacation on 2030-07-01", true},
+		{"Timed", "Standup on 2030-07-01 at 9:30", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			f := newEventService(t)
+			svc, ctx := f.svc, f.ctx
+			result, err := svc.QuickAdd(ctx, 1, tc.input, "Europe/Moscow", true)
+			if err != nil {
+				t.Fatalf("QuickAdd(%q) error = %v", tc.input, err)
+			}
+
+			event, err := svc.GetEvent(ctx, result.Event.ID, 1)
+			if err != nil {
+				t.Fatalf("GetEvent() error = %v", err)
+			}
+			if result.AllDay != tc.wantAllDay || event.IsAllDay() != tc.wantAllDay {
+				t.Errorf("AllDay = %v, IsAllDay() = %v; want %v", result.AllDay, event.IsAllDay(), tc.wantAllDay)
+			}
+		})
+	}
+}
+
+func TestEventService_QuickAdd_BadTime(t *testing.T) {
+	t.Parallel()
+
+	f := newEventService(t)
+	if _, err := f.svc.QuickAdd(f.ctx, 1, "Lunch at 25:00", "", true); !errors.Is(err, entity.ErrUnparsableText) {
+		t.Fatalf("QuickAdd() error = %v; want %v", err, entity.ErrUnparsableText)
+	}
+
+	if events, _ := f.repo.GetByUser(f.ctx, 1); len(events) != 0 {
+		t.Errorf("events = %d; want none created", len(events))
+	}
+}
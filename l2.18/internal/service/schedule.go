@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/holiday"
+	"calendar-wbf/pkg/logger"
+)
+
+const (
+	_defaultWorkStart = "09:00"
+	_defaultWorkEnd   = "18:00"
+	_workClockLayout  = "15:04"
+	_hoursPerDay      = 24
+)
+
+type (
+	WorkScheduleRepo interface {
+		Upsert(ctx context.Context, schedule *entity.WorkSchedule) (*entity.WorkSchedule, error)
+		Get(ctx context.Context, userID uint64) (*entity.WorkSchedule, error)
+		Delete(ctx context.Context, userID uint64) error
+	}
+
+	// ScheduleService keeps the working calendars of users. Users without a
+	// saved schedule get the holidays of the default country, if one is set,
+	// and no working hours.
+	ScheduleService struct {
+		repo           WorkScheduleRepo
+		holidays       *holiday.Registry
+		defaultCountry string
+		events         *EventService
+		logger         logger.Logger
+	}
+)
+
+func NewScheduleService(
+	repo WorkScheduleRepo,
+	holidays *holiday.Registry,
+	defaultCountry string,
+	events *EventService,
+	logger logger.Logger,
+) *ScheduleService {
+	return &ScheduleService{
+		repo:           repo,
+		holidays:       holidays,
+		defaultCountry: strings.ToUpper(defaultCountry),
+		events:         events,
+		logger:         logger,
+	}
+}
+
+func (s *ScheduleService) SaveWorkSchedule(
+	ctx context.Context,
+	schedule *entity.WorkSchedule,
+) (*entity.WorkSchedule, error) {
+	const op = "service.SaveWorkSchedule"
+	log := s.logger.Ctx(ctx)
+
+	if err := s.events.validateUserID(schedule.UserID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	applyScheduleDefaults(schedule)
+	if err := s.validateSchedule(schedule); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, _defaultContextTimeout)
+	defer cancel()
+
+	saved, err := s.repo.Upsert(ctx, schedule)
+	if err != nil {
+		return nil, fmt.Errorf("%s: upsert schedule: %w", op, err)
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "work schedule saved",
+		logger.String("op", op),
+		logger.Uint64("user_id", saved.UserID),
+		logger.String("country", saved.Country),
+		logger.String("timezone", saved.Timezone),
+		logger.Int("days_off", len(saved.DaysOff)),
+	)
+
+	return saved, nil
+}
+
+func (s *ScheduleService) GetWorkSchedule(ctx context.Context, userID uint64) (*entity.WorkSchedule, error) {
+	const op = "service.GetWorkSchedule"
+
+	if err := s.events.validateUserID(userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, _defaultContextTimeout)
+	defer cancel()
+
+	schedule, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return schedule, nil
+}
+
+// Holidays lists the public holidays of a country in a year. A year the
+// calendar has no data for fails with ErrHolidaysNotCovered rather than
+// looking holiday-free.
+func (s *ScheduleService) Holidays(country string, year int) ([]holiday.Holiday, error) {
+	const op = "service.Holidays"
+
+	cal, err := s.holidays.Calendar(country)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %w", op, entity.ErrInvalidSchedule, err)
+	}
+	if !cal.Covers(year) {
+		return nil, fmt.Errorf("%s: %s %d: %w", op, cal.Country, year, entity.ErrHolidaysNotCovered)
+	}
+
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return cal.Between(from, from.AddDate(1, 0, -1)), nil
+}
+
+// Overlay returns the read-only entries for the calendar days from through
+// to: public holidays of the user's country and personal days off. They are
+// all-day entries in the timezone of the schedule and are merged into day,
+// week and month views. When the holiday calendar lacks some of the years,
+// the entries found are returned with an ErrHolidaysNotCovered error.
+func (s *ScheduleService) Overlay(
+	ctx context.Context,
+	userID uint64,
+	from, to time.Time,
+) ([]*entity.Event, error) {
+	const op = "service.Overlay"
+
+	schedule, _, err := s.effectiveSchedule(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	loc, cal := s.resolve(schedule)
+
+	var (
+		result      []*entity.Event
+		coverageErr error
+	)
+	if cal != nil {
+		if years := cal.Uncovered(from, to); len(years) > 0 {
+			coverageErr = fmt.Errorf("%s: %s %v: %w", op, cal.Country, years, entity.ErrHolidaysNotCovered)
+		}
+	}
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		day := entity.DayKey(d)
+		start, _ := time.ParseInLocation(time.DateOnly, day, loc)
+
+		if cal != nil {
+			if h, ok := cal.Holiday(start); ok {
+				result = append(result, overlayEntry(schedule, start, entity.EventKindHoliday, h.Name, cal.Name))
+			}
+		}
+		if slices.Contains(schedule.DaysOff, day) {
+			result = append(result, overlayEntry(schedule, start, entity.EventKindDayOff, "Day off", ""))
+		}
+	}
+
+	return result, coverageErr
+}
+
+// CheckWorkingTime lists why an event at start lasting duration falls outside
+// the working calendar of the user: holidays, days off, non-working weekdays
+// and working hours. Only holidays are checked for users without a saved
+// schedule. Years the holiday calendar has no data for are warned about too.
+func (s *ScheduleService) CheckWorkingTime(
+	ctx context.Context,
+	userID uint64,
+	start time.Time,
+	duration time.Duration,
+) ([]string, error) {
+	const op = "service.CheckWorkingTime"
+
+	schedule, saved, err := s.effectiveSchedule(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	loc, cal := s.resolve(schedule)
+	localStart := start.In(loc)
+	localEnd := localStart.Add(duration)
+
+	var warnings []string
+	lastDay := entity.DayKey(localEnd)
+	if duration > 0 && localEnd.Equal(dayStart(localEnd)) {
+		lastDay = entity.DayKey(localEnd.Add(-time.Nanosecond))
+	}
+
+	if cal != nil {
+		last, _ := time.Parse(time.DateOnly, lastDay)
+		for _, year := range cal.Uncovered(localStart, last) {
+			warnings = append(warnings, fmt.Sprintf("no public holiday data for %s in %d", cal.Country, year))
+		}
+	}
+
+	for d := dayStart(localStart); entity.DayKey(d) <= lastDay; d = d.AddDate(0, 0, 1) {
+		day := entity.DayKey(d)
+
+		var isHoliday, transferred bool
+		if cal != nil {
+			h, ok := cal.Holiday(d)
+			if ok {
+				warnings = append(warnings, fmt.Sprintf("%s is a public holiday: %s", day, h.Name))
+			}
+			isHoliday, transferred = ok, cal.IsTransferredWorkingDay(d)
+		}
+
+		if slices.Contains(schedule.DaysOff, day) {
+			warnings = append(warnings, day+" is a day off")
+			continue
+		}
+		if saved && !isHoliday && !transferred && !slices.Contains(schedule.WorkDays, d.Weekday()) {
+			warnings = append(warnings, fmt.Sprintf("%s is a non-working day (%s)", day, d.Weekday()))
+		}
+	}
+
+	if saved && !isAllDaySpan(localStart, duration) && !withinHours(schedule, localStart, localEnd) {
+		warnings = append(warnings, fmt.Sprintf("outside working hours %s-%s %s",
+			schedule.WorkStart, schedule.WorkEnd, schedule.Timezone))
+	}
+
+	return warnings, nil
+}
+
+func (s *ScheduleService) effectiveSchedule(
+	ctx context.Context,
+	userID uint64,
+) (*entity.WorkSchedule, bool, error) {
+	if err := s.events.validateUserID(userID); err != nil {
+		return nil, false, err
+	}
+
+	schedule, err := s.repo.Get(ctx, userID)
+	switch {
+	case err == nil:
+		return schedule, true, nil
+	case errors.Is(err, entity.ErrScheduleNotFound):
+		fallback := &entity.WorkSchedule{
+			TenantID: entity.TenantFromContext(ctx),
+			UserID:   userID,
+			Country:  s.defaultCountry,
+		}
+		applyScheduleDefaults(fallback)
+		return fallback, false, nil
+	default:
+		return nil, false, err
+	}
+}
+
+// resolve returns the location and holiday calendar of a validated
+// schedule. Calendars removed from HOLIDAY_DIR since the schedule was saved
+// are skipped.
+func (s *ScheduleService) resolve(schedule *entity.WorkSchedule) (*time.Location, *holiday.Calendar) {
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	if schedule.Country == "" {
+		return loc, nil
+	}
+	cal, err := s.holidays.Calendar(schedule.Country)
+	if err != nil {
+		return loc, nil
+	}
+	return loc, cal
+}
+
+func (s *ScheduleService) validateSchedule(schedule *entity.WorkSchedule) error {
+	if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+		return entity.ErrInvalidTimezone
+	}
+	if schedule.Country != "" {
+		if _, err := s.holidays.Calendar(schedule.Country); err != nil {
+			return fmt.Errorf("%w: %w", entity.ErrInvalidSchedule, err)
+		}
+	}
+
+	workStart, err := time.Parse(_workClockLayout, schedule.WorkStart)
+	if err != nil {
+		return entity.ErrInvalidSchedule
+	}
+	workEnd, err := time.Parse(_workClockLayout, schedule.WorkEnd)
+	if err != nil || !workEnd.After(workStart) {
+		return entity.ErrInvalidSchedule
+	}
+
+	// Working hours are compared as strings, so store them zero-padded.
+	schedule.WorkStart, schedule.WorkEnd = workStart.Format(_workClockLayout), workEnd.Format(_workClockLayout)
+
+	for _, day := range schedule.WorkDays {
+		if day < time.Sunday || day > time.Saturday {
+			return entity.ErrInvalidSchedule
+		}
+	}
+	for _, day := range schedule.DaysOff {
+		if _, err = time.Parse(time.DateOnly, day); err != nil {
+			return entity.ErrInvalidSchedule
+		}
+	}
+	return nil
+}
+
+func applyScheduleDefaults(schedule *entity.WorkSchedule) {
+	schedule.Country = strings.ToUpper(schedule.Country)
+	if schedule.Timezone == "" {
+		schedule.Timezone = time.UTC.String()
+	}
+	if schedule.WorkStart == "" {
+		schedule.WorkStart = _defaultWorkStart
+	}
+	if schedule.WorkEnd == "" {
+		schedule.WorkEnd = _defaultWorkEnd
+	}
+	if schedule.WorkDays == nil {
+		schedule.WorkDays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	}
+
+	slices.Sort(schedule.WorkDays)
+	schedule.WorkDays = slices.Compact(schedule.WorkDays)
+	slices.Sort(schedule.DaysOff)
+	schedule.DaysOff = slices.Compact(schedule.DaysOff)
+}
+
+func overlayEntry(
+	schedule *entity.WorkSchedule,
+	start time.Time,
+	kind entity.EventKind,
+	title, text string,
+) *entity.Event {
+	return &entity.Event{
+		TenantID: schedule.TenantID,
+		UserID:   schedule.UserID,
+		Date:     start,
+		Duration: _hoursPerDay * time.Hour,
+		Title:    title,
+		Text:     text,
+		Kind:     kind,
+		ReadOnly: true,
+	}
+}
+
+func dayStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// isAllDaySpan reports whether the event covers whole days, which working
+// hours do not apply to.
+func isAllDaySpan(start time.Time, duration time.Duration) bool {
+	return start.Equal(dayStart(start)) && duration%(_hoursPerDay*time.Hour) == 0
+}
+
+func withinHours(schedule *entity.WorkSchedule, start, end time.Time) bool {
+	if entity.DayKey(start) != entity.DayKey(end) && !end.Equal(dayStart(end)) {
+		return false
+	}
+
+	clock := func(t time.Time) string { return t.Format(_workClockLayout) }
+	endClock := clock(end)
+	if end.Equal(dayStart(end)) && end.After(start) {
+		endClock = "24:00"
+	}
+	return clock(start) >= schedule.WorkStart && endClock <= schedule.WorkEnd
+}
@@ -0,0 +1,168 @@
+package service_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/internal/repository"
+	"calendar-wbf/internal/service"
+	"calendar-wbf/pkg/holiday"
+	"calendar-wbf/pkg/logger"
+)
+
+// newScheduleService returns a service with the bundled holiday calendars
+// and RU as the default country. User 1 has a Moscow schedule, 09:00-18:00
+// on weekdays with 2026-05-08 off; user 2 has none.
+func newScheduleService(t *testing.T) (*service.ScheduleService, eventFixture) {
+	t.Helper()
+
+	holidays, err := holiday.Load("")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	f := newEventService(t)
+	svc := service.NewScheduleService(repository.NewWorkScheduleRepository(), holidays, "ru", f.svc, logger.NewNop())
+	_, err = svc.SaveWorkSchedule(f.ctx, &entity.WorkSchedule{
+		UserID: 1, Country: "RU", Timezone: "Europe/Moscow", DaysOff: []string{"2026-05-08"},
+	})
+	if err != nil {
+		t.Fatalf("SaveWorkSchedule() error = %v", err)
+	}
+	return svc, f
+}
+
+func TestScheduleService_Overlay(t *testing.T) {
+	t.Parallel()
+
+	msk, _ := time.LoadLocation("Europe/Moscow")
+	day := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		desc     string
+		userID   uint64
+		from, to time.Time
+		want     []string
+		wantErr  error
+	}{
+		{
+			desc: "SavedSchedule", userID: 1,
+			from: day(2026, time.May, 7), to: day(2026, time.May, 11),
+			want: []string{"2026-05-08 day_off Day off", "2026-05-09 holiday День Победы",
+				"2026-05-11 holiday Выходной (перенос с 9 мая)"},
+		},
+		{
+			desc: "DefaultCountry", userID: 2,
+			from: day(2026, time.May, 7), to: day(2026, time.May, 9),
+			want: []string{"2026-05-09 holiday День Победы"},
+		},
+		{
+			desc: "YearsNotCovered", userID: 1,
+			from: day(2027, time.December, 30), to: day(2028, time.January, 2),
+			wantErr: entity.ErrHolidaysNotCovered,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			svc, f := newScheduleService(t)
+			entries, err := svc.Overlay(f.ctx, tc.userID, tc.from, tc.to)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Overlay() error = %v; want %v", err, tc.wantErr)
+			}
+
+			var got []string
+			for _, entry := range entries {
+				got = append(got, entity.DayKey(entry.Date)+" "+string(entry.Kind)+" "+entry.Title)
+				if !entry.IsAllDay() || !entry.ReadOnly {
+					t.Errorf("entry %+v is not a read-only all-day entry", entry)
+				}
+				if tc.userID == 1 && entry.Date.Location().String() != msk.String() {
+					t.Errorf("entry date %s not in the schedule timezone", entry.Date)
+				}
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("Overlay() = %q; want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestScheduleService_CheckWorkingTime(t *testing.T) {
+	t.Parallel()
+
+	msk, _ := time.LoadLocation("Europe/Moscow")
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, msk)
+	}
+	const outside = "outside working hours 09:00-18:00 Europe/Moscow"
+
+	testCases := []struct {
+		desc     string
+		userID   uint64
+		start    time.Time
+		duration time.Duration
+		want     []string
+	}{
+		{"WithinHours", 1, at(time.October, 20, 10, 0), time.Hour, nil},
+		{"EndsAtClosing", 1, at(time.October, 20, 17, 0), time.Hour, nil},
+		{"BeforeOpening", 1, at(time.October, 20, 8, 30), time.Hour, []string{outside}},
+		{"PastClosing", 1, at(time.October, 20, 17, 30), time.Hour, []string{outside}},
+		{"AcrossMidnight", 1, at(time.October, 20, 23, 0), 2 * time.Hour, []string{outside}},
+		{"Weekend", 1, at(time.October, 24, 10, 0), time.Hour, []string{"2026-10-24 is a non-working day (Saturday)"}},
+		{"DayOff", 1, at(time.May, 8, 10, 0), time.Hour, []string{"2026-05-08 is a day off"}},
+		{
+			"HolidayInUserTimezone", 1, time.Date(2026, time.November, 3, 21, 30, 0, 0, time.UTC), time.Hour,
+			[]string{"2026-11-04 is a public holiday: День народного единства", outside},
+		},
+		{
+			"AllDayWeekendEndsAtMidnight", 1, at(time.October, 24, 0, 0), 48 * time.Hour,
+			[]string{"2026-10-24 is a non-working day (Saturday)", "2026-10-25 is a non-working day (Sunday)"},
+		},
+		{"NoScheduleOnlyHolidays", 2, at(time.October, 24, 7, 0), time.Hour, nil},
+		{
+			"YearNotCovered", 1, time.Date(2028, time.March, 1, 10, 0, 0, 0, msk), time.Hour,
+			[]string{"no public holiday data for RU in 2028"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			svc, f := newScheduleService(t)
+			got, err := svc.CheckWorkingTime(f.ctx, tc.userID, tc.start, tc.duration)
+			if err != nil {
+				t.Fatalf("CheckWorkingTime() error = %v", err)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("CheckWorkingTime() = %q; want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestScheduleService_Holidays(t *testing.T) {
+	t.Parallel()
+
+	svc, _ := newScheduleService(t)
+
+	for _, year := range []int{2026, 2027} {
+		if holidays, err := svc.Holidays("us", year); err != nil || len(holidays) == 0 {
+			t.Errorf("Holidays(us, %d) = %d holidays, error %v", year, len(holidays), err)
+		}
+	}
+	if _, err := svc.Holidays("us", 2028); !errors.Is(err, entity.ErrHolidaysNotCovered) {
+		t.Errorf("Holidays(us, 2028) error = %v; want %v", err, entity.ErrHolidaysNotCovered)
+	}
+	if _, err := svc.Holidays("xx", 2026); !errors.Is(err, entity.ErrInvalidSchedule) {
+		t.Errorf("Holidays(xx, 2026) error = %v; want %v", err, entity.ErrInvalidSchedule)
+	}
+}
@@ -3,91 +3,183 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"calendar-wbf/internal/entity"
 	"calendar-wbf/pkg/cache"
 	"calendar-wbf/pkg/logger"
+	"calendar-wbf/pkg/markdown"
+	"calendar-wbf/pkg/quickadd"
 )
 
 const (
 	_defaultContextTimeout = 500 * time.Millisecond
+
+	_maxEventTags       = 10
+	_maxEventDuration   = 7 * 24 * time.Hour
+	_maxEventTextLength = 10000
+	_maxRangeDays       = 366
 )
 
 type (
+	// EventRepo stores events. Changes take the domain events they raise,
+	// which are stored along with the change when the repository keeps an
+	// outbox.
 	EventRepo interface {
-		Create(ctx context.Context, event *entity.Event) (*entity.Event, error)
+		Create(ctx context.Context, event *entity.Event, raise ...entity.DomainEventType) (*entity.Event, error)
 		GetByID(ctx context.Context, id uint64) (*entity.Event, error)
-		Update(ctx context.Context, event *entity.Event) (*entity.Event, error)
-		Delete(ctx context.Context, id uint64) error
-		GetByUserAndDate(ctx context.Context, userID uint64, date time.Time) ([]*entity.Event, error)
-		GetByUserAndDateRange(ctx context.Context, userID uint64, startDate, endDate time.Time) ([]*entity.Event, error)
+		UpdateIf(
+			ctx context.Context,
+			event *entity.Event,
+			match entity.EventMatch,
+			raise ...entity.DomainEventType,
+		) (*entity.Event, error)
+		DeleteIf(ctx context.Context, id uint64, match entity.EventMatch, raise ...entity.DomainEventType) error
+		GetByUserAndDate(
+			ctx context.Context,
+			userID uint64,
+			date time.Time,
+			filter entity.TagFilter,
+		) ([]*entity.Event, error)
+		GetByUserAndDateRange(
+			ctx context.Context,
+			userID uint64,
+			startDate, endDate time.Time,
+			filter entity.TagFilter,
+		) ([]*entity.Event, error)
+		GetByUser(ctx context.Context, userID uint64) ([]*entity.Event, error)
+		RemoveTag(
+			ctx context.Context,
+			userID uint64,
+			tag string,
+			raise ...entity.DomainEventType,
+		) ([]*entity.Event, error)
+		CountByTenant(ctx context.Context) (int, error)
+		Ping(ctx context.Context) error
+	}
+
+	// TenantQuotas caps how many events a tenant may keep. PerTenant
+	// overrides Default; zero means unlimited.
+	TenantQuotas struct {
+		Default   int
+		PerTenant map[string]int
 	}
 
 	EventService struct {
 		eventRepo EventRepo
 		logger    logger.Logger
-		cache     cache.Cache[uint64, *entity.Event]
-		cacheTTL  time.Duration
+		cache     *cache.LoadingCache[string, *entity.Event]
+		cacheTTL  atomic.Int64
+		parser    *quickadd.Parser
+		quotas    atomic.Pointer[TenantQuotas]
+		// createMu serializes creation while quotas are enforced, so
+		// concurrent requests cannot both pass the count check.
+		createMu  sync.Mutex
+		onDeleted []func(ctx context.Context, eventID uint64)
 	}
 )
 
+func (q *TenantQuotas) Limit(tenantID string) int {
+	if limit, ok := q.PerTenant[tenantID]; ok {
+		return limit
+	}
+	return q.Default
+}
+
 func NewEventService(
 	eventRepo EventRepo,
 	logger logger.Logger,
-	cache cache.Cache[uint64, *entity.Event],
+	eventCache *cache.LoadingCache[string, *entity.Event],
 	cacheTTL time.Duration,
 ) *EventService {
-	cache.SetOnEvicted(func(key uint64, value *entity.Event) {
+	eventCache.SetOnEvicted(func(key string, value *entity.Event, reason cache.EvictionReason) {
 		logger.Infow("cache eviction",
 			"key", key,
 			"event_id", value.ID,
+			"reason", reason.String(),
 		)
 	})
 
-	return &EventService{
+	svc := &EventService{
 		eventRepo: eventRepo,
 		logger:    logger,
-		cache:     cache,
-		cacheTTL:  cacheTTL,
+		cache:     eventCache,
+		parser:    quickadd.NewParser(),
 	}
+	svc.SetCacheTTL(cacheTTL)
+	svc.SetQuotas(TenantQuotas{})
+
+	return svc
+}
+
+// OnEventDeleted registers fn to run after an event is deleted, so data kept
+// alongside events is removed whichever transport deleted it. Hooks are
+// registered while the application is wired, before serving.
+func (s *EventService) OnEventDeleted(fn func(ctx context.Context, eventID uint64)) {
+	s.onDeleted = append(s.onDeleted, fn)
+}
+
+func (s *EventService) SetQuotas(quotas TenantQuotas) {
+	s.quotas.Store(&quotas)
+}
+
+func (s *EventService) SetCacheTTL(ttl time.Duration) {
+	s.cacheTTL.Store(int64(ttl))
+	s.cache.SetTTL(ttl)
+}
+
+func (s *EventService) CacheTTL() time.Duration {
+	return time.Duration(s.cacheTTL.Load())
 }
 
 func (s *EventService) CreateEvent(
 	ctx context.Context,
 	userID uint64,
 	date time.Time,
+	duration time.Duration,
 	title, text string,
+	tags []string,
+	color string,
 ) (*entity.Event, error) {
+	return s.createEvent(ctx, &entity.Event{
+		UserID:   userID,
+		Date:     date,
+		Duration: duration,
+		Title:    title,
+		Text:     text,
+		Tags:     entity.NormalizeTags(tags),
+		Color:    strings.ToLower(color),
+	})
+}
+
+func (s *EventService) createEvent(ctx context.Context, event *entity.Event) (*entity.Event, error) {
 	const op = "service.CreateEvent"
 	log := s.logger.Ctx(ctx)
+	userID := event.UserID
 
 	log.LogAttrs(ctx, logger.InfoLevel, "create event started",
 		logger.String("op", op),
 		logger.Uint64("user_id", userID),
-		logger.String("date", date.String()),
-		logger.String("title", title),
+		logger.String("date", event.Date.String()),
+		logger.String("title", event.Title),
 	)
 
 	startTime := time.Now()
 	defer func() {
-		duration := time.Since(startTime)
-		if duration > 200*time.Millisecond {
+		elapsed := time.Since(startTime)
+		if elapsed > 200*time.Millisecond {
 			log.LogAttrs(ctx, logger.WarnLevel, "slow service operation",
 				logger.String("op", op),
 				logger.Uint64("user_id", userID),
-				logger.String("duration", duration.String()),
+				logger.String("duration", elapsed.String()),
 			)
 		}
 	}()
 
-	event := &entity.Event{
-		UserID: userID,
-		Date:   date,
-		Title:  title,
-		Text:   text,
-	}
-
 	if err := s.validateEvent(event); err != nil {
 		log.LogAttrs(ctx, logger.ErrorLevel, "event validation failed",
 			logger.String("op", op),
@@ -96,11 +188,12 @@ func (s *EventService) CreateEvent(
 		)
 		return nil, fmt.Errorf("%s: validate event: %w", op, err)
 	}
+	event.TextHTML = markdown.Render(event.Text)
 
 	ctx, cancel := context.WithTimeout(ctx, _defaultContextTimeout)
 	defer cancel()
 
-	createdEvent, err := s.eventRepo.Create(ctx, event)
+	createdEvent, err := s.create(ctx, event)
 	if err != nil {
 		log.LogAttrs(ctx, logger.ErrorLevel, "event creation failed",
 			logger.String("op", op),
@@ -110,26 +203,71 @@ func (s *EventService) CreateEvent(
 		return nil, fmt.Errorf("%s: create event: %w", op, err)
 	}
 
-	s.cache.Put(createdEvent.ID, createdEvent, s.cacheTTL)
+	s.cache.Put(eventCacheKey(createdEvent), createdEvent, s.CacheTTL())
 
-	duration := time.Since(startTime)
+	elapsed := time.Since(startTime)
 	log.LogAttrs(ctx, logger.InfoLevel, "event created successfully",
 		logger.String("op", op),
 		logger.Uint64("event_id", createdEvent.ID),
-		logger.String("duration", duration.String()),
+		logger.String("duration", elapsed.String()),
 	)
 
 	return createdEvent, nil
 }
 
+// create stores the event in the tenant of the context, checking the quota
+// of the tenant first when one is set.
+func (s *EventService) create(ctx context.Context, event *entity.Event) (*entity.Event, error) {
+	limit := s.quotas.Load().Limit(entity.TenantFromContext(ctx))
+	if limit <= 0 {
+		return s.eventRepo.Create(ctx, event, entity.EventCreated)
+	}
+
+	s.createMu.Lock()
+	defer s.createMu.Unlock()
+
+	count, err := s.eventRepo.CountByTenant(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("count events: %w", err)
+	}
+	if count >= limit {
+		return nil, fmt.Errorf("%d of %d events: %w", count, limit, entity.ErrQuotaExceeded)
+	}
+
+	return s.eventRepo.Create(ctx, event, entity.EventCreated)
+}
+
 func (s *EventService) UpdateEvent(
 	ctx context.Context,
 	id, userID uint64,
 	date time.Time,
+	duration time.Duration,
 	title, text string,
+	tags []string,
+	color string,
+) (*entity.Event, error) {
+	return s.updateEvent(ctx, &entity.Event{
+		ID:       id,
+		UserID:   userID,
+		Date:     date,
+		Duration: duration,
+		Title:    title,
+		Text:     text,
+		Tags:     entity.NormalizeTags(tags),
+		Color:    strings.ToLower(color),
+	}, nil)
+}
+
+// updateEvent replaces an event of its owner. An empty UID keeps the one
+// assigned by a calendar client, and nil Attendees keep the stored ones.
+func (s *EventService) updateEvent(
+	ctx context.Context,
+	event *entity.Event,
+	match entity.EventMatch,
 ) (*entity.Event, error) {
 	const op = "service.UpdateEvent"
 	log := s.logger.Ctx(ctx)
+	id, userID := event.ID, event.UserID
 
 	log.LogAttrs(ctx, logger.InfoLevel, "update event started",
 		logger.String("op", op),
@@ -142,7 +280,7 @@ func (s *EventService) UpdateEvent(
 	ctx, cancel := context.WithTimeout(ctx, _defaultContextTimeout)
 	defer cancel()
 
-	existing, err := s.eventRepo.GetByID(ctx, id)
+	existing, err := s.getEvent(ctx, id)
 	if err != nil {
 		log.LogAttrs(ctx, logger.ErrorLevel, "failed to get existing event",
 			logger.String("op", op),
@@ -162,12 +300,11 @@ func (s *EventService) UpdateEvent(
 		return nil, fmt.Errorf("%s: %w", op, entity.ErrEventNotFound)
 	}
 
-	event := &entity.Event{
-		ID:     id,
-		UserID: userID,
-		Date:   date,
-		Title:  title,
-		Text:   text,
+	if event.UID == "" {
+		event.UID = existing.UID
+	}
+	if event.Attendees == nil {
+		event.Attendees = existing.Attendees
 	}
 
 	if validateErr := s.validateEvent(event); validateErr != nil {
@@ -178,8 +315,9 @@ func (s *EventService) UpdateEvent(
 		)
 		return nil, fmt.Errorf("%s: validate event: %w", op, validateErr)
 	}
+	event.TextHTML = markdown.Render(event.Text)
 
-	updatedEvent, err := s.eventRepo.Update(ctx, event)
+	updatedEvent, err := s.eventRepo.UpdateIf(ctx, event, match, entity.EventUpdated)
 	if err != nil {
 		log.LogAttrs(ctx, logger.ErrorLevel, "event update failed",
 			logger.String("op", op),
@@ -189,19 +327,26 @@ func (s *EventService) UpdateEvent(
 		return nil, fmt.Errorf("%s: update event: %w", op, err)
 	}
 
-	s.cache.Put(updatedEvent.ID, updatedEvent, s.cacheTTL)
+	s.cache.Put(eventCacheKey(updatedEvent), updatedEvent, s.CacheTTL())
 
-	duration := time.Since(startTime)
+	elapsed := time.Since(startTime)
 	log.LogAttrs(ctx, logger.InfoLevel, "event updated successfully",
 		logger.String("op", op),
 		logger.Uint64("event_id", id),
-		logger.String("duration", duration.String()),
+		logger.String("duration", elapsed.String()),
 	)
 
 	return updatedEvent, nil
 }
 
 func (s *EventService) DeleteEvent(ctx context.Context, id, userID uint64) error {
+	return s.DeleteEventIf(ctx, id, userID, nil)
+}
+
+// DeleteEventIf deletes the event if match accepts the stored version at the
+// time of the delete, see EventMatch. CalDAV DELETE requests with If-Match
+// use it.
+func (s *EventService) DeleteEventIf(ctx context.Context, id, userID uint64, match entity.EventMatch) error {
 	const op = "service.DeleteEvent"
 	log := s.logger.Ctx(ctx)
 
@@ -214,7 +359,7 @@ func (s *EventService) DeleteEvent(ctx context.Context, id, userID uint64) error
 	ctx, cancel := context.WithTimeout(ctx, _defaultContextTimeout)
 	defer cancel()
 
-	existing, err := s.eventRepo.GetByID(ctx, id)
+	existing, err := s.getEvent(ctx, id)
 	if err != nil {
 		log.LogAttrs(ctx, logger.ErrorLevel, "failed to get existing event",
 			logger.String("op", op),
@@ -234,7 +379,7 @@ func (s *EventService) DeleteEvent(ctx context.Context, id, userID uint64) error
 		return fmt.Errorf("%s: %w", op, entity.ErrEventNotFound)
 	}
 
-	if repoErr := s.eventRepo.Delete(ctx, id); repoErr != nil {
+	if repoErr := s.eventRepo.DeleteIf(ctx, id, match, entity.EventDeleted); repoErr != nil {
 		log.LogAttrs(ctx, logger.ErrorLevel, "event deletion failed",
 			logger.String("op", op),
 			logger.Any("error", repoErr),
@@ -243,6 +388,11 @@ func (s *EventService) DeleteEvent(ctx context.Context, id, userID uint64) error
 		return fmt.Errorf("%s: delete event: %w", op, repoErr)
 	}
 
+	s.cache.Delete(eventCacheKey(existing))
+	for _, fn := range s.onDeleted {
+		fn(ctx, id)
+	}
+
 	log.LogAttrs(ctx, logger.InfoLevel, "event deleted successfully",
 		logger.String("op", op),
 		logger.Uint64("event_id", id),
@@ -251,7 +401,102 @@ func (s *EventService) DeleteEvent(ctx context.Context, id, userID uint64) error
 	return nil
 }
 
-func (s *EventService) GetEventsForDay(ctx context.Context, userID uint64, date time.Time) ([]*entity.Event, error) {
+// PutEvent creates the event when it has no ID and replaces it otherwise,
+// keeping the UID set by the caller. A replacement only happens if match
+// accepts the stored version at the time of the write. CalDAV PUT requests
+// use it.
+func (s *EventService) PutEvent(
+	ctx context.Context,
+	event *entity.Event,
+	match entity.EventMatch,
+) (*entity.Event, error) {
+	event.Tags = entity.NormalizeTags(event.Tags)
+	event.Color = strings.ToLower(event.Color)
+	if event.Attendees == nil {
+		// The calendar object is the whole event: one without attendees
+		// removes them.
+		event.Attendees = []entity.Attendee{}
+	}
+
+	if event.ID == 0 {
+		return s.createEvent(ctx, event)
+	}
+	return s.updateEvent(ctx, event, match)
+}
+
+// ListEvents returns every event of the user ordered by date.
+func (s *EventService) ListEvents(ctx context.Context, userID uint64) ([]*entity.Event, error) {
+	const op = "service.ListEvents"
+
+	if err := s.validateUserID(userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, _defaultContextTimeout)
+	defer cancel()
+
+	events, err := s.eventRepo.GetByUser(ctx, userID)
+	if err != nil {
+		s.logger.Ctx(ctx).LogAttrs(ctx, logger.ErrorLevel, "failed to list events",
+			logger.String("op", op),
+			logger.Any("error", err),
+			logger.Uint64("user_id", userID),
+		)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return events, nil
+}
+
+func (s *EventService) GetEvent(ctx context.Context, id, userID uint64) (*entity.Event, error) {
+	const op = "service.GetEvent"
+	log := s.logger.Ctx(ctx)
+
+	if err := s.validateUserID(userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, _defaultContextTimeout)
+	defer cancel()
+
+	event, err := s.getEvent(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if event.UserID != userID {
+		log.LogAttrs(ctx, logger.WarnLevel, "unauthorized read attempt",
+			logger.String("op", op),
+			logger.Uint64("event_id", id),
+			logger.Uint64("owner_id", event.UserID),
+			logger.Uint64("requested_by", userID),
+		)
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrEventNotFound)
+	}
+
+	return event, nil
+}
+
+// getEvent reads an event of the tenant of the context through the cache:
+// concurrent misses share one repository lookup and unknown IDs are
+// remembered for CACHE_NEGATIVE_TTL.
+func (s *EventService) getEvent(ctx context.Context, id uint64) (*entity.Event, error) {
+	key := entity.EventCacheKey(entity.TenantFromContext(ctx), id)
+	return s.cache.GetOrLoad(ctx, key, func(ctx context.Context, _ string) (*entity.Event, error) {
+		return s.eventRepo.GetByID(ctx, id)
+	})
+}
+
+func eventCacheKey(event *entity.Event) string {
+	return entity.EventCacheKey(event.TenantID, event.ID)
+}
+
+func (s *EventService) GetEventsForDay(
+	ctx context.Context,
+	userID uint64,
+	date time.Time,
+	filter entity.TagFilter,
+) ([]*entity.Event, error) {
 	const op = "service.GetEventsForDay"
 	log := s.logger.Ctx(ctx)
 
@@ -268,7 +513,7 @@ func (s *EventService) GetEventsForDay(ctx context.Context, userID uint64, date
 	ctx, cancel := context.WithTimeout(ctx, _defaultContextTimeout)
 	defer cancel()
 
-	events, err := s.eventRepo.GetByUserAndDate(ctx, userID, date)
+	events, err := s.eventRepo.GetByUserAndDate(ctx, userID, date, normalizeFilter(filter))
 	if err != nil {
 		log.LogAttrs(ctx, logger.ErrorLevel, "failed to get events",
 			logger.String("op", op),
@@ -291,6 +536,7 @@ func (s *EventService) GetEventsForWeek(
 	ctx context.Context,
 	userID uint64,
 	startDate time.Time,
+	filter entity.TagFilter,
 ) ([]*entity.Event, error) {
 	const op = "service.GetEventsForWeek"
 	log := s.logger.Ctx(ctx)
@@ -311,7 +557,7 @@ func (s *EventService) GetEventsForWeek(
 	ctx, cancel := context.WithTimeout(ctx, _defaultContextTimeout)
 	defer cancel()
 
-	events, err := s.eventRepo.GetByUserAndDateRange(ctx, userID, startDate, endDate)
+	events, err := s.eventRepo.GetByUserAndDateRange(ctx, userID, startDate, endDate, normalizeFilter(filter))
 	if err != nil {
 		log.LogAttrs(ctx, logger.ErrorLevel, "failed to get events",
 			logger.String("op", op),
@@ -330,7 +576,12 @@ func (s *EventService) GetEventsForWeek(
 	return events, nil
 }
 
-func (s *EventService) GetEventsForMonth(ctx context.Context, userID uint64, year, month int) ([]*entity.Event, error) {
+func (s *EventService) GetEventsForMonth(
+	ctx context.Context,
+	userID uint64,
+	year, month int,
+	filter entity.TagFilter,
+) ([]*entity.Event, error) {
 	const op = "service.GetEventsForMonth"
 	log := s.logger.Ctx(ctx)
 
@@ -359,7 +610,7 @@ func (s *EventService) GetEventsForMonth(ctx context.Context, userID uint64, yea
 	ctx, cancel := context.WithTimeout(ctx, _defaultContextTimeout)
 	defer cancel()
 
-	events, err := s.eventRepo.GetByUserAndDateRange(ctx, userID, startDate, endDate)
+	events, err := s.eventRepo.GetByUserAndDateRange(ctx, userID, startDate, endDate, normalizeFilter(filter))
 	if err != nil {
 		log.LogAttrs(ctx, logger.ErrorLevel, "failed to get events",
 			logger.String("op", op),
@@ -378,19 +629,88 @@ func (s *EventService) GetEventsForMonth(ctx context.Context, userID uint64, yea
 	return events, nil
 }
 
+// GetEventsForRange returns the events between from and to, both days
+// included. The range may span at most a year.
+func (s *EventService) GetEventsForRange(
+	ctx context.Context,
+	userID uint64,
+	from, to time.Time,
+	filter entity.TagFilter,
+) ([]*entity.Event, error) {
+	const op = "service.GetEventsForRange"
+	log := s.logger.Ctx(ctx)
+
+	log.LogAttrs(ctx, logger.InfoLevel, "get events for range requested",
+		logger.String("op", op),
+		logger.Uint64("user_id", userID),
+		logger.String("from", from.String()),
+		logger.String("to", to.String()),
+	)
+
+	if err := s.validateUserID(userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if from.IsZero() || to.Before(from) || to.After(from.AddDate(0, 0, _maxRangeDays)) {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrInvalidDate)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, _defaultContextTimeout)
+	defer cancel()
+
+	events, err := s.eventRepo.GetByUserAndDateRange(ctx, userID, from, to, normalizeFilter(filter))
+	if err != nil {
+		log.LogAttrs(ctx, logger.ErrorLevel, "failed to get events",
+			logger.String("op", op),
+			logger.Any("error", err),
+			logger.Uint64("user_id", userID),
+		)
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "events retrieved successfully",
+		logger.String("op", op),
+		logger.Uint64("user_id", userID),
+		logger.Int("count", len(events)),
+	)
+
+	return events, nil
+}
+
+func (s *EventService) Ping(ctx context.Context) error {
+	if err := s.eventRepo.Ping(ctx); err != nil {
+		return fmt.Errorf("service.Ping: repository unreachable: %w", err)
+	}
+	return nil
+}
+
 func (s *EventService) validateEvent(event *entity.Event) error {
-	if event.ID == 0 {
-		return entity.ErrInvalidDate
+	if event.UserID == 0 {
+		return entity.ErrInvalidUserID
 	}
 	if event.Date.IsZero() {
 		return entity.ErrInvalidDate
 	}
+	if event.Duration < 0 || event.Duration > _maxEventDuration {
+		return entity.ErrInvalidData
+	}
 	if event.Title == "" {
 		return entity.ErrInvalidData
 	}
-	if event.Text == "" {
+	if event.Text == "" || utf8.RuneCountInString(event.Text) > _maxEventTextLength {
 		return entity.ErrInvalidData
 	}
+	if len(event.Tags) > _maxEventTags {
+		return entity.ErrInvalidTag
+	}
+	for _, tag := range event.Tags {
+		if err := validateTagName(tag); err != nil {
+			return err
+		}
+	}
+	if event.Color != "" && !isHexColor(event.Color) {
+		return entity.ErrInvalidColor
+	}
 	return nil
 }
 
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/cache"
+	"calendar-wbf/pkg/logger"
+)
+
+const (
+	_maxTagLength = 30
+	_hexColorLen  = 7
+)
+
+type (
+	TagRepo interface {
+		Upsert(ctx context.Context, tag *entity.Tag) (*entity.Tag, error)
+		ListByUser(ctx context.Context, userID uint64) ([]*entity.Tag, error)
+		Delete(ctx context.Context, userID uint64, name string) error
+	}
+
+	TagService struct {
+		tagRepo   TagRepo
+		eventRepo EventRepo
+		events    *EventService
+		logger    logger.Logger
+		cache     cache.Cache[string, *entity.Event]
+	}
+)
+
+func NewTagService(
+	tagRepo TagRepo,
+	eventRepo EventRepo,
+	events *EventService,
+	logger logger.Logger,
+	cache cache.Cache[string, *entity.Event],
+) *TagService {
+	return &TagService{
+		tagRepo:   tagRepo,
+		eventRepo: eventRepo,
+		events:    events,
+		logger:    logger,
+		cache:     cache,
+	}
+}
+
+func (s *TagService) SaveTag(ctx context.Context, userID uint64, name, color string) (*entity.Tag, error) {
+	const op = "service.SaveTag"
+	log := s.logger.Ctx(ctx)
+
+	if err := s.events.validateUserID(userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tag := &entity.Tag{
+		UserID: userID,
+		Name:   entity.NormalizeTag(name),
+		Color:  strings.ToLower(color),
+	}
+
+	if err := validateTagName(tag.Name); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.Color != "" && !isHexColor(tag.Color) {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrInvalidColor)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, _defaultContextTimeout)
+	defer cancel()
+
+	saved, err := s.tagRepo.Upsert(ctx, tag)
+	if err != nil {
+		log.LogAttrs(ctx, logger.ErrorLevel, "tag save failed",
+			logger.String("op", op),
+			logger.Any("error", err),
+			logger.Uint64("user_id", userID),
+		)
+		return nil, fmt.Errorf("%s: upsert tag: %w", op, err)
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "tag saved",
+		logger.String("op", op),
+		logger.Uint64("user_id", userID),
+		logger.String("tag", saved.Name),
+	)
+
+	return saved, nil
+}
+
+func (s *TagService) ListTags(ctx context.Context, userID uint64) ([]*entity.Tag, error) {
+	const op = "service.ListTags"
+
+	if err := s.events.validateUserID(userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, _defaultContextTimeout)
+	defer cancel()
+
+	tags, err := s.tagRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tags, nil
+}
+
+// DeleteTag removes the tag definition and strips the tag from every event
+// of the user, refreshing cached copies of the affected events. Tags used on
+// events without a definition are stripped too; ErrTagNotFound means the
+// user had neither.
+func (s *TagService) DeleteTag(ctx context.Context, userID uint64, name string) error {
+	const op = "service.DeleteTag"
+	log := s.logger.Ctx(ctx)
+
+	if err := s.events.validateUserID(userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	name = entity.NormalizeTag(name)
+
+	ctx, cancel := context.WithTimeout(ctx, _defaultContextTimeout)
+	defer cancel()
+
+	defined := true
+	if err := s.tagRepo.Delete(ctx, userID, name); errors.Is(err, entity.ErrTagNotFound) {
+		defined = false
+	} else if err != nil {
+		log.LogAttrs(ctx, logger.WarnLevel, "tag delete failed",
+			logger.String("op", op),
+			logger.Any("error", err),
+			logger.Uint64("user_id", userID),
+			logger.String("tag", name),
+		)
+		return fmt.Errorf("%s: delete tag: %w", op, err)
+	}
+
+	updated, err := s.eventRepo.RemoveTag(ctx, userID, name, entity.EventUpdated)
+	if err != nil {
+		log.LogAttrs(ctx, logger.ErrorLevel, "failed to strip tag from events",
+			logger.String("op", op),
+			logger.Any("error", err),
+			logger.Uint64("user_id", userID),
+			logger.String("tag", name),
+		)
+		return fmt.Errorf("%s: remove tag from events: %w", op, err)
+	}
+	if !defined && len(updated) == 0 {
+		return fmt.Errorf("%s: %w", op, entity.ErrTagNotFound)
+	}
+
+	for _, event := range updated {
+		if key := eventCacheKey(event); s.cache.Has(key) {
+			s.cache.Put(key, event, s.events.CacheTTL())
+		}
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "tag deleted",
+		logger.String("op", op),
+		logger.Uint64("user_id", userID),
+		logger.String("tag", name),
+		logger.Int("events_updated", len(updated)),
+	)
+
+	return nil
+}
+
+func validateTagName(name string) error {
+	if name == "" || len(name) > _maxTagLength {
+		return entity.ErrInvalidTag
+	}
+	if strings.ContainsAny(name, ",\n\t") {
+		return entity.ErrInvalidTag
+	}
+	return nil
+}
+
+func isHexColor(color string) bool {
+	if len(color) != _hexColorLen || color[0] != '#' {
+		return false
+	}
+	for _, r := range color[1:] {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeFilter(filter entity.TagFilter) entity.TagFilter {
+	return entity.TagFilter{
+		Include: entity.NormalizeTags(filter.Include),
+		Exclude: entity.NormalizeTags(filter.Exclude),
+	}
+}
@@ -0,0 +1,70 @@
+package service_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/internal/repository"
+	"calendar-wbf/internal/service"
+	"calendar-wbf/pkg/logger"
+)
+
+func TestTagService_DeleteTag(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc    string
+		defined bool
+		tagged  bool
+		wantErr error
+	}{
+		{desc: "DefinedAndUsed", defined: true, tagged: true},
+		{desc: "DefinedOnly", defined: true},
+		{desc: "UsedWithoutDefinition", tagged: true},
+		{desc: "Unknown", wantErr: entity.ErrTagNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			f := newEventService(t)
+			tags := service.NewTagService(repository.NewTagRepository(), f.repo, f.svc, logger.NewNop(), f.cache)
+
+			if tc.defined {
+				if _, err := tags.SaveTag(f.ctx, 1, "work", "#ff0000"); err != nil {
+					t.Fatalf("SaveTag() error = %v", err)
+				}
+			}
+			var event *entity.Event
+			if tc.tagged {
+				var err error
+				event, err = f.svc.CreateEvent(f.ctx, 1, time.Now().Add(time.Hour), time.Hour, "Standup", "daily",
+					[]string{"work", "team"}, "")
+				if err != nil {
+					t.Fatalf("CreateEvent() error = %v", err)
+				}
+			}
+
+			if err := tags.DeleteTag(f.ctx, 1, "Work"); !errors.Is(err, tc.wantErr) {
+				t.Fatalf("DeleteTag() error = %v; want %v", err, tc.wantErr)
+			}
+
+			if defined, _ := tags.ListTags(f.ctx, 1); len(defined) != 0 {
+				t.Errorf("tags after delete = %d; want none", len(defined))
+			}
+			if event != nil {
+				got, err := f.svc.GetEvent(f.ctx, event.ID, 1)
+				if err != nil {
+					t.Fatalf("GetEvent() error = %v", err)
+				}
+				if !slices.Equal(got.Tags, []string{"team"}) {
+					t.Errorf("event tags = %v; want [team]", got.Tags)
+				}
+			}
+		})
+	}
+}
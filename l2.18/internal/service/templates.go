@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/logger"
+)
+
+const _maxTemplateNameLength = 50
+
+// _placeholderRe matches {{name}} placeholders; the spaces inside the braces
+// are optional.
+var _placeholderRe = regexp.MustCompile(`\{\{\s*([a-z][a-z0-9_]*)\s*\}\}`)
+
+type (
+	TemplateRepo interface {
+		Create(ctx context.Context, template *entity.Template) (*entity.Template, error)
+		Update(ctx context.Context, template *entity.Template) (*entity.Template, error)
+		GetByID(ctx context.Context, id uint64) (*entity.Template, error)
+		ListForUser(ctx context.Context, userID uint64) ([]*entity.Template, error)
+		Delete(ctx context.Context, id uint64) error
+	}
+
+	TemplateService struct {
+		repo   TemplateRepo
+		events *EventService
+		logger logger.Logger
+	}
+)
+
+func NewTemplateService(repo TemplateRepo, events *EventService, logger logger.Logger) *TemplateService {
+	return &TemplateService{
+		repo:   repo,
+		events: events,
+		logger: logger,
+	}
+}
+
+// SaveTemplate creates a template, or replaces the one with template.ID if
+// the user is its author.
+func (s *TemplateService) SaveTemplate(ctx context.Context, template *entity.Template) (*entity.Template, error) {
+	const op = "service.SaveTemplate"
+	log := s.logger.Ctx(ctx)
+
+	if err := s.events.validateUserID(template.UserID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	template.Name = strings.TrimSpace(template.Name)
+	template.Tags = entity.NormalizeTags(template.Tags)
+	template.Color = strings.ToLower(template.Color)
+	if err := validateTemplate(template); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, _defaultContextTimeout)
+	defer cancel()
+
+	var (
+		saved *entity.Template
+		err   error
+	)
+	if template.ID == 0 {
+		saved, err = s.repo.Create(ctx, template)
+	} else {
+		if _, err = s.authored(ctx, template.ID, template.UserID); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		saved, err = s.repo.Update(ctx, template)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: save template: %w", op, err)
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "template saved",
+		logger.String("op", op),
+		logger.Uint64("template_id", saved.ID),
+		logger.Uint64("user_id", saved.UserID),
+		logger.Bool("shared", saved.Shared),
+	)
+
+	return saved, nil
+}
+
+func (s *TemplateService) GetTemplate(ctx context.Context, id, userID uint64) (*entity.Template, error) {
+	const op = "service.GetTemplate"
+
+	if err := s.events.validateUserID(userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, _defaultContextTimeout)
+	defer cancel()
+
+	template, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !template.VisibleTo(userID) {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrTemplateNotFound)
+	}
+	return template, nil
+}
+
+// ListTemplates returns the templates of the user along with the shared
+// templates of the tenant.
+func (s *TemplateService) ListTemplates(ctx context.Context, userID uint64) ([]*entity.Template, error) {
+	const op = "service.ListTemplates"
+
+	if err := s.events.validateUserID(userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, _defaultContextTimeout)
+	defer cancel()
+
+	templates, err := s.repo.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return templates, nil
+}
+
+func (s *TemplateService) DeleteTemplate(ctx context.Context, id, userID uint64) error {
+	const op = "service.DeleteTemplate"
+	log := s.logger.Ctx(ctx)
+
+	if err := s.events.validateUserID(userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, _defaultContextTimeout)
+	defer cancel()
+
+	if _, err := s.authored(ctx, id, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "template deleted",
+		logger.String("op", op),
+		logger.Uint64("template_id", id),
+		logger.Uint64("user_id", userID),
+	)
+	return nil
+}
+
+// CreateEvent creates an event for the user at date from a template visible
+// to them.
+func (s *TemplateService) CreateEvent(
+	ctx context.Context,
+	id, userID uint64,
+	date time.Time,
+	overrides entity.TemplateOverrides,
+) (*entity.Event, error) {
+	const op = "service.CreateEventFromTemplate"
+
+	template, err := s.GetTemplate(ctx, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	event, err := s.events.CreateFromTemplate(ctx, userID, template, date, overrides)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return event, nil
+}
+
+// authored returns the template if userID wrote it. Shared templates of
+// other users are visible but not editable, and read as missing here.
+func (s *TemplateService) authored(ctx context.Context, id, userID uint64) (*entity.Template, error) {
+	template, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if template.UserID != userID {
+		return nil, entity.ErrTemplateNotFound
+	}
+	return template, nil
+}
+
+// CreateFromTemplate creates an event at date from template, applying the
+// overrides and filling the placeholders of the title and text. Besides
+// overrides.Vars, {{date}}, {{time}} and {{weekday}} of the event date are
+// always available; Vars may replace them.
+func (s *EventService) CreateFromTemplate(
+	ctx context.Context,
+	userID uint64,
+	template *entity.Template,
+	date time.Time,
+	overrides entity.TemplateOverrides,
+) (*entity.Event, error) {
+	const op = "service.CreateFromTemplate"
+
+	event := &entity.Event{
+		UserID:   userID,
+		Date:     date,
+		Duration: template.Duration,
+		Title:    template.Title,
+		Text:     template.Text,
+		Tags:     slices.Clone(template.Tags),
+		Color:    template.Color,
+	}
+	if overrides.Duration != nil {
+		event.Duration = *overrides.Duration
+	}
+	if overrides.Title != nil {
+		event.Title = *overrides.Title
+	}
+	if overrides.Text != nil {
+		event.Text = *overrides.Text
+	}
+	if overrides.Tags != nil {
+		event.Tags = entity.NormalizeTags(overrides.Tags)
+	}
+	if overrides.Color != nil {
+		event.Color = strings.ToLower(*overrides.Color)
+	}
+
+	vars := map[string]string{
+		"date":    date.Format(time.DateOnly),
+		"time":    date.Format("15:04"),
+		"weekday": date.Weekday().String(),
+	}
+	for name, value := range overrides.Vars {
+		vars[name] = value
+	}
+
+	var err error
+	if event.Title, err = expandPlaceholders(event.Title, vars); err != nil {
+		return nil, fmt.Errorf("%s: title: %w", op, err)
+	}
+	if event.Text, err = expandPlaceholders(event.Text, vars); err != nil {
+		return nil, fmt.Errorf("%s: text: %w", op, err)
+	}
+
+	return s.createEvent(ctx, event)
+}
+
+func validateTemplate(template *entity.Template) error {
+	if template.Name == "" || utf8.RuneCountInString(template.Name) > _maxTemplateNameLength {
+		return entity.ErrInvalidTemplate
+	}
+	if template.Title == "" || template.Text == "" || utf8.RuneCountInString(template.Text) > _maxEventTextLength {
+		return entity.ErrInvalidTemplate
+	}
+	if template.Duration < 0 || template.Duration > _maxEventDuration {
+		return entity.ErrInvalidTemplate
+	}
+	if len(template.Tags) > _maxEventTags {
+		return entity.ErrInvalidTag
+	}
+	for _, tag := range template.Tags {
+		if err := validateTagName(tag); err != nil {
+			return err
+		}
+	}
+	if template.Color != "" && !isHexColor(template.Color) {
+		return entity.ErrInvalidColor
+	}
+
+	for _, field := range []string{template.Title, template.Text} {
+		if strings.Count(field, "{{") != len(_placeholderRe.FindAllString(field, -1)) {
+			return fmt.Errorf("%w: placeholders must look like {{name}}", entity.ErrInvalidTemplate)
+		}
+	}
+	return nil
+}
+
+// expandPlaceholders replaces the {{name}} placeholders of s with vars and
+// fails listing the names that have no value.
+func expandPlaceholders(s string, vars map[string]string) (string, error) {
+	var missing []string
+	expanded := _placeholderRe.ReplaceAllStringFunc(s, func(match string) string {
+		name := _placeholderRe.FindStringSubmatch(match)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		if !slices.Contains(missing, name) {
+			missing = append(missing, name)
+		}
+		return match
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w: no value for %s", entity.ErrInvalidTemplate, strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
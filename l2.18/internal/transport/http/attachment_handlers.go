@@ -0,0 +1,232 @@
+package httpt
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// _multipartOverhead allows for the boundaries and form fields around the
+// file in an upload request.
+const _multipartOverhead = 64 << 10
+
+type AttachmentService interface {
+	Upload(ctx context.Context, eventID, userID uint64, name string, r io.Reader) (*entity.Attachment, error)
+	List(ctx context.Context, eventID, userID uint64) ([]*entity.Attachment, error)
+	Open(ctx context.Context, eventID, userID uint64, id string) (*entity.Attachment, io.ReadCloser, error)
+	Delete(ctx context.Context, eventID, userID uint64, id string) error
+	MaxSize() int64
+}
+
+// @Summary Прикрепить файл к событию
+// @Description Загружает файл (multipart/form-data, поле file). Тип определяется по содержимому и должен быть
+// @Description разрешен ATTACHMENT_CONTENT_TYPES, размер ограничен ATTACHMENT_MAX_SIZE
+// @Tags Attachments
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "ID события"
+// @Param user_id formData int true "Владелец события"
+// @Param file formData file true "Файл"
+// @Success 200 {object} entity.Attachment
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 404 {object} httpt.ErrorResponse
+// @Failure 409 {object} httpt.ErrorResponse
+// @Failure 413 {object} httpt.ErrorResponse
+// @Failure 415 {object} httpt.ErrorResponse
+// @Router /upload_attachment/{id} [post]
+func (h *CalendarHandler) uploadAttachmentHandler(c *gin.Context) {
+	const op = "transport.uploadAttachmentHandler"
+	log := h.log.Ctx(c.Request.Context())
+
+	if h.attachments == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachments are not configured"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		h.handleEventIDError(c, op, id)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.attachments.MaxSize()+_multipartOverhead)
+
+	var req UploadAttachmentRequest
+	if bindErr := c.ShouldBind(&req); bindErr != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(bindErr, &tooLarge) {
+			h.handleServiceError(c, entity.ErrAttachmentTooLarge, op)
+			return
+		}
+		h.handleBindError(c, bindErr, op)
+		return
+	}
+
+	file, err := req.File.Open()
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+	defer file.Close()
+
+	// Uploads may take longer than _defaultContextTimeout; they stop when
+	// the client goes away.
+	ctx := c.Request.Context()
+
+	attachment, err := h.attachments.Upload(ctx, id, req.UserID, req.File.Filename, file)
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "attachment uploaded successfully",
+		logger.Uint64("event_id", id),
+		logger.String("attachment_id", attachment.ID),
+	)
+
+	c.JSON(http.StatusOK, attachment)
+}
+
+// @Summary Список вложений события
+// @Tags Attachments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID события"
+// @Param request body AttachmentsRequest true "Владелец события"
+// @Success 200 {object} httpt.AttachmentsResponse
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 404 {object} httpt.ErrorResponse
+// @Router /attachments/{id} [post]
+func (h *CalendarHandler) listAttachmentsHandler(c *gin.Context) {
+	const op = "transport.listAttachmentsHandler"
+
+	if h.attachments == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachments are not configured"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		h.handleEventIDError(c, op, id)
+		return
+	}
+
+	var req AttachmentsRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		h.handleBindError(c, bindErr, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	attachments, err := h.attachments.List(ctx, id, req.UserID)
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+
+	c.JSON(http.StatusOK, AttachmentsResponse{Result: attachments})
+}
+
+// @Summary Скачать вложение
+// @Description Отдает файл как вложение (Content-Disposition: attachment) с типом, определенным при загрузке
+// @Tags Attachments
+// @Produce octet-stream
+// @Param id path int true "ID события"
+// @Param attachment_id path string true "ID вложения"
+// @Param user_id query int true "Владелец события"
+// @Success 200 {file} file
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 404 {object} httpt.ErrorResponse
+// @Router /download_attachment/{id}/{attachment_id} [get]
+func (h *CalendarHandler) downloadAttachmentHandler(c *gin.Context) {
+	const op = "transport.downloadAttachmentHandler"
+
+	if h.attachments == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachments are not configured"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		h.handleEventIDError(c, op, id)
+		return
+	}
+
+	var req AttachmentsRequest
+	if bindErr := c.ShouldBindQuery(&req); bindErr != nil {
+		h.handleBindError(c, bindErr, op)
+		return
+	}
+
+	attachment, content, err := h.attachments.Open(c.Request.Context(), id, req.UserID, c.Param("attachment_id"))
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name}),
+		"X-Content-Type-Options": "nosniff",
+		"ETag":                   `"` + attachment.SHA256 + `"`,
+	})
+}
+
+// @Summary Удалить вложение
+// @Tags Attachments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID события"
+// @Param attachment_id path string true "ID вложения"
+// @Param request body AttachmentsRequest true "Владелец события"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 404 {object} httpt.ErrorResponse
+// @Router /delete_attachment/{id}/{attachment_id} [post]
+func (h *CalendarHandler) deleteAttachmentHandler(c *gin.Context) {
+	const op = "transport.deleteAttachmentHandler"
+	log := h.log.Ctx(c.Request.Context())
+
+	if h.attachments == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachments are not configured"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		h.handleEventIDError(c, op, id)
+		return
+	}
+
+	var req AttachmentsRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		h.handleBindError(c, bindErr, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	attachmentID := c.Param("attachment_id")
+	if svcErr := h.attachments.Delete(ctx, id, req.UserID, attachmentID); svcErr != nil {
+		h.handleServiceError(c, svcErr, op)
+		return
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "attachment deleted successfully",
+		logger.Uint64("event_id", id),
+		logger.String("attachment_id", attachmentID),
+	)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted successfully"})
+}
@@ -0,0 +1,669 @@
+package httpt
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/ical"
+	"calendar-wbf/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	_caldavRoot      = "/caldav/"
+	_calendarSegment = "events"
+	_icsExtension    = ".ics"
+	_icsContentType  = "text/calendar; charset=utf-8"
+	_caldavProdID    = "-//calendar-wbf//CalDAV//EN"
+	_syncTokenPrefix = "urn:calendar-wbf:sync:"
+	_tokenLength     = 16
+	_maxICSBodySize  = 1 << 20
+	_davAllow        = "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT"
+	_davCapabilities = "1, 3, calendar-access"
+)
+
+type (
+	CalDAVService interface {
+		ListEvents(ctx context.Context, userID uint64) ([]*entity.Event, error)
+		PutEvent(ctx context.Context, event *entity.Event, match entity.EventMatch) (*entity.Event, error)
+		DeleteEventIf(ctx context.Context, id, userID uint64, match entity.EventMatch) error
+	}
+
+	// CalDAV serves each user's events as one calendar collection for native
+	// clients (RFC 4791): /caldav/{user_id}/events/{uid}.ics.
+	//
+	// Like the JSON API, it identifies users by ID rather than authenticating
+	// them: clients send the ID as the Basic auth user name, which is only
+	// needed for discovery from /caldav/ and must match the URL when given.
+	CalDAV struct {
+		svc CalDAVService
+		log logger.Logger
+	}
+)
+
+var _safeResourceName = regexp.MustCompile(`^[A-Za-z0-9._@+=-]+$`)
+
+func NewCalDAV(svc CalDAVService, log logger.Logger) *CalDAV {
+	return &CalDAV{svc: svc, log: log}
+}
+
+// Register mounts the CalDAV endpoints. Collection paths are registered with
+// and without the trailing slash, since clients use both.
+func (d *CalDAV) Register(r gin.IRoutes) {
+	wellKnown := func(c *gin.Context) {
+		c.Redirect(http.StatusMovedPermanently, _caldavRoot)
+	}
+	r.Handle(http.MethodGet, "/.well-known/caldav", wellKnown)
+	r.Handle("PROPFIND", "/.well-known/caldav", wellKnown)
+
+	for _, path := range []string{"/caldav", "/caldav/"} {
+		r.Handle(http.MethodOptions, path, d.options)
+		r.Handle("PROPFIND", path, d.propfindRoot)
+	}
+
+	for _, path := range []string{"/caldav/:user", "/caldav/:user/"} {
+		r.Handle(http.MethodOptions, path, d.options)
+		r.Handle("PROPFIND", path, d.withUser(d.propfindPrincipal))
+	}
+
+	for _, path := range []string{"/caldav/:user/events", "/caldav/:user/events/"} {
+		r.Handle(http.MethodOptions, path, d.options)
+		r.Handle("PROPFIND", path, d.withUser(d.propfindCalendar))
+		r.Handle("REPORT", path, d.withUser(d.report))
+	}
+
+	resource := "/caldav/:user/events/:resource"
+	r.Handle(http.MethodOptions, resource, d.options)
+	r.Handle(http.MethodGet, resource, d.withUser(d.getEvent))
+	r.Handle(http.MethodHead, resource, d.withUser(d.getEvent))
+	r.Handle(http.MethodPut, resource, d.withUser(d.putEvent))
+	r.Handle(http.MethodDelete, resource, d.withUser(d.deleteEvent))
+	r.Handle("PROPFIND", resource, d.withUser(d.propfindEvent))
+}
+
+func (d *CalDAV) options(c *gin.Context) {
+	c.Header("DAV", _davCapabilities)
+	c.Header("Allow", _davAllow)
+	c.Status(http.StatusOK)
+}
+
+// withUser resolves the user from the URL and rejects Basic auth
+// credentials naming somebody else.
+func (d *CalDAV) withUser(next func(c *gin.Context, userID uint64)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := strconv.ParseUint(c.Param("user"), 10, 64)
+		if err != nil || userID == 0 {
+			c.Status(http.StatusNotFound)
+			return
+		}
+
+		if name, _, ok := c.Request.BasicAuth(); ok && name != c.Param("user") {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		next(c, userID)
+	}
+}
+
+func (d *CalDAV) propfindRoot(c *gin.Context) {
+	name, _, ok := c.Request.BasicAuth()
+	userID, err := strconv.ParseUint(name, 10, 64)
+	if !ok || err != nil || userID == 0 {
+		c.Header("WWW-Authenticate", `Basic realm="calendar"`)
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	req, ok := d.bindPropfind(c)
+	if !ok {
+		return
+	}
+
+	root := davResource{href: _caldavRoot, props: map[xml.Name]func() string{
+		davName(_nsDAV, "resourcetype"):           constProp("<d:collection/>"),
+		davName(_nsDAV, "current-user-principal"): constProp(hrefXML(principalHref(userID))),
+	}}
+	writeMultistatus(c.Writer, []davResponse{req.response(root)}, "")
+}
+
+func (d *CalDAV) propfindPrincipal(c *gin.Context, userID uint64) {
+	req, ok := d.bindPropfind(c)
+	if !ok {
+		return
+	}
+
+	responses := []davResponse{req.response(principalResource(userID))}
+	if c.GetHeader("Depth") == "1" {
+		events, ok := d.listEvents(c, userID)
+		if !ok {
+			return
+		}
+		responses = append(responses, req.response(calendarResource(userID, events)))
+	}
+	writeMultistatus(c.Writer, responses, "")
+}
+
+func (d *CalDAV) propfindCalendar(c *gin.Context, userID uint64) {
+	req, ok := d.bindPropfind(c)
+	if !ok {
+		return
+	}
+
+	events, ok := d.listEvents(c, userID)
+	if !ok {
+		return
+	}
+
+	responses := []davResponse{req.response(calendarResource(userID, events))}
+	if c.GetHeader("Depth") == "1" {
+		for _, event := range events {
+			responses = append(responses, req.response(eventResource(event)))
+		}
+	}
+	writeMultistatus(c.Writer, responses, "")
+}
+
+func (d *CalDAV) propfindEvent(c *gin.Context, userID uint64) {
+	req, ok := d.bindPropfind(c)
+	if !ok {
+		return
+	}
+
+	event, _, ok := d.findEvent(c, userID)
+	if !ok {
+		return
+	}
+	if event == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	writeMultistatus(c.Writer, []davResponse{req.response(eventResource(event))}, "")
+}
+
+func (d *CalDAV) report(c *gin.Context, userID uint64) {
+	const op = "transport.caldavReport"
+
+	var req reportRequest
+	if _, err := decodeDAVBody(c.Request.Body, &req); err != nil {
+		d.logBadRequest(c, op, err)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	events, ok := d.listEvents(c, userID)
+	if !ok {
+		return
+	}
+
+	var (
+		responses []davResponse
+		syncToken string
+	)
+
+	switch req.XMLName {
+	case davName(_nsCalDAV, "calendar-query"):
+		start, end, err := req.timeRange()
+		if err != nil {
+			d.logBadRequest(c, op, err)
+			writeDAVError(c.Writer, http.StatusForbidden, davName(_nsCalDAV, "valid-filter"))
+			return
+		}
+		for _, event := range events {
+			if overlaps(event, start, end) {
+				responses = append(responses, eventResource(event).selected(req.Prop))
+			}
+		}
+	case davName(_nsCalDAV, "calendar-multiget"):
+		for _, href := range req.Hrefs {
+			if event := eventByHref(events, userID, href); event != nil {
+				responses = append(responses, eventResource(event).selected(req.Prop))
+			} else {
+				responses = append(responses, davResponse{href: href, status: http.StatusNotFound})
+			}
+		}
+	case davName(_nsDAV, "sync-collection"):
+		// Without a change log only the current token can be answered
+		// incrementally; any other token makes the client resync in full
+		// (RFC 6578 3.2).
+		syncToken = syncTokenOf(events)
+		switch req.SyncToken {
+		case syncToken:
+		case "":
+			for _, event := range events {
+				responses = append(responses, eventResource(event).selected(req.Prop))
+			}
+		default:
+			writeDAVError(c.Writer, http.StatusForbidden, davName(_nsDAV, "valid-sync-token"))
+			return
+		}
+	default:
+		writeDAVError(c.Writer, http.StatusForbidden, davName(_nsDAV, "supported-report"))
+		return
+	}
+
+	writeMultistatus(c.Writer, responses, syncToken)
+}
+
+func (d *CalDAV) getEvent(c *gin.Context, userID uint64) {
+	event, _, ok := d.findEvent(c, userID)
+	if !ok {
+		return
+	}
+	if event == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.Header("ETag", etagOf(event))
+	c.Data(http.StatusOK, _icsContentType, encodeEvent(event))
+}
+
+func (d *CalDAV) putEvent(c *gin.Context, userID uint64) {
+	const op = "transport.caldavPutEvent"
+
+	existing, events, ok := d.findEvent(c, userID)
+	if !ok {
+		return
+	}
+
+	if !preconditionsHold(c, existing) {
+		c.Status(http.StatusPreconditionFailed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, _maxICSBodySize))
+	if err != nil {
+		d.logBadRequest(c, op, err)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.Status(http.StatusRequestEntityTooLarge)
+		} else {
+			c.Status(http.StatusBadRequest)
+		}
+		return
+	}
+
+	decoded, err := ical.Decode(bytes.NewReader(body))
+	if err != nil {
+		d.logBadRequest(c, op, err)
+		writeDAVError(c.Writer, http.StatusForbidden, davName(_nsCalDAV, "valid-calendar-data"))
+		return
+	}
+
+	// Every VEVENT of a resource shares the UID; overrides of recurring
+	// events are not supported, so the first one is stored.
+	event := eventFromICal(decoded[0], userID)
+	if existing != nil {
+		event.ID = existing.ID
+	}
+
+	if other := eventByUID(events, event.UID); other != nil && (existing == nil || other.ID != existing.ID) {
+		writeDAVError(c.Writer, http.StatusForbidden, davName(_nsCalDAV, "no-uid-conflict"))
+		return
+	}
+
+	// The ETag checked above may be stale by now, so the service checks it
+	// again under the lock of the write.
+	saved, err := d.svc.PutEvent(c.Request.Context(), event, ifMatch(c))
+	if err != nil {
+		d.handleError(c, op, err)
+		return
+	}
+
+	c.Header("ETag", etagOf(saved))
+	if existing == nil {
+		c.Header("Location", eventHref(saved))
+		c.Status(http.StatusCreated)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (d *CalDAV) deleteEvent(c *gin.Context, userID uint64) {
+	const op = "transport.caldavDeleteEvent"
+
+	event, _, ok := d.findEvent(c, userID)
+	if !ok {
+		return
+	}
+	if event == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	if !preconditionsHold(c, event) {
+		c.Status(http.StatusPreconditionFailed)
+		return
+	}
+
+	if err := d.svc.DeleteEventIf(c.Request.Context(), event.ID, userID, ifMatch(c)); err != nil {
+		d.handleError(c, op, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (d *CalDAV) bindPropfind(c *gin.Context) (*propfindRequest, bool) {
+	const op = "transport.caldavPropfind"
+
+	if depth := c.GetHeader("Depth"); depth == "infinity" {
+		writeDAVError(c.Writer, http.StatusForbidden, davName(_nsDAV, "propfind-finite-depth"))
+		return nil, false
+	}
+
+	var req propfindRequest
+	if _, err := decodeDAVBody(c.Request.Body, &req); err != nil {
+		d.logBadRequest(c, op, err)
+		c.Status(http.StatusBadRequest)
+		return nil, false
+	}
+	return &req, true
+}
+
+func (d *CalDAV) listEvents(c *gin.Context, userID uint64) ([]*entity.Event, bool) {
+	const op = "transport.caldavListEvents"
+
+	events, err := d.svc.ListEvents(c.Request.Context(), userID)
+	if err != nil {
+		d.handleError(c, op, err)
+		return nil, false
+	}
+	return events, true
+}
+
+// findEvent returns the event behind the :resource parameter, or nil when
+// there is none, together with all events of the user.
+func (d *CalDAV) findEvent(c *gin.Context, userID uint64) (*entity.Event, []*entity.Event, bool) {
+	events, ok := d.listEvents(c, userID)
+	if !ok {
+		return nil, nil, false
+	}
+
+	name, found := strings.CutSuffix(c.Param("resource"), _icsExtension)
+	if !found {
+		return nil, events, true
+	}
+	return eventByName(events, name), events, true
+}
+
+func (d *CalDAV) handleError(c *gin.Context, op string, err error) {
+	ctx := c.Request.Context()
+
+	switch {
+	case errors.Is(err, entity.ErrEventNotFound):
+		c.Status(http.StatusNotFound)
+	case errors.Is(err, entity.ErrPreconditionFailed):
+		c.Status(http.StatusPreconditionFailed)
+	case errors.Is(err, entity.ErrInvalidData), errors.Is(err, entity.ErrInvalidDate),
+		errors.Is(err, entity.ErrInvalidTag), errors.Is(err, entity.ErrInvalidColor):
+		d.logBadRequest(c, op, err)
+		writeDAVError(c.Writer, http.StatusForbidden, davName(_nsCalDAV, "valid-calendar-object-resource"))
+	case errors.Is(err, entity.ErrInvalidUserID):
+		c.Status(http.StatusNotFound)
+	case errors.Is(err, entity.ErrQuotaExceeded):
+		d.logBadRequest(c, op, err)
+		writeDAVError(c.Writer, http.StatusInsufficientStorage, davName(_nsDAV, "quota-not-exceeded"))
+	default:
+		d.log.Ctx(ctx).LogAttrs(ctx, logger.ErrorLevel, "caldav request failed",
+			logger.String("op", op),
+			logger.Any("error", err),
+			logger.String("path", c.Request.URL.Path),
+		)
+		c.Status(http.StatusInternalServerError)
+	}
+}
+
+func (d *CalDAV) logBadRequest(c *gin.Context, op string, err error) {
+	ctx := c.Request.Context()
+	d.log.Ctx(ctx).LogAttrs(ctx, logger.WarnLevel, "invalid caldav request",
+		logger.String("op", op),
+		logger.Any("error", err),
+		logger.String("path", c.Request.URL.Path),
+		logger.String("client_ip", c.ClientIP()),
+	)
+}
+
+func principalResource(userID uint64) davResource {
+	return davResource{href: principalHref(userID), props: map[xml.Name]func() string{
+		davName(_nsDAV, "resourcetype"):           constProp("<d:collection/><d:principal/>"),
+		davName(_nsDAV, "displayname"):            constProp(fmt.Sprintf("User %d", userID)),
+		davName(_nsDAV, "current-user-principal"): constProp(hrefXML(principalHref(userID))),
+		davName(_nsDAV, "principal-URL"):          constProp(hrefXML(principalHref(userID))),
+		davName(_nsCalDAV, "calendar-home-set"):   constProp(hrefXML(principalHref(userID))),
+	}}
+}
+
+func calendarResource(userID uint64, events []*entity.Event) davResource {
+	token := syncTokenOf(events)
+
+	return davResource{href: calendarHref(userID), props: map[xml.Name]func() string{
+		davName(_nsDAV, "resourcetype"):           constProp("<d:collection/><c:calendar/>"),
+		davName(_nsDAV, "displayname"):            constProp("Calendar"),
+		davName(_nsDAV, "current-user-principal"): constProp(hrefXML(principalHref(userID))),
+		davName(_nsDAV, "current-user-privilege-set"): constProp(
+			"<d:privilege><d:read/></d:privilege><d:privilege><d:write/></d:privilege>" +
+				"<d:privilege><d:write-content/></d:privilege><d:privilege><d:bind/></d:privilege>" +
+				"<d:privilege><d:unbind/></d:privilege>",
+		),
+		davName(_nsDAV, "supported-report-set"): constProp(
+			"<d:supported-report><d:report><c:calendar-query/></d:report></d:supported-report>" +
+				"<d:supported-report><d:report><c:calendar-multiget/></d:report></d:supported-report>" +
+				"<d:supported-report><d:report><d:sync-collection/></d:report></d:supported-report>",
+		),
+		davName(_nsDAV, "sync-token"):                          constProp(escapeXML(token)),
+		davName(_nsCalServer, "getctag"):                       constProp(escapeXML(token)),
+		davName(_nsCalDAV, "supported-calendar-component-set"): constProp(`<c:comp name="VEVENT"/>`),
+	}}
+}
+
+func eventResource(event *entity.Event) davResource {
+	return davResource{href: eventHref(event), props: map[xml.Name]func() string{
+		davName(_nsDAV, "resourcetype"):     constProp(""),
+		davName(_nsDAV, "getetag"):          constProp(escapeXML(etagOf(event))),
+		davName(_nsDAV, "getcontenttype"):   constProp(_icsContentType + "; component=vevent"),
+		davName(_nsDAV, "getlastmodified"):  constProp(event.UpdatedAt.UTC().Format(http.TimeFormat)),
+		davName(_nsCalDAV, "calendar-data"): func() string { return escapeXML(string(encodeEvent(event))) },
+	}}
+}
+
+func constProp(value string) func() string {
+	return func() string { return value }
+}
+
+func principalHref(userID uint64) string {
+	return _caldavRoot + strconv.FormatUint(userID, 10) + "/"
+}
+
+func calendarHref(userID uint64) string {
+	return principalHref(userID) + _calendarSegment + "/"
+}
+
+func eventHref(event *entity.Event) string {
+	return calendarHref(event.UserID) + resourceName(event) + _icsExtension
+}
+
+// resourceName is the client's UID when it is safe in a URL, otherwise the
+// event ID. Events created through the JSON API have no UID and are exported
+// with their ID as the UID, so a client saving them back keeps the name.
+func resourceName(event *entity.Event) string {
+	if _safeResourceName.MatchString(event.UID) {
+		return event.UID
+	}
+	return strconv.FormatUint(event.ID, 10)
+}
+
+func eventByName(events []*entity.Event, name string) *entity.Event {
+	for _, event := range events {
+		if resourceName(event) == name {
+			return event
+		}
+	}
+	return nil
+}
+
+func eventByUID(events []*entity.Event, uid string) *entity.Event {
+	for _, event := range events {
+		if event.UID == uid {
+			return event
+		}
+	}
+	return nil
+}
+
+func eventByHref(events []*entity.Event, userID uint64, href string) *entity.Event {
+	if u, err := url.Parse(href); err == nil {
+		href = u.Path
+	}
+
+	name, found := strings.CutPrefix(href, calendarHref(userID))
+	if !found {
+		return nil
+	}
+	name, found = strings.CutSuffix(name, _icsExtension)
+	if !found {
+		return nil
+	}
+	return eventByName(events, name)
+}
+
+func etagOf(event *entity.Event) string {
+	return fmt.Sprintf(`"%d-%x"`, event.ID, event.UpdatedAt.UnixNano())
+}
+
+// syncTokenOf hashes the IDs and modification times of all events, so it
+// changes on every create, update and delete. It doubles as the ctag.
+func syncTokenOf(events []*entity.Event) string {
+	hash := sha256.New()
+	for _, event := range events {
+		_, _ = fmt.Fprintf(hash, "%d:%d;", event.ID, event.UpdatedAt.UnixNano())
+	}
+	return _syncTokenPrefix + hex.EncodeToString(hash.Sum(nil))[:_tokenLength]
+}
+
+// preconditionsHold checks If-Match and If-None-Match against the current
+// state of the resource.
+func preconditionsHold(c *gin.Context, event *entity.Event) bool {
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch == "*" && event != nil {
+		return false
+	}
+
+	switch match := ifMatch(c); {
+	case c.GetHeader("If-Match") == "":
+		return true
+	case event == nil:
+		return false
+	case match == nil:
+		return true
+	default:
+		return match(event)
+	}
+}
+
+// ifMatch returns the If-Match condition on the ETag of the stored event,
+// nil when any version matches.
+func ifMatch(c *gin.Context) entity.EventMatch {
+	header := c.GetHeader("If-Match")
+	if header == "" || header == "*" {
+		return nil
+	}
+
+	etags := strings.Split(strings.ReplaceAll(header, " ", ""), ",")
+	return func(stored *entity.Event) bool {
+		return slices.Contains(etags, etagOf(stored))
+	}
+}
+
+// timeRange returns the VEVENT time-range of a calendar-query; zero times
+// leave the range open.
+func (req *reportRequest) timeRange() (time.Time, time.Time, error) {
+	var start, end time.Time
+	if req.Filter == nil {
+		return start, end, nil
+	}
+
+	for _, filter := range req.Filter.CompFilters {
+		if filter.Name != "VEVENT" || filter.TimeRange == nil {
+			continue
+		}
+
+		var err error
+		if filter.TimeRange.Start != "" {
+			if start, err = time.Parse("20060102T150405Z", filter.TimeRange.Start); err != nil {
+				return start, end, fmt.Errorf("time-range start: %w", err)
+			}
+		}
+		if filter.TimeRange.End != "" {
+			if end, err = time.Parse("20060102T150405Z", filter.TimeRange.End); err != nil {
+				return start, end, fmt.Errorf("time-range end: %w", err)
+			}
+		}
+	}
+	return start, end, nil
+}
+
+// overlaps follows RFC 4791 9.9: an event matches when it starts before the
+// range ends and ends after it starts.
+func overlaps(event *entity.Event, start, end time.Time) bool {
+	if !end.IsZero() && !event.Date.Before(end) {
+		return false
+	}
+	if !start.IsZero() && !event.End().After(start) && !(event.Duration == 0 && event.Date.Equal(start)) {
+		return false
+	}
+	return true
+}
+
+func encodeEvent(event *entity.Event) []byte {
+	var buf bytes.Buffer
+	_ = ical.Encode(&buf, _caldavProdID, event.ICal())
+	return buf.Bytes()
+}
+
+func eventFromICal(e ical.Event, userID uint64) *entity.Event {
+	text := e.Description
+	if text == "" {
+		text = e.Summary
+	}
+
+	// COLOR may be any CSS color name; only hex colors are kept.
+	color := ""
+	if strings.HasPrefix(e.Color, "#") {
+		color = e.Color
+	}
+
+	return &entity.Event{
+		UserID:    userID,
+		UID:       e.UID,
+		Date:      e.Start,
+		Duration:  max(e.End.Sub(e.Start), 0),
+		Title:     e.Summary,
+		Text:      text,
+		Tags:      e.Categories,
+		Color:     color,
+		Attendees: attendeesFromICal(e.Attendees),
+	}
+}
+
+func attendeesFromICal(attendees []ical.Attendee) []entity.Attendee {
+	result := make([]entity.Attendee, 0, len(attendees))
+	for _, a := range attendees {
+		result = append(result, entity.Attendee{Email: a.Email, Name: a.Name, Status: strings.ToLower(a.Status)})
+	}
+	return result
+}
@@ -0,0 +1,455 @@
+package httpt_test
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	httpt "calendar-wbf/internal/transport/http"
+	"calendar-wbf/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const _testUser = "7"
+
+type (
+	fakeCalDAVService struct {
+		mu     sync.Mutex
+		events []*entity.Event
+		nextID uint64
+		clock  time.Time
+		// beforeWrite runs between the handler's precondition check and
+		// the write, where a concurrent request could change the event.
+		beforeWrite func()
+	}
+
+	multistatus struct {
+		SyncToken string `xml:"DAV: sync-token"`
+		Responses []struct {
+			Href      string `xml:"DAV: href"`
+			Status    string `xml:"DAV: status"`
+			Propstats []struct {
+				Status string `xml:"DAV: status"`
+				Prop   struct {
+					Inner string `xml:",innerxml"`
+				} `xml:"DAV: prop"`
+			} `xml:"DAV: propstat"`
+		} `xml:"DAV: response"`
+	}
+)
+
+func (s *fakeCalDAVService) ListEvents(_ context.Context, userID uint64) ([]*entity.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []*entity.Event
+	for _, event := range s.events {
+		if event.UserID == userID {
+			copied := *event
+			events = append(events, &copied)
+		}
+	}
+	return events, nil
+}
+
+func (s *fakeCalDAVService) PutEvent(
+	_ context.Context,
+	event *entity.Event,
+	match entity.EventMatch,
+) (*entity.Event, error) {
+	if s.beforeWrite != nil {
+		s.beforeWrite()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if event.Title == "" {
+		return nil, entity.ErrInvalidData
+	}
+
+	s.clock = s.clock.Add(time.Second)
+	event.UpdatedAt = s.clock
+
+	if event.ID == 0 {
+		s.nextID++
+		event.ID, event.CreatedAt = s.nextID, s.clock
+		s.events = append(s.events, event)
+		return event, nil
+	}
+
+	for i, existing := range s.events {
+		if existing.ID == event.ID {
+			if match != nil && !match(existing) {
+				return nil, entity.ErrPreconditionFailed
+			}
+			event.CreatedAt = existing.CreatedAt
+			s.events[i] = event
+			return event, nil
+		}
+	}
+	return nil, entity.ErrEventNotFound
+}
+
+func (s *fakeCalDAVService) DeleteEventIf(_ context.Context, id, userID uint64, match entity.EventMatch) error {
+	if s.beforeWrite != nil {
+		s.beforeWrite()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, event := range s.events {
+		if event.ID == id && event.UserID == userID {
+			if match != nil && !match(event) {
+				return entity.ErrPreconditionFailed
+			}
+			s.events = slices.Delete(s.events, i, i+1)
+			return nil
+		}
+	}
+	return entity.ErrEventNotFound
+}
+
+func newCalDAVServer(t *testing.T) (*gin.Engine, *fakeCalDAVService) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	svc := &fakeCalDAVService{clock: time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)}
+	router := gin.New()
+	httpt.NewCalDAV(svc, logger.NewNop()).Register(router)
+
+	return router, svc
+}
+
+func fixture(t *testing.T, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "caldav", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return string(data)
+}
+
+func davRequest(
+	t *testing.T,
+	router http.Handler,
+	method, path, body string,
+	headers map[string]string,
+) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeMultistatus(t *testing.T, rec *httptest.ResponseRecorder) multistatus {
+	t.Helper()
+
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("status = %d, want 207; body = %s", rec.Code, rec.Body.String())
+	}
+
+	var ms multistatus
+	if err := xml.Unmarshal(rec.Body.Bytes(), &ms); err != nil {
+		t.Fatalf("decode multistatus: %v; body = %s", err, rec.Body.String())
+	}
+	return ms
+}
+
+func putFixture(t *testing.T, router http.Handler) *httptest.ResponseRecorder {
+	t.Helper()
+
+	rec := davRequest(t, router, http.MethodPut, "/caldav/7/events/planning-42@example.org.ics",
+		fixture(t, "thunderbird_put.ics"), map[string]string{"If-None-Match": "*"})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("PUT status = %d, want 201; body = %s", rec.Code, rec.Body.String())
+	}
+	return rec
+}
+
+func TestCalDAV_Discovery(t *testing.T) {
+	t.Parallel()
+
+	router, _ := newCalDAVServer(t)
+
+	rec := davRequest(t, router, "PROPFIND", "/.well-known/caldav", "", nil)
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/caldav/" {
+		t.Fatalf("well-known = %d %q, want 301 /caldav/", rec.Code, rec.Header().Get("Location"))
+	}
+
+	rec = davRequest(t, router, "PROPFIND", "/caldav/", "", map[string]string{"Depth": "0"})
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+		t.Fatalf("anonymous PROPFIND = %d, want 401 with a challenge", rec.Code)
+	}
+
+	req := httptest.NewRequest("PROPFIND", "/caldav/", nil)
+	req.SetBasicAuth(_testUser, "")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if ms := decodeMultistatus(t, rec); !strings.Contains(ms.Responses[0].Propstats[0].Prop.Inner, "/caldav/7/") {
+		t.Errorf("current-user-principal missing in %s", rec.Body.String())
+	}
+
+	rec = davRequest(t, router, "PROPFIND", "/caldav/7/", fixture(t, "apple_propfind_principal.xml"),
+		map[string]string{"Depth": "0"})
+	inner := decodeMultistatus(t, rec).Responses[0].Propstats[0].Prop.Inner
+	if !strings.Contains(inner, "calendar-home-set") || !strings.Contains(inner, "/caldav/7/") {
+		t.Errorf("calendar-home-set missing in %s", inner)
+	}
+
+	req = httptest.NewRequest("PROPFIND", "/caldav/7/", nil)
+	req.SetBasicAuth("8", "")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("foreign principal = %d, want 403", rec.Code)
+	}
+}
+
+func TestCalDAV_PropfindCalendar(t *testing.T) {
+	t.Parallel()
+
+	router, _ := newCalDAVServer(t)
+	putFixture(t, router)
+
+	rec := davRequest(t, router, "PROPFIND", "/caldav/7/events/", fixture(t, "apple_propfind_calendar.xml"),
+		map[string]string{"Depth": "1"})
+	ms := decodeMultistatus(t, rec)
+
+	if len(ms.Responses) != 2 {
+		t.Fatalf("responses = %d, want collection and one event", len(ms.Responses))
+	}
+
+	collection := ms.Responses[0]
+	if collection.Href != "/caldav/7/events/" {
+		t.Errorf("href = %q, want /caldav/7/events/", collection.Href)
+	}
+	if len(collection.Propstats) != 2 {
+		t.Fatalf("propstats = %d, want found and missing", len(collection.Propstats))
+	}
+
+	found, missing := collection.Propstats[0], collection.Propstats[1]
+	for _, want := range []string{"getctag", "sync-token", `name="VEVENT"`, "<c:calendar/>", "write-content"} {
+		if !strings.Contains(found.Prop.Inner, want) {
+			t.Errorf("found props lack %q: %s", want, found.Prop.Inner)
+		}
+	}
+	if !strings.Contains(missing.Status, "404") || !strings.Contains(missing.Prop.Inner, "calendar-color") {
+		t.Errorf("calendar-color not reported missing: %s %s", missing.Status, missing.Prop.Inner)
+	}
+
+	if href := ms.Responses[1].Href; href != "/caldav/7/events/planning-42@example.org.ics" {
+		t.Errorf("event href = %q", href)
+	}
+
+	rec = davRequest(t, router, "PROPFIND", "/caldav/7/events/", "", map[string]string{"Depth": "infinity"})
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Depth infinity = %d, want 403", rec.Code)
+	}
+}
+
+func TestCalDAV_PutGetDelete(t *testing.T) {
+	t.Parallel()
+
+	router, svc := newCalDAVServer(t)
+	rec := putFixture(t, router)
+	etag := rec.Header().Get("ETag")
+
+	events, _ := svc.ListEvents(context.Background(), 7)
+	if len(events) != 1 {
+		t.Fatalf("events = %d, want 1", len(events))
+	}
+	event := events[0]
+	if event.UID != "planning-42@example.org" || event.Title != "Sprint planning" ||
+		event.Duration != 90*time.Minute || !slices.Equal(event.Tags, []string{"work", "team"}) {
+		t.Errorf("stored event = %+v", event)
+	}
+	if want := time.Date(2026, time.June, 15, 7, 0, 0, 0, time.UTC); !event.Date.Equal(want) {
+		t.Errorf("Date = %v, want %v", event.Date, want)
+	}
+	if event.Text != "Agenda:\n- backlog\n- capacity" {
+		t.Errorf("Text = %q", event.Text)
+	}
+	wantAttendees := []entity.Attendee{
+		{Email: "alice@example.com", Name: "Alice", Status: "accepted"},
+		{Email: "bob@example.org", Status: "needs-action"},
+	}
+	if !slices.Equal(event.Attendees, wantAttendees) {
+		t.Errorf("Attendees = %+v, want %+v", event.Attendees, wantAttendees)
+	}
+
+	path := "/caldav/7/events/planning-42@example.org.ics"
+	rec = davRequest(t, router, http.MethodGet, path, "", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != etag {
+		t.Fatalf("GET = %d etag %q, want 200 %q", rec.Code, rec.Header().Get("ETag"), etag)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	for _, want := range []string{
+		"UID:planning-42@example.org", "DTSTART:20260615T070000Z", "CATEGORIES:work,team",
+		"ATTENDEE;CN=Alice;PARTSTAT=ACCEPTED:mailto:alice@example.com",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("calendar data lacks %q:\n%s", want, body)
+		}
+	}
+
+	testCases := []struct {
+		desc    string
+		method  string
+		body    string
+		headers map[string]string
+		want    int
+	}{
+		{"CreateExisting", http.MethodPut, fixture(t, "thunderbird_put.ics"), map[string]string{"If-None-Match": "*"}, 412},
+		{"StaleUpdate", http.MethodPut, fixture(t, "thunderbird_put.ics"), map[string]string{"If-Match": `"1-0"`}, 412},
+		{"Malformed", http.MethodPut, "BEGIN:VCALENDAR\r\n", nil, 403},
+		{"TooLarge", http.MethodPut, strings.Repeat("X", 1<<20+1), nil, 413},
+		{"StaleDelete", http.MethodDelete, "", map[string]string{"If-Match": `"1-0"`}, 412},
+	}
+
+	for _, tc := range testCases {
+		rec := davRequest(t, router, tc.method, path, tc.body, tc.headers)
+		if rec.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d; body = %s", tc.desc, rec.Code, tc.want, rec.Body.String())
+		}
+	}
+
+	updated := strings.Replace(fixture(t, "thunderbird_put.ics"), "Sprint planning", "Sprint review", 1)
+	rec = davRequest(t, router, http.MethodPut, path, updated, map[string]string{"If-Match": etag})
+	if rec.Code != http.StatusNoContent || rec.Header().Get("ETag") == etag {
+		t.Fatalf("update = %d etag %q, want 204 with a new etag", rec.Code, rec.Header().Get("ETag"))
+	}
+
+	conflict := strings.Replace(fixture(t, "thunderbird_put.ics"), "Sprint planning", "Copy", 1)
+	rec = davRequest(t, router, http.MethodPut, "/caldav/7/events/copy.ics", conflict, nil)
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "no-uid-conflict") {
+		t.Errorf("UID conflict = %d %s, want 403 no-uid-conflict", rec.Code, rec.Body.String())
+	}
+
+	rec = davRequest(t, router, http.MethodDelete, path, "", nil)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE = %d, want 204", rec.Code)
+	}
+	if rec = davRequest(t, router, http.MethodGet, path, "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("GET after DELETE = %d, want 404", rec.Code)
+	}
+}
+
+func TestCalDAV_ConcurrentChange(t *testing.T) {
+	t.Parallel()
+
+	router, svc := newCalDAVServer(t)
+	etag := putFixture(t, router).Header().Get("ETag")
+
+	// Another client updates the event after the handler checked If-Match.
+	svc.beforeWrite = func() {
+		svc.mu.Lock()
+		defer svc.mu.Unlock()
+		svc.clock = svc.clock.Add(time.Second)
+		svc.events[0].UpdatedAt = svc.clock
+	}
+
+	path := "/caldav/7/events/planning-42@example.org.ics"
+	ifMatch := map[string]string{"If-Match": etag}
+	updated := strings.Replace(fixture(t, "thunderbird_put.ics"), "Sprint planning", "Sprint review", 1)
+	if rec := davRequest(t, router, http.MethodPut, path, updated, ifMatch); rec.Code != 412 {
+		t.Errorf("PUT = %d, want 412", rec.Code)
+	}
+	if rec := davRequest(t, router, http.MethodDelete, path, "", ifMatch); rec.Code != 412 {
+		t.Errorf("DELETE = %d, want 412", rec.Code)
+	}
+
+	events, _ := svc.ListEvents(context.Background(), 7)
+	if len(events) != 1 || events[0].Title != "Sprint planning" {
+		t.Errorf("events = %+v, want the event unchanged", events)
+	}
+}
+
+func TestCalDAV_Reports(t *testing.T) {
+	t.Parallel()
+
+	router, svc := newCalDAVServer(t)
+	putFixture(t, router)
+
+	// An event created through the JSON API, with no client UID, outside
+	// the queried range.
+	_, _ = svc.PutEvent(context.Background(), &entity.Event{
+		UserID:   7,
+		Date:     time.Date(2026, time.August, 3, 0, 0, 0, 0, time.UTC),
+		Duration: 24 * time.Hour,
+		Title:    "Holiday",
+		Text:     "Holiday",
+	}, nil)
+
+	t.Run("CalendarQuery", func(t *testing.T) {
+		rec := davRequest(t, router, "REPORT", "/caldav/7/events/", fixture(t, "thunderbird_calendar_query.xml"),
+			map[string]string{"Depth": "1"})
+		ms := decodeMultistatus(t, rec)
+
+		if len(ms.Responses) != 1 || ms.Responses[0].Href != "/caldav/7/events/planning-42@example.org.ics" {
+			t.Fatalf("responses = %+v, want only the June event", ms.Responses)
+		}
+		if !strings.Contains(ms.Responses[0].Propstats[0].Prop.Inner, "getetag") {
+			t.Errorf("getetag missing: %s", rec.Body.String())
+		}
+	})
+
+	t.Run("Multiget", func(t *testing.T) {
+		rec := davRequest(t, router, "REPORT", "/caldav/7/events/", fixture(t, "thunderbird_multiget.xml"),
+			map[string]string{"Depth": "1"})
+		ms := decodeMultistatus(t, rec)
+
+		if len(ms.Responses) != 2 {
+			t.Fatalf("responses = %d, want 2", len(ms.Responses))
+		}
+		if inner := ms.Responses[0].Propstats[0].Prop.Inner; !strings.Contains(inner, "SUMMARY:Sprint planning") {
+			t.Errorf("calendar-data missing: %s", inner)
+		}
+		if !strings.Contains(ms.Responses[1].Status, "404") {
+			t.Errorf("missing href status = %q, want 404", ms.Responses[1].Status)
+		}
+	})
+
+	t.Run("SyncCollection", func(t *testing.T) {
+		body := fixture(t, "apple_sync_collection.xml")
+
+		ms := decodeMultistatus(t, davRequest(t, router, "REPORT", "/caldav/7/events/", body, nil))
+		if len(ms.Responses) != 2 || ms.SyncToken == "" {
+			t.Fatalf("initial sync = %d responses, token %q", len(ms.Responses), ms.SyncToken)
+		}
+		if ms.Responses[1].Href != "/caldav/7/events/2.ics" {
+			t.Errorf("UID-less event href = %q, want /caldav/7/events/2.ics", ms.Responses[1].Href)
+		}
+
+		current := strings.Replace(body, "<A:sync-token></A:sync-token>",
+			"<A:sync-token>"+ms.SyncToken+"</A:sync-token>", 1)
+		again := decodeMultistatus(t, davRequest(t, router, "REPORT", "/caldav/7/events/", current, nil))
+		if len(again.Responses) != 0 || again.SyncToken != ms.SyncToken {
+			t.Errorf("incremental sync = %d responses, token %q", len(again.Responses), again.SyncToken)
+		}
+
+		stale := strings.Replace(body, "<A:sync-token></A:sync-token>",
+			"<A:sync-token>urn:calendar-wbf:sync:0</A:sync-token>", 1)
+		rec := davRequest(t, router, "REPORT", "/caldav/7/events/", stale, nil)
+		if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "valid-sync-token") {
+			t.Errorf("stale token = %d %s, want 403 valid-sync-token", rec.Code, rec.Body.String())
+		}
+	})
+}
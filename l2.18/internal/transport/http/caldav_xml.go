@@ -0,0 +1,239 @@
+package httpt
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	_nsDAV          = "DAV:"
+	_nsCalDAV       = "urn:ietf:params:xml:ns:caldav"
+	_nsCalServer    = "http://calendarserver.org/ns/"
+	_xmlContentType = "application/xml; charset=utf-8"
+)
+
+type (
+	propfindRequest struct {
+		XMLName  xml.Name  `xml:"DAV: propfind"`
+		AllProp  *struct{} `xml:"DAV: allprop"`
+		PropName *struct{} `xml:"DAV: propname"`
+		Prop     propNames `xml:"DAV: prop"`
+	}
+
+	// reportRequest covers calendar-query, calendar-multiget and
+	// sync-collection; XMLName tells them apart.
+	reportRequest struct {
+		XMLName   xml.Name
+		Prop      propNames   `xml:"DAV: prop"`
+		Hrefs     []string    `xml:"DAV: href"`
+		Filter    *compFilter `xml:"urn:ietf:params:xml:ns:caldav filter>comp-filter"`
+		SyncToken string      `xml:"DAV: sync-token"`
+	}
+
+	compFilter struct {
+		Name        string       `xml:"name,attr"`
+		TimeRange   *timeRange   `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+		CompFilters []compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	}
+
+	timeRange struct {
+		Start string `xml:"start,attr"`
+		End   string `xml:"end,attr"`
+	}
+
+	// propNames lists the children of a DAV:prop element.
+	propNames []xml.Name
+
+	// davResource is a PROPFIND or REPORT target. Property values are
+	// rendered lazily, since calendar-data is only built when asked for.
+	davResource struct {
+		href  string
+		props map[xml.Name]func() string
+	}
+
+	davResponse struct {
+		href    string
+		found   []davProp
+		missing []xml.Name
+		status  int
+	}
+
+	davProp struct {
+		name  xml.Name
+		inner string
+	}
+)
+
+func (p *propNames) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			*p = append(*p, tok.Name)
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// decodeDAVBody decodes an XML request body into v. An empty body leaves v
+// untouched, which PROPFIND treats as allprop.
+func decodeDAVBody(body io.Reader, v any) (bool, error) {
+	err := xml.NewDecoder(body).Decode(v)
+	if err == io.EOF {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// response answers a DAV:propfind request for res.
+func (req *propfindRequest) response(res davResource) davResponse {
+	resp := davResponse{href: res.href}
+
+	switch {
+	case req.PropName != nil:
+		for name := range res.props {
+			resp.found = append(resp.found, davProp{name: name})
+		}
+	case req.AllProp != nil || len(req.Prop) == 0:
+		for name, value := range res.props {
+			// RFC 4791 9.6: calendar-data is not part of allprop.
+			if name.Space == _nsCalDAV && name.Local == "calendar-data" {
+				continue
+			}
+			resp.found = append(resp.found, davProp{name: name, inner: value()})
+		}
+	default:
+		return res.selected(req.Prop)
+	}
+
+	slices.SortFunc(resp.found, func(a, b davProp) int {
+		return strings.Compare(a.name.Space+a.name.Local, b.name.Space+b.name.Local)
+	})
+	return resp
+}
+
+func (res davResource) selected(names propNames) davResponse {
+	resp := davResponse{href: res.href}
+	for _, name := range names {
+		if value, ok := res.props[name]; ok {
+			resp.found = append(resp.found, davProp{name: name, inner: value()})
+		} else {
+			resp.missing = append(resp.missing, name)
+		}
+	}
+	return resp
+}
+
+func writeMultistatus(w http.ResponseWriter, responses []davResponse, syncToken string) {
+	var b strings.Builder
+
+	b.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+	b.WriteString(`<d:multistatus xmlns:d="DAV:" xmlns:c="` + _nsCalDAV + `" xmlns:cs="` + _nsCalServer + `">`)
+
+	for _, resp := range responses {
+		b.WriteString("<d:response><d:href>" + escapeXML(resp.href) + "</d:href>")
+
+		if resp.status != 0 {
+			b.WriteString(statusLine(resp.status))
+		}
+		if len(resp.found) > 0 {
+			b.WriteString("<d:propstat><d:prop>")
+			for _, prop := range resp.found {
+				writeElement(&b, prop.name, prop.inner)
+			}
+			b.WriteString("</d:prop>" + statusLine(http.StatusOK) + "</d:propstat>")
+		}
+		if len(resp.missing) > 0 {
+			b.WriteString("<d:propstat><d:prop>")
+			for _, name := range resp.missing {
+				writeElement(&b, name, "")
+			}
+			b.WriteString("</d:prop>" + statusLine(http.StatusNotFound) + "</d:propstat>")
+		}
+
+		b.WriteString("</d:response>")
+	}
+
+	if syncToken != "" {
+		b.WriteString("<d:sync-token>" + escapeXML(syncToken) + "</d:sync-token>")
+	}
+	b.WriteString("</d:multistatus>")
+
+	w.Header().Set("Content-Type", _xmlContentType)
+	w.WriteHeader(http.StatusMultiStatus)
+	_, _ = io.WriteString(w, b.String())
+}
+
+// writeDAVError writes a DAV:error body naming the failed precondition.
+func writeDAVError(w http.ResponseWriter, status int, condition xml.Name) {
+	var b strings.Builder
+
+	b.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+	b.WriteString(`<d:error xmlns:d="DAV:" xmlns:c="` + _nsCalDAV + `">`)
+	writeElement(&b, condition, "")
+	b.WriteString("</d:error>")
+
+	w.Header().Set("Content-Type", _xmlContentType)
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, b.String())
+}
+
+// writeElement writes <prefix:local>inner</prefix:local>, declaring the
+// namespace inline when it has no well-known prefix.
+func writeElement(b *strings.Builder, name xml.Name, inner string) {
+	tag, decl := name.Local, ""
+	if prefix := davPrefix(name.Space); prefix != "" {
+		tag = prefix + ":" + name.Local
+	} else if name.Space != "" {
+		tag = "x:" + name.Local
+		decl = ` xmlns:x="` + escapeXML(name.Space) + `"`
+	}
+
+	if inner == "" {
+		b.WriteString("<" + tag + decl + "/>")
+		return
+	}
+	b.WriteString("<" + tag + decl + ">" + inner + "</" + tag + ">")
+}
+
+func davPrefix(space string) string {
+	switch space {
+	case _nsDAV:
+		return "d"
+	case _nsCalDAV:
+		return "c"
+	case _nsCalServer:
+		return "cs"
+	}
+	return ""
+}
+
+func statusLine(status int) string {
+	return fmt.Sprintf("<d:status>HTTP/1.1 %d %s</d:status>", status, http.StatusText(status))
+}
+
+func hrefXML(href string) string {
+	return "<d:href>" + escapeXML(href) + "</d:href>"
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func davName(space, local string) xml.Name {
+	return xml.Name{Space: space, Local: local}
+}
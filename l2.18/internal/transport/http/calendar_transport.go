@@ -1,6 +1,12 @@
 package httpt
 
 import (
+	"sync/atomic"
+
+	"calendar-wbf/internal/config"
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/cache"
+	"calendar-wbf/pkg/graphql"
 	"calendar-wbf/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -8,19 +14,49 @@ import (
 
 type CalendarHandler struct {
 	svc    EventService
+	tags   TagService
 	log    logger.Logger
 	router *gin.Engine
+
+	attachments  AttachmentService
+	build        VersionResponse
+	caldav       *CalDAV
+	cache        cache.Cache[string, *entity.Event]
+	config       *config.Store
+	digests      DigestService
+	graphql      *graphql.Schema
+	idempotency  *IdempotencyStore
+	logRing      *logger.Ring
+	privacy      PrivacyService
+	schedules    ScheduleService
+	shuttingDown atomic.Bool
+	templates    TemplateService
 }
 
 func NewCalendarHandler(
 	svc EventService,
+	tags TagService,
 	log logger.Logger,
+	opts ...Option,
 ) *CalendarHandler {
 	h := &CalendarHandler{
-		svc: svc,
-		log: log,
+		svc:   svc,
+		tags:  tags,
+		log:   log,
+		build: newBuildInfo("", ""),
+	}
+
+	for _, opt := range opts {
+		opt(h)
 	}
 
+	schema, err := h.newGraphQLSchema()
+	if err != nil {
+		// The schema is fixed at compile time, so this is a programming error.
+		panic(err)
+	}
+	h.graphql = schema
+
 	router := gin.New()
 
 	router.Use(h.requestIDMiddleware())
@@ -40,3 +76,9 @@ func NewCalendarHandler(
 func (h *CalendarHandler) Engine() *gin.Engine {
 	return h.router
 }
+
+// MarkShuttingDown makes the readiness probe fail so load balancers stop
+// routing new requests while in-flight ones drain.
+func (h *CalendarHandler) MarkShuttingDown() {
+	h.shuttingDown.Store(true)
+}
@@ -0,0 +1,141 @@
+package httpt
+
+import (
+	"context"
+	"net/http"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DigestService interface {
+	SaveSubscription(ctx context.Context, sub *entity.DigestSubscription) (*entity.DigestSubscription, error)
+	DeleteSubscription(ctx context.Context, userID uint64) error
+	PreviewDigest(
+		ctx context.Context,
+		userID uint64,
+		period entity.DigestPeriod,
+		format entity.DigestFormat,
+		timezone string,
+	) (*entity.Digest, error)
+}
+
+// @Summary Подписаться на дайджест
+// @Description Ежедневная или еженедельная повестка в заданное локальное время пользователя
+// @Tags Digest
+// @Accept json
+// @Produce json
+// @Param request body SaveDigestRequest true "Настройки дайджеста"
+// @Success 200 {object} entity.DigestSubscription
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 500 {object} httpt.ErrorResponse
+// @Router /save_digest [post]
+func (h *CalendarHandler) saveDigestHandler(c *gin.Context) {
+	const op = "transport.saveDigestHandler"
+	log := h.log.Ctx(c.Request.Context())
+
+	if h.digests == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Digests are not configured"})
+		return
+	}
+
+	var req SaveDigestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleBindError(c, err, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	sub, err := h.digests.SaveSubscription(ctx, req.toSubscription())
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "digest subscription saved successfully",
+		logger.Uint64("user_id", sub.UserID),
+	)
+
+	c.JSON(http.StatusOK, sub)
+}
+
+// @Summary Отписаться от дайджеста
+// @Tags Digest
+// @Accept json
+// @Produce json
+// @Param request body DeleteDigestRequest true "UserID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 404 {object} httpt.ErrorResponse
+// @Router /delete_digest [post]
+func (h *CalendarHandler) deleteDigestHandler(c *gin.Context) {
+	const op = "transport.deleteDigestHandler"
+
+	if h.digests == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Digests are not configured"})
+		return
+	}
+
+	var req DeleteDigestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleBindError(c, err, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	if err := h.digests.DeleteSubscription(ctx, req.UserID); err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"result": "Digest subscription deleted successfully"})
+}
+
+// @Summary Предпросмотр дайджеста
+// @Description Формирует повестку на сегодня или на неделю в формате text, html или markdown.
+// @Description С raw=true возвращает только тело дайджеста с соответствующим Content-Type.
+// @Tags Digest
+// @Accept json
+// @Produce json,plain,html
+// @Param request body DigestPreviewRequest true "Параметры дайджеста"
+// @Success 200 {object} entity.Digest
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 500 {object} httpt.ErrorResponse
+// @Router /digest_preview [post]
+func (h *CalendarHandler) digestPreviewHandler(c *gin.Context) {
+	const op = "transport.digestPreviewHandler"
+
+	if h.digests == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Digests are not configured"})
+		return
+	}
+
+	var req DigestPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleBindError(c, err, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	digest, err := h.digests.PreviewDigest(ctx, req.UserID,
+		entity.DigestPeriod(req.Period), entity.DigestFormat(req.Format), req.Timezone)
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+
+	if req.Raw {
+		c.Data(http.StatusOK, digest.ContentType, []byte(digest.Body))
+		return
+	}
+
+	c.JSON(http.StatusOK, digest)
+}
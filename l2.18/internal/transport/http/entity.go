@@ -2,9 +2,14 @@
 package httpt
 
 import (
+	"mime/multipart"
+	"strings"
 	"time"
 
+	"calendar-wbf/internal/config"
 	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/cache"
+	"calendar-wbf/pkg/logger"
 )
 
 // swagger: model ErrorResponse
@@ -22,18 +27,40 @@ type EventsResponse struct {
 
 // swagger: model CreateEventRequest
 type CreateEventRequest struct {
-	UserID uint64    `json:"user_id" binding:"required,gt=0"`
-	Date   time.Time `json:"date"    binding:"required"`
-	Title  string    `json:"title"   binding:"required"`
-	Text   string    `json:"text"`
+	UserID   uint64          `json:"user_id"  binding:"required,gt=0"`
+	Date     time.Time       `json:"date"     binding:"required"`
+	Duration entity.Duration `json:"duration" binding:"gte=0"`
+	Title    string          `json:"title"    binding:"required"`
+	Text     string          `json:"text"`
+	Tags     []string        `json:"tags"     binding:"max=10"`
+	Color    string          `json:"color"    binding:"omitempty,hexcolor"`
+}
+
+// swagger: model QuickAddRequest
+type QuickAddRequest struct {
+	UserID   uint64 `json:"user_id"  binding:"required,gt=0"`
+	Text     string `json:"text"     binding:"required,max=500"`
+	Timezone string `json:"timezone" binding:"omitempty,timezone"`
+	Create   bool   `json:"create"`
 }
 
+// swagger: model QuickAddResponse
+type QuickAddResponse entity.QuickAdd
+
 // swagger: model UpdateEventRequest
 type UpdateEventRequest struct {
-	UserID uint64    `json:"user_id" binding:"required,gt=0"`
-	Date   time.Time `json:"date"    binding:"required"`
-	Title  string    `json:"title"   binding:"required"`
-	Text   string    `json:"text"`
+	UserID   uint64          `json:"user_id"  binding:"required,gt=0"`
+	Date     time.Time       `json:"date"     binding:"required"`
+	Duration entity.Duration `json:"duration" binding:"gte=0"`
+	Title    string          `json:"title"    binding:"required"`
+	Text     string          `json:"text"`
+	Tags     []string        `json:"tags"     binding:"max=10"`
+	Color    string          `json:"color"    binding:"omitempty,hexcolor"`
+}
+
+// swagger: model GetEventRequest
+type GetEventRequest struct {
+	UserID uint64 `json:"user_id" binding:"required,gt=0"`
 }
 
 // swagger: model DeleteEventRequest
@@ -41,21 +68,301 @@ type DeleteEventRequest struct {
 	UserID uint64 `json:"user_id" binding:"required,gt=0"`
 }
 
+// swagger: model TagFilterRequest
+type TagFilterRequest struct {
+	IncludeTags []string `json:"include_tags"`
+	ExcludeTags []string `json:"exclude_tags"`
+}
+
 // swagger: model GetEventForDayRequest
 type GetEventForDayRequest struct {
+	TagFilterRequest
+
 	UserID uint64    `json:"user_id" binding:"required,gt=0"`
 	Date   time.Time `json:"date"    binding:"required"`
 }
 
 // swagger: model GetEventForWeekRequest
 type GetEventForWeekRequest struct {
+	TagFilterRequest
+
 	UserID    uint64    `json:"user_id"    binding:"required,gt=0"`
 	StartDate time.Time `json:"start_date" binding:"required"`
 }
 
 // swagger: model GetEventForMonthRequest
 type GetEventForMonthRequest struct {
+	TagFilterRequest
+
 	UserID uint64 `json:"user_id" binding:"required,gt=0"`
 	Year   int    `json:"year"    binding:"required"`
 	Month  int    `json:"month"   binding:"required"`
 }
+
+// swagger: model UploadAttachmentRequest
+type UploadAttachmentRequest struct {
+	UserID uint64                `form:"user_id" binding:"required,gt=0"`
+	File   *multipart.FileHeader `form:"file"    binding:"required"`
+}
+
+// swagger: model AttachmentsRequest
+type AttachmentsRequest struct {
+	UserID uint64 `json:"user_id" form:"user_id" binding:"required,gt=0"`
+}
+
+// swagger: model AttachmentsResponse
+type AttachmentsResponse struct {
+	Result []*entity.Attachment `json:"result"`
+}
+
+// swagger: model SaveTemplateRequest
+type SaveTemplateRequest struct {
+	ID       uint64          `json:"id"`
+	UserID   uint64          `json:"user_id"  binding:"required,gt=0"`
+	Name     string          `json:"name"     binding:"required,max=50"`
+	Shared   bool            `json:"shared"`
+	Title    string          `json:"title"    binding:"required"`
+	Text     string          `json:"text"     binding:"required"`
+	Duration entity.Duration `json:"duration" binding:"gte=0"`
+	Tags     []string        `json:"tags"     binding:"max=10"`
+	Color    string          `json:"color"    binding:"omitempty,hexcolor"`
+}
+
+// swagger: model TemplateRequest
+type TemplateRequest struct {
+	UserID uint64 `json:"user_id" binding:"required,gt=0"`
+}
+
+// swagger: model TemplatesResponse
+type TemplatesResponse struct {
+	Result []*entity.Template `json:"result"`
+}
+
+// swagger: model CreateFromTemplateRequest
+type CreateFromTemplateRequest struct {
+	UserID   uint64            `json:"user_id"  binding:"required,gt=0"`
+	Date     time.Time         `json:"date"     binding:"required"`
+	Duration *entity.Duration  `json:"duration" binding:"omitempty,gte=0"`
+	Title    *string           `json:"title"    binding:"omitempty,min=1"`
+	Text     *string           `json:"text"     binding:"omitempty,min=1"`
+	Tags     []string          `json:"tags"     binding:"omitempty,max=10"`
+	Color    *string           `json:"color"    binding:"omitempty,hexcolor"`
+	Vars     map[string]string `json:"vars"     binding:"max=20"`
+}
+
+// swagger: model CreateFromTemplateResponse
+type CreateFromTemplateResponse struct {
+	Event    *entity.Event `json:"event"`
+	Warnings []string      `json:"warnings,omitempty"`
+}
+
+// swagger: model SaveTagRequest
+type SaveTagRequest struct {
+	UserID uint64 `json:"user_id" binding:"required,gt=0"`
+	Name   string `json:"name"    binding:"required,max=30"`
+	Color  string `json:"color"   binding:"omitempty,hexcolor"`
+}
+
+// swagger: model DeleteTagRequest
+type DeleteTagRequest struct {
+	UserID uint64 `json:"user_id" binding:"required,gt=0"`
+	Name   string `json:"name"    binding:"required"`
+}
+
+// swagger: model ListTagsRequest
+type ListTagsRequest struct {
+	UserID uint64 `json:"user_id" binding:"required,gt=0"`
+}
+
+// swagger: model TagsResponse
+type TagsResponse struct {
+	Result []*entity.Tag `json:"result"`
+}
+
+// swagger: model SaveDigestRequest
+type SaveDigestRequest struct {
+	UserID   uint64 `json:"user_id"  binding:"required,gt=0"`
+	Email    string `json:"email"    binding:"omitempty,email"`
+	Timezone string `json:"timezone" binding:"omitempty,timezone"`
+	SendAt   string `json:"send_at"  binding:"omitempty,datetime=15:04"`
+	Weekday  string `json:"weekday"  binding:"omitempty,oneof=monday tuesday wednesday thursday friday saturday sunday"`
+	Period   string `json:"period"   binding:"omitempty,oneof=daily weekly"`
+	Format   string `json:"format"   binding:"omitempty,oneof=text html markdown"`
+}
+
+// swagger: model SaveWorkScheduleRequest
+type SaveWorkScheduleRequest struct {
+	UserID    uint64   `json:"user_id"    binding:"required,gt=0"`
+	Country   string   `json:"country"    binding:"omitempty,len=2,alpha"`
+	Timezone  string   `json:"timezone"   binding:"omitempty,timezone"`
+	WorkDays  []string `json:"work_days"  binding:"omitempty,max=7,dive,oneof=monday tuesday wednesday thursday friday saturday sunday"`
+	WorkStart string   `json:"work_start" binding:"omitempty,datetime=15:04"`
+	WorkEnd   string   `json:"work_end"   binding:"omitempty,datetime=15:04"`
+	DaysOff   []string `json:"days_off"   binding:"max=366,dive,datetime=2006-01-02"`
+}
+
+// swagger: model WorkScheduleRequest
+type WorkScheduleRequest struct {
+	UserID uint64 `json:"user_id" binding:"required,gt=0"`
+}
+
+// swagger: model HolidaysRequest
+type HolidaysRequest struct {
+	Country string `json:"country" binding:"required,len=2,alpha"`
+	Year    int    `json:"year"    binding:"required,gte=1900,lte=2100"`
+}
+
+// swagger: model DeleteDigestRequest
+type DeleteDigestRequest struct {
+	UserID uint64 `json:"user_id" binding:"required,gt=0"`
+}
+
+// swagger: model DigestPreviewRequest
+type DigestPreviewRequest struct {
+	UserID   uint64 `json:"user_id"  binding:"required,gt=0"`
+	Timezone string `json:"timezone" binding:"omitempty,timezone"`
+	Period   string `json:"period"   binding:"omitempty,oneof=daily weekly"`
+	Format   string `json:"format"   binding:"omitempty,oneof=text html markdown"`
+	Raw      bool   `json:"raw"`
+}
+
+func (r SaveDigestRequest) toSubscription() *entity.DigestSubscription {
+	sub := &entity.DigestSubscription{
+		UserID:   r.UserID,
+		Email:    r.Email,
+		Timezone: r.Timezone,
+		SendAt:   r.SendAt,
+		Weekday:  time.Monday,
+		Period:   entity.DigestPeriod(r.Period),
+		Format:   entity.DigestFormat(r.Format),
+	}
+
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), r.Weekday) {
+			sub.Weekday = day
+		}
+	}
+
+	return sub
+}
+
+func (r SaveWorkScheduleRequest) toSchedule() *entity.WorkSchedule {
+	schedule := &entity.WorkSchedule{
+		UserID:    r.UserID,
+		Country:   r.Country,
+		Timezone:  r.Timezone,
+		WorkStart: r.WorkStart,
+		WorkEnd:   r.WorkEnd,
+		DaysOff:   r.DaysOff,
+	}
+
+	for _, name := range r.WorkDays {
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.EqualFold(day.String(), name) {
+				schedule.WorkDays = append(schedule.WorkDays, day)
+			}
+		}
+	}
+
+	return schedule
+}
+
+func (r SaveTemplateRequest) toTemplate() *entity.Template {
+	return &entity.Template{
+		ID:       r.ID,
+		UserID:   r.UserID,
+		Name:     r.Name,
+		Shared:   r.Shared,
+		Title:    r.Title,
+		Text:     r.Text,
+		Duration: time.Duration(r.Duration),
+		Tags:     r.Tags,
+		Color:    r.Color,
+	}
+}
+
+func (r CreateFromTemplateRequest) toOverrides() entity.TemplateOverrides {
+	return entity.TemplateOverrides{
+		Duration: (*time.Duration)(r.Duration),
+		Title:    r.Title,
+		Text:     r.Text,
+		Tags:     r.Tags,
+		Color:    r.Color,
+		Vars:     r.Vars,
+	}
+}
+
+func (r TagFilterRequest) toFilter() entity.TagFilter {
+	return entity.TagFilter{
+		Include: r.IncludeTags,
+		Exclude: r.ExcludeTags,
+	}
+}
+
+// swagger: model ProbeResponse
+type ProbeResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// swagger: model VersionResponse
+type VersionResponse struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	GoVersion string `json:"go_version"`
+}
+
+// swagger: model CacheInfoResponse
+type CacheInfoResponse struct {
+	Len         int     `json:"len"`
+	Capacity    int     `json:"capacity"`
+	Hits        uint64  `json:"hits"`
+	Misses      uint64  `json:"misses"`
+	HitRatio    float64 `json:"hit_ratio"`
+	Evictions   uint64  `json:"evictions"`
+	Expirations uint64  `json:"expirations"`
+	Deletions   uint64  `json:"deletions"`
+}
+
+func newCacheInfoResponse(stats cache.Stats) CacheInfoResponse {
+	return CacheInfoResponse{
+		Len:         stats.Len,
+		Capacity:    stats.Capacity,
+		Hits:        stats.Hits,
+		Misses:      stats.Misses,
+		HitRatio:    stats.HitRatio(),
+		Evictions:   stats.Evictions,
+		Expirations: stats.Expirations,
+		Deletions:   stats.Deletions,
+	}
+}
+
+// swagger: model ConfigResponse
+type ConfigResponse struct {
+	Config         *config.Config `json:"config"`
+	PendingRestart []string       `json:"pending_restart"`
+}
+
+// swagger: model LogLevelRequest
+type LogLevelRequest struct {
+	Level     string `json:"level"     binding:"required,oneof=debug info warn error"`
+	Component string `json:"component"`
+}
+
+// swagger: model LogLevelResponse
+type LogLevelResponse struct {
+	Level      string            `json:"level"`
+	Components map[string]string `json:"components,omitempty"`
+}
+
+// swagger: model LogsRequest
+type LogsRequest struct {
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=10000"`
+	Level string `form:"level" binding:"omitempty,oneof=debug info warn error"`
+}
+
+// swagger: model LogsResponse
+type LogsResponse struct {
+	Entries []logger.RingEntry `json:"entries"`
+}
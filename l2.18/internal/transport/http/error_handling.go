@@ -21,30 +21,85 @@ func (h *CalendarHandler) handleServiceError(c *gin.Context, err error, op strin
 	)
 
 	switch {
-	case errors.Is(err, entity.ErrInvalidData):
-		c.JSON(
-			http.StatusBadRequest,
-			gin.H{"error": "Invalid event data. Check title or text."},
-		)
 	case errors.Is(err, entity.ErrInvalidUserID):
 		log.LogAttrs(c.Request.Context(), logger.WarnLevel, "event not found",
 			logger.String("event_id", c.Param("event_id")),
 			logger.String("client_ip", c.ClientIP()),
 		)
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 	case errors.Is(err, context.DeadlineExceeded):
 		log.LogAttrs(c.Request.Context(), logger.WarnLevel, "request timeout",
 			logger.String("path", c.Request.URL.Path),
 			logger.String("client_ip", c.ClientIP()),
 		)
-		c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Request timed out"})
-	default:
+	}
+
+	status, message := serviceErrorStatus(err)
+	if status == http.StatusInternalServerError {
 		log.LogAttrs(c.Request.Context(), logger.ErrorLevel, "internal server error",
 			logger.Any("error", err),
 			logger.String("path", c.Request.URL.Path),
 			logger.String("client_ip", c.ClientIP()),
 		)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal service error"})
+	}
+	c.JSON(status, gin.H{"error": message})
+}
+
+// serviceErrorStatus maps a service error to the status and message the API
+// reports for it.
+func serviceErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidData):
+		return http.StatusBadRequest, "Invalid event data. Check title or text."
+	case errors.Is(err, entity.ErrInvalidTag), errors.Is(err, entity.ErrInvalidColor):
+		return http.StatusBadRequest, "Invalid tag or color"
+	case errors.Is(err, entity.ErrInvalidTimezone):
+		return http.StatusBadRequest, "Invalid timezone"
+	case errors.Is(err, entity.ErrUnparsableText):
+		return http.StatusBadRequest, "Could not recognize event title or time in text"
+	case errors.Is(err, entity.ErrInvalidDigest):
+		return http.StatusBadRequest, "Invalid digest settings"
+	case errors.Is(err, entity.ErrInvalidTenant):
+		return http.StatusBadRequest, "Invalid tenant"
+	case errors.Is(err, entity.ErrQuotaExceeded):
+		return http.StatusForbidden, "Event quota of the tenant exceeded"
+	case errors.Is(err, entity.ErrDigestNotFound):
+		return http.StatusNotFound, "digest subscription not found"
+	case errors.Is(err, entity.ErrInvalidSchedule):
+		return http.StatusBadRequest, "Invalid work schedule"
+	case errors.Is(err, entity.ErrScheduleNotFound):
+		return http.StatusNotFound, "work schedule not found"
+	case errors.Is(err, entity.ErrHolidaysNotCovered):
+		return http.StatusNotFound, "No holiday data for this year"
+	case errors.Is(err, entity.ErrAttachmentTooLarge):
+		return http.StatusRequestEntityTooLarge, "Attachment is too large"
+	case errors.Is(err, entity.ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType, "Attachment content type is not allowed"
+	case errors.Is(err, entity.ErrTooManyAttachments):
+		return http.StatusConflict, "Event has too many attachments"
+	case errors.Is(err, entity.ErrAttachmentNotFound):
+		return http.StatusNotFound, "attachment not found"
+	case errors.Is(err, entity.ErrInvalidTemplate):
+		return http.StatusBadRequest, "Invalid template or missing placeholder values"
+	case errors.Is(err, entity.ErrTemplateNotFound):
+		return http.StatusNotFound, "template not found"
+	case errors.Is(err, entity.ErrExportNotFound):
+		return http.StatusNotFound, "export not found"
+	case errors.Is(err, entity.ErrExportNotReady):
+		return http.StatusConflict, "Export is not ready"
+	case errors.Is(err, entity.ErrDuplicateEvent):
+		return http.StatusConflict, "A request with this Idempotency-Key is still in progress"
+	case errors.Is(err, entity.ErrIdempotencyKeyReused):
+		return http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request"
+	case errors.Is(err, entity.ErrEventNotFound):
+		return http.StatusNotFound, "event not found"
+	case errors.Is(err, entity.ErrTagNotFound):
+		return http.StatusNotFound, "tag not found"
+	case errors.Is(err, entity.ErrInvalidUserID):
+		return http.StatusNotFound, "user not found"
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, "Request timed out"
+	default:
+		return http.StatusInternalServerError, "Internal service error"
 	}
 }
 
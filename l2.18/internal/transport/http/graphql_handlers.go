@@ -0,0 +1,134 @@
+package httpt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"calendar-wbf/internal/config"
+	"calendar-wbf/pkg/graphql"
+	"calendar-wbf/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// _graphqlTimeout bounds a whole request, which may call several
+	// services one after another.
+	_graphqlTimeout = 2 * time.Second
+	_maxGraphQLBody = 1 << 20
+)
+
+var errGraphQLQueryMissing = errors.New("query missing")
+
+// @Summary GraphQL
+// @Description Запросы событий на день, неделю, месяц и за период и мутации событий одним запросом с выбором полей.
+// @Description Глубина и сложность запроса ограничены GRAPHQL_MAX_DEPTH и GRAPHQL_MAX_COMPLEXITY;
+// @Description через GET доступны только запросы без мутаций. Схема: GET /graphql/schema
+// @Tags GraphQL
+// @Accept json
+// @Produce json
+// @Param request body graphql.Request true "query, operationName и variables"
+// @Success 200 {object} graphql.Response
+// @Failure 400 {object} graphql.Response
+// @Failure 404 {object} httpt.ErrorResponse
+// @Router /graphql [post]
+func (h *CalendarHandler) graphqlHandler(c *gin.Context) {
+	const op = "transport.graphqlHandler"
+	log := h.log.Ctx(c.Request.Context())
+
+	cfg, enabled := h.graphqlConfig()
+	if !enabled {
+		c.JSON(http.StatusNotFound, gin.H{"error": "GraphQL is disabled"})
+		return
+	}
+
+	req, err := bindGraphQLRequest(c)
+	if err != nil {
+		h.handleBindError(c, err, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _graphqlTimeout)
+	defer cancel()
+
+	resp := h.graphql.Execute(withGraphQLState(ctx), req, graphql.Options{
+		MaxDepth:      cfg.MaxDepth,
+		MaxComplexity: cfg.MaxComplexity,
+		QueryOnly:     c.Request.Method == http.MethodGet,
+	})
+
+	status := http.StatusOK
+	if !resp.Executed() {
+		status = http.StatusBadRequest
+		log.LogAttrs(ctx, logger.WarnLevel, "graphql request rejected",
+			logger.String("op", op),
+			logger.String("operation", req.OperationName),
+			logger.String("error", resp.Errors[0].Message),
+		)
+	} else {
+		log.LogAttrs(ctx, logger.InfoLevel, "graphql request executed",
+			logger.String("op", op),
+			logger.String("operation", req.OperationName),
+			logger.Int("errors", len(resp.Errors)),
+		)
+	}
+
+	c.JSON(status, resp)
+}
+
+// @Summary Схема GraphQL
+// @Description Схема в SDL для генераторов клиентского кода и редакторов
+// @Tags GraphQL
+// @Produce plain
+// @Success 200 {string} string "Схема"
+// @Failure 404 {object} httpt.ErrorResponse
+// @Router /graphql/schema [get]
+func (h *CalendarHandler) graphqlSchemaHandler(c *gin.Context) {
+	if _, enabled := h.graphqlConfig(); !enabled {
+		c.JSON(http.StatusNotFound, gin.H{"error": "GraphQL is disabled"})
+		return
+	}
+	c.String(http.StatusOK, h.graphql.SDL())
+}
+
+// graphqlConfig reads the settings per request, so GRAPHQL_* changes apply
+// on reload.
+func (h *CalendarHandler) graphqlConfig() (config.GraphQL, bool) {
+	if h.config == nil {
+		return config.GraphQL{}, true
+	}
+	cfg := h.config.Load().GraphQL
+	return cfg, cfg.Enabled
+}
+
+// bindGraphQLRequest reads a request from the JSON body of a POST or the
+// query string of a GET, where variables are a JSON object.
+func bindGraphQLRequest(c *gin.Context) (graphql.Request, error) {
+	var req graphql.Request
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			dec := json.NewDecoder(strings.NewReader(variables))
+			dec.UseNumber()
+			if err := dec.Decode(&req.Variables); err != nil {
+				return req, err
+			}
+		}
+	} else {
+		dec := json.NewDecoder(http.MaxBytesReader(c.Writer, c.Request.Body, _maxGraphQLBody))
+		dec.UseNumber()
+		if err := dec.Decode(&req); err != nil {
+			return req, err
+		}
+	}
+
+	if req.Query == "" {
+		return req, errGraphQLQueryMissing
+	}
+	return req, nil
+}
@@ -0,0 +1,724 @@
+package httpt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/graphql"
+	"calendar-wbf/pkg/logger"
+)
+
+const (
+	// _attachmentsCost weighs the attachments of an event, which are listed
+	// from the store once per event.
+	_attachmentsCost   = 5
+	_maxQuickAddLength = 500
+)
+
+type (
+	graphqlStateKey struct{}
+
+	// graphqlState memoizes lookups shared by the fields of one request, such
+	// as the tag colors of a user. Fields are resolved one at a time, so it
+	// needs no locking.
+	graphqlState struct {
+		tags map[uint64]map[string]*entity.Tag
+	}
+
+	eventResult struct {
+		Event    *entity.Event
+		Warnings []string
+	}
+)
+
+var (
+	timeScalar = &graphql.Scalar{
+		Name:        "Time",
+		Description: "An RFC 3339 timestamp. Inputs may also be a date, YYYY-MM-DD, taken as UTC midnight.",
+		Serialize: func(v any) (any, error) {
+			t, ok := v.(time.Time)
+			if !ok {
+				return nil, fmt.Errorf("Time cannot represent %T", v)
+			}
+			if t.IsZero() {
+				return nil, nil
+			}
+			return t.Format(time.RFC3339Nano), nil
+		},
+		ParseValue: func(v any) (any, error) {
+			s, ok := v.(string)
+			if !ok {
+				return nil, errors.New("expected an RFC 3339 timestamp")
+			}
+			if t, err := time.Parse(time.DateOnly, s); err == nil {
+				return t, nil
+			}
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return nil, errors.New("expected an RFC 3339 timestamp")
+			}
+			return t, nil
+		},
+	}
+
+	durationScalar = &graphql.Scalar{
+		Name:        "Duration",
+		Description: "A duration such as \"1h30m\".",
+		Serialize: func(v any) (any, error) {
+			d, ok := v.(time.Duration)
+			if !ok {
+				return nil, fmt.Errorf("Duration cannot represent %T", v)
+			}
+			return d.String(), nil
+		},
+		ParseValue: func(v any) (any, error) {
+			s, ok := v.(string)
+			if !ok {
+				return nil, errors.New("expected a duration such as \"1h30m\"")
+			}
+			d, err := time.ParseDuration(s)
+			if err != nil || d < 0 {
+				return nil, errors.New("expected a non-negative duration such as \"1h30m\"")
+			}
+			return d, nil
+		},
+	}
+)
+
+// newGraphQLSchema describes the events API for /graphql. Resolvers call the
+// same services as the REST handlers and map their errors the same way.
+func (h *CalendarHandler) newGraphQLSchema() (*graphql.Schema, error) {
+	nonNull := func(t graphql.Type) graphql.Type { return &graphql.NonNull{Of: t} }
+	listOf := func(t graphql.Type) graphql.Type { return nonNull(&graphql.List{Of: nonNull(t)}) }
+
+	tagType := &graphql.Object{
+		Name: "Tag",
+		Fields: []*graphql.Field{
+			{Name: "name", Type: nonNull(graphql.String)},
+			{
+				Name:    "color",
+				Type:    graphql.String,
+				Resolve: optionalString(func(t *entity.Tag) string { return t.Color }),
+			},
+		},
+	}
+
+	attachmentType := &graphql.Object{
+		Name: "Attachment",
+		Fields: []*graphql.Field{
+			{Name: "id", Type: nonNull(graphql.ID)},
+			{Name: "name", Type: nonNull(graphql.String)},
+			{Name: "contentType", Type: nonNull(graphql.String)},
+			{Name: "size", Type: nonNull(graphql.Int)},
+			{Name: "sha256", Type: nonNull(graphql.String)},
+			{Name: "createdAt", Type: nonNull(timeScalar)},
+			{
+				Name:        "url",
+				Description: "Where the content is downloaded from.",
+				Type:        nonNull(graphql.String),
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					a := p.Source.(*entity.Attachment)
+					return fmt.Sprintf("/download_attachment/%d/%s?user_id=%d", a.EventID, a.ID, a.UserID), nil
+				},
+			},
+		},
+	}
+
+	attendeeType := &graphql.Object{
+		Name:        "Attendee",
+		Description: "A participant of an event, as set by calendar clients over CalDAV.",
+		Fields: []*graphql.Field{
+			{Name: "email", Type: nonNull(graphql.String)},
+			{
+				Name:    "name",
+				Type:    graphql.String,
+				Resolve: optionalString(func(a entity.Attendee) string { return a.Name }),
+			},
+			{
+				Name:        "status",
+				Description: "The participation status, e.g. accepted, declined, tentative or needs-action.",
+				Type:        graphql.String,
+				Resolve:     optionalString(func(a entity.Attendee) string { return a.Status }),
+			},
+		},
+	}
+
+	eventType := &graphql.Object{
+		Name: "Event",
+		Description: "An event of the calendar. Holidays and days off of the working calendar appear as " +
+			"read-only entries without an id.",
+		Fields: []*graphql.Field{
+			{
+				Name: "id",
+				Type: graphql.ID,
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					if id := p.Source.(*entity.Event).ID; id != 0 {
+						return id, nil
+					}
+					return nil, nil
+				},
+			},
+			{Name: "uid", Type: graphql.String, Resolve: optionalString(func(e *entity.Event) string { return e.UID })},
+			{Name: "userId", Type: nonNull(graphql.ID)},
+			{Name: "date", Type: nonNull(timeScalar)},
+			{
+				Name: "end",
+				Type: nonNull(timeScalar),
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					return p.Source.(*entity.Event).End(), nil
+				},
+			},
+			{Name: "duration", Type: nonNull(durationScalar)},
+			{
+				Name: "allDay",
+				Type: nonNull(graphql.Boolean),
+				Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+					return p.Source.(*entity.Event).IsAllDay(), nil
+				},
+			},
+			{Name: "title", Type: nonNull(graphql.String)},
+			{Name: "text", Type: nonNull(graphql.String)},
+			{
+				Name:        "textHtml",
+				Description: "The text rendered from Markdown.",
+				Type:        graphql.String,
+				Resolve:     optionalString(func(e *entity.Event) string { return e.TextHTML }),
+			},
+			{
+				Name:        "tags",
+				Description: "The tags of the event with the colors the user gave them.",
+				Type:        listOf(tagType),
+				Resolve:     h.resolveEventTags,
+			},
+			{
+				Name:    "color",
+				Type:    graphql.String,
+				Resolve: optionalString(func(e *entity.Event) string { return e.Color }),
+			},
+			{
+				Name:        "kind",
+				Description: "holiday or day_off for read-only entries, null for events.",
+				Type:        graphql.String,
+				Resolve:     optionalString(func(e *entity.Event) string { return string(e.Kind) }),
+			},
+			{Name: "readOnly", Type: nonNull(graphql.Boolean)},
+			{Name: "attendees", Type: listOf(attendeeType)},
+			{
+				Name:    "attachments",
+				Type:    listOf(attachmentType),
+				Cost:    _attachmentsCost,
+				Resolve: h.resolveEventAttachments,
+			},
+			{Name: "createdAt", Type: timeScalar},
+			{Name: "updatedAt", Type: timeScalar},
+		},
+	}
+
+	eventResultType := &graphql.Object{
+		Name: "EventResult",
+		Fields: []*graphql.Field{
+			{Name: "event", Type: nonNull(eventType)},
+			{
+				Name:        "warnings",
+				Description: "Why the event falls outside the working calendar of the user, if it does.",
+				Type:        listOf(graphql.String),
+			},
+		},
+	}
+
+	quickAddType := &graphql.Object{
+		Name: "QuickAddResult",
+		Fields: []*graphql.Field{
+			{Name: "event", Type: eventType},
+			{Name: "allDay", Type: nonNull(graphql.Boolean)},
+			{Name: "lang", Type: nonNull(graphql.String)},
+			{Name: "created", Type: nonNull(graphql.Boolean)},
+			{Name: "warnings", Type: listOf(graphql.String)},
+		},
+	}
+
+	eventInput := &graphql.InputObject{
+		Name: "EventInput",
+		Fields: []*graphql.InputValue{
+			{Name: "userId", Type: nonNull(graphql.ID)},
+			{Name: "date", Type: nonNull(timeScalar)},
+			{Name: "duration", Type: durationScalar, Default: time.Duration(0)},
+			{Name: "title", Type: nonNull(graphql.String)},
+			{Name: "text", Type: nonNull(graphql.String), Description: "Markdown."},
+			{Name: "tags", Type: &graphql.List{Of: nonNull(graphql.String)}},
+			{Name: "color", Type: graphql.String, Description: "A hex color such as \"#ff8800\"."},
+		},
+	}
+
+	userID := &graphql.InputValue{Name: "userId", Type: nonNull(graphql.ID)}
+	eventID := &graphql.InputValue{Name: "id", Type: nonNull(graphql.ID)}
+	filterArgs := []*graphql.InputValue{
+		{Name: "includeTags", Type: &graphql.List{Of: nonNull(graphql.String)}},
+		{Name: "excludeTags", Type: &graphql.List{Of: nonNull(graphql.String)}},
+	}
+	rangeArgs := func(args ...*graphql.InputValue) []*graphql.InputValue {
+		return append(append([]*graphql.InputValue{userID}, args...), filterArgs...)
+	}
+
+	query := &graphql.Object{
+		Name: "Query",
+		Fields: []*graphql.Field{
+			{
+				Name:    "event",
+				Type:    eventType,
+				Args:    []*graphql.InputValue{eventID, userID},
+				Resolve: h.resolveEvent,
+			},
+			{
+				Name:        "eventsForDay",
+				Description: "Events of the day of date, in the location of date.",
+				Type:        listOf(eventType),
+				Args:        rangeArgs(&graphql.InputValue{Name: "date", Type: nonNull(timeScalar)}),
+				Resolve:     h.resolveEventsForDay,
+			},
+			{
+				Name:        "eventsForWeek",
+				Description: "Events of the seven days from startDate.",
+				Type:        listOf(eventType),
+				Args:        rangeArgs(&graphql.InputValue{Name: "startDate", Type: nonNull(timeScalar)}),
+				Resolve:     h.resolveEventsForWeek,
+			},
+			{
+				Name: "eventsForMonth",
+				Type: listOf(eventType),
+				Args: rangeArgs(
+					&graphql.InputValue{Name: "year", Type: nonNull(graphql.Int)},
+					&graphql.InputValue{Name: "month", Type: nonNull(graphql.Int)},
+				),
+				Resolve: h.resolveEventsForMonth,
+			},
+			{
+				Name:        "eventsInRange",
+				Description: "Events from the day of from to the day of to, both included; at most a year.",
+				Type:        listOf(eventType),
+				Args: rangeArgs(
+					&graphql.InputValue{Name: "from", Type: nonNull(timeScalar)},
+					&graphql.InputValue{Name: "to", Type: nonNull(timeScalar)},
+				),
+				Resolve: h.resolveEventsInRange,
+			},
+			{
+				Name:    "tags",
+				Type:    listOf(tagType),
+				Args:    []*graphql.InputValue{userID},
+				Resolve: h.resolveTags,
+			},
+		},
+	}
+
+	mutation := &graphql.Object{
+		Name: "Mutation",
+		Fields: []*graphql.Field{
+			{
+				Name:    "createEvent",
+				Type:    nonNull(eventResultType),
+				Args:    []*graphql.InputValue{{Name: "input", Type: nonNull(eventInput)}},
+				Resolve: h.resolveCreateEvent,
+			},
+			{
+				Name:    "updateEvent",
+				Type:    nonNull(eventResultType),
+				Args:    []*graphql.InputValue{eventID, {Name: "input", Type: nonNull(eventInput)}},
+				Resolve: h.resolveUpdateEvent,
+			},
+			{
+				Name:    "deleteEvent",
+				Type:    nonNull(graphql.Boolean),
+				Args:    []*graphql.InputValue{eventID, userID},
+				Resolve: h.resolveDeleteEvent,
+			},
+			{
+				Name:        "quickAdd",
+				Description: "Recognizes an event in a phrase; without create only returns the draft.",
+				Type:        nonNull(quickAddType),
+				Args: []*graphql.InputValue{
+					userID,
+					{Name: "text", Type: nonNull(graphql.String)},
+					{Name: "timezone", Type: graphql.String},
+					{Name: "create", Type: graphql.Boolean, Default: false},
+				},
+				Resolve: h.resolveQuickAdd,
+			},
+		},
+	}
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+func (h *CalendarHandler) resolveEvent(ctx context.Context, p graphql.ResolveParams) (any, error) {
+	const op = "transport.graphql.event"
+
+	id, userID, err := eventArgs(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := h.svc.GetEvent(ctx, id, userID)
+	if err != nil {
+		return nil, h.graphqlServiceError(ctx, op, err)
+	}
+	return event, nil
+}
+
+func (h *CalendarHandler) resolveEventsForDay(ctx context.Context, p graphql.ResolveParams) (any, error) {
+	const op = "transport.graphql.eventsForDay"
+
+	userID, err := idArg(p.Args, "userId")
+	if err != nil {
+		return nil, err
+	}
+	date := p.Args["date"].(time.Time)
+	filter := filterArg(p.Args)
+
+	events, err := h.svc.GetEventsForDay(ctx, userID, date, filter)
+	if err != nil {
+		return nil, h.graphqlServiceError(ctx, op, err)
+	}
+	return h.withOverlay(ctx, userID, date, date, filter, events), nil
+}
+
+func (h *CalendarHandler) resolveEventsForWeek(ctx context.Context, p graphql.ResolveParams) (any, error) {
+	const op = "transport.graphql.eventsForWeek"
+
+	userID, err := idArg(p.Args, "userId")
+	if err != nil {
+		return nil, err
+	}
+	startDate := p.Args["startDate"].(time.Time)
+	filter := filterArg(p.Args)
+
+	events, err := h.svc.GetEventsForWeek(ctx, userID, startDate, filter)
+	if err != nil {
+		return nil, h.graphqlServiceError(ctx, op, err)
+	}
+	// nolint: mnd
+	return h.withOverlay(ctx, userID, startDate, startDate.AddDate(0, 0, 6), filter, events), nil
+}
+
+func (h *CalendarHandler) resolveEventsForMonth(ctx context.Context, p graphql.ResolveParams) (any, error) {
+	const op = "transport.graphql.eventsForMonth"
+
+	userID, err := idArg(p.Args, "userId")
+	if err != nil {
+		return nil, err
+	}
+	year, month := p.Args["year"].(int), p.Args["month"].(int)
+	filter := filterArg(p.Args)
+
+	events, err := h.svc.GetEventsForMonth(ctx, userID, year, month, filter)
+	if err != nil {
+		return nil, h.graphqlServiceError(ctx, op, err)
+	}
+	monthStart := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	return h.withOverlay(ctx, userID, monthStart, monthStart.AddDate(0, 1, -1), filter, events), nil
+}
+
+func (h *CalendarHandler) resolveEventsInRange(ctx context.Context, p graphql.ResolveParams) (any, error) {
+	const op = "transport.graphql.eventsInRange"
+
+	userID, err := idArg(p.Args, "userId")
+	if err != nil {
+		return nil, err
+	}
+	from, to := p.Args["from"].(time.Time), p.Args["to"].(time.Time)
+	filter := filterArg(p.Args)
+
+	events, err := h.svc.GetEventsForRange(ctx, userID, from, to, filter)
+	if err != nil {
+		return nil, h.graphqlServiceError(ctx, op, err)
+	}
+	return h.withOverlay(ctx, userID, from, to, filter, events), nil
+}
+
+func (h *CalendarHandler) resolveTags(ctx context.Context, p graphql.ResolveParams) (any, error) {
+	const op = "transport.graphql.tags"
+
+	userID, err := idArg(p.Args, "userId")
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := h.tags.ListTags(ctx, userID)
+	if err != nil {
+		return nil, h.graphqlServiceError(ctx, op, err)
+	}
+	return tags, nil
+}
+
+func (h *CalendarHandler) resolveEventTags(ctx context.Context, p graphql.ResolveParams) (any, error) {
+	const op = "transport.graphql.Event.tags"
+
+	event := p.Source.(*entity.Event)
+	if len(event.Tags) == 0 {
+		return []*entity.Tag{}, nil
+	}
+
+	saved, err := h.userTags(ctx, event.UserID)
+	if err != nil {
+		return nil, h.graphqlServiceError(ctx, op, err)
+	}
+
+	tags := make([]*entity.Tag, len(event.Tags))
+	for i, name := range event.Tags {
+		if tag, ok := saved[name]; ok {
+			tags[i] = tag
+			continue
+		}
+		tags[i] = &entity.Tag{UserID: event.UserID, Name: name}
+	}
+	return tags, nil
+}
+
+// userTags returns the saved tags of the user by name, loading them once
+// per request.
+func (h *CalendarHandler) userTags(ctx context.Context, userID uint64) (map[string]*entity.Tag, error) {
+	state, _ := ctx.Value(graphqlStateKey{}).(*graphqlState)
+	if state != nil {
+		if tags, ok := state.tags[userID]; ok {
+			return tags, nil
+		}
+	}
+
+	list, err := h.tags.ListTags(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]*entity.Tag, len(list))
+	for _, tag := range list {
+		tags[tag.Name] = tag
+	}
+	if state != nil {
+		state.tags[userID] = tags
+	}
+	return tags, nil
+}
+
+func (h *CalendarHandler) resolveEventAttachments(ctx context.Context, p graphql.ResolveParams) (any, error) {
+	const op = "transport.graphql.Event.attachments"
+
+	event := p.Source.(*entity.Event)
+	if h.attachments == nil || event.ID == 0 {
+		return []*entity.Attachment{}, nil
+	}
+
+	attachments, err := h.attachments.List(ctx, event.ID, event.UserID)
+	if err != nil {
+		return nil, h.graphqlServiceError(ctx, op, err)
+	}
+	return attachments, nil
+}
+
+func (h *CalendarHandler) resolveCreateEvent(ctx context.Context, p graphql.ResolveParams) (any, error) {
+	const op = "transport.graphql.createEvent"
+	log := h.log.Ctx(ctx)
+
+	input := p.Args["input"].(map[string]any)
+	userID, err := idArg(input, "userId")
+	if err != nil {
+		return nil, err
+	}
+	date, duration, tags, color := eventInputFields(input)
+
+	event, err := h.svc.CreateEvent(
+		ctx, userID, date, duration, input["title"].(string), input["text"].(string), tags, color,
+	)
+	if err != nil {
+		return nil, h.graphqlServiceError(ctx, op, err)
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "event created successfully",
+		logger.String("op", op),
+		logger.Uint64("event_id", event.ID),
+	)
+
+	return &eventResult{Event: event, Warnings: h.workingTimeWarnings(ctx, userID, event.Date, event.Duration)}, nil
+}
+
+func (h *CalendarHandler) resolveUpdateEvent(ctx context.Context, p graphql.ResolveParams) (any, error) {
+	const op = "transport.graphql.updateEvent"
+	log := h.log.Ctx(ctx)
+
+	id, err := idArg(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+	input := p.Args["input"].(map[string]any)
+	userID, err := idArg(input, "userId")
+	if err != nil {
+		return nil, err
+	}
+	date, duration, tags, color := eventInputFields(input)
+
+	event, err := h.svc.UpdateEvent(
+		ctx, id, userID, date, duration, input["title"].(string), input["text"].(string), tags, color,
+	)
+	if err != nil {
+		return nil, h.graphqlServiceError(ctx, op, err)
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "event update successfully",
+		logger.String("op", op),
+		logger.Uint64("event_id", event.ID),
+	)
+
+	return &eventResult{Event: event, Warnings: h.workingTimeWarnings(ctx, userID, event.Date, event.Duration)}, nil
+}
+
+func (h *CalendarHandler) resolveDeleteEvent(ctx context.Context, p graphql.ResolveParams) (any, error) {
+	const op = "transport.graphql.deleteEvent"
+	log := h.log.Ctx(ctx)
+
+	id, userID, err := eventArgs(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = h.svc.DeleteEvent(ctx, id, userID); err != nil {
+		return nil, h.graphqlServiceError(ctx, op, err)
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "event deleted successfully",
+		logger.String("op", op),
+		logger.Uint64("event_id", id),
+	)
+
+	return true, nil
+}
+
+func (h *CalendarHandler) resolveQuickAdd(ctx context.Context, p graphql.ResolveParams) (any, error) {
+	const op = "transport.graphql.quickAdd"
+	log := h.log.Ctx(ctx)
+
+	userID, err := idArg(p.Args, "userId")
+	if err != nil {
+		return nil, err
+	}
+	text := p.Args["text"].(string)
+	if len(text) > _maxQuickAddLength {
+		return nil, badUserInput(fmt.Sprintf("text must be at most %d bytes", _maxQuickAddLength))
+	}
+	timezone, _ := p.Args["timezone"].(string)
+
+	result, err := h.svc.QuickAdd(ctx, userID, text, timezone, p.Args["create"].(bool))
+	if err != nil {
+		return nil, h.graphqlServiceError(ctx, op, err)
+	}
+
+	if result.Created {
+		log.LogAttrs(ctx, logger.InfoLevel, "event quick added successfully",
+			logger.String("op", op),
+			logger.Uint64("event_id", result.Event.ID),
+		)
+	}
+
+	if result.Event != nil {
+		result.Warnings = h.workingTimeWarnings(ctx, userID, result.Event.Date, result.Event.Duration)
+	}
+	return result, nil
+}
+
+// graphqlServiceError logs a service error like handleServiceError and
+// returns it with the message of the REST API and a code in extensions.
+func (h *CalendarHandler) graphqlServiceError(ctx context.Context, op string, err error) error {
+	status, message := serviceErrorStatus(err)
+	if errors.Is(err, entity.ErrInvalidDate) {
+		status, message = http.StatusBadRequest, "Invalid date"
+	}
+
+	level := logger.WarnLevel
+	if status == http.StatusInternalServerError {
+		level = logger.ErrorLevel
+	}
+	h.log.Ctx(ctx).LogAttrs(ctx, level, op+" failed",
+		logger.String("op", op),
+		logger.Any("error", err),
+	)
+
+	return &graphql.Error{Message: message, Extensions: map[string]any{"code": graphqlErrorCode(status)}}
+}
+
+func graphqlErrorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "BAD_USER_INPUT"
+	case http.StatusInternalServerError:
+		return "INTERNAL_SERVER_ERROR"
+	case http.StatusGatewayTimeout:
+		return "TIMEOUT"
+	}
+	return strings.ToUpper(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+}
+
+func badUserInput(message string) error {
+	return &graphql.Error{Message: message, Extensions: map[string]any{"code": "BAD_USER_INPUT"}}
+}
+
+func idArg(args map[string]any, name string) (uint64, error) {
+	id, err := strconv.ParseUint(args[name].(string), 10, 64)
+	if err != nil || id == 0 {
+		return 0, badUserInput("Invalid " + name)
+	}
+	return id, nil
+}
+
+func eventArgs(args map[string]any) (id, userID uint64, err error) {
+	if id, err = idArg(args, "id"); err != nil {
+		return 0, 0, err
+	}
+	if userID, err = idArg(args, "userId"); err != nil {
+		return 0, 0, err
+	}
+	return id, userID, nil
+}
+
+func filterArg(args map[string]any) entity.TagFilter {
+	return entity.TagFilter{
+		Include: stringsArg(args["includeTags"]),
+		Exclude: stringsArg(args["excludeTags"]),
+	}
+}
+
+func stringsArg(v any) []string {
+	items, _ := v.([]any)
+	if items == nil {
+		return nil
+	}
+
+	result := make([]string, len(items))
+	for i, item := range items {
+		result[i] = item.(string)
+	}
+	return result
+}
+
+func eventInputFields(input map[string]any) (time.Time, time.Duration, []string, string) {
+	duration, _ := input["duration"].(time.Duration)
+	color, _ := input["color"].(string)
+	return input["date"].(time.Time), duration, stringsArg(input["tags"]), color
+}
+
+// optionalString resolves an empty string field to null.
+func optionalString[T any](get func(T) string) graphql.ResolveFunc {
+	return func(_ context.Context, p graphql.ResolveParams) (any, error) {
+		if s := get(p.Source.(T)); s != "" {
+			return s, nil
+		}
+		return nil, nil
+	}
+}
+
+func withGraphQLState(ctx context.Context) context.Context {
+	return context.WithValue(ctx, graphqlStateKey{}, &graphqlState{tags: make(map[uint64]map[string]*entity.Tag)})
+}
@@ -0,0 +1,259 @@
+package httpt
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	// fakeGraphQLEvents implements the methods the tests call; the embedded
+	// interface panics on any other.
+	fakeGraphQLEvents struct {
+		EventService
+
+		events []*entity.Event
+	}
+
+	fakeGraphQLTags struct {
+		TagService
+
+		tags  []*entity.Tag
+		calls int
+	}
+)
+
+func (s *fakeGraphQLEvents) GetEvent(_ context.Context, id, userID uint64) (*entity.Event, error) {
+	for _, event := range s.events {
+		if event.ID == id && event.UserID == userID {
+			return event, nil
+		}
+	}
+	return nil, entity.ErrEventNotFound
+}
+
+func (s *fakeGraphQLEvents) GetEventsForMonth(
+	_ context.Context,
+	userID uint64,
+	year, month int,
+	_ entity.TagFilter,
+) ([]*entity.Event, error) {
+	if month < 1 || month > 12 {
+		return nil, entity.ErrInvalidDate
+	}
+
+	var events []*entity.Event
+	for _, event := range s.events {
+		if event.UserID == userID && event.Date.Year() == year && int(event.Date.Month()) == month {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (s *fakeGraphQLEvents) CreateEvent(
+	_ context.Context,
+	userID uint64,
+	date time.Time,
+	duration time.Duration,
+	title, text string,
+	tags []string,
+	color string,
+) (*entity.Event, error) {
+	if text == "" {
+		return nil, entity.ErrInvalidData
+	}
+
+	event := &entity.Event{
+		ID: uint64(len(s.events) + 1), UserID: userID, Date: date, Duration: duration,
+		Title: title, Text: text, Tags: tags, Color: color,
+	}
+	s.events = append(s.events, event)
+	return event, nil
+}
+
+func (s *fakeGraphQLTags) ListTags(_ context.Context, _ uint64) ([]*entity.Tag, error) {
+	s.calls++
+	return s.tags, nil
+}
+
+func newGraphQLServer(t *testing.T) (*gin.Engine, *fakeGraphQLTags) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	day := time.Date(2026, time.October, 1, 9, 0, 0, 0, time.UTC)
+	events := &fakeGraphQLEvents{events: []*entity.Event{
+		{ID: 1, UserID: 7, Date: day, Duration: time.Hour, Title: "Standup", Text: "daily", Tags: []string{"work"}},
+		{
+			ID: 2, UserID: 7, Date: day.AddDate(0, 0, 1), Title: "Gym", Text: "legs", Tags: []string{"work", "sport"},
+			Attendees: []entity.Attendee{
+				{Email: "alice@example.com", Name: "Alice", Status: "accepted"},
+				{Email: "bob@example.org"},
+			},
+		},
+		{ID: 3, UserID: 8, Date: day, Title: "Other", Text: "user"},
+	}}
+	tags := &fakeGraphQLTags{tags: []*entity.Tag{{UserID: 7, Name: "work", Color: "#ff0000"}}}
+
+	h := &CalendarHandler{svc: events, tags: tags, log: logger.NewNop()}
+	schema, err := h.newGraphQLSchema()
+	if err != nil {
+		t.Fatalf("newGraphQLSchema() error = %v", err)
+	}
+	h.graphql = schema
+
+	router := gin.New()
+	router.POST("/graphql", h.graphqlHandler)
+	router.GET("/graphql", h.graphqlHandler)
+	return router, tags
+}
+
+func TestGraphQLHandler(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc       string
+		method     string
+		body       string
+		wantStatus int
+		want       string
+	}{
+		{
+			desc:   "MonthView",
+			method: http.MethodPost,
+			body: `{"query":"query ($m: Int!) { eventsForMonth(userId: 7, year: 2026, month: $m) ` +
+				`{ id title duration tags { name color } } }","variables":{"m":10}}`,
+			wantStatus: http.StatusOK,
+			want: `{"data":{"eventsForMonth":[` +
+				`{"id":"1","title":"Standup","duration":"1h0m0s","tags":[{"name":"work","color":"#ff0000"}]},` +
+				`{"id":"2","title":"Gym","duration":"0s","tags":[{"name":"work","color":"#ff0000"},` +
+				`{"name":"sport","color":null}]}]}}`,
+		},
+		{
+			desc:   "Attendees",
+			method: http.MethodPost,
+			body: `{"query":"{ eventsForMonth(userId: 7, year: 2026, month: 10) ` +
+				`{ id attendees { email name status } } }"}`,
+			wantStatus: http.StatusOK,
+			want: `{"data":{"eventsForMonth":[{"id":"1","attendees":[]},{"id":"2","attendees":[` +
+				`{"email":"alice@example.com","name":"Alice","status":"accepted"},` +
+				`{"email":"bob@example.org","name":null,"status":null}]}]}}`,
+		},
+		{
+			desc:       "GetQuery",
+			method:     http.MethodGet,
+			body:       `{ event(id: 1, userId: 7) { title date end allDay attachments { id } } }`,
+			wantStatus: http.StatusOK,
+			want: `{"data":{"event":{"title":"Standup","date":"2026-10-01T09:00:00Z",` +
+				`"end":"2026-10-01T10:00:00Z","allDay":false,"attachments":[]}}}`,
+		},
+		{
+			desc:       "NotFound",
+			method:     http.MethodPost,
+			body:       `{"query":"{ event(id: 3, userId: 7) { title } }"}`,
+			wantStatus: http.StatusOK,
+			want: `{"data":{"event":null},"errors":[{"message":"event not found",` +
+				`"locations":[{"line":1,"column":3}],"path":["event"],"extensions":{"code":"NOT_FOUND"}}]}`,
+		},
+		{
+			desc:       "InvalidDate",
+			method:     http.MethodPost,
+			body:       `{"query":"{ eventsForMonth(userId: 7, year: 2026, month: 13) { id } }"}`,
+			wantStatus: http.StatusOK,
+			want: `{"data":null,"errors":[{"message":"Invalid date",` +
+				`"locations":[{"line":1,"column":3}],"path":["eventsForMonth"],"extensions":{"code":"BAD_USER_INPUT"}}]}`,
+		},
+		{
+			desc:   "CreateEvent",
+			method: http.MethodPost,
+			body: `{"query":"mutation { createEvent(input: {userId: \"7\", date: \"2026-10-05\", ` +
+				`duration: \"30m\", title: \"Call\", text: \"mom\"}) { event { id date duration } warnings } }"}`,
+			wantStatus: http.StatusOK,
+			want: `{"data":{"createEvent":{"event":{"id":"4","date":"2026-10-05T00:00:00Z","duration":"30m0s"},` +
+				`"warnings":[]}}}`,
+		},
+		{
+			desc:   "CreateEventInvalid",
+			method: http.MethodPost,
+			body: `{"query":"mutation { createEvent(input: {userId: 7, date: \"2026-10-05\", ` +
+				`title: \"x\", text: \"\"}) { warnings } }"}`,
+			wantStatus: http.StatusOK,
+			want: `{"data":null,"errors":[{"message":"Invalid event data. Check title or text.",` +
+				`"locations":[{"line":1,"column":12}],"path":["createEvent"],"extensions":{"code":"BAD_USER_INPUT"}}]}`,
+		},
+		{
+			desc:       "MutationOverGet",
+			method:     http.MethodGet,
+			body:       `mutation { deleteEvent(id: 1, userId: 7) }`,
+			wantStatus: http.StatusBadRequest,
+			want: `{"errors":[{"message":"Only queries are allowed in this request",` +
+				`"locations":[{"line":1,"column":1}]}]}`,
+		},
+		{
+			desc:       "InvalidUserID",
+			method:     http.MethodPost,
+			body:       `{"query":"{ tags(userId: \"x\") { name } }"}`,
+			wantStatus: http.StatusOK,
+			want: `{"data":null,"errors":[{"message":"Invalid userId",` +
+				`"locations":[{"line":1,"column":3}],"path":["tags"],"extensions":{"code":"BAD_USER_INPUT"}}]}`,
+		},
+		{
+			desc:       "MissingQuery",
+			method:     http.MethodPost,
+			body:       `{"variables":{}}`,
+			wantStatus: http.StatusBadRequest,
+			want:       `{"error":"Invalid request format"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			router, _ := newGraphQLServer(t)
+
+			var req *http.Request
+			if tc.method == http.MethodGet {
+				req = httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(tc.body), nil)
+			} else {
+				req = httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(tc.body))
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tc.wantStatus)
+			}
+			if got := w.Body.String(); got != tc.want {
+				t.Errorf("body =\n%s\nwant\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGraphQLHandler_TagsLoadedOncePerRequest(t *testing.T) {
+	t.Parallel()
+
+	router, tags := newGraphQLServer(t)
+	body := `{"query":"{ a: eventsForMonth(userId: 7, year: 2026, month: 10) { tags { color } } ` +
+		`b: eventsForMonth(userId: 7, year: 2026, month: 10) { tags { name } } }"}`
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body)))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	if tags.calls != 1 {
+		t.Errorf("ListTags calls = %d, want 1", tags.calls)
+	}
+}
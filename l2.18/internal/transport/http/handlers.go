@@ -17,12 +17,52 @@ const (
 )
 
 type EventService interface {
-	CreateEvent(ctx context.Context, userID uint64, date time.Time, title, text string) (*entity.Event, error)
-	UpdateEvent(ctx context.Context, id, userID uint64, date time.Time, title, text string) (*entity.Event, error)
+	CreateEvent(
+		ctx context.Context,
+		userID uint64,
+		date time.Time,
+		duration time.Duration,
+		title, text string,
+		tags []string,
+		color string,
+	) (*entity.Event, error)
+	UpdateEvent(
+		ctx context.Context,
+		id, userID uint64,
+		date time.Time,
+		duration time.Duration,
+		title, text string,
+		tags []string,
+		color string,
+	) (*entity.Event, error)
 	DeleteEvent(ctx context.Context, id, userID uint64) error
-	GetEventsForDay(ctx context.Context, userID uint64, date time.Time) ([]*entity.Event, error)
-	GetEventsForWeek(ctx context.Context, userID uint64, startDate time.Time) ([]*entity.Event, error)
-	GetEventsForMonth(ctx context.Context, userID uint64, year, month int) ([]*entity.Event, error)
+	GetEvent(ctx context.Context, id, userID uint64) (*entity.Event, error)
+	GetEventsForDay(
+		ctx context.Context,
+		userID uint64,
+		date time.Time,
+		filter entity.TagFilter,
+	) ([]*entity.Event, error)
+	GetEventsForWeek(
+		ctx context.Context,
+		userID uint64,
+		startDate time.Time,
+		filter entity.TagFilter,
+	) ([]*entity.Event, error)
+	GetEventsForMonth(
+		ctx context.Context,
+		userID uint64,
+		year, month int,
+		filter entity.TagFilter,
+	) ([]*entity.Event, error)
+	GetEventsForRange(
+		ctx context.Context,
+		userID uint64,
+		from, to time.Time,
+		filter entity.TagFilter,
+	) ([]*entity.Event, error)
+	QuickAdd(ctx context.Context, userID uint64, input, timezone string, create bool) (*entity.QuickAdd, error)
+	Ping(ctx context.Context) error
 }
 
 // @Summary Создать событие
@@ -42,12 +82,15 @@ func (h *CalendarHandler) createEventHandler(c *gin.Context) {
 	var req CreateEventRequest
 	if svcErr := c.ShouldBindJSON(&req); svcErr != nil {
 		h.handleBindError(c, svcErr, op)
+		return
 	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
 	defer cancel()
 
-	event, err := h.svc.CreateEvent(ctx, req.UserID, req.Date, req.Title, req.Text)
+	event, err := h.svc.CreateEvent(
+		ctx, req.UserID, req.Date, time.Duration(req.Duration), req.Title, req.Text, req.Tags, req.Color,
+	)
 	if err != nil {
 		h.handleServiceError(c, err, op)
 		return
@@ -57,7 +100,8 @@ func (h *CalendarHandler) createEventHandler(c *gin.Context) {
 		logger.Uint64("event_id", event.ID),
 	)
 
-	c.JSON(http.StatusOK, gin.H{"result": "Event create successfully"})
+	warnings := h.workingTimeWarnings(ctx, req.UserID, event.Date, event.Duration)
+	c.JSON(http.StatusOK, resultWithWarnings("Event create successfully", warnings))
 }
 
 // @Summary Обновить событие
@@ -86,12 +130,15 @@ func (h *CalendarHandler) updateEventHandler(c *gin.Context) {
 	var req UpdateEventRequest
 	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
 		h.handleBindError(c, bindErr, op)
+		return
 	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
 	defer cancel()
 
-	event, err := h.svc.UpdateEvent(ctx, id, req.UserID, req.Date, req.Title, req.Text)
+	event, err := h.svc.UpdateEvent(
+		ctx, id, req.UserID, req.Date, time.Duration(req.Duration), req.Title, req.Text, req.Tags, req.Color,
+	)
 	if err != nil {
 		h.handleServiceError(c, err, op)
 		return
@@ -101,7 +148,8 @@ func (h *CalendarHandler) updateEventHandler(c *gin.Context) {
 		logger.Uint64("event_id", event.ID),
 	)
 
-	c.JSON(http.StatusOK, gin.H{"result": "Event update successfully"})
+	warnings := h.workingTimeWarnings(ctx, req.UserID, event.Date, event.Duration)
+	c.JSON(http.StatusOK, resultWithWarnings("Event update successfully", warnings))
 }
 
 // @Summary Удалить событие
@@ -130,6 +178,7 @@ func (h *CalendarHandler) deleteEventHandler(c *gin.Context) {
 	var req DeleteEventRequest
 	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
 		h.handleBindError(c, bindErr, op)
+		return
 	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
@@ -147,6 +196,45 @@ func (h *CalendarHandler) deleteEventHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Event deleted successfully"})
 }
 
+// @Summary Получить событие
+// @Description Возвращает событие по ID; повторные запросы обслуживаются из кэша
+// @Tags Events
+// @Accept json
+// @Produce json
+// @Param id path int true "ID события"
+// @Param request body GetEventRequest true "Владелец события"
+// @Success 200 {object} entity.Event "Событие"
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 404 {object} httpt.ErrorResponse
+// @Failure 500 {object} httpt.ErrorResponse
+// @Router /get_event/{id} [post]
+func (h *CalendarHandler) getEventHandler(c *gin.Context) {
+	const op = "transport.getEventHandler"
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		h.handleEventIDError(c, op, id)
+		return
+	}
+
+	var req GetEventRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		h.handleBindError(c, bindErr, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	event, err := h.svc.GetEvent(ctx, id, req.UserID)
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
+
 // @Summary Получить события на день
 // @Description Получает список событий календаря на указанный день для заданного пользователя
 // @Tags Events
@@ -161,32 +249,28 @@ func (h *CalendarHandler) getEventsForDayHandler(c *gin.Context) {
 	const op = "transport.getEventsForDayHandler"
 	log := h.log.Ctx(c.Request.Context())
 
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
-	if err != nil || id <= 0 {
-		h.handleEventIDError(c, op, id)
-		return
-	}
-
 	var req GetEventForDayRequest
 	if svcErr := c.ShouldBindJSON(&req); svcErr != nil {
 		h.handleBindError(c, svcErr, op)
+		return
 	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
 	defer cancel()
 
-	event, err := h.svc.GetEventsForDay(ctx, req.UserID, req.Date)
+	filter := req.toFilter()
+	event, err := h.svc.GetEventsForDay(ctx, req.UserID, req.Date, filter)
 	if err != nil {
 		h.handleServiceError(c, err, op)
 		return
 	}
+	event = h.withOverlay(ctx, req.UserID, req.Date, req.Date, filter, event)
 
 	log.LogAttrs(ctx, logger.InfoLevel, "event for day retreived successfully",
 		logger.Slice("events", event),
 	)
 
-	c.JSON(http.StatusOK, gin.H{"result": "Event deleted successfully"})
+	c.JSON(http.StatusOK, event)
 }
 
 // @Summary Получить события на неделю
@@ -203,26 +287,23 @@ func (h *CalendarHandler) getEventsForWeekHandler(c *gin.Context) {
 	const op = "transport.getEventsForWeekHandler"
 	log := h.log.Ctx(c.Request.Context())
 
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
-	if err != nil || id <= 0 {
-		h.handleEventIDError(c, op, id)
-		return
-	}
-
 	var req GetEventForWeekRequest
 	if svcErr := c.ShouldBindJSON(&req); svcErr != nil {
 		h.handleBindError(c, svcErr, op)
+		return
 	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
 	defer cancel()
 
-	event, err := h.svc.GetEventsForWeek(ctx, req.UserID, req.StartDate)
+	filter := req.toFilter()
+	event, err := h.svc.GetEventsForWeek(ctx, req.UserID, req.StartDate, filter)
 	if err != nil {
 		h.handleServiceError(c, err, op)
 		return
 	}
+	// nolint: mnd
+	event = h.withOverlay(ctx, req.UserID, req.StartDate, req.StartDate.AddDate(0, 0, 6), filter, event)
 
 	log.LogAttrs(ctx, logger.InfoLevel, "event for week retreived successfully",
 		logger.Slice("events", event),
@@ -245,26 +326,23 @@ func (h *CalendarHandler) getEventsForMonthsHandler(c *gin.Context) {
 	const op = "transport.getEventsForMonthsHandler"
 	log := h.log.Ctx(c.Request.Context())
 
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
-	if err != nil || id <= 0 {
-		h.handleEventIDError(c, op, id)
-		return
-	}
-
 	var req GetEventForMonthRequest
 	if svcErr := c.ShouldBindJSON(&req); svcErr != nil {
 		h.handleBindError(c, svcErr, op)
+		return
 	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
 	defer cancel()
 
-	event, err := h.svc.GetEventsForMonth(ctx, req.UserID, req.Year, req.Month)
+	filter := req.toFilter()
+	event, err := h.svc.GetEventsForMonth(ctx, req.UserID, req.Year, req.Month, filter)
 	if err != nil {
 		h.handleServiceError(c, err, op)
 		return
 	}
+	monthStart := time.Date(req.Year, time.Month(req.Month), 1, 0, 0, 0, 0, time.UTC)
+	event = h.withOverlay(ctx, req.UserID, monthStart, monthStart.AddDate(0, 1, -1), filter, event)
 
 	log.LogAttrs(ctx, logger.InfoLevel, "event for month retreived successfully",
 		logger.Slice("events", event),
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -18,7 +19,9 @@ import (
 
 type HTTPServer struct {
 	server          *http.Server
-	shutdownTimeout time.Duration
+	handler         *CalendarHandler
+	shutdownTimeout atomic.Int64
+	drainDelay      atomic.Int64
 	log             logger.Logger
 }
 
@@ -27,7 +30,7 @@ func NewHTTPServer(
 	cfg *config.HTTP,
 	log logger.Logger,
 ) (*HTTPServer, error) {
-	return &HTTPServer{
+	s := &HTTPServer{
 		server: &http.Server{
 			Addr:              net.JoinHostPort(cfg.Host, cfg.Port),
 			Handler:           handler.Engine(),
@@ -36,9 +39,31 @@ func NewHTTPServer(
 			IdleTimeout:       cfg.IdleTimeout,
 			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
 		},
-		shutdownTimeout: cfg.ShutdownTimeout,
-		log:             log,
-	}, nil
+		handler: handler,
+		log:     log,
+	}
+	s.SetShutdownTimeout(cfg.ShutdownTimeout)
+	s.SetDrainDelay(cfg.DrainDelay)
+
+	return s, nil
+}
+
+func (s *HTTPServer) SetShutdownTimeout(timeout time.Duration) {
+	s.shutdownTimeout.Store(int64(timeout))
+}
+
+func (s *HTTPServer) ShutdownTimeout() time.Duration {
+	return time.Duration(s.shutdownTimeout.Load())
+}
+
+// SetDrainDelay sets how long the server keeps serving after the readiness
+// probe starts failing, so load balancers notice before connections close.
+func (s *HTTPServer) SetDrainDelay(delay time.Duration) {
+	s.drainDelay.Store(int64(delay))
+}
+
+func (s *HTTPServer) DrainDelay() time.Duration {
+	return time.Duration(s.drainDelay.Load())
 }
 
 func (s *HTTPServer) Start(ctx context.Context) error {
@@ -60,7 +85,7 @@ func (s *HTTPServer) Start(ctx context.Context) error {
 		signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 		select {
 		case <-stop:
-			s.log.Infow("shutdown signal received", "timeout", s.shutdownTimeout.String())
+			s.log.Infow("shutdown signal received", "timeout", s.ShutdownTimeout().String())
 			return s.Stop(ctx)
 		case <-ctx.Done():
 			return ctx.Err()
@@ -75,7 +100,16 @@ func (s *HTTPServer) Start(ctx context.Context) error {
 }
 
 func (s *HTTPServer) Stop(ctx context.Context) error {
-	shutdownCtx, cancel := context.WithTimeout(ctx, s.shutdownTimeout)
+	s.handler.MarkShuttingDown()
+
+	if delay := s.DrainDelay(); delay > 0 {
+		s.log.Infow("draining HTTP server", "delay", delay.String())
+		time.Sleep(delay)
+	}
+
+	// The signal that stops the server usually cancels ctx as well, which
+	// must not cut the wait for in-flight requests short.
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.ShutdownTimeout())
 	defer cancel()
 
 	s.log.Infow("shutting down HTTP server")
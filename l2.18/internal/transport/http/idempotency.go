@@ -0,0 +1,270 @@
+package httpt
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/cache"
+	"calendar-wbf/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	_idempotencyKeyHeader    = "Idempotency-Key"
+	_idempotentReplayHeader  = "Idempotent-Replayed"
+	_maxIdempotencyKeyLength = 255
+)
+
+type (
+	// IdempotencyStore remembers the first response to each Idempotency-Key,
+	// scoped by user, for the configured window.
+	IdempotencyStore struct {
+		responses cache.Cache[string, *idempotentResponse]
+		ttl       time.Duration
+
+		mu       sync.Mutex
+		inFlight map[string]string
+	}
+
+	idempotentResponse struct {
+		fingerprint string
+		status      int
+		contentType string
+		body        []byte
+	}
+
+	// responseRecorder copies the response body so it can be stored.
+	responseRecorder struct {
+		gin.ResponseWriter
+		body bytes.Buffer
+	}
+)
+
+func NewIdempotencyStore(capacity int, ttl time.Duration, log logger.Logger) (*IdempotencyStore, error) {
+	responses, err := cache.NewLRUCache[string, *idempotentResponse](capacity, log)
+	if err != nil {
+		return nil, fmt.Errorf("transport.NewIdempotencyStore: %w", err)
+	}
+
+	return &IdempotencyStore{
+		responses: responses,
+		ttl:       ttl,
+		inFlight:  make(map[string]string),
+	}, nil
+}
+
+// begin returns the stored response for key, or reserves key for a new
+// request. A different payload under the same key is rejected with
+// ErrIdempotencyKeyReused, a retry racing the first request with
+// ErrDuplicateEvent.
+func (s *IdempotencyStore) begin(key, fingerprint string) (*idempotentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if resp, ok := s.responses.Get(key); ok {
+		if resp.fingerprint != fingerprint {
+			return nil, entity.ErrIdempotencyKeyReused
+		}
+		return resp, nil
+	}
+
+	if inFlight, ok := s.inFlight[key]; ok {
+		if inFlight != fingerprint {
+			return nil, entity.ErrIdempotencyKeyReused
+		}
+		return nil, entity.ErrDuplicateEvent
+	}
+
+	s.inFlight[key] = fingerprint
+	return nil, nil
+}
+
+// finish releases key and stores resp. Server errors and a nil resp, left by
+// a handler that panicked, are not stored, so the client can retry them.
+func (s *IdempotencyStore) finish(key string, resp *idempotentResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.inFlight, key)
+	if resp != nil && resp.status < http.StatusInternalServerError {
+		s.responses.Put(key, resp, s.ttl)
+	}
+}
+
+// PurgeScope drops the stored responses of the user in the tenant, which
+// hold full event payloads, and returns how many were removed.
+func (s *IdempotencyStore) PurgeScope(tenantID string, userID uint64) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int
+	for _, key := range s.scopeKeys(tenantID, userID) {
+		if s.responses.Delete(key) {
+			n++
+		}
+	}
+	return n
+}
+
+// CountScope returns how many responses of the user in the tenant are
+// stored.
+func (s *IdempotencyStore) CountScope(tenantID string, userID uint64) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.scopeKeys(tenantID, userID))
+}
+
+func (s *IdempotencyStore) scopeKeys(tenantID string, userID uint64) []string {
+	prefix := scopePrefix(tenantID, userID) + "/"
+
+	var keys []string
+	for _, key := range s.responses.Keys() {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// idempotencyMiddleware replays the stored response when a request is
+// retried with the same Idempotency-Key. Requests without the header pass
+// through.
+func (h *CalendarHandler) idempotencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "transport.idempotencyMiddleware"
+
+		idempotencyKey := c.GetHeader(_idempotencyKeyHeader)
+		if h.idempotency == nil || idempotencyKey == "" {
+			c.Next()
+			return
+		}
+
+		if len(idempotencyKey) > _maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid Idempotency-Key"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			h.handleBindError(c, err, op)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		key := idempotencyScope(c.Request.Context(), body) + ":" + idempotencyKey
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		stored, err := h.idempotency.begin(key, fingerprint)
+		if err != nil {
+			h.handleServiceError(c, err, op)
+			c.Abort()
+			return
+		}
+
+		if stored != nil {
+			ctx := c.Request.Context()
+			h.log.Ctx(ctx).LogAttrs(ctx, logger.InfoLevel, "idempotent response replayed",
+				logger.String("op", op),
+				logger.String("path", c.Request.URL.Path),
+				logger.Int("status", stored.status),
+			)
+			c.Header(_idempotentReplayHeader, "true")
+			c.Data(stored.status, stored.contentType, stored.body)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		defer func() {
+			// The recorded status of a panicking handler is still the
+			// default 200, so release the key and let recovery answer.
+			if r := recover(); r != nil {
+				h.idempotency.finish(key, nil)
+				panic(r)
+			}
+
+			h.idempotency.finish(key, &idempotentResponse{
+				fingerprint: fingerprint,
+				status:      recorder.Status(),
+				contentType: recorder.Header().Get("Content-Type"),
+				body:        recorder.body.Bytes(),
+			})
+		}()
+
+		c.Next()
+	}
+}
+
+// idempotencyScope returns the tenant, user and token subject a key belongs
+// to, so clients cannot replay each other's responses by guessing keys. With
+// token auth the verified subject tells clients apart; the user ID of the
+// body, or of the GraphQL variables, only groups the keys for erasure and is
+// all there is to go on without auth.
+func idempotencyScope(ctx context.Context, body []byte) string {
+	var req struct {
+		UserID    json.Number `json:"user_id"`
+		Variables struct {
+			UserID json.Number `json:"userId"`
+		} `json:"variables"`
+	}
+	_ = json.Unmarshal(body, &req)
+
+	userID, err := strconv.ParseUint(req.UserID.String(), 10, 64)
+	if err != nil {
+		userID, _ = strconv.ParseUint(req.Variables.UserID.String(), 10, 64)
+	}
+
+	return scopePrefix(entity.TenantFromContext(ctx), userID) + "/" + subjectFromContext(ctx)
+}
+
+// scopePrefix is the part of the scope erasure of a user matches on.
+func scopePrefix(tenantID string, userID uint64) string {
+	return tenantID + "/" + strconv.FormatUint(userID, 10)
+}
+
+// requestFingerprint hashes the route and the payload. JSON payloads are
+// normalized first, so retries differing only in key order or whitespace
+// still match. Numbers are kept as written, so IDs above 2^53 that would
+// round to the same float still differ.
+func requestFingerprint(method, path string, body []byte) string {
+	var payload any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&payload); err == nil && !dec.More() {
+		if normalized, err := json.Marshal(payload); err == nil {
+			body = normalized
+		}
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
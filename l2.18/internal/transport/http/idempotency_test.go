@@ -0,0 +1,286 @@
+package httpt
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"calendar-wbf/internal/config"
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakePrivacy struct {
+	PrivacyService
+}
+
+func (fakePrivacy) Erase(ctx context.Context, userID uint64) (*entity.EraseReport, error) {
+	return &entity.EraseReport{
+		TenantID: entity.TenantFromContext(ctx),
+		UserID:   userID,
+		Removed:  map[string]int{"events": 1},
+		Verified: true,
+	}, nil
+}
+
+// newIdempotencyServer serves POST /create_event behind the idempotency
+// middleware with handler, and the erase endpoint, for tenant acme.
+func newIdempotencyServer(t *testing.T, handler gin.HandlerFunc) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	store, err := NewIdempotencyStore(100, time.Hour, logger.NewNop())
+	if err != nil {
+		t.Fatalf("NewIdempotencyStore() error = %v", err)
+	}
+	h := &CalendarHandler{log: logger.NewNop(), idempotency: store, privacy: fakePrivacy{}}
+
+	router := gin.New()
+	router.Use(gin.RecoveryWithWriter(io.Discard))
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(entity.WithTenant(c.Request.Context(), "acme"))
+	})
+	router.POST("/create_event", h.idempotencyMiddleware(), handler)
+	router.DELETE("/admin/users/:user_id", h.eraseUserHandler)
+	return router
+}
+
+func sendIdempotent(router http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/create_event", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(_idempotencyKeyHeader, key)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestEraseUserHandler_PurgesIdempotentResponses(t *testing.T) {
+	t.Parallel()
+
+	router := newIdempotencyServer(t, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"result": "created"})
+	})
+	sendIdempotent(router, "k1", `{"user_id":7,"title":"private"}`)
+	sendIdempotent(router, "k2", `{"user_id":7,"title":"private"}`)
+	sendIdempotent(router, "k1", `{"user_id":8,"title":"other"}`)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/users/7", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("erase status = %d; want 200: %s", w.Code, w.Body.String())
+	}
+
+	var report entity.EraseReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if report.Removed["idempotency"] != 2 || !report.Verified {
+		t.Errorf("report = %+v; want 2 idempotent responses removed and verified", report)
+	}
+
+	w = sendIdempotent(router, "k1", `{"user_id":7,"title":"private"}`)
+	if w.Header().Get(_idempotentReplayHeader) != "" {
+		t.Error("response of the erased user replayed after erase")
+	}
+	w = sendIdempotent(router, "k1", `{"user_id":8,"title":"other"}`)
+	if w.Header().Get(_idempotentReplayHeader) != "true" {
+		t.Error("response of another user not replayed after erase")
+	}
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	t.Parallel()
+
+	const body = `{"user_id":7,"title":"standup"}`
+
+	testCases := []struct {
+		desc       string
+		status     int
+		panics     bool
+		retry      string
+		wantStatus int
+		wantReplay bool
+		wantCalls  int32
+	}{
+		{"Replay", http.StatusOK, false, `{"title":"standup", "user_id":7}`, http.StatusOK, true, 1},
+		{"DifferentPayload", http.StatusOK, false, `{"user_id":7}`, http.StatusUnprocessableEntity, false, 1},
+		{"ClientErrorStored", http.StatusBadRequest, false, body, http.StatusBadRequest, true, 1},
+		{"ServerErrorNotStored", http.StatusServiceUnavailable, false, body, http.StatusServiceUnavailable, false, 2},
+		{"PanicNotStored", http.StatusOK, true, body, http.StatusInternalServerError, false, 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+			router := newIdempotencyServer(t, func(c *gin.Context) {
+				calls.Add(1)
+				if tc.panics {
+					panic("handler failed")
+				}
+				c.JSON(tc.status, gin.H{"call": calls.Load()})
+			})
+
+			first := sendIdempotent(router, "k", body)
+			w := sendIdempotent(router, "k", tc.retry)
+
+			if w.Code != tc.wantStatus {
+				t.Errorf("retry status = %d; want %d: %s", w.Code, tc.wantStatus, w.Body.String())
+			}
+			if replayed := w.Header().Get(_idempotentReplayHeader) == "true"; replayed != tc.wantReplay {
+				t.Errorf("retry replayed = %v; want %v", replayed, tc.wantReplay)
+			}
+			if tc.wantReplay && w.Body.String() != first.Body.String() {
+				t.Errorf("replayed body = %s; want %s", w.Body.String(), first.Body.String())
+			}
+			if got := calls.Load(); got != tc.wantCalls {
+				t.Errorf("handler calls = %d; want %d", got, tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddleware_InFlight(t *testing.T) {
+	t.Parallel()
+
+	const body = `{"user_id":7,"title":"standup"}`
+
+	started, release := make(chan struct{}), make(chan struct{})
+	router := newIdempotencyServer(t, func(c *gin.Context) {
+		close(started)
+		<-release
+		c.JSON(http.StatusOK, gin.H{"result": "created"})
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- sendIdempotent(router, "k", body) }()
+	<-started
+
+	if w := sendIdempotent(router, "k", body); w.Code != http.StatusConflict {
+		t.Errorf("status while in flight = %d; want 409", w.Code)
+	}
+	if w := sendIdempotent(router, "k", `{"user_id":7,"title":"retro"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status of another payload while in flight = %d; want 422", w.Code)
+	}
+
+	close(release)
+	if w := <-done; w.Code != http.StatusOK {
+		t.Fatalf("first status = %d; want 200", w.Code)
+	}
+	if w := sendIdempotent(router, "k", body); w.Header().Get(_idempotentReplayHeader) != "true" {
+		t.Error("retry after the first request finished not replayed")
+	}
+}
+
+func TestIdempotencyMiddleware_Scope(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{Tenant: config.Tenant{TokenSecret: _testSecret, TokenClaim: "tenant"}}
+	alice := signToken(t, _testSecret, map[string]any{"tenant": "acme", "sub": "alice"})
+	bob := signToken(t, _testSecret, map[string]any{"tenant": "acme", "sub": "bob"})
+
+	const (
+		graphqlBody = `{"query":"mutation($userId: ID!) { createEvent(userId: $userId) { id } }",` +
+			`"variables":{"userId":"7"}}`
+		otherGraphqlBody = `{"query":"mutation($userId: ID!) { createEvent(userId: $userId) { id } }",` +
+			`"variables":{"userId":"8"}}`
+	)
+
+	testCases := []struct {
+		desc       string
+		path       string
+		first      string
+		firstToken string
+		retry      string
+		retryToken string
+		wantReplay bool
+	}{
+		{"SameUser", "/create_event", `{"user_id":7}`, "", `{"user_id":7}`, "", true},
+		{"OtherUser", "/create_event", `{"user_id":7}`, "", `{"user_id":8}`, "", false},
+		{"SameSubject", "/create_event", `{"user_id":7}`, alice, `{"user_id":7}`, alice, true},
+		{"OtherSubject", "/create_event", `{"user_id":7}`, alice, `{"user_id":7}`, bob, false},
+		{"GraphQLSameUser", "/graphql", graphqlBody, "", graphqlBody, "", true},
+		{"GraphQLOtherUser", "/graphql", graphqlBody, "", otherGraphqlBody, "", false},
+		{"GraphQLOtherSubject", "/graphql", graphqlBody, alice, graphqlBody, bob, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			store, err := NewIdempotencyStore(100, time.Hour, logger.NewNop())
+			if err != nil {
+				t.Fatalf("NewIdempotencyStore() error = %v", err)
+			}
+			h := &CalendarHandler{log: logger.NewNop(), idempotency: store, config: config.NewStore(cfg)}
+
+			var calls atomic.Int32
+			handler := func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"call": calls.Add(1)})
+			}
+			router := gin.New()
+			router.Use(h.tenantMiddleware())
+			router.POST("/create_event", h.idempotencyMiddleware(), handler)
+			router.POST("/graphql", h.idempotencyMiddleware(), handler)
+
+			send := func(body, token string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(body))
+				req.Header.Set(_idempotencyKeyHeader, "k")
+				if token != "" {
+					req.Header.Set("Authorization", "Bearer "+token)
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				return w
+			}
+
+			send(tc.first, tc.firstToken)
+			w := send(tc.retry, tc.retryToken)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("retry status = %d; want 200: %s", w.Code, w.Body.String())
+			}
+			if replayed := w.Header().Get(_idempotentReplayHeader) == "true"; replayed != tc.wantReplay {
+				t.Errorf("retry replayed = %v; want %v", replayed, tc.wantReplay)
+			}
+		})
+	}
+}
+
+func TestRequestFingerprint(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc      string
+		first     string
+		second    string
+		wantEqual bool
+	}{
+		{"KeyOrderAndSpaces", `{"user_id":7,"title":"a"}`, `{ "title": "a", "user_id": 7 }`, true},
+		{"LargeIDs", `{"user_id":7,"event_id":9007199254740993}`, `{"user_id":7,"event_id":9007199254740992}`, false},
+		{"NotJSON", `title=a`, `title=b`, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			first := requestFingerprint(http.MethodPost, "/update_event", []byte(tc.first))
+			second := requestFingerprint(http.MethodPost, "/update_event", []byte(tc.second))
+			if (first == second) != tc.wantEqual {
+				t.Errorf("fingerprints equal = %v; want %v", first == second, tc.wantEqual)
+			}
+		})
+	}
+}
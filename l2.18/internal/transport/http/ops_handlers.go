@@ -0,0 +1,304 @@
+package httpt
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"calendar-wbf/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	_readinessTimeout = 200 * time.Millisecond
+	_unknownCommit    = "unknown"
+	_defaultLogsLimit = 100
+)
+
+func newBuildInfo(name, version string) VersionResponse {
+	info := VersionResponse{
+		Name:      name,
+		Version:   version,
+		Commit:    _unknownCommit,
+		GoVersion: runtime.Version(),
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			if setting.Key == "vcs.revision" {
+				info.Commit = setting.Value
+			}
+		}
+	}
+
+	return info
+}
+
+// @Summary Liveness probe
+// @Description Процесс запущен и обрабатывает запросы
+// @Tags Ops
+// @Produce json
+// @Success 200 {object} httpt.ProbeResponse
+// @Router /health/live [get]
+func (h *CalendarHandler) livenessHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, ProbeResponse{Status: "ok"})
+}
+
+// @Summary Readiness probe
+// @Description Проверяет доступность репозитория и состояние завершения работы
+// @Tags Ops
+// @Produce json
+// @Success 200 {object} httpt.ProbeResponse
+// @Failure 503 {object} httpt.ProbeResponse
+// @Router /health/ready [get]
+func (h *CalendarHandler) readinessHandler(c *gin.Context) {
+	const op = "transport.readinessHandler"
+
+	checks := map[string]string{
+		"shutdown":   "ok",
+		"repository": "ok",
+	}
+	ready := true
+
+	if h.shuttingDown.Load() {
+		checks["shutdown"] = "shutting down"
+		ready = false
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _readinessTimeout)
+	defer cancel()
+
+	if err := h.svc.Ping(ctx); err != nil {
+		h.log.Ctx(ctx).LogAttrs(ctx, logger.WarnLevel, "readiness check failed",
+			logger.String("op", op),
+			logger.Any("error", err),
+		)
+		checks["repository"] = err.Error()
+		ready = false
+	}
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, ProbeResponse{Status: "unavailable", Checks: checks})
+		return
+	}
+
+	c.JSON(http.StatusOK, ProbeResponse{Status: "ok", Checks: checks})
+}
+
+// @Summary Версия сервиса
+// @Description Имя, версия и коммит сборки
+// @Tags Ops
+// @Produce json
+// @Success 200 {object} httpt.VersionResponse
+// @Router /version [get]
+func (h *CalendarHandler) versionHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, h.build)
+}
+
+func (h *CalendarHandler) adminAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "transport.adminAuthMiddleware"
+
+		var token string
+		if h.config != nil {
+			token = h.config.Load().Admin.Token
+		}
+
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin API is disabled"})
+			return
+		}
+
+		provided, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			h.log.Ctx(c.Request.Context()).LogAttrs(c.Request.Context(), logger.WarnLevel, "admin auth failed",
+				logger.String("op", op),
+				logger.String("path", c.Request.URL.Path),
+				logger.String("client_ip", c.ClientIP()),
+			)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// @Summary Состояние кэша
+// @Description Размер, емкость и счетчики попаданий, промахов и вытеснений
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} httpt.CacheInfoResponse
+// @Failure 401 {object} httpt.ErrorResponse
+// @Router /admin/cache [get]
+func (h *CalendarHandler) cacheInfoHandler(c *gin.Context) {
+	if h.cache == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cache is not configured"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newCacheInfoResponse(h.cache.Stats()))
+}
+
+// @Summary Очистить кэш
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} httpt.CacheInfoResponse
+// @Failure 401 {object} httpt.ErrorResponse
+// @Router /admin/cache [delete]
+func (h *CalendarHandler) cachePurgeHandler(c *gin.Context) {
+	const op = "transport.cachePurgeHandler"
+
+	if h.cache == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cache is not configured"})
+		return
+	}
+
+	purged := h.cache.Len()
+	h.cache.Purge()
+
+	h.log.Ctx(c.Request.Context()).LogAttrs(c.Request.Context(), logger.InfoLevel, "cache purged via admin API",
+		logger.String("op", op),
+		logger.Int("purged", purged),
+		logger.String("client_ip", c.ClientIP()),
+	)
+
+	c.JSON(http.StatusOK, newCacheInfoResponse(h.cache.Stats()))
+}
+
+// @Summary Текущая конфигурация
+// @Description Загруженная конфигурация и ключи, ожидающие перезапуска
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} httpt.ConfigResponse
+// @Failure 401 {object} httpt.ErrorResponse
+// @Router /admin/config [get]
+func (h *CalendarHandler) configHandler(c *gin.Context) {
+	if h.config == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Config store is not configured"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ConfigResponse{
+		Config:         h.config.Load(),
+		PendingRestart: h.config.PendingRestart(),
+	})
+}
+
+// @Summary Текущий уровень логирования
+// @Description Возвращает общий уровень и переопределения по компонентам
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} httpt.LogLevelResponse
+// @Failure 401 {object} httpt.ErrorResponse
+// @Router /admin/log_level [get]
+func (h *CalendarHandler) getLogLevelHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, h.logLevelResponse())
+}
+
+// @Summary Изменить уровень логирования
+// @Description Меняет общий уровень или, если указан component, уровень одного компонента
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body httpt.LogLevelRequest true "Новый уровень: debug, info, warn, error"
+// @Success 200 {object} httpt.LogLevelResponse
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 401 {object} httpt.ErrorResponse
+// @Router /admin/log_level [put]
+func (h *CalendarHandler) setLogLevelHandler(c *gin.Context) {
+	const op = "transport.setLogLevelHandler"
+
+	var req LogLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleBindError(c, err, op)
+		return
+	}
+
+	level, err := logger.ParseLevel(req.Level)
+	if err != nil {
+		h.handleBindError(c, err, op)
+		return
+	}
+
+	previous := h.log.Level()
+	if req.Component != "" {
+		if override, ok := h.log.ComponentLevels()[req.Component]; ok {
+			previous = override
+		}
+		h.log.SetComponentLevel(req.Component, level)
+	} else {
+		h.log.SetLevel(level)
+	}
+
+	h.log.Ctx(c.Request.Context()).LogAttrs(c.Request.Context(), logger.WarnLevel, "log level changed via admin API",
+		logger.String("op", op),
+		logger.String("component", req.Component),
+		logger.String("from", previous.String()),
+		logger.String("to", level.String()),
+		logger.String("client_ip", c.ClientIP()),
+	)
+
+	c.JSON(http.StatusOK, h.logLevelResponse())
+}
+
+func (h *CalendarHandler) logLevelResponse() LogLevelResponse {
+	resp := LogLevelResponse{Level: strings.ToLower(h.log.Level().String())}
+
+	overrides := h.log.ComponentLevels()
+	if len(overrides) > 0 {
+		resp.Components = make(map[string]string, len(overrides))
+		for component, level := range overrides {
+			resp.Components[component] = strings.ToLower(level.String())
+		}
+	}
+	return resp
+}
+
+// @Summary Последние записи лога
+// @Description Записи из буфера в памяти (синк ring), от старых к новым
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Сколько последних записей вернуть, по умолчанию 100"
+// @Param level query string false "Минимальный уровень: debug, info, warn, error"
+// @Success 200 {object} httpt.LogsResponse
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 401 {object} httpt.ErrorResponse
+// @Router /admin/logs [get]
+func (h *CalendarHandler) logsHandler(c *gin.Context) {
+	const op = "transport.logsHandler"
+
+	if h.logRing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Log ring is not configured"})
+		return
+	}
+
+	req := LogsRequest{Limit: _defaultLogsLimit, Level: "debug"}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.handleBindError(c, err, op)
+		return
+	}
+
+	level, err := logger.ParseLevel(req.Level)
+	if err != nil {
+		h.handleBindError(c, err, op)
+		return
+	}
+
+	entries := h.logRing.Entries(req.Limit, level)
+	if entries == nil {
+		entries = []logger.RingEntry{}
+	}
+	c.JSON(http.StatusOK, LogsResponse{Entries: entries})
+}
@@ -0,0 +1,151 @@
+package httpt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"calendar-wbf/internal/config"
+	"calendar-wbf/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakePingEvents struct {
+	EventService
+
+	err error
+}
+
+func (s *fakePingEvents) Ping(ctx context.Context) error {
+	if s.err != nil {
+		return s.err
+	}
+	return ctx.Err()
+}
+
+// newOpsHandler serves the probes and an admin endpoint guarded by token.
+func newOpsHandler(pingErr error, token string) *CalendarHandler {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{}
+	cfg.Admin.Token = token
+	h := &CalendarHandler{
+		svc:    &fakePingEvents{err: pingErr},
+		config: config.NewStore(cfg),
+		log:    logger.NewNop(),
+		router: gin.New(),
+	}
+
+	h.router.GET("/health/live", h.livenessHandler)
+	h.router.GET("/health/ready", h.readinessHandler)
+	h.router.GET("/admin/ping", h.adminAuthMiddleware(), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return h
+}
+
+func get(h http.Handler, path, authorization string) int {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestProbes(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc         string
+		pingErr      error
+		shuttingDown bool
+		wantReady    int
+	}{
+		{"Ready", nil, false, http.StatusOK},
+		{"RepositoryUnavailable", errors.New("repository down"), false, http.StatusServiceUnavailable},
+		{"ShuttingDown", nil, true, http.StatusServiceUnavailable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			h := newOpsHandler(tc.pingErr, "")
+			if tc.shuttingDown {
+				h.MarkShuttingDown()
+			}
+
+			if code := get(h.router, "/health/live", ""); code != http.StatusOK {
+				t.Errorf("liveness = %d; want 200", code)
+			}
+			if code := get(h.router, "/health/ready", ""); code != tc.wantReady {
+				t.Errorf("readiness = %d; want %d", code, tc.wantReady)
+			}
+		})
+	}
+}
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc          string
+		token         string
+		authorization string
+		want          int
+	}{
+		{"Disabled", "", "Bearer secret", http.StatusForbidden},
+		{"MissingToken", "secret", "", http.StatusUnauthorized},
+		{"WrongToken", "secret", "Bearer guess", http.StatusUnauthorized},
+		{"NotBearer", "secret", "Basic secret", http.StatusUnauthorized},
+		{"Valid", "secret", "Bearer secret", http.StatusNoContent},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			h := newOpsHandler(nil, tc.token)
+			if code := get(h.router, "/admin/ping", tc.authorization); code != tc.want {
+				t.Errorf("status = %d; want %d", code, tc.want)
+			}
+		})
+	}
+}
+
+func TestHTTPServer_StopDrainsBeforeShutdown(t *testing.T) {
+	t.Parallel()
+
+	h := newOpsHandler(nil, "")
+	srv, err := NewHTTPServer(h, &config.HTTP{ShutdownTimeout: time.Second, DrainDelay: 200 * time.Millisecond},
+		logger.NewNop())
+	if err != nil {
+		t.Fatalf("NewHTTPServer() error = %v", err)
+	}
+
+	start := time.Now()
+	stopped := make(chan error, 1)
+	go func() { stopped <- srv.Stop(context.Background()) }()
+
+	deadline := time.Now().Add(time.Second)
+	for get(h.router, "/health/ready", "") != http.StatusServiceUnavailable {
+		if time.Now().After(deadline) {
+			t.Fatal("readiness still ok after Stop")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err = <-stopped; err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < srv.DrainDelay() {
+		t.Errorf("Stop() returned after %s; want at least the %s drain delay", elapsed, srv.DrainDelay())
+	}
+}
@@ -0,0 +1,76 @@
+package httpt
+
+import (
+	"calendar-wbf/internal/config"
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/cache"
+	"calendar-wbf/pkg/logger"
+)
+
+type Option func(*CalendarHandler)
+
+func WithBuildInfo(name, version string) Option {
+	return func(h *CalendarHandler) {
+		h.build = newBuildInfo(name, version)
+	}
+}
+
+func WithCache(eventCache cache.Cache[string, *entity.Event]) Option {
+	return func(h *CalendarHandler) {
+		h.cache = eventCache
+	}
+}
+
+func WithCalDAV(caldav *CalDAV) Option {
+	return func(h *CalendarHandler) {
+		h.caldav = caldav
+	}
+}
+
+func WithConfigStore(store *config.Store) Option {
+	return func(h *CalendarHandler) {
+		h.config = store
+	}
+}
+
+func WithDigest(digests DigestService) Option {
+	return func(h *CalendarHandler) {
+		h.digests = digests
+	}
+}
+
+func WithSchedules(schedules ScheduleService) Option {
+	return func(h *CalendarHandler) {
+		h.schedules = schedules
+	}
+}
+
+func WithAttachments(attachments AttachmentService) Option {
+	return func(h *CalendarHandler) {
+		h.attachments = attachments
+	}
+}
+
+func WithTemplates(templates TemplateService) Option {
+	return func(h *CalendarHandler) {
+		h.templates = templates
+	}
+}
+
+func WithPrivacy(privacy PrivacyService) Option {
+	return func(h *CalendarHandler) {
+		h.privacy = privacy
+	}
+}
+
+func WithLogRing(ring *logger.Ring) Option {
+	return func(h *CalendarHandler) {
+		h.logRing = ring
+	}
+}
+
+func WithIdempotency(store *IdempotencyStore) Option {
+	return func(h *CalendarHandler) {
+		h.idempotency = store
+	}
+}
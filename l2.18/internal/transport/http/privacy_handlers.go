@@ -0,0 +1,203 @@
+package httpt
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const _exportContentType = "application/zip"
+
+type PrivacyService interface {
+	StartExport(ctx context.Context, userID uint64) (*entity.ExportJob, error)
+	GetExport(ctx context.Context, id string) (*entity.ExportJob, error)
+	OpenExport(ctx context.Context, id string) (*entity.ExportJob, io.ReadCloser, error)
+	Erase(ctx context.Context, userID uint64) (*entity.EraseReport, error)
+}
+
+// @Summary Выгрузить данные пользователя
+// @Description Ставит в очередь сборку архива со всеми данными пользователя арендатора из заголовка: data.json
+// @Description (события, теги, настройки, шаблоны, вложения, записи аудита), events.ics и файлы вложений
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param user_id path int true "ID пользователя"
+// @Success 202 {object} entity.ExportJob
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 401 {object} httpt.ErrorResponse
+// @Router /admin/users/{user_id}/export [post]
+func (h *CalendarHandler) exportUserHandler(c *gin.Context) {
+	const op = "transport.exportUserHandler"
+	log := h.log.Ctx(c.Request.Context())
+
+	if h.privacy == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Privacy operations are not configured"})
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil || userID == 0 {
+		h.handleUserIDError(c, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	job, err := h.privacy.StartExport(ctx, userID)
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "user export started via admin API",
+		logger.String("export_id", job.ID),
+		logger.Uint64("user_id", userID),
+		logger.String("client_ip", c.ClientIP()),
+	)
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// @Summary Состояние выгрузки
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param export_id path string true "ID выгрузки"
+// @Success 200 {object} entity.ExportJob
+// @Failure 401 {object} httpt.ErrorResponse
+// @Failure 404 {object} httpt.ErrorResponse
+// @Router /admin/exports/{export_id} [get]
+func (h *CalendarHandler) exportStatusHandler(c *gin.Context) {
+	const op = "transport.exportStatusHandler"
+
+	if h.privacy == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Privacy operations are not configured"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	job, err := h.privacy.GetExport(ctx, c.Param("export_id"))
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// @Summary Скачать выгрузку
+// @Description Отдает zip-архив готовой выгрузки; архив хранится PRIVACY_EXPORT_TTL
+// @Tags Admin
+// @Produce application/zip
+// @Security BearerAuth
+// @Param export_id path string true "ID выгрузки"
+// @Success 200 {file} file
+// @Failure 401 {object} httpt.ErrorResponse
+// @Failure 404 {object} httpt.ErrorResponse
+// @Failure 409 {object} httpt.ErrorResponse
+// @Router /admin/exports/{export_id}/download [get]
+func (h *CalendarHandler) downloadExportHandler(c *gin.Context) {
+	const op = "transport.downloadExportHandler"
+
+	if h.privacy == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Privacy operations are not configured"})
+		return
+	}
+
+	job, archive, err := h.privacy.OpenExport(c.Request.Context(), c.Param("export_id"))
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+	defer archive.Close()
+
+	name := "calendar-export-" + strconv.FormatUint(job.UserID, 10) + "-" + job.CompletedAt.Format("20060102") + ".zip"
+	c.DataFromReader(http.StatusOK, job.Size, _exportContentType, archive, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": name}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// @Summary Удалить данные пользователя
+// @Description Удаляет события, вложения, теги, настройки, шаблоны и выгрузки пользователя из хранилищ, кэша и
+// @Description индексов, затем проверяет их повторно. remaining перечисляет то, что осталось; verified — что ничего
+// @Description не осталось. Повторный вызов безопасен
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param user_id path int true "ID пользователя"
+// @Success 200 {object} entity.EraseReport
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 401 {object} httpt.ErrorResponse
+// @Router /admin/users/{user_id} [delete]
+func (h *CalendarHandler) eraseUserHandler(c *gin.Context) {
+	const op = "transport.eraseUserHandler"
+	log := h.log.Ctx(c.Request.Context())
+
+	if h.privacy == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Privacy operations are not configured"})
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 64)
+	if err != nil || userID == 0 {
+		h.handleUserIDError(c, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	report, err := h.privacy.Erase(ctx, userID)
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+	h.eraseIdempotentResponses(report)
+
+	log.LogAttrs(ctx, logger.WarnLevel, "user erased via admin API",
+		logger.Uint64("user_id", userID),
+		logger.Bool("verified", report.Verified),
+		logger.String("client_ip", c.ClientIP()),
+	)
+
+	c.JSON(http.StatusOK, report)
+}
+
+// eraseIdempotentResponses drops the responses stored for the user's
+// Idempotency-Keys and adds them to the report.
+func (h *CalendarHandler) eraseIdempotentResponses(report *entity.EraseReport) {
+	if h.idempotency == nil {
+		return
+	}
+
+	report.Removed["idempotency"] = h.idempotency.PurgeScope(report.TenantID, report.UserID)
+	if left := h.idempotency.CountScope(report.TenantID, report.UserID); left > 0 {
+		if report.Remaining == nil {
+			report.Remaining = make(map[string]int)
+		}
+		report.Remaining["idempotency"] = left
+		report.Verified = false
+	}
+}
+
+func (h *CalendarHandler) handleUserIDError(c *gin.Context, op string) {
+	log := h.log.Ctx(c.Request.Context())
+
+	log.LogAttrs(c.Request.Context(), logger.WarnLevel, "invalid user ID",
+		logger.String("op", op),
+		logger.String("user_id", c.Param("user_id")),
+		logger.String("client_ip", c.ClientIP()),
+	)
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+}
@@ -0,0 +1,53 @@
+package httpt
+
+import (
+	"context"
+	"net/http"
+
+	"calendar-wbf/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Быстрое создание события
+// @Description Распознает событие из фразы на русском или английском языке,
+// @Description например "Обед с Анной завтра в 13:00 на 1ч". Без create возвращает черновик для подтверждения.
+// @Tags Events
+// @Accept json
+// @Produce json
+// @Param request body QuickAddRequest true "Текст события и часовой пояс пользователя"
+// @Success 200 {object} httpt.QuickAddResponse
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 500 {object} httpt.ErrorResponse
+// @Router /quick_add [post]
+func (h *CalendarHandler) quickAddHandler(c *gin.Context) {
+	const op = "transport.quickAddHandler"
+	log := h.log.Ctx(c.Request.Context())
+
+	var req QuickAddRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleBindError(c, err, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	result, err := h.svc.QuickAdd(ctx, req.UserID, req.Text, req.Timezone, req.Create)
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+
+	if result.Created {
+		log.LogAttrs(ctx, logger.InfoLevel, "event quick added successfully",
+			logger.Uint64("event_id", result.Event.ID),
+		)
+	}
+
+	if result.Event != nil {
+		result.Warnings = h.workingTimeWarnings(ctx, req.UserID, result.Event.Date, result.Event.Duration)
+	}
+
+	c.JSON(http.StatusOK, QuickAddResponse(*result))
+}
@@ -18,21 +18,75 @@ import (
 // @license.url     https://github.com/aws/mit-0
 // @host            localhost:8080
 // @BasePath        /
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
 func (h *CalendarHandler) setupRoutes() {
-	h.router.GET("/health", func(c *gin.Context) {
-		c.Status(http.StatusOK)
-	})
+	h.router.GET("/health", h.livenessHandler)
+	h.router.GET("/health/live", h.livenessHandler)
+	h.router.GET("/health/ready", h.readinessHandler)
+	h.router.GET("/version", h.versionHandler)
 
 	h.router.GET("/", func(c *gin.Context) {
 		c.HTML(http.StatusOK, "index.html", gin.H{})
 	})
 
-	h.router.POST("/create_event", h.createEventHandler)
-	h.router.POST("/update_event", h.updateEventHandler)
-	h.router.POST("/delete_event", h.deleteEventHandler)
-	h.router.POST("/events_for_day", h.getEventsForDayHandler)
-	h.router.POST("/events_for_week", h.getEventsForWeekHandler)
-	h.router.POST("/events_for_month", h.getEventsForMonthsHandler)
+	api := h.router.Group("", h.tenantMiddleware())
+	idempotent := h.idempotencyMiddleware()
+
+	api.POST("/create_event", idempotent, h.createEventHandler)
+	api.POST("/quick_add", idempotent, h.quickAddHandler)
+	api.POST("/get_event/:id", h.getEventHandler)
+	api.POST("/update_event/:id", idempotent, h.updateEventHandler)
+	api.POST("/delete_event/:id", idempotent, h.deleteEventHandler)
+	api.POST("/events_for_day", h.getEventsForDayHandler)
+	api.POST("/events_for_week", h.getEventsForWeekHandler)
+	api.POST("/events_for_month", h.getEventsForMonthsHandler)
+
+	api.POST("/graphql", idempotent, h.graphqlHandler)
+	api.GET("/graphql", h.graphqlHandler)
+	api.GET("/graphql/schema", h.graphqlSchemaHandler)
+
+	api.POST("/upload_attachment/:id", h.uploadAttachmentHandler)
+	api.POST("/attachments/:id", h.listAttachmentsHandler)
+	api.GET("/download_attachment/:id/:attachment_id", h.downloadAttachmentHandler)
+	api.POST("/delete_attachment/:id/:attachment_id", idempotent, h.deleteAttachmentHandler)
+
+	api.POST("/save_template", h.saveTemplateHandler)
+	api.POST("/templates", h.listTemplatesHandler)
+	api.POST("/get_template/:id", h.getTemplateHandler)
+	api.POST("/delete_template/:id", h.deleteTemplateHandler)
+	api.POST("/create_from_template/:id", idempotent, h.createFromTemplateHandler)
+
+	api.POST("/save_tag", h.saveTagHandler)
+	api.POST("/tags", h.listTagsHandler)
+	api.POST("/delete_tag", h.deleteTagHandler)
+
+	api.POST("/save_digest", h.saveDigestHandler)
+	api.POST("/delete_digest", h.deleteDigestHandler)
+	api.POST("/digest_preview", h.digestPreviewHandler)
+
+	api.POST("/save_work_schedule", h.saveWorkScheduleHandler)
+	api.POST("/work_schedule", h.workScheduleHandler)
+	api.POST("/holidays", h.holidaysHandler)
+
+	if h.caldav != nil {
+		h.caldav.Register(api)
+	}
+
+	admin := h.router.Group("/admin", h.adminAuthMiddleware())
+	admin.GET("/cache", h.cacheInfoHandler)
+	admin.DELETE("/cache", h.cachePurgeHandler)
+	admin.GET("/config", h.configHandler)
+	admin.GET("/log_level", h.getLogLevelHandler)
+	admin.PUT("/log_level", h.setLogLevelHandler)
+	admin.GET("/logs", h.logsHandler)
+
+	adminTenant := admin.Group("", h.adminTenantMiddleware())
+	adminTenant.POST("/users/:user_id/export", h.exportUserHandler)
+	adminTenant.DELETE("/users/:user_id", h.eraseUserHandler)
+	adminTenant.GET("/exports/:export_id", h.exportStatusHandler)
+	adminTenant.GET("/exports/:export_id/download", h.downloadExportHandler)
 
 	h.router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 }
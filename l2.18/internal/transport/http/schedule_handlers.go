@@ -0,0 +1,209 @@
+package httpt
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/holiday"
+	"calendar-wbf/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScheduleService interface {
+	SaveWorkSchedule(ctx context.Context, schedule *entity.WorkSchedule) (*entity.WorkSchedule, error)
+	GetWorkSchedule(ctx context.Context, userID uint64) (*entity.WorkSchedule, error)
+	Holidays(country string, year int) ([]holiday.Holiday, error)
+	Overlay(ctx context.Context, userID uint64, from, to time.Time) ([]*entity.Event, error)
+	CheckWorkingTime(ctx context.Context, userID uint64, start time.Time, duration time.Duration) ([]string, error)
+}
+
+// @Summary Сохранить рабочий календарь
+// @Description Страна для праздников, рабочие дни и часы в часовом поясе пользователя и личные выходные
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Param request body SaveWorkScheduleRequest true "Рабочий календарь"
+// @Success 200 {object} entity.WorkSchedule
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 500 {object} httpt.ErrorResponse
+// @Router /save_work_schedule [post]
+func (h *CalendarHandler) saveWorkScheduleHandler(c *gin.Context) {
+	const op = "transport.saveWorkScheduleHandler"
+	log := h.log.Ctx(c.Request.Context())
+
+	if h.schedules == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Work schedules are not configured"})
+		return
+	}
+
+	var req SaveWorkScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleBindError(c, err, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	schedule, err := h.schedules.SaveWorkSchedule(ctx, req.toSchedule())
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "work schedule saved successfully",
+		logger.Uint64("user_id", schedule.UserID),
+	)
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// @Summary Получить рабочий календарь
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Param request body WorkScheduleRequest true "UserID"
+// @Success 200 {object} entity.WorkSchedule
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 404 {object} httpt.ErrorResponse
+// @Router /work_schedule [post]
+func (h *CalendarHandler) workScheduleHandler(c *gin.Context) {
+	const op = "transport.workScheduleHandler"
+
+	if h.schedules == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Work schedules are not configured"})
+		return
+	}
+
+	var req WorkScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleBindError(c, err, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	schedule, err := h.schedules.GetWorkSchedule(ctx, req.UserID)
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// @Summary Государственные праздники
+// @Description Праздничные и перенесенные выходные дни страны за год из загруженных календарей
+// @Tags Schedule
+// @Accept json
+// @Produce json
+// @Param request body HolidaysRequest true "Страна и год"
+// @Success 200 {array} holiday.Holiday
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 404 {object} httpt.ErrorResponse
+// @Router /holidays [post]
+func (h *CalendarHandler) holidaysHandler(c *gin.Context) {
+	const op = "transport.holidaysHandler"
+
+	if h.schedules == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Work schedules are not configured"})
+		return
+	}
+
+	var req HolidaysRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleBindError(c, err, op)
+		return
+	}
+
+	holidays, err := h.schedules.Holidays(req.Country, req.Year)
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+	if holidays == nil {
+		holidays = []holiday.Holiday{}
+	}
+
+	c.JSON(http.StatusOK, holidays)
+}
+
+// withOverlay merges the holidays and days off of the user into a view of
+// the calendar days from through to. A view filtered to some tags has none:
+// the entries carry no tags. Years without holiday data are logged, and the
+// entries found are still merged.
+func (h *CalendarHandler) withOverlay(
+	ctx context.Context,
+	userID uint64,
+	from, to time.Time,
+	filter entity.TagFilter,
+	events []*entity.Event,
+) []*entity.Event {
+	if h.schedules == nil || len(filter.Include) > 0 {
+		return events
+	}
+
+	overlay, err := h.schedules.Overlay(ctx, userID, from, to)
+	switch {
+	case errors.Is(err, entity.ErrHolidaysNotCovered):
+		h.log.Ctx(ctx).LogAttrs(ctx, logger.WarnLevel, "holiday calendar does not cover the view",
+			logger.Uint64("user_id", userID),
+			logger.Any("error", err),
+		)
+	case err != nil:
+		h.log.Ctx(ctx).LogAttrs(ctx, logger.WarnLevel, "failed to load calendar overlay",
+			logger.Uint64("user_id", userID),
+			logger.Any("error", err),
+		)
+		return events
+	}
+	if len(overlay) == 0 {
+		return events
+	}
+
+	merged := append(slices.Clone(events), overlay...)
+	slices.SortStableFunc(merged, func(a, b *entity.Event) int {
+		return cmp.Compare(a.Date.Unix(), b.Date.Unix())
+	})
+	return merged
+}
+
+// workingTimeWarnings consults the working calendar of the user for an
+// event being scheduled. Failures only drop the warnings.
+func (h *CalendarHandler) workingTimeWarnings(
+	ctx context.Context,
+	userID uint64,
+	start time.Time,
+	duration time.Duration,
+) []string {
+	if h.schedules == nil {
+		return nil
+	}
+
+	warnings, err := h.schedules.CheckWorkingTime(ctx, userID, start, duration)
+	if err != nil {
+		h.log.Ctx(ctx).LogAttrs(ctx, logger.WarnLevel, "failed to check working time",
+			logger.Uint64("user_id", userID),
+			logger.Any("error", err),
+		)
+		return nil
+	}
+	return warnings
+}
+
+// resultWithWarnings is the confirmation of a mutating endpoint, with the
+// working calendar warnings when there are any.
+func resultWithWarnings(result string, warnings []string) gin.H {
+	body := gin.H{"result": result}
+	if len(warnings) > 0 {
+		body["warnings"] = warnings
+	}
+	return body
+}
@@ -0,0 +1,119 @@
+package httpt
+
+import (
+	"context"
+	"net/http"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagService interface {
+	SaveTag(ctx context.Context, userID uint64, name, color string) (*entity.Tag, error)
+	ListTags(ctx context.Context, userID uint64) ([]*entity.Tag, error)
+	DeleteTag(ctx context.Context, userID uint64, name string) error
+}
+
+// @Summary Создать или обновить тег
+// @Description Сохраняет тег пользователя с необязательным цветом
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Param request body SaveTagRequest true "Данные тега"
+// @Success 200 {object} entity.Tag
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 500 {object} httpt.ErrorResponse
+// @Router /save_tag [post]
+func (h *CalendarHandler) saveTagHandler(c *gin.Context) {
+	const op = "transport.saveTagHandler"
+	log := h.log.Ctx(c.Request.Context())
+
+	var req SaveTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleBindError(c, err, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	tag, err := h.tags.SaveTag(ctx, req.UserID, req.Name, req.Color)
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "tag saved successfully",
+		logger.String("tag", tag.Name),
+	)
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// @Summary Получить теги пользователя
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Param request body ListTagsRequest true "UserID"
+// @Success 200 {object} httpt.TagsResponse
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 500 {object} httpt.ErrorResponse
+// @Router /tags [post]
+func (h *CalendarHandler) listTagsHandler(c *gin.Context) {
+	const op = "transport.listTagsHandler"
+
+	var req ListTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleBindError(c, err, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	tags, err := h.tags.ListTags(ctx, req.UserID)
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+
+	c.JSON(http.StatusOK, TagsResponse{Result: tags})
+}
+
+// @Summary Удалить тег
+// @Description Удаляет тег пользователя и снимает его со всех событий
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Param request body DeleteTagRequest true "UserID и имя тега"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 404 {object} httpt.ErrorResponse
+// @Failure 500 {object} httpt.ErrorResponse
+// @Router /delete_tag [post]
+func (h *CalendarHandler) deleteTagHandler(c *gin.Context) {
+	const op = "transport.deleteTagHandler"
+	log := h.log.Ctx(c.Request.Context())
+
+	var req DeleteTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleBindError(c, err, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	if err := h.tags.DeleteTag(ctx, req.UserID, req.Name); err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "tag deleted successfully",
+		logger.String("tag", req.Name),
+	)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tag deleted successfully"})
+}
@@ -0,0 +1,259 @@
+package httpt
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TemplateService interface {
+	SaveTemplate(ctx context.Context, template *entity.Template) (*entity.Template, error)
+	GetTemplate(ctx context.Context, id, userID uint64) (*entity.Template, error)
+	ListTemplates(ctx context.Context, userID uint64) ([]*entity.Template, error)
+	DeleteTemplate(ctx context.Context, id, userID uint64) error
+	CreateEvent(
+		ctx context.Context,
+		id, userID uint64,
+		date time.Time,
+		overrides entity.TemplateOverrides,
+	) (*entity.Event, error)
+}
+
+// @Summary Создать или обновить шаблон события
+// @Description Без id создает шаблон, с id — заменяет шаблон автора. В title и text допустимы плейсхолдеры
+// @Description {{name}}; shared делает шаблон доступным всем пользователям арендатора
+// @Tags Templates
+// @Accept json
+// @Produce json
+// @Param request body SaveTemplateRequest true "Шаблон"
+// @Success 200 {object} entity.Template
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 404 {object} httpt.ErrorResponse
+// @Router /save_template [post]
+func (h *CalendarHandler) saveTemplateHandler(c *gin.Context) {
+	const op = "transport.saveTemplateHandler"
+	log := h.log.Ctx(c.Request.Context())
+
+	if h.templates == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Templates are not configured"})
+		return
+	}
+
+	var req SaveTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleBindError(c, err, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	template, err := h.templates.SaveTemplate(ctx, req.toTemplate())
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "template saved successfully",
+		logger.Uint64("template_id", template.ID),
+	)
+
+	c.JSON(http.StatusOK, template)
+}
+
+// @Summary Список шаблонов
+// @Description Шаблоны пользователя и общие шаблоны арендатора
+// @Tags Templates
+// @Accept json
+// @Produce json
+// @Param request body TemplateRequest true "UserID"
+// @Success 200 {object} httpt.TemplatesResponse
+// @Failure 400 {object} httpt.ErrorResponse
+// @Router /templates [post]
+func (h *CalendarHandler) listTemplatesHandler(c *gin.Context) {
+	const op = "transport.listTemplatesHandler"
+
+	if h.templates == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Templates are not configured"})
+		return
+	}
+
+	var req TemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.handleBindError(c, err, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	templates, err := h.templates.ListTemplates(ctx, req.UserID)
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+	if templates == nil {
+		templates = []*entity.Template{}
+	}
+
+	c.JSON(http.StatusOK, TemplatesResponse{Result: templates})
+}
+
+// @Summary Получить шаблон
+// @Tags Templates
+// @Accept json
+// @Produce json
+// @Param id path int true "ID шаблона"
+// @Param request body TemplateRequest true "UserID"
+// @Success 200 {object} entity.Template
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 404 {object} httpt.ErrorResponse
+// @Router /get_template/{id} [post]
+func (h *CalendarHandler) getTemplateHandler(c *gin.Context) {
+	const op = "transport.getTemplateHandler"
+
+	if h.templates == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Templates are not configured"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		h.handleTemplateIDError(c, op)
+		return
+	}
+
+	var req TemplateRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		h.handleBindError(c, bindErr, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	template, err := h.templates.GetTemplate(ctx, id, req.UserID)
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// @Summary Удалить шаблон
+// @Description Удалить шаблон может только его автор
+// @Tags Templates
+// @Accept json
+// @Produce json
+// @Param id path int true "ID шаблона"
+// @Param request body TemplateRequest true "UserID"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 404 {object} httpt.ErrorResponse
+// @Router /delete_template/{id} [post]
+func (h *CalendarHandler) deleteTemplateHandler(c *gin.Context) {
+	const op = "transport.deleteTemplateHandler"
+	log := h.log.Ctx(c.Request.Context())
+
+	if h.templates == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Templates are not configured"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		h.handleTemplateIDError(c, op)
+		return
+	}
+
+	var req TemplateRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		h.handleBindError(c, bindErr, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	if svcErr := h.templates.DeleteTemplate(ctx, id, req.UserID); svcErr != nil {
+		h.handleServiceError(c, svcErr, op)
+		return
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "template deleted successfully",
+		logger.Uint64("template_id", id),
+	)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted successfully"})
+}
+
+// @Summary Создать событие из шаблона
+// @Description Создает событие на date по шаблону. Заданные поля заменяют значения шаблона, vars заполняют
+// @Description плейсхолдеры; {{date}}, {{time}} и {{weekday}} подставляются из даты события
+// @Tags Templates
+// @Accept json
+// @Produce json
+// @Param id path int true "ID шаблона"
+// @Param request body CreateFromTemplateRequest true "Дата и переопределения"
+// @Success 200 {object} httpt.CreateFromTemplateResponse
+// @Failure 400 {object} httpt.ErrorResponse
+// @Failure 404 {object} httpt.ErrorResponse
+// @Router /create_from_template/{id} [post]
+func (h *CalendarHandler) createFromTemplateHandler(c *gin.Context) {
+	const op = "transport.createFromTemplateHandler"
+	log := h.log.Ctx(c.Request.Context())
+
+	if h.templates == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Templates are not configured"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		h.handleTemplateIDError(c, op)
+		return
+	}
+
+	var req CreateFromTemplateRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		h.handleBindError(c, bindErr, op)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), _defaultContextTimeout)
+	defer cancel()
+
+	event, err := h.templates.CreateEvent(ctx, id, req.UserID, req.Date, req.toOverrides())
+	if err != nil {
+		h.handleServiceError(c, err, op)
+		return
+	}
+
+	log.LogAttrs(ctx, logger.InfoLevel, "event created from template successfully",
+		logger.Uint64("event_id", event.ID),
+		logger.Uint64("template_id", id),
+	)
+
+	c.JSON(http.StatusOK, CreateFromTemplateResponse{
+		Event:    event,
+		Warnings: h.workingTimeWarnings(ctx, req.UserID, event.Date, event.Duration),
+	})
+}
+
+func (h *CalendarHandler) handleTemplateIDError(c *gin.Context, op string) {
+	log := h.log.Ctx(c.Request.Context())
+
+	log.LogAttrs(c.Request.Context(), logger.WarnLevel, "invalid template ID",
+		logger.String("op", op),
+		logger.String("template_id", c.Param("id")),
+		logger.String("client_ip", c.ClientIP()),
+	)
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+}
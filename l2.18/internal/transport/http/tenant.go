@@ -0,0 +1,211 @@
+package httpt
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"calendar-wbf/internal/config"
+	"calendar-wbf/internal/entity"
+	"calendar-wbf/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const _tokenParts = 3
+
+type subjectKey struct{}
+
+var (
+	errTenantRequired = errors.New("tenant required")
+	errTokenMissing   = errors.New("bearer token missing")
+	errTokenInvalid   = errors.New("bearer token invalid or expired")
+	errTenantMismatch = errors.New("tenant header does not match token")
+)
+
+// tenantMiddleware resolves the tenant of the request and stores it in the
+// context for the service and the logs. The settings are read per request,
+// so TENANT_* changes apply on reload.
+func (h *CalendarHandler) tenantMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "transport.tenantMiddleware"
+
+		var cfg config.Tenant
+		if h.config != nil {
+			cfg = h.config.Load().Tenant
+		}
+
+		tenantID, subject, err := resolveTenant(c.Request, &cfg)
+		if err != nil {
+			ctx := c.Request.Context()
+			h.log.Ctx(ctx).LogAttrs(ctx, logger.WarnLevel, "tenant not resolved",
+				logger.String("op", op),
+				logger.Any("error", err),
+				logger.String("path", c.Request.URL.Path),
+				logger.String("client_ip", c.ClientIP()),
+			)
+
+			switch {
+			case errors.Is(err, entity.ErrInvalidTenant):
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant"})
+			case errors.Is(err, errTenantMismatch):
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Tenant does not match token"})
+			case errors.Is(err, errTokenInvalid):
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			case errors.Is(err, errTokenMissing):
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Bearer token required"})
+			default:
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Tenant required"})
+			}
+			return
+		}
+
+		ctx := entity.WithTenant(c.Request.Context(), tenantID)
+		ctx = h.log.WithTenantID(ctx, tenantID)
+		if subject != "" {
+			ctx = context.WithValue(ctx, subjectKey{}, subject)
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// adminTenantMiddleware scopes admin requests to the tenant named by the
+// tenant header. The bearer token of admin requests is the admin token, so
+// tenant tokens are not consulted here.
+func (h *CalendarHandler) adminTenantMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		const op = "transport.adminTenantMiddleware"
+
+		var cfg config.Tenant
+		if h.config != nil {
+			cfg = h.config.Load().Tenant
+		}
+		cfg.TokenSecret = ""
+
+		tenantID, _, err := resolveTenant(c.Request, &cfg)
+		if err != nil {
+			ctx := c.Request.Context()
+			h.log.Ctx(ctx).LogAttrs(ctx, logger.WarnLevel, "tenant not resolved",
+				logger.String("op", op),
+				logger.Any("error", err),
+				logger.String("path", c.Request.URL.Path),
+			)
+
+			if errors.Is(err, entity.ErrInvalidTenant) {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid tenant"})
+			} else {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Tenant required"})
+			}
+			return
+		}
+
+		ctx := entity.WithTenant(c.Request.Context(), tenantID)
+		ctx = h.log.WithTenantID(ctx, tenantID)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// resolveTenant takes the tenant from the token claim when a secret is set
+// and from the header otherwise. A header sent along with a token must name
+// the same tenant. Requests naming no tenant get the default one unless
+// TENANT_REQUIRED is set. The subject of the token, if any, is returned too.
+func resolveTenant(r *http.Request, cfg *config.Tenant) (string, string, error) {
+	var tenantID, subject string
+	if cfg.Header != "" {
+		tenantID = strings.TrimSpace(r.Header.Get(cfg.Header))
+	}
+
+	if cfg.TokenSecret != "" {
+		claimed, sub, err := tenantFromToken(r.Header.Get("Authorization"), cfg.TokenSecret, cfg.TokenClaim, time.Now())
+		switch {
+		case errors.Is(err, errTokenMissing) && tenantID == "" && !cfg.Required:
+			return entity.DefaultTenant, "", nil
+		case err != nil:
+			return "", "", err
+		case tenantID != "" && tenantID != claimed:
+			return "", "", errTenantMismatch
+		}
+		tenantID, subject = claimed, sub
+	}
+
+	if tenantID == "" {
+		if cfg.Required {
+			return "", "", errTenantRequired
+		}
+		return entity.DefaultTenant, "", nil
+	}
+
+	if !entity.ValidTenantID(tenantID) {
+		return "", "", entity.ErrInvalidTenant
+	}
+	return tenantID, subject, nil
+}
+
+// tenantFromToken verifies an HS256 JWT from the Authorization header and
+// returns the tenant claim and the sub claim. Expired tokens are rejected;
+// tokens without exp never expire.
+func tenantFromToken(authorization, secret, claim string, now time.Time) (string, string, error) {
+	token, found := strings.CutPrefix(authorization, "Bearer ")
+	if !found || token == "" {
+		return "", "", errTokenMissing
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != _tokenParts {
+		return "", "", errTokenInvalid
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, mac.Sum(nil)) {
+		return "", "", errTokenInvalid
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err = decodeTokenPart(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return "", "", errTokenInvalid
+	}
+
+	var claims map[string]any
+	if err = decodeTokenPart(parts[1], &claims); err != nil {
+		return "", "", errTokenInvalid
+	}
+	if exp, ok := claims["exp"].(float64); ok && now.Unix() >= int64(exp) {
+		return "", "", errTokenInvalid
+	}
+
+	tenantID, _ := claims[claim].(string)
+	if !entity.ValidTenantID(tenantID) {
+		return "", "", entity.ErrInvalidTenant
+	}
+	subject, _ := claims["sub"].(string)
+	return tenantID, subject, nil
+}
+
+// subjectFromContext returns the sub claim of the verified bearer token, ""
+// when the request was not authenticated by a token.
+func subjectFromContext(ctx context.Context) string {
+	subject, _ := ctx.Value(subjectKey{}).(string)
+	return subject
+}
+
+func decodeTokenPart(part string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,90 @@
+package httpt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"calendar-wbf/internal/config"
+	"calendar-wbf/internal/entity"
+)
+
+const _testSecret = "tenant-secret"
+
+func signToken(t *testing.T, secret string, claims map[string]any) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestResolveTenant(t *testing.T) {
+	t.Parallel()
+
+	expired := time.Now().Add(-time.Minute).Unix()
+	headerOnly := config.Tenant{Header: "X-Tenant-ID", TokenClaim: "tenant"}
+	withSecret := headerOnly
+	withSecret.TokenSecret = _testSecret
+	required := headerOnly
+	required.Required = true
+
+	testCases := []struct {
+		desc    string
+		cfg     config.Tenant
+		header  string
+		token   string
+		want    string
+		wantErr error
+	}{
+		{"NoConfig", config.Tenant{}, "hr", "", entity.DefaultTenant, nil},
+		{"Header", headerOnly, "hr", "", "hr", nil},
+		{"Default", headerOnly, "", "", entity.DefaultTenant, nil},
+		{"InvalidHeader", headerOnly, "HR Dept", "", "", entity.ErrInvalidTenant},
+		{"Required", required, "", "", "", errTenantRequired},
+		{"Token", withSecret, "", signToken(t, _testSecret, map[string]any{"tenant": "eng"}), "eng", nil},
+		{"TokenMatchesHeader", withSecret, "eng", signToken(t, _testSecret, map[string]any{"tenant": "eng"}), "eng", nil},
+		{
+			"TokenMismatch", withSecret, "hr", signToken(t, _testSecret, map[string]any{"tenant": "eng"}),
+			"", errTenantMismatch,
+		},
+		{"HeaderWithoutToken", withSecret, "hr", "", "", errTokenMissing},
+		{"WrongSecret", withSecret, "", signToken(t, "other", map[string]any{"tenant": "eng"}), "", errTokenInvalid},
+		{
+			"Expired", withSecret, "", signToken(t, _testSecret, map[string]any{"tenant": "eng", "exp": expired}),
+			"", errTokenInvalid,
+		},
+		{"MissingClaim", withSecret, "", signToken(t, _testSecret, map[string]any{"sub": "7"}), "", entity.ErrInvalidTenant},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest("POST", "/events_for_day", nil)
+			if tc.header != "" {
+				req.Header.Set("X-Tenant-ID", tc.header)
+			}
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+
+			got, _, err := resolveTenant(req, &tc.cfg)
+			if got != tc.want || !errors.Is(err, tc.wantErr) {
+				t.Errorf("resolveTenant() = %q, %v; want %q, %v", got, err, tc.want, tc.wantErr)
+			}
+		})
+	}
+}
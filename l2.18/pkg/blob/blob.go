@@ -0,0 +1,146 @@
+// Package blob stores opaque binary objects, such as event attachments,
+// under slash-separated keys.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	_dirPerm  = 0o750
+	_filePerm = 0o640
+)
+
+var (
+	ErrNotFound   = errors.New("blob: not found")
+	ErrInvalidKey = errors.New("blob: invalid key")
+)
+
+// Store is implemented by blob backends. Put replaces an existing object and
+// returns the number of bytes written; a failed Put leaves no object behind.
+// Deleting a missing object is not an error.
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// FSStore keeps each object in a file under root.
+type FSStore struct {
+	root string
+}
+
+var _ Store = (*FSStore)(nil)
+
+func NewFSStore(root string) (*FSStore, error) {
+	if err := os.MkdirAll(root, _dirPerm); err != nil {
+		return nil, fmt.Errorf("blob: create root: %w", err)
+	}
+	return &FSStore{root: root}, nil
+}
+
+func (s *FSStore) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), _dirPerm); err != nil {
+		return 0, fmt.Errorf("blob: create dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, fmt.Errorf("blob: create temp file: %w", err)
+	}
+	// Removing the temp file fails once it has been renamed into place.
+	defer os.Remove(tmp.Name()) // nolint: errcheck
+
+	n, err := io.Copy(tmp, contextReader{ctx: ctx, r: r})
+	if err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("blob: write %s: %w", key, err)
+	}
+	if err = tmp.Chmod(_filePerm); err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("blob: chmod %s: %w", key, err)
+	}
+	if err = tmp.Close(); err != nil {
+		return 0, fmt.Errorf("blob: close %s: %w", key, err)
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("blob: rename %s: %w", key, err)
+	}
+	return n, nil
+}
+
+func (s *FSStore) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("blob: open %s: %w", key, err)
+	}
+	return f, nil
+}
+
+func (s *FSStore) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("blob: delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// path maps a key to a file under root. Segments may hold letters, digits,
+// '.', '-' and '_' but may not be empty or start with a dot, which keeps
+// keys from escaping root or clashing with temp files.
+func (s *FSStore) path(key string) (string, error) {
+	segments := strings.Split(key, "/")
+	for _, segment := range segments {
+		if segment == "" || segment[0] == '.' {
+			return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+		for _, c := range segment {
+			if !isKeyRune(c) {
+				return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+			}
+		}
+	}
+	return filepath.Join(append([]string{s.root}, segments...)...), nil
+}
+
+func isKeyRune(c rune) bool {
+	return c == '.' || c == '-' || c == '_' ||
+		(c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// contextReader stops a copy once the context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,88 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFSStore_PutOpenDelete(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store, err := NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := store.Put(ctx, "default/1/a.pdf", strings.NewReader("hello"))
+	if err != nil || n != 5 {
+		t.Fatalf("Put() = %d, %v; want 5, nil", n, err)
+	}
+	if _, err = store.Put(ctx, "default/1/a.pdf", strings.NewReader("replaced")); err != nil {
+		t.Fatalf("Put() replace: %v", err)
+	}
+
+	rc, err := store.Open(ctx, "default/1/a.pdf")
+	if err != nil {
+		t.Fatalf("Open(): %v", err)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(data) != "replaced" {
+		t.Errorf("Open() content = %q, want %q", data, "replaced")
+	}
+
+	if err = store.Delete(ctx, "default/1/a.pdf"); err != nil {
+		t.Fatalf("Delete(): %v", err)
+	}
+	if _, err = store.Open(ctx, "default/1/a.pdf"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open() after Delete error = %v, want ErrNotFound", err)
+	}
+	if err = store.Delete(ctx, "default/1/a.pdf"); err != nil {
+		t.Errorf("Delete() missing = %v, want nil", err)
+	}
+}
+
+func TestFSStore_FailedPutLeavesNothing(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	store, err := NewFSStore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errRead := errors.New("read failed")
+	_, err = store.Put(context.Background(), "t/1/x", io.MultiReader(strings.NewReader("part"), errReader{errRead}))
+	if !errors.Is(err, errRead) {
+		t.Fatalf("Put() error = %v, want %v", err, errRead)
+	}
+
+	entries, _ := os.ReadDir(root + "/t/1")
+	if len(entries) != 0 {
+		t.Errorf("files left after failed Put: %v", entries)
+	}
+}
+
+func TestFSStore_InvalidKey(t *testing.T) {
+	t.Parallel()
+
+	store, err := NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"", "../etc/passwd", "a//b", "a/.hidden", "a/b c", `a\b`, "/abs"} {
+		if _, err = store.Put(context.Background(), key, strings.NewReader("x")); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
@@ -0,0 +1,123 @@
+package cache
+
+type (
+	// arcPolicy is the Adaptive Replacement Cache of Megiddo and Modha. T1
+	// holds keys seen once recently, T2 keys seen at least twice; B1 and B2
+	// remember keys recently evicted from each and steer the target size p
+	// of T1, so a one-off scan cannot flush the frequently used keys in T2.
+	arcPolicy[K comparable] struct {
+		capacity int
+		p        int
+		t1, t2   *keyList[K]
+		b1, b2   *keyList[K]
+	}
+)
+
+func newARCPolicy[K comparable](capacity int) *arcPolicy[K] {
+	return &arcPolicy[K]{
+		capacity: capacity,
+		t1:       newKeyList[K](),
+		t2:       newKeyList[K](),
+		b1:       newKeyList[K](),
+		b2:       newKeyList[K](),
+	}
+}
+
+func (p *arcPolicy[K]) access(key K) {
+	if p.t1.remove(key) {
+		p.t2.pushFront(key)
+		return
+	}
+	p.t2.moveToFront(key)
+}
+
+func (p *arcPolicy[K]) add(key K) []K {
+	var victims []K
+
+	switch {
+	case p.b1.contains(key):
+		p.p = min(p.capacity, p.p+max(p.b2.len()/p.b1.len(), 1))
+		victims = p.replace(false)
+		p.b1.remove(key)
+		p.t2.pushFront(key)
+		return victims
+	case p.b2.contains(key):
+		p.p = max(0, p.p-max(p.b1.len()/p.b2.len(), 1))
+		victims = p.replace(true)
+		p.b2.remove(key)
+		p.t2.pushFront(key)
+		return victims
+	}
+
+	switch l1 := p.t1.len() + p.b1.len(); {
+	case l1 >= p.capacity:
+		if p.t1.len() < p.capacity {
+			p.b1.popBack()
+			victims = p.replace(false)
+		} else if victim, ok := p.t1.popBack(); ok {
+			victims = append(victims, victim)
+		}
+	case l1+p.t2.len()+p.b2.len() >= p.capacity:
+		if l1+p.t2.len()+p.b2.len() >= 2*p.capacity {
+			p.b2.popBack()
+		}
+		victims = p.replace(false)
+	}
+
+	p.t1.pushFront(key)
+	return victims
+}
+
+// replace moves the LRU key of T1 or T2 into its ghost list once the cache
+// is full, preferring T1 while it is above its target size p.
+func (p *arcPolicy[K]) replace(inB2 bool) []K {
+	if p.t1.len()+p.t2.len() < p.capacity {
+		return nil
+	}
+
+	if p.t1.len() > 0 && (p.t1.len() > p.p || (inB2 && p.t1.len() == p.p)) {
+		victim, _ := p.t1.popBack()
+		p.b1.pushFront(victim)
+		return []K{victim}
+	}
+
+	victim, ok := p.t2.popBack()
+	if !ok {
+		victim, _ = p.t1.popBack()
+		p.b1.pushFront(victim)
+		return []K{victim}
+	}
+	p.b2.pushFront(victim)
+	return []K{victim}
+}
+
+func (p *arcPolicy[K]) remove(key K) {
+	if !p.t1.remove(key) {
+		p.t2.remove(key)
+	}
+}
+
+func (p *arcPolicy[K]) resize(capacity int) []K {
+	p.capacity = capacity
+	p.p = min(p.p, capacity)
+
+	var victims []K
+	for p.t1.len()+p.t2.len() > capacity {
+		victims = append(victims, p.replace(false)...)
+	}
+	for p.b1.len() > capacity {
+		p.b1.popBack()
+	}
+	for p.b2.len() > capacity {
+		p.b2.popBack()
+	}
+	return victims
+}
+
+func (p *arcPolicy[K]) reset() {
+	p.p = 0
+	p.t1.reset()
+	p.t2.reset()
+	p.b1.reset()
+	p.b2.reset()
+}
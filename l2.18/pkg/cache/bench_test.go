@@ -0,0 +1,84 @@
+package cache_test
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"calendar-wbf/pkg/cache"
+	mock_logger "calendar-wbf/pkg/logger/mock"
+
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	_benchCapacity = 10_000
+	_benchKeySpace = 2 * _benchCapacity
+)
+
+func benchmarkCaches(b *testing.B) map[string]func() cache.Cache[int, int] {
+	b.Helper()
+
+	mockLogger := mock_logger.NewMockLogger(gomock.NewController(b))
+
+	return map[string]func() cache.Cache[int, int]{
+		"LRU": func() cache.Cache[int, int] {
+			c, _ := cache.NewLRUCache[int, int](_benchCapacity, mockLogger)
+			return c
+		},
+		"Sharded": func() cache.Cache[int, int] {
+			c, _ := cache.NewShardedCache[int, int](_benchCapacity, mockLogger)
+			return c
+		},
+	}
+}
+
+// BenchmarkCache_Parallel compares implementations under concurrent
+// read/write mixes: go test -bench=Cache_Parallel -cpu=1,4,16 ./pkg/cache.
+func BenchmarkCache_Parallel(b *testing.B) {
+	mixes := []struct {
+		name        string
+		readPercent int
+		cleanup     bool
+	}{
+		{"Read90", 90, false},
+		{"Read50", 50, false},
+		{"Read10", 10, false},
+		{"Read90WithCleanup", 90, true},
+	}
+
+	for name, newCache := range benchmarkCaches(b) {
+		for _, mix := range mixes {
+			b.Run(fmt.Sprintf("%s/%s", name, mix.name), func(b *testing.B) {
+				c := newCache()
+				for key := range _benchCapacity {
+					c.Put(key, key, time.Hour)
+				}
+
+				// Nothing expires within the run, so this isolates the cost of
+				// the expiry pass itself: a full scan for LRU, a heap peek per
+				// shard for the sharded cache.
+				if mix.cleanup {
+					c.StartCleanup(time.Millisecond)
+					defer c.StopCleanup()
+				}
+
+				b.ReportAllocs()
+				b.ResetTimer()
+
+				b.RunParallel(func(pb *testing.PB) {
+					rng := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+					for pb.Next() {
+						key := rng.IntN(_benchKeySpace)
+						if rng.IntN(100) < mix.readPercent {
+							c.Get(key)
+						} else {
+							c.Put(key, key, time.Hour)
+						}
+					}
+				})
+			})
+		}
+	}
+}
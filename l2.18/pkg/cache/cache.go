@@ -8,12 +8,23 @@ import (
 
 type Cache[K comparable, V any] interface {
 	Get(key K) (V, bool)
+	// Peek returns an unexpired value without updating recency or stats.
+	Peek(key K) (V, bool)
 	Put(key K, value V, ttl time.Duration)
 	Has(key K) bool
+	// Delete removes the key and reports whether it was present.
+	Delete(key K) bool
+	// Keys returns the keys of unexpired entries.
+	Keys() []K
 	Len() int
 	Capacity() int
+	Resize(capacity int) error
 	Purge()
+	Stats() Stats
+	// Snapshot returns unexpired entries from the least to the most recently
+	// used, for SaveSnapshot.
+	Snapshot() []SnapshotEntry[K, V]
 	StartCleanup(interval time.Duration)
 	StopCleanup()
-	SetOnEvicted(onEvicted func(key K, value V))
+	SetOnEvicted(onEvicted func(key K, value V, reason EvictionReason))
 }
@@ -0,0 +1,109 @@
+package cache
+
+import "container/heap"
+
+type (
+	expiryEntry[K comparable, V any] struct {
+		key     K
+		value   V
+		expires int64
+
+		heapIndex    int
+		heapDeadline int64
+	}
+
+	// expiryQueue orders entries by heapDeadline so cleanup only touches
+	// entries at the top. The deadline is a lower bound of expires: extending
+	// a TTL leaves the entry in place and nextExpired re-sifts it when it
+	// surfaces, keeping refreshes of hot keys O(1).
+	expiryQueue[K comparable, V any] struct {
+		heap expiryHeap[K, V]
+	}
+
+	expiryHeap[K comparable, V any] []*expiryEntry[K, V]
+)
+
+func newExpiryEntry[K comparable, V any](key K, value V) *expiryEntry[K, V] {
+	return &expiryEntry[K, V]{key: key, value: value, heapIndex: -1}
+}
+
+func (e *expiryEntry[K, V]) expired(now int64) bool {
+	return e.expires != 0 && now > e.expires
+}
+
+// schedule only touches the heap when the entry isn't in it yet or its
+// deadline moves earlier; later deadlines and removed TTLs are reconciled
+// lazily by nextExpired.
+func (q *expiryQueue[K, V]) schedule(e *expiryEntry[K, V], expires int64) {
+	e.expires = expires
+
+	switch {
+	case expires == 0:
+	case e.heapIndex < 0:
+		e.heapDeadline = expires
+		heap.Push(&q.heap, e)
+	case expires < e.heapDeadline:
+		e.heapDeadline = expires
+		heap.Fix(&q.heap, e.heapIndex)
+	}
+}
+
+func (q *expiryQueue[K, V]) unschedule(e *expiryEntry[K, V]) {
+	if e.heapIndex >= 0 {
+		heap.Remove(&q.heap, e.heapIndex)
+	}
+}
+
+// nextExpired returns the earliest entry that has expired by now. The caller
+// must unschedule it before asking again.
+func (q *expiryQueue[K, V]) nextExpired(now int64) (*expiryEntry[K, V], bool) {
+	for len(q.heap) > 0 && q.heap[0].heapDeadline < now {
+		e := q.heap[0]
+		switch {
+		case e.expired(now):
+			return e, true
+		case e.expires == 0:
+			heap.Pop(&q.heap)
+		default:
+			e.heapDeadline = e.expires
+			heap.Fix(&q.heap, 0)
+		}
+	}
+	return nil, false
+}
+
+func (q *expiryQueue[K, V]) reset() {
+	for _, e := range q.heap {
+		e.heapIndex = -1
+	}
+	q.heap = nil
+}
+
+func (h expiryHeap[K, V]) Len() int { return len(h) }
+
+func (h expiryHeap[K, V]) Less(i, j int) bool { return h[i].heapDeadline < h[j].heapDeadline }
+
+func (h expiryHeap[K, V]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].heapIndex = i
+	h[j].heapIndex = j
+}
+
+func (h *expiryHeap[K, V]) Push(x any) {
+	e, ok := x.(*expiryEntry[K, V])
+	if !ok {
+		return
+	}
+	e.heapIndex = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *expiryHeap[K, V]) Pop() any {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	e.heapIndex = -1
+	*h = old[:n-1]
+	return e
+}
@@ -0,0 +1,68 @@
+package cache
+
+import "container/list"
+
+// keyList is a recency-ordered set of keys: front is most recent.
+type keyList[K comparable] struct {
+	order *list.List
+	elems map[K]*list.Element
+}
+
+func newKeyList[K comparable]() *keyList[K] {
+	return &keyList[K]{
+		order: list.New(),
+		elems: make(map[K]*list.Element),
+	}
+}
+
+func (l *keyList[K]) len() int {
+	return l.order.Len()
+}
+
+func (l *keyList[K]) contains(key K) bool {
+	_, ok := l.elems[key]
+	return ok
+}
+
+func (l *keyList[K]) pushFront(key K) {
+	l.elems[key] = l.order.PushFront(key)
+}
+
+func (l *keyList[K]) moveToFront(key K) {
+	if elem, ok := l.elems[key]; ok {
+		l.order.MoveToFront(elem)
+	}
+}
+
+func (l *keyList[K]) remove(key K) bool {
+	elem, ok := l.elems[key]
+	if !ok {
+		return false
+	}
+	l.order.Remove(elem)
+	delete(l.elems, key)
+	return true
+}
+
+func (l *keyList[K]) back() (K, bool) {
+	var zero K
+	elem := l.order.Back()
+	if elem == nil {
+		return zero, false
+	}
+	key, _ := elem.Value.(K)
+	return key, true
+}
+
+func (l *keyList[K]) popBack() (K, bool) {
+	key, ok := l.back()
+	if ok {
+		l.remove(key)
+	}
+	return key, ok
+}
+
+func (l *keyList[K]) reset() {
+	l.order.Init()
+	clear(l.elems)
+}
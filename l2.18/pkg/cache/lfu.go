@@ -0,0 +1,125 @@
+package cache
+
+import "container/list"
+
+type (
+	// lfuPolicy evicts the least frequently used key in O(1): keys live in
+	// per-frequency buckets ordered by frequency, and within a bucket by
+	// recency, so ties go to the least recently used key.
+	lfuPolicy[K comparable] struct {
+		capacity int
+		buckets  *list.List
+		nodes    map[K]*lfuNode[K]
+	}
+
+	lfuBucket[K comparable] struct {
+		freq int
+		keys *list.List
+	}
+
+	lfuNode[K comparable] struct {
+		bucket *list.Element
+		elem   *list.Element
+	}
+)
+
+func newLFUPolicy[K comparable](capacity int) *lfuPolicy[K] {
+	return &lfuPolicy[K]{
+		capacity: capacity,
+		buckets:  list.New(),
+		nodes:    make(map[K]*lfuNode[K]),
+	}
+}
+
+func (p *lfuPolicy[K]) access(key K) {
+	node, ok := p.nodes[key]
+	if !ok {
+		return
+	}
+
+	current := bucketOf[K](node.bucket)
+	next := node.bucket.Next()
+	if next == nil || bucketOf[K](next).freq != current.freq+1 {
+		next = p.buckets.InsertAfter(&lfuBucket[K]{freq: current.freq + 1, keys: list.New()}, node.bucket)
+	}
+
+	p.detach(node)
+	node.bucket = next
+	node.elem = bucketOf[K](next).keys.PushFront(key)
+}
+
+func (p *lfuPolicy[K]) add(key K) []K {
+	var victims []K
+	for len(p.nodes) >= p.capacity {
+		victim, ok := p.evict()
+		if !ok {
+			break
+		}
+		victims = append(victims, victim)
+	}
+
+	first := p.buckets.Front()
+	if first == nil || bucketOf[K](first).freq != 1 {
+		first = p.buckets.PushFront(&lfuBucket[K]{freq: 1, keys: list.New()})
+	}
+
+	p.nodes[key] = &lfuNode[K]{
+		bucket: first,
+		elem:   bucketOf[K](first).keys.PushFront(key),
+	}
+	return victims
+}
+
+func (p *lfuPolicy[K]) remove(key K) {
+	if node, ok := p.nodes[key]; ok {
+		p.detach(node)
+		delete(p.nodes, key)
+	}
+}
+
+func (p *lfuPolicy[K]) resize(capacity int) []K {
+	p.capacity = capacity
+
+	var victims []K
+	for len(p.nodes) > p.capacity {
+		victim, ok := p.evict()
+		if !ok {
+			break
+		}
+		victims = append(victims, victim)
+	}
+	return victims
+}
+
+func (p *lfuPolicy[K]) reset() {
+	p.buckets.Init()
+	clear(p.nodes)
+}
+
+func (p *lfuPolicy[K]) evict() (K, bool) {
+	var zero K
+
+	first := p.buckets.Front()
+	if first == nil {
+		return zero, false
+	}
+
+	oldest := bucketOf[K](first).keys.Back()
+	key, _ := oldest.Value.(K)
+	p.remove(key)
+	return key, true
+}
+
+// detach unlinks the node from its bucket and drops the bucket once empty.
+func (p *lfuPolicy[K]) detach(node *lfuNode[K]) {
+	bucket := bucketOf[K](node.bucket)
+	bucket.keys.Remove(node.elem)
+	if bucket.keys.Len() == 0 {
+		p.buckets.Remove(node.bucket)
+	}
+}
+
+func bucketOf[K comparable](elem *list.Element) *lfuBucket[K] {
+	bucket, _ := elem.Value.(*lfuBucket[K])
+	return bucket
+}
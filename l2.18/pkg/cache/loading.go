@@ -0,0 +1,439 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"calendar-wbf/pkg/logger"
+)
+
+const (
+	_defaultLoadTTL     = 5 * time.Minute
+	_defaultLoadTimeout = 5 * time.Second
+)
+
+type (
+	LoadFunc[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+	// LoadingCache wraps a Cache with read-through loading. Concurrent
+	// GetOrLoad calls for one key share a single load; values past the
+	// refresh-ahead point or inside the stale window are served while a
+	// background load replaces them; not-found results are cached briefly.
+	// It implements Cache, where Get, Peek, Has and Keys only see fresh
+	// values.
+	LoadingCache[K comparable, V any] struct {
+		items Cache[K, *Loaded[V]]
+		log   logger.Logger
+
+		ttl          atomic.Int64
+		refreshAhead float64
+		staleTTL     time.Duration
+		negativeTTL  time.Duration
+		isNotFound   func(error) bool
+		notFound     error
+		loadTimeout  time.Duration
+
+		mu    sync.Mutex
+		calls map[K]*loadCall[V]
+	}
+
+	// Loaded is what LoadingCache keeps in the underlying cache: a value or a
+	// cached not-found error, with its freshness deadlines in Unix
+	// nanoseconds. It marshals to JSON so it can live in a remote tier; the
+	// error itself stays in process and only a not-found marker is shared.
+	Loaded[V any] struct {
+		value     V
+		err       error
+		notFound  bool
+		refreshAt int64
+		staleAt   int64
+	}
+
+	loadedJSON[V any] struct {
+		Value     V     `json:"value"`
+		NotFound  bool  `json:"not_found,omitempty"`
+		RefreshAt int64 `json:"refresh_at,omitempty"`
+		StaleAt   int64 `json:"stale_at,omitempty"`
+	}
+
+	LoadingOption func(*loadingConfig)
+
+	loadingConfig struct {
+		ttl          time.Duration
+		refreshAhead float64
+		staleTTL     time.Duration
+		negativeTTL  time.Duration
+		isNotFound   func(error) bool
+		notFound     error
+		loadTimeout  time.Duration
+	}
+
+	// filler is implemented by caches that store a loaded value more cheaply
+	// than a write, such as TieredCache, which broadcasts writes.
+	filler[K comparable, V any] interface {
+		Fill(key K, value V, ttl time.Duration)
+	}
+
+	loadCall[V any] struct {
+		done       chan struct{}
+		value      V
+		err        error
+		background bool
+		discarded  bool
+	}
+)
+
+// WithLoadTTL sets how long loaded values stay fresh.
+func WithLoadTTL(ttl time.Duration) LoadingOption {
+	return func(cfg *loadingConfig) {
+		cfg.ttl = ttl
+	}
+}
+
+// WithRefreshAhead starts a background reload once a value has lived the
+// given fraction of its TTL. Zero disables refresh-ahead.
+func WithRefreshAhead(fraction float64) LoadingOption {
+	return func(cfg *loadingConfig) {
+		cfg.refreshAhead = fraction
+	}
+}
+
+// WithStaleWhileRevalidate keeps expired values for d and serves them while
+// a background reload runs.
+func WithStaleWhileRevalidate(d time.Duration) LoadingOption {
+	return func(cfg *loadingConfig) {
+		cfg.staleTTL = d
+	}
+}
+
+// WithNegativeCaching caches loader errors matched by isNotFound for ttl, so
+// repeated lookups of a missing key don't reach the loader.
+func WithNegativeCaching(ttl time.Duration, isNotFound func(error) bool) LoadingOption {
+	return func(cfg *loadingConfig) {
+		cfg.negativeTTL = ttl
+		cfg.isNotFound = isNotFound
+	}
+}
+
+// WithSharedNegativeCaching caches loader errors matching notFound (via
+// errors.Is) for ttl, like WithNegativeCaching, and answers not-found
+// entries stored by another process, which carry no error, with notFound.
+// Without it such entries are reloaded.
+func WithSharedNegativeCaching(ttl time.Duration, notFound error) LoadingOption {
+	return func(cfg *loadingConfig) {
+		cfg.negativeTTL = ttl
+		cfg.notFound = notFound
+		cfg.isNotFound = nil
+		if notFound != nil {
+			cfg.isNotFound = func(err error) bool { return errors.Is(err, notFound) }
+		}
+	}
+}
+
+// WithLoadTimeout bounds a single loader call. Loads are detached from the
+// caller's context so one cancelled request doesn't fail the others waiting
+// on the same key.
+func WithLoadTimeout(d time.Duration) LoadingOption {
+	return func(cfg *loadingConfig) {
+		cfg.loadTimeout = d
+	}
+}
+
+func NewLoadingCache[K comparable, V any](
+	items Cache[K, *Loaded[V]],
+	log logger.Logger,
+	opts ...LoadingOption,
+) (*LoadingCache[K, V], error) {
+	cfg := loadingConfig{
+		ttl:         _defaultLoadTTL,
+		loadTimeout: _defaultLoadTimeout,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	switch {
+	case cfg.refreshAhead < 0 || cfg.refreshAhead >= 1:
+		return nil, fmt.Errorf("cache.NewLoadingCache: refresh-ahead must be in [0, 1), got %v", cfg.refreshAhead)
+	case cfg.staleTTL < 0 || cfg.negativeTTL < 0 || cfg.ttl < 0:
+		return nil, fmt.Errorf("cache.NewLoadingCache: durations must not be negative")
+	case cfg.negativeTTL > 0 && cfg.isNotFound == nil:
+		return nil, fmt.Errorf("cache.NewLoadingCache: negative caching needs a not-found predicate")
+	case cfg.loadTimeout <= 0:
+		return nil, fmt.Errorf("cache.NewLoadingCache: load timeout must be positive, got %v", cfg.loadTimeout)
+	}
+
+	c := &LoadingCache[K, V]{
+		items:        items,
+		log:          log,
+		refreshAhead: cfg.refreshAhead,
+		staleTTL:     cfg.staleTTL,
+		negativeTTL:  cfg.negativeTTL,
+		isNotFound:   cfg.isNotFound,
+		notFound:     cfg.notFound,
+		loadTimeout:  cfg.loadTimeout,
+		calls:        make(map[K]*loadCall[V]),
+	}
+	c.SetTTL(cfg.ttl)
+
+	return c, nil
+}
+
+// SetTTL changes the freshness TTL for values loaded from now on.
+func (c *LoadingCache[K, V]) SetTTL(ttl time.Duration) {
+	c.ttl.Store(int64(ttl))
+}
+
+// GetOrLoad returns the cached value for key or loads it. A stale or
+// refresh-due value is returned immediately and reloaded in the background.
+func (c *LoadingCache[K, V]) GetOrLoad(ctx context.Context, key K, load LoadFunc[K, V]) (V, error) {
+	var zero V
+
+	if item, ok := c.items.Get(key); ok {
+		switch {
+		case !item.notFound:
+			now := time.Now().UnixNano()
+			if item.refreshAt != 0 && now >= item.refreshAt {
+				c.startLoad(ctx, key, load, true)
+			}
+			return item.value, nil
+		case item.err != nil:
+			return zero, item.err
+		case c.notFound != nil:
+			return zero, c.notFound
+		}
+	}
+
+	call := c.startLoad(ctx, key, load, false)
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// startLoad joins the in-flight load for key or starts a new one.
+func (c *LoadingCache[K, V]) startLoad(ctx context.Context, key K, load LoadFunc[K, V], background bool) *loadCall[V] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if call, ok := c.calls[key]; ok {
+		return call
+	}
+
+	call := &loadCall[V]{done: make(chan struct{}), background: background}
+	c.calls[key] = call
+
+	go c.runLoad(context.WithoutCancel(ctx), key, load, call)
+	return call
+}
+
+func (c *LoadingCache[K, V]) runLoad(ctx context.Context, key K, load LoadFunc[K, V], call *loadCall[V]) {
+	ctx, cancel := context.WithTimeout(ctx, c.loadTimeout)
+	defer cancel()
+
+	call.value, call.err = load(ctx, key)
+
+	c.mu.Lock()
+	switch {
+	case call.discarded:
+		// A Put, Delete or Purge happened during the load and wins.
+	case call.err == nil:
+		item, ttl := c.newLoaded(call.value, c.loadTTL())
+		c.fill(key, item, ttl)
+	case c.negativeTTL > 0 && c.isNotFound(call.err):
+		c.fill(key, &Loaded[V]{err: call.err, notFound: true}, c.negativeTTL)
+	case call.background:
+		c.log.Warnw("cache background refresh failed",
+			"key", fmt.Sprint(key),
+			"error", call.err,
+		)
+	}
+	delete(c.calls, key)
+	c.mu.Unlock()
+
+	close(call.done)
+}
+
+func (c *LoadingCache[K, V]) loadTTL() time.Duration {
+	return time.Duration(c.ttl.Load())
+}
+
+// newLoaded wraps a fresh value and returns it with the TTL to store it
+// for. The underlying entry outlives ttl by the stale window; refreshAt and
+// staleAt mark when GetOrLoad should reload it and when plain reads stop
+// seeing it.
+func (c *LoadingCache[K, V]) newLoaded(value V, ttl time.Duration) (*Loaded[V], time.Duration) {
+	item := &Loaded[V]{value: value}
+	storeTTL := ttl
+
+	if ttl > 0 {
+		now := time.Now()
+		item.staleAt = now.Add(ttl).UnixNano()
+		item.refreshAt = item.staleAt
+		if c.refreshAhead > 0 {
+			item.refreshAt = now.Add(time.Duration(float64(ttl) * c.refreshAhead)).UnixNano()
+		}
+		storeTTL += c.staleTTL
+	}
+	return item, storeTTL
+}
+
+// fill stores the result of a load, which unlike Put is not a write other
+// replicas need to hear about.
+func (c *LoadingCache[K, V]) fill(key K, item *Loaded[V], ttl time.Duration) {
+	if f, ok := c.items.(filler[K, *Loaded[V]]); ok {
+		f.Fill(key, item, ttl)
+		return
+	}
+	c.items.Put(key, item, ttl)
+}
+
+// discardLoadLocked stops an in-flight load from overwriting a newer write.
+func (c *LoadingCache[K, V]) discardLoadLocked(key K) {
+	if call, ok := c.calls[key]; ok {
+		call.discarded = true
+	}
+}
+
+func (l *Loaded[V]) fresh(now int64) bool {
+	return !l.notFound && (l.staleAt == 0 || now < l.staleAt)
+}
+
+func (l *Loaded[V]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(loadedJSON[V]{l.value, l.notFound, l.refreshAt, l.staleAt})
+}
+
+func (l *Loaded[V]) UnmarshalJSON(data []byte) error {
+	var raw loadedJSON[V]
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*l = Loaded[V]{value: raw.Value, notFound: raw.NotFound, refreshAt: raw.RefreshAt, staleAt: raw.StaleAt}
+	return nil
+}
+
+func (c *LoadingCache[K, V]) Get(key K) (V, bool) {
+	var zero V
+
+	item, ok := c.items.Get(key)
+	if !ok || !item.fresh(time.Now().UnixNano()) {
+		return zero, false
+	}
+	return item.value, true
+}
+
+func (c *LoadingCache[K, V]) Peek(key K) (V, bool) {
+	var zero V
+
+	item, ok := c.items.Peek(key)
+	if !ok || !item.fresh(time.Now().UnixNano()) {
+		return zero, false
+	}
+	return item.value, true
+}
+
+func (c *LoadingCache[K, V]) Put(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.discardLoadLocked(key)
+	item, storeTTL := c.newLoaded(value, ttl)
+	c.items.Put(key, item, storeTTL)
+}
+
+func (c *LoadingCache[K, V]) Has(key K) bool {
+	_, ok := c.Peek(key)
+	return ok
+}
+
+func (c *LoadingCache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.discardLoadLocked(key)
+	return c.items.Delete(key)
+}
+
+func (c *LoadingCache[K, V]) Keys() []K {
+	keys := c.items.Keys()
+	now := time.Now().UnixNano()
+
+	fresh := keys[:0]
+	for _, key := range keys {
+		if item, ok := c.items.Peek(key); ok && item.fresh(now) {
+			fresh = append(fresh, key)
+		}
+	}
+	return fresh
+}
+
+func (c *LoadingCache[K, V]) Len() int {
+	return c.items.Len()
+}
+
+func (c *LoadingCache[K, V]) Capacity() int {
+	return c.items.Capacity()
+}
+
+func (c *LoadingCache[K, V]) Resize(capacity int) error {
+	return c.items.Resize(capacity)
+}
+
+func (c *LoadingCache[K, V]) Purge() {
+	c.mu.Lock()
+	for _, call := range c.calls {
+		call.discarded = true
+	}
+	c.mu.Unlock()
+
+	c.items.Purge()
+}
+
+// Snapshot returns fresh values with their freshness deadline; stale values
+// and cached not-found results are left out.
+func (c *LoadingCache[K, V]) Snapshot() []SnapshotEntry[K, V] {
+	items := c.items.Snapshot()
+	now := time.Now().UnixNano()
+
+	entries := make([]SnapshotEntry[K, V], 0, len(items))
+	for _, item := range items {
+		if item.Value.fresh(now) {
+			entries = append(entries, SnapshotEntry[K, V]{item.Key, item.Value.value, expiryTime(item.Value.staleAt)})
+		}
+	}
+	return entries
+}
+
+func (c *LoadingCache[K, V]) Stats() Stats {
+	return c.items.Stats()
+}
+
+func (c *LoadingCache[K, V]) StartCleanup(interval time.Duration) {
+	c.items.StartCleanup(interval)
+}
+
+func (c *LoadingCache[K, V]) StopCleanup() {
+	c.items.StopCleanup()
+}
+
+// SetOnEvicted registers a callback for evicted values; cached not-found
+// results are not reported.
+func (c *LoadingCache[K, V]) SetOnEvicted(onEvicted func(key K, value V, reason EvictionReason)) {
+	if onEvicted == nil {
+		c.items.SetOnEvicted(nil)
+		return
+	}
+
+	c.items.SetOnEvicted(func(key K, item *Loaded[V], reason EvictionReason) {
+		if !item.notFound {
+			onEvicted(key, item.value, reason)
+		}
+	})
+}
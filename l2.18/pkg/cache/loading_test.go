@@ -0,0 +1,319 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"calendar-wbf/pkg/cache"
+	mock_logger "calendar-wbf/pkg/logger/mock"
+
+	"go.uber.org/mock/gomock"
+)
+
+var errTestNotFound = errors.New("not found")
+
+type countingLoader struct {
+	calls  atomic.Int32
+	mu     sync.Mutex
+	value  string
+	err    error
+	gate   chan struct{}
+	inLoad chan struct{}
+}
+
+func (l *countingLoader) set(value string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.value, l.err = value, err
+}
+
+func (l *countingLoader) load(_ context.Context, _ int) (string, error) {
+	l.calls.Add(1)
+	if l.inLoad != nil {
+		l.inLoad <- struct{}{}
+	}
+	if l.gate != nil {
+		<-l.gate
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.value, l.err
+}
+
+func newTestLoadingCache(t *testing.T, opts ...cache.LoadingOption) *cache.LoadingCache[int, string] {
+	t.Helper()
+
+	mockLogger := mock_logger.NewMockLogger(gomock.NewController(t))
+
+	items, err := cache.NewLRUCache[int, *cache.Loaded[string]](10, mockLogger)
+	if err != nil {
+		t.Fatalf("NewLRUCache() error = %v", err)
+	}
+
+	c, err := cache.NewLoadingCache[int, string](items, mockLogger, opts...)
+	if err != nil {
+		t.Fatalf("NewLoadingCache() error = %v", err)
+	}
+	return c
+}
+
+func eventually(t *testing.T, desc string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", desc)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLoadingCache_DeduplicatesConcurrentLoads(t *testing.T) {
+	t.Parallel()
+
+	c := newTestLoadingCache(t)
+	loader := &countingLoader{value: "one", gate: make(chan struct{})}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make([]string, callers)
+
+	for i := range callers {
+		wg.Go(func() {
+			results[i], _ = c.GetOrLoad(context.Background(), 1, loader.load)
+		})
+	}
+
+	eventually(t, "first load", func() bool { return loader.calls.Load() == 1 })
+	time.Sleep(10 * time.Millisecond)
+	close(loader.gate)
+	wg.Wait()
+
+	if got := loader.calls.Load(); got != 1 {
+		t.Errorf("loader calls = %d; want 1", got)
+	}
+	for i, got := range results {
+		if got != "one" {
+			t.Errorf("results[%d] = %q; want \"one\"", i, got)
+		}
+	}
+	if value, ok := c.Get(1); !ok || value != "one" {
+		t.Errorf("Get(1) = %q, %v; want \"one\", true", value, ok)
+	}
+}
+
+func TestLoadingCache_NegativeCaching(t *testing.T) {
+	t.Parallel()
+
+	isNotFound := func(err error) bool { return errors.Is(err, errTestNotFound) }
+
+	testCases := []struct {
+		desc      string
+		loadErr   error
+		wantCalls int32
+	}{
+		{"NotFoundIsCached", errTestNotFound, 1},
+		{"OtherErrorsAreNot", errors.New("timeout"), 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			c := newTestLoadingCache(t, cache.WithNegativeCaching(50*time.Millisecond, isNotFound))
+			loader := &countingLoader{err: tc.loadErr}
+
+			for range 3 {
+				if _, err := c.GetOrLoad(context.Background(), 1, loader.load); !errors.Is(err, tc.loadErr) {
+					t.Fatalf("GetOrLoad() error = %v; want %v", err, tc.loadErr)
+				}
+			}
+
+			if got := loader.calls.Load(); got != tc.wantCalls {
+				t.Errorf("loader calls = %d; want %d", got, tc.wantCalls)
+			}
+			if c.Has(1) {
+				t.Errorf("Has(1) = true for a cached not-found result")
+			}
+		})
+	}
+}
+
+func TestLoadingCache_NegativeEntryExpires(t *testing.T) {
+	t.Parallel()
+
+	c := newTestLoadingCache(t, cache.WithNegativeCaching(10*time.Millisecond, func(err error) bool {
+		return errors.Is(err, errTestNotFound)
+	}))
+	loader := &countingLoader{err: errTestNotFound}
+
+	_, _ = c.GetOrLoad(context.Background(), 1, loader.load)
+	time.Sleep(20 * time.Millisecond)
+	loader.set("created", nil)
+
+	if value, err := c.GetOrLoad(context.Background(), 1, loader.load); err != nil || value != "created" {
+		t.Errorf("GetOrLoad() = %q, %v; want \"created\", nil", value, err)
+	}
+}
+
+func TestLoadingCache_StaleWhileRevalidate(t *testing.T) {
+	t.Parallel()
+
+	c := newTestLoadingCache(t,
+		cache.WithLoadTTL(10*time.Millisecond),
+		cache.WithStaleWhileRevalidate(time.Second),
+	)
+	loader := &countingLoader{value: "v1"}
+
+	_, _ = c.GetOrLoad(context.Background(), 1, loader.load)
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := c.Get(1); ok {
+		t.Errorf("Get(1) returned a stale value")
+	}
+
+	loader.set("v2", nil)
+	if value, err := c.GetOrLoad(context.Background(), 1, loader.load); err != nil || value != "v1" {
+		t.Errorf("GetOrLoad() = %q, %v; want stale \"v1\", nil", value, err)
+	}
+
+	eventually(t, "revalidation", func() bool {
+		value, ok := c.Get(1)
+		return ok && value == "v2"
+	})
+}
+
+func TestLoadingCache_RefreshAhead(t *testing.T) {
+	t.Parallel()
+
+	c := newTestLoadingCache(t,
+		cache.WithLoadTTL(time.Second),
+		cache.WithRefreshAhead(0.01),
+	)
+	loader := &countingLoader{value: "v1"}
+
+	_, _ = c.GetOrLoad(context.Background(), 1, loader.load)
+	time.Sleep(20 * time.Millisecond)
+	loader.set("v2", nil)
+
+	if value, _ := c.GetOrLoad(context.Background(), 1, loader.load); value != "v1" {
+		t.Errorf("GetOrLoad() = %q; want cached \"v1\" while refreshing", value)
+	}
+
+	eventually(t, "refresh-ahead", func() bool {
+		value, _ := c.Peek(1)
+		return value == "v2"
+	})
+	if got := loader.calls.Load(); got != 2 {
+		t.Errorf("loader calls = %d; want 2", got)
+	}
+}
+
+func TestLoadingCache_PutDuringLoadWins(t *testing.T) {
+	t.Parallel()
+
+	c := newTestLoadingCache(t)
+	loader := &countingLoader{value: "loaded", gate: make(chan struct{}), inLoad: make(chan struct{}, 1)}
+
+	done := make(chan string)
+	go func() {
+		value, _ := c.GetOrLoad(context.Background(), 1, loader.load)
+		done <- value
+	}()
+
+	<-loader.inLoad
+	c.Put(1, "written", 0)
+	close(loader.gate)
+
+	if got := <-done; got != "loaded" {
+		t.Errorf("GetOrLoad() = %q; want the loader's result \"loaded\"", got)
+	}
+	if value, _ := c.Get(1); value != "written" {
+		t.Errorf("Get(1) = %q; want \"written\" from the later Put", value)
+	}
+}
+
+func TestLoadingCache_CancelledWaiter(t *testing.T) {
+	t.Parallel()
+
+	c := newTestLoadingCache(t)
+	loader := &countingLoader{value: "one", gate: make(chan struct{}), inLoad: make(chan struct{}, 1)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() {
+		_, err := c.GetOrLoad(ctx, 1, loader.load)
+		result <- err
+	}()
+
+	<-loader.inLoad
+	cancel()
+	if err := <-result; !errors.Is(err, context.Canceled) {
+		t.Errorf("GetOrLoad() error = %v; want context.Canceled", err)
+	}
+
+	close(loader.gate)
+	eventually(t, "detached load", func() bool { return c.Has(1) })
+}
+
+func TestLoadingCache_OnEvictedSkipsNegative(t *testing.T) {
+	t.Parallel()
+
+	c := newTestLoadingCache(t, cache.WithNegativeCaching(time.Minute, func(err error) bool {
+		return errors.Is(err, errTestNotFound)
+	}))
+
+	var evicted []int
+	c.SetOnEvicted(func(key int, _ string, _ cache.EvictionReason) {
+		evicted = append(evicted, key)
+	})
+
+	_, _ = c.GetOrLoad(context.Background(), 1, (&countingLoader{err: errTestNotFound}).load)
+	c.Put(2, "two", 0)
+	c.Purge()
+
+	if len(evicted) != 1 || evicted[0] != 2 {
+		t.Errorf("evicted = %v; want [2]", evicted)
+	}
+}
+
+func TestNewLoadingCache_Options(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc      string
+		opts      []cache.LoadingOption
+		wantError bool
+	}{
+		{"Defaults", nil, false},
+		{"RefreshAheadTooLarge", []cache.LoadingOption{cache.WithRefreshAhead(1)}, true},
+		{"NegativeStale", []cache.LoadingOption{cache.WithStaleWhileRevalidate(-time.Second)}, true},
+		{"NegativeCachingWithoutPredicate", []cache.LoadingOption{cache.WithNegativeCaching(time.Second, nil)}, true},
+		{
+			"SharedNegativeCachingWithoutError",
+			[]cache.LoadingOption{cache.WithSharedNegativeCaching(time.Second, nil)},
+			true,
+		},
+		{"ZeroLoadTimeout", []cache.LoadingOption{cache.WithLoadTimeout(0)}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			mockLogger := mock_logger.NewMockLogger(gomock.NewController(t))
+			items, _ := cache.NewLRUCache[int, *cache.Loaded[string]](1, mockLogger)
+
+			_, err := cache.NewLoadingCache[int, string](items, mockLogger, tc.opts...)
+			if (err != nil) != tc.wantError {
+				t.Errorf("NewLoadingCache() error = %v, wantError %v", err, tc.wantError)
+			}
+		})
+	}
+}
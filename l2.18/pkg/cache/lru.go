@@ -22,7 +22,8 @@ type LRUCache[K comparable, V any] struct {
 	capacity        int
 	cleanupInterval time.Duration
 	cleanupStop     chan struct{}
-	onEvicted       func(key K, value V)
+	onEvicted       func(key K, value V, reason EvictionReason)
+	stats           statsCounter
 }
 
 type entry[K comparable, V any] struct {
@@ -55,6 +56,7 @@ func (c *LRUCache[K, V]) Get(key K) (V, bool) {
 
 	elem, ok := c.cache[key]
 	if !ok {
+		c.stats.miss()
 		return zero, false
 	}
 
@@ -63,16 +65,38 @@ func (c *LRUCache[K, V]) Get(key K) (V, bool) {
 		c.log.Errorw("cache contains value of unexpected type",
 			"type", fmt.Sprintf("%T", elem.Value),
 		)
-		c.removeElement(elem)
+		c.removeElement(elem, EvictionDeleted)
+		c.stats.miss()
 		return zero, false
 	}
 
-	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
-		c.removeElement(elem)
+	if entry.expired(time.Now()) {
+		c.removeElement(elem, EvictionExpired)
+		c.stats.miss()
 		return zero, false
 	}
 
 	c.lruList.MoveToFront(elem)
+	c.stats.hit()
+
+	return entry.value, true
+}
+
+func (c *LRUCache[K, V]) Peek(key K) (V, bool) {
+	var zero V
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, ok := c.cache[key]
+	if !ok {
+		return zero, false
+	}
+
+	entry, ok := elem.Value.(*entry[K, V])
+	if !ok || entry.expired(time.Now()) {
+		return zero, false
+	}
 
 	return entry.value, true
 }
@@ -125,7 +149,35 @@ func (c *LRUCache[K, V]) Has(key K) bool {
 		return false
 	}
 
-	return entry.expires.IsZero() || time.Now().Before(entry.expires)
+	return !entry.expired(time.Now())
+}
+
+func (c *LRUCache[K, V]) Delete(key K) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, ok := c.cache[key]
+	if !ok {
+		return false
+	}
+
+	c.removeElement(elem, EvictionDeleted)
+	return true
+}
+
+// Keys returns unexpired keys from the most to the least recently used.
+func (c *LRUCache[K, V]) Keys() []K {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	keys := make([]K, 0, c.lruList.Len())
+	for elem := c.lruList.Front(); elem != nil; elem = elem.Next() {
+		if entry, ok := elem.Value.(*entry[K, V]); ok && !entry.expired(now) {
+			keys = append(keys, entry.key)
+		}
+	}
+	return keys
 }
 
 func (c *LRUCache[K, V]) Len() int {
@@ -135,9 +187,46 @@ func (c *LRUCache[K, V]) Len() int {
 }
 
 func (c *LRUCache[K, V]) Capacity() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 	return c.capacity
 }
 
+func (c *LRUCache[K, V]) Snapshot() []SnapshotEntry[K, V] {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	entries := make([]SnapshotEntry[K, V], 0, c.lruList.Len())
+	for elem := c.lruList.Back(); elem != nil; elem = elem.Prev() {
+		if entry, ok := elem.Value.(*entry[K, V]); ok && !entry.expired(now) {
+			entries = append(entries, SnapshotEntry[K, V]{entry.key, entry.value, entry.expires})
+		}
+	}
+	return entries
+}
+
+func (c *LRUCache[K, V]) Stats() Stats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.stats.snapshot(c.lruList.Len(), c.capacity)
+}
+
+func (c *LRUCache[K, V]) Resize(capacity int) error {
+	if capacity <= 0 {
+		return fmt.Errorf("cache.Resize: capacity must be positive, got %d", capacity)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.capacity = capacity
+	for c.lruList.Len() > c.capacity {
+		c.removeOldest()
+	}
+	return nil
+}
+
 func (c *LRUCache[K, V]) Purge() {
 	var evicted []struct {
 		key   K
@@ -145,21 +234,23 @@ func (c *LRUCache[K, V]) Purge() {
 	}
 
 	c.mutex.Lock()
-	for key, elem := range c.cache {
+	for elem := c.lruList.Back(); elem != nil; elem = elem.Prev() {
 		if entry, ok := elem.Value.(*entry[K, V]); ok {
 			evicted = append(evicted, struct {
 				key   K
 				value V
-			}{key, entry.value})
+			}{entry.key, entry.value})
 		}
 	}
 	c.lruList.Init()
 	clear(c.cache)
+	onEvicted := c.onEvicted
 	c.mutex.Unlock()
 
+	c.stats.removed(EvictionPurged, len(evicted))
 	for _, item := range evicted {
-		if c.onEvicted != nil {
-			c.onEvicted(item.key, item.value)
+		if onEvicted != nil {
+			onEvicted(item.key, item.value, EvictionPurged)
 		}
 	}
 }
@@ -174,7 +265,7 @@ func (c *LRUCache[K, V]) StartCleanup(interval time.Duration) {
 
 	c.cleanupInterval = interval
 	c.cleanupStop = make(chan struct{})
-	go c.runCleanup()
+	go c.runCleanup(interval, c.cleanupStop)
 }
 
 func (c *LRUCache[K, V]) StopCleanup() {
@@ -186,15 +277,15 @@ func (c *LRUCache[K, V]) StopCleanup() {
 	c.mutex.Unlock()
 }
 
-func (c *LRUCache[K, V]) runCleanup() {
-	ticker := time.NewTicker(c.cleanupInterval)
+func (c *LRUCache[K, V]) runCleanup(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
 			c.cleanupExpired()
-		case <-c.cleanupStop:
+		case <-stop:
 			return
 		}
 	}
@@ -214,17 +305,13 @@ func (c *LRUCache[K, V]) cleanupExpired() {
 			continue
 		}
 
-		if entry.expires.IsZero() {
-			continue
-		}
-
-		if now.After(entry.expires) {
+		if entry.expired(now) {
 			toRemove = append(toRemove, elem)
 		}
 	}
 
 	for _, elem := range toRemove {
-		c.removeElement(elem)
+		c.removeElement(elem, EvictionExpired)
 		removed++
 	}
 
@@ -238,11 +325,11 @@ func (c *LRUCache[K, V]) cleanupExpired() {
 
 func (c *LRUCache[K, V]) removeOldest() {
 	if elem := c.lruList.Back(); elem != nil {
-		c.removeElement(elem)
+		c.removeElement(elem, EvictionCapacity)
 	}
 }
 
-func (c *LRUCache[K, V]) removeElement(elem *list.Element) {
+func (c *LRUCache[K, V]) removeElement(elem *list.Element, reason EvictionReason) {
 	c.lruList.Remove(elem)
 	entry, ok := elem.Value.(*entry[K, V])
 	if !ok {
@@ -252,12 +339,17 @@ func (c *LRUCache[K, V]) removeElement(elem *list.Element) {
 		return
 	}
 	delete(c.cache, entry.key)
+	c.stats.removed(reason, 1)
 	if c.onEvicted != nil {
-		c.onEvicted(entry.key, entry.value)
+		c.onEvicted(entry.key, entry.value, reason)
 	}
 }
 
-func (c *LRUCache[K, V]) SetOnEvicted(onEvicted func(key K, value V)) {
+func (e *entry[K, V]) expired(now time.Time) bool {
+	return !e.expires.IsZero() && now.After(e.expires)
+}
+
+func (c *LRUCache[K, V]) SetOnEvicted(onEvicted func(key K, value V, reason EvictionReason)) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 	c.onEvicted = onEvicted
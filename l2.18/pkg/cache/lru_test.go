@@ -415,7 +415,7 @@ func TestLRUCache_OnEvicted(t *testing.T) {
 			)
 
 			c, _ := cache.NewLRUCache[int, string](tc.input.capacity, mockLogger)
-			c.SetOnEvicted(func(key int, _ string) {
+			c.SetOnEvicted(func(key int, _ string, _ cache.EvictionReason) {
 				mu.Lock()
 				defer mu.Unlock()
 				evictedKeys = append(evictedKeys, key)
@@ -507,3 +507,414 @@ func TestLRUCache_NewLRUCache(t *testing.T) {
 		})
 	}
 }
+
+func TestLRUCache_Resize(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc        string
+		capacity    int
+		keys        []int
+		newCapacity int
+		wantError   bool
+		wantLen     int
+		wantKeys    []int
+	}{
+		{"Shrink", 3, []int{1, 2, 3}, 1, false, 1, []int{3}},
+		{"Grow", 2, []int{1, 2}, 5, false, 2, []int{1, 2}},
+		{"InvalidCapacity", 2, []int{1, 2}, 0, true, 2, []int{1, 2}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+
+			mockLogger := mock_logger.NewMockLogger(ctrl)
+
+			c, _ := cache.NewLRUCache[int, string](tc.capacity, mockLogger)
+			for _, key := range tc.keys {
+				c.Put(key, "value", 0)
+			}
+
+			err := c.Resize(tc.newCapacity)
+			if (err != nil) != tc.wantError {
+				t.Fatalf("Resize(%d) error = %v, wantError %v", tc.newCapacity, err, tc.wantError)
+			}
+
+			if c.Len() != tc.wantLen {
+				t.Errorf("Len() = %d; want %d", c.Len(), tc.wantLen)
+			}
+
+			for _, key := range tc.wantKeys {
+				if !c.Has(key) {
+					t.Errorf("Has(%d) = false; want true", key)
+				}
+			}
+		})
+	}
+}
+
+func applyOps(c *cache.LRUCache[int, string], ops []cacheOperation) {
+	for _, op := range ops {
+		switch op.op {
+		case "put":
+			c.Put(op.key, op.value, op.ttl)
+		case "get":
+			c.Get(op.key)
+		case "peek":
+			c.Peek(op.key)
+		case "delete":
+			c.Delete(op.key)
+		case "purge":
+			c.Purge()
+		case "sleep":
+			time.Sleep(op.ttl)
+		}
+	}
+}
+
+type evictionRecord struct {
+	key    int
+	reason cache.EvictionReason
+}
+
+func TestLRUCache_OnEvictedReason(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc     string
+		capacity int
+		ops      []cacheOperation
+		expected []evictionRecord
+	}{
+		{
+			desc:     "Capacity",
+			capacity: 1,
+			ops: []cacheOperation{
+				{"put", 1, "one", 0},
+				{"put", 2, "two", 0},
+			},
+			expected: []evictionRecord{{1, cache.EvictionCapacity}},
+		},
+		{
+			desc:     "ExpiredOnGet",
+			capacity: 2,
+			ops: []cacheOperation{
+				{"put", 1, "one", 10 * time.Millisecond},
+				{"sleep", 0, "", 20 * time.Millisecond},
+				{"get", 1, "", 0},
+			},
+			expected: []evictionRecord{{1, cache.EvictionExpired}},
+		},
+		{
+			desc:     "Deleted",
+			capacity: 2,
+			ops: []cacheOperation{
+				{"put", 1, "one", 0},
+				{"delete", 1, "", 0},
+				{"delete", 1, "", 0},
+			},
+			expected: []evictionRecord{{1, cache.EvictionDeleted}},
+		},
+		{
+			desc:     "Purged",
+			capacity: 2,
+			ops: []cacheOperation{
+				{"put", 1, "one", 0},
+				{"put", 2, "two", 0},
+				{"purge", 0, "", 0},
+			},
+			expected: []evictionRecord{{1, cache.EvictionPurged}, {2, cache.EvictionPurged}},
+		},
+		{
+			desc:     "OverwriteIsNotEviction",
+			capacity: 2,
+			ops: []cacheOperation{
+				{"put", 1, "one", 0},
+				{"put", 1, "uno", 0},
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+
+			mockLogger := mock_logger.NewMockLogger(ctrl)
+
+			var (
+				mu      sync.Mutex
+				records []evictionRecord
+			)
+
+			c, _ := cache.NewLRUCache[int, string](tc.capacity, mockLogger)
+			c.SetOnEvicted(func(key int, _ string, reason cache.EvictionReason) {
+				mu.Lock()
+				defer mu.Unlock()
+				records = append(records, evictionRecord{key, reason})
+			})
+
+			applyOps(c, tc.ops)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if len(records) != len(tc.expected) {
+				t.Fatalf("evictions = %v; want %v", records, tc.expected)
+			}
+			for i, record := range records {
+				if record != tc.expected[i] {
+					t.Errorf("eviction[%d] = {%d %s}; want {%d %s}",
+						i, record.key, record.reason, tc.expected[i].key, tc.expected[i].reason)
+				}
+			}
+		})
+	}
+}
+
+func TestLRUCache_Stats(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc     string
+		capacity int
+		ops      []cacheOperation
+		expected cache.Stats
+	}{
+		{
+			desc:     "Empty",
+			capacity: 2,
+			expected: cache.Stats{Capacity: 2},
+		},
+		{
+			desc:     "HitsAndMisses",
+			capacity: 2,
+			ops: []cacheOperation{
+				{"put", 1, "one", 0},
+				{"get", 1, "", 0},
+				{"get", 1, "", 0},
+				{"get", 2, "", 0},
+				{"peek", 1, "", 0},
+				{"peek", 2, "", 0},
+			},
+			expected: cache.Stats{Hits: 2, Misses: 1, Len: 1, Capacity: 2},
+		},
+		{
+			desc:     "RemovalsByReason",
+			capacity: 2,
+			ops: []cacheOperation{
+				{"put", 1, "one", 0},
+				{"put", 2, "two", 10 * time.Millisecond},
+				{"put", 3, "three", 0},
+				{"sleep", 0, "", 20 * time.Millisecond},
+				{"put", 4, "four", 0},
+				{"delete", 3, "", 0},
+				{"put", 5, "five", 0},
+				{"purge", 0, "", 0},
+			},
+			expected: cache.Stats{Evictions: 2, Deletions: 3, Capacity: 2},
+		},
+		{
+			desc:     "ExpiredGetIsMiss",
+			capacity: 2,
+			ops: []cacheOperation{
+				{"put", 1, "one", 10 * time.Millisecond},
+				{"sleep", 0, "", 20 * time.Millisecond},
+				{"get", 1, "", 0},
+			},
+			expected: cache.Stats{Misses: 1, Expirations: 1, Capacity: 2},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+
+			mockLogger := mock_logger.NewMockLogger(ctrl)
+
+			c, _ := cache.NewLRUCache[int, string](tc.capacity, mockLogger)
+			applyOps(c, tc.ops)
+
+			if got := c.Stats(); got != tc.expected {
+				t.Errorf("Stats() = %+v; want %+v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestLRUCache_Delete(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc    string
+		keys    []int
+		delete  int
+		want    bool
+		wantLen int
+	}{
+		{"ExistingKey", []int{1, 2}, 1, true, 1},
+		{"MissingKey", []int{1, 2}, 3, false, 2},
+		{"EmptyCache", nil, 1, false, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+
+			mockLogger := mock_logger.NewMockLogger(ctrl)
+
+			c, _ := cache.NewLRUCache[int, string](2, mockLogger)
+			for _, key := range tc.keys {
+				c.Put(key, "value", 0)
+			}
+
+			if got := c.Delete(tc.delete); got != tc.want {
+				t.Errorf("Delete(%d) = %v; want %v", tc.delete, got, tc.want)
+			}
+			if c.Has(tc.delete) {
+				t.Errorf("Has(%d) = true after Delete", tc.delete)
+			}
+			if c.Len() != tc.wantLen {
+				t.Errorf("Len() = %d; want %d", c.Len(), tc.wantLen)
+			}
+		})
+	}
+}
+
+func TestLRUCache_Keys(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc     string
+		ops      []cacheOperation
+		expected []int
+	}{
+		{
+			desc:     "Empty",
+			expected: []int{},
+		},
+		{
+			desc: "MostRecentFirst",
+			ops: []cacheOperation{
+				{"put", 1, "one", 0},
+				{"put", 2, "two", 0},
+				{"put", 3, "three", 0},
+				{"get", 1, "", 0},
+			},
+			expected: []int{1, 3, 2},
+		},
+		{
+			desc: "SkipsExpired",
+			ops: []cacheOperation{
+				{"put", 1, "one", 10 * time.Millisecond},
+				{"put", 2, "two", 0},
+				{"sleep", 0, "", 20 * time.Millisecond},
+			},
+			expected: []int{2},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+
+			mockLogger := mock_logger.NewMockLogger(ctrl)
+
+			c, _ := cache.NewLRUCache[int, string](3, mockLogger)
+			applyOps(c, tc.ops)
+
+			got := c.Keys()
+			if len(got) != len(tc.expected) {
+				t.Fatalf("Keys() = %v; want %v", got, tc.expected)
+			}
+			for i := range got {
+				if got[i] != tc.expected[i] {
+					t.Errorf("Keys() = %v; want %v", got, tc.expected)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestLRUCache_Peek(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc        string
+		ops         []cacheOperation
+		peek        int
+		wantValue   string
+		wantOK      bool
+		wantEvicted int
+	}{
+		{
+			desc: "DoesNotBumpRecency",
+			ops: []cacheOperation{
+				{"put", 1, "one", 0},
+				{"put", 2, "two", 0},
+			},
+			peek:        1,
+			wantValue:   "one",
+			wantOK:      true,
+			wantEvicted: 1,
+		},
+		{
+			desc: "Expired",
+			ops: []cacheOperation{
+				{"put", 1, "one", 10 * time.Millisecond},
+				{"put", 2, "two", 0},
+				{"sleep", 0, "", 20 * time.Millisecond},
+			},
+			peek:        1,
+			wantValue:   "",
+			wantOK:      false,
+			wantEvicted: 1,
+		},
+		{
+			desc: "Missing",
+			ops: []cacheOperation{
+				{"put", 1, "one", 0},
+				{"put", 2, "two", 0},
+			},
+			peek:        3,
+			wantValue:   "",
+			wantOK:      false,
+			wantEvicted: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+
+			mockLogger := mock_logger.NewMockLogger(ctrl)
+
+			c, _ := cache.NewLRUCache[int, string](2, mockLogger)
+			applyOps(c, tc.ops)
+
+			got, ok := c.Peek(tc.peek)
+			if got != tc.wantValue || ok != tc.wantOK {
+				t.Errorf("Peek(%d) = %q, %v; want %q, %v", tc.peek, got, ok, tc.wantValue, tc.wantOK)
+			}
+
+			c.Put(3, "three", 0)
+			if c.Has(tc.wantEvicted) {
+				t.Errorf("Has(%d) = true; Peek must not refresh recency", tc.wantEvicted)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@
 package mock_cache
 
 import (
+	cache "calendar-wbf/pkg/cache"
 	reflect "reflect"
 	time "time"
 
@@ -54,6 +55,20 @@ func (mr *MockCacheMockRecorder[K, V]) Capacity() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capacity", reflect.TypeOf((*MockCache[K, V])(nil).Capacity))
 }
 
+// Delete mocks base method.
+func (m *MockCache[K, V]) Delete(key K) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", key)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCacheMockRecorder[K, V]) Delete(key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCache[K, V])(nil).Delete), key)
+}
+
 // Get mocks base method.
 func (m *MockCache[K, V]) Get(key K) (V, bool) {
 	m.ctrl.T.Helper()
@@ -83,6 +98,20 @@ func (mr *MockCacheMockRecorder[K, V]) Has(key any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Has", reflect.TypeOf((*MockCache[K, V])(nil).Has), key)
 }
 
+// Keys mocks base method.
+func (m *MockCache[K, V]) Keys() []K {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Keys")
+	ret0, _ := ret[0].([]K)
+	return ret0
+}
+
+// Keys indicates an expected call of Keys.
+func (mr *MockCacheMockRecorder[K, V]) Keys() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Keys", reflect.TypeOf((*MockCache[K, V])(nil).Keys))
+}
+
 // Len mocks base method.
 func (m *MockCache[K, V]) Len() int {
 	m.ctrl.T.Helper()
@@ -97,6 +126,21 @@ func (mr *MockCacheMockRecorder[K, V]) Len() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Len", reflect.TypeOf((*MockCache[K, V])(nil).Len))
 }
 
+// Peek mocks base method.
+func (m *MockCache[K, V]) Peek(key K) (V, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Peek", key)
+	ret0, _ := ret[0].(V)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Peek indicates an expected call of Peek.
+func (mr *MockCacheMockRecorder[K, V]) Peek(key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Peek", reflect.TypeOf((*MockCache[K, V])(nil).Peek), key)
+}
+
 // Purge mocks base method.
 func (m *MockCache[K, V]) Purge() {
 	m.ctrl.T.Helper()
@@ -121,8 +165,22 @@ func (mr *MockCacheMockRecorder[K, V]) Put(key, value, ttl any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockCache[K, V])(nil).Put), key, value, ttl)
 }
 
+// Resize mocks base method.
+func (m *MockCache[K, V]) Resize(capacity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resize", capacity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Resize indicates an expected call of Resize.
+func (mr *MockCacheMockRecorder[K, V]) Resize(capacity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resize", reflect.TypeOf((*MockCache[K, V])(nil).Resize), capacity)
+}
+
 // SetOnEvicted mocks base method.
-func (m *MockCache[K, V]) SetOnEvicted(onEvicted func(K, V)) {
+func (m *MockCache[K, V]) SetOnEvicted(onEvicted func(K, V, cache.EvictionReason)) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "SetOnEvicted", onEvicted)
 }
@@ -133,6 +191,20 @@ func (mr *MockCacheMockRecorder[K, V]) SetOnEvicted(onEvicted any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOnEvicted", reflect.TypeOf((*MockCache[K, V])(nil).SetOnEvicted), onEvicted)
 }
 
+// Snapshot mocks base method.
+func (m *MockCache[K, V]) Snapshot() []cache.SnapshotEntry[K, V] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshot")
+	ret0, _ := ret[0].([]cache.SnapshotEntry[K, V])
+	return ret0
+}
+
+// Snapshot indicates an expected call of Snapshot.
+func (mr *MockCacheMockRecorder[K, V]) Snapshot() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockCache[K, V])(nil).Snapshot))
+}
+
 // StartCleanup mocks base method.
 func (m *MockCache[K, V]) StartCleanup(interval time.Duration) {
 	m.ctrl.T.Helper()
@@ -145,6 +217,20 @@ func (mr *MockCacheMockRecorder[K, V]) StartCleanup(interval any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartCleanup", reflect.TypeOf((*MockCache[K, V])(nil).StartCleanup), interval)
 }
 
+// Stats mocks base method.
+func (m *MockCache[K, V]) Stats() cache.Stats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(cache.Stats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockCacheMockRecorder[K, V]) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockCache[K, V])(nil).Stats))
+}
+
 // StopCleanup mocks base method.
 func (m *MockCache[K, V]) StopCleanup() {
 	m.ctrl.T.Helper()
@@ -0,0 +1,364 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"calendar-wbf/pkg/logger"
+)
+
+type Policy string
+
+const (
+	PolicyLRU     Policy = "lru"
+	PolicyLFU     Policy = "lfu"
+	PolicyARC     Policy = "arc"
+	PolicyTinyLFU Policy = "tinylfu"
+)
+
+type (
+	// evictionPolicy decides which resident keys leave the cache. PolicyCache
+	// owns the values and TTLs and calls it under its lock.
+	evictionPolicy[K comparable] interface {
+		// access records a hit on a resident key.
+		access(key K)
+		// add records a newly stored key and returns the keys to evict to
+		// stay within capacity. It may return key itself if it is not admitted.
+		add(key K) []K
+		// remove forgets a resident key dropped by the cache (expiry, purge).
+		remove(key K)
+		// resize changes the capacity and returns the keys to evict.
+		resize(capacity int) []K
+		reset()
+	}
+
+	// PolicyCache implements Cache with a pluggable eviction policy while
+	// keeping TTL, cleanup and eviction callback semantics of LRUCache.
+	PolicyCache[K comparable, V any] struct {
+		mu       sync.Mutex
+		items    map[K]*expiryEntry[K, V]
+		expiry   expiryQueue[K, V]
+		policy   evictionPolicy[K]
+		name     Policy
+		capacity int
+		log      logger.Logger
+
+		onEvicted   func(key K, value V, reason EvictionReason)
+		cleanupStop chan struct{}
+		stats       statsCounter
+	}
+)
+
+// New builds a cache with the given eviction policy. LRU uses the sharded
+// implementation; the other policies keep global state behind one lock.
+func New[K comparable, V any](
+	policy Policy,
+	capacity int,
+	log logger.Logger,
+	opts ...ShardedOption,
+) (Cache[K, V], error) {
+	var (
+		c   Cache[K, V]
+		err error
+	)
+
+	switch policy {
+	case PolicyLRU, "":
+		c, err = NewShardedCache[K, V](capacity, log, opts...)
+	case PolicyLFU:
+		c, err = NewLFUCache[K, V](capacity, log)
+	case PolicyARC:
+		c, err = NewARCCache[K, V](capacity, log)
+	case PolicyTinyLFU:
+		c, err = NewTinyLFUCache[K, V](capacity, log)
+	default:
+		return nil, fmt.Errorf("cache.New: unknown policy %q", policy)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func NewLFUCache[K comparable, V any](capacity int, log logger.Logger) (*PolicyCache[K, V], error) {
+	return newPolicyCache[K, V](PolicyLFU, capacity, log, func() evictionPolicy[K] {
+		return newLFUPolicy[K](capacity)
+	})
+}
+
+func NewARCCache[K comparable, V any](capacity int, log logger.Logger) (*PolicyCache[K, V], error) {
+	return newPolicyCache[K, V](PolicyARC, capacity, log, func() evictionPolicy[K] {
+		return newARCPolicy[K](capacity)
+	})
+}
+
+func NewTinyLFUCache[K comparable, V any](capacity int, log logger.Logger) (*PolicyCache[K, V], error) {
+	return newPolicyCache[K, V](PolicyTinyLFU, capacity, log, func() evictionPolicy[K] {
+		return newTinyLFUPolicy[K](capacity)
+	})
+}
+
+func newPolicyCache[K comparable, V any](
+	name Policy,
+	capacity int,
+	log logger.Logger,
+	newPolicy func() evictionPolicy[K],
+) (*PolicyCache[K, V], error) {
+	if capacity <= 0 {
+		return nil, fmt.Errorf("cache.New%sCache: capacity must be positive, got %d", name, capacity)
+	}
+
+	return &PolicyCache[K, V]{
+		items:    make(map[K]*expiryEntry[K, V]),
+		policy:   newPolicy(),
+		name:     name,
+		capacity: capacity,
+		log:      log,
+	}, nil
+}
+
+// Policy returns the eviction policy the cache was built with.
+func (c *PolicyCache[K, V]) Policy() Policy {
+	return c.name
+}
+
+func (c *PolicyCache[K, V]) Get(key K) (V, bool) {
+	var zero V
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		c.stats.miss()
+		return zero, false
+	}
+
+	if e.expired(time.Now().UnixNano()) {
+		c.policy.remove(key)
+		c.removeEntry(e, EvictionExpired)
+		c.stats.miss()
+		return zero, false
+	}
+
+	c.policy.access(key)
+	c.stats.hit()
+	return e.value, true
+}
+
+func (c *PolicyCache[K, V]) Peek(key K) (V, bool) {
+	var zero V
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok || e.expired(time.Now().UnixNano()) {
+		return zero, false
+	}
+	return e.value, true
+}
+
+func (c *PolicyCache[K, V]) Put(key K, value V, ttl time.Duration) {
+	var expires int64
+	if ttl > 0 {
+		expires = time.Now().Add(ttl).UnixNano()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		e.value = value
+		c.expiry.schedule(e, expires)
+		c.policy.access(key)
+		return
+	}
+
+	e := newExpiryEntry(key, value)
+	c.items[key] = e
+	c.expiry.schedule(e, expires)
+
+	for _, victim := range c.policy.add(key) {
+		if evicted, exists := c.items[victim]; exists {
+			c.removeEntry(evicted, EvictionCapacity)
+		}
+	}
+}
+
+func (c *PolicyCache[K, V]) Has(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	return ok && !e.expired(time.Now().UnixNano())
+}
+
+func (c *PolicyCache[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return false
+	}
+
+	c.policy.remove(key)
+	c.removeEntry(e, EvictionDeleted)
+	return true
+}
+
+// Keys returns unexpired keys in no particular order.
+func (c *PolicyCache[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now().UnixNano()
+	keys := make([]K, 0, len(c.items))
+	for key, e := range c.items {
+		if !e.expired(now) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (c *PolicyCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+func (c *PolicyCache[K, V]) Capacity() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.capacity
+}
+
+// Snapshot returns entries in no particular order; the policy's frequency
+// history is not saved.
+func (c *PolicyCache[K, V]) Snapshot() []SnapshotEntry[K, V] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now().UnixNano()
+	entries := make([]SnapshotEntry[K, V], 0, len(c.items))
+	for _, e := range c.items {
+		if !e.expired(now) {
+			entries = append(entries, SnapshotEntry[K, V]{e.key, e.value, expiryTime(e.expires)})
+		}
+	}
+	return entries
+}
+
+func (c *PolicyCache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats.snapshot(len(c.items), c.capacity)
+}
+
+func (c *PolicyCache[K, V]) Resize(capacity int) error {
+	if capacity <= 0 {
+		return fmt.Errorf("cache.Resize: capacity must be positive, got %d", capacity)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.capacity = capacity
+	for _, victim := range c.policy.resize(capacity) {
+		if e, exists := c.items[victim]; exists {
+			c.removeEntry(e, EvictionCapacity)
+		}
+	}
+	return nil
+}
+
+func (c *PolicyCache[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.policy.reset()
+	c.expiry.reset()
+	c.stats.removed(EvictionPurged, len(c.items))
+	for _, e := range c.items {
+		delete(c.items, e.key)
+		if c.onEvicted != nil {
+			c.onEvicted(e.key, e.value, EvictionPurged)
+		}
+	}
+}
+
+func (c *PolicyCache[K, V]) StartCleanup(interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cleanupStop != nil {
+		close(c.cleanupStop)
+	}
+
+	c.cleanupStop = make(chan struct{})
+	go c.runCleanup(interval, c.cleanupStop)
+}
+
+func (c *PolicyCache[K, V]) StopCleanup() {
+	c.mu.Lock()
+	if c.cleanupStop != nil {
+		close(c.cleanupStop)
+		c.cleanupStop = nil
+	}
+	c.mu.Unlock()
+}
+
+func (c *PolicyCache[K, V]) SetOnEvicted(onEvicted func(key K, value V, reason EvictionReason)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onEvicted = onEvicted
+}
+
+func (c *PolicyCache[K, V]) runCleanup(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.cleanupExpired()
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (c *PolicyCache[K, V]) cleanupExpired() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now().UnixNano()
+	removed := 0
+
+	for e, ok := c.expiry.nextExpired(now); ok; e, ok = c.expiry.nextExpired(now) {
+		c.policy.remove(e.key)
+		c.removeEntry(e, EvictionExpired)
+		removed++
+	}
+
+	if removed > 0 {
+		c.log.Infow("cache cleanup completed",
+			"removed", removed,
+			"remaining", len(c.items),
+		)
+	}
+}
+
+// removeEntry drops an entry the policy has already forgotten. A key the
+// policy refused to admit is reported as a capacity eviction.
+func (c *PolicyCache[K, V]) removeEntry(e *expiryEntry[K, V], reason EvictionReason) {
+	c.expiry.unschedule(e)
+	delete(c.items, e.key)
+	c.stats.removed(reason, 1)
+	if c.onEvicted != nil {
+		c.onEvicted(e.key, e.value, reason)
+	}
+}
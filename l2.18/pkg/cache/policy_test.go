@@ -0,0 +1,185 @@
+package cache_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"calendar-wbf/pkg/cache"
+	mock_logger "calendar-wbf/pkg/logger/mock"
+
+	"go.uber.org/mock/gomock"
+)
+
+var _allPolicies = []cache.Policy{cache.PolicyLRU, cache.PolicyLFU, cache.PolicyARC, cache.PolicyTinyLFU}
+
+func TestPolicyCache_LFUEvictsLeastFrequent(t *testing.T) {
+	t.Parallel()
+
+	c, _ := cache.NewLFUCache[int, string](2, mock_logger.NewMockLogger(gomock.NewController(t)))
+
+	c.Put(1, "one", 0)
+	c.Put(2, "two", 0)
+	c.Get(1)
+	c.Get(1)
+	c.Get(2)
+	c.Put(3, "three", 0)
+
+	if c.Has(2) {
+		t.Errorf("Has(2) = true; least frequently used key should be evicted")
+	}
+	if !c.Has(1) || !c.Has(3) {
+		t.Errorf("Has(1) = %v, Has(3) = %v; want both true", c.Has(1), c.Has(3))
+	}
+}
+
+func TestPolicyCache_ScanResistance(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc     string
+		newCache func(capacity int) cache.Cache[int, int]
+	}{
+		{"ARC", func(capacity int) cache.Cache[int, int] {
+			c, _ := cache.NewARCCache[int, int](capacity, mock_logger.NewMockLogger(gomock.NewController(t)))
+			return c
+		}},
+		{"TinyLFU", func(capacity int) cache.Cache[int, int] {
+			c, _ := cache.NewTinyLFUCache[int, int](capacity, mock_logger.NewMockLogger(gomock.NewController(t)))
+			return c
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			const hot = 10
+			c := tc.newCache(100)
+
+			for range 5 {
+				for key := range hot {
+					if _, ok := c.Get(key); !ok {
+						c.Put(key, key, 0)
+					}
+				}
+			}
+
+			for key := 1000; key < 2000; key++ {
+				c.Put(key, key, 0)
+			}
+
+			for key := range hot {
+				if !c.Has(key) {
+					t.Errorf("hot key %d was flushed by a one-off scan", key)
+				}
+			}
+			if c.Len() > 100 {
+				t.Errorf("Len() = %d; want at most 100", c.Len())
+			}
+		})
+	}
+}
+
+func TestPolicyCache_SharedSemantics(t *testing.T) {
+	t.Parallel()
+
+	for _, policy := range _allPolicies {
+		t.Run(string(policy), func(t *testing.T) {
+			t.Parallel()
+
+			mockLogger := mock_logger.NewMockLogger(gomock.NewController(t))
+			mockLogger.EXPECT().
+				Infow("cache cleanup completed", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				AnyTimes()
+
+			c, err := cache.New[int, string](policy, 4, mockLogger, cache.WithShards(1))
+			if err != nil {
+				t.Fatalf("New(%s) error = %v", policy, err)
+			}
+
+			var (
+				mu      sync.Mutex
+				evicted []int
+			)
+			c.SetOnEvicted(func(key int, _ string, _ cache.EvictionReason) {
+				mu.Lock()
+				defer mu.Unlock()
+				evicted = append(evicted, key)
+			})
+
+			c.Put(1, "expiring", time.Millisecond)
+			c.Put(2, "kept", 0)
+			time.Sleep(5 * time.Millisecond)
+
+			if _, ok := c.Get(1); ok {
+				t.Errorf("Get(1) returned an expired entry")
+			}
+			if value, ok := c.Get(2); !ok || value != "kept" {
+				t.Errorf("Get(2) = %q, %v; want \"kept\", true", value, ok)
+			}
+			if keys := c.Keys(); len(keys) != 1 || keys[0] != 2 {
+				t.Errorf("Keys() = %v; want [2]", keys)
+			}
+			if value, ok := c.Peek(2); !ok || value != "kept" {
+				t.Errorf("Peek(2) = %q, %v; want \"kept\", true", value, ok)
+			}
+			if stats := c.Stats(); stats.Hits != 1 || stats.Misses != 1 || stats.Expirations != 1 {
+				t.Errorf("Stats() = %+v; want 1 hit, 1 miss, 1 expiration", stats)
+			}
+			if !c.Delete(2) || c.Delete(2) {
+				t.Errorf("Delete(2) should succeed once")
+			}
+
+			for key := 10; key < 20; key++ {
+				c.Put(key, "filler", 0)
+			}
+			if c.Len() > 4 {
+				t.Errorf("Len() = %d; want at most 4", c.Len())
+			}
+
+			c.Put(30, "short", time.Millisecond)
+			c.StartCleanup(2 * time.Millisecond)
+			time.Sleep(20 * time.Millisecond)
+			c.StopCleanup()
+			if c.Has(30) {
+				t.Errorf("Has(30) = true after cleanup; want false")
+			}
+
+			if err = c.Resize(2); err != nil {
+				t.Fatalf("Resize(2) error = %v", err)
+			}
+			if c.Len() > 2 || c.Capacity() != 2 {
+				t.Errorf("after Resize(2): Len() = %d, Capacity() = %d", c.Len(), c.Capacity())
+			}
+
+			remaining := c.Len()
+			mu.Lock()
+			before := len(evicted)
+			mu.Unlock()
+
+			c.Purge()
+			if c.Len() != 0 {
+				t.Errorf("Len() after Purge = %d; want 0", c.Len())
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(evicted)-before != remaining {
+				t.Errorf("Purge notified %d evictions; want %d", len(evicted)-before, remaining)
+			}
+			if evicted[0] != 1 {
+				t.Errorf("first eviction = %d; want expired key 1", evicted[0])
+			}
+		})
+	}
+}
+
+func TestNew_UnknownPolicy(t *testing.T) {
+	t.Parallel()
+
+	c, err := cache.New[int, int]("fifo", 10, mock_logger.NewMockLogger(gomock.NewController(t)))
+	if err == nil || c != nil {
+		t.Errorf("New(fifo) = %v, %v; want nil, error", c, err)
+	}
+}
@@ -0,0 +1,315 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"calendar-wbf/pkg/logger"
+	"calendar-wbf/pkg/resp"
+)
+
+const (
+	_defaultRemotePrefix  = "cache:"
+	_defaultRemoteTimeout = 200 * time.Millisecond
+)
+
+type (
+	// RemoteStore is the key/value subset of a Redis-compatible server.
+	// Get reports a missing key with resp.ErrNil.
+	RemoteStore interface {
+		Get(ctx context.Context, key string) ([]byte, error)
+		Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+		Del(ctx context.Context, keys ...string) (int64, error)
+		Exists(ctx context.Context, key string) (bool, error)
+		Keys(ctx context.Context, pattern string) ([]string, error)
+		PTTL(ctx context.Context, key string) (time.Duration, error)
+	}
+
+	// PubSub broadcasts invalidation messages between replicas.
+	PubSub interface {
+		Publish(ctx context.Context, channel string, payload []byte) error
+		Subscribe(ctx context.Context, channel string) (<-chan []byte, error)
+	}
+
+	// RemoteCache keeps JSON-encoded values in a RemoteStore under a key
+	// prefix, so every replica sees the same entries. The server owns
+	// memory and expiry: Capacity is unbounded, Resize and cleanup are
+	// no-ops and eviction callbacks are never fired. Remote errors are
+	// logged and treated as misses so the caller falls back to the source.
+	RemoteCache[K comparable, V any] struct {
+		store   RemoteStore
+		log     logger.Logger
+		prefix  string
+		timeout time.Duration
+		stats   statsCounter
+	}
+
+	RemoteOption func(*remoteConfig)
+
+	remoteConfig struct {
+		prefix  string
+		timeout time.Duration
+	}
+)
+
+// WithKeyPrefix namespaces keys so several caches can share one server.
+func WithKeyPrefix(prefix string) RemoteOption {
+	return func(cfg *remoteConfig) {
+		cfg.prefix = prefix
+	}
+}
+
+// WithRemoteTimeout bounds every round trip to the server.
+func WithRemoteTimeout(d time.Duration) RemoteOption {
+	return func(cfg *remoteConfig) {
+		cfg.timeout = d
+	}
+}
+
+func NewRemoteCache[K comparable, V any](
+	store RemoteStore,
+	log logger.Logger,
+	opts ...RemoteOption,
+) (*RemoteCache[K, V], error) {
+	cfg := remoteConfig{
+		prefix:  _defaultRemotePrefix,
+		timeout: _defaultRemoteTimeout,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	switch {
+	case store == nil:
+		return nil, errors.New("cache.NewRemoteCache: store is required")
+	case cfg.prefix == "":
+		return nil, errors.New("cache.NewRemoteCache: key prefix must not be empty")
+	case cfg.timeout <= 0:
+		return nil, fmt.Errorf("cache.NewRemoteCache: timeout must be positive, got %v", cfg.timeout)
+	}
+
+	return &RemoteCache[K, V]{
+		store:   store,
+		log:     log,
+		prefix:  cfg.prefix,
+		timeout: cfg.timeout,
+	}, nil
+}
+
+func (c *RemoteCache[K, V]) Get(key K) (V, bool) {
+	value, ok := c.Peek(key)
+	if ok {
+		c.stats.hit()
+	} else {
+		c.stats.miss()
+	}
+	return value, ok
+}
+
+func (c *RemoteCache[K, V]) Peek(key K) (V, bool) {
+	var zero V
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	data, err := c.store.Get(ctx, c.remoteKey(key))
+	if errors.Is(err, resp.ErrNil) {
+		return zero, false
+	}
+	if err != nil {
+		c.warn("get", key, err)
+		return zero, false
+	}
+
+	var value V
+	if err = json.Unmarshal(data, &value); err != nil {
+		c.warn("decode", key, err)
+		return zero, false
+	}
+	return value, true
+}
+
+func (c *RemoteCache[K, V]) Put(key K, value V, ttl time.Duration) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.warn("encode", key, err)
+		return
+	}
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	if err = c.store.Set(ctx, c.remoteKey(key), data, ttl); err != nil {
+		c.warn("set", key, err)
+	}
+}
+
+func (c *RemoteCache[K, V]) Has(key K) bool {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	ok, err := c.store.Exists(ctx, c.remoteKey(key))
+	if err != nil {
+		c.warn("exists", key, err)
+	}
+	return ok
+}
+
+func (c *RemoteCache[K, V]) Delete(key K) bool {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	n, err := c.store.Del(ctx, c.remoteKey(key))
+	if err != nil {
+		c.warn("del", key, err)
+	}
+	c.stats.removed(EvictionDeleted, int(n))
+	return n > 0
+}
+
+// Keys lists the keys under the prefix. It scans the whole keyspace on the
+// server and is meant for admin endpoints, not request paths.
+func (c *RemoteCache[K, V]) Keys() []K {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	keys, _ := c.keys(ctx)
+	return keys
+}
+
+func (c *RemoteCache[K, V]) Len() int {
+	return len(c.Keys())
+}
+
+func (c *RemoteCache[K, V]) Capacity() int {
+	return math.MaxInt
+}
+
+func (c *RemoteCache[K, V]) Resize(int) error {
+	return nil
+}
+
+func (c *RemoteCache[K, V]) Purge() {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	keys, err := c.keys(ctx)
+	if err != nil {
+		return
+	}
+
+	remoteKeys := make([]string, len(keys))
+	for i, key := range keys {
+		remoteKeys[i] = c.remoteKey(key)
+	}
+
+	n, err := c.store.Del(ctx, remoteKeys...)
+	if err != nil {
+		c.log.Warnw("remote cache purge failed", "error", err)
+	}
+	c.stats.removed(EvictionPurged, int(n))
+}
+
+func (c *RemoteCache[K, V]) Stats() Stats {
+	return c.stats.snapshot(c.Len(), c.Capacity())
+}
+
+// Snapshot reads every entry with its remaining TTL. Entries come in key
+// order since the server keeps no recency information.
+func (c *RemoteCache[K, V]) Snapshot() []SnapshotEntry[K, V] {
+	ctx, cancel := c.context()
+	defer cancel()
+
+	keys, err := c.keys(ctx)
+	if err != nil {
+		return nil
+	}
+
+	entries := make([]SnapshotEntry[K, V], 0, len(keys))
+	for _, key := range keys {
+		value, ok := c.Peek(key)
+		if !ok {
+			continue
+		}
+
+		entry := SnapshotEntry[K, V]{Key: key, Value: value}
+		ttl, ttlErr := c.store.PTTL(ctx, c.remoteKey(key))
+		switch {
+		case errors.Is(ttlErr, resp.ErrNil):
+			continue
+		case ttlErr != nil:
+			c.warn("pttl", key, ttlErr)
+			continue
+		case ttl > 0:
+			entry.ExpiresAt = time.Now().Add(ttl)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func (c *RemoteCache[K, V]) StartCleanup(time.Duration) {}
+
+func (c *RemoteCache[K, V]) StopCleanup() {}
+
+func (c *RemoteCache[K, V]) SetOnEvicted(func(key K, value V, reason EvictionReason)) {}
+
+func (c *RemoteCache[K, V]) keys(ctx context.Context) ([]K, error) {
+	remoteKeys, err := c.store.Keys(ctx, c.prefix+"*")
+	if err != nil {
+		c.log.Warnw("remote cache keys failed", "error", err)
+		return nil, err
+	}
+
+	keys := make([]K, 0, len(remoteKeys))
+	for _, remoteKey := range remoteKeys {
+		key, parseErr := parseKey[K](remoteKey[len(c.prefix):])
+		if parseErr != nil {
+			c.log.Warnw("remote cache key skipped", "key", remoteKey, "error", parseErr)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (c *RemoteCache[K, V]) remoteKey(key K) string {
+	return c.prefix + formatKey(key)
+}
+
+func (c *RemoteCache[K, V]) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.timeout)
+}
+
+func (c *RemoteCache[K, V]) warn(op string, key K, err error) {
+	c.log.Warnw("remote cache error",
+		"op", op,
+		"key", formatKey(key),
+		"error", err,
+	)
+}
+
+// formatKey and parseKey turn keys into the strings used on the wire;
+// strings are kept as is, anything else goes through fmt.
+func formatKey[K comparable](key K) string {
+	if s, ok := any(key).(string); ok {
+		return s
+	}
+	return fmt.Sprint(key)
+}
+
+func parseKey[K comparable](s string) (K, error) {
+	var key K
+	if p, ok := any(&key).(*string); ok {
+		*p = s
+		return key, nil
+	}
+	if _, err := fmt.Sscan(s, &key); err != nil {
+		return key, fmt.Errorf("parse key %q: %w", s, err)
+	}
+	return key, nil
+}
@@ -0,0 +1,459 @@
+package cache
+
+import (
+	"fmt"
+	"hash/maphash"
+	"math/bits"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"calendar-wbf/pkg/logger"
+)
+
+const (
+	_shardsPerProc = 4
+	_maxShards     = 256
+)
+
+type (
+	// ShardedCache is an LRU cache split into independently locked shards.
+	// Recency and capacity are tracked per shard, so eviction approximates
+	// global LRU order while operations on different shards never contend.
+	ShardedCache[K comparable, V any] struct {
+		shards []*shard[K, V]
+		mask   uint64
+		seed   maphash.Seed
+		log    logger.Logger
+
+		capacity  atomic.Int64
+		onEvicted atomic.Pointer[func(key K, value V, reason EvictionReason)]
+		stats     statsCounter
+
+		cleanupMu   sync.Mutex
+		cleanupStop chan struct{}
+	}
+
+	ShardedOption func(*shardedConfig)
+
+	shardedConfig struct {
+		shards int
+	}
+
+	shard[K comparable, V any] struct {
+		mu       sync.Mutex
+		items    map[K]*shardEntry[K, V]
+		head     *shardEntry[K, V]
+		tail     *shardEntry[K, V]
+		expiry   expiryQueue[K, V]
+		capacity int
+	}
+
+	shardEntry[K comparable, V any] struct {
+		expiryEntry[K, V]
+
+		prev, next *shardEntry[K, V]
+	}
+
+	evictedEntry[K comparable, V any] struct {
+		key   K
+		value V
+	}
+)
+
+// WithShards sets the number of shards. It is rounded down to a power of two
+// and never exceeds the capacity; zero picks a value from GOMAXPROCS.
+func WithShards(n int) ShardedOption {
+	return func(cfg *shardedConfig) {
+		cfg.shards = n
+	}
+}
+
+func NewShardedCache[K comparable, V any](
+	capacity int,
+	log logger.Logger,
+	opts ...ShardedOption,
+) (*ShardedCache[K, V], error) {
+	if capacity <= 0 {
+		return nil, fmt.Errorf("cache.NewShardedCache: capacity must be positive, got %d", capacity)
+	}
+
+	cfg := shardedConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.shards < 0 {
+		return nil, fmt.Errorf("cache.NewShardedCache: shard count must not be negative, got %d", cfg.shards)
+	}
+
+	n := shardCount(cfg.shards, capacity)
+	c := &ShardedCache[K, V]{
+		shards: make([]*shard[K, V], n),
+		mask:   uint64(n - 1),
+		seed:   maphash.MakeSeed(),
+		log:    log,
+	}
+
+	for i := range c.shards {
+		c.shards[i] = &shard[K, V]{items: make(map[K]*shardEntry[K, V])}
+	}
+	c.distribute(capacity)
+
+	return c, nil
+}
+
+func shardCount(requested, capacity int) int {
+	n := requested
+	if n == 0 {
+		n = runtime.GOMAXPROCS(0) * _shardsPerProc
+	}
+	n = min(n, capacity, _maxShards)
+	return 1 << (bits.Len(uint(n)) - 1)
+}
+
+// distribute splits capacity across shards; the first capacity%len(shards)
+// shards get one extra slot so the total matches exactly.
+func (c *ShardedCache[K, V]) distribute(capacity int) {
+	base, extra := capacity/len(c.shards), capacity%len(c.shards)
+	for i, s := range c.shards {
+		s.capacity = base
+		if i < extra {
+			s.capacity++
+		}
+	}
+	c.capacity.Store(int64(capacity))
+}
+
+func (c *ShardedCache[K, V]) shardFor(key K) *shard[K, V] {
+	return c.shards[maphash.Comparable(c.seed, key)&c.mask]
+}
+
+// Shards returns the number of shards the cache was built with.
+func (c *ShardedCache[K, V]) Shards() int {
+	return len(c.shards)
+}
+
+func (c *ShardedCache[K, V]) Get(key K) (V, bool) {
+	var zero V
+	s := c.shardFor(key)
+
+	s.mu.Lock()
+	e, ok := s.items[key]
+	if !ok {
+		s.mu.Unlock()
+		c.stats.miss()
+		return zero, false
+	}
+
+	if e.expired(time.Now().UnixNano()) {
+		s.remove(e)
+		s.mu.Unlock()
+		c.stats.miss()
+		c.notify([]evictedEntry[K, V]{{e.key, e.value}}, EvictionExpired)
+		return zero, false
+	}
+
+	s.moveToFront(e)
+	value := e.value
+	s.mu.Unlock()
+
+	c.stats.hit()
+	return value, true
+}
+
+func (c *ShardedCache[K, V]) Peek(key K) (V, bool) {
+	var zero V
+	s := c.shardFor(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.items[key]
+	if !ok || e.expired(time.Now().UnixNano()) {
+		return zero, false
+	}
+	return e.value, true
+}
+
+func (c *ShardedCache[K, V]) Put(key K, value V, ttl time.Duration) {
+	var expires int64
+	if ttl > 0 {
+		expires = time.Now().Add(ttl).UnixNano()
+	}
+
+	s := c.shardFor(key)
+	var evicted []evictedEntry[K, V]
+
+	s.mu.Lock()
+	if e, ok := s.items[key]; ok {
+		e.value = value
+		s.expiry.schedule(&e.expiryEntry, expires)
+		s.moveToFront(e)
+		s.mu.Unlock()
+		return
+	}
+
+	for len(s.items) >= s.capacity && s.tail != nil {
+		oldest := s.tail
+		s.remove(oldest)
+		evicted = append(evicted, evictedEntry[K, V]{oldest.key, oldest.value})
+	}
+
+	e := &shardEntry[K, V]{expiryEntry: *newExpiryEntry(key, value)}
+	s.items[key] = e
+	s.pushFront(e)
+	s.expiry.schedule(&e.expiryEntry, expires)
+	s.mu.Unlock()
+
+	c.notify(evicted, EvictionCapacity)
+}
+
+func (c *ShardedCache[K, V]) Has(key K) bool {
+	s := c.shardFor(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.items[key]
+	return ok && !e.expired(time.Now().UnixNano())
+}
+
+func (c *ShardedCache[K, V]) Delete(key K) bool {
+	s := c.shardFor(key)
+
+	s.mu.Lock()
+	e, ok := s.items[key]
+	if !ok {
+		s.mu.Unlock()
+		return false
+	}
+	s.remove(e)
+	s.mu.Unlock()
+
+	c.notify([]evictedEntry[K, V]{{e.key, e.value}}, EvictionDeleted)
+	return true
+}
+
+// Keys returns unexpired keys shard by shard, each shard from the most to
+// the least recently used.
+func (c *ShardedCache[K, V]) Keys() []K {
+	var keys []K
+	for _, s := range c.shards {
+		s.mu.Lock()
+		now := time.Now().UnixNano()
+		for e := s.head; e != nil; e = e.next {
+			if !e.expired(now) {
+				keys = append(keys, e.key)
+			}
+		}
+		s.mu.Unlock()
+	}
+	return keys
+}
+
+func (c *ShardedCache[K, V]) Len() int {
+	total := 0
+	for _, s := range c.shards {
+		s.mu.Lock()
+		total += len(s.items)
+		s.mu.Unlock()
+	}
+	return total
+}
+
+func (c *ShardedCache[K, V]) Capacity() int {
+	return int(c.capacity.Load())
+}
+
+// Snapshot lists each shard from its least to its most recently used entry.
+// Recency is only ordered within a shard.
+func (c *ShardedCache[K, V]) Snapshot() []SnapshotEntry[K, V] {
+	var entries []SnapshotEntry[K, V]
+	for _, s := range c.shards {
+		s.mu.Lock()
+		now := time.Now().UnixNano()
+		for e := s.tail; e != nil; e = e.prev {
+			if !e.expired(now) {
+				entries = append(entries, SnapshotEntry[K, V]{e.key, e.value, expiryTime(e.expires)})
+			}
+		}
+		s.mu.Unlock()
+	}
+	return entries
+}
+
+func (c *ShardedCache[K, V]) Stats() Stats {
+	return c.stats.snapshot(c.Len(), c.Capacity())
+}
+
+// Resize changes the total capacity. The shard count is fixed at construction,
+// so the capacity can't drop below it.
+func (c *ShardedCache[K, V]) Resize(capacity int) error {
+	if capacity < len(c.shards) {
+		return fmt.Errorf("cache.Resize: capacity %d is below shard count %d", capacity, len(c.shards))
+	}
+
+	for _, s := range c.shards {
+		s.mu.Lock()
+	}
+	c.distribute(capacity)
+
+	var evicted []evictedEntry[K, V]
+	for _, s := range c.shards {
+		for len(s.items) > s.capacity {
+			oldest := s.tail
+			s.remove(oldest)
+			evicted = append(evicted, evictedEntry[K, V]{oldest.key, oldest.value})
+		}
+		s.mu.Unlock()
+	}
+
+	c.notify(evicted, EvictionCapacity)
+	return nil
+}
+
+func (c *ShardedCache[K, V]) Purge() {
+	for _, s := range c.shards {
+		s.mu.Lock()
+		evicted := make([]evictedEntry[K, V], 0, len(s.items))
+		for key, e := range s.items {
+			evicted = append(evicted, evictedEntry[K, V]{key, e.value})
+		}
+		clear(s.items)
+		s.head, s.tail = nil, nil
+		s.expiry.reset()
+		s.mu.Unlock()
+
+		c.notify(evicted, EvictionPurged)
+	}
+}
+
+func (c *ShardedCache[K, V]) StartCleanup(interval time.Duration) {
+	c.cleanupMu.Lock()
+	defer c.cleanupMu.Unlock()
+
+	if c.cleanupStop != nil {
+		close(c.cleanupStop)
+	}
+
+	c.cleanupStop = make(chan struct{})
+	go c.runCleanup(interval, c.cleanupStop)
+}
+
+func (c *ShardedCache[K, V]) StopCleanup() {
+	c.cleanupMu.Lock()
+	if c.cleanupStop != nil {
+		close(c.cleanupStop)
+		c.cleanupStop = nil
+	}
+	c.cleanupMu.Unlock()
+}
+
+func (c *ShardedCache[K, V]) SetOnEvicted(onEvicted func(key K, value V, reason EvictionReason)) {
+	if onEvicted == nil {
+		c.onEvicted.Store(nil)
+		return
+	}
+	c.onEvicted.Store(&onEvicted)
+}
+
+func (c *ShardedCache[K, V]) runCleanup(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.cleanupExpired()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// cleanupExpired pops expired entries off each shard's heap, holding only
+// that shard's lock, so readers of other shards are never blocked.
+func (c *ShardedCache[K, V]) cleanupExpired() {
+	removed := 0
+
+	for _, s := range c.shards {
+		now := time.Now().UnixNano()
+
+		s.mu.Lock()
+		var evicted []evictedEntry[K, V]
+		for e, ok := s.expiry.nextExpired(now); ok; e, ok = s.expiry.nextExpired(now) {
+			s.remove(s.items[e.key])
+			evicted = append(evicted, evictedEntry[K, V]{e.key, e.value})
+		}
+		s.mu.Unlock()
+
+		removed += len(evicted)
+		c.notify(evicted, EvictionExpired)
+	}
+
+	if removed > 0 {
+		c.log.Infow("cache cleanup completed",
+			"removed", removed,
+			"remaining", c.Len(),
+		)
+	}
+}
+
+// notify runs the eviction callback outside shard locks so the callback may
+// safely call back into the cache.
+func (c *ShardedCache[K, V]) notify(evicted []evictedEntry[K, V], reason EvictionReason) {
+	if len(evicted) == 0 {
+		return
+	}
+	c.stats.removed(reason, len(evicted))
+
+	onEvicted := c.onEvicted.Load()
+	if onEvicted == nil {
+		return
+	}
+
+	for _, item := range evicted {
+		(*onEvicted)(item.key, item.value, reason)
+	}
+}
+
+func (s *shard[K, V]) pushFront(e *shardEntry[K, V]) {
+	e.prev = nil
+	e.next = s.head
+	if s.head != nil {
+		s.head.prev = e
+	}
+	s.head = e
+	if s.tail == nil {
+		s.tail = e
+	}
+}
+
+func (s *shard[K, V]) unlink(e *shardEntry[K, V]) {
+	if e.prev != nil {
+		e.prev.next = e.next
+	} else {
+		s.head = e.next
+	}
+	if e.next != nil {
+		e.next.prev = e.prev
+	} else {
+		s.tail = e.prev
+	}
+	e.prev, e.next = nil, nil
+}
+
+func (s *shard[K, V]) moveToFront(e *shardEntry[K, V]) {
+	if s.head == e {
+		return
+	}
+	s.unlink(e)
+	s.pushFront(e)
+}
+
+func (s *shard[K, V]) remove(e *shardEntry[K, V]) {
+	s.unlink(e)
+	s.expiry.unschedule(&e.expiryEntry)
+	delete(s.items, e.key)
+}
@@ -0,0 +1,219 @@
+package cache_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"calendar-wbf/pkg/cache"
+	mock_logger "calendar-wbf/pkg/logger/mock"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestShardedCache_LRUWithinShard(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockLogger := mock_logger.NewMockLogger(ctrl)
+
+	c, err := cache.NewShardedCache[int, string](2, mockLogger, cache.WithShards(1))
+	if err != nil {
+		t.Fatalf("NewShardedCache() error = %v", err)
+	}
+
+	var evicted []int
+	c.SetOnEvicted(func(key int, _ string, _ cache.EvictionReason) {
+		evicted = append(evicted, key)
+	})
+
+	c.Put(1, "one", 0)
+	c.Put(2, "two", 0)
+	if _, ok := c.Get(1); !ok {
+		t.Fatalf("Get(1) = _, false; want true")
+	}
+	c.Put(3, "three", 0)
+
+	if c.Has(2) {
+		t.Errorf("Has(2) = true; least recently used key should be evicted")
+	}
+	if value, ok := c.Get(1); !ok || value != "one" {
+		t.Errorf("Get(1) = %q, %v; want \"one\", true", value, ok)
+	}
+	if len(evicted) != 1 || evicted[0] != 2 {
+		t.Errorf("evicted = %v; want [2]", evicted)
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d; want 2", c.Len())
+	}
+}
+
+func TestShardedCache_ShardCount(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc      string
+		capacity  int
+		requested int
+		want      int
+	}{
+		{"PowerOfTwo", 1000, 16, 16},
+		{"RoundedDown", 1000, 12, 8},
+		{"LimitedByCapacity", 3, 64, 2},
+		{"LimitedByMaximum", 100000, 5000, 256},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockLogger := mock_logger.NewMockLogger(ctrl)
+
+			c, err := cache.NewShardedCache[int, int](tc.capacity, mockLogger, cache.WithShards(tc.requested))
+			if err != nil {
+				t.Fatalf("NewShardedCache() error = %v", err)
+			}
+			if c.Shards() != tc.want {
+				t.Errorf("Shards() = %d; want %d", c.Shards(), tc.want)
+			}
+			if c.Capacity() != tc.capacity {
+				t.Errorf("Capacity() = %d; want %d", c.Capacity(), tc.capacity)
+			}
+		})
+	}
+}
+
+func TestShardedCache_ExpiryCleanup(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockLogger := mock_logger.NewMockLogger(ctrl)
+	mockLogger.EXPECT().
+		Infow("cache cleanup completed", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		AnyTimes()
+
+	c, _ := cache.NewShardedCache[int, string](100, mockLogger, cache.WithShards(4))
+
+	var (
+		mu      sync.Mutex
+		evicted = make(map[int]bool)
+	)
+	c.SetOnEvicted(func(key int, _ string, _ cache.EvictionReason) {
+		mu.Lock()
+		defer mu.Unlock()
+		evicted[key] = true
+	})
+
+	for key := range 10 {
+		c.Put(key, "short", 10*time.Millisecond)
+	}
+	for key := 10; key < 20; key++ {
+		c.Put(key, "forever", 0)
+	}
+	c.Put(0, "refreshed", 0)
+	c.Put(1, "extended", time.Hour)
+
+	c.StartCleanup(5 * time.Millisecond)
+	defer c.StopCleanup()
+
+	time.Sleep(50 * time.Millisecond)
+
+	if c.Len() != 12 {
+		t.Errorf("Len() = %d; want 12", c.Len())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for key := 2; key < 10; key++ {
+		if !evicted[key] {
+			t.Errorf("key %d was not evicted after expiring", key)
+		}
+	}
+	if evicted[0] || evicted[1] {
+		t.Errorf("entries with updated TTL were evicted: %v", evicted)
+	}
+}
+
+func TestShardedCache_Resize(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockLogger := mock_logger.NewMockLogger(ctrl)
+
+	c, _ := cache.NewShardedCache[int, int](64, mockLogger, cache.WithShards(4))
+	for key := range 64 {
+		c.Put(key, key, 0)
+	}
+
+	if err := c.Resize(2); err == nil {
+		t.Errorf("Resize(2) error = nil; want error for capacity below shard count")
+	}
+
+	if err := c.Resize(8); err != nil {
+		t.Fatalf("Resize(8) error = %v", err)
+	}
+	if c.Len() > 8 {
+		t.Errorf("Len() = %d; want at most 8", c.Len())
+	}
+	if c.Capacity() != 8 {
+		t.Errorf("Capacity() = %d; want 8", c.Capacity())
+	}
+}
+
+func TestShardedCache_ReentrantOnEvicted(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockLogger := mock_logger.NewMockLogger(ctrl)
+
+	c, _ := cache.NewShardedCache[int, int](1, mockLogger)
+	c.SetOnEvicted(func(key, value int, _ cache.EvictionReason) {
+		if key < 100 {
+			c.Put(key+100, value, 0)
+		}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Put(1, 1, 0)
+		c.Put(2, 2, 0)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Put deadlocked when the eviction callback wrote to the cache")
+	}
+}
+
+func TestShardedCache_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockLogger := mock_logger.NewMockLogger(ctrl)
+
+	const capacity = 128
+	c, _ := cache.NewShardedCache[int, int](capacity, mockLogger, cache.WithShards(8))
+
+	var wg sync.WaitGroup
+	for worker := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 2000 {
+				key := (worker*2000 + i) % (capacity * 4)
+				c.Put(key, i, time.Minute)
+				c.Get(key)
+				c.Has(key + 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if c.Len() > capacity {
+		t.Errorf("Len() = %d; want at most %d", c.Len(), capacity)
+	}
+}
@@ -0,0 +1,127 @@
+package sim
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"strings"
+	"text/tabwriter"
+
+	"calendar-wbf/pkg/cache"
+	"calendar-wbf/pkg/logger"
+)
+
+const (
+	_zipfS = 1.1
+	_zipfV = 1
+	// Scan keys live far above the hot set so the two never collide.
+	_scanKeyBase = 1 << 32
+)
+
+type Result struct {
+	Policy   cache.Policy
+	Requests int
+	Hits     int
+}
+
+func (r Result) HitRatio() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+	return float64(r.Hits) / float64(r.Requests)
+}
+
+// Replay feeds the trace through c as a read-through cache would: every miss
+// is followed by a Put of the key.
+func Replay[K comparable](c cache.Cache[K, struct{}], trace []K) (requests, hits int) {
+	for _, key := range trace {
+		if _, ok := c.Get(key); ok {
+			hits++
+			continue
+		}
+		c.Put(key, struct{}{}, 0)
+	}
+	return len(trace), hits
+}
+
+// Compare replays the same trace against a fresh cache per policy.
+func Compare[K comparable](policies []cache.Policy, capacity int, trace []K) ([]Result, error) {
+	results := make([]Result, 0, len(policies))
+
+	for _, policy := range policies {
+		c, err := cache.New[K, struct{}](policy, capacity, logger.NewNop())
+		if err != nil {
+			return nil, fmt.Errorf("sim.Compare: %w", err)
+		}
+
+		requests, hits := Replay(c, trace)
+		results = append(results, Result{Policy: policy, Requests: requests, Hits: hits})
+	}
+
+	return results, nil
+}
+
+func WriteTable(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "policy\trequests\thits\thit ratio\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.2f%%\t\n", r.Policy, r.Requests, r.Hits, 100*r.HitRatio())
+	}
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("sim.WriteTable: %w", err)
+	}
+	return nil
+}
+
+// Zipf returns requests drawn from keys [0, keys) with a Zipf distribution.
+func Zipf(requests, keys int, seed uint64) []uint64 {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	zipf := rand.NewZipf(rng, _zipfS, _zipfV, uint64(keys-1))
+
+	trace := make([]uint64, requests)
+	for i := range trace {
+		trace[i] = zipf.Uint64()
+	}
+	return trace
+}
+
+// MonthView models the calendar's read pattern: Zipf-distributed reads of a
+// hot set of events, interrupted every scanEvery requests by a month view
+// that touches scanLen events once each.
+func MonthView(requests, hotKeys, scanLen, scanEvery int, seed uint64) []uint64 {
+	hot := Zipf(requests, hotKeys, seed)
+	trace := make([]uint64, 0, requests+requests/scanEvery*scanLen)
+
+	next := uint64(_scanKeyBase)
+	for i, key := range hot {
+		if i > 0 && i%scanEvery == 0 {
+			for range scanLen {
+				trace = append(trace, next)
+				next++
+			}
+		}
+		trace = append(trace, key)
+	}
+	return trace
+}
+
+// ReadTrace parses one key per line, using the first field and skipping
+// blank lines and # comments.
+func ReadTrace(r io.Reader) ([]string, error) {
+	var trace []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		trace = append(trace, fields[0])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("sim.ReadTrace: %w", err)
+	}
+
+	return trace, nil
+}
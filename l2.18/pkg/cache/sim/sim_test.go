@@ -0,0 +1,52 @@
+package sim_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"calendar-wbf/pkg/cache"
+	"calendar-wbf/pkg/cache/sim"
+)
+
+func TestCompare_MonthView(t *testing.T) {
+	t.Parallel()
+
+	trace := sim.MonthView(50_000, 2_000, 500, 1_000, 1)
+	policies := []cache.Policy{cache.PolicyLRU, cache.PolicyLFU, cache.PolicyARC, cache.PolicyTinyLFU}
+
+	results, err := sim.Compare(policies, 500, trace)
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+
+	ratios := make(map[cache.Policy]float64, len(results))
+	for _, r := range results {
+		if r.Requests != len(trace) {
+			t.Errorf("%s: Requests = %d; want %d", r.Policy, r.Requests, len(trace))
+		}
+		ratios[r.Policy] = r.HitRatio()
+	}
+
+	for _, policy := range policies[1:] {
+		if ratios[policy] <= ratios[cache.PolicyLRU] {
+			t.Errorf("%s hit ratio %.3f does not beat LRU %.3f on month-view scans",
+				policy, ratios[policy], ratios[cache.PolicyLRU])
+		}
+	}
+}
+
+func TestReadTrace(t *testing.T) {
+	t.Parallel()
+
+	input := "# event ids\n42\n\n7 GET /event\n42\n"
+
+	got, err := sim.ReadTrace(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadTrace() error = %v", err)
+	}
+
+	if want := []string{"42", "7", "42"}; !slices.Equal(got, want) {
+		t.Errorf("ReadTrace() = %v; want %v", got, want)
+	}
+}
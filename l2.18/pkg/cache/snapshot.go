@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const _snapshotVersion = 1
+
+type (
+	// SnapshotEntry is one cached value as written to a snapshot. Entries are
+	// ordered from the least to the most recently used; a zero ExpiresAt
+	// means the entry had no TTL.
+	SnapshotEntry[K comparable, V any] struct {
+		Key       K         `json:"key"`
+		Value     V         `json:"value"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+
+	// Codec serializes snapshot entries.
+	Codec[K comparable, V any] interface {
+		Encode(w io.Writer, entries []SnapshotEntry[K, V]) error
+		Decode(r io.Reader) ([]SnapshotEntry[K, V], error)
+	}
+
+	GobCodec[K comparable, V any] struct{}
+
+	JSONCodec[K comparable, V any] struct{}
+
+	snapshotFile[K comparable, V any] struct {
+		Version int                   `json:"version"`
+		SavedAt time.Time             `json:"saved_at"`
+		Entries []SnapshotEntry[K, V] `json:"entries"`
+	}
+)
+
+// NewCodec returns the codec registered under name: "gob" or "json".
+func NewCodec[K comparable, V any](name string) (Codec[K, V], error) {
+	switch name {
+	case "gob":
+		return GobCodec[K, V]{}, nil
+	case "json":
+		return JSONCodec[K, V]{}, nil
+	default:
+		return nil, fmt.Errorf("cache.NewCodec: unknown codec %q", name)
+	}
+}
+
+func (GobCodec[K, V]) Encode(w io.Writer, entries []SnapshotEntry[K, V]) error {
+	return gob.NewEncoder(w).Encode(newSnapshotFile(entries))
+}
+
+func (GobCodec[K, V]) Decode(r io.Reader) ([]SnapshotEntry[K, V], error) {
+	var file snapshotFile[K, V]
+	if err := gob.NewDecoder(r).Decode(&file); err != nil {
+		return nil, err
+	}
+	return file.entries()
+}
+
+func (JSONCodec[K, V]) Encode(w io.Writer, entries []SnapshotEntry[K, V]) error {
+	return json.NewEncoder(w).Encode(newSnapshotFile(entries))
+}
+
+func (JSONCodec[K, V]) Decode(r io.Reader) ([]SnapshotEntry[K, V], error) {
+	var file snapshotFile[K, V]
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, err
+	}
+	return file.entries()
+}
+
+func newSnapshotFile[K comparable, V any](entries []SnapshotEntry[K, V]) snapshotFile[K, V] {
+	return snapshotFile[K, V]{
+		Version: _snapshotVersion,
+		SavedAt: time.Now(),
+		Entries: entries,
+	}
+}
+
+func (f snapshotFile[K, V]) entries() ([]SnapshotEntry[K, V], error) {
+	if f.Version != _snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", f.Version)
+	}
+	return f.Entries, nil
+}
+
+// SaveSnapshot writes the cache contents to path. The file is replaced
+// atomically so a crash mid-write leaves the previous snapshot intact.
+func SaveSnapshot[K comparable, V any](path string, c Cache[K, V], codec Codec[K, V]) (int, error) {
+	const op = "cache.SaveSnapshot"
+
+	entries := c.Snapshot()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err = codec.Encode(tmp, entries); err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("%s: encode: %w", op, err)
+	}
+	if err = tmp.Close(); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return len(entries), nil
+}
+
+// LoadSnapshot restores entries saved by SaveSnapshot, skipping those that
+// expired since and keeping only the most recent ones that fit. A missing
+// file is not an error.
+func LoadSnapshot[K comparable, V any](path string, c Cache[K, V], codec Codec[K, V]) (int, error) {
+	const op = "cache.LoadSnapshot"
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer f.Close()
+
+	entries, err := codec.Decode(f)
+	if err != nil {
+		return 0, fmt.Errorf("%s: decode %s: %w", op, path, err)
+	}
+
+	return Restore(c, entries), nil
+}
+
+// Restore puts unexpired entries into c with their remaining TTL, oldest
+// first so recency order is kept, and returns how many were restored.
+func Restore[K comparable, V any](c Cache[K, V], entries []SnapshotEntry[K, V]) int {
+	now := time.Now()
+
+	live := make([]SnapshotEntry[K, V], 0, len(entries))
+	for _, e := range entries {
+		if e.ExpiresAt.IsZero() || e.ExpiresAt.After(now) {
+			live = append(live, e)
+		}
+	}
+	if excess := len(live) - c.Capacity(); excess > 0 {
+		live = live[excess:]
+	}
+
+	for _, e := range live {
+		var ttl time.Duration
+		if !e.ExpiresAt.IsZero() {
+			ttl = e.ExpiresAt.Sub(now)
+		}
+		c.Put(e.Key, e.Value, ttl)
+	}
+	return len(live)
+}
+
+func expiryTime(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
@@ -0,0 +1,181 @@
+package cache_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"calendar-wbf/pkg/cache"
+	mock_logger "calendar-wbf/pkg/logger/mock"
+
+	"go.uber.org/mock/gomock"
+)
+
+type snapshotValue struct {
+	Title string
+	At    time.Time
+}
+
+func TestSnapshot_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc     string
+		codec    string
+		newCache func(t *testing.T, capacity int) cache.Cache[int, snapshotValue]
+	}{
+		{"LRU/gob", "gob", newSnapshotLRU},
+		{"LRU/json", "json", newSnapshotLRU},
+		{"Sharded/gob", "gob", func(t *testing.T, capacity int) cache.Cache[int, snapshotValue] {
+			t.Helper()
+			c, err := cache.NewShardedCache[int, snapshotValue](capacity, mock_logger.NewMockLogger(gomock.NewController(t)),
+				cache.WithShards(1))
+			if err != nil {
+				t.Fatalf("NewShardedCache() error = %v", err)
+			}
+			return c
+		}},
+		{"Loading/json", "json", func(t *testing.T, capacity int) cache.Cache[int, snapshotValue] {
+			t.Helper()
+			mockLogger := mock_logger.NewMockLogger(gomock.NewController(t))
+			items, _ := cache.NewLRUCache[int, *cache.Loaded[snapshotValue]](capacity, mockLogger)
+			c, err := cache.NewLoadingCache[int, snapshotValue](items, mockLogger,
+				cache.WithStaleWhileRevalidate(time.Minute))
+			if err != nil {
+				t.Fatalf("NewLoadingCache() error = %v", err)
+			}
+			return c
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			codec, err := cache.NewCodec[int, snapshotValue](tc.codec)
+			if err != nil {
+				t.Fatalf("NewCodec(%s) error = %v", tc.codec, err)
+			}
+			path := filepath.Join(t.TempDir(), "nested", "cache.snapshot")
+			at := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+
+			src := tc.newCache(t, 10)
+			src.Put(1, snapshotValue{"one", at}, time.Hour)
+			src.Put(2, snapshotValue{"two", at}, 0)
+			src.Put(3, snapshotValue{"short", at}, 30*time.Millisecond)
+			src.Put(4, snapshotValue{"four", at}, time.Hour)
+			src.Get(1)
+
+			saved, err := cache.SaveSnapshot(path, src, codec)
+			if err != nil || saved != 4 {
+				t.Fatalf("SaveSnapshot() = %d, %v; want 4, nil", saved, err)
+			}
+
+			time.Sleep(50 * time.Millisecond)
+
+			dst := tc.newCache(t, 10)
+			restored, err := cache.LoadSnapshot(path, dst, codec)
+			if err != nil || restored != 3 {
+				t.Fatalf("LoadSnapshot() = %d, %v; want 3 (expired entry dropped), nil", restored, err)
+			}
+
+			for key, want := range map[int]string{1: "one", 2: "two", 4: "four"} {
+				got, ok := dst.Get(key)
+				if !ok || got.Title != want || !got.At.Equal(at) {
+					t.Errorf("Get(%d) = %+v, %v; want %q", key, got, ok, want)
+				}
+			}
+			if dst.Has(3) {
+				t.Errorf("Has(3) = true; entry expired while the service was down")
+			}
+		})
+	}
+}
+
+func TestSnapshot_KeepsRecencyAndTTL(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	codec := cache.GobCodec[int, snapshotValue]{}
+
+	src := newSnapshotLRU(t, 5)
+	for key := 1; key <= 5; key++ {
+		src.Put(key, snapshotValue{Title: "v"}, 0)
+	}
+	src.Get(2)
+	src.Put(6, snapshotValue{Title: "ttl"}, 40*time.Millisecond)
+
+	if _, err := cache.SaveSnapshot(path, src, codec); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+
+	dst := newSnapshotLRU(t, 3)
+	if n, err := cache.LoadSnapshot(path, dst, codec); err != nil || n != 3 {
+		t.Fatalf("LoadSnapshot() = %d, %v; want the 3 most recent entries", n, err)
+	}
+
+	keys := dst.Keys()
+	if want := []int{6, 2, 5}; !slices.Equal(keys, want) {
+		t.Errorf("Keys() = %v; want %v", keys, want)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if dst.Has(6) {
+		t.Errorf("Has(6) = true; restored entry must keep its remaining TTL")
+	}
+}
+
+func TestLoadSnapshot_Errors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	corrupt := filepath.Join(dir, "corrupt")
+	if err := os.WriteFile(corrupt, []byte("not a snapshot"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	wrongVersion := filepath.Join(dir, "version")
+	if err := os.WriteFile(wrongVersion, []byte(`{"version":99,"entries":[]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		desc      string
+		path      string
+		wantError bool
+	}{
+		{"MissingFileIsColdStart", filepath.Join(dir, "missing"), false},
+		{"CorruptFile", corrupt, true},
+		{"UnsupportedVersion", wrongVersion, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			n, err := cache.LoadSnapshot(tc.path, newSnapshotLRU(t, 2), cache.JSONCodec[int, snapshotValue]{})
+			if (err != nil) != tc.wantError || n != 0 {
+				t.Errorf("LoadSnapshot() = %d, %v; wantError %v", n, err, tc.wantError)
+			}
+		})
+	}
+}
+
+func TestNewCodec_Unknown(t *testing.T) {
+	t.Parallel()
+
+	if _, err := cache.NewCodec[int, string]("xml"); err == nil {
+		t.Errorf("NewCodec(xml) error = nil; want error")
+	}
+}
+
+func newSnapshotLRU(t *testing.T, capacity int) cache.Cache[int, snapshotValue] {
+	t.Helper()
+
+	c, err := cache.NewLRUCache[int, snapshotValue](capacity, mock_logger.NewMockLogger(gomock.NewController(t)))
+	if err != nil {
+		t.Fatalf("NewLRUCache() error = %v", err)
+	}
+	return c
+}
@@ -0,0 +1,100 @@
+package cache
+
+import "sync/atomic"
+
+// EvictionReason tells the eviction callback why an entry left the cache.
+type EvictionReason int
+
+const (
+	// EvictionCapacity means the entry made room for another one or was
+	// dropped by Resize.
+	EvictionCapacity EvictionReason = iota
+	// EvictionExpired means the entry outlived its TTL.
+	EvictionExpired
+	// EvictionDeleted means the entry was removed by Delete.
+	EvictionDeleted
+	// EvictionPurged means the entry was removed by Purge.
+	EvictionPurged
+)
+
+func (r EvictionReason) String() string {
+	switch r {
+	case EvictionCapacity:
+		return "capacity"
+	case EvictionExpired:
+		return "expired"
+	case EvictionDeleted:
+		return "deleted"
+	case EvictionPurged:
+		return "purged"
+	default:
+		return "unknown"
+	}
+}
+
+type (
+	// Stats is a point-in-time snapshot of cache counters. Counters are
+	// cumulative since the cache was created; Peek, Has and Keys don't
+	// count as lookups.
+	Stats struct {
+		Hits        uint64 `json:"hits"`
+		Misses      uint64 `json:"misses"`
+		Evictions   uint64 `json:"evictions"`
+		Expirations uint64 `json:"expirations"`
+		Deletions   uint64 `json:"deletions"`
+		Len         int    `json:"len"`
+		Capacity    int    `json:"capacity"`
+	}
+
+	statsCounter struct {
+		hits        atomic.Uint64
+		misses      atomic.Uint64
+		evictions   atomic.Uint64
+		expirations atomic.Uint64
+		deletions   atomic.Uint64
+	}
+)
+
+// HitRatio returns hits/(hits+misses), or 0 before the first lookup.
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+func (s *statsCounter) hit() {
+	s.hits.Add(1)
+}
+
+func (s *statsCounter) miss() {
+	s.misses.Add(1)
+}
+
+func (s *statsCounter) removed(reason EvictionReason, n int) {
+	if n <= 0 {
+		return
+	}
+
+	switch reason {
+	case EvictionCapacity:
+		s.evictions.Add(uint64(n))
+	case EvictionExpired:
+		s.expirations.Add(uint64(n))
+	case EvictionDeleted, EvictionPurged:
+		s.deletions.Add(uint64(n))
+	}
+}
+
+func (s *statsCounter) snapshot(length, capacity int) Stats {
+	return Stats{
+		Hits:        s.hits.Load(),
+		Misses:      s.misses.Load(),
+		Evictions:   s.evictions.Load(),
+		Expirations: s.expirations.Load(),
+		Deletions:   s.deletions.Load(),
+		Len:         length,
+		Capacity:    capacity,
+	}
+}
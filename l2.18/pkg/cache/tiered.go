@@ -0,0 +1,301 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"calendar-wbf/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+const (
+	_defaultNearTTL      = 30 * time.Second
+	_minResubscribeDelay = 100 * time.Millisecond
+	_maxResubscribeDelay = 10 * time.Second
+	_invalidateDelete    = "del"
+	_invalidatePurge     = "purge"
+	_publishTimeout      = time.Second
+)
+
+type (
+	// TieredCache puts a small in-process near cache in front of a shared
+	// far cache. Reads fill the near tier from the far one; writes go to
+	// both and are broadcast over PubSub so other replicas drop their near
+	// copies, while Fill stores loaded values without a broadcast. Near
+	// entries live at most the near TTL, which bounds staleness if an
+	// invalidation message is lost.
+	//
+	// Len, Capacity, Keys, Snapshot, cleanup and eviction callbacks refer to
+	// the near tier; Stats counts a far hit as a hit.
+	TieredCache[K comparable, V any] struct {
+		near    Cache[K, V]
+		far     Cache[K, V]
+		log     logger.Logger
+		nearTTL time.Duration
+
+		bus     PubSub
+		channel string
+		node    string
+	}
+
+	TieredOption func(*tieredConfig)
+
+	tieredConfig struct {
+		nearTTL time.Duration
+		bus     PubSub
+		channel string
+	}
+
+	invalidation struct {
+		Node string `json:"node"`
+		Op   string `json:"op"`
+		Key  string `json:"key,omitempty"`
+	}
+)
+
+// WithNearTTL caps how long a value stays in the near tier.
+func WithNearTTL(ttl time.Duration) TieredOption {
+	return func(cfg *tieredConfig) {
+		cfg.nearTTL = ttl
+	}
+}
+
+// WithInvalidation broadcasts writes on channel; Run applies the messages
+// published by other replicas.
+func WithInvalidation(bus PubSub, channel string) TieredOption {
+	return func(cfg *tieredConfig) {
+		cfg.bus = bus
+		cfg.channel = channel
+	}
+}
+
+func NewTieredCache[K comparable, V any](
+	near, far Cache[K, V],
+	log logger.Logger,
+	opts ...TieredOption,
+) (*TieredCache[K, V], error) {
+	cfg := tieredConfig{nearTTL: _defaultNearTTL}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	switch {
+	case near == nil || far == nil:
+		return nil, errors.New("cache.NewTieredCache: near and far caches are required")
+	case cfg.nearTTL <= 0:
+		return nil, fmt.Errorf("cache.NewTieredCache: near TTL must be positive, got %v", cfg.nearTTL)
+	case cfg.bus != nil && cfg.channel == "":
+		return nil, errors.New("cache.NewTieredCache: invalidation channel must not be empty")
+	}
+
+	return &TieredCache[K, V]{
+		near:    near,
+		far:     far,
+		log:     log,
+		nearTTL: cfg.nearTTL,
+		bus:     cfg.bus,
+		channel: cfg.channel,
+		node:    uuid.NewString(),
+	}, nil
+}
+
+func (c *TieredCache[K, V]) Get(key K) (V, bool) {
+	if value, ok := c.near.Get(key); ok {
+		return value, true
+	}
+
+	value, ok := c.far.Get(key)
+	if ok {
+		c.near.Put(key, value, c.nearTTL)
+	}
+	return value, ok
+}
+
+func (c *TieredCache[K, V]) Peek(key K) (V, bool) {
+	if value, ok := c.near.Peek(key); ok {
+		return value, true
+	}
+	return c.far.Peek(key)
+}
+
+func (c *TieredCache[K, V]) Put(key K, value V, ttl time.Duration) {
+	c.far.Put(key, value, ttl)
+	c.near.Put(key, value, c.nearEntryTTL(ttl))
+	c.publish(_invalidateDelete, formatKey(key))
+}
+
+// Fill stores a value read from the source of truth in both tiers. Unlike
+// Put it is not broadcast: the value is what a write already announced, so
+// the near copies of other replicas are still valid.
+func (c *TieredCache[K, V]) Fill(key K, value V, ttl time.Duration) {
+	c.far.Put(key, value, ttl)
+	c.near.Put(key, value, c.nearEntryTTL(ttl))
+}
+
+func (c *TieredCache[K, V]) Has(key K) bool {
+	return c.near.Has(key) || c.far.Has(key)
+}
+
+func (c *TieredCache[K, V]) Delete(key K) bool {
+	farDeleted := c.far.Delete(key)
+	nearDeleted := c.near.Delete(key)
+	c.publish(_invalidateDelete, formatKey(key))
+	return farDeleted || nearDeleted
+}
+
+func (c *TieredCache[K, V]) Keys() []K {
+	return c.near.Keys()
+}
+
+func (c *TieredCache[K, V]) Len() int {
+	return c.near.Len()
+}
+
+func (c *TieredCache[K, V]) Capacity() int {
+	return c.near.Capacity()
+}
+
+func (c *TieredCache[K, V]) Resize(capacity int) error {
+	return c.near.Resize(capacity)
+}
+
+func (c *TieredCache[K, V]) Purge() {
+	c.far.Purge()
+	c.near.Purge()
+	c.publish(_invalidatePurge, "")
+}
+
+func (c *TieredCache[K, V]) Stats() Stats {
+	stats := c.near.Stats()
+	far := c.far.Stats()
+
+	stats.Hits += far.Hits
+	stats.Misses = far.Misses
+	return stats
+}
+
+func (c *TieredCache[K, V]) Snapshot() []SnapshotEntry[K, V] {
+	return c.near.Snapshot()
+}
+
+func (c *TieredCache[K, V]) StartCleanup(interval time.Duration) {
+	c.near.StartCleanup(interval)
+}
+
+func (c *TieredCache[K, V]) StopCleanup() {
+	c.near.StopCleanup()
+}
+
+func (c *TieredCache[K, V]) SetOnEvicted(onEvicted func(key K, value V, reason EvictionReason)) {
+	c.near.SetOnEvicted(onEvicted)
+}
+
+// Run applies invalidations from other replicas until ctx is done. A lost
+// subscription is retried with backoff, and the near tier is purged once it
+// is back since messages may have been missed in between.
+func (c *TieredCache[K, V]) Run(ctx context.Context) error {
+	if c.bus == nil {
+		return nil
+	}
+
+	delay := _minResubscribeDelay
+	missed := false
+
+	for {
+		messages, err := c.bus.Subscribe(ctx, c.channel)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			c.log.Warnw("cache invalidation subscribe failed",
+				"channel", c.channel,
+				"retry_in", delay,
+				"error", err,
+			)
+			missed = true
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, _maxResubscribeDelay)
+			continue
+		}
+
+		if missed {
+			c.near.Purge()
+			c.log.Infow("cache invalidation resubscribed, near cache purged", "channel", c.channel)
+		}
+		delay = _minResubscribeDelay
+
+		for payload := range messages {
+			c.apply(payload)
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+		c.log.Warnw("cache invalidation subscription lost", "channel", c.channel)
+		missed = true
+	}
+}
+
+func (c *TieredCache[K, V]) apply(payload []byte) {
+	var msg invalidation
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		c.log.Warnw("cache invalidation message dropped", "error", err)
+		return
+	}
+	if msg.Node == c.node {
+		return
+	}
+
+	switch msg.Op {
+	case _invalidateDelete:
+		key, err := parseKey[K](msg.Key)
+		if err != nil {
+			c.log.Warnw("cache invalidation message dropped", "error", err)
+			return
+		}
+		c.near.Delete(key)
+	case _invalidatePurge:
+		c.near.Purge()
+	default:
+		c.log.Warnw("cache invalidation message dropped", "op", msg.Op)
+	}
+}
+
+func (c *TieredCache[K, V]) publish(op, key string) {
+	if c.bus == nil {
+		return
+	}
+
+	payload, err := json.Marshal(invalidation{Node: c.node, Op: op, Key: key})
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), _publishTimeout)
+	defer cancel()
+
+	if err = c.bus.Publish(ctx, c.channel, payload); err != nil {
+		c.log.Warnw("cache invalidation publish failed",
+			"op", op,
+			"key", key,
+			"error", err,
+		)
+	}
+}
+
+func (c *TieredCache[K, V]) nearEntryTTL(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return c.nearTTL
+	}
+	return min(ttl, c.nearTTL)
+}
@@ -0,0 +1,320 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"calendar-wbf/pkg/cache"
+	mock_logger "calendar-wbf/pkg/logger/mock"
+	"calendar-wbf/pkg/resp"
+
+	"go.uber.org/mock/gomock"
+)
+
+type (
+	remoteValue struct {
+		Title string `json:"title"`
+	}
+
+	countingBus struct {
+		cache.PubSub
+		published atomic.Int32
+	}
+)
+
+func (b *countingBus) Publish(ctx context.Context, channel string, payload []byte) error {
+	b.published.Add(1)
+	return b.PubSub.Publish(ctx, channel, payload)
+}
+
+func TestRemoteCache_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer(t)
+	c := newTestRemote[int, remoteValue](t, srv, mock_logger.NewMockLogger(gomock.NewController(t)))
+
+	c.Put(1, remoteValue{"one"}, 0)
+	c.Put(2, remoteValue{"two"}, time.Hour)
+	c.Put(3, remoteValue{"short"}, 20*time.Millisecond)
+
+	if got, ok := c.Get(1); !ok || got.Title != "one" {
+		t.Errorf("Get(1) = %+v, %v; want one", got, ok)
+	}
+	if _, ok := c.Get(4); ok {
+		t.Errorf("Get(4) = _, true; want miss")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if c.Has(3) {
+		t.Errorf("Has(3) = true; the server must expire it")
+	}
+
+	keys := c.Keys()
+	slices.Sort(keys)
+	if !slices.Equal(keys, []int{1, 2}) {
+		t.Errorf("Keys() = %v; want [1 2]", keys)
+	}
+
+	snapshot := c.Snapshot()
+	if len(snapshot) != 2 || !snapshot[0].ExpiresAt.IsZero() || snapshot[1].ExpiresAt.IsZero() {
+		t.Errorf("Snapshot() = %+v; want key 1 without expiry and key 2 with one", snapshot)
+	}
+
+	if !c.Delete(1) || c.Delete(1) {
+		t.Errorf("Delete(1) must report true once")
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Deletions != 1 || stats.Len != 1 {
+		t.Errorf("Stats() = %+v; want 1 hit, 1 miss, 1 deletion, len 1", stats)
+	}
+
+	c.Purge()
+	if n := c.Len(); n != 0 {
+		t.Errorf("Len() after Purge = %d; want 0", n)
+	}
+}
+
+func TestRemoteCache_ServerDownIsMiss(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer(t)
+	mockLogger := mock_logger.NewMockLogger(gomock.NewController(t))
+	mockLogger.EXPECT().
+		Warnw("remote cache error", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		MinTimes(2)
+
+	c := newTestRemote[string, string](t, srv, mockLogger)
+	c.Put("a", "1", 0)
+	srv.Close()
+
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("Get(a) = _, true with the server down; want miss")
+	}
+	c.Put("a", "2", 0)
+}
+
+func TestTieredCache_ReadsThroughToFar(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer(t)
+	mockLogger := mock_logger.NewMockLogger(gomock.NewController(t))
+	far := newTestRemote[int, string](t, srv, mockLogger)
+
+	a := newTestTiered(t, srv, mockLogger)
+	b := newTestTiered(t, srv, mockLogger)
+
+	a.Put(1, "one", 0)
+	if got, ok := b.Get(1); !ok || got != "one" {
+		t.Fatalf("b.Get(1) = %q, %v; want the value written by a", got, ok)
+	}
+
+	far.Delete(1)
+	if got, ok := b.Get(1); !ok || got != "one" {
+		t.Errorf("b.Get(1) = %q, %v; want it served from the near tier", got, ok)
+	}
+
+	if stats := b.Stats(); stats.Hits != 2 || stats.Misses != 0 {
+		t.Errorf("b.Stats() = %+v; want a far hit and a near hit", stats)
+	}
+}
+
+func TestTieredCache_Invalidation(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc   string
+		change func(c *cache.TieredCache[int, string])
+		want   string
+		wantOK bool
+	}{
+		{"Put", func(c *cache.TieredCache[int, string]) { c.Put(1, "two", 0) }, "two", true},
+		{"Delete", func(c *cache.TieredCache[int, string]) { c.Delete(1) }, "", false},
+		{"Purge", func(c *cache.TieredCache[int, string]) { c.Purge() }, "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			srv := newTestServer(t)
+			mockLogger := mock_logger.NewMockLogger(gomock.NewController(t))
+			a := newTestTiered(t, srv, mockLogger)
+			b := newTestTiered(t, srv, mockLogger)
+
+			a.Put(1, "one", 0)
+			if got, _ := b.Get(1); got != "one" {
+				t.Fatalf("b.Get(1) = %q; want one", got)
+			}
+
+			// Run subscribes asynchronously, so repeat the change until the
+			// broadcast reaches b.
+			eventually(t, "invalidation on b", func() bool {
+				tc.change(a)
+				got, ok := b.Get(1)
+				return got == tc.want && ok == tc.wantOK
+			})
+		})
+	}
+}
+
+func TestTieredCache_LoadingReplicas(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer(t)
+	mockLogger := mock_logger.NewMockLogger(gomock.NewController(t))
+
+	newReplica := func(opt cache.LoadingOption) *cache.LoadingCache[int, string] {
+		near, _ := cache.NewLRUCache[int, *cache.Loaded[string]](10, mockLogger)
+		far := newTestRemote[int, *cache.Loaded[string]](t, srv, mockLogger)
+		tiered := startTiered(t, near, far, resp.NewClient(srv.Addr()), mockLogger)
+
+		c, err := cache.NewLoadingCache[int, string](tiered, mockLogger, opt)
+		if err != nil {
+			t.Fatalf("NewLoadingCache() error = %v", err)
+		}
+		return c
+	}
+	shared := cache.WithSharedNegativeCaching(time.Minute, errTestNotFound)
+	a, b := newReplica(shared), newReplica(shared)
+	loader := &countingLoader{value: "one"}
+
+	for _, c := range []*cache.LoadingCache[int, string]{a, b} {
+		if got, err := c.GetOrLoad(context.Background(), 1, loader.load); err != nil || got != "one" {
+			t.Fatalf("GetOrLoad(1) = %q, %v; want one", got, err)
+		}
+	}
+	if calls := loader.calls.Load(); calls != 1 {
+		t.Errorf("loader calls = %d; the second replica must read the shared tier", calls)
+	}
+
+	missing := &countingLoader{err: errTestNotFound}
+	_, _ = a.GetOrLoad(context.Background(), 2, missing.load)
+	if _, err := b.GetOrLoad(context.Background(), 2, missing.load); !errors.Is(err, errTestNotFound) || missing.calls.Load() != 1 {
+		t.Errorf("b.GetOrLoad(2) error = %v after %d loads; want the shared not-found entry", err, missing.calls.Load())
+	}
+
+	// Without a known not-found error a shared entry cannot be answered, so
+	// it is reloaded.
+	c := newReplica(cache.WithNegativeCaching(time.Minute, func(err error) bool {
+		return errors.Is(err, errTestNotFound)
+	}))
+	_, err := c.GetOrLoad(context.Background(), 2, missing.load)
+	if !errors.Is(err, errTestNotFound) || missing.calls.Load() != 2 {
+		t.Errorf("c.GetOrLoad(2) error = %v after %d loads; want a reload", err, missing.calls.Load())
+	}
+
+	eventually(t, "updated value on b", func() bool {
+		a.Put(1, "updated", time.Minute)
+		got, ok := b.Get(1)
+		return ok && got == "updated"
+	})
+}
+
+func TestTieredCache_FillIsNotBroadcast(t *testing.T) {
+	t.Parallel()
+
+	srv := newTestServer(t)
+	mockLogger := mock_logger.NewMockLogger(gomock.NewController(t))
+	client := resp.NewClient(srv.Addr())
+	t.Cleanup(func() { client.Close() })
+	bus := &countingBus{PubSub: client}
+
+	near, _ := cache.NewLRUCache[int, *cache.Loaded[string]](10, mockLogger)
+	far := newTestRemote[int, *cache.Loaded[string]](t, srv, mockLogger)
+	tiered, err := cache.NewTieredCache(near, far, mockLogger, cache.WithInvalidation(bus, "test:invalidate"))
+	if err != nil {
+		t.Fatalf("NewTieredCache() error = %v", err)
+	}
+	c, err := cache.NewLoadingCache[int, string](tiered, mockLogger,
+		cache.WithSharedNegativeCaching(time.Minute, errTestNotFound))
+	if err != nil {
+		t.Fatalf("NewLoadingCache() error = %v", err)
+	}
+
+	_, _ = c.GetOrLoad(context.Background(), 1, (&countingLoader{value: "one"}).load)
+	_, _ = c.GetOrLoad(context.Background(), 2, (&countingLoader{err: errTestNotFound}).load)
+	if got, ok := c.Get(1); !ok || got != "one" {
+		t.Fatalf("Get(1) = %q, %v; want the loaded value", got, ok)
+	}
+	if n := bus.published.Load(); n != 0 {
+		t.Errorf("published %d invalidations for loads; want none", n)
+	}
+
+	c.Put(1, "updated", time.Minute)
+	c.Delete(2)
+	if n := bus.published.Load(); n != 2 {
+		t.Errorf("published %d invalidations for a put and a delete; want 2", n)
+	}
+}
+
+func newTestServer(t *testing.T) *resp.Server {
+	t.Helper()
+
+	srv, err := resp.NewServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	return srv
+}
+
+func newTestRemote[K comparable, V any](t *testing.T, srv *resp.Server, log *mock_logger.MockLogger) *cache.RemoteCache[K, V] {
+	t.Helper()
+
+	client := resp.NewClient(srv.Addr())
+	t.Cleanup(func() { client.Close() })
+
+	c, err := cache.NewRemoteCache[K, V](client, log, cache.WithKeyPrefix("test:"))
+	if err != nil {
+		t.Fatalf("NewRemoteCache() error = %v", err)
+	}
+	return c
+}
+
+func newTestTiered(t *testing.T, srv *resp.Server, log *mock_logger.MockLogger) *cache.TieredCache[int, string] {
+	t.Helper()
+
+	near, err := cache.NewLRUCache[int, string](10, log)
+	if err != nil {
+		t.Fatalf("NewLRUCache() error = %v", err)
+	}
+	return startTiered(t, near, newTestRemote[int, string](t, srv, log), resp.NewClient(srv.Addr()), log)
+}
+
+// startTiered builds a TieredCache with a long near TTL, so only
+// invalidation can make a replica drop its near copy, and runs it until the
+// test ends.
+func startTiered[V any](
+	t *testing.T,
+	near, far cache.Cache[int, V],
+	bus *resp.Client,
+	log *mock_logger.MockLogger,
+) *cache.TieredCache[int, V] {
+	t.Helper()
+
+	c, err := cache.NewTieredCache(near, far, log,
+		cache.WithNearTTL(time.Minute),
+		cache.WithInvalidation(bus, "test:invalidate"),
+	)
+	if err != nil {
+		t.Fatalf("NewTieredCache() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- c.Run(ctx) }()
+
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+		bus.Close()
+	})
+	return c
+}
@@ -0,0 +1,239 @@
+package cache
+
+import (
+	"hash/maphash"
+	"math/bits"
+)
+
+const (
+	_windowPercent    = 1
+	_protectedPercent = 80
+	_sketchDepth      = 4
+	_sketchMaxCount   = 15
+	_sketchSampleMul  = 10
+	_sketchWidthMul   = 8
+	_minSketchWidth   = 16
+)
+
+// Odd 64-bit multipliers from SplitMix64 and xxHash, one per sketch row.
+const (
+	_sketchRow0 = 0x9e3779b97f4a7c15
+	_sketchRow1 = 0xbf58476d1ce4e5b9
+	_sketchRow2 = 0x94d049bb133111eb
+	_sketchRow3 = 0xc2b2ae3d27d4eb4f
+)
+
+type (
+	// tinyLFUPolicy is W-TinyLFU (Einziger, Friedman, Manes): new keys enter
+	// a small LRU window; a key leaving the window only gets into the main
+	// segmented LRU if the frequency sketch says it is used more often than
+	// the main area's eviction candidate. Recency bursts are absorbed by the
+	// window while scans of cold keys are refused admission.
+	tinyLFUPolicy[K comparable] struct {
+		window    *keyList[K]
+		probation *keyList[K]
+		protected *keyList[K]
+
+		windowCap    int
+		protectedCap int
+		mainCap      int
+
+		sketch *countMinSketch[K]
+	}
+
+	// countMinSketch estimates key frequencies with 4-bit style saturating
+	// counters. After sampleSize increments all counters are halved so the
+	// estimate follows recent popularity.
+	countMinSketch[K comparable] struct {
+		seed       maphash.Seed
+		rows       [_sketchDepth][]uint8
+		mask       uint64
+		additions  int
+		sampleSize int
+	}
+)
+
+func newTinyLFUPolicy[K comparable](capacity int) *tinyLFUPolicy[K] {
+	p := &tinyLFUPolicy[K]{
+		window:    newKeyList[K](),
+		probation: newKeyList[K](),
+		protected: newKeyList[K](),
+	}
+	p.setCapacity(capacity)
+	return p
+}
+
+func (p *tinyLFUPolicy[K]) setCapacity(capacity int) {
+	p.windowCap = max(1, capacity*_windowPercent/100)
+	p.mainCap = capacity - p.windowCap
+	p.protectedCap = p.mainCap * _protectedPercent / 100
+	if p.sketch == nil || p.sketch.mask != sketchWidth(capacity)-1 {
+		p.sketch = newCountMinSketch[K](capacity)
+	}
+}
+
+func (p *tinyLFUPolicy[K]) access(key K) {
+	p.sketch.increment(key)
+
+	switch {
+	case p.window.contains(key):
+		p.window.moveToFront(key)
+	case p.protected.contains(key):
+		p.protected.moveToFront(key)
+	case p.probation.remove(key):
+		p.protected.pushFront(key)
+		if p.protected.len() > p.protectedCap {
+			demoted, _ := p.protected.popBack()
+			p.probation.pushFront(demoted)
+		}
+	}
+}
+
+func (p *tinyLFUPolicy[K]) add(key K) []K {
+	p.sketch.increment(key)
+	p.window.pushFront(key)
+
+	var victims []K
+	for p.window.len() > p.windowCap {
+		candidate, _ := p.window.popBack()
+		if victim, evicted := p.admit(candidate); evicted {
+			victims = append(victims, victim)
+		}
+	}
+	return victims
+}
+
+// admit moves a key leaving the window into probation. When the main area is
+// full the candidate duels the main area's LRU key and the less frequent one
+// is evicted; ties favour the incumbent.
+func (p *tinyLFUPolicy[K]) admit(candidate K) (K, bool) {
+	if p.probation.len()+p.protected.len() < p.mainCap {
+		p.probation.pushFront(candidate)
+		var zero K
+		return zero, false
+	}
+
+	incumbent, ok := p.probation.back()
+	from := p.probation
+	if !ok {
+		if incumbent, ok = p.protected.back(); !ok {
+			return candidate, true
+		}
+		from = p.protected
+	}
+
+	if p.sketch.estimate(candidate) <= p.sketch.estimate(incumbent) {
+		return candidate, true
+	}
+
+	from.remove(incumbent)
+	p.probation.pushFront(candidate)
+	return incumbent, true
+}
+
+func (p *tinyLFUPolicy[K]) remove(key K) {
+	if !p.window.remove(key) && !p.probation.remove(key) {
+		p.protected.remove(key)
+	}
+}
+
+func (p *tinyLFUPolicy[K]) resize(capacity int) []K {
+	p.setCapacity(capacity)
+
+	for p.protected.len() > p.protectedCap {
+		demoted, _ := p.protected.popBack()
+		p.probation.pushFront(demoted)
+	}
+
+	var victims []K
+	for p.window.len() > p.windowCap {
+		demoted, _ := p.window.popBack()
+		p.probation.pushFront(demoted)
+	}
+	for p.probation.len()+p.protected.len() > p.mainCap {
+		victim, ok := p.probation.popBack()
+		if !ok {
+			victim, _ = p.protected.popBack()
+		}
+		victims = append(victims, victim)
+	}
+	return victims
+}
+
+func (p *tinyLFUPolicy[K]) reset() {
+	p.window.reset()
+	p.probation.reset()
+	p.protected.reset()
+	p.sketch.reset()
+}
+
+func newCountMinSketch[K comparable](capacity int) *countMinSketch[K] {
+	width := sketchWidth(capacity)
+
+	s := &countMinSketch[K]{
+		seed:       maphash.MakeSeed(),
+		mask:       width - 1,
+		sampleSize: _sketchSampleMul * capacity,
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+// sketchWidth gives each row several counters per cached key so that a scan
+// many times larger than the cache doesn't saturate the hot keys' counters.
+func sketchWidth(capacity int) uint64 {
+	return max(_minSketchWidth, uint64(1)<<bits.Len(uint(capacity*_sketchWidthMul)))
+}
+
+// indexes spreads the full 64-bit hash over the rows with a distinct odd
+// multiplier each, so two keys sharing a bucket in one row rarely share
+// buckets in the others.
+func (s *countMinSketch[K]) indexes(key K) [_sketchDepth]uint64 {
+	hash := maphash.Comparable(s.seed, key)
+
+	var idx [_sketchDepth]uint64
+	for i, mul := range [_sketchDepth]uint64{_sketchRow0, _sketchRow1, _sketchRow2, _sketchRow3} {
+		h := hash * mul
+		idx[i] = (h ^ h>>32) & s.mask
+	}
+	return idx
+}
+
+func (s *countMinSketch[K]) increment(key K) {
+	for row, i := range s.indexes(key) {
+		if s.rows[row][i] < _sketchMaxCount {
+			s.rows[row][i]++
+		}
+	}
+
+	s.additions++
+	if s.additions >= s.sampleSize {
+		s.halve()
+	}
+}
+
+func (s *countMinSketch[K]) estimate(key K) uint8 {
+	result := uint8(_sketchMaxCount)
+	for row, i := range s.indexes(key) {
+		result = min(result, s.rows[row][i])
+	}
+	return result
+}
+
+func (s *countMinSketch[K]) halve() {
+	for _, row := range s.rows {
+		for i := range row {
+			row[i] >>= 1
+		}
+	}
+	s.additions /= 2
+}
+
+func (s *countMinSketch[K]) reset() {
+	for _, row := range s.rows {
+		clear(row)
+	}
+	s.additions = 0
+}
@@ -0,0 +1,153 @@
+// Package graphql executes GraphQL requests against a schema built in code.
+//
+// It covers what clients use day to day: queries and mutations with
+// variables, aliases, named and inline fragments, the @include and @skip
+// directives and __typename. Subscriptions and introspection queries are not
+// supported; Schema.SDL prints the schema for client tooling instead.
+package graphql
+
+import "strconv"
+
+type (
+	// Pos is a 1-based location in the request source.
+	Pos struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+	}
+
+	Document struct {
+		Operations []*Operation
+		Fragments  map[string]*Fragment
+	}
+
+	Operation struct {
+		Kind       string
+		Name       string
+		Variables  []*VariableDefinition
+		Directives []*Directive
+		Selections []Selection
+		Pos        Pos
+	}
+
+	VariableDefinition struct {
+		Name    string
+		Type    *TypeRef
+		Default *Value
+		Pos     Pos
+	}
+
+	// TypeRef is a type as written in a variable definition.
+	TypeRef struct {
+		Name    string
+		Elem    *TypeRef
+		NonNull bool
+	}
+
+	// Selection is a *FieldNode, *FragmentSpread or *InlineFragment.
+	Selection interface {
+		position() Pos
+	}
+
+	FieldNode struct {
+		Alias      string
+		Name       string
+		Arguments  []*Argument
+		Directives []*Directive
+		Selections []Selection
+		Pos        Pos
+	}
+
+	FragmentSpread struct {
+		Name       string
+		Directives []*Directive
+		Pos        Pos
+	}
+
+	InlineFragment struct {
+		TypeCondition string
+		Directives    []*Directive
+		Selections    []Selection
+		Pos           Pos
+	}
+
+	Fragment struct {
+		Name          string
+		TypeCondition string
+		Directives    []*Directive
+		Selections    []Selection
+		Pos           Pos
+	}
+
+	Argument struct {
+		Name  string
+		Value *Value
+		Pos   Pos
+	}
+
+	Directive struct {
+		Name      string
+		Arguments []*Argument
+		Pos       Pos
+	}
+
+	// Value is a literal in the request. Raw holds the text of scalars and
+	// enums and the name of variables.
+	Value struct {
+		Kind   ValueKind
+		Raw    string
+		List   []*Value
+		Fields []*ObjectField
+		Pos    Pos
+	}
+
+	ObjectField struct {
+		Name  string
+		Value *Value
+	}
+
+	ValueKind int
+)
+
+const (
+	VariableValue ValueKind = iota
+	IntValue
+	FloatValue
+	StringValue
+	BooleanValue
+	NullValue
+	EnumValue
+	ListValue
+	ObjectValue
+)
+
+const (
+	OperationQuery    = "query"
+	OperationMutation = "mutation"
+)
+
+func (f *FieldNode) position() Pos      { return f.Pos }
+func (f *FragmentSpread) position() Pos { return f.Pos }
+func (f *InlineFragment) position() Pos { return f.Pos }
+
+// ResponseKey is the name the field gets in the result.
+func (f *FieldNode) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+func (t *TypeRef) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+func (p Pos) String() string {
+	return strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Column)
+}
@@ -0,0 +1,40 @@
+package graphql
+
+import (
+	"errors"
+	"strings"
+)
+
+// Error is an entry of the "errors" list of a response. Resolvers may return
+// an *Error, possibly wrapped, to set Extensions; Locations and Path are
+// filled in by the executor.
+type Error struct {
+	Message    string         `json:"message"`
+	Locations  []Pos          `json:"locations,omitempty"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString("graphql: ")
+	b.WriteString(e.Message)
+	if len(e.Locations) > 0 {
+		b.WriteString(" at ")
+		b.WriteString(e.Locations[0].String())
+	}
+	return b.String()
+}
+
+// fieldError converts what a resolver returned into a response error.
+func fieldError(err error, pos Pos, path []any) *Error {
+	result := &Error{Message: err.Error()}
+	var gqlErr *Error
+	if errors.As(err, &gqlErr) {
+		result.Message = gqlErr.Message
+		result.Extensions = gqlErr.Extensions
+	}
+	result.Locations = []Pos{pos}
+	result.Path = path
+	return result
+}
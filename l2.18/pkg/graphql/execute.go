@@ -0,0 +1,396 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	_defaultListFactor = 10
+	_typenameField     = "__typename"
+)
+
+type (
+	// Request is the body of a GraphQL HTTP request. Variables should be
+	// decoded with json.Decoder.UseNumber so that integers keep their
+	// precision; other numeric types are converted.
+	Request struct {
+		Query         string         `json:"query"`
+		OperationName string         `json:"operationName,omitempty"`
+		Variables     map[string]any `json:"variables,omitempty"`
+	}
+
+	// Response is the result of a request. Data is omitted from the JSON
+	// form when the request failed before execution.
+	Response struct {
+		Data   any
+		Errors []*Error
+
+		executed bool
+	}
+
+	// Options limit what a request may ask for. Zero values disable the
+	// limits.
+	//
+	// Complexity is the sum of the field costs, where the selections under a
+	// list field count ListFactor times (10 by default), since a list is
+	// expected to hold several items.
+	Options struct {
+		MaxDepth      int
+		MaxComplexity int
+		ListFactor    int
+		// QueryOnly rejects mutations, as for GET requests.
+		QueryOnly bool
+	}
+
+	execution struct {
+		doc    *Document
+		vars   map[string]any
+		args   map[*FieldNode]map[string]any
+		errors []*Error
+	}
+
+	// object is a result map that keeps the order of the selection set.
+	object struct {
+		keys   []string
+		values []any
+	}
+
+	fieldGroup struct {
+		key   string
+		nodes []*FieldNode
+	}
+)
+
+// Execute parses, validates and runs a request. Fields are resolved one at a
+// time in the order of the request, for queries as well as mutations.
+func (s *Schema) Execute(ctx context.Context, req Request, opts Options) *Response {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return failed(err)
+	}
+
+	op, gqlErr := selectOperation(doc, req.OperationName)
+	if gqlErr != nil {
+		return failed(gqlErr)
+	}
+
+	root := s.query
+	if op.Kind == OperationMutation {
+		if opts.QueryOnly {
+			return failed(&Error{Message: "Only queries are allowed in this request", Locations: []Pos{op.Pos}})
+		}
+		if s.mutation == nil {
+			return failed(&Error{Message: "Schema does not support mutations", Locations: []Pos{op.Pos}})
+		}
+		root = s.mutation
+	}
+
+	vars, gqlErr := s.coerceVariables(op, req.Variables)
+	if gqlErr != nil {
+		return failed(gqlErr)
+	}
+
+	v := newValidator(s, doc, op, vars, opts)
+	if err := v.validate(root); err != nil {
+		return failed(err)
+	}
+
+	e := &execution{doc: doc, vars: vars, args: v.args}
+	data, ok := e.selectionSet(ctx, root, nil, op.Selections, nil)
+	resp := &Response{Errors: e.errors, executed: true}
+	if ok {
+		resp.Data = data
+	}
+	return resp
+}
+
+// Executed reports whether the request got to execution, which makes data
+// part of the response even when it is null.
+func (r *Response) Executed() bool {
+	return r.executed
+}
+
+func (r *Response) MarshalJSON() ([]byte, error) {
+	body := make(map[string]any, 2)
+	if r.executed {
+		body["data"] = r.Data
+	}
+	if len(r.Errors) > 0 {
+		body["errors"] = r.Errors
+	}
+	return json.Marshal(body)
+}
+
+func failed(err error) *Response {
+	gqlErr, ok := err.(*Error)
+	if !ok {
+		gqlErr = &Error{Message: err.Error()}
+	}
+	return &Response{Errors: []*Error{gqlErr}}
+}
+
+func selectOperation(doc *Document, name string) (*Operation, *Error) {
+	if name == "" {
+		if len(doc.Operations) > 1 {
+			return nil, &Error{Message: "Must provide operation name if query contains multiple operations"}
+		}
+		return doc.Operations[0], nil
+	}
+
+	for _, op := range doc.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("Unknown operation named %q", name)}
+}
+
+// selectionSet resolves the fields of one object. It reports false when a
+// non-null field failed, so the failure must spread to the parent.
+func (e *execution) selectionSet(
+	ctx context.Context,
+	t *Object,
+	source any,
+	selections []Selection,
+	path []any,
+) (*object, bool) {
+	groups := collectFields(e.doc, e.vars, selections, nil, make(map[string]bool), false)
+	result := &object{keys: make([]string, 0, len(groups)), values: make([]any, 0, len(groups))}
+	for _, group := range groups {
+		value, ok := e.field(ctx, t, source, group, appendPath(path, group.key))
+		if !ok {
+			return nil, false
+		}
+		result.keys = append(result.keys, group.key)
+		result.values = append(result.values, value)
+	}
+	return result, true
+}
+
+// collectFields groups the fields of a selection set by response key,
+// expanding fragments. With all set, @skip and @include are ignored, as
+// validation needs to see every field.
+func collectFields(
+	doc *Document,
+	vars map[string]any,
+	selections []Selection,
+	groups []*fieldGroup,
+	visited map[string]bool,
+	all bool,
+) []*fieldGroup {
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *FieldNode:
+			if !all && !included(s.Directives, vars) {
+				continue
+			}
+			key := s.ResponseKey()
+			var group *fieldGroup
+			for _, g := range groups {
+				if g.key == key {
+					group = g
+					break
+				}
+			}
+			if group == nil {
+				group = &fieldGroup{key: key}
+				groups = append(groups, group)
+			}
+			group.nodes = append(group.nodes, s)
+		case *InlineFragment:
+			if !all && !included(s.Directives, vars) {
+				continue
+			}
+			groups = collectFields(doc, vars, s.Selections, groups, visited, all)
+		case *FragmentSpread:
+			if visited[s.Name] || (!all && !included(s.Directives, vars)) {
+				continue
+			}
+			visited[s.Name] = true
+			if fragment := doc.Fragments[s.Name]; fragment != nil {
+				groups = collectFields(doc, vars, fragment.Selections, groups, visited, all)
+			}
+		}
+	}
+	return groups
+}
+
+func (e *execution) field(ctx context.Context, t *Object, source any, group *fieldGroup, path []any) (any, bool) {
+	node := group.nodes[0]
+	if node.Name == _typenameField {
+		return t.Name, true
+	}
+
+	def := t.field(node.Name)
+	value, err := e.resolve(ctx, def, source, e.args[node])
+	if err != nil {
+		e.errors = append(e.errors, fieldError(err, node.Pos, path))
+		_, nonNull := def.Type.(*NonNull)
+		return nil, !nonNull
+	}
+	return e.completeNullable(ctx, def.Type, group.nodes, value, path)
+}
+
+func (e *execution) resolve(ctx context.Context, def *Field, source any, args map[string]any) (value any, err error) {
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	if def.Resolve == nil {
+		return defaultResolve(source, def.Name), nil
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("resolver panicked: %v", r)
+		}
+	}()
+	return def.Resolve(ctx, ResolveParams{Source: source, Args: args})
+}
+
+// completeNullable completes a value and turns a failure into null unless t
+// is non-null.
+func (e *execution) completeNullable(
+	ctx context.Context,
+	t Type,
+	nodes []*FieldNode,
+	value any,
+	path []any,
+) (any, bool) {
+	result, ok := e.complete(ctx, t, nodes, value, path)
+	if !ok {
+		if _, nonNull := t.(*NonNull); !nonNull {
+			return nil, true
+		}
+	}
+	return result, ok
+}
+
+func (e *execution) complete(ctx context.Context, t Type, nodes []*FieldNode, value any, path []any) (any, bool) {
+	if nn, ok := t.(*NonNull); ok {
+		result, ok := e.complete(ctx, nn.Of, nodes, value, path)
+		if !ok {
+			return nil, false
+		}
+		if result == nil {
+			e.errors = append(e.errors, &Error{
+				Message:   "Cannot return null for non-nullable field " + nodes[0].Name,
+				Locations: []Pos{nodes[0].Pos},
+				Path:      path,
+			})
+			return nil, false
+		}
+		return result, true
+	}
+	if isNil(value) {
+		return nil, true
+	}
+
+	switch t := t.(type) {
+	case *Scalar:
+		result, err := t.Serialize(value)
+		if err != nil {
+			e.errors = append(e.errors, fieldError(err, nodes[0].Pos, path))
+			return nil, false
+		}
+		return result, true
+	case *List:
+		items := reflect.ValueOf(value)
+		if kind := items.Kind(); kind != reflect.Slice && kind != reflect.Array {
+			e.errors = append(e.errors, fieldError(fmt.Errorf("expected a list, got %T", value), nodes[0].Pos, path))
+			return nil, false
+		}
+		result := make([]any, items.Len())
+		for i := range result {
+			item, ok := e.completeNullable(ctx, t.Of, nodes, items.Index(i).Interface(), appendPath(path, i))
+			if !ok {
+				return nil, false
+			}
+			result[i] = item
+		}
+		return result, true
+	case *Object:
+		var selections []Selection
+		for _, node := range nodes {
+			selections = append(selections, node.Selections...)
+		}
+		result, ok := e.selectionSet(ctx, t, value, selections, path)
+		if !ok {
+			return nil, false
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+// defaultResolve reads a map entry or an exported struct field named like the
+// field, ignoring case.
+func defaultResolve(source any, name string) any {
+	if m, ok := source.(map[string]any); ok {
+		return m[name]
+	}
+
+	v := reflect.ValueOf(source)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	f := v.FieldByNameFunc(func(field string) bool { return strings.EqualFold(field, name) })
+	if !f.IsValid() || !f.CanInterface() {
+		return nil
+	}
+	return f.Interface()
+}
+
+// isNil reports whether a resolved value is null. A nil slice is an empty
+// list rather than null, as in Go code.
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Interface, reflect.Func, reflect.Chan:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// appendPath copies the path, as sibling fields share its prefix.
+func appendPath(path []any, key any) []any {
+	next := make([]any, len(path), len(path)+1)
+	copy(next, path)
+	return append(next, key)
+}
+
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
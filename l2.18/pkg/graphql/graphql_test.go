@@ -0,0 +1,472 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"calendar-wbf/pkg/graphql"
+)
+
+type (
+	book struct {
+		ID     int
+		Title  string
+		Author *author
+		Tags   []string
+	}
+
+	author struct {
+		Name string
+	}
+)
+
+func newTestSchema(t *testing.T) *graphql.Schema {
+	t.Helper()
+
+	books := []*book{
+		{ID: 1, Title: "Dune", Author: &author{Name: "Herbert"}, Tags: []string{"sf"}},
+		{ID: 2, Title: "Emma"},
+		{ID: 3, Title: "Ulysses", Author: &author{Name: "Joyce"}, Tags: []string{"classic", "long"}},
+	}
+
+	authorType := &graphql.Object{
+		Name:   "Author",
+		Fields: []*graphql.Field{{Name: "name", Type: &graphql.NonNull{Of: graphql.String}}},
+	}
+	bookType := &graphql.Object{
+		Name:        "Book",
+		Description: "A book.",
+		Fields: []*graphql.Field{
+			{Name: "id", Type: &graphql.NonNull{Of: graphql.ID}},
+			{Name: "title", Type: &graphql.NonNull{Of: graphql.String}},
+			{Name: "author", Type: authorType},
+			{Name: "tags", Type: &graphql.NonNull{Of: &graphql.List{Of: &graphql.NonNull{Of: graphql.String}}}},
+			{
+				Name: "broken",
+				Type: &graphql.NonNull{Of: graphql.String},
+				Resolve: func(context.Context, graphql.ResolveParams) (any, error) {
+					return nil, nil
+				},
+			},
+		},
+	}
+	bookInput := &graphql.InputObject{
+		Name: "BookInput",
+		Fields: []*graphql.InputValue{
+			{Name: "title", Type: &graphql.NonNull{Of: graphql.String}},
+			{Name: "tags", Type: &graphql.List{Of: &graphql.NonNull{Of: graphql.String}}},
+		},
+	}
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: &graphql.Object{
+			Name: "Query",
+			Fields: []*graphql.Field{
+				{
+					Name: "book",
+					Type: bookType,
+					Args: []*graphql.InputValue{{Name: "id", Type: &graphql.NonNull{Of: graphql.ID}}},
+					Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+						for _, b := range books {
+							if p.Args["id"] == strconv.Itoa(b.ID) {
+								return b, nil
+							}
+						}
+						return nil, &graphql.Error{
+							Message:    "book not found",
+							Extensions: map[string]any{"code": "NOT_FOUND"},
+						}
+					},
+				},
+				{
+					Name: "books",
+					Type: &graphql.NonNull{Of: &graphql.List{Of: &graphql.NonNull{Of: bookType}}},
+					Args: []*graphql.InputValue{{Name: "limit", Type: graphql.Int, Default: 2}},
+					Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+						return books[:min(p.Args["limit"].(int), len(books))], nil
+					},
+				},
+				{
+					Name: "panics",
+					Type: graphql.String,
+					Resolve: func(context.Context, graphql.ResolveParams) (any, error) {
+						panic("boom")
+					},
+				},
+			},
+		},
+		Mutation: &graphql.Object{
+			Name: "Mutation",
+			Fields: []*graphql.Field{
+				{
+					Name: "addBook",
+					Type: &graphql.NonNull{Of: bookType},
+					Args: []*graphql.InputValue{{Name: "input", Type: &graphql.NonNull{Of: bookInput}}},
+					Resolve: func(_ context.Context, p graphql.ResolveParams) (any, error) {
+						input := p.Args["input"].(map[string]any)
+						b := &book{ID: 4, Title: input["title"].(string), Tags: []string{}}
+						if tags, ok := input["tags"].([]any); ok {
+							for _, tag := range tags {
+								b.Tags = append(b.Tags, tag.(string))
+							}
+						}
+						return b, nil
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewSchema() error = %v", err)
+	}
+	return schema
+}
+
+func TestSchema_Execute(t *testing.T) {
+	t.Parallel()
+
+	schema := newTestSchema(t)
+
+	testCases := []struct {
+		desc      string
+		query     string
+		operation string
+		variables map[string]any
+		opts      graphql.Options
+		want      string
+	}{
+		{
+			desc:  "AliasesAndNesting",
+			query: `{ first: book(id: 1) { title author { name } } other: book(id: "2") { title author { name } } }`,
+			want:  `{"data":{"first":{"title":"Dune","author":{"name":"Herbert"}},"other":{"title":"Emma","author":null}}}`,
+		},
+		{
+			desc: "FragmentsAndTypename",
+			query: `
+				query Books { books(limit: 3) { ...card ... on Book { tags } } }
+				fragment card on Book { __typename id }`,
+			want: `{"data":{"books":[` +
+				`{"__typename":"Book","id":"1","tags":["sf"]},` +
+				`{"__typename":"Book","id":"2","tags":[]},` +
+				`{"__typename":"Book","id":"3","tags":["classic","long"]}]}}`,
+		},
+		{
+			desc:  "ArgumentDefault",
+			query: `{ books { title } }`,
+			want:  `{"data":{"books":[{"title":"Dune"},{"title":"Emma"}]}}`,
+		},
+		{
+			desc:      "Variables",
+			query:     `query ($limit: Int = 3, $withTags: Boolean!) { books(limit: $limit) { id tags @include(if: $withTags) } }`,
+			variables: map[string]any{"limit": 1, "withTags": false},
+			want:      `{"data":{"books":[{"id":"1"}]}}`,
+		},
+		{
+			desc:      "VariableDefault",
+			query:     `query ($limit: Int = 1) { books(limit: $limit) { title @skip(if: false) } }`,
+			variables: map[string]any{},
+			want:      `{"data":{"books":[{"title":"Dune"}]}}`,
+		},
+		{
+			desc:      "MergedFields",
+			query:     `{ book(id: 3) { title author { name } } book(id: 3) { id author { __typename } } }`,
+			variables: map[string]any{},
+			want:      `{"data":{"book":{"title":"Ulysses","author":{"name":"Joyce","__typename":"Author"},"id":"3"}}}`,
+		},
+		{
+			desc:      "Mutation",
+			query:     `mutation Add($input: BookInput!) { addBook(input: $input) { id title tags } }`,
+			variables: map[string]any{"input": map[string]any{"title": "Walden", "tags": "essay"}},
+			want:      `{"data":{"addBook":{"id":"4","title":"Walden","tags":["essay"]}}}`,
+		},
+		{
+			desc:      "OperationName",
+			query:     `query A { books(limit: 1) { id } } query B { book(id: 2) { id } }`,
+			operation: "B",
+			want:      `{"data":{"book":{"id":"2"}}}`,
+		},
+		{
+			desc:  "NilSliceIsEmptyList",
+			query: `{ book(id: 2) { tags } }`,
+			want:  `{"data":{"book":{"tags":[]}}}`,
+		},
+		{
+			desc:  "ResolverError",
+			query: `{ found: book(id: 1) { id } missing: book(id: 9) { id } }`,
+			want: `{"data":{"found":{"id":"1"},"missing":null},"errors":[{"message":"book not found",` +
+				`"locations":[{"line":1,"column":29}],"path":["missing"],"extensions":{"code":"NOT_FOUND"}}]}`,
+		},
+		{
+			desc:  "NullPropagation",
+			query: `{ book(id: 1) { id broken } }`,
+			want: `{"data":{"book":null},"errors":[{"message":"Cannot return null for non-nullable field broken",` +
+				`"locations":[{"line":1,"column":20}],"path":["book","broken"]}]}`,
+		},
+		{
+			desc:  "NullPropagationToRoot",
+			query: `{ books(limit: 1) { broken } }`,
+			want: `{"data":null,"errors":[{"message":"Cannot return null for non-nullable field broken",` +
+				`"locations":[{"line":1,"column":21}],"path":["books",0,"broken"]}]}`,
+		},
+		{
+			desc:  "Panic",
+			query: `{ panics }`,
+			want: `{"data":{"panics":null},"errors":[{"message":"resolver panicked: boom",` +
+				`"locations":[{"line":1,"column":3}],"path":["panics"]}]}`,
+		},
+		{
+			desc:  "SyntaxError",
+			query: "{\n  book(id: 1 { id } }",
+			want:  `{"errors":[{"message":"Syntax error: unexpected \"{\"","locations":[{"line":2,"column":14}]}]}`,
+		},
+		{
+			desc:  "UnknownField",
+			query: `{ book(id: 1) { isbn } }`,
+			want:  `{"errors":[{"message":"Cannot query field \"isbn\" on type \"Book\"","locations":[{"line":1,"column":17}]}]}`,
+		},
+		{
+			desc:  "MissingArgument",
+			query: `{ book { id } }`,
+			want:  `{"errors":[{"message":"Argument \"id\" of type \"ID!\" is required","locations":[{"line":1,"column":3}]}]}`,
+		},
+		{
+			desc:  "InvalidArgument",
+			query: `{ books(limit: "two") { id } }`,
+			want: `{"errors":[{"message":"Argument \"limit\" has an invalid value: expected an integer, got string",` +
+				`"locations":[{"line":1,"column":9}]}]}`,
+		},
+		{
+			desc:  "MissingSelection",
+			query: `{ books }`,
+			want: `{"errors":[{"message":"Field \"books\" of type \"[Book!]!\" must have a selection of subfields",` +
+				`"locations":[{"line":1,"column":3}]}]}`,
+		},
+		{
+			desc:  "UndefinedVariable",
+			query: `{ books(limit: $n) { id } }`,
+			want:  `{"errors":[{"message":"Variable \"$n\" is not defined","locations":[{"line":1,"column":16}]}]}`,
+		},
+		{
+			desc:  "RequiredVariable",
+			query: `query ($id: ID!) { book(id: $id) { id } }`,
+			want: `{"errors":[{"message":"Variable \"$id\" of required type \"ID!\" was not provided",` +
+				`"locations":[{"line":1,"column":8}]}]}`,
+		},
+		{
+			desc:  "FragmentCycle",
+			query: `{ books { ...a } } fragment a on Book { ...b } fragment b on Book { ...a }`,
+			want:  `{"errors":[{"message":"Cannot spread fragment \"a\" within itself","locations":[{"line":1,"column":69}]}]}`,
+		},
+		{
+			desc:  "Conflict",
+			query: `{ book(id: 1) { x: id x: title } }`,
+			want: `{"errors":[{"message":"Fields \"x\" conflict because they differ in name or arguments",` +
+				`"locations":[{"line":1,"column":17},{"line":1,"column":23}]}]}`,
+		},
+		{
+			desc:  "DepthLimit",
+			query: `{ book(id: 1) { author { name } } }`,
+			opts:  graphql.Options{MaxDepth: 2},
+			want:  `{"errors":[{"message":"Query depth 3 exceeds the limit of 2","extensions":{"depth":3,"maxDepth":2}}]}`,
+		},
+		{
+			desc:  "ComplexityLimit",
+			query: `{ books { id author { name } } }`,
+			opts:  graphql.Options{MaxComplexity: 30},
+			want: `{"errors":[{"message":"Query complexity 31 exceeds the limit of 30",` +
+				`"extensions":{"complexity":31,"maxComplexity":30}}]}`,
+		},
+		{
+			desc:  "QueryOnly",
+			query: `mutation { addBook(input: {title: "x"}) { id } }`,
+			opts:  graphql.Options{QueryOnly: true},
+			want:  `{"errors":[{"message":"Only queries are allowed in this request","locations":[{"line":1,"column":1}]}]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			resp := schema.Execute(t.Context(), graphql.Request{
+				Query:         tc.query,
+				OperationName: tc.operation,
+				Variables:     tc.variables,
+			}, tc.opts)
+
+			got, err := json.Marshal(resp)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("Execute() =\n%s\nwant\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSchema_ExecuteRepeatedFragments(t *testing.T) {
+	t.Parallel()
+
+	// Each level spreads the next fragment twice, so the expanded request is
+	// exponential in size; the complexity check must reject it cheaply.
+	var b strings.Builder
+	b.WriteString("{ books { ...f0 } }\n")
+	const levels = 40
+	for i := range levels {
+		next := "id"
+		if i < levels-1 {
+			next = fmt.Sprintf("...f%d ...f%d", i+1, i+1)
+		}
+		fmt.Fprintf(&b, "fragment f%d on Book { %s }\n", i, next)
+	}
+
+	schema := newTestSchema(t)
+	resp := schema.Execute(t.Context(), graphql.Request{Query: b.String()}, graphql.Options{MaxComplexity: 1000})
+	if resp.Executed() || len(resp.Errors) != 1 || !strings.HasPrefix(resp.Errors[0].Message, "Query complexity") {
+		t.Fatalf("Execute() = %+v, want a complexity error", resp.Errors)
+	}
+}
+
+func TestSchema_ExecuteCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	resp := newTestSchema(t).Execute(ctx, graphql.Request{Query: `{ panics }`}, graphql.Options{})
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != context.Canceled.Error() {
+		t.Fatalf("Execute() errors = %+v, want %v", resp.Errors, context.Canceled)
+	}
+}
+
+func TestNewSchema_Invalid(t *testing.T) {
+	t.Parallel()
+
+	str := &graphql.NonNull{Of: graphql.String}
+	input := &graphql.InputObject{Name: "In", Fields: []*graphql.InputValue{{Name: "a", Type: str}}}
+
+	testCases := []struct {
+		desc  string
+		query *graphql.Object
+	}{
+		{
+			desc:  "NoFields",
+			query: &graphql.Object{Name: "Query"},
+		},
+		{
+			desc: "DuplicateField",
+			query: &graphql.Object{Name: "Query", Fields: []*graphql.Field{
+				{Name: "a", Type: str},
+				{Name: "a", Type: str},
+			}},
+		},
+		{
+			desc: "InputAsOutput",
+			query: &graphql.Object{Name: "Query", Fields: []*graphql.Field{
+				{Name: "a", Type: input},
+			}},
+		},
+		{
+			desc: "OutputAsInput",
+			query: &graphql.Object{Name: "Query", Fields: []*graphql.Field{
+				{Name: "a", Type: str, Args: []*graphql.InputValue{
+					{Name: "b", Type: &graphql.Object{Name: "B", Fields: []*graphql.Field{{Name: "c", Type: str}}}},
+				}},
+			}},
+		},
+		{
+			desc: "NameClash",
+			query: &graphql.Object{Name: "Query", Fields: []*graphql.Field{
+				{Name: "a", Type: &graphql.Object{Name: "String", Fields: []*graphql.Field{{Name: "c", Type: str}}}},
+			}},
+		},
+		{
+			desc: "ReservedName",
+			query: &graphql.Object{Name: "Query", Fields: []*graphql.Field{
+				{Name: "__a", Type: str},
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := graphql.NewSchema(graphql.SchemaConfig{Query: tc.query}); err == nil {
+				t.Fatal("NewSchema() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	doc, err := graphql.Parse(`
+		# comment
+		query Q($a: [ID!]! = ["1"], $b: In) @x {
+			alias: f(s: """
+				block
+				  text
+			""", o: {k: [1, 2.5e3, true, null, ENUM]}) { ... on T { g } }
+		}`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	op := doc.Operations[0]
+	if op.Name != "Q" || len(op.Variables) != 2 || op.Variables[0].Type.String() != "[ID!]!" {
+		t.Fatalf("operation = %+v, want Q with two variables", op)
+	}
+
+	field := op.Selections[0].(*graphql.FieldNode)
+	if field.ResponseKey() != "alias" || field.Name != "f" {
+		t.Errorf("field = %s: %s, want alias: f", field.ResponseKey(), field.Name)
+	}
+	if got := field.Arguments[0].Value.Raw; got != "block\n  text" {
+		t.Errorf("block string = %q, want %q", got, "block\n  text")
+	}
+	if got := len(field.Arguments[1].Value.Fields[0].Value.List); got != 5 {
+		t.Errorf("list length = %d, want 5", got)
+	}
+
+	for _, src := range []string{
+		`{ f(a: 1, a: 2) }`,
+		`{ f }}`,
+		`subscription { f }`,
+		`query ($a: Int = $b) { f }`,
+		`{ f(s: "unterminated) }`,
+		`fragment on on T { f }`,
+		strings.Repeat("{ f ", 200) + strings.Repeat("}", 200),
+	} {
+		var gqlErr *graphql.Error
+		if _, err := graphql.Parse(src); !errors.As(err, &gqlErr) {
+			t.Errorf("Parse(%.20q) error = %v, want a *graphql.Error", src, err)
+		}
+	}
+}
+
+func TestSchema_SDL(t *testing.T) {
+	t.Parallel()
+
+	sdl := newTestSchema(t).SDL()
+	for _, want := range []string{
+		"\"A book.\"\ntype Book {\n  id: ID!\n",
+		"input BookInput {\n  title: String!\n  tags: [String!]\n}",
+		"  books(limit: Int = 2): [Book!]!\n",
+		"type Mutation {\n  addBook(input: BookInput!): Book!\n}",
+	} {
+		if !strings.Contains(sdl, want) {
+			t.Errorf("SDL() does not contain %q:\n%s", want, sdl)
+		}
+	}
+	if strings.Contains(sdl, "scalar String") {
+		t.Errorf("SDL() prints built-in scalars:\n%s", sdl)
+	}
+}
@@ -0,0 +1,312 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// _bom is a byte order mark, which the spec ignores like whitespace.
+const _bom = "\ufeff"
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type (
+	token struct {
+		kind  tokenKind
+		value string
+		pos   Pos
+	}
+
+	lexer struct {
+		src  string
+		off  int
+		line int
+		col  int
+	}
+)
+
+func newLexer(src string) *lexer {
+	return &lexer{src: src, line: 1, col: 1}
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of request"
+	case tokenString:
+		return "string"
+	default:
+		return fmt.Sprintf("%q", t.value)
+	}
+}
+
+// next returns the next token, skipping whitespace, commas and comments.
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+	pos := Pos{Line: l.line, Column: l.col}
+	if l.off >= len(l.src) {
+		return token{kind: tokenEOF, pos: pos}, nil
+	}
+
+	c := l.src[l.off]
+	switch {
+	case strings.IndexByte("!$&()=:@[]{}|", c) >= 0:
+		l.advance(1)
+		return token{kind: tokenPunct, value: string(c), pos: pos}, nil
+	case c == '.':
+		if !strings.HasPrefix(l.src[l.off:], "...") {
+			return token{}, syntaxError(pos, "unexpected %q", c)
+		}
+		l.advance(3)
+		return token{kind: tokenPunct, value: "...", pos: pos}, nil
+	case c == '_' || isLetter(c):
+		start := l.off
+		for l.off < len(l.src) && (l.src[l.off] == '_' || isLetter(l.src[l.off]) || isDigit(l.src[l.off])) {
+			l.advance(1)
+		}
+		return token{kind: tokenName, value: l.src[start:l.off], pos: pos}, nil
+	case c == '-' || isDigit(c):
+		return l.number(pos)
+	case c == '"':
+		if strings.HasPrefix(l.src[l.off:], `"""`) {
+			return l.blockString(pos)
+		}
+		return l.string(pos)
+	default:
+		r, _ := utf8.DecodeRuneInString(l.src[l.off:])
+		return token{}, syntaxError(pos, "unexpected character %q", r)
+	}
+}
+
+func (l *lexer) skipIgnored() {
+	for l.off < len(l.src) {
+		switch c := l.src[l.off]; {
+		case c == '\n':
+			l.off++
+			l.line++
+			l.col = 1
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			l.advance(1)
+		case c == '#':
+			for l.off < len(l.src) && l.src[l.off] != '\n' {
+				l.advance(1)
+			}
+		case strings.HasPrefix(l.src[l.off:], _bom):
+			l.off += len(_bom)
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) number(pos Pos) (token, error) {
+	start := l.off
+	if l.src[l.off] == '-' {
+		l.advance(1)
+	}
+	if !l.digits() {
+		return token{}, syntaxError(pos, "invalid number")
+	}
+
+	kind := tokenInt
+	if l.off < len(l.src) && l.src[l.off] == '.' {
+		kind = tokenFloat
+		l.advance(1)
+		if !l.digits() {
+			return token{}, syntaxError(pos, "invalid number")
+		}
+	}
+	if l.off < len(l.src) && (l.src[l.off] == 'e' || l.src[l.off] == 'E') {
+		kind = tokenFloat
+		l.advance(1)
+		if l.off < len(l.src) && (l.src[l.off] == '+' || l.src[l.off] == '-') {
+			l.advance(1)
+		}
+		if !l.digits() {
+			return token{}, syntaxError(pos, "invalid number")
+		}
+	}
+	if l.off < len(l.src) && (l.src[l.off] == '_' || isLetter(l.src[l.off]) || l.src[l.off] == '.') {
+		return token{}, syntaxError(pos, "invalid number")
+	}
+	return token{kind: kind, value: l.src[start:l.off], pos: pos}, nil
+}
+
+func (l *lexer) digits() bool {
+	start := l.off
+	for l.off < len(l.src) && isDigit(l.src[l.off]) {
+		l.advance(1)
+	}
+	return l.off > start
+}
+
+func (l *lexer) string(pos Pos) (token, error) {
+	l.advance(1)
+
+	var b strings.Builder
+	for l.off < len(l.src) {
+		c := l.src[l.off]
+		switch {
+		case c == '"':
+			l.advance(1)
+			return token{kind: tokenString, value: b.String(), pos: pos}, nil
+		case c == '\n' || c == '\r':
+			return token{}, syntaxError(pos, "unterminated string")
+		case c == '\\':
+			if err := l.escape(&b, pos); err != nil {
+				return token{}, err
+			}
+		default:
+			r, size := utf8.DecodeRuneInString(l.src[l.off:])
+			b.WriteRune(r)
+			l.off += size
+			l.col++
+		}
+	}
+	return token{}, syntaxError(pos, "unterminated string")
+}
+
+func (l *lexer) escape(b *strings.Builder, pos Pos) error {
+	if l.off+1 >= len(l.src) {
+		return syntaxError(pos, "unterminated string")
+	}
+
+	c := l.src[l.off+1]
+	l.advance(2)
+	switch c {
+	case '"', '\\', '/':
+		b.WriteByte(c)
+	case 'b':
+		b.WriteByte('\b')
+	case 'f':
+		b.WriteByte('\f')
+	case 'n':
+		b.WriteByte('\n')
+	case 'r':
+		b.WriteByte('\r')
+	case 't':
+		b.WriteByte('\t')
+	case 'u':
+		const hexLen = 4
+		if l.off+hexLen > len(l.src) {
+			return syntaxError(pos, "invalid unicode escape")
+		}
+		var r rune
+		for _, h := range l.src[l.off : l.off+hexLen] {
+			d, ok := hexDigit(h)
+			if !ok {
+				return syntaxError(pos, "invalid unicode escape")
+			}
+			r = r<<4 | d
+		}
+		l.advance(hexLen)
+		b.WriteRune(r)
+	default:
+		return syntaxError(pos, "invalid escape \\%c", c)
+	}
+	return nil
+}
+
+// blockString reads a """ string. Common indentation and blank first and
+// last lines are removed as the spec describes.
+func (l *lexer) blockString(pos Pos) (token, error) {
+	l.advance(3)
+
+	var b strings.Builder
+	for l.off < len(l.src) {
+		switch {
+		case strings.HasPrefix(l.src[l.off:], `"""`):
+			l.advance(3)
+			return token{kind: tokenString, value: dedentBlock(b.String()), pos: pos}, nil
+		case strings.HasPrefix(l.src[l.off:], `\"""`):
+			b.WriteString(`"""`)
+			l.advance(4)
+		case l.src[l.off] == '\n':
+			b.WriteByte('\n')
+			l.off++
+			l.line++
+			l.col = 1
+		default:
+			r, size := utf8.DecodeRuneInString(l.src[l.off:])
+			b.WriteRune(r)
+			l.off += size
+			l.col++
+		}
+	}
+	return token{}, syntaxError(pos, "unterminated block string")
+}
+
+func dedentBlock(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// advance moves n bytes forward on the current line.
+func (l *lexer) advance(n int) {
+	l.off += n
+	l.col += n
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func hexDigit(r rune) (rune, bool) {
+	switch {
+	case r >= '0' && r <= '9':
+		return r - '0', true
+	case r >= 'a' && r <= 'f':
+		return r - 'a' + 10, true
+	case r >= 'A' && r <= 'F':
+		return r - 'A' + 10, true
+	}
+	return 0, false
+}
+
+func syntaxError(pos Pos, format string, args ...any) *Error {
+	return &Error{
+		Message:   "Syntax error: " + fmt.Sprintf(format, args...),
+		Locations: []Pos{pos},
+	}
+}
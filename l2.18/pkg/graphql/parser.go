@@ -0,0 +1,485 @@
+package graphql
+
+// _maxNesting bounds how deep selections and values may nest while parsing,
+// so a hostile request cannot exhaust the stack before limits are checked.
+const _maxNesting = 128
+
+type parser struct {
+	lex   *lexer
+	tok   token
+	depth int
+}
+
+// Parse parses an executable document: operations and fragments.
+func Parse(src string) (*Document, error) {
+	p := &parser{lex: newLexer(src)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc := &Document{Fragments: make(map[string]*Fragment)}
+	for p.tok.kind != tokenEOF {
+		if err := p.definition(doc); err != nil {
+			return nil, err
+		}
+	}
+	if len(doc.Operations) == 0 {
+		return nil, &Error{Message: "Request contains no operation"}
+	}
+	return doc, nil
+}
+
+func (p *parser) definition(doc *Document) error {
+	if p.peek(tokenPunct, "{") {
+		op := &Operation{Kind: OperationQuery, Pos: p.tok.pos}
+		selections, err := p.selectionSet()
+		if err != nil {
+			return err
+		}
+		op.Selections = selections
+		doc.Operations = append(doc.Operations, op)
+		return nil
+	}
+
+	if p.tok.kind != tokenName {
+		return p.unexpected()
+	}
+
+	switch p.tok.value {
+	case OperationQuery, OperationMutation:
+		op, err := p.operation()
+		if err != nil {
+			return err
+		}
+		doc.Operations = append(doc.Operations, op)
+	case "fragment":
+		fragment, err := p.fragment()
+		if err != nil {
+			return err
+		}
+		if _, exists := doc.Fragments[fragment.Name]; exists {
+			return &Error{
+				Message:   "There can be only one fragment named \"" + fragment.Name + "\"",
+				Locations: []Pos{fragment.Pos},
+			}
+		}
+		doc.Fragments[fragment.Name] = fragment
+	case "subscription":
+		return &Error{Message: "Subscriptions are not supported", Locations: []Pos{p.tok.pos}}
+	default:
+		return p.unexpected()
+	}
+	return nil
+}
+
+func (p *parser) operation() (*Operation, error) {
+	op := &Operation{Kind: p.tok.value, Pos: p.tok.pos}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if p.tok.kind == tokenName {
+		op.Name = p.tok.value
+		if err = p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek(tokenPunct, "(") {
+		if op.Variables, err = p.variableDefinitions(); err != nil {
+			return nil, err
+		}
+	}
+	if op.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if op.Selections, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+func (p *parser) variableDefinitions() ([]*VariableDefinition, error) {
+	if err := p.expect(tokenPunct, "("); err != nil {
+		return nil, err
+	}
+
+	var defs []*VariableDefinition
+	for !p.peek(tokenPunct, ")") {
+		def := &VariableDefinition{Pos: p.tok.pos}
+		if err := p.expect(tokenPunct, "$"); err != nil {
+			return nil, err
+		}
+
+		var err error
+		if def.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if err = p.expect(tokenPunct, ":"); err != nil {
+			return nil, err
+		}
+		if def.Type, err = p.typeRef(); err != nil {
+			return nil, err
+		}
+		if p.peek(tokenPunct, "=") {
+			if err = p.advance(); err != nil {
+				return nil, err
+			}
+			if def.Default, err = p.value(true); err != nil {
+				return nil, err
+			}
+		}
+		defs = append(defs, def)
+	}
+	return defs, p.advance()
+}
+
+func (p *parser) typeRef() (*TypeRef, error) {
+	var ref *TypeRef
+	if p.peek(tokenPunct, "[") {
+		if err := p.nest(); err != nil {
+			return nil, err
+		}
+		defer p.unnest()
+
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		elem, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect(tokenPunct, "]"); err != nil {
+			return nil, err
+		}
+		ref = &TypeRef{Elem: elem}
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		ref = &TypeRef{Name: name}
+	}
+
+	if p.peek(tokenPunct, "!") {
+		ref.NonNull = true
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	return ref, nil
+}
+
+func (p *parser) fragment() (*Fragment, error) {
+	fragment := &Fragment{Pos: p.tok.pos}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if fragment.Name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if fragment.Name == "on" {
+		return nil, syntaxError(fragment.Pos, "fragment cannot be named \"on\"")
+	}
+	if p.tok.kind != tokenName || p.tok.value != "on" {
+		return nil, p.unexpected()
+	}
+	if err = p.advance(); err != nil {
+		return nil, err
+	}
+	if fragment.TypeCondition, err = p.name(); err != nil {
+		return nil, err
+	}
+	if fragment.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if fragment.Selections, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return fragment, nil
+}
+
+func (p *parser) selectionSet() ([]Selection, error) {
+	if err := p.nest(); err != nil {
+		return nil, err
+	}
+	defer p.unnest()
+
+	if err := p.expect(tokenPunct, "{"); err != nil {
+		return nil, err
+	}
+
+	var selections []Selection
+	for !p.peek(tokenPunct, "}") {
+		selection, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, selection)
+	}
+	if len(selections) == 0 {
+		return nil, syntaxError(p.tok.pos, "expected a field, found \"}\"")
+	}
+	return selections, p.advance()
+}
+
+func (p *parser) selection() (Selection, error) {
+	if p.peek(tokenPunct, "...") {
+		return p.fragmentSelection()
+	}
+
+	field := &FieldNode{Pos: p.tok.pos}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek(tokenPunct, ":") {
+		if err = p.advance(); err != nil {
+			return nil, err
+		}
+		field.Alias = name
+		if name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	field.Name = name
+
+	if p.peek(tokenPunct, "(") {
+		if field.Arguments, err = p.arguments(false); err != nil {
+			return nil, err
+		}
+	}
+	if field.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if p.peek(tokenPunct, "{") {
+		if field.Selections, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return field, nil
+}
+
+func (p *parser) fragmentSelection() (Selection, error) {
+	pos := p.tok.pos
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.kind == tokenName && p.tok.value != "on" {
+		spread := &FragmentSpread{Name: p.tok.value, Pos: pos}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		var err error
+		if spread.Directives, err = p.directives(); err != nil {
+			return nil, err
+		}
+		return spread, nil
+	}
+
+	inline := &InlineFragment{Pos: pos}
+	var err error
+	if p.tok.kind == tokenName {
+		if err = p.advance(); err != nil {
+			return nil, err
+		}
+		if inline.TypeCondition, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if inline.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if inline.Selections, err = p.selectionSet(); err != nil {
+		return nil, err
+	}
+	return inline, nil
+}
+
+func (p *parser) arguments(constant bool) ([]*Argument, error) {
+	if err := p.expect(tokenPunct, "("); err != nil {
+		return nil, err
+	}
+
+	var args []*Argument
+	for !p.peek(tokenPunct, ")") {
+		arg := &Argument{Pos: p.tok.pos}
+
+		var err error
+		if arg.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+		for _, existing := range args {
+			if existing.Name == arg.Name {
+				return nil, &Error{
+					Message:   "There can be only one argument named \"" + arg.Name + "\"",
+					Locations: []Pos{arg.Pos},
+				}
+			}
+		}
+		if err = p.expect(tokenPunct, ":"); err != nil {
+			return nil, err
+		}
+		if arg.Value, err = p.value(constant); err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	if len(args) == 0 {
+		return nil, syntaxError(p.tok.pos, "expected an argument, found \")\"")
+	}
+	return args, p.advance()
+}
+
+func (p *parser) directives() ([]*Directive, error) {
+	var directives []*Directive
+	for p.peek(tokenPunct, "@") {
+		directive := &Directive{Pos: p.tok.pos}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		var err error
+		if directive.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if p.peek(tokenPunct, "(") {
+			if directive.Arguments, err = p.arguments(false); err != nil {
+				return nil, err
+			}
+		}
+		directives = append(directives, directive)
+	}
+	return directives, nil
+}
+
+// value parses a literal; constant values, such as variable defaults, may
+// not reference variables.
+func (p *parser) value(constant bool) (*Value, error) {
+	if err := p.nest(); err != nil {
+		return nil, err
+	}
+	defer p.unnest()
+
+	tok := p.tok
+	v := &Value{Raw: tok.value, Pos: tok.pos}
+
+	switch {
+	case tok.kind == tokenInt:
+		v.Kind = IntValue
+	case tok.kind == tokenFloat:
+		v.Kind = FloatValue
+	case tok.kind == tokenString:
+		v.Kind = StringValue
+	case tok.kind == tokenName && (tok.value == "true" || tok.value == "false"):
+		v.Kind = BooleanValue
+	case tok.kind == tokenName && tok.value == "null":
+		v.Kind = NullValue
+	case tok.kind == tokenName:
+		v.Kind = EnumValue
+	case tok.kind == tokenPunct && tok.value == "$" && !constant:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		return &Value{Kind: VariableValue, Raw: name, Pos: tok.pos}, nil
+	case tok.kind == tokenPunct && tok.value == "[":
+		return p.listValue(v, constant)
+	case tok.kind == tokenPunct && tok.value == "{":
+		return p.objectValue(v, constant)
+	default:
+		return nil, p.unexpected()
+	}
+	return v, p.advance()
+}
+
+func (p *parser) listValue(v *Value, constant bool) (*Value, error) {
+	v.Kind, v.Raw = ListValue, ""
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	v.List = []*Value{}
+	for !p.peek(tokenPunct, "]") {
+		item, err := p.value(constant)
+		if err != nil {
+			return nil, err
+		}
+		v.List = append(v.List, item)
+	}
+	return v, p.advance()
+}
+
+func (p *parser) objectValue(v *Value, constant bool) (*Value, error) {
+	v.Kind, v.Raw = ObjectValue, ""
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	for !p.peek(tokenPunct, "}") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err = p.expect(tokenPunct, ":"); err != nil {
+			return nil, err
+		}
+		item, err := p.value(constant)
+		if err != nil {
+			return nil, err
+		}
+		v.Fields = append(v.Fields, &ObjectField{Name: name, Value: item})
+	}
+	return v, p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.unexpected()
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+func (p *parser) peek(kind tokenKind, value string) bool {
+	return p.tok.kind == kind && p.tok.value == value
+}
+
+func (p *parser) expect(kind tokenKind, value string) error {
+	if !p.peek(kind, value) {
+		return syntaxError(p.tok.pos, "expected %q, found %s", value, p.tok)
+	}
+	return p.advance()
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	return syntaxError(p.tok.pos, "unexpected %s", p.tok)
+}
+
+func (p *parser) nest() error {
+	p.depth++
+	if p.depth > _maxNesting {
+		return syntaxError(p.tok.pos, "request is nested too deeply")
+	}
+	return nil
+}
+
+func (p *parser) unnest() {
+	p.depth--
+}
@@ -0,0 +1,508 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+type (
+	// Type is a *Scalar, *Object, *InputObject, *List or *NonNull.
+	Type interface {
+		String() string
+	}
+
+	// Scalar is a leaf type. Serialize turns a resolved Go value into its
+	// JSON form; ParseValue turns an input, as decoded from JSON with
+	// numbers kept as json.Number, into the Go value resolvers receive.
+	Scalar struct {
+		Name        string
+		Description string
+		Serialize   func(v any) (any, error)
+		ParseValue  func(v any) (any, error)
+	}
+
+	Object struct {
+		Name        string
+		Description string
+		Fields      []*Field
+
+		byName map[string]*Field
+	}
+
+	// Field is a field of an object. Without Resolve the value is read from
+	// the source: a map entry, or the exported struct field whose name
+	// matches ignoring case. Cost weighs the field in the complexity of a
+	// request and defaults to 1.
+	Field struct {
+		Name        string
+		Description string
+		Type        Type
+		Args        []*InputValue
+		Resolve     ResolveFunc
+		Cost        int
+	}
+
+	// InputValue is an argument or an input object field.
+	InputValue struct {
+		Name        string
+		Description string
+		Type        Type
+		Default     any
+	}
+
+	InputObject struct {
+		Name        string
+		Description string
+		Fields      []*InputValue
+	}
+
+	List struct {
+		Of Type
+	}
+
+	NonNull struct {
+		Of Type
+	}
+
+	ResolveFunc func(ctx context.Context, p ResolveParams) (any, error)
+
+	ResolveParams struct {
+		Source any
+		Args   map[string]any
+	}
+)
+
+var (
+	String = &Scalar{
+		Name:        "String",
+		Description: "UTF-8 text.",
+		Serialize:   serializeString,
+		ParseValue: func(v any) (any, error) {
+			s, ok := v.(string)
+			if !ok {
+				return nil, errExpected("a string", v)
+			}
+			return s, nil
+		},
+	}
+
+	Int = &Scalar{
+		Name:        "Int",
+		Description: "A signed 32-bit integer.",
+		Serialize: func(v any) (any, error) {
+			n, err := toInt64(v)
+			if err != nil {
+				return nil, err
+			}
+			if n < math.MinInt32 || n > math.MaxInt32 {
+				return nil, fmt.Errorf("Int cannot represent %d", n)
+			}
+			return n, nil
+		},
+		ParseValue: func(v any) (any, error) {
+			num, ok := v.(json.Number)
+			if !ok {
+				return nil, errExpected("an integer", v)
+			}
+			n, err := strconv.ParseInt(string(num), 10, 32)
+			if err != nil {
+				return nil, errExpected("a 32-bit integer", v)
+			}
+			return int(n), nil
+		},
+	}
+
+	Float = &Scalar{
+		Name:        "Float",
+		Description: "A double-precision number.",
+		Serialize: func(v any) (any, error) {
+			switch f := v.(type) {
+			case float64:
+				return f, nil
+			case float32:
+				return float64(f), nil
+			}
+			n, err := toInt64(v)
+			if err != nil {
+				return nil, err
+			}
+			return float64(n), nil
+		},
+		ParseValue: func(v any) (any, error) {
+			num, ok := v.(json.Number)
+			if !ok {
+				return nil, errExpected("a number", v)
+			}
+			f, err := num.Float64()
+			if err != nil || math.IsInf(f, 0) {
+				return nil, errExpected("a finite number", v)
+			}
+			return f, nil
+		},
+	}
+
+	Boolean = &Scalar{
+		Name:        "Boolean",
+		Description: "true or false.",
+		Serialize: func(v any) (any, error) {
+			b, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("Boolean cannot represent %T", v)
+			}
+			return b, nil
+		},
+		ParseValue: func(v any) (any, error) {
+			b, ok := v.(bool)
+			if !ok {
+				return nil, errExpected("a boolean", v)
+			}
+			return b, nil
+		},
+	}
+
+	// ID is serialized as a string and accepts strings and integers.
+	ID = &Scalar{
+		Name:        "ID",
+		Description: "A unique identifier, serialized as a string.",
+		Serialize:   serializeString,
+		ParseValue: func(v any) (any, error) {
+			switch id := v.(type) {
+			case string:
+				return id, nil
+			case json.Number:
+				if _, err := strconv.ParseInt(string(id), 10, 64); err != nil {
+					return nil, errExpected("an integer or string", v)
+				}
+				return string(id), nil
+			}
+			return nil, errExpected("an integer or string", v)
+		},
+	}
+
+	builtinScalars = []*Scalar{String, Int, Float, Boolean, ID}
+)
+
+func (s *Scalar) String() string      { return s.Name }
+func (o *Object) String() string      { return o.Name }
+func (i *InputObject) String() string { return i.Name }
+func (l *List) String() string        { return "[" + l.Of.String() + "]" }
+func (n *NonNull) String() string     { return n.Of.String() + "!" }
+
+func (o *Object) field(name string) *Field {
+	return o.byName[name]
+}
+
+func (f *Field) arg(name string) *InputValue {
+	for _, arg := range f.Args {
+		if arg.Name == name {
+			return arg
+		}
+	}
+	return nil
+}
+
+// coerceInput converts an input value, as decoded from JSON or built from a
+// literal, to the Go value for t: the scalar's parsed value, []any for
+// lists and map[string]any for input objects.
+func coerceInput(t Type, v any) (any, error) {
+	if nn, ok := t.(*NonNull); ok {
+		if v == nil {
+			return nil, fmt.Errorf("expected a non-null %s", nn.Of)
+		}
+		return coerceInput(nn.Of, v)
+	}
+	if v == nil {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *Scalar:
+		return t.ParseValue(v)
+	case *List:
+		items, ok := v.([]any)
+		if !ok {
+			// A single value is accepted where a list is expected.
+			item, err := coerceInput(t.Of, v)
+			if err != nil {
+				return nil, err
+			}
+			return []any{item}, nil
+		}
+		result := make([]any, len(items))
+		for i, item := range items {
+			coerced, err := coerceInput(t.Of, item)
+			if err != nil {
+				return nil, fmt.Errorf("at index %d: %w", i, err)
+			}
+			result[i] = coerced
+		}
+		return result, nil
+	case *InputObject:
+		fields, ok := v.(map[string]any)
+		if !ok {
+			return nil, errExpected("an object of type "+t.Name, v)
+		}
+		return coerceInputObject(t, fields)
+	}
+	return nil, fmt.Errorf("%s is not an input type", t)
+}
+
+func coerceInputObject(t *InputObject, fields map[string]any) (map[string]any, error) {
+	for name := range fields {
+		if inputField(t, name) == nil {
+			return nil, fmt.Errorf("field %q is not defined by type %s", name, t.Name)
+		}
+	}
+
+	result := make(map[string]any, len(t.Fields))
+	for _, field := range t.Fields {
+		value, provided := fields[field.Name]
+		if !provided {
+			if field.Default != nil {
+				result[field.Name] = field.Default
+				continue
+			}
+			if _, required := field.Type.(*NonNull); required {
+				return nil, fmt.Errorf("field %s.%s of type %s is required", t.Name, field.Name, field.Type)
+			}
+			continue
+		}
+
+		coerced, err := coerceInput(field.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		result[field.Name] = coerced
+	}
+	return result, nil
+}
+
+func inputField(t *InputObject, name string) *InputValue {
+	for _, field := range t.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+func serializeString(v any) (any, error) {
+	switch s := v.(type) {
+	case string:
+		return s, nil
+	case fmt.Stringer:
+		return s.String(), nil
+	}
+	if n, err := toInt64(v); err == nil {
+		return strconv.FormatInt(n, 10), nil
+	}
+	if n, ok := v.(uint64); ok {
+		return strconv.FormatUint(n, 10), nil
+	}
+	return nil, fmt.Errorf("cannot represent %T as a string", v)
+}
+
+func toInt64(v any) (int64, error) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), nil
+	case int8:
+		return int64(n), nil
+	case int16:
+		return int64(n), nil
+	case int32:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case uint8:
+		return int64(n), nil
+	case uint16:
+		return int64(n), nil
+	case uint32:
+		return int64(n), nil
+	case uint:
+		if uint64(n) <= math.MaxInt64 {
+			return int64(n), nil
+		}
+	case uint64:
+		if n <= math.MaxInt64 {
+			return int64(n), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot represent %T as an integer", v)
+}
+
+func errExpected(what string, v any) error {
+	if num, ok := v.(json.Number); ok {
+		return fmt.Errorf("expected %s, got %s", what, num)
+	}
+	return fmt.Errorf("expected %s, got %T", what, v)
+}
+
+// unwrap strips NonNull and List wrappers down to the named type.
+func unwrap(t Type) Type {
+	for {
+		switch w := t.(type) {
+		case *NonNull:
+			t = w.Of
+		case *List:
+			t = w.Of
+		default:
+			return t
+		}
+	}
+}
+
+func isInputType(t Type) bool {
+	switch unwrap(t).(type) {
+	case *Scalar, *InputObject:
+		return true
+	}
+	return false
+}
+
+func isOutputType(t Type) bool {
+	switch unwrap(t).(type) {
+	case *Scalar, *Object:
+		return true
+	}
+	return false
+}
+
+type (
+	// Schema is the entry point of execution. Query is required; Mutation
+	// may be nil.
+	Schema struct {
+		query    *Object
+		mutation *Object
+		types    map[string]Type
+	}
+
+	SchemaConfig struct {
+		Query    *Object
+		Mutation *Object
+	}
+)
+
+var errNoQuery = errors.New("graphql: schema needs a query type")
+
+// NewSchema checks the types reachable from the root objects and indexes
+// their fields. Types must not be changed afterwards.
+func NewSchema(cfg SchemaConfig) (*Schema, error) {
+	if cfg.Query == nil {
+		return nil, errNoQuery
+	}
+
+	s := &Schema{query: cfg.Query, mutation: cfg.Mutation, types: make(map[string]Type)}
+	for _, scalar := range builtinScalars {
+		s.types[scalar.Name] = scalar
+	}
+
+	roots := []*Object{cfg.Query}
+	if cfg.Mutation != nil {
+		roots = append(roots, cfg.Mutation)
+	}
+	for _, root := range roots {
+		if err := s.register(root); err != nil {
+			return nil, fmt.Errorf("graphql: %w", err)
+		}
+	}
+	return s, nil
+}
+
+func (s *Schema) register(t Type) error {
+	t = unwrap(t)
+	if t == nil {
+		return errors.New("field without a type")
+	}
+
+	name := t.String()
+	if existing, ok := s.types[name]; ok {
+		if existing != t {
+			return fmt.Errorf("two different types named %s", name)
+		}
+		return nil
+	}
+	if !validName(name) {
+		return fmt.Errorf("invalid type name %q", name)
+	}
+	s.types[name] = t
+
+	switch t := t.(type) {
+	case *Scalar:
+		if t.Serialize == nil || t.ParseValue == nil {
+			return fmt.Errorf("scalar %s needs Serialize and ParseValue", name)
+		}
+	case *Object:
+		return s.registerObject(t)
+	case *InputObject:
+		for _, field := range t.Fields {
+			if err := s.registerInput(name+"."+field.Name, field); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported type %T", t)
+	}
+	return nil
+}
+
+func (s *Schema) registerObject(o *Object) error {
+	if len(o.Fields) == 0 {
+		return fmt.Errorf("object %s has no fields", o.Name)
+	}
+
+	o.byName = make(map[string]*Field, len(o.Fields))
+	for _, field := range o.Fields {
+		path := o.Name + "." + field.Name
+		if !validName(field.Name) || field.Name == _typenameField {
+			return fmt.Errorf("invalid field name %s", path)
+		}
+		if _, exists := o.byName[field.Name]; exists {
+			return fmt.Errorf("duplicate field %s", path)
+		}
+		o.byName[field.Name] = field
+
+		if err := s.register(field.Type); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if !isOutputType(field.Type) {
+			return fmt.Errorf("field %s has input type %s", path, field.Type)
+		}
+		for _, arg := range field.Args {
+			if err := s.registerInput(path+"("+arg.Name+")", arg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) registerInput(path string, value *InputValue) error {
+	if !validName(value.Name) {
+		return fmt.Errorf("invalid name %s", path)
+	}
+	if err := s.register(value.Type); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if !isInputType(value.Type) {
+		return fmt.Errorf("%s has output type %s", path, value.Type)
+	}
+	return nil
+}
+
+func validName(name string) bool {
+	if name == "" || (name[0] != '_' && !isLetter(name[0])) {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		if c := name[i]; c != '_' && !isLetter(c) && !isDigit(c) {
+			return false
+		}
+	}
+	return len(name) < 2 || name[:2] != "__"
+}
@@ -0,0 +1,120 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// SDL prints the schema in the GraphQL schema definition language, for
+// client code generators and editors. Built-in scalars are left out.
+func (s *Schema) SDL() string {
+	var b strings.Builder
+	if s.query.Name != "Query" || (s.mutation != nil && s.mutation.Name != "Mutation") {
+		b.WriteString("schema {\n  query: " + s.query.Name + "\n")
+		if s.mutation != nil {
+			b.WriteString("  mutation: " + s.mutation.Name + "\n")
+		}
+		b.WriteString("}\n\n")
+	}
+
+	names := make([]string, 0, len(s.types))
+	for name := range s.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		switch t := s.types[name].(type) {
+		case *Scalar:
+			if slices.Contains(builtinScalars, t) {
+				continue
+			}
+			writeDescription(&b, t.Description, "")
+			b.WriteString("scalar " + t.Name + "\n\n")
+		case *InputObject:
+			writeDescription(&b, t.Description, "")
+			b.WriteString("input " + t.Name + " {\n")
+			for _, field := range t.Fields {
+				writeDescription(&b, field.Description, "  ")
+				b.WriteString("  " + inputValueSDL(field) + "\n")
+			}
+			b.WriteString("}\n\n")
+		case *Object:
+			writeDescription(&b, t.Description, "")
+			b.WriteString("type " + t.Name + " {\n")
+			for _, field := range t.Fields {
+				writeDescription(&b, field.Description, "  ")
+				b.WriteString("  " + field.Name)
+				if len(field.Args) > 0 {
+					args := make([]string, len(field.Args))
+					for i, arg := range field.Args {
+						args[i] = inputValueSDL(arg)
+					}
+					b.WriteString("(" + strings.Join(args, ", ") + ")")
+				}
+				b.WriteString(": " + field.Type.String() + "\n")
+			}
+			b.WriteString("}\n\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func writeDescription(b *strings.Builder, description, indent string) {
+	if description == "" {
+		return
+	}
+	if !strings.Contains(description, "\n") {
+		quoted, _ := json.Marshal(description)
+		b.WriteString(indent + string(quoted) + "\n")
+		return
+	}
+
+	b.WriteString(indent + `"""` + "\n")
+	for line := range strings.SplitSeq(description, "\n") {
+		b.WriteString(indent + strings.ReplaceAll(line, `"""`, `\"""`) + "\n")
+	}
+	b.WriteString(indent + `"""` + "\n")
+}
+
+func inputValueSDL(v *InputValue) string {
+	s := v.Name + ": " + v.Type.String()
+	if v.Default != nil {
+		s += " = " + literalSDL(v.Default)
+	}
+	return s
+}
+
+// literalSDL prints a default value, which is in the coerced Go form.
+func literalSDL(v any) string {
+	switch v := v.(type) {
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = literalSDL(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fields := make([]string, len(keys))
+		for i, key := range keys {
+			fields[i] = key + ": " + literalSDL(v[key])
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	case fmt.Stringer:
+		return literalSDL(v.String())
+	}
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return "null"
+	}
+	return string(encoded)
+}
@@ -0,0 +1,569 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+type (
+	validator struct {
+		schema     *Schema
+		doc        *Document
+		op         *Operation
+		vars       map[string]any
+		declared   map[string]bool
+		opts       Options
+		listFactor int
+
+		// args holds the coerced arguments of every field, for execution.
+		args      map[*FieldNode]map[string]any
+		fragments map[string]*fragmentCost
+	}
+
+	fragmentCost struct {
+		done       bool
+		complexity int
+		depth      int
+	}
+)
+
+func newValidator(s *Schema, doc *Document, op *Operation, vars map[string]any, opts Options) *validator {
+	declared := make(map[string]bool, len(op.Variables))
+	for _, def := range op.Variables {
+		declared[def.Name] = true
+	}
+
+	listFactor := opts.ListFactor
+	if listFactor <= 0 {
+		listFactor = _defaultListFactor
+	}
+
+	return &validator{
+		schema:     s,
+		doc:        doc,
+		op:         op,
+		vars:       vars,
+		declared:   declared,
+		opts:       opts,
+		listFactor: listFactor,
+		args:       make(map[*FieldNode]map[string]any),
+		fragments:  make(map[string]*fragmentCost),
+	}
+}
+
+// validate checks the selected operation against the schema, coerces field
+// arguments and enforces the depth and complexity limits.
+func (v *validator) validate(root *Object) *Error {
+	if len(v.op.Directives) > 0 {
+		d := v.op.Directives[0]
+		return &Error{Message: "Directive \"@" + d.Name + "\" may not be used on operations", Locations: []Pos{d.Pos}}
+	}
+
+	complexity, depth, err := v.selectionSet(root, v.op.Selections)
+	if err != nil {
+		return err
+	}
+	if v.opts.MaxDepth > 0 && depth > v.opts.MaxDepth {
+		return &Error{
+			Message:    fmt.Sprintf("Query depth %d exceeds the limit of %d", depth, v.opts.MaxDepth),
+			Extensions: map[string]any{"depth": depth, "maxDepth": v.opts.MaxDepth},
+		}
+	}
+	if v.opts.MaxComplexity > 0 && complexity > v.opts.MaxComplexity {
+		return &Error{
+			Message:    fmt.Sprintf("Query complexity %d exceeds the limit of %d", complexity, v.opts.MaxComplexity),
+			Extensions: map[string]any{"complexity": complexity, "maxComplexity": v.opts.MaxComplexity},
+		}
+	}
+
+	return v.conflicts(root, v.op.Selections)
+}
+
+// selectionSet validates selections on t and returns their complexity and
+// depth. Fragments are measured once, so a request that spreads the same
+// fragment many times is cheap to check and priced in full.
+func (v *validator) selectionSet(t *Object, selections []Selection) (complexity, depth int, err *Error) {
+	for _, selection := range selections {
+		var c, d int
+		switch s := selection.(type) {
+		case *FieldNode:
+			c, d, err = v.field(t, s)
+		case *InlineFragment:
+			if err = v.directives(s.Directives); err != nil {
+				return 0, 0, err
+			}
+			if err = v.typeCondition(t, s.TypeCondition, s.Pos); err != nil {
+				return 0, 0, err
+			}
+			c, d, err = v.selectionSet(t, s.Selections)
+		case *FragmentSpread:
+			if err = v.directives(s.Directives); err != nil {
+				return 0, 0, err
+			}
+			c, d, err = v.spread(t, s)
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		complexity = addCost(complexity, c)
+		depth = max(depth, d)
+	}
+	return complexity, depth, nil
+}
+
+func (v *validator) field(t *Object, node *FieldNode) (complexity, depth int, err *Error) {
+	if err = v.directives(node.Directives); err != nil {
+		return 0, 0, err
+	}
+
+	if node.Name == _typenameField {
+		if len(node.Arguments) > 0 || len(node.Selections) > 0 {
+			return 0, 0, &Error{
+				Message:   "Field \"__typename\" takes no arguments or selections",
+				Locations: []Pos{node.Pos},
+			}
+		}
+		return 0, 1, nil
+	}
+
+	def := t.field(node.Name)
+	if def == nil {
+		return 0, 0, &Error{
+			Message:   fmt.Sprintf("Cannot query field %q on type %q", node.Name, t.Name),
+			Locations: []Pos{node.Pos},
+		}
+	}
+	if v.args[node], err = v.arguments(def, node); err != nil {
+		return 0, 0, err
+	}
+
+	cost := def.Cost
+	if cost == 0 {
+		cost = 1
+	}
+
+	obj, isObject := unwrap(def.Type).(*Object)
+	switch {
+	case !isObject && len(node.Selections) > 0:
+		return 0, 0, &Error{
+			Message: fmt.Sprintf(
+				"Field %q must not have a selection since type %q has no subfields", node.Name, def.Type,
+			),
+			Locations: []Pos{node.Pos},
+		}
+	case !isObject:
+		return cost, 1, nil
+	case len(node.Selections) == 0:
+		return 0, 0, &Error{
+			Message:   fmt.Sprintf("Field %q of type %q must have a selection of subfields", node.Name, def.Type),
+			Locations: []Pos{node.Pos},
+		}
+	}
+
+	complexity, depth, err = v.selectionSet(obj, node.Selections)
+	if err != nil {
+		return 0, 0, err
+	}
+	if isList(def.Type) {
+		complexity = mulCost(complexity, v.listFactor)
+	}
+	return addCost(cost, complexity), depth + 1, nil
+}
+
+func (v *validator) spread(t *Object, s *FragmentSpread) (complexity, depth int, err *Error) {
+	fragment := v.doc.Fragments[s.Name]
+	if fragment == nil {
+		return 0, 0, &Error{Message: fmt.Sprintf("Unknown fragment %q", s.Name), Locations: []Pos{s.Pos}}
+	}
+
+	if cost, seen := v.fragments[s.Name]; seen {
+		if !cost.done {
+			return 0, 0, &Error{
+				Message:   fmt.Sprintf("Cannot spread fragment %q within itself", s.Name),
+				Locations: []Pos{s.Pos},
+			}
+		}
+		return cost.complexity, cost.depth, v.typeCondition(t, fragment.TypeCondition, s.Pos)
+	}
+
+	if len(fragment.Directives) > 0 {
+		d := fragment.Directives[0]
+		return 0, 0, &Error{
+			Message:   "Directive \"@" + d.Name + "\" may not be used on fragment definitions",
+			Locations: []Pos{d.Pos},
+		}
+	}
+	if err = v.typeCondition(t, fragment.TypeCondition, s.Pos); err != nil {
+		return 0, 0, err
+	}
+
+	cost := &fragmentCost{}
+	v.fragments[s.Name] = cost
+	if cost.complexity, cost.depth, err = v.selectionSet(t, fragment.Selections); err != nil {
+		return 0, 0, err
+	}
+	cost.done = true
+	return cost.complexity, cost.depth, nil
+}
+
+// typeCondition checks a fragment's type. The schema has no interfaces or
+// unions, so the condition must name the type itself.
+func (v *validator) typeCondition(t *Object, condition string, pos Pos) *Error {
+	if condition == "" || condition == t.Name {
+		return nil
+	}
+	if _, known := v.schema.types[condition]; !known {
+		return &Error{Message: fmt.Sprintf("Unknown type %q", condition), Locations: []Pos{pos}}
+	}
+	return &Error{
+		Message: fmt.Sprintf(
+			"Fragment cannot be spread here as objects of type %q can never be of type %q", t.Name, condition,
+		),
+		Locations: []Pos{pos},
+	}
+}
+
+func (v *validator) directives(directives []*Directive) *Error {
+	for _, d := range directives {
+		if d.Name != "skip" && d.Name != "include" {
+			return &Error{Message: "Unknown directive \"@" + d.Name + "\"", Locations: []Pos{d.Pos}}
+		}
+		if len(d.Arguments) != 1 || d.Arguments[0].Name != "if" {
+			return &Error{
+				Message:   "Directive \"@" + d.Name + "\" takes a single argument \"if\" of type \"Boolean!\"",
+				Locations: []Pos{d.Pos},
+			}
+		}
+		if _, err := v.literal(d.Arguments[0].Value); err != nil {
+			return err
+		}
+		if _, err := directiveIf(d, v.vars); err != nil {
+			return &Error{Message: "Directive \"@" + d.Name + "\": " + err.Error(), Locations: []Pos{d.Pos}}
+		}
+	}
+	return nil
+}
+
+func (v *validator) arguments(def *Field, node *FieldNode) (map[string]any, *Error) {
+	for _, arg := range node.Arguments {
+		if def.arg(arg.Name) == nil {
+			return nil, &Error{
+				Message:   fmt.Sprintf("Unknown argument %q on field %q", arg.Name, def.Name),
+				Locations: []Pos{arg.Pos},
+			}
+		}
+	}
+
+	args := make(map[string]any, len(def.Args))
+	for _, argDef := range def.Args {
+		var (
+			raw      any
+			provided bool
+			pos      = node.Pos
+		)
+		for _, arg := range node.Arguments {
+			if arg.Name != argDef.Name {
+				continue
+			}
+			pos = arg.Pos
+			if arg.Value.Kind == VariableValue {
+				if !v.declared[arg.Value.Raw] {
+					return nil, undefinedVariable(arg.Value)
+				}
+				raw, provided = v.vars[arg.Value.Raw]
+				break
+			}
+
+			var err *Error
+			if raw, err = v.literal(arg.Value); err != nil {
+				return nil, err
+			}
+			provided = true
+		}
+
+		if !provided {
+			if argDef.Default != nil {
+				args[argDef.Name] = argDef.Default
+				continue
+			}
+			if _, required := argDef.Type.(*NonNull); required {
+				return nil, &Error{
+					Message:   fmt.Sprintf("Argument %q of type %q is required", argDef.Name, argDef.Type),
+					Locations: []Pos{pos},
+				}
+			}
+			continue
+		}
+
+		value, err := coerceInput(argDef.Type, raw)
+		if err != nil {
+			return nil, &Error{
+				Message:   fmt.Sprintf("Argument %q has an invalid value: %v", argDef.Name, err),
+				Locations: []Pos{pos},
+			}
+		}
+		args[argDef.Name] = value
+	}
+	return args, nil
+}
+
+// literal converts a literal to the form JSON variables have, substituting
+// variables. Omitted variables disappear from lists and objects.
+func (v *validator) literal(value *Value) (any, *Error) {
+	return literalValue(value, v.vars, v.declared)
+}
+
+func literalValue(value *Value, vars map[string]any, declared map[string]bool) (any, *Error) {
+	switch value.Kind {
+	case VariableValue:
+		if !declared[value.Raw] {
+			return nil, undefinedVariable(value)
+		}
+		return vars[value.Raw], nil
+	case IntValue, FloatValue:
+		return json.Number(value.Raw), nil
+	case StringValue:
+		return value.Raw, nil
+	case BooleanValue:
+		return value.Raw == "true", nil
+	case NullValue:
+		return nil, nil
+	case ListValue:
+		list := make([]any, 0, len(value.List))
+		for _, item := range value.List {
+			if item.Kind == VariableValue && declared[item.Raw] {
+				if _, provided := vars[item.Raw]; !provided {
+					continue
+				}
+			}
+			converted, err := literalValue(item, vars, declared)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, converted)
+		}
+		return list, nil
+	case ObjectValue:
+		fields := make(map[string]any, len(value.Fields))
+		for _, field := range value.Fields {
+			if _, duplicate := fields[field.Name]; duplicate {
+				return nil, &Error{
+					Message:   fmt.Sprintf("There can be only one input field named %q", field.Name),
+					Locations: []Pos{field.Value.Pos},
+				}
+			}
+			if field.Value.Kind == VariableValue && declared[field.Value.Raw] {
+				if _, provided := vars[field.Value.Raw]; !provided {
+					continue
+				}
+			}
+			converted, err := literalValue(field.Value, vars, declared)
+			if err != nil {
+				return nil, err
+			}
+			fields[field.Name] = converted
+		}
+		return fields, nil
+	}
+	return nil, &Error{Message: fmt.Sprintf("Unexpected enum value %s", value.Raw), Locations: []Pos{value.Pos}}
+}
+
+func undefinedVariable(value *Value) *Error {
+	return &Error{
+		Message:   fmt.Sprintf("Variable \"$%s\" is not defined", value.Raw),
+		Locations: []Pos{value.Pos},
+	}
+}
+
+// coerceVariables checks the variables of the operation and returns them in
+// JSON form, with defaults applied. Omitted variables without a default are
+// left out, so arguments using them fall back to their own defaults.
+func (s *Schema) coerceVariables(op *Operation, values map[string]any) (map[string]any, *Error) {
+	vars := make(map[string]any, len(op.Variables))
+	for _, def := range op.Variables {
+		if _, duplicate := vars[def.Name]; duplicate {
+			return nil, &Error{
+				Message:   fmt.Sprintf("There can be only one variable named \"$%s\"", def.Name),
+				Locations: []Pos{def.Pos},
+			}
+		}
+
+		t, err := s.typeOf(def.Type)
+		if err != nil {
+			err.Locations = []Pos{def.Pos}
+			return nil, err
+		}
+		if !isInputType(t) {
+			return nil, &Error{
+				Message:   fmt.Sprintf("Variable \"$%s\" cannot be of non-input type %q", def.Name, def.Type),
+				Locations: []Pos{def.Pos},
+			}
+		}
+
+		value, provided := values[def.Name]
+		if !provided && def.Default != nil {
+			if value, err = literalValue(def.Default, nil, nil); err != nil {
+				return nil, err
+			}
+			provided = true
+		}
+		if !provided {
+			if _, required := t.(*NonNull); required {
+				return nil, &Error{
+					Message:   fmt.Sprintf("Variable \"$%s\" of required type %q was not provided", def.Name, def.Type),
+					Locations: []Pos{def.Pos},
+				}
+			}
+			continue
+		}
+
+		value = normalizeNumbers(value)
+		if _, err := coerceInput(t, value); err != nil {
+			return nil, &Error{
+				Message:   fmt.Sprintf("Variable \"$%s\" got an invalid value: %v", def.Name, err),
+				Locations: []Pos{def.Pos},
+			}
+		}
+		vars[def.Name] = value
+	}
+	return vars, nil
+}
+
+func (s *Schema) typeOf(ref *TypeRef) (Type, *Error) {
+	var t Type
+	if ref.Elem != nil {
+		elem, err := s.typeOf(ref.Elem)
+		if err != nil {
+			return nil, err
+		}
+		t = &List{Of: elem}
+	} else {
+		named, ok := s.types[ref.Name]
+		if !ok {
+			return nil, &Error{Message: fmt.Sprintf("Unknown type %q", ref.Name)}
+		}
+		t = named
+	}
+
+	if ref.NonNull {
+		t = &NonNull{Of: t}
+	}
+	return t, nil
+}
+
+// normalizeNumbers turns Go numbers in decoded variables into json.Number,
+// the form scalars parse.
+func normalizeNumbers(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		normalized := make(map[string]any, len(v))
+		for key, item := range v {
+			normalized[key] = normalizeNumbers(item)
+		}
+		return normalized
+	case []any:
+		normalized := make([]any, len(v))
+		for i, item := range v {
+			normalized[i] = normalizeNumbers(item)
+		}
+		return normalized
+	case float64:
+		return json.Number(strconv.FormatFloat(v, 'g', -1, 64))
+	case float32:
+		return json.Number(strconv.FormatFloat(float64(v), 'g', -1, 32))
+	case uint64:
+		return json.Number(strconv.FormatUint(v, 10))
+	}
+	if n, err := toInt64(v); err == nil {
+		return json.Number(strconv.FormatInt(n, 10))
+	}
+	return v
+}
+
+// conflicts rejects selections that put two different fields, or one field
+// with different arguments, under the same response key.
+func (v *validator) conflicts(t *Object, selections []Selection) *Error {
+	for _, group := range collectFields(v.doc, v.vars, selections, nil, make(map[string]bool), true) {
+		first := group.nodes[0]
+		for _, node := range group.nodes[1:] {
+			if node.Name != first.Name || !reflect.DeepEqual(v.args[node], v.args[first]) {
+				return &Error{
+					Message:   fmt.Sprintf("Fields %q conflict because they differ in name or arguments", group.key),
+					Locations: []Pos{first.Pos, node.Pos},
+				}
+			}
+		}
+		if first.Name == _typenameField {
+			continue
+		}
+
+		obj, isObject := unwrap(t.field(first.Name).Type).(*Object)
+		if !isObject {
+			continue
+		}
+		var merged []Selection
+		for _, node := range group.nodes {
+			merged = append(merged, node.Selections...)
+		}
+		if err := v.conflicts(obj, merged); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// included evaluates @skip and @include, which validation has checked.
+func included(directives []*Directive, vars map[string]any) bool {
+	for _, d := range directives {
+		value, _ := directiveIf(d, vars)
+		if (d.Name == "skip" && value) || (d.Name == "include" && !value) {
+			return false
+		}
+	}
+	return true
+}
+
+func directiveIf(d *Directive, vars map[string]any) (bool, error) {
+	arg := d.Arguments[0].Value
+	var raw any
+	switch arg.Kind {
+	case VariableValue:
+		raw = vars[arg.Raw]
+	case BooleanValue:
+		raw = arg.Raw == "true"
+	default:
+		return false, errExpected("a boolean", arg.Raw)
+	}
+
+	value, err := coerceInput(&NonNull{Of: Boolean}, raw)
+	if err != nil {
+		return false, err
+	}
+	return value.(bool), nil
+}
+
+func isList(t Type) bool {
+	if nn, ok := t.(*NonNull); ok {
+		t = nn.Of
+	}
+	_, ok := t.(*List)
+	return ok
+}
+
+// addCost and mulCost saturate instead of overflowing on absurd requests.
+func addCost(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
+}
+
+func mulCost(a, factor int) int {
+	if a > 0 && factor > math.MaxInt/a {
+		return math.MaxInt
+	}
+	return a * factor
+}
@@ -0,0 +1,200 @@
+// Package holiday loads public holiday calendars: which dates are days off in
+// a country and which weekend dates are worked instead of a transferred day
+// off.
+package holiday
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+//go:embed data/*.json
+var bundledFS embed.FS
+
+const _countryCodeLen = 2
+
+var (
+	ErrUnknownCountry = errors.New("holiday: unknown country")
+	ErrMalformed      = errors.New("holiday: malformed calendar file")
+)
+
+type (
+	Holiday struct {
+		Date string `json:"date"`
+		Name string `json:"name"`
+	}
+
+	// Calendar is one country's data file. Dates are YYYY-MM-DD in the
+	// local calendar of the country. A calendar covers the years it lists
+	// any holiday in; other years are unknown rather than holiday-free.
+	Calendar struct {
+		Country     string    `json:"country"`
+		Name        string    `json:"name"`
+		Holidays    []Holiday `json:"holidays"`
+		WorkingDays []string  `json:"working_days"`
+
+		byDate  map[string]Holiday
+		working map[string]struct{}
+		years   []int
+	}
+
+	// Registry holds the calendars by ISO 3166-1 alpha-2 country code.
+	Registry struct {
+		calendars map[string]*Calendar
+	}
+)
+
+// Load reads the bundled calendars and then every *.json file in dir, which
+// replace bundled calendars of the same country. An empty dir loads only the
+// bundled data.
+func Load(dir string) (*Registry, error) {
+	r := &Registry{calendars: make(map[string]*Calendar)}
+
+	if err := r.loadFS(bundledFS, "data"); err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		return r, nil
+	}
+
+	if err := r.loadFS(os.DirFS(dir), "."); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Registry) loadFS(fsys fs.FS, dir string) error {
+	names, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("holiday: list %s: %w", dir, err)
+	}
+
+	for _, name := range names {
+		data, readErr := fs.ReadFile(fsys, name)
+		if readErr != nil {
+			return fmt.Errorf("holiday: read %s: %w", name, readErr)
+		}
+
+		cal, parseErr := Parse(data)
+		if parseErr != nil {
+			return fmt.Errorf("%s: %w", name, parseErr)
+		}
+		r.calendars[cal.Country] = cal
+	}
+	return nil
+}
+
+// Parse decodes and indexes one calendar file.
+func Parse(data []byte) (*Calendar, error) {
+	var cal Calendar
+	if err := json.Unmarshal(data, &cal); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+
+	cal.Country = strings.ToUpper(strings.TrimSpace(cal.Country))
+	if len(cal.Country) != _countryCodeLen {
+		return nil, fmt.Errorf("%w: country %q", ErrMalformed, cal.Country)
+	}
+
+	cal.byDate = make(map[string]Holiday, len(cal.Holidays))
+	for _, h := range cal.Holidays {
+		date, err := time.Parse(time.DateOnly, h.Date)
+		if err != nil {
+			return nil, fmt.Errorf("%w: holiday date %q", ErrMalformed, h.Date)
+		}
+		cal.byDate[h.Date] = h
+		cal.years = append(cal.years, date.Year())
+	}
+	slices.Sort(cal.years)
+	cal.years = slices.Compact(cal.years)
+
+	cal.working = make(map[string]struct{}, len(cal.WorkingDays))
+	for _, date := range cal.WorkingDays {
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return nil, fmt.Errorf("%w: working day %q", ErrMalformed, date)
+		}
+		cal.working[date] = struct{}{}
+	}
+
+	slices.SortFunc(cal.Holidays, func(a, b Holiday) int {
+		return strings.Compare(a.Date, b.Date)
+	})
+
+	return &cal, nil
+}
+
+func (r *Registry) Calendar(country string) (*Calendar, error) {
+	cal, ok := r.calendars[strings.ToUpper(country)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCountry, country)
+	}
+	return cal, nil
+}
+
+// Countries returns the codes of the loaded calendars in order.
+func (r *Registry) Countries() []string {
+	countries := make([]string, 0, len(r.calendars))
+	for country := range r.calendars {
+		countries = append(countries, country)
+	}
+	slices.Sort(countries)
+	return countries
+}
+
+// Years returns the years the calendar has data for, in order.
+func (c *Calendar) Years() []int {
+	return slices.Clone(c.years)
+}
+
+func (c *Calendar) Covers(year int) bool {
+	_, found := slices.BinarySearch(c.years, year)
+	return found
+}
+
+// Uncovered returns the years from the calendar day of from through the one
+// of to that the calendar has no data for.
+func (c *Calendar) Uncovered(from, to time.Time) []int {
+	var years []int
+	for year := from.Year(); year <= to.Year(); year++ {
+		if !c.Covers(year) {
+			years = append(years, year)
+		}
+	}
+	return years
+}
+
+// Holiday returns the holiday on the calendar day of date in its own
+// location.
+func (c *Calendar) Holiday(date time.Time) (Holiday, bool) {
+	h, ok := c.byDate[date.Format(time.DateOnly)]
+	return h, ok
+}
+
+// IsTransferredWorkingDay reports whether date is a weekend day that is
+// worked in place of a day off moved elsewhere.
+func (c *Calendar) IsTransferredWorkingDay(date time.Time) bool {
+	_, ok := c.working[date.Format(time.DateOnly)]
+	return ok
+}
+
+// Between returns the holidays from the calendar day of from through the
+// one of to, inclusive.
+func (c *Calendar) Between(from, to time.Time) []Holiday {
+	first, last := from.Format(time.DateOnly), to.Format(time.DateOnly)
+
+	var result []Holiday
+	for _, h := range c.Holidays {
+		if h.Date >= first && h.Date <= last {
+			result = append(result, h)
+		}
+	}
+	return result
+}
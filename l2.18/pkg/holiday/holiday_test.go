@@ -0,0 +1,107 @@
+package holiday_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"calendar-wbf/pkg/holiday"
+)
+
+func TestLoad_Bundled(t *testing.T) {
+	t.Parallel()
+
+	registry, err := holiday.Load("")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := registry.Countries(); !slices.Contains(got, "RU") || !slices.Contains(got, "US") {
+		t.Errorf("Countries() = %v, want RU and US", got)
+	}
+
+	ru, err := registry.Calendar("ru")
+	if err != nil {
+		t.Fatalf("Calendar(ru) error = %v", err)
+	}
+
+	msk := time.FixedZone("MSK", 3*60*60)
+	if h, ok := ru.Holiday(time.Date(2026, time.June, 12, 9, 0, 0, 0, msk)); !ok || h.Name != "День России" {
+		t.Errorf("Holiday(2026-06-12) = %+v, %v", h, ok)
+	}
+	if _, ok := ru.Holiday(time.Date(2026, time.June, 11, 9, 0, 0, 0, msk)); ok {
+		t.Error("2026-06-11 reported as a holiday")
+	}
+
+	may := ru.Between(time.Date(2026, time.May, 1, 0, 0, 0, 0, msk), time.Date(2026, time.May, 31, 0, 0, 0, 0, msk))
+	if len(may) != 3 || may[0].Date != "2026-05-01" || may[2].Date != "2026-05-11" {
+		t.Errorf("Between(May) = %+v", may)
+	}
+
+	for _, country := range []string{"RU", "US"} {
+		cal, _ := registry.Calendar(country)
+		if years := cal.Years(); !slices.Contains(years, 2026) || !slices.Contains(years, 2027) {
+			t.Errorf("%s years = %v, want 2026 and 2027", country, years)
+		}
+	}
+
+	if _, err = registry.Calendar("XX"); !errors.Is(err, holiday.ErrUnknownCountry) {
+		t.Errorf("Calendar(XX) error = %v, want ErrUnknownCountry", err)
+	}
+}
+
+func TestLoad_DirOverridesBundled(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	data := `{"country":"ru","name":"Россия","holidays":[{"date":"2027-01-01","name":"Новый год"}],` +
+		`"working_days":["2027-01-09"]}`
+	if err := os.WriteFile(filepath.Join(dir, "ru.json"), []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	registry, err := holiday.Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	ru, _ := registry.Calendar("RU")
+
+	if _, ok := ru.Holiday(time.Date(2026, time.June, 12, 0, 0, 0, 0, time.UTC)); ok {
+		t.Error("bundled RU calendar not replaced")
+	}
+	if !ru.IsTransferredWorkingDay(time.Date(2027, time.January, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Error("2027-01-09 not a transferred working day")
+	}
+
+	from := time.Date(2026, time.December, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2028, time.January, 31, 0, 0, 0, 0, time.UTC)
+	if got := ru.Uncovered(from, to); !slices.Equal(got, []int{2026, 2028}) {
+		t.Errorf("Uncovered(2026-12-01, 2028-01-31) = %v, want [2026 2028]", got)
+	}
+}
+
+func TestParse_Malformed(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc string
+		data string
+	}{
+		{"NotJSON", `holidays`},
+		{"Country", `{"country":"Russia"}`},
+		{"HolidayDate", `{"country":"RU","holidays":[{"date":"12.06.2026","name":"День России"}]}`},
+		{"WorkingDay", `{"country":"RU","working_days":["2026-13-01"]}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := holiday.Parse([]byte(tc.data)); !errors.Is(err, holiday.ErrMalformed) {
+				t.Errorf("Parse() error = %v, want ErrMalformed", err)
+			}
+		})
+	}
+}
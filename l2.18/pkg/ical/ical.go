@@ -0,0 +1,472 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	_maxLineOctets = 75
+	_dateLayout    = "20060102"
+	_utcLayout     = "20060102T150405Z"
+	_localLayout   = "20060102T150405"
+	_hoursPerDay   = 24
+	_daysPerWeek   = 7
+)
+
+var (
+	ErrNoEvent         = errors.New("ical: no VEVENT in calendar object")
+	ErrMalformed       = errors.New("ical: malformed calendar object")
+	ErrUnknownTimezone = errors.New("ical: unknown TZID")
+)
+
+type (
+	// Event is the subset of an iCalendar (RFC 5545) VEVENT exchanged with
+	// calendar clients; recurrence and alarms are not modeled. AllDay events
+	// start at midnight of Start's location and are written with DATE values.
+	Event struct {
+		UID          string
+		Start        time.Time
+		End          time.Time
+		AllDay       bool
+		Summary      string
+		Description  string
+		Categories   []string
+		Color        string
+		Attendees    []Attendee
+		Created      time.Time
+		LastModified time.Time
+	}
+
+	// Attendee is an ATTENDEE with a mailto: address. Status is its PARTSTAT,
+	// e.g. ACCEPTED, and may be empty.
+	Attendee struct {
+		Email  string
+		Name   string
+		Status string
+	}
+
+	property struct {
+		name   string
+		params map[string]string
+		value  string
+	}
+)
+
+// Encode writes events as one VCALENDAR object.
+func Encode(w io.Writer, prodID string, events ...Event) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", prodID)
+	line("CALSCALE", "GREGORIAN")
+
+	stamp := time.Now().UTC().Format(_utcLayout)
+	for _, e := range events {
+		line("BEGIN", "VEVENT")
+		line("UID", escapeText(e.UID))
+		line("DTSTAMP", stamp)
+
+		if e.AllDay {
+			line("DTSTART;VALUE=DATE", e.Start.Format(_dateLayout))
+			line("DTEND;VALUE=DATE", e.End.Format(_dateLayout))
+		} else {
+			line("DTSTART", e.Start.UTC().Format(_utcLayout))
+			line("DTEND", e.End.UTC().Format(_utcLayout))
+		}
+
+		line("SUMMARY", escapeText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escapeText(e.Description))
+		}
+		if len(e.Categories) > 0 {
+			escaped := make([]string, len(e.Categories))
+			for i, category := range e.Categories {
+				escaped[i] = escapeText(category)
+			}
+			line("CATEGORIES", strings.Join(escaped, ","))
+		}
+		if e.Color != "" {
+			line("COLOR", escapeText(e.Color))
+		}
+		for _, a := range e.Attendees {
+			line(a.property(), "mailto:"+a.Email)
+		}
+		if !e.Created.IsZero() {
+			line("CREATED", e.Created.UTC().Format(_utcLayout))
+		}
+		if !e.LastModified.IsZero() {
+			line("LAST-MODIFIED", e.LastModified.UTC().Format(_utcLayout))
+		}
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// Decode reads the VEVENTs of a VCALENDAR object. Properties it does not
+// model, VTIMEZONE definitions (TZID names are resolved with the tz
+// database) and nested components such as VALARM are skipped.
+func Decode(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events  []Event
+		current *Event
+		hasEnd  bool
+		dur     time.Duration
+		depth   int
+		inCal   bool
+	)
+
+	for _, raw := range lines {
+		prop, err := parseLine(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		switch prop.name {
+		case "BEGIN":
+			switch {
+			case prop.value == "VCALENDAR" && !inCal:
+				inCal = true
+			case prop.value == "VEVENT" && current == nil && depth == 0:
+				current, hasEnd, dur = &Event{}, false, 0
+			default:
+				depth++
+			}
+			continue
+		case "END":
+			switch {
+			case depth > 0:
+				depth--
+			case prop.value == "VEVENT" && current != nil:
+				if current.UID == "" || current.Start.IsZero() {
+					return nil, fmt.Errorf("%w: VEVENT without UID or DTSTART", ErrMalformed)
+				}
+				if !hasEnd {
+					current.End = defaultEnd(current, dur)
+				}
+				events = append(events, *current)
+				current = nil
+			}
+			continue
+		}
+
+		if current == nil || depth > 0 {
+			continue
+		}
+
+		if err := current.set(prop, &hasEnd, &dur); err != nil {
+			return nil, err
+		}
+	}
+
+	if !inCal || current != nil {
+		return nil, fmt.Errorf("%w: unterminated VCALENDAR or VEVENT", ErrMalformed)
+	}
+	if len(events) == 0 {
+		return nil, ErrNoEvent
+	}
+	return events, nil
+}
+
+func (e *Event) set(prop property, hasEnd *bool, dur *time.Duration) error {
+	var err error
+
+	switch prop.name {
+	case "UID":
+		e.UID = unescapeText(prop.value)
+	case "SUMMARY":
+		e.Summary = unescapeText(prop.value)
+	case "DESCRIPTION":
+		e.Description = unescapeText(prop.value)
+	case "CATEGORIES":
+		for _, category := range splitText(prop.value) {
+			if category = strings.TrimSpace(category); category != "" {
+				e.Categories = append(e.Categories, category)
+			}
+		}
+	case "COLOR":
+		e.Color = unescapeText(prop.value)
+	case "ATTENDEE":
+		// Attendees addressed by anything but e-mail, e.g. urn:uuid:, are
+		// skipped.
+		if scheme, email, ok := strings.Cut(prop.value, ":"); ok && strings.EqualFold(scheme, "mailto") && email != "" {
+			e.Attendees = append(e.Attendees, Attendee{
+				Email:  email,
+				Name:   prop.params["CN"],
+				Status: strings.ToUpper(prop.params["PARTSTAT"]),
+			})
+		}
+	case "DTSTART":
+		e.Start, e.AllDay, err = parseTime(prop)
+	case "DTEND":
+		e.End, _, err = parseTime(prop)
+		*hasEnd = true
+	case "DURATION":
+		*dur, err = ParseDuration(prop.value)
+	case "CREATED":
+		e.Created, _, err = parseTime(prop)
+	case "LAST-MODIFIED":
+		e.LastModified, _, err = parseTime(prop)
+	}
+
+	if err != nil {
+		return fmt.Errorf("ical: %s: %w", prop.name, err)
+	}
+	return nil
+}
+
+// property writes the name and parameters of the ATTENDEE line. Parameter
+// values cannot contain DQUOTE, so one in a name is dropped.
+func (a Attendee) property() string {
+	prop := "ATTENDEE"
+	if a.Name != "" {
+		name := strings.ReplaceAll(a.Name, `"`, "")
+		if strings.ContainsAny(name, ",;:") {
+			name = `"` + name + `"`
+		}
+		prop += ";CN=" + name
+	}
+	if a.Status != "" {
+		prop += ";PARTSTAT=" + a.Status
+	}
+	return prop
+}
+
+// defaultEnd follows RFC 5545 3.6.1: without DTEND a DATE event lasts one
+// day and a DATE-TIME event has no duration.
+func defaultEnd(e *Event, dur time.Duration) time.Time {
+	if dur > 0 {
+		return e.Start.Add(dur)
+	}
+	if e.AllDay {
+		return e.Start.AddDate(0, 0, 1)
+	}
+	return e.Start
+}
+
+func parseTime(prop property) (time.Time, bool, error) {
+	if prop.params["VALUE"] == "DATE" || len(prop.value) == len(_dateLayout) {
+		t, err := time.ParseInLocation(_dateLayout, prop.value, time.UTC)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(prop.value, "Z") {
+		t, err := time.Parse(_utcLayout, prop.value)
+		return t, false, err
+	}
+
+	loc := time.UTC
+	if tzid := prop.params["TZID"]; tzid != "" {
+		var err error
+		if loc, err = time.LoadLocation(strings.TrimPrefix(tzid, "/")); err != nil {
+			return time.Time{}, false, fmt.Errorf("%w %q", ErrUnknownTimezone, tzid)
+		}
+	}
+
+	t, err := time.ParseInLocation(_localLayout, prop.value, loc)
+	return t, false, err
+}
+
+// ParseDuration parses an RFC 5545 duration such as "PT1H30M" or "P1D".
+func ParseDuration(s string) (time.Duration, error) {
+	rest := s
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(rest, "-"):
+		sign, rest = -1, rest[1:]
+	case strings.HasPrefix(rest, "+"):
+		rest = rest[1:]
+	}
+
+	rest, ok := strings.CutPrefix(rest, "P")
+	if !ok || rest == "" {
+		return 0, fmt.Errorf("%w: duration %q", ErrMalformed, s)
+	}
+
+	var (
+		total  time.Duration
+		units  int
+		inTime bool
+		digits string
+	)
+	for _, r := range rest {
+		switch {
+		case r >= '0' && r <= '9':
+			digits += string(r)
+			continue
+		case r == 'T' && !inTime && digits == "":
+			inTime = true
+			continue
+		}
+
+		n, err := strconv.Atoi(digits)
+		if err != nil {
+			return 0, fmt.Errorf("%w: duration %q", ErrMalformed, s)
+		}
+		digits = ""
+
+		unit, ok := durationUnit(r, inTime)
+		if !ok {
+			return 0, fmt.Errorf("%w: duration %q", ErrMalformed, s)
+		}
+		total += time.Duration(n) * unit
+		units++
+	}
+
+	if digits != "" || units == 0 {
+		return 0, fmt.Errorf("%w: duration %q", ErrMalformed, s)
+	}
+	return sign * total, nil
+}
+
+func durationUnit(r rune, inTime bool) (time.Duration, bool) {
+	switch {
+	case r == 'W' && !inTime:
+		return _daysPerWeek * _hoursPerDay * time.Hour, true
+	case r == 'D' && !inTime:
+		return _hoursPerDay * time.Hour, true
+	case r == 'H' && inTime:
+		return time.Hour, true
+	case r == 'M' && inTime:
+		return time.Minute, true
+	case r == 'S' && inTime:
+		return time.Second, true
+	}
+	return 0, false
+}
+
+// unfold joins continuation lines, which start with a space or a tab.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ical: read: %w", err)
+	}
+	return lines, nil
+}
+
+// parseLine splits "NAME;PARAM=value;PARAM=\"quoted\":value".
+func parseLine(line string) (property, error) {
+	colon, inQuotes := -1, false
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+		if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return property{}, fmt.Errorf("%w: line %q", ErrMalformed, line)
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	prop := property{
+		name:   strings.ToUpper(parts[0]),
+		params: make(map[string]string, len(parts)-1),
+		value:  line[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+
+	if prop.name == "BEGIN" || prop.name == "END" {
+		prop.value = strings.ToUpper(prop.value)
+	}
+	return prop, nil
+}
+
+// writeFolded writes a content line, folding it at 75 octets without
+// splitting UTF-8 sequences.
+func writeFolded(w *bufio.Writer, line string) {
+	limit := _maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		_, _ = w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// The leading space of a continuation line counts toward its limit.
+		limit = _maxLineOctets - 1
+	}
+	_, _ = w.WriteString(line + "\r\n")
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+func unescapeText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// splitText splits a multi-valued TEXT property on unescaped commas.
+func splitText(s string) []string {
+	var (
+		values []string
+		start  int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ',':
+			values = append(values, unescapeText(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(values, unescapeText(s[start:]))
+}
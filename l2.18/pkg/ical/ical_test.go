@@ -0,0 +1,212 @@
+package ical_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"calendar-wbf/pkg/ical"
+)
+
+func TestDecode_ClientFixtures(t *testing.T) {
+	t.Parallel()
+
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+
+	testCases := []struct {
+		desc string
+		file string
+		want ical.Event
+	}{
+		{
+			desc: "AppleTimedWithAlarm",
+			file: "testdata/apple.ics",
+			want: ical.Event{
+				UID:          "8F3C2A6E-5B1D-4E0A-9C77-2D1E4F6A8B90",
+				Start:        time.Date(2026, time.October, 20, 10, 0, 0, 0, moscow),
+				End:          time.Date(2026, time.October, 20, 11, 0, 0, 0, moscow),
+				Summary:      "Doctor, room 5",
+				Description:  "Bring the referral\nand the insurance card; ask about the results",
+				Categories:   []string{"health", "personal"},
+				Created:      time.Date(2026, time.October, 18, 9, 0, 0, 0, time.UTC),
+				LastModified: time.Date(2026, time.October, 18, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			desc: "ThunderbirdAllDay",
+			file: "testdata/thunderbird.ics",
+			want: ical.Event{
+				UID:     "0b7e4e1c-5c1f-4d0a-8b5e-3f2a1c9d7e61",
+				Start:   time.Date(2026, time.November, 2, 0, 0, 0, 0, time.UTC),
+				End:     time.Date(2026, time.November, 4, 0, 0, 0, 0, time.UTC),
+				AllDay:  true,
+				Summary: "Conference",
+				Attendees: []ical.Attendee{
+					{Email: "alice@example.com", Name: "Alice", Status: "ACCEPTED"},
+					{Email: "bob@example.org", Status: "NEEDS-ACTION"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := os.ReadFile(tc.file)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+
+			events, err := ical.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if len(events) != 1 {
+				t.Fatalf("Decode() returned %d events; want 1", len(events))
+			}
+			assertEvent(t, events[0], tc.want)
+		})
+	}
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	events := []ical.Event{
+		{
+			UID:         "42@calendar",
+			Start:       time.Date(2026, time.October, 20, 7, 0, 0, 0, time.UTC),
+			End:         time.Date(2026, time.October, 20, 8, 30, 0, 0, time.UTC),
+			Summary:     "Планерка; отдел, продаж",
+			Description: strings.Repeat("Длинное описание, которое не влезет в одну строку. ", 4),
+			Categories:  []string{"work", "a,b"},
+			Color:       "#ff0000",
+			Attendees: []ical.Attendee{
+				{Email: "alice@example.com", Name: "Doe, Alice", Status: "TENTATIVE"},
+				{Email: "bob@example.org"},
+			},
+		},
+		{
+			UID:     "43@calendar",
+			Start:   time.Date(2026, time.November, 2, 0, 0, 0, 0, time.UTC),
+			End:     time.Date(2026, time.November, 3, 0, 0, 0, 0, time.UTC),
+			AllDay:  true,
+			Summary: "Day off",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := ical.Encode(&buf, "-//calendar-wbf//EN", events...); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line %q is %d octets; want at most 75", line, len(line))
+		}
+	}
+	if !strings.Contains(buf.String(), "DTSTART;VALUE=DATE:20261102\r\n") {
+		t.Errorf("all-day event not written as DATE:\n%s", buf.String())
+	}
+
+	got, err := ical.Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if len(got) != len(events) {
+		t.Fatalf("Decode() returned %d events; want %d", len(got), len(events))
+	}
+	for i := range events {
+		assertEvent(t, got[i], events[i])
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	t.Parallel()
+
+	wrap := func(lines ...string) string {
+		return "BEGIN:VCALENDAR\r\n" + strings.Join(lines, "\r\n") + "\r\nEND:VCALENDAR\r\n"
+	}
+
+	testCases := []struct {
+		desc  string
+		input string
+		want  error
+	}{
+		{"NoEvent", wrap("VERSION:2.0"), ical.ErrNoEvent},
+		{"MissingUID", wrap("BEGIN:VEVENT", "DTSTART:20261020T100000Z", "END:VEVENT"), ical.ErrMalformed},
+		{"Unterminated", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:x\r\n", ical.ErrMalformed},
+		{"NoColon", wrap("BEGIN:VEVENT", "UID", "END:VEVENT"), ical.ErrMalformed},
+		{"UnknownTimezone", wrap(
+			"BEGIN:VEVENT", "UID:x", "DTSTART;TZID=Mars/Olympus:20261020T100000", "END:VEVENT",
+		), ical.ErrUnknownTimezone},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := ical.Decode(strings.NewReader(tc.input)); !errors.Is(err, tc.want) {
+				t.Errorf("Decode() error = %v; want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		input     string
+		want      time.Duration
+		wantError bool
+	}{
+		{"PT1H30M", 90 * time.Minute, false},
+		{"P1D", 24 * time.Hour, false},
+		{"P1W", 7 * 24 * time.Hour, false},
+		{"P1DT2H", 26 * time.Hour, false},
+		{"-PT15M", -15 * time.Minute, false},
+		{"PT", 0, true},
+		{"P1H", 0, true},
+		{"1H", 0, true},
+		{"PT5", 0, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ical.ParseDuration(tc.input)
+			if (err != nil) != tc.wantError {
+				t.Fatalf("ParseDuration(%q) error = %v; wantError %v", tc.input, err, tc.wantError)
+			}
+			if got != tc.want {
+				t.Errorf("ParseDuration(%q) = %v; want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func assertEvent(t *testing.T, got, want ical.Event) {
+	t.Helper()
+
+	if got.UID != want.UID || got.Summary != want.Summary || got.Description != want.Description ||
+		got.Color != want.Color || got.AllDay != want.AllDay || !slices.Equal(got.Categories, want.Categories) ||
+		!slices.Equal(got.Attendees, want.Attendees) {
+		t.Errorf("event = %+v; want %+v", got, want)
+	}
+	if !got.Start.Equal(want.Start) || !got.End.Equal(want.End) {
+		t.Errorf("time = %v - %v; want %v - %v", got.Start, got.End, want.Start, want.End)
+	}
+	if !got.Created.Equal(want.Created) || !got.LastModified.Equal(want.LastModified) {
+		t.Errorf("created/modified = %v/%v; want %v/%v", got.Created, got.LastModified, want.Created, want.LastModified)
+	}
+}
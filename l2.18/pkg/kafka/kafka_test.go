@@ -0,0 +1,185 @@
+package kafka_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"calendar-wbf/pkg/kafka"
+)
+
+func TestProducer_Produce(t *testing.T) {
+	t.Parallel()
+
+	srv, producer := newTestProducer(t, 5)
+	ctx := context.Background()
+	sent := time.UnixMilli(1_790_000_000_123)
+
+	err := producer.Produce(ctx, "calendar.events",
+		kafka.Message{Key: []byte("foobar"), Value: []byte("first"), Time: sent},
+		kafka.Message{
+			Key:     []byte("foobar"),
+			Value:   []byte("second"),
+			Headers: []kafka.Header{{Key: "type", Value: []byte("event.updated")}, {Key: "empty"}},
+			Time:    sent.Add(time.Second),
+		},
+		kafka.Message{Key: []byte("abc"), Value: nil},
+	)
+	if err != nil {
+		t.Fatalf("Produce() error = %v", err)
+	}
+
+	records := srv.Records("calendar.events")
+	if len(records) != 3 {
+		t.Fatalf("Records() = %d records; want 3", len(records))
+	}
+
+	// Partitions follow the murmur2 partitioner of the Java client.
+	testCases := []struct {
+		desc      string
+		record    kafka.Record
+		partition int32
+		offset    int64
+		value     string
+	}{
+		{"First", records[0], 1, 0, "first"},
+		{"SameKeyKeepsOrder", records[1], 1, 1, "second"},
+		{"OtherKey", records[2], 2, 0, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if tc.record.Partition != tc.partition || tc.record.Offset != tc.offset {
+				t.Errorf("record at %d/%d; want %d/%d",
+					tc.record.Partition, tc.record.Offset, tc.partition, tc.offset)
+			}
+			if string(tc.record.Value) != tc.value {
+				t.Errorf("Value = %q; want %q", tc.record.Value, tc.value)
+			}
+		})
+	}
+
+	if !records[0].Time.Equal(sent) || !records[1].Time.Equal(sent.Add(time.Second)) {
+		t.Errorf("times = %v, %v; want %v and a second later", records[0].Time, records[1].Time, sent)
+	}
+	if headers := records[1].Headers; len(headers) != 2 || headers[0].Key != "type" ||
+		string(headers[0].Value) != "event.updated" || headers[1].Value != nil {
+		t.Errorf("Headers = %+v", headers)
+	}
+	if records[2].Value != nil {
+		t.Errorf("Value = %q; want nil", records[2].Value)
+	}
+}
+
+func TestProducer_RetriesRetriableErrors(t *testing.T) {
+	t.Parallel()
+
+	srv, producer := newTestProducer(t, 1)
+	srv.FailNext(kafka.ErrNotLeaderForPartition, 2)
+
+	if err := producer.Produce(context.Background(), "events", kafka.Message{Value: []byte("x")}); err != nil {
+		t.Fatalf("Produce() error = %v", err)
+	}
+	if got := len(srv.Records("events")); got != 1 {
+		t.Errorf("Records() = %d records; want 1", got)
+	}
+}
+
+func TestProducer_Errors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc     string
+		failures int
+		code     kafka.Error
+	}{
+		{"NotRetriable", 1, kafka.ErrMessageTooLarge},
+		{"RetriesExhausted", 10, kafka.ErrLeaderNotAvailable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			srv, producer := newTestProducer(t, 1)
+			srv.FailNext(tc.code, tc.failures)
+
+			err := producer.Produce(context.Background(), "events", kafka.Message{Value: []byte("x")})
+			if !errors.Is(err, tc.code) {
+				t.Errorf("Produce() error = %v; want %v", err, tc.code)
+			}
+			if got := len(srv.Records("events")); got != 0 {
+				t.Errorf("Records() = %d records; want 0", got)
+			}
+		})
+	}
+}
+
+func TestProducer_ReconnectsAfterServerRestart(t *testing.T) {
+	t.Parallel()
+
+	srv, err := kafka.NewServer("127.0.0.1:0", 1)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	addr := srv.Addr()
+
+	producer, err := kafka.NewProducer([]string{addr}, kafka.WithRetries(3, 10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewProducer() error = %v", err)
+	}
+	t.Cleanup(func() { producer.Close() })
+
+	ctx := context.Background()
+	if err = producer.Produce(ctx, "events", kafka.Message{Value: []byte("before")}); err != nil {
+		t.Fatalf("Produce() error = %v", err)
+	}
+	srv.Close()
+
+	restarted, err := kafka.NewServer(addr, 1)
+	if err != nil {
+		t.Skipf("port %s not reusable: %v", addr, err)
+	}
+	t.Cleanup(func() { restarted.Close() })
+
+	if err = producer.Produce(ctx, "events", kafka.Message{Value: []byte("after")}); err != nil {
+		t.Fatalf("Produce() after restart error = %v", err)
+	}
+	if records := restarted.Records("events"); len(records) != 1 || string(records[0].Value) != "after" {
+		t.Errorf("Records() = %+v; want the message sent after the restart", records)
+	}
+}
+
+func TestProducer_Unreachable(t *testing.T) {
+	t.Parallel()
+
+	producer, err := kafka.NewProducer([]string{"127.0.0.1:1"}, kafka.WithDialTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewProducer() error = %v", err)
+	}
+	defer producer.Close()
+
+	if err = producer.Produce(context.Background(), "events", kafka.Message{Value: []byte("x")}); err == nil {
+		t.Error("Produce() error = nil; want a dial error")
+	}
+}
+
+func newTestProducer(t *testing.T, partitions int) (*kafka.Server, *kafka.Producer) {
+	t.Helper()
+
+	srv, err := kafka.NewServer("127.0.0.1:0", partitions)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	producer, err := kafka.NewProducer([]string{srv.Addr()}, kafka.WithRetries(3, time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewProducer() error = %v", err)
+	}
+
+	t.Cleanup(func() {
+		producer.Close()
+		srv.Close()
+	})
+	return srv, producer
+}
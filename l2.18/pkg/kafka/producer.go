@@ -0,0 +1,470 @@
+package kafka
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	_defaultClientID     = "calendar"
+	_defaultDialTimeout  = time.Second
+	_defaultIOTimeout    = 5 * time.Second
+	_defaultRetries      = 3
+	_defaultRetryBackoff = 100 * time.Millisecond
+	_maxPartitions       = 1 << 16
+
+	// _requiredAcks waits for all in-sync replicas.
+	_requiredAcks int16 = -1
+)
+
+type (
+	// Producer publishes messages to the leaders of topic partitions, which
+	// it learns from the bootstrap brokers and caches per topic. Calls are
+	// serialized, each over one connection per broker.
+	Producer struct {
+		bootstrap    []string
+		clientID     string
+		dialTimeout  time.Duration
+		ioTimeout    time.Duration
+		retries      int
+		retryBackoff time.Duration
+
+		mu          sync.Mutex
+		conns       map[string]*conn
+		brokers     map[int32]string
+		leaders     map[string][]int32
+		correlation int32
+		roundRobin  uint32
+		closed      bool
+	}
+
+	ProducerOption func(*Producer)
+
+	conn struct {
+		net.Conn
+		r *bufio.Reader
+	}
+
+	partitionResult struct {
+		partition int32
+		err       Error
+	}
+)
+
+func WithClientID(id string) ProducerOption {
+	return func(p *Producer) {
+		p.clientID = id
+	}
+}
+
+func WithDialTimeout(d time.Duration) ProducerOption {
+	return func(p *Producer) {
+		p.dialTimeout = d
+	}
+}
+
+// WithIOTimeout bounds each request when the context has no deadline.
+func WithIOTimeout(d time.Duration) ProducerOption {
+	return func(p *Producer) {
+		p.ioTimeout = d
+	}
+}
+
+// WithRetries sets how many times partitions failing with a retriable
+// error are sent again after a metadata refresh.
+func WithRetries(n int, backoff time.Duration) ProducerOption {
+	return func(p *Producer) {
+		p.retries = max(n, 0)
+		p.retryBackoff = backoff
+	}
+}
+
+// NewProducer returns a producer for the cluster behind the bootstrap
+// brokers. Connections are dialed on first use.
+func NewProducer(bootstrap []string, opts ...ProducerOption) (*Producer, error) {
+	if len(bootstrap) == 0 {
+		return nil, errors.New("kafka: no bootstrap brokers")
+	}
+
+	p := &Producer{
+		bootstrap:    bootstrap,
+		clientID:     _defaultClientID,
+		dialTimeout:  _defaultDialTimeout,
+		ioTimeout:    _defaultIOTimeout,
+		retries:      _defaultRetries,
+		retryBackoff: _defaultRetryBackoff,
+		conns:        make(map[string]*conn),
+		brokers:      make(map[int32]string),
+		leaders:      make(map[string][]int32),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p, nil
+}
+
+// Produce publishes msgs to the topic and returns once every partition
+// leader has acknowledged them. On error some partitions may have stored
+// their messages, so a retry can publish them twice.
+func (p *Producer) Produce(ctx context.Context, topic string, msgs ...Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return errors.New("kafka: producer closed")
+	}
+
+	leaders, err := p.partitions(ctx, topic, false)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	pending := p.assign(msgs, len(leaders))
+	for attempt := 0; ; attempt++ {
+		err = p.send(ctx, topic, leaders, pending, now)
+		if err == nil || attempt == p.retries || !isRetriable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(p.retryBackoff):
+		}
+		if leaders, err = p.partitions(ctx, topic, true); err != nil {
+			return err
+		}
+		for partition := range pending {
+			if int(partition) >= len(leaders) {
+				return fmt.Errorf("kafka: %s: partition %d is gone", topic, partition)
+			}
+		}
+	}
+}
+
+func (p *Producer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	for addr, cn := range p.conns {
+		cn.Close()
+		delete(p.conns, addr)
+	}
+	return nil
+}
+
+// assign groups messages by partition, keeping their order.
+func (p *Producer) assign(msgs []Message, partitions int) map[int32][]Message {
+	pending := make(map[int32][]Message)
+	for _, msg := range msgs {
+		var partition int32
+		if msg.Key != nil {
+			partition = partitionFor(msg.Key, partitions)
+		} else {
+			partition = int32(p.roundRobin % uint32(partitions))
+			p.roundRobin++
+		}
+		pending[partition] = append(pending[partition], msg)
+	}
+	return pending
+}
+
+// send produces the pending messages to their leaders and removes the
+// partitions that were acknowledged.
+func (p *Producer) send(
+	ctx context.Context,
+	topic string,
+	leaders []int32,
+	pending map[int32][]Message,
+	now time.Time,
+) error {
+	byLeader := make(map[int32][]int32)
+	for partition := range pending {
+		leader := leaders[partition]
+		byLeader[leader] = append(byLeader[leader], partition)
+	}
+
+	var failed error
+	for leader, partitions := range byLeader {
+		addr, ok := p.brokers[leader]
+		if !ok {
+			failed = fmt.Errorf("partitions %v: %w", partitions, ErrLeaderNotAvailable)
+			continue
+		}
+
+		results, err := p.produce(ctx, addr, topic, partitions, pending, now)
+		if err != nil {
+			failed = err
+			continue
+		}
+		for _, result := range results {
+			if result.err == 0 {
+				delete(pending, result.partition)
+				continue
+			}
+			failed = fmt.Errorf("partition %d: %w", result.partition, result.err)
+			if !result.err.Retriable() {
+				return fmt.Errorf("kafka: produce to %s: %w", topic, failed)
+			}
+		}
+	}
+
+	if failed != nil {
+		return fmt.Errorf("kafka: produce to %s: %w", topic, failed)
+	}
+	return nil
+}
+
+func (p *Producer) produce(
+	ctx context.Context,
+	addr, topic string,
+	partitions []int32,
+	pending map[int32][]Message,
+	now time.Time,
+) ([]partitionResult, error) {
+	var body encoder
+	body.nullableString(nil)
+	body.int16(_requiredAcks)
+	body.int32(int32(p.ioTimeout.Milliseconds()))
+	body.int32(1)
+	body.string(topic)
+	body.int32(int32(len(partitions)))
+	for _, partition := range partitions {
+		body.int32(partition)
+		body.bytes(encodeBatch(pending[partition], now))
+	}
+
+	d, err := p.roundTrip(ctx, addr, _apiProduce, _produceVersion, body.b)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []partitionResult
+	for range d.arrayLen() {
+		name := d.string()
+		for range d.arrayLen() {
+			result := partitionResult{partition: d.int32(), err: Error(d.int16())}
+			d.int64()
+			d.int64()
+			if name == topic {
+				results = append(results, result)
+			}
+		}
+	}
+	d.int32()
+	if d.err != nil {
+		p.drop(addr)
+		return nil, d.err
+	}
+
+	for _, partition := range partitions {
+		if !hasPartition(results, partition) {
+			return nil, fmt.Errorf("%w: no result for partition %d", ErrProtocol, partition)
+		}
+	}
+	return results, nil
+}
+
+// partitions returns the leader of each partition of the topic, asking
+// the cluster when the topic is not cached or refresh is set.
+func (p *Producer) partitions(ctx context.Context, topic string, refresh bool) ([]int32, error) {
+	if leaders, ok := p.leaders[topic]; ok && !refresh {
+		return leaders, nil
+	}
+
+	var lastErr error
+	for _, addr := range p.metadataAddrs() {
+		leaders, err := p.metadata(ctx, addr, topic)
+		if err == nil {
+			p.leaders[topic] = leaders
+			return leaders, nil
+		}
+		lastErr = err
+
+		var kafkaErr Error
+		if errors.As(err, &kafkaErr) {
+			break
+		}
+	}
+	return nil, fmt.Errorf("kafka: metadata for %s: %w", topic, lastErr)
+}
+
+// metadataAddrs lists the known brokers followed by the bootstrap ones.
+func (p *Producer) metadataAddrs() []string {
+	addrs := make([]string, 0, len(p.brokers)+len(p.bootstrap))
+	for _, addr := range p.brokers {
+		addrs = append(addrs, addr)
+	}
+	return append(addrs, p.bootstrap...)
+}
+
+func (p *Producer) metadata(ctx context.Context, addr, topic string) ([]int32, error) {
+	var body encoder
+	body.int32(1)
+	body.string(topic)
+
+	d, err := p.roundTrip(ctx, addr, _apiMetadata, _metadataVersion, body.b)
+	if err != nil {
+		return nil, err
+	}
+
+	brokers := make(map[int32]string)
+	for range d.arrayLen() {
+		id := d.int32()
+		host := d.string()
+		port := d.int32()
+		d.nullableString()
+		brokers[id] = net.JoinHostPort(host, strconv.Itoa(int(port)))
+	}
+	d.int32()
+
+	var (
+		leaders  []int32
+		topicErr Error
+	)
+	for range d.arrayLen() {
+		code := Error(d.int16())
+		name := d.string()
+		d.int8()
+		for range d.arrayLen() {
+			d.int16()
+			index := d.int32()
+			leader := d.int32()
+			for range d.arrayLen() {
+				d.int32()
+			}
+			for range d.arrayLen() {
+				d.int32()
+			}
+			if name != topic || index < 0 || index >= _maxPartitions {
+				continue
+			}
+			if int(index) >= len(leaders) {
+				leaders = append(leaders, make([]int32, int(index)+1-len(leaders))...)
+			}
+			leaders[index] = leader
+		}
+		if name == topic {
+			topicErr = code
+		}
+	}
+	if d.err != nil {
+		p.drop(addr)
+		return nil, d.err
+	}
+
+	switch {
+	case topicErr != 0:
+		return nil, topicErr
+	case len(leaders) == 0:
+		return nil, ErrLeaderNotAvailable
+	}
+
+	p.brokers = brokers
+	return leaders, nil
+}
+
+// roundTrip sends one request and returns a decoder over the response
+// body. A failed connection is closed and dialed again by the next call.
+func (p *Producer) roundTrip(
+	ctx context.Context,
+	addr string,
+	apiKey, version int16,
+	body []byte,
+) (*decoder, error) {
+	cn, err := p.conn(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(p.ioTimeout)
+	}
+	if err = cn.SetDeadline(deadline); err != nil {
+		p.drop(addr)
+		return nil, err
+	}
+
+	p.correlation++
+	correlation := p.correlation
+
+	var req encoder
+	req.int16(apiKey)
+	req.int16(version)
+	req.int32(correlation)
+	req.nullableString(&p.clientID)
+	req.b = append(req.b, body...)
+
+	if err = writeFrame(cn, req.b); err != nil {
+		p.drop(addr)
+		return nil, fmt.Errorf("write to %s: %w", addr, err)
+	}
+	resp, err := readFrame(cn.r)
+	if err != nil {
+		p.drop(addr)
+		return nil, fmt.Errorf("read from %s: %w", addr, err)
+	}
+
+	d := &decoder{b: resp}
+	if got := d.int32(); got != correlation || d.err != nil {
+		p.drop(addr)
+		return nil, fmt.Errorf("%w: correlation id %d, want %d", ErrProtocol, got, correlation)
+	}
+	return d, nil
+}
+
+func (p *Producer) conn(ctx context.Context, addr string) (*conn, error) {
+	if cn, ok := p.conns[addr]; ok {
+		return cn, nil
+	}
+
+	dialer := net.Dialer{Timeout: p.dialTimeout}
+	nc, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", addr, err)
+	}
+
+	cn := &conn{Conn: nc, r: bufio.NewReader(nc)}
+	p.conns[addr] = cn
+	return cn, nil
+}
+
+func (p *Producer) drop(addr string) {
+	if cn, ok := p.conns[addr]; ok {
+		cn.Close()
+		delete(p.conns, addr)
+	}
+}
+
+// isRetriable reports whether a failed produce may succeed with fresh
+// metadata: on retriable broker errors and lost connections.
+func isRetriable(err error) bool {
+	var kafkaErr Error
+	if errors.As(err, &kafkaErr) {
+		return kafkaErr.Retriable()
+	}
+	return !errors.Is(err, ErrProtocol) && !errors.Is(err, context.Canceled) &&
+		!errors.Is(err, context.DeadlineExceeded)
+}
+
+func hasPartition(results []partitionResult, partition int32) bool {
+	for _, result := range results {
+		if result.partition == partition {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,457 @@
+// Package kafka speaks the part of the Kafka wire protocol needed to publish
+// messages: a producer sending Metadata and Produce requests and a small
+// in-process broker implementing the same subset for tests and local
+// development.
+package kafka
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	_apiProduce  int16 = 0
+	_apiMetadata int16 = 3
+
+	// Produce v3 carries v2 record batches and is the oldest version
+	// brokers since Kafka 4.0 accept.
+	_produceVersion  int16 = 3
+	_metadataVersion int16 = 1
+
+	_batchMagic     int8 = 2
+	_batchHeaderLen      = 61
+	_maxFrameSize        = 64 << 20
+)
+
+var (
+	ErrProtocol = errors.New("kafka: protocol error")
+
+	_castagnoli = crc32.MakeTable(crc32.Castagnoli)
+)
+
+type (
+	// Message is one record to publish. A nil Key spreads messages over
+	// the partitions; messages with the same key go to the same partition
+	// and keep their order. A zero Time is set to the time of sending.
+	Message struct {
+		Key     []byte
+		Value   []byte
+		Headers []Header
+		Time    time.Time
+	}
+
+	Header struct {
+		Key   string
+		Value []byte
+	}
+
+	// Error is an error code sent by the broker for a topic or partition.
+	Error int16
+
+	encoder struct {
+		b []byte
+	}
+
+	decoder struct {
+		b   []byte
+		err error
+	}
+)
+
+const (
+	ErrCorruptMessage          Error = 2
+	ErrUnknownTopicOrPartition Error = 3
+	ErrLeaderNotAvailable      Error = 5
+	ErrNotLeaderForPartition   Error = 6
+	ErrRequestTimedOut         Error = 7
+	ErrMessageTooLarge         Error = 10
+	ErrInvalidTopic            Error = 17
+	ErrNotEnoughReplicas       Error = 19
+	ErrUnsupportedVersion      Error = 35
+)
+
+var _errorNames = map[Error]string{
+	ErrCorruptMessage:          "CORRUPT_MESSAGE",
+	ErrUnknownTopicOrPartition: "UNKNOWN_TOPIC_OR_PARTITION",
+	ErrLeaderNotAvailable:      "LEADER_NOT_AVAILABLE",
+	ErrNotLeaderForPartition:   "NOT_LEADER_OR_FOLLOWER",
+	ErrRequestTimedOut:         "REQUEST_TIMED_OUT",
+	ErrMessageTooLarge:         "MESSAGE_TOO_LARGE",
+	ErrNotEnoughReplicas:       "NOT_ENOUGH_REPLICAS",
+	ErrInvalidTopic:            "INVALID_TOPIC_EXCEPTION",
+	ErrUnsupportedVersion:      "UNSUPPORTED_VERSION",
+}
+
+func (e Error) Error() string {
+	name, ok := _errorNames[e]
+	if !ok {
+		name = "error code " + strconv.Itoa(int(e))
+	}
+	return name
+}
+
+// Retriable reports whether the request may succeed once the metadata is
+// refreshed, e.g. after a leader election.
+func (e Error) Retriable() bool {
+	switch e {
+	case ErrUnknownTopicOrPartition, ErrLeaderNotAvailable, ErrNotLeaderForPartition,
+		ErrRequestTimedOut, ErrNotEnoughReplicas:
+		return true
+	default:
+		return false
+	}
+}
+
+func (e *encoder) int8(v int8) {
+	e.b = append(e.b, byte(v))
+}
+
+func (e *encoder) int16(v int16) {
+	e.b = binary.BigEndian.AppendUint16(e.b, uint16(v))
+}
+
+func (e *encoder) int32(v int32) {
+	e.b = binary.BigEndian.AppendUint32(e.b, uint32(v))
+}
+
+func (e *encoder) int64(v int64) {
+	e.b = binary.BigEndian.AppendUint64(e.b, uint64(v))
+}
+
+func (e *encoder) string(s string) {
+	e.int16(int16(len(s)))
+	e.b = append(e.b, s...)
+}
+
+func (e *encoder) nullableString(s *string) {
+	if s == nil {
+		e.int16(-1)
+		return
+	}
+	e.string(*s)
+}
+
+func (e *encoder) bytes(b []byte) {
+	if b == nil {
+		e.int32(-1)
+		return
+	}
+	e.int32(int32(len(b)))
+	e.b = append(e.b, b...)
+}
+
+func (e *encoder) varint(v int64) {
+	e.b = binary.AppendVarint(e.b, v)
+}
+
+func (e *encoder) varintBytes(b []byte) {
+	if b == nil {
+		e.varint(-1)
+		return
+	}
+	e.varint(int64(len(b)))
+	e.b = append(e.b, b...)
+}
+
+func (d *decoder) fail(format string, args ...any) {
+	if d.err == nil {
+		d.err = fmt.Errorf("%w: "+format, append([]any{ErrProtocol}, args...)...)
+	}
+}
+
+func (d *decoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.b) {
+		d.fail("need %d bytes, have %d", n, len(d.b))
+		return nil
+	}
+	b := d.b[:n]
+	d.b = d.b[n:]
+	return b
+}
+
+func (d *decoder) int8() int8 {
+	if b := d.take(1); b != nil {
+		return int8(b[0])
+	}
+	return 0
+}
+
+func (d *decoder) int16() int16 {
+	if b := d.take(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (d *decoder) int32() int32 {
+	if b := d.take(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (d *decoder) int64() int64 {
+	if b := d.take(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (d *decoder) string() string {
+	n := d.int16()
+	if n < 0 {
+		d.fail("null string")
+		return ""
+	}
+	return string(d.take(int(n)))
+}
+
+func (d *decoder) nullableString() *string {
+	n := d.int16()
+	if n < 0 {
+		return nil
+	}
+	s := string(d.take(int(n)))
+	return &s
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.int32()
+	if n < 0 {
+		return nil
+	}
+	return d.take(int(n))
+}
+
+// arrayLen reads an array length, treating a null array as empty. Each
+// item takes at least one byte, which bounds lengths from bad input.
+func (d *decoder) arrayLen() int {
+	n := d.int32()
+	if n < 0 {
+		return 0
+	}
+	if int(n) > len(d.b) {
+		d.fail("array of %d items in %d bytes", n, len(d.b))
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.b)
+	if n <= 0 {
+		d.fail("bad varint")
+		return 0
+	}
+	d.b = d.b[n:]
+	return v
+}
+
+func (d *decoder) varintBytes() []byte {
+	n := d.varint()
+	if n < 0 {
+		return nil
+	}
+	if n > math.MaxInt32 {
+		d.fail("bad length %d", n)
+		return nil
+	}
+	return d.take(int(n))
+}
+
+// encodeBatch encodes messages as one uncompressed v2 record batch without
+// producer ID, so the broker does not deduplicate retries.
+func encodeBatch(msgs []Message, now time.Time) []byte {
+	base := msgs[0].Time
+	if base.IsZero() {
+		base = now
+	}
+	baseTimestamp := base.UnixMilli()
+	maxTimestamp := baseTimestamp
+
+	var records encoder
+	for i, msg := range msgs {
+		timestamp := baseTimestamp
+		if !msg.Time.IsZero() {
+			timestamp = msg.Time.UnixMilli()
+		}
+		maxTimestamp = max(maxTimestamp, timestamp)
+
+		var r encoder
+		r.int8(0)
+		r.varint(timestamp - baseTimestamp)
+		r.varint(int64(i))
+		r.varintBytes(msg.Key)
+		r.varintBytes(msg.Value)
+		r.varint(int64(len(msg.Headers)))
+		for _, h := range msg.Headers {
+			r.varintBytes([]byte(h.Key))
+			r.varintBytes(h.Value)
+		}
+
+		records.varint(int64(len(r.b)))
+		records.b = append(records.b, r.b...)
+	}
+
+	var e encoder
+	e.b = make([]byte, 0, _batchHeaderLen+len(records.b))
+	e.int64(0)
+	e.int32(int32(_batchHeaderLen - 12 + len(records.b)))
+	e.int32(-1)
+	e.int8(_batchMagic)
+	crcAt := len(e.b)
+	e.int32(0)
+	e.int16(0)
+	e.int32(int32(len(msgs) - 1))
+	e.int64(baseTimestamp)
+	e.int64(maxTimestamp)
+	e.int64(-1)
+	e.int16(-1)
+	e.int32(-1)
+	e.int32(int32(len(msgs)))
+	e.b = append(e.b, records.b...)
+
+	binary.BigEndian.PutUint32(e.b[crcAt:], crc32.Checksum(e.b[crcAt+4:], _castagnoli))
+	return e.b
+}
+
+// decodeBatches reads the uncompressed v2 record batches of a produce
+// request, checking their checksums.
+func decodeBatches(b []byte) ([]Message, error) {
+	var msgs []Message
+	for len(b) > 0 {
+		d := &decoder{b: b}
+		d.int64()
+		length := d.int32()
+		if d.err != nil || length < _batchHeaderLen-12 || int(length) > len(d.b) {
+			return nil, fmt.Errorf("%w: bad batch length", ErrProtocol)
+		}
+		batch := &decoder{b: d.b[:length]}
+		b = d.b[length:]
+
+		batch.int32()
+		if magic := batch.int8(); magic != _batchMagic {
+			return nil, fmt.Errorf("%w: unsupported magic %d", ErrProtocol, magic)
+		}
+		crc := uint32(batch.int32())
+		if crc32.Checksum(batch.b, _castagnoli) != crc {
+			return nil, ErrCorruptMessage
+		}
+		if attributes := batch.int16(); attributes&0x7 != 0 {
+			return nil, fmt.Errorf("%w: compressed batch", ErrProtocol)
+		}
+		batch.int32()
+		baseTimestamp := batch.int64()
+		batch.int64()
+		batch.int64()
+		batch.int16()
+		batch.int32()
+
+		count := batch.arrayLen()
+		for range count {
+			record := &decoder{b: batch.varintBytes()}
+			record.int8()
+			timestamp := baseTimestamp + record.varint()
+			record.varint()
+			msg := Message{
+				Key:   record.varintBytes(),
+				Value: record.varintBytes(),
+				Time:  time.UnixMilli(timestamp),
+			}
+			headers := record.varint()
+			if headers < 0 || headers > int64(len(record.b)) {
+				return nil, fmt.Errorf("%w: bad header count", ErrProtocol)
+			}
+			for range headers {
+				msg.Headers = append(msg.Headers, Header{Key: string(record.varintBytes()), Value: record.varintBytes()})
+			}
+			if record.err != nil {
+				return nil, record.err
+			}
+			msgs = append(msgs, msg)
+		}
+		if batch.err != nil {
+			return nil, batch.err
+		}
+	}
+	return msgs, nil
+}
+
+// writeFrame writes a size-prefixed request or response.
+func writeFrame(w io.Writer, body []byte) error {
+	frame := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(body)), uint32(len(body)))
+	_, err := w.Write(append(frame, body...))
+	return err
+}
+
+func readFrame(r io.Reader) ([]byte, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > _maxFrameSize {
+		return nil, fmt.Errorf("%w: frame of %d bytes", ErrProtocol, n)
+	}
+
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// partitionFor hashes a key like the default partitioner of the Java
+// client, so keyed messages land on the same partitions.
+func partitionFor(key []byte, partitions int) int32 {
+	return int32((murmur2(key) & 0x7fffffff) % uint32(partitions))
+}
+
+func murmur2(data []byte) uint32 {
+	const (
+		seed = 0x9747b28c
+		m    = 0x5bd1e995
+		r    = 24
+	)
+
+	length := len(data)
+	h := uint32(seed) ^ uint32(length)
+	for i := 0; i+4 <= length; i += 4 {
+		k := binary.LittleEndian.Uint32(data[i:])
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+
+	tail := data[length&^3:]
+	switch len(tail) {
+	case 3:
+		h ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(tail[0])
+		h *= m
+	}
+
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return h
+}
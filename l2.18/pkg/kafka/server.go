@@ -0,0 +1,309 @@
+package kafka
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+)
+
+const _serverNodeID int32 = 0
+
+type (
+	// Server is an in-memory stand-in for a single Kafka broker answering
+	// the requests the Producer sends: Metadata v1 and Produce v3. Topics
+	// are created on first use. Like a broker, it closes connections
+	// sending other requests.
+	Server struct {
+		listener   net.Listener
+		host       string
+		port       int32
+		partitions int
+
+		mu       sync.Mutex
+		topics   map[string][][]Record
+		failures []Error
+
+		conns sync.WaitGroup
+		open  map[net.Conn]struct{}
+	}
+
+	// Record is a message stored by the Server.
+	Record struct {
+		Message
+
+		Partition int32
+		Offset    int64
+	}
+)
+
+// NewServer starts a broker on addr whose topics have the given number of
+// partitions; use "127.0.0.1:0" for a random port.
+func NewServer(addr string, partitions int) (*Server, error) {
+	if partitions < 1 {
+		return nil, errors.New("kafka: server needs at least one partition")
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	tcpAddr, ok := ln.Addr().(*net.TCPAddr)
+	if !ok {
+		ln.Close()
+		return nil, errors.New("kafka: server needs a TCP address")
+	}
+
+	s := &Server{
+		listener:   ln,
+		host:       tcpAddr.IP.String(),
+		port:       int32(tcpAddr.Port),
+		partitions: partitions,
+		topics:     make(map[string][][]Record),
+		open:       make(map[net.Conn]struct{}),
+	}
+	go s.acceptLoop()
+
+	return s, nil
+}
+
+func (s *Server) Addr() string {
+	return net.JoinHostPort(s.host, strconv.Itoa(int(s.port)))
+}
+
+// Records returns the messages of the topic ordered by partition and
+// offset.
+func (s *Server) Records(topic string) []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var records []Record
+	for _, partition := range s.topics[topic] {
+		records = append(records, partition...)
+	}
+	return records
+}
+
+// FailNext answers the next n partitions of produce requests with code
+// instead of storing their messages, to exercise retries.
+func (s *Server) FailNext(code Error, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for range n {
+		s.failures = append(s.failures, code)
+	}
+}
+
+// Close stops accepting, drops every client connection and waits for their
+// handlers to exit.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+
+	s.mu.Lock()
+	for nc := range s.open {
+		nc.Close()
+	}
+	s.mu.Unlock()
+
+	s.conns.Wait()
+	return err
+}
+
+func (s *Server) acceptLoop() {
+	for {
+		nc, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.open[nc] = struct{}{}
+		s.conns.Add(1)
+		s.mu.Unlock()
+
+		go s.serve(nc)
+	}
+}
+
+func (s *Server) serve(nc net.Conn) {
+	defer s.conns.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.open, nc)
+		s.mu.Unlock()
+		nc.Close()
+	}()
+
+	r := bufio.NewReader(nc)
+	for {
+		req, err := readFrame(r)
+		if err != nil {
+			return
+		}
+
+		d := &decoder{b: req}
+		apiKey, version, correlation := d.int16(), d.int16(), d.int32()
+		d.nullableString()
+
+		var resp encoder
+		resp.int32(correlation)
+		switch {
+		case apiKey == _apiMetadata && version == _metadataVersion:
+			s.metadata(d, &resp)
+		case apiKey == _apiProduce && version == _produceVersion:
+			s.produce(d, &resp)
+		default:
+			return
+		}
+		if d.err != nil {
+			return
+		}
+
+		if err = writeFrame(nc, resp.b); err != nil {
+			return
+		}
+	}
+}
+
+// metadata lists the server as the only broker and leader, creating the
+// requested topics. A null topic list asks for every topic.
+func (s *Server) metadata(d *decoder, resp *encoder) {
+	var names []string
+	if n := d.int32(); n >= 0 {
+		for range min(int(n), len(d.b)) {
+			names = append(names, d.string())
+		}
+	}
+	if d.err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	if names == nil {
+		for name := range s.topics {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		s.topic(name)
+	}
+	s.mu.Unlock()
+
+	resp.int32(1)
+	resp.int32(_serverNodeID)
+	resp.string(s.host)
+	resp.int32(s.port)
+	resp.nullableString(nil)
+	resp.int32(_serverNodeID)
+
+	resp.int32(int32(len(names)))
+	for _, name := range names {
+		code := Error(0)
+		if name == "" {
+			code = ErrInvalidTopic
+		}
+		resp.int16(int16(code))
+		resp.string(name)
+		resp.int8(0)
+		if code != 0 {
+			resp.int32(0)
+			continue
+		}
+
+		resp.int32(int32(s.partitions))
+		for i := range s.partitions {
+			resp.int16(0)
+			resp.int32(int32(i))
+			resp.int32(_serverNodeID)
+			resp.int32(1)
+			resp.int32(_serverNodeID)
+			resp.int32(1)
+			resp.int32(_serverNodeID)
+		}
+	}
+}
+
+func (s *Server) produce(d *decoder, resp *encoder) {
+	d.nullableString()
+	d.int16()
+	d.int32()
+
+	var topics encoder
+	n := d.arrayLen()
+	topics.int32(int32(n))
+	for range n {
+		name := d.string()
+		topics.string(name)
+
+		partitions := d.arrayLen()
+		topics.int32(int32(partitions))
+		for range partitions {
+			partition := d.int32()
+			batches := d.bytes()
+			if d.err != nil {
+				return
+			}
+
+			offset, code := s.append(name, partition, batches)
+			topics.int32(partition)
+			topics.int16(int16(code))
+			topics.int64(offset)
+			topics.int64(-1)
+		}
+	}
+
+	resp.b = append(resp.b, topics.b...)
+	resp.int32(0)
+}
+
+// append stores the batches of one partition and returns the offset of
+// the first message.
+func (s *Server) append(topic string, partition int32, batches []byte) (int64, Error) {
+	msgs, err := decodeBatches(batches)
+	if err != nil {
+		return -1, ErrCorruptMessage
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.failures) > 0 {
+		code := s.failures[0]
+		s.failures = s.failures[1:]
+		return -1, code
+	}
+	if topic == "" {
+		return -1, ErrInvalidTopic
+	}
+	partitions := s.topic(topic)
+	if partition < 0 || int(partition) >= len(partitions) {
+		return -1, ErrUnknownTopicOrPartition
+	}
+
+	base := int64(len(partitions[partition]))
+	for i, msg := range msgs {
+		partitions[partition] = append(partitions[partition], Record{
+			Message:   msg,
+			Partition: partition,
+			Offset:    base + int64(i),
+		})
+	}
+	return base, 0
+}
+
+// topic returns the partitions of the topic, creating it. Callers hold
+// the lock.
+func (s *Server) topic(name string) [][]Record {
+	if name == "" {
+		return nil
+	}
+	partitions, ok := s.topics[name]
+	if !ok {
+		partitions = make([][]Record, s.partitions)
+		s.topics[name] = partitions
+	}
+	return partitions
+}
@@ -29,6 +29,17 @@ func NewAdapter(cfg *config.Config, opts ...Option) (*Adapter, error) {
 	}, nil
 }
 
+// NewNop returns a logger that discards everything, for tools and benchmarks
+// that need a Logger but have no logging config.
+func NewNop() *Adapter {
+	return &Adapter{
+		zapLogger: &ZapLogger{
+			logger: zap.NewNop(),
+			levels: newLevels(InfoLevel),
+		},
+	}
+}
+
 func (a *Adapter) Debug(msg string, args ...any) {
 	a.zapLogger.Zap().Sugar().Debugw(msg, args...)
 }
@@ -62,23 +73,21 @@ func (a *Adapter) Errorw(msg string, keysAndValues ...any) {
 }
 
 func (a *Adapter) Ctx(ctx context.Context) Logger {
-	return &Adapter{
-		zapLogger: &ZapLogger{
-			logger: a.zapLogger.NewContextLogger(ctx),
-		},
-	}
+	return a.derive(a.zapLogger.NewContextLogger(ctx))
 }
 
+// With adds fields to the logger. A "component" field also makes the new
+// logger follow that component's level override, if one is set.
 func (a *Adapter) With(args ...any) Logger {
-	newAdapter := &Adapter{zapLogger: &ZapLogger{}}
-	newAdapter.zapLogger.logger = a.zapLogger.Zap().With(toZapFields(args)...)
-	return newAdapter
+	logger := a.zapLogger.Zap()
+	if component, ok := componentOf(args); ok {
+		logger = a.zapLogger.levels.withComponentLevel(logger, component)
+	}
+	return a.derive(logger.With(toZapFields(args)...))
 }
 
 func (a *Adapter) WithGroup(name string) Logger {
-	newAdapter := &Adapter{zapLogger: &ZapLogger{}}
-	newAdapter.zapLogger.logger = a.zapLogger.Zap().With(zap.Namespace(name))
-	return newAdapter
+	return a.derive(a.zapLogger.Zap().With(zap.Namespace(name)))
 }
 
 func (a *Adapter) Log(level Level, msg string, attrs ...Attr) {
@@ -101,7 +110,37 @@ func (a *Adapter) LogAttrs(ctx context.Context, level Level, msg string, attrs .
 }
 
 func (a *Adapter) Level() Level {
-	return InfoLevel
+	return a.zapLogger.Level()
+}
+
+func (a *Adapter) SetLevel(level Level) {
+	a.zapLogger.SetLevel(level)
+}
+
+func (a *Adapter) SetComponentLevel(component string, level Level) {
+	a.zapLogger.SetComponentLevel(component, level)
+}
+
+func (a *Adapter) SetComponentLevels(overrides map[string]Level) {
+	a.zapLogger.SetComponentLevels(overrides)
+}
+
+func (a *Adapter) ComponentLevels() map[string]Level {
+	return a.zapLogger.ComponentLevels()
+}
+
+// Ring returns the in-memory sink, or nil when the "ring" sink is disabled.
+func (a *Adapter) Ring() *Ring {
+	return a.zapLogger.ring
+}
+
+// RingOf returns the in-memory sink behind l, if l is an Adapter with the
+// "ring" sink enabled.
+func RingOf(l Logger) *Ring {
+	if a, ok := l.(*Adapter); ok {
+		return a.Ring()
+	}
+	return nil
 }
 
 func (a *Adapter) GenerateRequestID() string {
@@ -116,6 +155,14 @@ func (a *Adapter) WithRequestID(ctx context.Context, requestID string) context.C
 	return a.zapLogger.WithRequestID(ctx, requestID)
 }
 
+func (a *Adapter) GetTenantID(ctx context.Context) string {
+	return a.zapLogger.GetTenantID(ctx)
+}
+
+func (a *Adapter) WithTenantID(ctx context.Context, tenantID string) context.Context {
+	return a.zapLogger.WithTenantID(ctx, tenantID)
+}
+
 func (a *Adapter) LogRequest(
 	ctx context.Context,
 	method, path string,
@@ -125,6 +172,16 @@ func (a *Adapter) LogRequest(
 	a.zapLogger.LogRequest(ctx, method, path, status, duration)
 }
 
+func (a *Adapter) derive(logger *zap.Logger) *Adapter {
+	return &Adapter{
+		zapLogger: &ZapLogger{
+			logger: logger,
+			levels: a.zapLogger.levels,
+			ring:   a.zapLogger.ring,
+		},
+	}
+}
+
 func toZapLevel(level Level) zapcore.Level {
 	switch level {
 	case DebugLevel:
@@ -140,6 +197,19 @@ func toZapLevel(level Level) zapcore.Level {
 	}
 }
 
+func fromZapLevel(level zapcore.Level) Level {
+	switch level {
+	case zapcore.DebugLevel:
+		return DebugLevel
+	case zapcore.InfoLevel:
+		return InfoLevel
+	case zapcore.WarnLevel:
+		return WarnLevel
+	default:
+		return ErrorLevel
+	}
+}
+
 func toZapFields(args []any) []zap.Field {
 	if len(args)%2 != 0 {
 		args = append(args, "<missing>")
@@ -12,18 +12,13 @@ type contextKey string
 
 const (
 	requestIDKey contextKey = "request_id"
-	loggerKey    contextKey = "zap_logger"
+	tenantIDKey  contextKey = "tenant_id"
 
 	_httpStatusClassDiv = 100
 )
 
 func (l *ZapLogger) WithRequestID(ctx context.Context, requestID string) context.Context {
-	ctx = context.WithValue(ctx, requestIDKey, requestID)
-
-	logger := l.logger.With(zap.String("request_id", requestID))
-	ctx = context.WithValue(ctx, loggerKey, logger)
-
-	return ctx
+	return context.WithValue(ctx, requestIDKey, requestID)
 }
 
 func (l *ZapLogger) GetRequestID(ctx context.Context) string {
@@ -33,19 +28,38 @@ func (l *ZapLogger) GetRequestID(ctx context.Context) string {
 	return ""
 }
 
-func (l *ZapLogger) NewContextLogger(ctx context.Context) *zap.Logger {
-	requestID := l.GetRequestID(ctx)
-	if requestID == "" {
-		return l.logger
+func (l *ZapLogger) WithTenantID(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantIDKey, tenantID)
+}
+
+func (l *ZapLogger) GetTenantID(ctx context.Context) string {
+	if tenantID, ok := ctx.Value(tenantIDKey).(string); ok {
+		return tenantID
 	}
+	return ""
+}
 
-	if cached, ok := ctx.Value(loggerKey).(*zap.Logger); ok {
-		return cached
+// NewContextLogger adds the request and tenant IDs from ctx to this logger.
+// It derives from l rather than a logger stored in ctx, so component fields
+// and level overrides are kept.
+func (l *ZapLogger) NewContextLogger(ctx context.Context) *zap.Logger {
+	fields := l.contextFields(ctx)
+	if len(fields) == 0 {
+		return l.logger
 	}
 
-	logger := l.logger.With(zap.String("request_id", requestID))
+	return l.logger.With(fields...)
+}
 
-	return logger
+func (l *ZapLogger) contextFields(ctx context.Context) []zap.Field {
+	var fields []zap.Field
+	if requestID := l.GetRequestID(ctx); requestID != "" {
+		fields = append(fields, zap.String("request_id", requestID))
+	}
+	if tenantID := l.GetTenantID(ctx); tenantID != "" {
+		fields = append(fields, zap.String("tenant_id", tenantID))
+	}
+	return fields
 }
 
 func (l *ZapLogger) LogRequest(
@@ -54,7 +68,7 @@ func (l *ZapLogger) LogRequest(
 	status int,
 	duration time.Duration,
 ) {
-	logger := l.NewContextLogger(ctx)
+	logger := l.NewContextLogger(ctx).WithOptions(zap.AddCallerSkip(1))
 
 	logger.Info("request",
 		zap.String("method", method),
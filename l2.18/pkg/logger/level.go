@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const _componentKey = "component"
+
+type (
+	// levels holds the root level and per-component overrides shared by every
+	// logger derived from one ZapLogger. Loggers created With a "component"
+	// field check its override first, so both can change at runtime without
+	// rebuilding loggers.
+	levels struct {
+		root      zap.AtomicLevel
+		overrides atomic.Pointer[map[string]zapcore.Level]
+	}
+
+	componentEnabler struct {
+		levels    *levels
+		component string
+	}
+
+	// levelCore gates a core with an enabler. The wrapped core enables every
+	// level, so the enabler alone decides what gets written.
+	levelCore struct {
+		zapcore.Core
+		enabler zapcore.LevelEnabler
+	}
+)
+
+// ParseLevels parses per-component overrides written as
+// "component=level,component=level". Component names may contain spaces,
+// e.g. "http server=warn".
+func ParseLevels(s string) (map[string]Level, error) {
+	overrides := make(map[string]Level)
+	if strings.TrimSpace(s) == "" {
+		return overrides, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		component, levelName, ok := strings.Cut(pair, "=")
+		component = strings.TrimSpace(component)
+		if !ok || component == "" {
+			return nil, fmt.Errorf("logger.ParseLevels: expected component=level, got %q", pair)
+		}
+
+		level, err := ParseLevel(strings.TrimSpace(levelName))
+		if err != nil || strings.TrimSpace(levelName) == "" {
+			return nil, fmt.Errorf("logger.ParseLevels: component %q: unknown level %q", component, levelName)
+		}
+		overrides[component] = level
+	}
+	return overrides, nil
+}
+
+func newLevels(root Level) *levels {
+	l := &levels{root: zap.NewAtomicLevelAt(toZapLevel(root))}
+	l.set(nil)
+	return l
+}
+
+func (l *levels) set(overrides map[string]Level) {
+	zapLevels := make(map[string]zapcore.Level, len(overrides))
+	for component, level := range overrides {
+		zapLevels[component] = toZapLevel(level)
+	}
+	l.overrides.Store(&zapLevels)
+}
+
+func (l *levels) setOne(component string, level Level) {
+	for {
+		current := l.overrides.Load()
+		next := maps.Clone(*current)
+		next[component] = toZapLevel(level)
+		if l.overrides.CompareAndSwap(current, &next) {
+			return
+		}
+	}
+}
+
+func (l *levels) snapshot() map[string]Level {
+	current := *l.overrides.Load()
+	overrides := make(map[string]Level, len(current))
+	for component, level := range current {
+		overrides[component] = fromZapLevel(level)
+	}
+	return overrides
+}
+
+func (l *levels) enabler(component string) componentEnabler {
+	return componentEnabler{levels: l, component: component}
+}
+
+func (e componentEnabler) Enabled(level zapcore.Level) bool {
+	if override, ok := (*e.levels.overrides.Load())[e.component]; ok {
+		return level >= override
+	}
+	return e.levels.root.Enabled(level)
+}
+
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return c.enabler.Enabled(level)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), enabler: c.enabler}
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.enabler.Enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// withComponentLevel makes logger follow the level of component.
+func (l *levels) withComponentLevel(logger *zap.Logger, component string) *zap.Logger {
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if lc, ok := core.(*levelCore); ok {
+			return &levelCore{Core: lc.Core, enabler: l.enabler(component)}
+		}
+		return core
+	}))
+}
+
+// componentOf returns the value of a "component" key in With-style
+// key/value pairs.
+func componentOf(args []any) (string, bool) {
+	for i := 0; i+1 < len(args); i += 2 {
+		if key, ok := args[i].(string); ok && key == _componentKey {
+			component, ok := args[i+1].(string)
+			return component, ok
+		}
+	}
+	return "", false
+}
@@ -2,6 +2,8 @@ package logger
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -35,13 +37,23 @@ type (
 		With(args ...any) Logger
 		WithGroup(name string) Logger
 		WithRequestID(ctx context.Context, requestID string) context.Context
+		WithTenantID(ctx context.Context, tenantID string) context.Context
 
 		GenerateRequestID() string
 		GetRequestID(ctx context.Context) string
+		GetTenantID(ctx context.Context) string
 		LogRequest(ctx context.Context, method, path string, status int, duration time.Duration)
 
 		Log(level Level, msg string, attrs ...Attr)
 		LogAttrs(ctx context.Context, level Level, msg string, attrs ...Attr)
+
+		Level() Level
+		SetLevel(level Level)
+		// SetComponentLevel overrides the level of loggers created With the
+		// given "component" field; SetComponentLevels replaces all overrides.
+		SetComponentLevel(component string, level Level)
+		SetComponentLevels(overrides map[string]Level)
+		ComponentLevels() map[string]Level
 	}
 )
 
@@ -60,6 +72,21 @@ func (l Level) String() string {
 	}
 }
 
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return DebugLevel, nil
+	case "info", "":
+		return InfoLevel, nil
+	case "warn":
+		return WarnLevel, nil
+	case "error":
+		return ErrorLevel, nil
+	default:
+		return InfoLevel, fmt.Errorf("logger.ParseLevel: unknown level %q", s)
+	}
+}
+
 func String(key string, value string) Attr {
 	return Attr{Key: key, Value: value}
 }
@@ -0,0 +1,189 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"calendar-wbf/internal/config"
+	"calendar-wbf/pkg/logger"
+)
+
+type entry struct {
+	Level     string `json:"level"`
+	Msg       string `json:"msg"`
+	Caller    string `json:"caller"`
+	Component string `json:"component"`
+	RequestID string `json:"request_id"`
+	TenantID  string `json:"tenant_id"`
+}
+
+func TestAdapter_ComponentLevels(t *testing.T) {
+	t.Parallel()
+
+	log, out := newTestAdapter(t, config.Logger{Level: "info", Levels: "cache=debug"})
+	cacheLog := log.With("component", "cache")
+	httpLog := log.With("component", "http server")
+
+	log.Debugw("root debug")
+	cacheLog.Debugw("cache debug")
+	httpLog.Debugw("http debug")
+	httpLog.Infow("http info")
+
+	log.SetComponentLevel("http server", logger.ErrorLevel)
+	httpLog.Warnw("http warn")
+	log.SetComponentLevels(nil)
+	cacheLog.Debugw("cache debug after reset")
+	log.SetLevel(logger.DebugLevel)
+	httpLog.Debugw("http debug after root change")
+
+	want := []string{"cache debug", "http info", "http debug after root change"}
+	if got := messages(t, out); !slices.Equal(got, want) {
+		t.Errorf("messages = %q; want %q", got, want)
+	}
+}
+
+func TestAdapter_ComponentLevelKeptWithContext(t *testing.T) {
+	t.Parallel()
+
+	log, out := newTestAdapter(t, config.Logger{Level: "warn", Levels: "calendar service=debug"})
+	svcLog := log.With("component", "calendar service")
+
+	ctx := log.WithRequestID(context.Background(), "req-1")
+	ctx = log.WithTenantID(ctx, "hr")
+	svcLog.LogAttrs(ctx, logger.DebugLevel, "loaded", logger.Int("n", 1))
+
+	entries := decode(t, out)
+	if len(entries) != 1 || entries[0].Component != "calendar service" || entries[0].RequestID != "req-1" ||
+		entries[0].TenantID != "hr" {
+		t.Errorf("entries = %+v; want one entry with component, request and tenant IDs", entries)
+	}
+}
+
+func TestAdapter_Sampling(t *testing.T) {
+	t.Parallel()
+
+	log, out := newTestAdapter(t, config.Logger{
+		Level:              "info",
+		SamplingInitial:    2,
+		SamplingThereafter: 100,
+		SamplingTick:       time.Minute,
+	})
+
+	for range 5 {
+		log.LogAttrs(context.Background(), logger.InfoLevel, "HTTP request")
+		log.Warnw("slow request")
+	}
+	log.Infow("other message")
+
+	counts := make(map[string]int)
+	for _, msg := range messages(t, out) {
+		counts[msg]++
+	}
+	want := map[string]int{"HTTP request": 2, "slow request": 5, "other message": 1}
+	if !maps.Equal(counts, want) {
+		t.Errorf("message counts = %v; want %v", counts, want)
+	}
+}
+
+func TestAdapter_ReportsCaller(t *testing.T) {
+	t.Parallel()
+
+	log, out := newTestAdapter(t, config.Logger{Level: "info"})
+	log.Infow("sugared")
+	log.LogAttrs(context.Background(), logger.InfoLevel, "attrs")
+	log.With("component", "x").Info("derived")
+
+	for _, e := range decode(t, out) {
+		if !strings.HasPrefix(e.Caller, "logger/logger_test.go:") {
+			t.Errorf("%q caller = %s; want the call site in logger_test.go", e.Msg, e.Caller)
+		}
+	}
+}
+
+func TestParseLevels(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc      string
+		input     string
+		want      map[string]logger.Level
+		wantError bool
+	}{
+		{"Empty", "", map[string]logger.Level{}, false},
+		{"SpacesInNames", "cache=debug, http server = warn", map[string]logger.Level{
+			"cache":       logger.DebugLevel,
+			"http server": logger.WarnLevel,
+		}, false},
+		{"MissingLevel", "cache=", nil, true},
+		{"MissingComponent", "=debug", nil, true},
+		{"UnknownLevel", "cache=trace", nil, true},
+		{"NoSeparator", "cache", nil, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := logger.ParseLevels(tc.input)
+			if (err != nil) != tc.wantError {
+				t.Fatalf("ParseLevels(%q) error = %v; wantError %v", tc.input, err, tc.wantError)
+			}
+			if !tc.wantError && !maps.Equal(got, tc.want) {
+				t.Errorf("ParseLevels(%q) = %v; want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewAdapter_InvalidLevels(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{Logger: config.Logger{Level: "info", Levels: "cache"}}
+	if _, err := logger.NewAdapter(cfg, logger.Output(&bytes.Buffer{})); err == nil {
+		t.Errorf("NewAdapter() error = nil; want error for malformed LOGGER_LEVELS")
+	}
+}
+
+func newTestAdapter(t *testing.T, cfg config.Logger) (*logger.Adapter, *bytes.Buffer) {
+	t.Helper()
+
+	out := &bytes.Buffer{}
+	log, err := logger.NewAdapter(&config.Config{Logger: cfg}, logger.Output(out))
+	if err != nil {
+		t.Fatalf("NewAdapter() error = %v", err)
+	}
+	return log, out
+}
+
+func decode(t *testing.T, out *bytes.Buffer) []entry {
+	t.Helper()
+
+	var entries []entry
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var e entry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func messages(t *testing.T, out *bytes.Buffer) []string {
+	t.Helper()
+
+	var msgs []string
+	for _, e := range decode(t, out) {
+		msgs = append(msgs, e.Msg)
+	}
+	return msgs
+}
@@ -42,6 +42,20 @@ func (m *MockLogger) EXPECT() *MockLoggerMockRecorder {
 	return m.recorder
 }
 
+// ComponentLevels mocks base method.
+func (m *MockLogger) ComponentLevels() map[string]logger.Level {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ComponentLevels")
+	ret0, _ := ret[0].(map[string]logger.Level)
+	return ret0
+}
+
+// ComponentLevels indicates an expected call of ComponentLevels.
+func (mr *MockLoggerMockRecorder) ComponentLevels() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ComponentLevels", reflect.TypeOf((*MockLogger)(nil).ComponentLevels))
+}
+
 // Ctx mocks base method.
 func (m *MockLogger) Ctx(ctx context.Context) logger.Logger {
 	m.ctrl.T.Helper()
@@ -152,6 +166,20 @@ func (mr *MockLoggerMockRecorder) GetRequestID(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRequestID", reflect.TypeOf((*MockLogger)(nil).GetRequestID), ctx)
 }
 
+// GetTenantID mocks base method.
+func (m *MockLogger) GetTenantID(ctx context.Context) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenantID", ctx)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetTenantID indicates an expected call of GetTenantID.
+func (mr *MockLoggerMockRecorder) GetTenantID(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenantID", reflect.TypeOf((*MockLogger)(nil).GetTenantID), ctx)
+}
+
 // Info mocks base method.
 func (m *MockLogger) Info(msg string, args ...any) {
 	m.ctrl.T.Helper()
@@ -186,6 +214,20 @@ func (mr *MockLoggerMockRecorder) Infow(msg any, keysAndValues ...any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Infow", reflect.TypeOf((*MockLogger)(nil).Infow), varargs...)
 }
 
+// Level mocks base method.
+func (m *MockLogger) Level() logger.Level {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Level")
+	ret0, _ := ret[0].(logger.Level)
+	return ret0
+}
+
+// Level indicates an expected call of Level.
+func (mr *MockLoggerMockRecorder) Level() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Level", reflect.TypeOf((*MockLogger)(nil).Level))
+}
+
 // Log mocks base method.
 func (m *MockLogger) Log(level logger.Level, msg string, attrs ...logger.Attr) {
 	m.ctrl.T.Helper()
@@ -232,6 +274,42 @@ func (mr *MockLoggerMockRecorder) LogRequest(ctx, method, path, status, duration
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogRequest", reflect.TypeOf((*MockLogger)(nil).LogRequest), ctx, method, path, status, duration)
 }
 
+// SetComponentLevel mocks base method.
+func (m *MockLogger) SetComponentLevel(component string, level logger.Level) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetComponentLevel", component, level)
+}
+
+// SetComponentLevel indicates an expected call of SetComponentLevel.
+func (mr *MockLoggerMockRecorder) SetComponentLevel(component, level any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetComponentLevel", reflect.TypeOf((*MockLogger)(nil).SetComponentLevel), component, level)
+}
+
+// SetComponentLevels mocks base method.
+func (m *MockLogger) SetComponentLevels(overrides map[string]logger.Level) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetComponentLevels", overrides)
+}
+
+// SetComponentLevels indicates an expected call of SetComponentLevels.
+func (mr *MockLoggerMockRecorder) SetComponentLevels(overrides any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetComponentLevels", reflect.TypeOf((*MockLogger)(nil).SetComponentLevels), overrides)
+}
+
+// SetLevel mocks base method.
+func (m *MockLogger) SetLevel(level logger.Level) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetLevel", level)
+}
+
+// SetLevel indicates an expected call of SetLevel.
+func (mr *MockLoggerMockRecorder) SetLevel(level any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLevel", reflect.TypeOf((*MockLogger)(nil).SetLevel), level)
+}
+
 // Warn mocks base method.
 func (m *MockLogger) Warn(msg string, args ...any) {
 	m.ctrl.T.Helper()
@@ -311,3 +389,17 @@ func (mr *MockLoggerMockRecorder) WithRequestID(ctx, requestID any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithRequestID", reflect.TypeOf((*MockLogger)(nil).WithRequestID), ctx, requestID)
 }
+
+// WithTenantID mocks base method.
+func (m *MockLogger) WithTenantID(ctx context.Context, tenantID string) context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTenantID", ctx, tenantID)
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// WithTenantID indicates an expected call of WithTenantID.
+func (mr *MockLoggerMockRecorder) WithTenantID(ctx, tenantID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTenantID", reflect.TypeOf((*MockLogger)(nil).WithTenantID), ctx, tenantID)
+}
@@ -2,6 +2,8 @@ package logger
 
 import (
 	"errors"
+	"io"
+	"time"
 
 	"go.uber.org/zap/zapcore"
 )
@@ -28,7 +30,57 @@ func MaxAge(age int) Option {
 
 func SetLevel(level zapcore.Level) Option {
 	return func(cfg *ZapLogger) {
-		cfg.level = level
+		cfg.levels.root.SetLevel(level)
+	}
+}
+
+// ComponentLevels replaces the overrides read from LOGGER_LEVELS.
+func ComponentLevels(overrides map[string]Level) Option {
+	return func(cfg *ZapLogger) {
+		cfg.overrides = overrides
+	}
+}
+
+// Sampling keeps the first initial entries with the same level and message
+// per tick, then every thereafter-th. Zero initial disables sampling.
+func Sampling(initial, thereafter int, tick time.Duration) Option {
+	return func(cfg *ZapLogger) {
+		cfg.sampling = sampling{initial: initial, thereafter: thereafter, tick: tick}
+	}
+}
+
+// Sinks replaces the sinks read from LOGGER_SINKS.
+func Sinks(names ...string) Option {
+	return func(cfg *ZapLogger) {
+		cfg.sinks = names
+	}
+}
+
+// RingSize sets how many entries the "ring" sink keeps.
+func RingSize(size int) Option {
+	return func(cfg *ZapLogger) {
+		cfg.ringSize = size
+	}
+}
+
+// RedactKeys replaces the key rules read from LOGGER_REDACT_KEYS.
+func RedactKeys(rules map[string]RedactAction) Option {
+	return func(cfg *ZapLogger) {
+		cfg.redactKeys = rules
+	}
+}
+
+// RedactDetectors replaces the detectors read from LOGGER_REDACT_DETECTORS.
+func RedactDetectors(names ...string) Option {
+	return func(cfg *ZapLogger) {
+		cfg.detectors = names
+	}
+}
+
+// Output writes JSON entries to w instead of the configured sinks.
+func Output(w io.Writer) Option {
+	return func(cfg *ZapLogger) {
+		cfg.writer = w
 	}
 }
 
@@ -37,12 +89,24 @@ func (cfg *ZapLogger) validate() error {
 		return errors.New("invalid maxSize: must be > 0")
 	}
 
-	if cfg.maxBackups <= 0 {
-		return errors.New("invalid maxBackups: must be > 0")
+	if cfg.maxBackups < 0 {
+		return errors.New("invalid maxBackups: must be >= 0")
 	}
 
 	if cfg.maxAge <= 0 {
 		return errors.New("invalid maxAge: must be > 0")
 	}
+
+	if cfg.ringSize <= 0 {
+		return errors.New("invalid ringSize: must be > 0")
+	}
+
+	if cfg.sampling.initial < 0 {
+		return errors.New("invalid sampling initial: must be >= 0")
+	}
+
+	if cfg.sampling.initial > 0 && (cfg.sampling.thereafter <= 0 || cfg.sampling.tick <= 0) {
+		return errors.New("invalid sampling: thereafter and tick must be > 0")
+	}
 	return nil
 }
@@ -0,0 +1,337 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Redaction actions for LOGGER_REDACT_KEYS.
+const (
+	// RedactHash replaces the value with a short SHA-256 digest, so equal
+	// values can still be correlated across entries.
+	RedactHash RedactAction = "hash"
+	// RedactMask keeps the shape of the value: the network of an IP address,
+	// the first letter and domain of an email, the first and last rune of
+	// anything else.
+	RedactMask RedactAction = "mask"
+	// RedactDrop removes the field.
+	RedactDrop RedactAction = "drop"
+)
+
+// Value detectors for LOGGER_REDACT_DETECTORS.
+const (
+	DetectEmail = "email"
+	DetectPhone = "phone"
+
+	_hashLength = 12
+)
+
+type (
+	RedactAction string
+
+	// redactor rewrites fields before they reach the sinks: key rules act on
+	// whole values, detectors replace matches inside any string value, error
+	// message or nested object.
+	redactor struct {
+		keys      map[string]RedactAction
+		detectors []detector
+	}
+
+	detector struct {
+		re          *regexp.Regexp
+		replacement string
+	}
+
+	redactCore struct {
+		zapcore.Core
+		redactor *redactor
+	}
+)
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	phonePattern = regexp.MustCompile(
+		`(?:\+\d{1,3}[\s-]?|\b[78]?[\s-]?)\(?\d{3}\)?[\s.-]?\d{3}[\s.-]?\d{2}[\s.-]?\d{2}\b`,
+	)
+)
+
+// ParseRedactKeys parses key rules written as "key=action,key=action", e.g.
+// "client_ip=mask,title=hash". Keys are matched case-insensitively.
+func ParseRedactKeys(s string) (map[string]RedactAction, error) {
+	rules := make(map[string]RedactAction)
+	if strings.TrimSpace(s) == "" {
+		return rules, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		key, action, ok := strings.Cut(pair, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if !ok || key == "" {
+			return nil, fmt.Errorf("logger.ParseRedactKeys: invalid rule %q: want key=action", pair)
+		}
+
+		parsed := RedactAction(strings.ToLower(strings.TrimSpace(action)))
+		if !parsed.valid() {
+			return nil, fmt.Errorf("logger.ParseRedactKeys: invalid action %q for %q", action, key)
+		}
+		rules[key] = parsed
+	}
+	return rules, nil
+}
+
+// ParseDetectors parses a comma-separated detector list such as
+// "email,phone".
+func ParseDetectors(s string) ([]string, error) {
+	var detectors []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "":
+			continue
+		case DetectEmail, DetectPhone:
+			if !slices.Contains(detectors, name) {
+				detectors = append(detectors, name)
+			}
+		default:
+			return nil, fmt.Errorf("logger.ParseDetectors: unknown detector %q", name)
+		}
+	}
+	return detectors, nil
+}
+
+func (a RedactAction) valid() bool {
+	switch a {
+	case RedactHash, RedactMask, RedactDrop:
+		return true
+	}
+	return false
+}
+
+// newRedactor returns nil when there is nothing to redact.
+func newRedactor(keys map[string]RedactAction, detectors []string) (*redactor, error) {
+	if len(keys) == 0 && len(detectors) == 0 {
+		return nil, nil
+	}
+
+	r := &redactor{keys: make(map[string]RedactAction, len(keys))}
+	for key, action := range keys {
+		if !action.valid() {
+			return nil, fmt.Errorf("invalid redact action %q for %q", action, key)
+		}
+		r.keys[strings.ToLower(key)] = action
+	}
+
+	for _, name := range detectors {
+		switch name {
+		case DetectEmail:
+			r.detectors = append(r.detectors, detector{re: emailPattern, replacement: "[email]"})
+		case DetectPhone:
+			r.detectors = append(r.detectors, detector{re: phonePattern, replacement: "[phone]"})
+		default:
+			return nil, fmt.Errorf("unknown redact detector %q", name)
+		}
+	}
+	return r, nil
+}
+
+func (r *redactor) wrap(core zapcore.Core) zapcore.Core {
+	if r == nil {
+		return core
+	}
+	return &redactCore{Core: core, redactor: r}
+}
+
+func (r *redactor) fields(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, 0, len(fields))
+	for _, field := range fields {
+		if redacted, ok := r.field(field); ok {
+			out = append(out, redacted)
+		}
+	}
+	return out
+}
+
+// field returns false when the field has to be dropped.
+func (r *redactor) field(field zapcore.Field) (zapcore.Field, bool) {
+	if action, ok := r.keys[strings.ToLower(field.Key)]; ok {
+		if action == RedactDrop {
+			return field, false
+		}
+		return zap.String(field.Key, r.apply(action, fieldString(field))), true
+	}
+
+	switch field.Type {
+	case zapcore.StringType:
+		field.String = r.detect(field.String)
+	case zapcore.ErrorType:
+		if err, ok := field.Interface.(error); ok && err != nil {
+			if msg := r.detect(err.Error()); msg != err.Error() {
+				return zap.String(field.Key, msg), true
+			}
+		}
+	case zapcore.ObjectMarshalerType, zapcore.ReflectType:
+		enc := zapcore.NewMapObjectEncoder()
+		field.AddTo(enc)
+		return zap.Any(field.Key, r.value(enc.Fields[field.Key])), true
+	}
+	return field, true
+}
+
+// value redacts an encoded object, such as a slog group or a reflected
+// struct.
+func (r *redactor) value(v any) any {
+	switch v := v.(type) {
+	case string:
+		return r.detect(v)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, value := range v {
+			action, ok := r.keys[strings.ToLower(key)]
+			switch {
+			case !ok:
+				out[key] = r.value(value)
+			case action != RedactDrop:
+				out[key] = r.apply(action, fmt.Sprint(value))
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, value := range v {
+			out[i] = r.value(value)
+		}
+		return out
+	default:
+		if normalized, ok := jsonValue(v); ok {
+			return r.value(normalized)
+		}
+		return v
+	}
+}
+
+// jsonValue turns structs, maps and slices into the map[string]any and []any
+// they are logged as, so their keys and strings can be redacted. Numbers are
+// kept as json.Number to log large IDs exactly.
+func jsonValue(v any) (any, bool) {
+	if v == nil {
+		return nil, false
+	}
+	switch reflect.Indirect(reflect.ValueOf(v)).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		return nil, false
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v), true
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var out any
+	if err = dec.Decode(&out); err != nil {
+		return fmt.Sprint(v), true
+	}
+	return out, true
+}
+
+func (r *redactor) detect(s string) string {
+	for _, d := range r.detectors {
+		s = d.re.ReplaceAllString(s, d.replacement)
+	}
+	return s
+}
+
+func (r *redactor) apply(action RedactAction, s string) string {
+	switch action {
+	case RedactHash:
+		return hashValue(s)
+	case RedactMask:
+		return maskValue(s)
+	default:
+		return ""
+	}
+}
+
+func hashValue(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:_hashLength]
+}
+
+func maskValue(s string) string {
+	if addr, err := netip.ParseAddr(s); err == nil {
+		bits := 24
+		if addr.Is6() && !addr.Is4In6() {
+			bits = 48
+		}
+		if prefix, err := addr.Unmap().Prefix(bits); err == nil {
+			return prefix.Addr().String()
+		}
+	}
+
+	if local, domain, ok := strings.Cut(s, "@"); ok && local != "" {
+		return maskRunes(local, false) + "@" + domain
+	}
+
+	return maskRunes(s, true)
+}
+
+func maskRunes(s string, keepLast bool) string {
+	n := utf8.RuneCountInString(s)
+	if n <= 2 {
+		return strings.Repeat("*", n)
+	}
+
+	first, _ := utf8.DecodeRuneInString(s)
+	if !keepLast {
+		return string(first) + strings.Repeat("*", n-1)
+	}
+	last, _ := utf8.DecodeLastRuneInString(s)
+	return string(first) + strings.Repeat("*", n-2) + string(last)
+}
+
+func fieldString(field zapcore.Field) string {
+	switch field.Type {
+	case zapcore.StringType:
+		return field.String
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type:
+		return strconv.FormatInt(field.Integer, 10)
+	case zapcore.ErrorType:
+		if err, ok := field.Interface.(error); ok && err != nil {
+			return err.Error()
+		}
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	field.AddTo(enc)
+	return fmt.Sprint(enc.Fields[field.Key])
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redactor.fields(fields)), redactor: c.redactor}
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Core.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, c.redactor.fields(fields))
+}
@@ -0,0 +1,258 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"maps"
+	"strings"
+	"testing"
+
+	"calendar-wbf/internal/config"
+	"calendar-wbf/pkg/logger"
+)
+
+const _redactRules = "title=hash,client_ip=mask,user_agent=drop,email=mask"
+
+func TestRedaction(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc string
+		log  func(log *logger.Adapter)
+	}{
+		{"Sugared", func(log *logger.Adapter) {
+			log.Infow("msg",
+				"title", "Doctor", "client_ip", "203.0.113.42", "user_agent", "curl/8.0",
+				"email", "alice@example.com", "note", "call +7 (916) 123-45-67 or bob@example.org",
+			)
+		}},
+		{"Attrs", func(log *logger.Adapter) {
+			log.LogAttrs(context.Background(), logger.InfoLevel, "msg",
+				logger.String("title", "Doctor"),
+				logger.String("client_ip", "203.0.113.42"),
+				logger.String("user_agent", "curl/8.0"),
+				logger.String("email", "alice@example.com"),
+				logger.String("note", "call +7 (916) 123-45-67 or bob@example.org"),
+			)
+		}},
+		{"With", func(log *logger.Adapter) {
+			log.With("title", "Doctor", "client_ip", "203.0.113.42", "user_agent", "curl/8.0").
+				Infow("msg", "email", "alice@example.com", "note", "call +7 (916) 123-45-67 or bob@example.org")
+		}},
+		{"Slog", func(log *logger.Adapter) {
+			slog.New(log.Handler()).With("title", "Doctor").Info("msg",
+				"client_ip", "203.0.113.42", "user_agent", "curl/8.0",
+				"email", "alice@example.com", "note", "call +7 (916) 123-45-67 or bob@example.org",
+			)
+		}},
+	}
+
+	want := map[string]any{
+		"title":     "24669ff48290",
+		"client_ip": "203.0.113.0",
+		"email":     "a****@example.com",
+		"note":      "call [phone] or [email]",
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			log, out := newTestAdapter(t, config.Logger{
+				Level:           "info",
+				RedactKeys:      _redactRules,
+				RedactDetectors: "email,phone",
+			})
+			tc.log(log)
+
+			got := decodeFields(t, out)
+			if _, ok := got["user_agent"]; ok {
+				t.Errorf("user_agent = %v; want dropped", got["user_agent"])
+			}
+			for key, value := range want {
+				if got[key] != value {
+					t.Errorf("%s = %v; want %v", key, got[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestRedaction_NestedAndErrors(t *testing.T) {
+	t.Parallel()
+
+	log, out := newTestAdapter(t, config.Logger{
+		Level:           "info",
+		RedactKeys:      _redactRules,
+		RedactDetectors: "email",
+	})
+
+	slog.New(log.Handler()).Info("msg",
+		slog.Group("req", "client_ip", "2001:db8:1:2::1", "user_agent", "x", "to", "bob@example.org"),
+	)
+	log.Errorw("failed", "error", errors.New("send to bob@example.org: timeout"))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d entries; want 2:\n%s", len(lines), out)
+	}
+
+	var group struct {
+		Req map[string]any `json:"req"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &group); err != nil {
+		t.Fatalf("decode %q: %v", lines[0], err)
+	}
+	wantGroup := map[string]any{"client_ip": "2001:db8:1::", "to": "[email]"}
+	if !maps.Equal(group.Req, wantGroup) {
+		t.Errorf("req = %v; want %v", group.Req, wantGroup)
+	}
+
+	var failed struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &failed); err != nil {
+		t.Fatalf("decode %q: %v", lines[1], err)
+	}
+	if failed.Error != "send to [email]: timeout" {
+		t.Errorf("error = %q; want the email redacted", failed.Error)
+	}
+}
+
+func TestRedaction_ReflectedValues(t *testing.T) {
+	t.Parallel()
+
+	type event struct {
+		ID    uint64 `json:"id"`
+		Title string `json:"title"`
+		Text  string `json:"text"`
+	}
+	doctor := event{ID: 1<<53 + 1, Title: "Doctor", Text: "call bob@example.org"}
+
+	testCases := []struct {
+		desc string
+		log  func(log *logger.Adapter)
+		want string
+	}{
+		{"Struct", func(log *logger.Adapter) {
+			log.LogAttrs(context.Background(), logger.InfoLevel, "msg", logger.Any("events", doctor))
+		}, `{"id":9007199254740993,"text":"call [email]","title":"24669ff48290"}`},
+		{"Pointer", func(log *logger.Adapter) {
+			log.Infow("msg", "events", &doctor)
+		}, `{"id":9007199254740993,"text":"call [email]","title":"24669ff48290"}`},
+		{"SliceOfStructs", func(log *logger.Adapter) {
+			log.LogAttrs(context.Background(), logger.InfoLevel, "msg",
+				logger.Slice("events", []event{doctor, {ID: 2, Title: "Gym"}}))
+		}, `[{"id":9007199254740993,"text":"call [email]","title":"24669ff48290"},` +
+			`{"id":2,"text":"","title":"fbeb6d88367e"}]`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			log, out := newTestAdapter(t, config.Logger{
+				Level:           "info",
+				RedactKeys:      _redactRules,
+				RedactDetectors: "email",
+			})
+			tc.log(log)
+
+			var entry struct {
+				Events json.RawMessage `json:"events"`
+			}
+			if err := json.Unmarshal(bytes.TrimSpace(out.Bytes()), &entry); err != nil {
+				t.Fatalf("decode %q: %v", out, err)
+			}
+			if string(entry.Events) != tc.want {
+				t.Errorf("events = %s; want %s", entry.Events, tc.want)
+			}
+		})
+	}
+}
+
+func TestRedaction_LeavesOtherValues(t *testing.T) {
+	t.Parallel()
+
+	log, out := newTestAdapter(t, config.Logger{Level: "info", RedactDetectors: "email,phone"})
+	log.Infow("msg",
+		"date", "2026-10-20T10:00:00Z",
+		"request_id", "63a3f95c-f43a-417b-953a-3d33e95a1767",
+		"user_id", 1234567890,
+	)
+
+	got := decodeFields(t, out)
+	if got["date"] != "2026-10-20T10:00:00Z" || got["request_id"] != "63a3f95c-f43a-417b-953a-3d33e95a1767" ||
+		got["user_id"] != float64(1234567890) {
+		t.Errorf("fields = %v; want dates, IDs and numbers unchanged", got)
+	}
+}
+
+func TestParseRedactKeys(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc      string
+		input     string
+		want      map[string]logger.RedactAction
+		wantError bool
+	}{
+		{"Empty", "", map[string]logger.RedactAction{}, false},
+		{"CaseAndSpaces", " Client_IP = MASK ,title=hash", map[string]logger.RedactAction{
+			"client_ip": logger.RedactMask,
+			"title":     logger.RedactHash,
+		}, false},
+		{"UnknownAction", "title=encrypt", nil, true},
+		{"NoSeparator", "title", nil, true},
+		{"MissingKey", "=drop", nil, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := logger.ParseRedactKeys(tc.input)
+			if (err != nil) != tc.wantError {
+				t.Fatalf("ParseRedactKeys(%q) error = %v; wantError %v", tc.input, err, tc.wantError)
+			}
+			if !tc.wantError && !maps.Equal(got, tc.want) {
+				t.Errorf("ParseRedactKeys(%q) = %v; want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewAdapter_InvalidRedaction(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc string
+		cfg  config.Logger
+	}{
+		{"Keys", config.Logger{Level: "info", RedactKeys: "title=encrypt"}},
+		{"Detectors", config.Logger{Level: "info", RedactDetectors: "email,ssn"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := logger.NewAdapter(&config.Config{Logger: tc.cfg}, logger.Output(&bytes.Buffer{})); err == nil {
+				t.Errorf("NewAdapter() error = nil; want error")
+			}
+		})
+	}
+}
+
+func decodeFields(t *testing.T, out *bytes.Buffer) map[string]any {
+	t.Helper()
+
+	var fields map[string]any
+	if err := json.Unmarshal(bytes.TrimSpace(out.Bytes()), &fields); err != nil {
+		t.Fatalf("decode %q: %v", out, err)
+	}
+	return fields
+}
@@ -0,0 +1,168 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type (
+	// Ring keeps the most recent log entries in memory, for the admin logs
+	// endpoint and for tests asserting on what was logged.
+	Ring struct {
+		mu      sync.Mutex
+		entries []RingEntry
+		next    int
+		full    bool
+	}
+
+	RingEntry struct {
+		Time    time.Time      `json:"time"`
+		Level   string         `json:"level"`
+		Message string         `json:"message"`
+		Caller  string         `json:"caller,omitempty"`
+		Fields  map[string]any `json:"fields,omitempty"`
+	}
+
+	ringCore struct {
+		ring   *Ring
+		fields []zapcore.Field
+	}
+)
+
+func NewRing(size int) *Ring {
+	if size <= 0 {
+		size = _defaultRingSize
+	}
+	return &Ring{entries: make([]RingEntry, size)}
+}
+
+// Entries returns up to limit of the newest entries at or above minLevel,
+// oldest first. A non-positive limit returns all of them.
+func (r *Ring) Entries(limit int, minLevel Level) []RingEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := r.next
+	if r.full {
+		n = len(r.entries)
+	}
+
+	var matched []RingEntry
+	for i := 1; i <= n; i++ {
+		e := r.entries[(r.next-i+len(r.entries))%len(r.entries)]
+		if level, err := ParseLevel(e.Level); err == nil && level < minLevel {
+			continue
+		}
+		matched = append(matched, e)
+		if limit > 0 && len(matched) == limit {
+			break
+		}
+	}
+
+	for i, j := 0, len(matched)-1; i < j; i, j = i+1, j-1 {
+		matched[i], matched[j] = matched[j], matched[i]
+	}
+	return matched
+}
+
+func (r *Ring) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.full {
+		return len(r.entries)
+	}
+	return r.next
+}
+
+func (r *Ring) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	clear(r.entries)
+	r.next, r.full = 0, false
+}
+
+// DeleteFunc removes the entries del reports true for, keeping the order of
+// the others, and returns how many were removed.
+func (r *Ring) DeleteFunc(del func(RingEntry) bool) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n, start := r.next, 0
+	if r.full {
+		n, start = len(r.entries), r.next
+	}
+
+	kept := make([]RingEntry, 0, n)
+	for i := range n {
+		if e := r.entries[(start+i)%len(r.entries)]; !del(e) {
+			kept = append(kept, e)
+		}
+	}
+
+	clear(r.entries)
+	copy(r.entries, kept)
+	r.next = len(kept) % len(r.entries)
+	r.full = len(kept) == len(r.entries)
+	return n - len(kept)
+}
+
+func (r *Ring) add(e RingEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+func newRingCore(ring *Ring) *ringCore {
+	return &ringCore{ring: ring}
+}
+
+func (c *ringCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c *ringCore) With(fields []zapcore.Field) zapcore.Core {
+	return &ringCore{ring: c.ring, fields: append(c.fields[:len(c.fields):len(c.fields)], fields...)}
+}
+
+func (c *ringCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+func (c *ringCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range c.fields {
+		field.AddTo(enc)
+	}
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+
+	e := RingEntry{
+		Time:    ent.Time,
+		Level:   strings.ToLower(fromZapLevel(ent.Level).String()),
+		Message: ent.Message,
+	}
+	if ent.Caller.Defined {
+		e.Caller = ent.Caller.TrimmedPath()
+	}
+	if len(enc.Fields) > 0 {
+		e.Fields = enc.Fields
+	}
+
+	c.ring.add(e)
+	return nil
+}
+
+func (c *ringCore) Sync() error {
+	return nil
+}
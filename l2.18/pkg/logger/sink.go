@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"calendar-wbf/internal/config"
+
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Sinks selectable with LOGGER_SINKS.
+const (
+	SinkFile    = "file"
+	SinkStdout  = "stdout"
+	SinkConsole = "console"
+	SinkSyslog  = "syslog"
+	SinkRing    = "ring"
+
+	_defaultRingSize = 1000
+)
+
+// ParseSinks parses a comma-separated sink list such as "file,stdout".
+func ParseSinks(s string) ([]string, error) {
+	var sinks []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "":
+			continue
+		case SinkFile, SinkStdout, SinkConsole, SinkSyslog, SinkRing:
+			if !slices.Contains(sinks, name) {
+				sinks = append(sinks, name)
+			}
+		default:
+			return nil, fmt.Errorf("logger.ParseSinks: unknown sink %q", name)
+		}
+	}
+
+	if len(sinks) == 0 {
+		return nil, fmt.Errorf("logger.ParseSinks: no sinks in %q", s)
+	}
+	return sinks, nil
+}
+
+// sinkCores builds one core per sink. Every core accepts all levels; level
+// checks and sampling wrap the tee of them.
+func (l *ZapLogger) sinkCores(cfg *config.Config, encoderConfig zapcore.EncoderConfig) ([]zapcore.Core, error) {
+	if l.writer != nil {
+		return []zapcore.Core{jsonCore(encoderConfig, l.writer)}, nil
+	}
+
+	cores := make([]zapcore.Core, 0, len(l.sinks))
+	for _, sink := range l.sinks {
+		switch sink {
+		case SinkFile:
+			cores = append(cores, jsonCore(encoderConfig, &lumberjack.Logger{
+				Filename:   cfg.Logger.Filename,
+				MaxSize:    l.maxSize,
+				MaxBackups: l.maxBackups,
+				MaxAge:     l.maxAge,
+				Compress:   true,
+			}))
+		case SinkStdout:
+			cores = append(cores, jsonCore(encoderConfig, os.Stdout))
+		case SinkConsole:
+			cores = append(cores, consoleCore(encoderConfig, os.Stdout))
+		case SinkSyslog:
+			core, err := newSyslogCore(encoderConfig, cfg.Logger.SyslogNetwork, cfg.Logger.SyslogAddr, cfg.App.Name)
+			if err != nil {
+				return nil, err
+			}
+			cores = append(cores, core)
+		case SinkRing:
+			l.ring = NewRing(l.ringSize)
+			cores = append(cores, newRingCore(l.ring))
+		}
+	}
+	return cores, nil
+}
+
+func jsonCore(encoderConfig zapcore.EncoderConfig, w io.Writer) zapcore.Core {
+	return zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(w), zapcore.DebugLevel)
+}
+
+// consoleCore pretty-prints entries for reading in a terminal during local
+// development: aligned columns, colored levels, local time.
+func consoleCore(encoderConfig zapcore.EncoderConfig, w io.Writer) zapcore.Core {
+	encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	encoderConfig.EncodeTime = zapcore.TimeEncoderOfLayout("15:04:05.000")
+	encoderConfig.ConsoleSeparator = "  "
+
+	return zapcore.NewCore(zapcore.NewConsoleEncoder(encoderConfig), zapcore.AddSync(w), zapcore.DebugLevel)
+}
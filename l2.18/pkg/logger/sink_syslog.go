@@ -0,0 +1,70 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"fmt"
+	"log/syslog"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// syslogCore sends JSON-encoded entries to syslog with a severity matching
+// the entry level.
+type syslogCore struct {
+	enc zapcore.Encoder
+	w   *syslog.Writer
+}
+
+// newSyslogCore dials the syslog daemon at addr over network ("udp",
+// "tcp"); an empty network uses the local daemon.
+func newSyslogCore(encoderConfig zapcore.EncoderConfig, network, addr, tag string) (zapcore.Core, error) {
+	w, err := syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, fmt.Errorf("logger.newSyslogCore: %w", err)
+	}
+
+	// Syslog stamps entries itself.
+	encoderConfig.TimeKey = zapcore.OmitKey
+	return &syslogCore{enc: zapcore.NewJSONEncoder(encoderConfig), w: w}, nil
+}
+
+func (c *syslogCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	return &syslogCore{enc: enc, w: c.w}
+}
+
+func (c *syslogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+func (c *syslogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	msg := buf.String()
+	buf.Free()
+
+	switch {
+	case ent.Level <= zapcore.DebugLevel:
+		return c.w.Debug(msg)
+	case ent.Level == zapcore.InfoLevel:
+		return c.w.Info(msg)
+	case ent.Level == zapcore.WarnLevel:
+		return c.w.Warning(msg)
+	default:
+		return c.w.Err(msg)
+	}
+}
+
+func (c *syslogCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,123 @@
+package logger_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"calendar-wbf/internal/config"
+	"calendar-wbf/pkg/logger"
+)
+
+func TestParseSinks(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc      string
+		input     string
+		want      []string
+		wantError bool
+	}{
+		{"Default", "file,stdout", []string{"file", "stdout"}, false},
+		{"SpacesAndCase", " Console , ring ", []string{"console", "ring"}, false},
+		{"Duplicates", "ring,ring,file", []string{"ring", "file"}, false},
+		{"Unknown", "file,kafka", nil, true},
+		{"Empty", " , ", nil, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := logger.ParseSinks(tc.input)
+			if (err != nil) != tc.wantError {
+				t.Fatalf("ParseSinks(%q) error = %v; wantError %v", tc.input, err, tc.wantError)
+			}
+			if !tc.wantError && !slices.Equal(got, tc.want) {
+				t.Errorf("ParseSinks(%q) = %q; want %q", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRingSink(t *testing.T) {
+	t.Parallel()
+
+	log, err := logger.NewAdapter(&config.Config{Logger: config.Logger{Level: "debug"}},
+		logger.Sinks(logger.SinkRing),
+		logger.RingSize(3),
+	)
+	if err != nil {
+		t.Fatalf("NewAdapter() error = %v", err)
+	}
+
+	ring := logger.RingOf(log)
+	if ring == nil {
+		t.Fatal("RingOf() = nil; want the ring sink")
+	}
+
+	log.Debugw("one")
+	log.Warnw("two", "n", 2)
+	log.With("component", "cache").Infow("three")
+	log.LogAttrs(context.Background(), logger.ErrorLevel, "four")
+
+	if ring.Len() != 3 {
+		t.Errorf("Len() = %d; want 3 after wraparound", ring.Len())
+	}
+
+	testCases := []struct {
+		desc     string
+		limit    int
+		minLevel logger.Level
+		want     []string
+	}{
+		{"All", 0, logger.DebugLevel, []string{"two", "three", "four"}},
+		{"Limit", 2, logger.DebugLevel, []string{"three", "four"}},
+		{"MinLevel", 0, logger.WarnLevel, []string{"two", "four"}},
+		{"LimitAndMinLevel", 1, logger.WarnLevel, []string{"four"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var got []string
+			for _, e := range ring.Entries(tc.limit, tc.minLevel) {
+				got = append(got, e.Message)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("Entries(%d, %v) = %q; want %q", tc.limit, tc.minLevel, got, tc.want)
+			}
+		})
+	}
+
+	entries := ring.Entries(0, logger.DebugLevel)
+	if entries[0].Fields["n"] != int64(2) || entries[1].Fields["component"] != "cache" {
+		t.Errorf("fields = %v, %v; want n and component", entries[0].Fields, entries[1].Fields)
+	}
+
+	if removed := ring.DeleteFunc(func(e logger.RingEntry) bool { return e.Message == "three" }); removed != 1 {
+		t.Errorf("DeleteFunc() = %d; want 1", removed)
+	}
+	log.Infow("five")
+	log.Infow("six")
+	var got []string
+	for _, e := range ring.Entries(0, logger.DebugLevel) {
+		got = append(got, e.Message)
+	}
+	if want := []string{"four", "five", "six"}; !slices.Equal(got, want) {
+		t.Errorf("Entries() after DeleteFunc = %q; want %q", got, want)
+	}
+
+	ring.Reset()
+	if got := ring.Entries(0, logger.DebugLevel); len(got) != 0 {
+		t.Errorf("Entries() after Reset = %v; want none", got)
+	}
+}
+
+func TestRingOf_WithoutRingSink(t *testing.T) {
+	t.Parallel()
+
+	log, _ := newTestAdapter(t, config.Logger{Level: "info"})
+	if ring := logger.RingOf(log); ring != nil {
+		t.Errorf("RingOf() = %v; want nil without the ring sink", ring)
+	}
+}
@@ -0,0 +1,172 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type (
+	// slogHandler is a slog.Handler writing through a ZapLogger, so code using
+	// log/slog shares our sinks, levels, sampling and request IDs.
+	slogHandler struct {
+		zapLogger *ZapLogger
+		// root is the logger before any attributes, so a request ID can be
+		// added at the top level even after WithGroup.
+		root   *zap.Logger
+		fields []zap.Field
+	}
+
+	slogGroup []slog.Attr
+)
+
+// Handler returns a slog.Handler backed by this logger. Pass it to
+// slog.SetDefault to route log/slog and the standard log package through it.
+func (a *Adapter) Handler() slog.Handler {
+	return &slogHandler{zapLogger: a.zapLogger, root: a.zapLogger.logger}
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.root.Core().Enabled(fromSlogLevel(level))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, record slog.Record) error {
+	logger := h.zapLogger.logger
+	if fields := h.zapLogger.contextFields(ctx); len(fields) > 0 {
+		logger = h.root.With(append(fields, h.fields...)...)
+	}
+
+	ce := logger.Check(fromSlogLevel(record.Level), record.Message)
+	if ce == nil {
+		return nil
+	}
+
+	if !record.Time.IsZero() {
+		ce.Time = record.Time
+	}
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		ce.Caller = zapcore.EntryCaller{
+			Defined:  true,
+			PC:       record.PC,
+			File:     frame.File,
+			Line:     frame.Line,
+			Function: frame.Function,
+		}
+	}
+
+	fields := make([]zap.Field, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = appendSlogAttr(fields, attr)
+		return true
+	})
+
+	ce.Write(fields...)
+	return nil
+}
+
+// WithAttrs follows a "component" attribute's level override the same way
+// Adapter.With does.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	root := h.root
+
+	fields := make([]zap.Field, 0, len(attrs))
+	for _, attr := range attrs {
+		if attr.Key == _componentKey && attr.Value.Kind() == slog.KindString {
+			root = h.zapLogger.levels.withComponentLevel(root, attr.Value.String())
+		}
+		fields = appendSlogAttr(fields, attr)
+	}
+
+	return h.derive(root, fields)
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.derive(h.root, []zap.Field{zap.Namespace(name)})
+}
+
+func (h *slogHandler) derive(root *zap.Logger, fields []zap.Field) *slogHandler {
+	all := append(h.fields[:len(h.fields):len(h.fields)], fields...)
+
+	return &slogHandler{
+		zapLogger: &ZapLogger{
+			logger: root.With(all...),
+			levels: h.zapLogger.levels,
+			ring:   h.zapLogger.ring,
+		},
+		root:   root,
+		fields: all,
+	}
+}
+
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, field := range appendSlogAttrs(nil, g) {
+		field.AddTo(enc)
+	}
+	return nil
+}
+
+func appendSlogAttrs(fields []zap.Field, attrs []slog.Attr) []zap.Field {
+	for _, attr := range attrs {
+		fields = appendSlogAttr(fields, attr)
+	}
+	return fields
+}
+
+// appendSlogAttr converts attr following the slog.Handler rules: values are
+// resolved, empty attributes are dropped and groups without a key are
+// inlined.
+func appendSlogAttr(fields []zap.Field, attr slog.Attr) []zap.Field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	value := attr.Value
+	switch value.Kind() {
+	case slog.KindString:
+		return append(fields, zap.String(attr.Key, value.String()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(attr.Key, value.Int64()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(attr.Key, value.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(attr.Key, value.Float64()))
+	case slog.KindBool:
+		return append(fields, zap.Bool(attr.Key, value.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(attr.Key, value.Duration()))
+	case slog.KindTime:
+		return append(fields, zap.Time(attr.Key, value.Time()))
+	case slog.KindGroup:
+		group := value.Group()
+		if len(group) == 0 {
+			return fields
+		}
+		if attr.Key == "" {
+			return appendSlogAttrs(fields, group)
+		}
+		return append(fields, zap.Object(attr.Key, slogGroup(group)))
+	default:
+		return append(fields, zap.Any(attr.Key, value.Any()))
+	}
+}
+
+func fromSlogLevel(level slog.Level) zapcore.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return zapcore.DebugLevel
+	case level < slog.LevelWarn:
+		return zapcore.InfoLevel
+	case level < slog.LevelError:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
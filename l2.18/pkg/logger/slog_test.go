@@ -0,0 +1,75 @@
+package logger_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"calendar-wbf/internal/config"
+)
+
+func TestHandler_WritesThroughAdapter(t *testing.T) {
+	t.Parallel()
+
+	log, out := newTestAdapter(t, config.Logger{Level: "info"})
+	slogger := slog.New(log.Handler())
+
+	ctx := log.WithRequestID(context.Background(), "req-1")
+	slogger.DebugContext(ctx, "dropped")
+	slogger.With("user", 7).WithGroup("http").InfoContext(ctx, "request",
+		"method", "GET",
+		slog.Group("client", "ip", "127.0.0.1"),
+		slog.Group("empty"),
+	)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d entries; want 1:\n%s", len(lines), out)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatalf("decode %q: %v", lines[0], err)
+	}
+
+	if got["msg"] != "request" || got["level"] != "info" || got["request_id"] != "req-1" {
+		t.Errorf("entry = %v; want info \"request\" with request_id", got)
+	}
+	if got["user"] != float64(7) {
+		t.Errorf("user = %v; want 7", got["user"])
+	}
+	if caller, _ := got["caller"].(string); !strings.HasPrefix(caller, "logger/slog_test.go:") {
+		t.Errorf("caller = %v; want the call site in slog_test.go", got["caller"])
+	}
+
+	group, _ := got["http"].(map[string]any)
+	client, _ := group["client"].(map[string]any)
+	if group["method"] != "GET" || client["ip"] != "127.0.0.1" {
+		t.Errorf("http = %v; want method and nested client group", got["http"])
+	}
+	if _, ok := group["empty"]; ok {
+		t.Errorf("http = %v; want empty group dropped", group)
+	}
+}
+
+func TestHandler_ComponentLevel(t *testing.T) {
+	t.Parallel()
+
+	log, out := newTestAdapter(t, config.Logger{Level: "warn", Levels: "stdlib=debug"})
+	handler := log.Handler()
+
+	slog.New(handler).Info("root info")
+	component := slog.New(handler).With("component", "stdlib")
+	component.Debug("component debug")
+
+	if !component.Enabled(context.Background(), slog.LevelDebug) {
+		t.Errorf("Enabled(debug) = false; want true for the overridden component")
+	}
+
+	want := []string{"component debug"}
+	if got := messages(t, out); len(got) != 1 || got[0] != want[0] {
+		t.Errorf("messages = %q; want %q", got, want)
+	}
+}
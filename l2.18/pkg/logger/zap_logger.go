@@ -2,29 +2,51 @@ package logger
 
 import (
 	"fmt"
-	"os"
+	"io"
+	"time"
 
 	"calendar-wbf/internal/config"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 const (
 	_defaultMaxSize    = 100
 	_defaultMaxBackups = 7
 	_defaultMaxAge     = 30
+	_defaultSinks      = SinkFile + "," + SinkStdout
+
+	// Adapter methods wrap the zap calls, so callers are one frame up.
+	_adapterCallerSkip = 1
 )
 
-type ZapLogger struct {
-	logger *zap.Logger
-	level  zapcore.Level
+type (
+	ZapLogger struct {
+		logger *zap.Logger
+		levels *levels
 
-	maxSize    int
-	maxBackups int
-	maxAge     int
-}
+		maxSize    int
+		maxBackups int
+		maxAge     int
+		sampling   sampling
+		overrides  map[string]Level
+		sinks      []string
+		redactKeys map[string]RedactAction
+		detectors  []string
+		ringSize   int
+		ring       *Ring
+		writer     io.Writer
+	}
+
+	// sampling keeps the first Initial entries with the same level and
+	// message per Tick, then every Thereafter-th. Zero Initial disables it.
+	sampling struct {
+		initial    int
+		thereafter int
+		tick       time.Duration
+	}
+)
 
 func NewZapLogger(cfg *config.Config, opts ...Option) (*ZapLogger, error) {
 	encoderConfig := zapcore.EncoderConfig{
@@ -41,54 +63,147 @@ func NewZapLogger(cfg *config.Config, opts ...Option) (*ZapLogger, error) {
 		EncodeCaller:  zapcore.ShortCallerEncoder,
 	}
 
+	initialLevel, err := ParseLevel(cfg.Logger.Level)
+	if err != nil {
+		return nil, fmt.Errorf("logger.newZapLogger: %w", err)
+	}
+
+	overrides, err := ParseLevels(cfg.Logger.Levels)
+	if err != nil {
+		return nil, fmt.Errorf("logger.newZapLogger: %w", err)
+	}
+
+	sinks, err := ParseSinks(orDefaultString(cfg.Logger.Sinks, _defaultSinks))
+	if err != nil {
+		return nil, fmt.Errorf("logger.newZapLogger: %w", err)
+	}
+
+	redactKeys, err := ParseRedactKeys(cfg.Logger.RedactKeys)
+	if err != nil {
+		return nil, fmt.Errorf("logger.newZapLogger: %w", err)
+	}
+
+	detectors, err := ParseDetectors(cfg.Logger.RedactDetectors)
+	if err != nil {
+		return nil, fmt.Errorf("logger.newZapLogger: %w", err)
+	}
+
 	logger := &ZapLogger{
-		maxSize:    _defaultMaxSize,
-		maxBackups: _defaultMaxBackups,
-		maxAge:     _defaultMaxAge,
-		level:      zapcore.InfoLevel,
+		levels:     newLevels(initialLevel),
+		maxSize:    orDefault(cfg.Logger.MaxSize, _defaultMaxSize),
+		maxBackups: cfg.Logger.MaxBackups,
+		maxAge:     orDefault(cfg.Logger.MaxAge, _defaultMaxAge),
+		sampling: sampling{
+			initial:    cfg.Logger.SamplingInitial,
+			thereafter: cfg.Logger.SamplingThereafter,
+			tick:       cfg.Logger.SamplingTick,
+		},
+		overrides:  overrides,
+		sinks:      sinks,
+		redactKeys: redactKeys,
+		detectors:  detectors,
+		ringSize:   orDefault(cfg.Logger.RingSize, _defaultRingSize),
 	}
 
 	for _, opt := range opts {
 		opt(logger)
 	}
 
-	lumberSync := &lumberjack.Logger{
-		Filename:   cfg.Logger.Filename,
-		MaxSize:    logger.maxSize,
-		MaxBackups: logger.maxBackups,
-		MaxAge:     logger.maxAge,
-		Compress:   true,
-	}
-
 	if err := logger.validate(); err != nil {
 		return nil, fmt.Errorf("logger.newZapLogger: validation: %w", err)
 	}
+	logger.levels.set(logger.overrides)
 
-	core := zapcore.NewCore(
-		zapcore.NewJSONEncoder(encoderConfig),
-		zapcore.NewMultiWriteSyncer(
-			zapcore.AddSync(lumberSync),
-			zapcore.AddSync(os.Stdout),
+	cores, err := logger.sinkCores(cfg, encoderConfig)
+	if err != nil {
+		return nil, fmt.Errorf("logger.newZapLogger: %w", err)
+	}
+
+	redactor, err := newRedactor(logger.redactKeys, logger.detectors)
+	if err != nil {
+		return nil, fmt.Errorf("logger.newZapLogger: %w", err)
+	}
+
+	// The sink cores accept every level; levelCore applies the root level or
+	// a component override on top of them. Redaction runs once for all sinks,
+	// including fields added with With.
+	core := redactor.wrap(zapcore.NewTee(cores...))
+
+	logger.logger = zap.New(
+		&levelCore{Core: logger.sample(core), enabler: logger.levels.enabler("")},
+		zap.Fields(
+			zap.String("service", cfg.App.Name),
+			zap.String("env", cfg.Env),
 		),
-		zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-			return lvl >= logger.level
-		}),
+		zap.AddCaller(),
+		zap.AddCallerSkip(_adapterCallerSkip),
+		zap.AddStacktrace(zap.ErrorLevel),
 	)
 
-	logger = &ZapLogger{
-		logger: zap.New(core,
-			zap.Fields(
-				zap.String("service", cfg.App.Name),
-				zap.String("env", cfg.Env),
-			),
-			zap.AddCaller(),
-			zap.AddStacktrace(zap.ErrorLevel),
-		),
+	return logger, nil
+}
+
+// sample drops repeats of high-volume debug and info messages such as the
+// per-request access log. Warnings and errors are never sampled.
+func (l *ZapLogger) sample(core zapcore.Core) zapcore.Core {
+	if l.sampling.initial <= 0 {
+		return core
 	}
 
-	return logger, nil
+	sampled := zapcore.NewSamplerWithOptions(core, l.sampling.tick, l.sampling.initial, l.sampling.thereafter)
+
+	return zapcore.NewTee(
+		&levelCore{Core: sampled, enabler: zap.LevelEnablerFunc(func(level zapcore.Level) bool {
+			return level < zapcore.WarnLevel
+		})},
+		&levelCore{Core: core, enabler: zap.LevelEnablerFunc(func(level zapcore.Level) bool {
+			return level >= zapcore.WarnLevel
+		})},
+	)
+}
+
+func (l *ZapLogger) SetLevel(level Level) {
+	l.levels.root.SetLevel(toZapLevel(level))
+}
+
+func (l *ZapLogger) Level() Level {
+	return fromZapLevel(l.levels.root.Level())
+}
+
+// SetComponentLevel overrides the level of loggers created With the given
+// component.
+func (l *ZapLogger) SetComponentLevel(component string, level Level) {
+	l.levels.setOne(component, level)
+}
+
+// SetComponentLevels replaces every component override.
+func (l *ZapLogger) SetComponentLevels(overrides map[string]Level) {
+	l.levels.set(overrides)
+}
+
+func (l *ZapLogger) ComponentLevels() map[string]Level {
+	return l.levels.snapshot()
 }
 
 func (l *ZapLogger) Zap() *zap.Logger {
 	return l.logger
 }
+
+// Ring returns the in-memory sink, or nil when the "ring" sink is disabled.
+func (l *ZapLogger) Ring() *Ring {
+	return l.ring
+}
+
+func orDefault(value, fallback int) int {
+	if value == 0 {
+		return fallback
+	}
+	return value
+}
+
+func orDefaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,304 @@
+// Package markdown renders the Markdown subset used in event descriptions:
+// paragraphs, ATX headings, block quotes, lists, fenced code, rules, emphasis,
+// inline code and links. Raw HTML in the source is escaped, never passed
+// through, and links are kept only for http, https and mailto URLs, so the
+// output is safe to embed in a page.
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	_maxHeadingLevel = 6
+	_fence           = "```"
+	_escapable       = "\\`*_[]()#>-+.!"
+)
+
+type blockKind int
+
+const (
+	blockNone blockKind = iota
+	blockParagraph
+	blockQuote
+	blockUnordered
+	blockOrdered
+)
+
+type renderer struct {
+	b     strings.Builder
+	kind  blockKind
+	lines []string
+}
+
+// Render converts src to HTML.
+func Render(src string) string {
+	var r renderer
+
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		trimmed := strings.TrimLeft(line, " ")
+
+		switch {
+		case strings.HasPrefix(trimmed, _fence):
+			r.flush()
+			i = r.code(lines, i+1)
+		case trimmed == "":
+			r.flush()
+		case isRule(trimmed):
+			r.flush()
+			r.b.WriteString("<hr>\n")
+		case headingLevel(trimmed) > 0:
+			r.flush()
+			level := headingLevel(trimmed)
+			tag := "h" + strconv.Itoa(level)
+			r.b.WriteString("<" + tag + ">")
+			r.inline(strings.TrimSpace(trimmed[level:]))
+			r.b.WriteString("</" + tag + ">\n")
+		case strings.HasPrefix(trimmed, ">"):
+			r.push(blockQuote, strings.TrimSpace(trimmed[1:]))
+		case listItem(trimmed, false) != "":
+			r.push(blockUnordered, listItem(trimmed, false))
+		case listItem(trimmed, true) != "":
+			r.push(blockOrdered, listItem(trimmed, true))
+		default:
+			if r.kind == blockUnordered || r.kind == blockOrdered {
+				// A lazy continuation line belongs to the last item.
+				r.lines[len(r.lines)-1] += "\n" + trimmed
+				continue
+			}
+			if r.kind == blockQuote {
+				r.lines = append(r.lines, trimmed)
+				continue
+			}
+			r.push(blockParagraph, trimmed)
+		}
+	}
+	r.flush()
+
+	return strings.TrimSuffix(r.b.String(), "\n")
+}
+
+// code writes a fenced block starting at lines[from] and returns the index
+// of the closing fence. An unclosed fence runs to the end of the text.
+func (r *renderer) code(lines []string, from int) int {
+	end := from
+	for end < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[end]), _fence) {
+		end++
+	}
+
+	r.b.WriteString("<pre><code>")
+	r.b.WriteString(html.EscapeString(strings.Join(lines[from:end], "\n")))
+	r.b.WriteString("</code></pre>\n")
+	return end
+}
+
+func (r *renderer) push(kind blockKind, line string) {
+	if r.kind != kind {
+		r.flush()
+		r.kind = kind
+	}
+	r.lines = append(r.lines, line)
+}
+
+func (r *renderer) flush() {
+	switch r.kind {
+	case blockParagraph:
+		r.b.WriteString("<p>")
+		r.inline(strings.Join(r.lines, "\n"))
+		r.b.WriteString("</p>\n")
+	case blockQuote:
+		r.b.WriteString("<blockquote><p>")
+		r.inline(strings.Join(r.lines, "\n"))
+		r.b.WriteString("</p></blockquote>\n")
+	case blockUnordered, blockOrdered:
+		tag := "ul"
+		if r.kind == blockOrdered {
+			tag = "ol"
+		}
+		r.b.WriteString("<" + tag + ">\n")
+		for _, item := range r.lines {
+			r.b.WriteString("<li>")
+			r.inline(item)
+			r.b.WriteString("</li>\n")
+		}
+		r.b.WriteString("</" + tag + ">\n")
+	case blockNone:
+	}
+
+	r.kind = blockNone
+	r.lines = r.lines[:0]
+}
+
+func (r *renderer) inline(s string) {
+	text := 0 // start of the plain text not written yet
+	for i := 0; i < len(s); {
+		out, n := span(s, i)
+		if n == 0 {
+			i++
+			continue
+		}
+
+		r.b.WriteString(html.EscapeString(s[text:i]))
+		r.b.WriteString(out)
+		i += n
+		text = i
+	}
+	r.b.WriteString(html.EscapeString(s[text:]))
+}
+
+// span renders the inline element starting at s[i] and returns it with its
+// length in s. Zero means no element starts at i.
+func span(s string, i int) (string, int) {
+	switch c := s[i]; {
+	case c == '\\' && i+1 < len(s) && strings.IndexByte(_escapable, s[i+1]) >= 0:
+		return html.EscapeString(s[i+1 : i+2]), 2
+	case c == '`':
+		if end := strings.IndexByte(s[i+1:], '`'); end >= 0 {
+			return "<code>" + html.EscapeString(s[i+1:i+1+end]) + "</code>", end + 2
+		}
+	case c == '*' || c == '_':
+		return emphasis(s, i)
+	case c == '[':
+		return link(s, i)
+	case c == 'h' && (i == 0 || !isWordByte(s[i-1])):
+		return autolink(s, i)
+	}
+	return "", 0
+}
+
+func emphasis(s string, i int) (string, int) {
+	c := s[i]
+	if c == '_' && i > 0 && isWordByte(s[i-1]) {
+		return "", 0
+	}
+
+	delim, tag := string(c), "em"
+	if i+1 < len(s) && s[i+1] == c {
+		delim, tag = strings.Repeat(string(c), 2), "strong"
+	}
+
+	body := s[i+len(delim):]
+	end := strings.Index(body, delim)
+	if end <= 0 || body[0] == ' ' || body[end-1] == ' ' {
+		return "", 0
+	}
+
+	var inner renderer
+	inner.inline(body[:end])
+	return "<" + tag + ">" + inner.b.String() + "</" + tag + ">", end + 2*len(delim)
+}
+
+func link(s string, i int) (string, int) {
+	closeText := strings.IndexByte(s[i:], ']')
+	if closeText < 0 || i+closeText+1 >= len(s) || s[i+closeText+1] != '(' {
+		return "", 0
+	}
+	closeURL := strings.IndexByte(s[i+closeText+2:], ')')
+	if closeURL < 0 {
+		return "", 0
+	}
+
+	text := s[i+1 : i+closeText]
+	target := strings.TrimSpace(s[i+closeText+2 : i+closeText+2+closeURL])
+	n := closeText + closeURL + 3
+
+	var inner renderer
+	inner.inline(text)
+	if !safeURL(target) {
+		return inner.b.String(), n
+	}
+	return anchor(target, inner.b.String()), n
+}
+
+func autolink(s string, i int) (string, int) {
+	rest := s[i:]
+	if !strings.HasPrefix(rest, "http://") && !strings.HasPrefix(rest, "https://") {
+		return "", 0
+	}
+
+	end := strings.IndexAny(rest, " \t\n<")
+	if end < 0 {
+		end = len(rest)
+	}
+	target := strings.TrimRight(rest[:end], ".,;:!?)'\"")
+	if !safeURL(target) {
+		return "", 0
+	}
+	return anchor(target, html.EscapeString(target)), len(target)
+}
+
+func anchor(href, text string) string {
+	return `<a href="` + html.EscapeString(href) + `" rel="nofollow noopener noreferrer">` + text + "</a>"
+}
+
+func safeURL(raw string) bool {
+	if raw == "" || strings.ContainsAny(raw, " \t\n") {
+		return false
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return u.Host != ""
+	case "mailto":
+		return u.Opaque != ""
+	default:
+		return false
+	}
+}
+
+func headingLevel(line string) int {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > _maxHeadingLevel || (level < len(line) && line[level] != ' ') {
+		return 0
+	}
+	return level
+}
+
+func isRule(line string) bool {
+	compact := strings.ReplaceAll(line, " ", "")
+	if len(compact) < 3 {
+		return false
+	}
+	for _, marker := range []string{"-", "*", "_"} {
+		if strings.Trim(compact, marker) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// listItem returns the text of a list item line, or "" if line is not an
+// item of the requested kind.
+func listItem(line string, ordered bool) string {
+	if !ordered {
+		if len(line) > 1 && strings.IndexByte("-*+", line[0]) >= 0 && line[1] == ' ' {
+			return strings.TrimSpace(line[2:])
+		}
+		return ""
+	}
+
+	digits := 0
+	for digits < len(line) && line[digits] >= '0' && line[digits] <= '9' {
+		digits++
+	}
+	if digits == 0 || digits+1 >= len(line) || line[digits] != '.' || line[digits+1] != ' ' {
+		return ""
+	}
+	return strings.TrimSpace(line[digits+2:])
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,81 @@
+package markdown
+
+import "testing"
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc string
+		src  string
+		want string
+	}{
+		{"Plain", "Обсудить бюджет", "<p>Обсудить бюджет</p>"},
+		{"Paragraphs", "one\ntwo\n\nthree", "<p>one\ntwo</p>\n<p>three</p>"},
+		{"Heading", "## Agenda", "<h2>Agenda</h2>"},
+		{"NotHeading", "#hashtag", "<p>#hashtag</p>"},
+		{"Emphasis", "**bold** and *it* and _it_", "<p><strong>bold</strong> and <em>it</em> and <em>it</em></p>"},
+		{"SnakeCase", "user_id and snake_case_name", "<p>user_id and snake_case_name</p>"},
+		{"Unclosed", "2 * 3", "<p>2 * 3</p>"},
+		{"InlineCode", "run `go test <pkg>`", "<p>run <code>go test &lt;pkg&gt;</code></p>"},
+		{"Escape", `\*not em\*`, "<p>*not em*</p>"},
+		{
+			"Link", "[docs](https://example.com/a?b=1&c=2)",
+			`<p><a href="https://example.com/a?b=1&amp;c=2" rel="nofollow noopener noreferrer">docs</a></p>`,
+		},
+		{
+			"Autolink", "see https://example.com/x.",
+			`<p>see <a href="https://example.com/x" rel="nofollow noopener noreferrer">https://example.com/x</a>.</p>`,
+		},
+		{
+			"Mailto", "[mail](mailto:anna@example.com)",
+			`<p><a href="mailto:anna@example.com" rel="nofollow noopener noreferrer">mail</a></p>`,
+		},
+		{"Unordered", "- one\n- **two**", "<ul>\n<li>one</li>\n<li><strong>two</strong></li>\n</ul>"},
+		{"Ordered", "1. one\n2. two", "<ol>\n<li>one</li>\n<li>two</li>\n</ol>"},
+		{"Quote", "> said\n> twice", "<blockquote><p>said\ntwice</p></blockquote>"},
+		{"Fence", "```\n<b>x</b>\n```\nafter", "<pre><code>&lt;b&gt;x&lt;/b&gt;</code></pre>\n<p>after</p>"},
+		{"Rule", "a\n\n---\n\nb", "<p>a</p>\n<hr>\n<p>b</p>"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if got := Render(tc.src); got != tc.want {
+				t.Errorf("Render(%q) =\n%s\nwant\n%s", tc.src, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRender_Sanitizes(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		desc string
+		src  string
+		want string
+	}{
+		{"Script", "<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>"},
+		{"JavaScriptURL", "[click](javascript:alert(1))", "<p>click)</p>"},
+		{"DataURL", "[img](data:text/html;base64,PHNjcmlwdD4=)", "<p>img</p>"},
+		{"RelativeURL", "[up](../admin)", "<p>up</p>"},
+		{
+			"QuoteInHref", `[x](https://example.com/"onmouseover="alert(1))`,
+			`<p><a href="https://example.com/&#34;onmouseover=&#34;alert(1" ` +
+				`rel="nofollow noopener noreferrer">x</a>)</p>`,
+		},
+		{"Attribute", `<img src=x onerror="alert(1)">`, "<p>&lt;img src=x onerror=&#34;alert(1)&#34;&gt;</p>"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if got := Render(tc.src); got != tc.want {
+				t.Errorf("Render(%q) =\n%s\nwant\n%s", tc.src, got, tc.want)
+			}
+		})
+	}
+}
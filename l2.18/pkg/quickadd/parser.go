@@ -0,0 +1,634 @@
+package quickadd
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	_defaultDuration = time.Hour
+	_day             = 24 * time.Hour
+	_hoursPerHalfDay = 12
+	_daysPerWeek     = 7
+	_minutesPerHour  = 60
+	_maxHour         = 23
+	_maxMinute       = 59
+	_twoDigitYear    = 100
+	_century         = 2000
+)
+
+type Lang string
+
+const (
+	LangEnglish Lang = "en"
+	LangRussian Lang = "ru"
+)
+
+var (
+	ErrEmptyInput = errors.New("quickadd: empty input")
+	ErrEmptyTitle = errors.New("quickadd: no title left after extracting date and time")
+	ErrBadTime    = errors.New("quickadd: time of day out of range")
+)
+
+type (
+	Result struct {
+		Title    string
+		Start    time.Time
+		Duration time.Duration
+		AllDay   bool
+		Lang     Lang
+	}
+
+	Option func(*Parser)
+
+	Parser struct {
+		now             func() time.Time
+		defaultDuration time.Duration
+		rules           []rule
+	}
+
+	// parsed accumulates what the rules extracted from a single input.
+	parsed struct {
+		now      time.Time
+		date     *time.Time
+		hour     int
+		minute   int
+		hasTime  bool
+		duration time.Duration
+		absolute *time.Time
+		// badTime is set when an unambiguous time of day such as "25:00"
+		// is out of range.
+		badTime bool
+	}
+
+	rule struct {
+		re    *regexp.Regexp
+		apply func(p *parsed, g map[string]string) bool
+	}
+)
+
+func WithClock(now func() time.Time) Option {
+	return func(p *Parser) {
+		p.now = now
+	}
+}
+
+func WithDefaultDuration(d time.Duration) Option {
+	return func(p *Parser) {
+		p.defaultDuration = d
+	}
+}
+
+func NewParser(opts ...Option) *Parser {
+	p := &Parser{
+		now:             time.Now,
+		defaultDuration: _defaultDuration,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p.rules = buildRules()
+
+	return p
+}
+
+// Parse extracts the start, duration and title of an event from a free-form
+// English or Russian phrase, resolving relative dates in loc. Phrases without
+// a time of day produce an all-day result starting at midnight and lasting
+// whole days. An out-of-range time such as "25:00" fails with ErrBadTime.
+func (p *Parser) Parse(input string, loc *time.Location) (Result, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return Result{}, ErrEmptyInput
+	}
+
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	state := &parsed{now: p.now().In(loc)}
+	rest := input
+
+	for _, r := range p.rules {
+		rest = r.consume(rest, state)
+	}
+	if state.badTime {
+		return Result{}, ErrBadTime
+	}
+
+	title := cleanTitle(rest)
+	if title == "" {
+		return Result{}, ErrEmptyTitle
+	}
+
+	result := Result{
+		Title:    title,
+		Duration: state.duration,
+		Lang:     detectLang(input),
+	}
+
+	switch {
+	case state.absolute != nil:
+		result.Start = *state.absolute
+	case state.hasTime:
+		day := state.now
+		if state.date != nil {
+			day = *state.date
+		}
+		result.Start = time.Date(day.Year(), day.Month(), day.Day(), state.hour, state.minute, 0, 0, loc)
+		if state.date == nil && result.Start.Before(state.now) {
+			result.Start = result.Start.AddDate(0, 0, 1)
+		}
+	default:
+		day := state.now
+		if state.date != nil {
+			day = *state.date
+		}
+		result.Start = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+		result.AllDay = true
+	}
+
+	switch {
+	case result.AllDay:
+		result.Duration = max(_day, (result.Duration+_day-1)/_day*_day)
+	case result.Duration == 0:
+		result.Duration = p.defaultDuration
+	}
+
+	return result, nil
+}
+
+// consume applies the rule to the first acceptable match and blanks the
+// matched span out so later rules and the title never see it again.
+func (r rule) consume(text string, state *parsed) string {
+	for _, idx := range r.re.FindAllStringSubmatchIndex(text, -1) {
+		groups := make(map[string]string)
+		for i, name := range r.re.SubexpNames() {
+			if name != "" && idx[2*i] >= 0 {
+				groups[name] = text[idx[2*i]:idx[2*i+1]]
+			}
+		}
+
+		if !r.apply(state, groups) {
+			continue
+		}
+
+		m := r.re.SubexpIndex("m")
+		start, end := idx[2*m], idx[2*m+1]
+		return text[:start] + strings.Repeat(" ", end-start) + text[end:]
+	}
+	return text
+}
+
+func buildRules() []rule {
+	const (
+		clock     = `(?P<hour>\d{1,2})(?:(?P<sep>[:.])(?P<minute>\d{2}))?\s*(?P<ampm>a\.?m\.?|p\.?m\.?)?(?:\s*(?P<daypart>утра|дня|вечера|ночи))?`
+		colon     = `(?P<hour>\d{1,2})(?P<sep>:)(?P<minute>\d{2})\s*(?P<ampm>a\.?m\.?|p\.?m\.?)?`
+		rangeFrom = `(?P<h1>\d{1,2})(?:(?P<s1>[:.])(?P<m1>\d{2}))?\s*(?P<ap1>am|pm)?`
+		rangeTo   = `(?P<h2>\d{1,2})(?:(?P<s2>[:.])(?P<m2>\d{2}))?\s*(?P<ap2>am|pm)?`
+		durUnit   = `(?P<unit>hours|hour|hrs|hr|h|minutes|minute|mins|min|m|часов|часа|час|ч|минуты|минуту|минут|мин|м)`
+		monthName = `(?P<month>january|february|march|april|may|june|july|august|september|october|november|december|` +
+			`jan|feb|mar|apr|jun|jul|aug|sep|sept|oct|nov|dec|` +
+			`января|февраля|марта|апреля|мая|июня|июля|августа|сентября|октября|ноября|декабря)`
+		weekday = `(?P<weekday>monday|tuesday|wednesday|thursday|friday|saturday|sunday|mon|tue|tues|wed|thu|thur|thurs|fri|sat|sun|` +
+			`понедельник|вторник|среду|среда|четверг|пятницу|пятница|субботу|суббота|воскресенье)`
+	)
+
+	return []rule{
+		newRule(`(?:from|с|со)\s+`+rangeFrom+`\s*(?:-|–|to|till|until|до|по)\s*`+rangeTo, applyTimeRange),
+		newRule(`(?P<h1>\d{1,2})(?P<s1>:)(?P<m1>\d{2})\s*(?:-|–)\s*(?P<h2>\d{1,2})(?P<s2>:)(?P<m2>\d{2})`, applyTimeRange),
+
+		newRule(`(?:for|на)\s+(?P<num>\d+(?:[.,]\d+)?)\s*`+durUnit, applyDuration),
+		newRule(`(?:for|на)\s+(?P<word>half\s+an\s+hour|an\s+hour\s+and\s+a\s+half|an?\s+hour|полчаса|полтора\s+часа|час)`,
+			applyDurationWord),
+
+		newRule(`(?:in|через)\s+(?P<num>\d+)\s*(?P<unit>hours|hour|hrs|h|minutes|minute|mins|min|часов|часа|час|минуты|минуту|минут|мин)`,
+			applyRelativeTime),
+		newRule(`(?P<word>in\s+an?\s+hour|in\s+half\s+an\s+hour|через\s+час|через\s+полчаса)`, applyRelativeTimeWord),
+
+		newRule(`(?:at|@|в|во|к)\s+`+clock, applyClock),
+		newRule(`(?:at|в|во|к)\s+(?P<word>noon|midnight|полдень|полночь)`, applyClockWord),
+		newRule(colon, applyClock),
+		newRule(`(?P<hour>\d{1,2})\s*(?P<ampm>a\.?m\.?|p\.?m\.?)`, applyClock),
+
+		newRule(`(?:on\s+)?(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})`, applyISODate),
+		newRule(`(?:on\s+)?(?P<day>\d{1,2})(?P<sep>[./])(?P<month>\d{1,2})(?:[./](?P<year>\d{2,4}))?`, applyNumericDate),
+		newRule(`(?:on\s+)?(?P<day>\d{1,2})(?:st|nd|rd|th)?\s+(?:of\s+)?`+monthName+`(?:\s+(?P<year>\d{4}))?`, applyMonthDate),
+		newRule(`(?:on\s+)?`+monthName+`\s+(?P<day>\d{1,2})(?:st|nd|rd|th)?(?:,?\s+(?P<year>\d{4}))?`, applyMonthDate),
+
+		newRule(`(?P<word>the\s+day\s+after\s+tomorrow|day\s+after\s+tomorrow|послезавтра|tomorrow|завтра|today|tonight|сегодня)`,
+			applyRelativeDay),
+		newRule(`(?:in|через)\s+(?P<num>\d+)\s*(?P<unit>days|day|weeks|week|дней|дня|день|недели|недель|неделю)`,
+			applyRelativeDate),
+		newRule(`(?P<word>in\s+a\s+week|next\s+week|через\s+неделю|на\s+следующей\s+неделе)`, applyNextWeek),
+		newRule(`(?:(?:on|next|this|в\s+следующ(?:ий|ую|ее)|в\s+эт(?:от|у|о)|в|во)\s+)?`+weekday, applyWeekday),
+	}
+}
+
+func newRule(body string, apply func(p *parsed, g map[string]string) bool) rule {
+	return rule{
+		re:    regexp.MustCompile(`(?i)(?:^|[\s,;(])(?P<m>` + body + `)(?:$|[\s,;.!?)])`),
+		apply: apply,
+	}
+}
+
+func applyTimeRange(p *parsed, g map[string]string) bool {
+	if p.hasTime {
+		return false
+	}
+
+	h1, m1, ok1 := clockTime(g["h1"], g["m1"], g["ap1"], "")
+	h2, m2, ok2 := clockTime(g["h2"], g["m2"], g["ap2"], "")
+	if !ok1 || !ok2 {
+		p.badTime = p.badTime ||
+			(!ok1 && explicitClock(g["s1"], g["ap1"], "")) ||
+			(!ok2 && explicitClock(g["s2"], g["ap2"], ""))
+		return false
+	}
+
+	start := h1*_minutesPerHour + m1
+	end := h2*_minutesPerHour + m2
+	if end <= start {
+		end += 24 * _minutesPerHour
+	}
+
+	p.hour, p.minute, p.hasTime = h1, m1, true
+	p.duration = time.Duration(end-start) * time.Minute
+	return true
+}
+
+func applyDuration(p *parsed, g map[string]string) bool {
+	if p.duration != 0 {
+		return false
+	}
+
+	num, err := strconv.ParseFloat(strings.ReplaceAll(g["num"], ",", "."), 64)
+	if err != nil || num <= 0 {
+		return false
+	}
+
+	unit := time.Minute
+	if isHourUnit(g["unit"]) {
+		unit = time.Hour
+	}
+
+	p.duration = time.Duration(num * float64(unit))
+	return true
+}
+
+func applyDurationWord(p *parsed, g map[string]string) bool {
+	if p.duration != 0 {
+		return false
+	}
+
+	switch word := normalizeSpaces(g["word"]); word {
+	case "half an hour", "полчаса":
+		p.duration = 30 * time.Minute
+	case "an hour and a half", "полтора часа":
+		p.duration = 90 * time.Minute
+	default:
+		p.duration = time.Hour
+	}
+	return true
+}
+
+func applyRelativeTime(p *parsed, g map[string]string) bool {
+	if p.absolute != nil || p.hasTime {
+		return false
+	}
+
+	num, err := strconv.Atoi(g["num"])
+	if err != nil {
+		return false
+	}
+
+	unit := time.Minute
+	if isHourUnit(g["unit"]) {
+		unit = time.Hour
+	}
+
+	at := p.now.Add(time.Duration(num) * unit).Truncate(time.Minute)
+	p.absolute = &at
+	return true
+}
+
+func applyRelativeTimeWord(p *parsed, g map[string]string) bool {
+	if p.absolute != nil || p.hasTime {
+		return false
+	}
+
+	offset := time.Hour
+	if strings.Contains(normalizeSpaces(g["word"]), "half") || strings.Contains(g["word"], "полчаса") {
+		offset = 30 * time.Minute
+	}
+
+	at := p.now.Add(offset).Truncate(time.Minute)
+	p.absolute = &at
+	return true
+}
+
+func applyClock(p *parsed, g map[string]string) bool {
+	if p.hasTime || p.absolute != nil {
+		return false
+	}
+
+	hour, minute, ok := clockTime(g["hour"], g["minute"], g["ampm"], g["daypart"])
+	if !ok {
+		p.badTime = p.badTime || explicitClock(g["sep"], g["ampm"], g["daypart"])
+		return false
+	}
+
+	p.hour, p.minute, p.hasTime = hour, minute, true
+	return true
+}
+
+func applyClockWord(p *parsed, g map[string]string) bool {
+	if p.hasTime || p.absolute != nil {
+		return false
+	}
+
+	switch strings.ToLower(g["word"]) {
+	case "noon", "полдень":
+		p.hour = _hoursPerHalfDay
+	default:
+		p.hour = 0
+	}
+
+	p.minute, p.hasTime = 0, true
+	return true
+}
+
+func applyISODate(p *parsed, g map[string]string) bool {
+	year, _ := strconv.Atoi(g["year"])
+	month, _ := strconv.Atoi(g["month"])
+	day, _ := strconv.Atoi(g["day"])
+	return p.setDate(year, month, day)
+}
+
+func applyNumericDate(p *parsed, g map[string]string) bool {
+	day, _ := strconv.Atoi(g["day"])
+	month, _ := strconv.Atoi(g["month"])
+
+	if g["sep"] == "/" && month > 12 && day <= 12 {
+		day, month = month, day
+	}
+
+	if g["year"] == "" {
+		return p.setUpcomingDate(month, day)
+	}
+
+	year, _ := strconv.Atoi(g["year"])
+	if year < _twoDigitYear {
+		year += _century
+	}
+	return p.setDate(year, month, day)
+}
+
+func applyMonthDate(p *parsed, g map[string]string) bool {
+	month := monthNumber(g["month"])
+	day, _ := strconv.Atoi(g["day"])
+
+	if g["year"] == "" {
+		return p.setUpcomingDate(month, day)
+	}
+
+	year, _ := strconv.Atoi(g["year"])
+	return p.setDate(year, month, day)
+}
+
+func applyRelativeDay(p *parsed, g map[string]string) bool {
+	if p.date != nil {
+		return false
+	}
+
+	word := normalizeSpaces(g["word"])
+	switch {
+	case strings.Contains(word, "after"), word == "послезавтра":
+		p.shiftDays(2)
+	case word == "tomorrow", word == "завтра":
+		p.shiftDays(1)
+	default:
+		p.shiftDays(0)
+	}
+	return true
+}
+
+func applyRelativeDate(p *parsed, g map[string]string) bool {
+	if p.date != nil {
+		return false
+	}
+
+	num, err := strconv.Atoi(g["num"])
+	if err != nil {
+		return false
+	}
+
+	unit := strings.ToLower(g["unit"])
+	if strings.HasPrefix(unit, "week") || strings.HasPrefix(unit, "недел") {
+		num *= _daysPerWeek
+	}
+
+	p.shiftDays(num)
+	return true
+}
+
+func applyNextWeek(p *parsed, _ map[string]string) bool {
+	if p.date != nil {
+		return false
+	}
+
+	p.shiftDays(_daysPerWeek)
+	return true
+}
+
+// applyWeekday resolves a weekday name to its next occurrence after today,
+// so "monday" said on a Monday means a week ahead.
+func applyWeekday(p *parsed, g map[string]string) bool {
+	if p.date != nil {
+		return false
+	}
+
+	target, ok := weekdayNumber(g["weekday"])
+	if !ok {
+		return false
+	}
+
+	days := (int(target) - int(p.now.Weekday()) + _daysPerWeek) % _daysPerWeek
+	if days == 0 {
+		days = _daysPerWeek
+	}
+
+	p.shiftDays(days)
+	return true
+}
+
+func (p *parsed) shiftDays(days int) {
+	day := p.now.AddDate(0, 0, days)
+	p.date = &day
+}
+
+func (p *parsed) setDate(year, month, day int) bool {
+	if p.date != nil || month < 1 || month > 12 || day < 1 {
+		return false
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, p.now.Location())
+	if date.Day() != day {
+		return false
+	}
+
+	p.date = &date
+	return true
+}
+
+// setUpcomingDate picks this year's date, or next year's if it has passed.
+func (p *parsed) setUpcomingDate(month, day int) bool {
+	year := p.now.Year()
+	today := time.Date(year, p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+
+	if month >= 1 && month <= 12 && time.Date(year, time.Month(month), day, 0, 0, 0, 0, p.now.Location()).Before(today) {
+		year++
+	}
+	return p.setDate(year, month, day)
+}
+
+func clockTime(hourStr, minuteStr, ampm, daypart string) (int, int, bool) {
+	hour, err := strconv.Atoi(hourStr)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	minute := 0
+	if minuteStr != "" {
+		if minute, err = strconv.Atoi(minuteStr); err != nil {
+			return 0, 0, false
+		}
+	}
+
+	ampm = strings.ReplaceAll(strings.ToLower(ampm), ".", "")
+	switch {
+	case ampm == "pm" && hour < _hoursPerHalfDay:
+		hour += _hoursPerHalfDay
+	case ampm == "am" && hour == _hoursPerHalfDay:
+		hour = 0
+	}
+
+	switch strings.ToLower(daypart) {
+	case "дня", "вечера":
+		if hour < _hoursPerHalfDay {
+			hour += _hoursPerHalfDay
+		}
+	case "ночи":
+		if hour == _hoursPerHalfDay {
+			hour = 0
+		}
+	}
+
+	if hour > _maxHour || minute > _maxMinute {
+		return 0, 0, false
+	}
+	return hour, minute, true
+}
+
+// explicitClock reports whether a match can only be a time of day: "25.12"
+// may be a date and "at 25" a street number, but "25:00" and "25pm" may not.
+func explicitClock(sep, ampm, daypart string) bool {
+	return sep == ":" || ampm != "" || daypart != ""
+}
+
+func isHourUnit(unit string) bool {
+	unit = strings.ToLower(unit)
+	return strings.HasPrefix(unit, "h") || strings.HasPrefix(unit, "ч")
+}
+
+func monthNumber(name string) int {
+	name = strings.ToLower(name)
+
+	prefixes := []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	for i, prefix := range prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return i + 1
+		}
+	}
+
+	ruPrefixes := []string{"янв", "фев", "мар", "апр", "мая", "июн", "июл", "авг", "сен", "окт", "ноя", "дек"}
+	for i, prefix := range ruPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func weekdayNumber(name string) (time.Weekday, bool) {
+	name = strings.ToLower(name)
+
+	prefixes := map[string]time.Weekday{
+		"mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday, "thu": time.Thursday,
+		"fri": time.Friday, "sat": time.Saturday, "sun": time.Sunday,
+		"пон": time.Monday, "вто": time.Tuesday, "сре": time.Wednesday, "чет": time.Thursday,
+		"пят": time.Friday, "суб": time.Saturday, "вос": time.Sunday,
+	}
+	for prefix, day := range prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+func cleanTitle(rest string) string {
+	dangling := map[string]struct{}{
+		"at": {}, "on": {}, "for": {}, "in": {}, "from": {}, "to": {}, "by": {},
+		"в": {}, "во": {}, "на": {}, "с": {}, "со": {}, "к": {}, "до": {}, "через": {},
+	}
+
+	words := strings.FieldsFunc(rest, func(r rune) bool {
+		return unicode.IsSpace(r)
+	})
+
+	trim := func(word string) string {
+		return strings.Trim(word, ",;:-–—")
+	}
+
+	for len(words) > 0 {
+		last := trim(words[len(words)-1])
+		if _, ok := dangling[strings.ToLower(last)]; ok || last == "" {
+			words = words[:len(words)-1]
+			continue
+		}
+		words[len(words)-1] = last
+		break
+	}
+
+	for len(words) > 0 {
+		first := trim(words[0])
+		if _, ok := dangling[strings.ToLower(first)]; ok || first == "" {
+			words = words[1:]
+			continue
+		}
+		words[0] = first
+		break
+	}
+
+	return strings.Join(words, " ")
+}
+
+func detectLang(input string) Lang {
+	for _, r := range input {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return LangRussian
+		}
+	}
+	return LangEnglish
+}
+
+func normalizeSpaces(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
@@ -0,0 +1,205 @@
+package quickadd_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"calendar-wbf/pkg/quickadd"
+)
+
+func TestParser_Parse(t *testing.T) {
+	t.Parallel()
+
+	loc, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+
+	// Sunday, 10:00 local time.
+	now := time.Date(2026, time.October, 18, 10, 0, 0, 0, loc)
+	parser := quickadd.NewParser(quickadd.WithClock(func() time.Time { return now }))
+
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		year := 2026
+		if month < time.October {
+			year = 2027
+		}
+		return time.Date(year, month, day, hour, minute, 0, 0, loc)
+	}
+
+	testCases := []struct {
+		desc  string
+		input string
+		want  quickadd.Result
+	}{
+		{
+			desc:  "EnglishTomorrowAtFor",
+			input: "Lunch with Anna tomorrow at 13:00 for 1h",
+			want: quickadd.Result{
+				Title: "Lunch with Anna", Start: at(time.October, 19, 13, 0), Duration: time.Hour,
+				Lang: quickadd.LangEnglish,
+			},
+		},
+		{
+			desc:  "RussianTomorrowAtFor",
+			input: "Обед с Анной завтра в 13:00 на 1ч",
+			want: quickadd.Result{
+				Title: "Обед с Анной", Start: at(time.October, 19, 13, 0), Duration: time.Hour,
+				Lang: quickadd.LangRussian,
+			},
+		},
+		{
+			desc:  "PastTimeRollsOverToTomorrow",
+			input: "Standup at 9:30am",
+			want: quickadd.Result{
+				Title: "Standup", Start: at(time.October, 19, 9, 30), Duration: time.Hour,
+				Lang: quickadd.LangEnglish,
+			},
+		},
+		{
+			desc:  "RussianWeekdayWithRange",
+			input: "Встреча в пятницу с 15:00 до 16:30",
+			want: quickadd.Result{
+				Title: "Встреча", Start: at(time.October, 23, 15, 0), Duration: 90 * time.Minute,
+				Lang: quickadd.LangRussian,
+			},
+		},
+		{
+			desc:  "NumericDateWithMeridiem",
+			input: "Dentist on 25.12 at 4pm for 30 min",
+			want: quickadd.Result{
+				Title: "Dentist", Start: at(time.December, 25, 16, 0), Duration: 30 * time.Minute,
+				Lang: quickadd.LangEnglish,
+			},
+		},
+		{
+			desc:  "RussianMonthNameNextYearAllDay",
+			input: "Отпуск 1 января",
+			want: quickadd.Result{
+				Title: "Отпуск", Start: at(time.January, 1, 0, 0), Duration: 24 * time.Hour, AllDay: true,
+				Lang: quickadd.LangRussian,
+			},
+		},
+		{
+			desc:  "EnglishRelativeHours",
+			input: "Call mom in 2 hours",
+			want: quickadd.Result{
+				Title: "Call mom", Start: at(time.October, 18, 12, 0), Duration: time.Hour,
+				Lang: quickadd.LangEnglish,
+			},
+		},
+		{
+			desc:  "NextWeekdayFractionalDuration",
+			input: "Review next monday 10:00 for 1.5 hours",
+			want: quickadd.Result{
+				Title: "Review", Start: at(time.October, 19, 10, 0), Duration: 90 * time.Minute,
+				Lang: quickadd.LangEnglish,
+			},
+		},
+		{
+			desc:  "RussianDaysAheadEveningHalfHour",
+			input: "Созвон через 3 дня в 7 вечера на полчаса",
+			want: quickadd.Result{
+				Title: "Созвон", Start: at(time.October, 21, 19, 0), Duration: 30 * time.Minute,
+				Lang: quickadd.LangRussian,
+			},
+		},
+		{
+			desc:  "RussianDayAfterTomorrow",
+			input: "Планёрка послезавтра в 10",
+			want: quickadd.Result{
+				Title: "Планёрка", Start: at(time.October, 20, 10, 0), Duration: time.Hour,
+				Lang: quickadd.LangRussian,
+			},
+		},
+		{
+			desc:  "MonthFirstWithNoon",
+			input: "Board meeting November 3 at noon",
+			want: quickadd.Result{
+				Title: "Board meeting", Start: at(time.November, 3, 12, 0), Duration: time.Hour,
+				Lang: quickadd.LangEnglish,
+			},
+		},
+		{
+			desc:  "AllDayDurationRoundedToDays",
+			input: "Conference tomorrow for 36 hours",
+			want: quickadd.Result{
+				Title: "Conference", Start: at(time.October, 19, 0, 0), Duration: 48 * time.Hour, AllDay: true,
+				Lang: quickadd.LangEnglish,
+			},
+		},
+		{
+			desc:  "DayMonthNotTakenForTime",
+			input: "Отпуск в 25.12",
+			want: quickadd.Result{
+				Title: "Отпуск", Start: at(time.December, 25, 0, 0), Duration: 24 * time.Hour, AllDay: true,
+				Lang: quickadd.LangRussian,
+			},
+		},
+		{
+			desc:  "DanglingPrepositionTrimmed",
+			input: "Встреча на завтра",
+			want: quickadd.Result{
+				Title: "Встреча", Start: at(time.October, 19, 0, 0), Duration: 24 * time.Hour, AllDay: true,
+				Lang: quickadd.LangRussian,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parser.Parse(tc.input, loc)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tc.input, err)
+			}
+
+			if got.Title != tc.want.Title {
+				t.Errorf("Title = %q; want %q", got.Title, tc.want.Title)
+			}
+			if !got.Start.Equal(tc.want.Start) {
+				t.Errorf("Start = %v; want %v", got.Start, tc.want.Start)
+			}
+			if got.Duration != tc.want.Duration {
+				t.Errorf("Duration = %v; want %v", got.Duration, tc.want.Duration)
+			}
+			if got.AllDay != tc.want.AllDay {
+				t.Errorf("AllDay = %v; want %v", got.AllDay, tc.want.AllDay)
+			}
+			if got.Lang != tc.want.Lang {
+				t.Errorf("Lang = %q; want %q", got.Lang, tc.want.Lang)
+			}
+		})
+	}
+}
+
+func TestParser_ParseErrors(t *testing.T) {
+	t.Parallel()
+
+	parser := quickadd.NewParser()
+
+	testCases := []struct {
+		desc  string
+		input string
+		want  error
+	}{
+		{"Empty", "   ", quickadd.ErrEmptyInput},
+		{"OnlyDateAndTime", "tomorrow at 10", quickadd.ErrEmptyTitle},
+		{"OnlyRussianDateAndTime", "завтра в 10:00 на час", quickadd.ErrEmptyTitle},
+		{"HourOutOfRange", "Lunch at 25:00", quickadd.ErrBadTime},
+		{"MinuteOutOfRange", "Standup 9:75-10:00", quickadd.ErrBadTime},
+		{"MeridiemHourOutOfRange", "Dinner at 25pm", quickadd.ErrBadTime},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := parser.Parse(tc.input, time.UTC); !errors.Is(err, tc.want) {
+				t.Errorf("Parse(%q) error = %v; want %v", tc.input, err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,369 @@
+package resp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	_defaultPoolSize    = 8
+	_defaultDialTimeout = time.Second
+	_defaultIOTimeout   = time.Second
+)
+
+type (
+	// Client sends commands over a pool of connections dialed on demand.
+	// Subscriptions get a dedicated connection each.
+	Client struct {
+		addr        string
+		dialTimeout time.Duration
+		ioTimeout   time.Duration
+
+		idle   chan *conn
+		mu     sync.Mutex
+		closed bool
+	}
+
+	ClientOption func(*Client)
+
+	conn struct {
+		net.Conn
+		r *bufio.Reader
+		w *bufio.Writer
+	}
+)
+
+func WithPoolSize(n int) ClientOption {
+	return func(c *Client) {
+		if n > 0 {
+			c.idle = make(chan *conn, n)
+		}
+	}
+}
+
+func WithDialTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.dialTimeout = d
+	}
+}
+
+// WithIOTimeout bounds each command when the context has no deadline.
+func WithIOTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.ioTimeout = d
+	}
+}
+
+func NewClient(addr string, opts ...ClientOption) *Client {
+	c := &Client{
+		addr:        addr,
+		dialTimeout: _defaultDialTimeout,
+		ioTimeout:   _defaultIOTimeout,
+		idle:        make(chan *conn, _defaultPoolSize),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Do sends one command and returns its reply. Error replies are returned as
+// an Error. A pooled connection the server has closed meanwhile, e.g. after
+// a restart, is replaced by a fresh one and the command is sent again.
+func (c *Client) Do(ctx context.Context, args ...string) (any, error) {
+	cn, reused, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := cn.roundTrip(ctx, c.ioTimeout, args)
+	if err != nil && reused && isStaleConn(err) {
+		cn.Close()
+		if cn, err = c.dial(ctx); err != nil {
+			return nil, err
+		}
+		reply, err = cn.roundTrip(ctx, c.ioTimeout, args)
+	}
+	if err != nil {
+		cn.Close()
+		return nil, fmt.Errorf("resp: %s: %w", args[0], err)
+	}
+	c.put(cn)
+
+	if replyErr, ok := reply.(Error); ok {
+		return nil, replyErr
+	}
+	return reply, nil
+}
+
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, "PING")
+	return err
+}
+
+// Get returns ErrNil when the key doesn't exist.
+func (c *Client) Get(ctx context.Context, key string) ([]byte, error) {
+	reply, err := c.Do(ctx, "GET", key)
+	if err != nil {
+		return nil, err
+	}
+	return asBulk(reply)
+}
+
+// Set stores value; a positive ttl sets a millisecond expiry.
+func (c *Client) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []string{"SET", key, string(value)}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(max(ttl.Milliseconds(), 1), 10))
+	}
+	_, err := c.Do(ctx, args...)
+	return err
+}
+
+func (c *Client) Del(ctx context.Context, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	reply, err := c.Do(ctx, append([]string{"DEL"}, keys...)...)
+	if err != nil {
+		return 0, err
+	}
+	return asInt(reply)
+}
+
+func (c *Client) Exists(ctx context.Context, key string) (bool, error) {
+	reply, err := c.Do(ctx, "EXISTS", key)
+	if err != nil {
+		return false, err
+	}
+	n, err := asInt(reply)
+	return n > 0, err
+}
+
+// PTTL returns the remaining TTL, -1 for keys without expiry and ErrNil for
+// missing keys.
+func (c *Client) PTTL(ctx context.Context, key string) (time.Duration, error) {
+	reply, err := c.Do(ctx, "PTTL", key)
+	if err != nil {
+		return 0, err
+	}
+	n, err := asInt(reply)
+	switch {
+	case err != nil:
+		return 0, err
+	case n == -2:
+		return 0, ErrNil
+	case n == -1:
+		return -1, nil
+	default:
+		return time.Duration(n) * time.Millisecond, nil
+	}
+}
+
+// Keys lists keys matching a glob pattern. It walks the whole keyspace on
+// the server and is meant for admin and tests, not hot paths.
+func (c *Client) Keys(ctx context.Context, pattern string) ([]string, error) {
+	reply, err := c.Do(ctx, "KEYS", pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	items, ok := reply.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: KEYS reply %T", ErrProtocol, reply)
+	}
+
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		b, bulkErr := asBulk(item)
+		if bulkErr != nil {
+			return nil, bulkErr
+		}
+		keys = append(keys, string(b))
+	}
+	return keys, nil
+}
+
+func (c *Client) Publish(ctx context.Context, channel string, payload []byte) error {
+	_, err := c.Do(ctx, "PUBLISH", channel, string(payload))
+	return err
+}
+
+// Subscribe listens on channel over a dedicated connection. The returned
+// channel is closed when ctx is done or the connection fails, so callers
+// can tell a lost subscription apart and resubscribe.
+func (c *Client) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	cn, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := cn.roundTrip(ctx, c.ioTimeout, []string{"SUBSCRIBE", channel})
+	if err != nil {
+		cn.Close()
+		return nil, fmt.Errorf("resp: SUBSCRIBE: %w", err)
+	}
+	if kind, _ := pushKind(reply); kind != "subscribe" {
+		cn.Close()
+		return nil, fmt.Errorf("%w: unexpected SUBSCRIBE reply %v", ErrProtocol, reply)
+	}
+
+	if err = cn.SetDeadline(time.Time{}); err != nil {
+		cn.Close()
+		return nil, err
+	}
+
+	messages := make(chan []byte, _defaultPoolSize)
+	stop := context.AfterFunc(ctx, func() { cn.Close() })
+
+	go func() {
+		defer close(messages)
+		defer stop()
+		defer cn.Close()
+
+		for {
+			push, readErr := readReply(cn.r)
+			if readErr != nil {
+				return
+			}
+			kind, items := pushKind(push)
+			if kind != "message" || len(items) != 3 {
+				continue
+			}
+			payload, _ := items[2].([]byte)
+
+			select {
+			case messages <- payload:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return messages, nil
+}
+
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	for {
+		select {
+		case cn := <-c.idle:
+			cn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// get takes an idle connection or dials a new one, reporting which.
+func (c *Client) get(ctx context.Context) (*conn, bool, error) {
+	select {
+	case cn := <-c.idle:
+		return cn, true, nil
+	default:
+		cn, err := c.dial(ctx)
+		return cn, false, err
+	}
+}
+
+func (c *Client) put(cn *conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		cn.Close()
+		return
+	}
+
+	select {
+	case c.idle <- cn:
+	default:
+		cn.Close()
+	}
+}
+
+func (c *Client) dial(ctx context.Context) (*conn, error) {
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+	if closed {
+		return nil, errors.New("resp: client closed")
+	}
+
+	dialer := net.Dialer{Timeout: c.dialTimeout}
+	nc, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, fmt.Errorf("resp: dial %s: %w", c.addr, err)
+	}
+
+	return &conn{Conn: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}, nil
+}
+
+func (cn *conn) roundTrip(ctx context.Context, timeout time.Duration, args []string) (any, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(timeout)
+	}
+	if err := cn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	raw := make([][]byte, len(args))
+	for i, arg := range args {
+		raw[i] = []byte(arg)
+	}
+	if err := writeCommand(cn.w, raw...); err != nil {
+		return nil, err
+	}
+	return readReply(cn.r)
+}
+
+func isStaleConn(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE)
+}
+
+func pushKind(reply any) (string, []any) {
+	items, ok := reply.([]any)
+	if !ok || len(items) == 0 {
+		return "", nil
+	}
+	kind, _ := items[0].([]byte)
+	return string(kind), items
+}
+
+func asBulk(reply any) ([]byte, error) {
+	switch v := reply.(type) {
+	case []byte:
+		if v == nil {
+			return nil, ErrNil
+		}
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return nil, fmt.Errorf("%w: expected bulk string, got %T", ErrProtocol, reply)
+	}
+}
+
+func asInt(reply any) (int64, error) {
+	n, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("%w: expected integer, got %T", ErrProtocol, reply)
+	}
+	return n, nil
+}
@@ -0,0 +1,153 @@
+// Package resp speaks RESP2, the Redis serialization protocol: a pooled
+// client for key/value and pub/sub commands and a small in-process server
+// implementing the same subset for tests and local development.
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const _maxBulkLen = 512 << 20
+
+var (
+	ErrNil      = errors.New("resp: nil reply")
+	ErrProtocol = errors.New("resp: protocol error")
+)
+
+// Error is an error reply sent by the server, e.g. "ERR unknown command".
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+// writeCommand encodes args as an array of bulk strings.
+func writeCommand(w *bufio.Writer, args ...[]byte) error {
+	if err := writeHeader(w, '*', len(args)); err != nil {
+		return err
+	}
+	for _, arg := range args {
+		if err := writeBulk(w, arg); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+func writeHeader(w *bufio.Writer, prefix byte, n int) error {
+	if err := w.WriteByte(prefix); err != nil {
+		return err
+	}
+	if _, err := w.WriteString(strconv.Itoa(n)); err != nil {
+		return err
+	}
+	_, err := w.WriteString("\r\n")
+	return err
+}
+
+func writeBulk(w *bufio.Writer, b []byte) error {
+	if b == nil {
+		_, err := w.WriteString("$-1\r\n")
+		return err
+	}
+	if err := writeHeader(w, '$', len(b)); err != nil {
+		return err
+	}
+	if _, err := w.Write(b); err != nil {
+		return err
+	}
+	_, err := w.WriteString("\r\n")
+	return err
+}
+
+func writeSimple(w *bufio.Writer, prefix byte, s string) error {
+	if err := w.WriteByte(prefix); err != nil {
+		return err
+	}
+	if _, err := w.WriteString(s); err != nil {
+		return err
+	}
+	_, err := w.WriteString("\r\n")
+	return err
+}
+
+// readReply decodes one reply: string for simple strings, Error for error
+// replies, int64 for integers, []byte for bulk strings (nil for a null
+// bulk) and []any for arrays (nil for a null array).
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, fmt.Errorf("%w: empty line", ErrProtocol)
+	}
+
+	switch line[0] {
+	case '+':
+		return string(line[1:]), nil
+	case '-':
+		return Error(line[1:]), nil
+	case ':':
+		n, err := strconv.ParseInt(string(line[1:]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad integer %q", ErrProtocol, line)
+		}
+		return n, nil
+	case '$':
+		return readBulk(r, line)
+	case '*':
+		n, err := parseLength(line)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]any, n)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected prefix %q", ErrProtocol, line[0])
+	}
+}
+
+func readBulk(r *bufio.Reader, header []byte) ([]byte, error) {
+	n, err := parseLength(header)
+	if err != nil || n < 0 {
+		return nil, err
+	}
+
+	buf := make([]byte, n+2)
+	if _, err = io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	if buf[n] != '\r' || buf[n+1] != '\n' {
+		return nil, fmt.Errorf("%w: bulk string not terminated", ErrProtocol)
+	}
+	return buf[:n], nil
+}
+
+func parseLength(line []byte) (int, error) {
+	n, err := strconv.Atoi(string(line[1:]))
+	if err != nil || n < -1 || n > _maxBulkLen {
+		return 0, fmt.Errorf("%w: bad length %q", ErrProtocol, line)
+	}
+	return n, nil
+}
+
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("%w: line not terminated by CRLF", ErrProtocol)
+	}
+	return line[:len(line)-2], nil
+}
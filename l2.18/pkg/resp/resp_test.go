@@ -0,0 +1,211 @@
+package resp_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"calendar-wbf/pkg/resp"
+)
+
+func TestClient_KeyValue(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+
+	if err := client.Set(ctx, "event:1", []byte("binary\r\n\x00value"), 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := client.Set(ctx, "event:2", []byte("two"), time.Hour); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := client.Set(ctx, "other", []byte{}, 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	testCases := []struct {
+		desc    string
+		key     string
+		want    string
+		wantErr error
+	}{
+		{"BinarySafe", "event:1", "binary\r\n\x00value", nil},
+		{"WithTTL", "event:2", "two", nil},
+		{"EmptyValue", "other", "", nil},
+		{"Missing", "event:3", "", resp.ErrNil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := client.Get(ctx, tc.key)
+			if !errors.Is(err, tc.wantErr) || string(got) != tc.want {
+				t.Errorf("Get(%s) = %q, %v; want %q, %v", tc.key, got, err, tc.want, tc.wantErr)
+			}
+		})
+	}
+
+	keys, err := client.Keys(ctx, "event:*")
+	if err != nil || !slices.Equal(keys, []string{"event:1", "event:2"}) {
+		t.Errorf("Keys(event:*) = %v, %v", keys, err)
+	}
+
+	if ttl, err := client.PTTL(ctx, "event:2"); err != nil || ttl <= 59*time.Minute {
+		t.Errorf("PTTL(event:2) = %v, %v; want about an hour", ttl, err)
+	}
+	if ttl, err := client.PTTL(ctx, "event:1"); err != nil || ttl != -1 {
+		t.Errorf("PTTL(event:1) = %v, %v; want -1", ttl, err)
+	}
+	if _, err := client.PTTL(ctx, "event:3"); !errors.Is(err, resp.ErrNil) {
+		t.Errorf("PTTL(event:3) error = %v; want ErrNil", err)
+	}
+
+	if n, err := client.Del(ctx, "event:1", "event:3"); err != nil || n != 1 {
+		t.Errorf("Del() = %d, %v; want 1", n, err)
+	}
+	if ok, err := client.Exists(ctx, "event:1"); err != nil || ok {
+		t.Errorf("Exists(event:1) = %v, %v; want false", ok, err)
+	}
+}
+
+func TestClient_Expiry(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	if err := client.Set(ctx, "short", []byte("v"), 20*time.Millisecond); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	time.Sleep(40 * time.Millisecond)
+
+	if _, err := client.Get(ctx, "short"); !errors.Is(err, resp.ErrNil) {
+		t.Errorf("Get(short) error = %v; want ErrNil after expiry", err)
+	}
+}
+
+func TestClient_ErrorReply(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t)
+
+	_, err := client.Do(context.Background(), "NOPE")
+	var replyErr resp.Error
+	if !errors.As(err, &replyErr) {
+		t.Fatalf("Do(NOPE) error = %v; want resp.Error", err)
+	}
+
+	if err = client.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() after error reply = %v; connection must stay usable", err)
+	}
+}
+
+func TestClient_PubSub(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	messages, err := client.Subscribe(ctx, "invalidate")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	for _, payload := range []string{"first", "second"} {
+		if err = client.Publish(ctx, "invalidate", []byte(payload)); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+		select {
+		case got := <-messages:
+			if string(got) != payload {
+				t.Errorf("message = %q; want %q", got, payload)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("message %q not delivered", payload)
+		}
+	}
+
+	cancel()
+	select {
+	case _, ok := <-messages:
+		if ok {
+			t.Errorf("received a message after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("channel not closed after cancel")
+	}
+}
+
+func TestClient_SubscriptionClosedWithServer(t *testing.T) {
+	t.Parallel()
+
+	srv, err := resp.NewServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	client := resp.NewClient(srv.Addr())
+	t.Cleanup(func() { client.Close() })
+
+	messages, err := client.Subscribe(context.Background(), "invalidate")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	srv.Close()
+
+	select {
+	case <-messages:
+	case <-time.After(time.Second):
+		t.Errorf("channel not closed when the server went away")
+	}
+}
+
+func TestClient_ReconnectsAfterServerRestart(t *testing.T) {
+	t.Parallel()
+
+	srv, err := resp.NewServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	client := resp.NewClient(srv.Addr())
+	t.Cleanup(func() { client.Close() })
+
+	ctx := context.Background()
+	if err = client.Set(ctx, "k", []byte("v"), 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	srv.Close()
+	restarted, err := resp.NewServer(srv.Addr())
+	if err != nil {
+		t.Fatalf("NewServer(%s) error = %v", srv.Addr(), err)
+	}
+	t.Cleanup(func() { restarted.Close() })
+
+	if err = client.Set(ctx, "k", []byte("v2"), 0); err != nil {
+		t.Errorf("Set() on a stale pooled connection error = %v; want a transparent redial", err)
+	}
+}
+
+func newTestClient(t *testing.T) *resp.Client {
+	t.Helper()
+
+	srv, err := resp.NewServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	client := resp.NewClient(srv.Addr(), resp.WithPoolSize(2))
+
+	t.Cleanup(func() {
+		client.Close()
+		srv.Close()
+	})
+	return client
+}
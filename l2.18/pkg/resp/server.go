@@ -0,0 +1,422 @@
+package resp
+
+import (
+	"bufio"
+	"net"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// Server is an in-memory stand-in for Redis implementing the commands the
+	// Client uses: PING, ECHO, GET, SET (EX/PX), DEL, EXISTS, PTTL, KEYS,
+	// DBSIZE, FLUSHDB, PUBLISH, SUBSCRIBE, UNSUBSCRIBE and QUIT.
+	Server struct {
+		listener net.Listener
+
+		mu   sync.Mutex
+		data map[string]serverItem
+		subs map[string]map[*serverConn]struct{}
+
+		conns sync.WaitGroup
+		open  map[*serverConn]struct{}
+	}
+
+	serverItem struct {
+		value   []byte
+		expires time.Time
+	}
+
+	serverConn struct {
+		net.Conn
+		r *bufio.Reader
+
+		writeMu  sync.Mutex
+		w        *bufio.Writer
+		channels map[string]struct{}
+	}
+)
+
+// NewServer starts a server on addr; use "127.0.0.1:0" for a random port.
+func NewServer(addr string) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		listener: ln,
+		data:     make(map[string]serverItem),
+		subs:     make(map[string]map[*serverConn]struct{}),
+		open:     make(map[*serverConn]struct{}),
+	}
+	go s.acceptLoop()
+
+	return s, nil
+}
+
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops accepting, drops every client connection and waits for their
+// handlers to exit.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+
+	s.mu.Lock()
+	for sc := range s.open {
+		sc.Close()
+	}
+	s.mu.Unlock()
+
+	s.conns.Wait()
+	return err
+}
+
+func (s *Server) acceptLoop() {
+	for {
+		nc, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		sc := &serverConn{
+			Conn:     nc,
+			r:        bufio.NewReader(nc),
+			w:        bufio.NewWriter(nc),
+			channels: make(map[string]struct{}),
+		}
+
+		s.mu.Lock()
+		s.open[sc] = struct{}{}
+		s.conns.Add(1)
+		s.mu.Unlock()
+
+		go s.serve(sc)
+	}
+}
+
+func (s *Server) serve(sc *serverConn) {
+	defer s.conns.Done()
+	defer func() {
+		s.mu.Lock()
+		for channel := range sc.channels {
+			delete(s.subs[channel], sc)
+		}
+		delete(s.open, sc)
+		s.mu.Unlock()
+		sc.Close()
+	}()
+
+	for {
+		reply, err := readReply(sc.r)
+		if err != nil {
+			return
+		}
+
+		items, ok := reply.([]any)
+		if !ok || len(items) == 0 {
+			_ = sc.errorReply("ERR expected command array")
+			continue
+		}
+
+		args := make([]string, len(items))
+		for i, item := range items {
+			b, _ := item.([]byte)
+			args[i] = string(b)
+		}
+
+		if strings.EqualFold(args[0], "QUIT") {
+			_ = sc.write(func(w *bufio.Writer) error { return writeSimple(w, '+', "OK") })
+			return
+		}
+		if err = s.exec(sc, args); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) exec(sc *serverConn, args []string) error {
+	cmd := strings.ToUpper(args[0])
+
+	if len(sc.channels) > 0 && cmd != "SUBSCRIBE" && cmd != "UNSUBSCRIBE" && cmd != "PING" {
+		return sc.errorReply("ERR only (UN)SUBSCRIBE and PING are allowed in this context")
+	}
+
+	switch cmd {
+	case "PING":
+		return sc.write(func(w *bufio.Writer) error { return writeSimple(w, '+', "PONG") })
+	case "ECHO":
+		if len(args) != 2 {
+			return sc.arityError(cmd)
+		}
+		return sc.bulkReply([]byte(args[1]))
+	case "GET":
+		if len(args) != 2 {
+			return sc.arityError(cmd)
+		}
+		item, ok := s.lookup(args[1])
+		if !ok {
+			return sc.bulkReply(nil)
+		}
+		return sc.bulkReply(item.value)
+	case "SET":
+		return s.set(sc, args)
+	case "DEL", "EXISTS":
+		if len(args) < 2 {
+			return sc.arityError(cmd)
+		}
+		return sc.intReply(s.count(args[1:], cmd == "DEL"))
+	case "PTTL":
+		if len(args) != 2 {
+			return sc.arityError(cmd)
+		}
+		return sc.intReply(s.pttl(args[1]))
+	case "KEYS":
+		if len(args) != 2 {
+			return sc.arityError(cmd)
+		}
+		return sc.arrayReply(s.keys(args[1]))
+	case "DBSIZE":
+		return sc.intReply(int64(len(s.keys("*"))))
+	case "FLUSHDB":
+		s.mu.Lock()
+		clear(s.data)
+		s.mu.Unlock()
+		return sc.write(func(w *bufio.Writer) error { return writeSimple(w, '+', "OK") })
+	case "PUBLISH":
+		if len(args) != 3 {
+			return sc.arityError(cmd)
+		}
+		return sc.intReply(s.publish(args[1], []byte(args[2])))
+	case "SUBSCRIBE":
+		if len(args) < 2 {
+			return sc.arityError(cmd)
+		}
+		return s.subscribe(sc, args[1:])
+	case "UNSUBSCRIBE":
+		return s.unsubscribe(sc, args[1:])
+	default:
+		return sc.errorReply("ERR unknown command '" + args[0] + "'")
+	}
+}
+
+func (s *Server) set(sc *serverConn, args []string) error {
+	if len(args) != 3 && len(args) != 5 {
+		return sc.arityError("SET")
+	}
+
+	item := serverItem{value: []byte(args[2])}
+	if len(args) == 5 {
+		n, err := strconv.ParseInt(args[4], 10, 64)
+		if err != nil || n <= 0 {
+			return sc.errorReply("ERR invalid expire time in 'set' command")
+		}
+		switch strings.ToUpper(args[3]) {
+		case "EX":
+			item.expires = time.Now().Add(time.Duration(n) * time.Second)
+		case "PX":
+			item.expires = time.Now().Add(time.Duration(n) * time.Millisecond)
+		default:
+			return sc.errorReply("ERR syntax error")
+		}
+	}
+
+	s.mu.Lock()
+	s.data[args[1]] = item
+	s.mu.Unlock()
+
+	return sc.write(func(w *bufio.Writer) error { return writeSimple(w, '+', "OK") })
+}
+
+// lookup returns a live item, dropping it first if it has expired.
+func (s *Server) lookup(key string) (serverItem, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lookupLocked(key, time.Now())
+}
+
+func (s *Server) lookupLocked(key string, now time.Time) (serverItem, bool) {
+	item, ok := s.data[key]
+	if !ok {
+		return serverItem{}, false
+	}
+	if !item.expires.IsZero() && !now.Before(item.expires) {
+		delete(s.data, key)
+		return serverItem{}, false
+	}
+	return item, true
+}
+
+func (s *Server) count(keys []string, remove bool) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var n int64
+	for _, key := range keys {
+		if _, ok := s.lookupLocked(key, now); ok {
+			n++
+			if remove {
+				delete(s.data, key)
+			}
+		}
+	}
+	return n
+}
+
+func (s *Server) pttl(key string) int64 {
+	item, ok := s.lookup(key)
+	switch {
+	case !ok:
+		return -2
+	case item.expires.IsZero():
+		return -1
+	default:
+		return time.Until(item.expires).Milliseconds()
+	}
+}
+
+func (s *Server) keys(pattern string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var keys []string
+	for key := range s.data {
+		if _, ok := s.lookupLocked(key, now); !ok {
+			continue
+		}
+		if matched, _ := path.Match(pattern, key); matched {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func (s *Server) publish(channel string, payload []byte) int64 {
+	s.mu.Lock()
+	receivers := make([]*serverConn, 0, len(s.subs[channel]))
+	for sc := range s.subs[channel] {
+		receivers = append(receivers, sc)
+	}
+	s.mu.Unlock()
+
+	for _, sc := range receivers {
+		_ = sc.write(func(w *bufio.Writer) error {
+			if err := writeHeader(w, '*', 3); err != nil {
+				return err
+			}
+			if err := writeBulk(w, []byte("message")); err != nil {
+				return err
+			}
+			if err := writeBulk(w, []byte(channel)); err != nil {
+				return err
+			}
+			return writeBulk(w, payload)
+		})
+	}
+	return int64(len(receivers))
+}
+
+func (s *Server) subscribe(sc *serverConn, channels []string) error {
+	for _, channel := range channels {
+		s.mu.Lock()
+		if s.subs[channel] == nil {
+			s.subs[channel] = make(map[*serverConn]struct{})
+		}
+		s.subs[channel][sc] = struct{}{}
+		sc.channels[channel] = struct{}{}
+		count := len(sc.channels)
+		s.mu.Unlock()
+
+		if err := sc.subscriptionReply("subscribe", channel, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) unsubscribe(sc *serverConn, channels []string) error {
+	if len(channels) == 0 {
+		s.mu.Lock()
+		for channel := range sc.channels {
+			channels = append(channels, channel)
+		}
+		s.mu.Unlock()
+	}
+
+	for _, channel := range channels {
+		s.mu.Lock()
+		delete(s.subs[channel], sc)
+		delete(sc.channels, channel)
+		count := len(sc.channels)
+		s.mu.Unlock()
+
+		if err := sc.subscriptionReply("unsubscribe", channel, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sc *serverConn) write(fn func(w *bufio.Writer) error) error {
+	sc.writeMu.Lock()
+	defer sc.writeMu.Unlock()
+
+	if err := fn(sc.w); err != nil {
+		return err
+	}
+	return sc.w.Flush()
+}
+
+func (sc *serverConn) errorReply(msg string) error {
+	return sc.write(func(w *bufio.Writer) error { return writeSimple(w, '-', msg) })
+}
+
+func (sc *serverConn) arityError(cmd string) error {
+	return sc.errorReply("ERR wrong number of arguments for '" + strings.ToLower(cmd) + "' command")
+}
+
+func (sc *serverConn) intReply(n int64) error {
+	return sc.write(func(w *bufio.Writer) error { return writeSimple(w, ':', strconv.FormatInt(n, 10)) })
+}
+
+func (sc *serverConn) bulkReply(b []byte) error {
+	return sc.write(func(w *bufio.Writer) error { return writeBulk(w, b) })
+}
+
+func (sc *serverConn) arrayReply(items []string) error {
+	return sc.write(func(w *bufio.Writer) error {
+		if err := writeHeader(w, '*', len(items)); err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := writeBulk(w, []byte(item)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (sc *serverConn) subscriptionReply(kind, channel string, count int) error {
+	return sc.write(func(w *bufio.Writer) error {
+		if err := writeHeader(w, '*', 3); err != nil {
+			return err
+		}
+		if err := writeBulk(w, []byte(kind)); err != nil {
+			return err
+		}
+		if err := writeBulk(w, []byte(channel)); err != nil {
+			return err
+		}
+		return writeSimple(w, ':', strconv.Itoa(count))
+	})
+}